@@ -18,7 +18,11 @@ package cli
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"os/signal"
+	"strings"
+	"syscall"
 
 	"github.com/spf13/cobra"
 
@@ -44,12 +48,26 @@ var (
 	doShardReplications bool
 	doTablets           bool
 	doRoutingRules      bool
+	watch               bool
+	verify              bool
+	verifyVersions      bool
 
 	Main = &cobra.Command{
 		Use:   "topo2topo",
 		Short: "topo2topo copies Vitess topology data from one topo server to another.",
 		Long: `topo2topo copies Vitess topology data from one topo server to another.
-It can also be used to compare data between two topologies.`,
+It can also be used to compare data between two topologies.
+
+With --watch, it copies the data and then keeps streaming the changes made to
+the source topology into the destination until interrupted, so that a topology
+can be migrated without freezing writes for the duration of the copy. This
+requires the source implementation to support recursive watches.
+
+With --verify, it compares the files of both topologies and prints every
+difference as a JSON report, exiting with an error if there is any.
+
+Both topologies may share one topo server, as long as their roots don't
+overlap, e.g. --from_root /cluster1/global and --to_root /cluster2/global.`,
 		Args:    cobra.NoArgs,
 		PreRunE: servenv.CobraPreRunE,
 		Version: servenv.AppVersion.String(),
@@ -72,6 +90,9 @@ func init() {
 	Main.Flags().BoolVar(&doShardReplications, "do-shard-replications", doShardReplications, "copies the shard replication information")
 	Main.Flags().BoolVar(&doTablets, "do-tablets", doTablets, "copies the tablet information")
 	Main.Flags().BoolVar(&doRoutingRules, "do-routing-rules", doRoutingRules, "copies the routing rules")
+	Main.Flags().BoolVar(&verify, "verify", verify, "compares the files of both topologies and prints the differences as JSON")
+	Main.Flags().BoolVar(&verifyVersions, "verify-versions", verifyVersions, "with --verify, also reports files whose versions differ. Versions are only comparable if the destination was restored from a backup of the source.")
+	Main.Flags().BoolVar(&watch, "watch", watch, "after copying the data, keeps streaming the changes made to the source topology into the destination until interrupted")
 
 	acl.RegisterFlags(Main.Flags())
 	grpccommon.RegisterFlags(Main.Flags())
@@ -81,6 +102,10 @@ func run(cmd *cobra.Command, args []string) error {
 	defer logutil.Flush()
 	servenv.Init()
 
+	if !compare && !verify && fromServerAddress == toServerAddress && topo.RootsOverlap(fromRoot, toRoot) {
+		return fmt.Errorf("cannot copy data between the overlapping roots %q and %q of %v", fromRoot, toRoot, fromServerAddress)
+	}
+
 	fromTS, err := topo.OpenServer(fromImplementation, fromServerAddress, fromRoot)
 	if err != nil {
 		return fmt.Errorf("Cannot open 'from' topo %v: %w", fromImplementation, err)
@@ -95,6 +120,12 @@ func run(cmd *cobra.Command, args []string) error {
 	if compare {
 		return compareTopos(ctx, fromTS, toTS)
 	}
+	if verify {
+		return verifyTopos(ctx, fromTS, toTS)
+	}
+	if watch {
+		return syncTopos(ctx, fromTS, toTS)
+	}
 
 	parser, err := sqlparser.New(sqlparser.Options{
 		MySQLServerVersion: servenv.MySQLServerVersion(),
@@ -138,6 +169,68 @@ func copyTopos(ctx context.Context, fromTS, toTS *topo.Server, parser *sqlparser
 	return nil
 }
 
+func syncTopos(ctx context.Context, fromTS, toTS *topo.Server) error {
+	ctx, cancel := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	cells, err := fromTS.GetCellInfoNames(ctx)
+	if err != nil {
+		return fmt.Errorf("GetCellInfoNames(): %w", err)
+	}
+	return helpers.SyncTopos(ctx, fromTS, toTS, cells, fileFilter)
+}
+
+func verifyTopos(ctx context.Context, fromTS, toTS *topo.Server) error {
+	cells, err := fromTS.GetCellInfoNames(ctx)
+	if err != nil {
+		return fmt.Errorf("GetCellInfoNames(): %w", err)
+	}
+	report, err := helpers.DiffTopos(ctx, fromTS, toTS, cells, fileFilter, verifyVersions)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(data))
+
+	if len(report.Diffs) > 0 {
+		return fmt.Errorf("topologies differ: found %d differences in %d files", len(report.Diffs), report.FilesCompared)
+	}
+	return nil
+}
+
+// fileFilter selects the files watched by syncTopos and compared by
+// verifyTopos according to the --do-* flags.
+func fileFilter(cell, filePath string) bool {
+	parts := strings.Split(filePath, "/")
+	if cell == topo.GlobalCell {
+		switch {
+		// keyspaces/<keyspace>/{Keyspace,VSchema}
+		case len(parts) == 3 && parts[0] == topo.KeyspacesPath && (parts[2] == topo.KeyspaceFile || parts[2] == topo.VSchemaFile):
+			return doKeyspaces
+		// keyspaces/<keyspace>/shards/<shard>/Shard
+		case len(parts) == 5 && parts[0] == topo.KeyspacesPath && parts[2] == topo.ShardsPath && parts[4] == topo.ShardFile:
+			return doShards
+		case filePath == topo.RoutingRulesFile:
+			return doRoutingRules
+		}
+		return false
+	}
+
+	switch {
+	// keyspaces/<keyspace>/shards/<shard>/ShardReplication
+	case len(parts) == 5 && parts[0] == topo.KeyspacesPath && parts[2] == topo.ShardsPath && parts[4] == topo.ShardReplicationFile:
+		return doShardReplications
+	// tablets/<alias>/Tablet
+	case len(parts) == 3 && parts[0] == topo.TabletsPath && parts[2] == topo.TabletFile:
+		return doTablets
+	}
+	return false
+}
+
 func compareTopos(ctx context.Context, fromTS, toTS *topo.Server) (err error) {
 	if doKeyspaces {
 		err = helpers.CompareKeyspaces(ctx, fromTS, toTS)
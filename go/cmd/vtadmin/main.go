@@ -17,6 +17,7 @@ limitations under the License.
 package main
 
 import (
+	"crypto/tls"
 	"flag"
 	"io"
 	"time"
@@ -36,6 +37,7 @@ import (
 	"vitess.io/vitess/go/vt/vtadmin/http/debug"
 	"vitess.io/vitess/go/vt/vtadmin/rbac"
 	"vitess.io/vitess/go/vt/vtenv"
+	"vitess.io/vitess/go/vt/vttls"
 )
 
 var (
@@ -52,6 +54,10 @@ var (
 
 	cacheRefreshKey string
 
+	componentTLSCA   string
+	componentTLSCert string
+	componentTLSKey  string
+
 	traceCloser io.Closer = &noopCloser{}
 
 	rootCmd = &cobra.Command{
@@ -133,6 +139,15 @@ func run(cmd *cobra.Command, args []string) {
 		clusters[i] = cluster
 	}
 
+	if componentTLSCA != "" || componentTLSCert != "" {
+		tlsConfig, err := vttls.ClientConfig(vttls.VerifyIdentity, componentTLSCert, componentTLSKey, componentTLSCA, "", "", tls.VersionTLS12)
+		if err != nil {
+			bootSpan.Finish()
+			fatal(err)
+		}
+		httpOpts.ComponentTLSConfig = tlsConfig
+	}
+
 	if cacheRefreshKey == "" {
 		log.Warningf("no cache-refresh-key set; forcing cache refreshes will not be possible")
 	}
@@ -196,6 +211,10 @@ func main() {
 			"address for a tablet. Currently used to make passthrough "+
 			"requests to /debug/vars endpoints.",
 	)
+	rootCmd.Flags().DurationVar(&httpOpts.ComponentTimeout, "http-component-timeout", 10*time.Second, "timeout of the requests to the http servers of the tablets, vtgates and vtctlds, e.g. for their component info and their config")
+	rootCmd.Flags().StringVar(&componentTLSCA, "http-component-tls-ca", "", "path to the CA the certificates of the http servers of the tablets, vtgates and vtctlds are verified with. If set, or if --http-component-tls-cert is, their addresses without a scheme are reached over https")
+	rootCmd.Flags().StringVar(&componentTLSCert, "http-component-tls-cert", "", "path to the client certificate presented to the http servers of the tablets, vtgates and vtctlds")
+	rootCmd.Flags().StringVar(&componentTLSKey, "http-component-tls-key", "", "path to the key of --http-component-tls-cert")
 
 	// RBAC flags
 	rootCmd.Flags().StringVar(&rbacConfigPath, "rbac-config", "", "path to an RBAC config file. must be set if passing --rbac")
@@ -0,0 +1,65 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// ErrNotConfirmed is returned by Confirm when the user declines.
+var ErrNotConfirmed = errors.New("aborted")
+
+// Confirm asks the user to confirm an action by answering yes on stdin, and
+// returns ErrNotConfirmed if the user declines. It only prompts when stdin is
+// a terminal: non-interactive callers, like scripts, proceed without being
+// asked, so they never block on a prompt nobody will answer.
+func Confirm(prompt string) error {
+	if !Interactive() {
+		return nil
+	}
+
+	return confirm(os.Stdin, os.Stderr, prompt)
+}
+
+// Interactive returns whether stdin is a terminal, in which case Confirm
+// prompts the user.
+func Interactive() bool {
+	return term.IsTerminal(int(os.Stdin.Fd()))
+}
+
+func confirm(r io.Reader, w io.Writer, prompt string) error {
+	fmt.Fprintf(w, "%s [y/N]: ", prompt)
+
+	answer, err := bufio.NewReader(r).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return nil
+	default:
+		return ErrNotConfirmed
+	}
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"vitess.io/vitess/go/cmd/vtctldclient/cli"
+
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+)
+
+var (
+	// ApproveCommand makes an ApproveCommand gRPC call to a vtctld.
+	ApproveCommand = &cobra.Command{
+		Use:   "ApproveCommand <id>",
+		Short: "Approves a destructive command that another caller ran.",
+		Long: `Approves a destructive command that another caller ran.
+
+When vtctld runs with --command_approval_required, the destructive commands of
+the listed classes fail with the id of an approval the first time they are run.
+Once a second authenticated caller approves it, running the same command again,
+before the approval expires, executes it.`,
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(1),
+		RunE:                  commandApproveCommand,
+	}
+	// GetCommandApprovals makes a GetCommandApprovals gRPC call to a vtctld.
+	GetCommandApprovals = &cobra.Command{
+		Use:                   "GetCommandApprovals",
+		Short:                 "Outputs the approvals of destructive commands, and their audit history.",
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.NoArgs,
+		RunE:                  commandGetCommandApprovals,
+	}
+)
+
+func commandApproveCommand(cmd *cobra.Command, args []string) error {
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.ApproveCommand(commandCtx, &vtctldatapb.ApproveCommandRequest{
+		Id: cmd.Flags().Arg(0),
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp.Approval)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+
+	return nil
+}
+
+func commandGetCommandApprovals(cmd *cobra.Command, args []string) error {
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.GetCommandApprovals(commandCtx, &vtctldatapb.GetCommandApprovalsRequest{})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+
+	return nil
+}
+
+func init() {
+	Root.AddCommand(ApproveCommand)
+	Root.AddCommand(GetCommandApprovals)
+}
@@ -76,6 +76,18 @@ If no replica-type tablet can be found, the backup can be taken on the primary i
 		Args:                  cobra.ExactArgs(1),
 		RunE:                  commandRestoreFromBackup,
 	}
+	// RestoreTopoFromBackups makes a RestoreTopoFromBackups gRPC call to a vtctld.
+	RestoreTopoFromBackups = &cobra.Command{
+		Use:   "RestoreTopoFromBackups [--dry-run] <keyspace> <shard> [<shard> ...]",
+		Short: "Recreates the keyspace, shard and vschema records missing from the topo from those kept in the backups of the given shards.",
+		Long: `Recreates the keyspace, shard and vschema records missing from the topo from those kept in the backups of the given shards.
+
+The records of the most recent backup of each shard are compared to those of the topo. Records that differ are reported, and left unchanged.
+With --dry-run, the missing records are only reported.`,
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.MinimumNArgs(2),
+		RunE:                  commandRestoreTopoFromBackups,
+	}
 )
 
 var backupOptions = struct {
@@ -278,6 +290,31 @@ func commandRestoreFromBackup(cmd *cobra.Command, args []string) error {
 	}
 }
 
+var restoreTopoFromBackupsOptions = struct {
+	DryRun bool
+}{}
+
+func commandRestoreTopoFromBackups(cmd *cobra.Command, args []string) error {
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.RestoreTopoFromBackups(commandCtx, &vtctldatapb.RestoreTopoFromBackupsRequest{
+		Keyspace: cmd.Flags().Arg(0),
+		Shards:   cmd.Flags().Args()[1:],
+		DryRun:   restoreTopoFromBackupsOptions.DryRun,
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+	return nil
+}
+
 func init() {
 	Backup.Flags().BoolVar(&backupOptions.AllowPrimary, "allow-primary", false, "Allow the primary of a shard to be used for the backup. WARNING: If using the builtin backup engine, this will shutdown mysqld on the primary and stop writes for the duration of the backup.")
 	Backup.Flags().Int32Var(&backupOptions.Concurrency, "concurrency", 4, "Specifies the number of compression/checksum jobs to run simultaneously.")
@@ -303,4 +340,7 @@ func init() {
 	RestoreFromBackup.Flags().StringVar(&restoreFromBackupOptions.RestoreToTimestamp, "restore-to-timestamp", "", "Run a point in time recovery that restores up to, and excluding, given timestamp in RFC3339 format (`2006-01-02T15:04:05Z07:00`). This will attempt to use one full backup followed by zero or more incremental backups")
 	RestoreFromBackup.Flags().BoolVar(&restoreFromBackupOptions.DryRun, "dry-run", false, "Only validate restore steps, do not actually restore data")
 	Root.AddCommand(RestoreFromBackup)
+
+	RestoreTopoFromBackups.Flags().BoolVar(&restoreTopoFromBackupsOptions.DryRun, "dry-run", false, "Only report the records that are missing or differ, do not create any.")
+	Root.AddCommand(RestoreTopoFromBackups)
 }
@@ -44,17 +44,36 @@ be stored starting at the provided root.`,
 	}
 	// AddCellsAlias makes an AddCellsAlias gRPC call to a vtctld.
 	AddCellsAlias = &cobra.Command{
-		Use:   "AddCellsAlias --cells <cell1,cell2,...> [--cells <cell3> ...] <alias>",
+		Use:   "AddCellsAlias --cells <cell1,cell2,...> [--cells <cell3> ...] [--dry-run] [--yes] <alias>",
 		Short: "Defines a group of cells that can be referenced by a single name (the alias).",
 		Long: `Defines a group of cells that can be referenced by a single name (the alias).
 
 When routing query traffic, replica/rdonly traffic can be routed across cells
 within the group (alias). Only primary traffic can be routed across cells not in
-the same group (alias).`,
+the same group (alias).
+
+The keyspaces whose routing changes as a result are printed, and if there are
+any and stdin is a terminal, the change must be confirmed before it is applied.`,
 		DisableFlagsInUseLine: true,
 		Args:                  cobra.ExactArgs(1),
 		RunE:                  commandAddCellsAlias,
 	}
+	// DecommissionCell makes a DecommissionCell gRPC call to a vtctld.
+	DecommissionCell = &cobra.Command{
+		Use:   "DecommissionCell [--dry-run] <cell>",
+		Short: "Removes a cell that no tablet or replication stream uses anymore.",
+		Long: `Removes a cell that no tablet or replication stream uses anymore.
+
+The cell is first checked for tablets, and for replication streams that run in
+it or read from it, which all must be deleted or moved to other cells. The cell
+is then removed from the cells aliases it is in, the files of its cell-local
+topology are deleted, and its CellInfo is deleted.
+
+With --dry-run, the steps are only printed.`,
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(1),
+		RunE:                  commandDecommissionCell,
+	}
 	// DeleteCellInfo makes a DeleteCellInfo gRPC call to a vtctld.
 	DeleteCellInfo = &cobra.Command{
 		Use:                   "DeleteCellInfo [--force] <cell>",
@@ -66,9 +85,12 @@ the same group (alias).`,
 	}
 	// DeleteCellsAlias makes a DeleteCellsAlias gRPC call to a vtctld.
 	DeleteCellsAlias = &cobra.Command{
-		Use:                   "DeleteCellsAlias <alias>",
-		Short:                 "Deletes the CellsAlias for the provided alias.",
-		Long:                  "Deletes the CellsAlias for the provided alias.",
+		Use:   "DeleteCellsAlias [--dry-run] [--yes] <alias>",
+		Short: "Deletes the CellsAlias for the provided alias.",
+		Long: `Deletes the CellsAlias for the provided alias.
+
+The keyspaces whose routing changes as a result are printed, and if there are
+any and stdin is a terminal, the deletion must be confirmed before it is applied.`,
 		DisableFlagsInUseLine: true,
 		Args:                  cobra.ExactArgs(1),
 		RunE:                  commandDeleteCellsAlias,
@@ -110,9 +132,12 @@ If a value is empty, it is ignored.`,
 	}
 	// UpdateCellsAlias makes an UpdateCellsAlias gRPC call to a vtctld.
 	UpdateCellsAlias = &cobra.Command{
-		Use:                   "UpdateCellsAlias [--cells <cell1,cell2,...> [--cells <cell4> ...]] <alias>",
-		Short:                 "Updates the content of a CellsAlias with the provided parameters, creating the CellsAlias if it does not exist.",
-		Long:                  "Updates the content of a CellsAlias with the provided parameters, creating the CellsAlias if it does not exist.",
+		Use:   "UpdateCellsAlias [--cells <cell1,cell2,...> [--cells <cell4> ...]] [--dry-run] [--yes] <alias>",
+		Short: "Updates the content of a CellsAlias with the provided parameters, creating the CellsAlias if it does not exist.",
+		Long: `Updates the content of a CellsAlias with the provided parameters, creating the CellsAlias if it does not exist.
+
+The keyspaces whose routing changes as a result are printed, and if there are
+any and stdin is a terminal, the change must be confirmed before it is applied.`,
 		DisableFlagsInUseLine: true,
 		Args:                  cobra.ExactArgs(1),
 		RunE:                  commandUpdateCellsAlias,
@@ -137,24 +162,136 @@ func commandAddCellInfo(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-var addCellsAliasOptions topodatapb.CellsAlias
+// cellsAliasChangeOptions holds the flags shared by the commands that change
+// a CellsAlias.
+type cellsAliasChangeOptions struct {
+	DryRun bool
+	Yes    bool
+}
+
+func (opts *cellsAliasChangeOptions) addFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&opts.DryRun, "dry-run", false, "Only report which keyspaces' routing would change, without applying the change.")
+	cmd.Flags().BoolVar(&opts.Yes, "yes", false, "Apply the change without asking for confirmation on a terminal, even if it changes the routing of some keyspaces.")
+}
+
+// preview returns whether a cells alias change must first be sent as a dry
+// run: when --dry-run was passed, or when the change may have to be
+// confirmed on a terminal.
+func (opts *cellsAliasChangeOptions) preview() bool {
+	return opts.DryRun || (!opts.Yes && cli.Interactive())
+}
+
+// confirm prints the impact of a cells alias change, as reported by a dry
+// run, and returns whether the change should be applied. Changes that affect
+// the routing of any keyspace must be confirmed on a terminal, unless --yes
+// was passed.
+func (opts *cellsAliasChangeOptions) confirm(alias string, impact *vtctldatapb.CellsAliasImpact) (bool, error) {
+	if impact == nil {
+		// The vtctlds which predate the dry runs of cells alias changes
+		// ignore them, and apply the change.
+		return false, fmt.Errorf("the vtctld does not support dry runs of cells alias changes, so it applied the change to %s without confirmation; pass --yes to change cells aliases through it", alias)
+	}
+
+	if err := printCellsAliasImpact(alias, impact); err != nil {
+		return false, err
+	}
+
+	switch {
+	case opts.DryRun:
+		return false, nil
+	case opts.Yes, len(impact.GetAffectedKeyspaces()) == 0:
+		return true, nil
+	}
+
+	prompt := fmt.Sprintf("Routing of keyspaces %s will change. Apply?", strings.Join(impact.AffectedKeyspaces, ", "))
+	if err := cli.Confirm(prompt); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// printCellsAliasImpact prints the impact of a cells alias change. The
+// vtctlds which predate the impact of the changes don't report it.
+func printCellsAliasImpact(alias string, impact *vtctldatapb.CellsAliasImpact) error {
+	if impact == nil {
+		return nil
+	}
+
+	data, err := cli.MarshalJSON(impact)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Impact of changing cells alias %s:\n%s\n", alias, data)
+	return nil
+}
+
+var addCellsAliasOptions = struct {
+	cellsAliasChangeOptions
+	Cells []string
+}{}
 
 func commandAddCellsAlias(cmd *cobra.Command, args []string) error {
 	cli.FinishedParsing(cmd)
 
 	alias := cmd.Flags().Arg(0)
-	_, err := client.AddCellsAlias(commandCtx, &vtctldatapb.AddCellsAliasRequest{
-		Name:  alias,
-		Cells: addCellsAliasOptions.Cells,
-	})
+	req := &vtctldatapb.AddCellsAliasRequest{
+		Name:   alias,
+		Cells:  addCellsAliasOptions.Cells,
+		DryRun: addCellsAliasOptions.preview(),
+	}
+	resp, err := client.AddCellsAlias(commandCtx, req)
 	if err != nil {
 		return err
 	}
 
+	if req.DryRun {
+		apply, err := addCellsAliasOptions.confirm(alias, resp.Impact)
+		if err != nil || !apply {
+			return err
+		}
+
+		req.DryRun = false
+		if _, err = client.AddCellsAlias(commandCtx, req); err != nil {
+			return err
+		}
+	} else if err = printCellsAliasImpact(alias, resp.Impact); err != nil {
+		return err
+	}
+
 	fmt.Printf("Created cells alias: %s (cells = %v)\n", alias, addCellsAliasOptions.Cells)
 	return nil
 }
 
+var decommissionCellOptions = struct {
+	DryRun bool
+}{}
+
+func commandDecommissionCell(cmd *cobra.Command, args []string) error {
+	cli.FinishedParsing(cmd)
+
+	cell := cmd.Flags().Arg(0)
+	resp, err := client.DecommissionCell(commandCtx, &vtctldatapb.DecommissionCellRequest{
+		Cell:   cell,
+		DryRun: decommissionCellOptions.DryRun,
+	})
+	if err != nil {
+		return err
+	}
+
+	for i, step := range resp.Steps {
+		fmt.Printf("%d. %s\n", i+1, step)
+	}
+	switch {
+	case resp.Decommissioned:
+		fmt.Printf("Decommissioned cell %s\n", cell)
+	case len(resp.Tablets) > 0 || len(resp.Streams) > 0:
+		return fmt.Errorf("cell %s is still in use by %d tablets and %d streams", cell, len(resp.Tablets), len(resp.Streams))
+	}
+	return nil
+}
+
 var deleteCellInfoOptions = struct {
 	Force bool
 }{}
@@ -175,17 +312,35 @@ func commandDeleteCellInfo(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+var deleteCellsAliasOptions cellsAliasChangeOptions
+
 func commandDeleteCellsAlias(cmd *cobra.Command, args []string) error {
 	cli.FinishedParsing(cmd)
 
 	alias := cmd.Flags().Arg(0)
-	_, err := client.DeleteCellsAlias(commandCtx, &vtctldatapb.DeleteCellsAliasRequest{
-		Name: alias,
-	})
+	req := &vtctldatapb.DeleteCellsAliasRequest{
+		Name:   alias,
+		DryRun: deleteCellsAliasOptions.preview(),
+	}
+	resp, err := client.DeleteCellsAlias(commandCtx, req)
 	if err != nil {
 		return err
 	}
 
+	if req.DryRun {
+		apply, err := deleteCellsAliasOptions.confirm(alias, resp.Impact)
+		if err != nil || !apply {
+			return err
+		}
+
+		req.DryRun = false
+		if _, err = client.DeleteCellsAlias(commandCtx, req); err != nil {
+			return err
+		}
+	} else if err = printCellsAliasImpact(alias, resp.Impact); err != nil {
+		return err
+	}
+
 	fmt.Printf("Delete cells alias %s\n", alias)
 	return nil
 }
@@ -264,20 +419,40 @@ func commandUpdateCellInfo(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-var updateCellsAliasOptions topodatapb.CellsAlias
+var updateCellsAliasOptions = struct {
+	cellsAliasChangeOptions
+	Cells []string
+}{}
 
 func commandUpdateCellsAlias(cmd *cobra.Command, args []string) error {
 	cli.FinishedParsing(cmd)
 
 	alias := cmd.Flags().Arg(0)
-	resp, err := client.UpdateCellsAlias(commandCtx, &vtctldatapb.UpdateCellsAliasRequest{
+	req := &vtctldatapb.UpdateCellsAliasRequest{
 		Name:       alias,
-		CellsAlias: &updateCellsAliasOptions,
-	})
+		CellsAlias: &topodatapb.CellsAlias{Cells: updateCellsAliasOptions.Cells},
+		DryRun:     updateCellsAliasOptions.preview(),
+	}
+	resp, err := client.UpdateCellsAlias(commandCtx, req)
 	if err != nil {
 		return err
 	}
 
+	if req.DryRun {
+		apply, err := updateCellsAliasOptions.confirm(alias, resp.Impact)
+		if err != nil || !apply {
+			return err
+		}
+
+		req.DryRun = false
+		resp, err = client.UpdateCellsAlias(commandCtx, req)
+		if err != nil {
+			return err
+		}
+	} else if err = printCellsAliasImpact(alias, resp.Impact); err != nil {
+		return err
+	}
+
 	data, err := cli.MarshalJSON(resp.CellsAlias)
 	if err != nil {
 		return err
@@ -294,10 +469,15 @@ func init() {
 	Root.AddCommand(AddCellInfo)
 
 	AddCellsAlias.Flags().StringSliceVarP(&addCellsAliasOptions.Cells, "cells", "c", nil, "The list of cell names that are members of this alias.")
+	addCellsAliasOptions.addFlags(AddCellsAlias)
 	Root.AddCommand(AddCellsAlias)
 
+	DecommissionCell.Flags().BoolVar(&decommissionCellOptions.DryRun, "dry-run", false, "Only print the steps to remove the cell.")
+	Root.AddCommand(DecommissionCell)
+
 	DeleteCellInfo.Flags().BoolVarP(&deleteCellInfoOptions.Force, "force", "f", false, "Proceeds even if the cell's topology server cannot be reached. The assumption is that you shut down the entire cell, and just need to update the global topo data.")
 	Root.AddCommand(DeleteCellInfo)
+	deleteCellsAliasOptions.addFlags(DeleteCellsAlias)
 	Root.AddCommand(DeleteCellsAlias)
 
 	Root.AddCommand(GetCellInfoNames)
@@ -309,5 +489,6 @@ func init() {
 	Root.AddCommand(UpdateCellInfo)
 
 	UpdateCellsAlias.Flags().StringSliceVarP(&updateCellsAliasOptions.Cells, "cells", "c", nil, "The list of cell names that are members of this alias.")
+	updateCellsAliasOptions.addFlags(UpdateCellsAlias)
 	Root.AddCommand(UpdateCellsAlias)
 }
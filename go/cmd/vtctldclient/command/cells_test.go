@@ -0,0 +1,71 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command_test
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/cmd/vtctldclient/command"
+	"vitess.io/vitess/go/vt/vtctl/localvtctldclient"
+
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+	vtctlservicepb "vitess.io/vitess/go/vt/proto/vtctlservice"
+)
+
+// dryRunIgnoringServer is a vtctld which predates the dry runs of cells
+// alias changes: it applies every change, and reports no impact.
+type dryRunIgnoringServer struct {
+	vtctlservicepb.UnimplementedVtctldServer
+	requests []*vtctldatapb.DeleteCellsAliasRequest
+}
+
+func (s *dryRunIgnoringServer) DeleteCellsAlias(ctx context.Context, req *vtctldatapb.DeleteCellsAliasRequest) (*vtctldatapb.DeleteCellsAliasResponse, error) {
+	s.requests = append(s.requests, req.CloneVT())
+	return &vtctldatapb.DeleteCellsAliasResponse{}, nil
+}
+
+func TestDeleteCellsAliasDryRun(t *testing.T) {
+	server := &dryRunIgnoringServer{}
+	localvtctldclient.SetServer(server)
+
+	args := append([]string{}, os.Args...)
+	protocol := command.VtctldClientProtocol
+	t.Cleanup(func() {
+		os.Args = append([]string{}, args...)
+		command.VtctldClientProtocol = protocol
+	})
+	command.VtctldClientProtocol = "local"
+
+	// Without a terminal to confirm the change on, it is applied directly.
+	os.Args = []string{"vtctldclient", "--server", "local", "DeleteCellsAlias", "alias1"}
+	require.NoError(t, command.Root.Execute())
+	require.Len(t, server.requests, 1)
+	assert.False(t, server.requests[0].DryRun)
+
+	// The change a dry run of an older vtctld applied is reported, and not
+	// applied again.
+	os.Args = []string{"vtctldclient", "--server", "local", "DeleteCellsAlias", "--dry-run", "alias2"}
+	err := command.Root.Execute()
+	assert.ErrorContains(t, err, "does not support dry runs")
+	require.Len(t, server.requests, 2)
+	assert.True(t, server.requests[1].DryRun)
+}
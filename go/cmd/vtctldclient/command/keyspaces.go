@@ -78,10 +78,18 @@ Otherwise, the keyspace must be empty (have no shards), or returns an error.`,
 		Args:                  cobra.ExactArgs(1),
 		RunE:                  commandGetKeyspace,
 	}
+	// GetKeyspaceLabels makes a GetKeyspaceLabels gRPC call to a vtctld.
+	GetKeyspaceLabels = &cobra.Command{
+		Use:                   "GetKeyspaceLabels <keyspace>",
+		Short:                 "Returns the labels of the given keyspace.",
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(1),
+		RunE:                  commandGetKeyspaceLabels,
+	}
 	// GetKeyspaces makes a GetKeyspaces gRPC call to a vtctld.
 	GetKeyspaces = &cobra.Command{
-		Use:                   "GetKeyspaces",
-		Short:                 "Returns information about every keyspace in the topology.",
+		Use:                   "GetKeyspaces [--label <key>=<value> ...]",
+		Short:                 "Returns information about every keyspace in the topology, or those with the given labels.",
 		DisableFlagsInUseLine: true,
 		Aliases:               []string{"getkeyspaces"},
 		Args:                  cobra.NoArgs,
@@ -109,6 +117,23 @@ SetKeyspaceDurabilityPolicy --durability-policy='semi_sync' customer`,
 		Args:                  cobra.ExactArgs(1),
 		RunE:                  commandSetKeyspaceDurabilityPolicy,
 	}
+	// SetKeyspaceLabels makes a SetKeyspaceLabels gRPC call to a vtctld.
+	SetKeyspaceLabels = &cobra.Command{
+		Use:   "SetKeyspaceLabels [--remove <key> ...] <keyspace> [<key>=<value> ...]",
+		Short: "Adds, updates and removes the labels of the specified keyspace.",
+		Long: `Adds, updates and removes the labels of the specified keyspace.
+
+Labels are free-form metadata, such as the owner, tier or cost center of the
+keyspace. Vitess does not interpret them, but GetKeyspaces and VTAdmin can
+filter keyspaces by them.
+
+To set the owner of the customer keyspace, and remove its tier, you would use
+the following command:
+SetKeyspaceLabels --remove tier customer owner=payments`,
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.MinimumNArgs(1),
+		RunE:                  commandSetKeyspaceLabels,
+	}
 	// ValidateSchemaKeyspace makes a ValidateSchemaKeyspace gRPC call to a vtctld.
 	ValidateSchemaKeyspace = &cobra.Command{
 		Use:                   "ValidateSchemaKeyspace [--exclude-tables=<exclude_tables>] [--include-views] [--skip-no-primary] [--include-vschema] <keyspace>",
@@ -280,10 +305,36 @@ func commandGetKeyspace(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+func commandGetKeyspaceLabels(cmd *cobra.Command, args []string) error {
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.GetKeyspaceLabels(commandCtx, &vtctldatapb.GetKeyspaceLabelsRequest{
+		Keyspace: cmd.Flags().Arg(0),
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp.Labels)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+
+	return nil
+}
+
+var getKeyspacesOptions = struct {
+	Labels map[string]string
+}{}
+
 func commandGetKeyspaces(cmd *cobra.Command, args []string) error {
 	cli.FinishedParsing(cmd)
 
-	resp, err := client.GetKeyspaces(commandCtx, &vtctldatapb.GetKeyspacesRequest{})
+	resp, err := client.GetKeyspaces(commandCtx, &vtctldatapb.GetKeyspacesRequest{
+		Labels: getKeyspacesOptions.Labels,
+	})
 	if err != nil {
 		return err
 	}
@@ -350,6 +401,52 @@ func commandSetKeyspaceDurabilityPolicy(cmd *cobra.Command, args []string) error
 	return nil
 }
 
+var setKeyspaceLabelsOptions = struct {
+	RemoveLabels []string
+}{}
+
+func commandSetKeyspaceLabels(cmd *cobra.Command, args []string) error {
+	keyspace := cmd.Flags().Arg(0)
+	labels, err := parseLabels(cmd.Flags().Args()[1:])
+	if err != nil {
+		return err
+	}
+	if len(labels) == 0 && len(setKeyspaceLabelsOptions.RemoveLabels) == 0 {
+		return errors.New("no labels to set or remove")
+	}
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.SetKeyspaceLabels(commandCtx, &vtctldatapb.SetKeyspaceLabelsRequest{
+		Keyspace:     keyspace,
+		Labels:       labels,
+		RemoveLabels: setKeyspaceLabelsOptions.RemoveLabels,
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+	return nil
+}
+
+// parseLabels parses the <key>=<value> labels of the arguments of a command.
+func parseLabels(args []string) (map[string]string, error) {
+	labels := make(map[string]string, len(args))
+	for _, arg := range args {
+		key, value, ok := strings.Cut(arg, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid label %q, expected <key>=<value>", arg)
+		}
+		labels[key] = value
+	}
+	return labels, nil
+}
+
 var validateSchemaKeyspaceOptions = struct {
 	ExcludeTables  []string
 	IncludeViews   bool
@@ -419,6 +516,9 @@ func init() {
 
 	Root.AddCommand(FindAllShardsInKeyspace)
 	Root.AddCommand(GetKeyspace)
+	Root.AddCommand(GetKeyspaceLabels)
+
+	GetKeyspaces.Flags().StringToStringVar(&getKeyspacesOptions.Labels, "label", nil, "Only returns the keyspaces with this label, as <key>=<value>. May be repeated, in which case the keyspaces must have all the labels.")
 	Root.AddCommand(GetKeyspaces)
 
 	RemoveKeyspaceCell.Flags().BoolVarP(&removeKeyspaceCellOptions.Force, "force", "f", false, "Proceed even if the cell's topology server cannot be reached. The assumption is that you turned down the entire cell, and just need to update the global topo data.")
@@ -428,6 +528,9 @@ func init() {
 	SetKeyspaceDurabilityPolicy.Flags().StringVar(&setKeyspaceDurabilityPolicyOptions.DurabilityPolicy, "durability-policy", "none", "Type of durability to enforce for this keyspace. Default is none. Other values include 'semi_sync' and others as dictated by registered plugins.")
 	Root.AddCommand(SetKeyspaceDurabilityPolicy)
 
+	SetKeyspaceLabels.Flags().StringSliceVar(&setKeyspaceLabelsOptions.RemoveLabels, "remove", nil, "The key of a label to remove from the keyspace. May be repeated.")
+	Root.AddCommand(SetKeyspaceLabels)
+
 	ValidateSchemaKeyspace.Flags().BoolVar(&validateSchemaKeyspaceOptions.IncludeViews, "include-views", false, "Includes views in compared schemas.")
 	ValidateSchemaKeyspace.Flags().BoolVar(&validateSchemaKeyspaceOptions.IncludeVSchema, "include-vschema", false, "Includes VSchema validation in validation results.")
 	ValidateSchemaKeyspace.Flags().BoolVar(&validateSchemaKeyspaceOptions.SkipNoPrimary, "skip-no-primary", false, "Skips validation on whether or not a primary exists in shards.")
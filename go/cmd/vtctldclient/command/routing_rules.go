@@ -48,6 +48,14 @@ var (
 		Args:                  cobra.NoArgs,
 		RunE:                  commandGetRoutingRules,
 	}
+	// SwapRoutingRules makes a SwapRoutingRules gRPC call to a vtctld.
+	SwapRoutingRules = &cobra.Command{
+		Use:                   "SwapRoutingRules {--rules-file RULES_FILE | --shard-rules-file SHARD_RULES_FILE} [--cells=c1,c2,...] [--skip-rebuild] [--dry-run]",
+		Short:                 "Replaces the VSchema routing rules and/or shard routing rules as a whole, under the routing rules lock, and displays the changes made to them.",
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.NoArgs,
+		RunE:                  commandSwapRoutingRules,
+	}
 )
 
 var applyRoutingRulesOptions = struct {
@@ -145,6 +153,71 @@ func commandGetRoutingRules(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+var swapRoutingRulesOptions = struct {
+	RulesFilePath      string
+	ShardRulesFilePath string
+	Cells              []string
+	SkipRebuild        bool
+	DryRun             bool
+}{}
+
+func commandSwapRoutingRules(cmd *cobra.Command, args []string) error {
+	if swapRoutingRulesOptions.RulesFilePath == "" && swapRoutingRulesOptions.ShardRulesFilePath == "" {
+		return errors.New("must pass at least one of --rules-file or --shard-rules-file")
+	}
+
+	cli.FinishedParsing(cmd)
+
+	req := &vtctldatapb.SwapRoutingRulesRequest{
+		DryRun:       swapRoutingRulesOptions.DryRun,
+		SkipRebuild:  swapRoutingRulesOptions.SkipRebuild,
+		RebuildCells: swapRoutingRulesOptions.Cells,
+	}
+	if swapRoutingRulesOptions.RulesFilePath != "" {
+		data, err := os.ReadFile(swapRoutingRulesOptions.RulesFilePath)
+		if err != nil {
+			return err
+		}
+		req.RoutingRules = &vschemapb.RoutingRules{}
+		if err := json2.UnmarshalPB(data, req.RoutingRules); err != nil {
+			return err
+		}
+	}
+	if swapRoutingRulesOptions.ShardRulesFilePath != "" {
+		data, err := os.ReadFile(swapRoutingRulesOptions.ShardRulesFilePath)
+		if err != nil {
+			return err
+		}
+		req.ShardRoutingRules = &vschemapb.ShardRoutingRules{}
+		if err := json2.UnmarshalPB(data, req.ShardRoutingRules); err != nil {
+			return err
+		}
+	}
+
+	resp, err := client.SwapRoutingRules(commandCtx, req)
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp)
+	if err != nil {
+		return err
+	}
+
+	if swapRoutingRulesOptions.DryRun {
+		fmt.Printf("[DRY RUN] Would have made the following changes:\n%s\n", data)
+		return nil
+	}
+
+	fmt.Printf("Made the following changes:\n%s\n", data)
+
+	if swapRoutingRulesOptions.SkipRebuild {
+		fmt.Println("Skipping rebuild of VSchema graph, will need to run RebuildVSchemaGraph for changes to take effect.")
+	}
+
+	return nil
+}
+
 func init() {
 	ApplyRoutingRules.Flags().StringVarP(&applyRoutingRulesOptions.Rules, "rules", "r", "", "Routing rules, specified as a string.")
 	ApplyRoutingRules.Flags().StringVarP(&applyRoutingRulesOptions.RulesFilePath, "rules-file", "f", "", "Path to a file containing routing rules specified as JSON.")
@@ -154,4 +227,11 @@ func init() {
 	Root.AddCommand(ApplyRoutingRules)
 
 	Root.AddCommand(GetRoutingRules)
+
+	SwapRoutingRules.Flags().StringVarP(&swapRoutingRulesOptions.RulesFilePath, "rules-file", "f", "", "Path to a file containing the new routing rules, specified as JSON.")
+	SwapRoutingRules.Flags().StringVar(&swapRoutingRulesOptions.ShardRulesFilePath, "shard-rules-file", "", "Path to a file containing the new shard routing rules, specified as JSON.")
+	SwapRoutingRules.Flags().StringSliceVarP(&swapRoutingRulesOptions.Cells, "cells", "c", nil, "Limit the VSchema graph rebuilding to the specified cells. Ignored if --skip-rebuild is specified.")
+	SwapRoutingRules.Flags().BoolVar(&swapRoutingRulesOptions.SkipRebuild, "skip-rebuild", false, "Skip rebuilding the SrvVSchema objects.")
+	SwapRoutingRules.Flags().BoolVarP(&swapRoutingRulesOptions.DryRun, "dry-run", "d", false, "Display the changes that would be made to the rules, without making them.")
+	Root.AddCommand(SwapRoutingRules)
 }
@@ -69,7 +69,7 @@ var (
 	}
 	// RebuildKeyspaceGraph makes one or more RebuildKeyspaceGraph gRPC calls to a vtctld.
 	RebuildKeyspaceGraph = &cobra.Command{
-		Use:                   "RebuildKeyspaceGraph [--cells=c1,c2,...] [--allow-partial] ks1 [ks2 ...]",
+		Use:                   "RebuildKeyspaceGraph [--cells=c1,c2,...] [--allow-partial] [--shards=s1,s2,...] ks1 [ks2 ...]",
 		Short:                 "Rebuilds the serving data for the keyspace(s). This command may trigger an update to all connected clients.",
 		DisableFlagsInUseLine: true,
 		Args:                  cobra.MinimumNArgs(1),
@@ -192,6 +192,7 @@ func commandGetSrvVSchemas(cmd *cobra.Command, args []string) error {
 var rebuildKeyspaceGraphOptions = struct {
 	Cells        []string
 	AllowPartial bool
+	Shards       []string
 }{}
 
 func commandRebuildKeyspaceGraph(cmd *cobra.Command, args []string) error {
@@ -203,6 +204,7 @@ func commandRebuildKeyspaceGraph(cmd *cobra.Command, args []string) error {
 			Keyspace:     ks,
 			Cells:        rebuildKeyspaceGraphOptions.Cells,
 			AllowPartial: rebuildKeyspaceGraphOptions.AllowPartial,
+			Shards:       rebuildKeyspaceGraphOptions.Shards,
 		})
 		if err != nil {
 			return fmt.Errorf("RebuildKeyspaceGraph(%v) failed: %v", ks, err)
@@ -241,6 +243,7 @@ func init() {
 
 	RebuildKeyspaceGraph.Flags().StringSliceVarP(&rebuildKeyspaceGraphOptions.Cells, "cells", "c", nil, "Specifies a comma-separated list of cells to update.")
 	RebuildKeyspaceGraph.Flags().BoolVar(&rebuildKeyspaceGraphOptions.AllowPartial, "allow-partial", false, "Specifies whether a SNAPSHOT keyspace is allowed to serve with an incomplete set of shards. Ignored for all other types of keyspaces.")
+	RebuildKeyspaceGraph.Flags().StringSliceVar(&rebuildKeyspaceGraphOptions.Shards, "shards", nil, "Specifies a comma-separated list of the shards that changed, to only update their references in the existing SrvKeyspaces instead of rebuilding them from all the shards.")
 	Root.AddCommand(RebuildKeyspaceGraph)
 
 	RebuildVSchemaGraph.Flags().StringSliceVarP(&rebuildVSchemaGraphOptions.Cells, "cells", "c", nil, "Specifies a comma-separated list of cells to look for tablets.")
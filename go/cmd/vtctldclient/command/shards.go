@@ -17,6 +17,7 @@ limitations under the License.
 package command
 
 import (
+	"errors"
 	"fmt"
 	"strconv"
 
@@ -93,6 +94,14 @@ that shard.`,
 		Args:                  cobra.ExactArgs(1),
 		RunE:                  commandGetShard,
 	}
+	// GetShardLabels makes a GetShardLabels gRPC request to a vtctld.
+	GetShardLabels = &cobra.Command{
+		Use:                   "GetShardLabels <keyspace/shard>",
+		Short:                 "Returns the labels of a shard.",
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(1),
+		RunE:                  commandGetShardLabels,
+	}
 	// GetShardReplication makes a GetShardReplication gRPC request to a vtctld.
 	GetShardReplication = &cobra.Command{
 		Use:                   "GetShardReplication <keyspace/shard> [cell1 [cell2...]]",
@@ -118,6 +127,24 @@ that shard.`,
 		Args:                  cobra.ExactArgs(2),
 		RunE:                  commandSetShardIsPrimaryServing,
 	}
+	// SetShardLabels makes a SetShardLabels gRPC call to a vtctld.
+	SetShardLabels = &cobra.Command{
+		Use:   "SetShardLabels [--remove <key> ...] [--expect <key>=<value> ...] <keyspace/shard> [<key>=<value> ...]",
+		Short: "Adds, updates and removes the labels of a shard.",
+		Long: `Adds, updates and removes the labels of a shard.
+
+Labels are free-form metadata, such as the state of the operations on the
+shard. Vitess does not interpret them.
+
+With --expect, the labels are only changed if the shard has the expected
+labels, with the expected values, when the change is made. An empty expected
+value also matches a label that is not set. For example, to mark a shard as
+frozen unless another operation already set its state:
+SetShardLabels --expect state= commerce/-80 state=frozen`,
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.MinimumNArgs(1),
+		RunE:                  commandSetShardLabels,
+	}
 	// SetShardTabletControl makes a SetShardTabletControl gRPC call to a vtctld.
 	SetShardTabletControl = &cobra.Command{
 		Use:   "SetShardTabletControl [--cells=c1,c2...] [--denied-tables=t1,t2,...] [--remove] [--disable-query-service[=0|false]] <keyspace/shard> <tablet_type>",
@@ -294,6 +321,32 @@ func commandGetShard(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+func commandGetShardLabels(cmd *cobra.Command, args []string) error {
+	keyspace, shard, err := topoproto.ParseKeyspaceShard(cmd.Flags().Arg(0))
+	if err != nil {
+		return err
+	}
+
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.GetShardLabels(commandCtx, &vtctldatapb.GetShardLabelsRequest{
+		Keyspace: keyspace,
+		Shard:    shard,
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp.Labels)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+
+	return nil
+}
+
 func commandGetShardReplication(cmd *cobra.Command, args []string) error {
 	keyspace, shard, err := topoproto.ParseKeyspaceShard(cmd.Flags().Arg(0))
 	if err != nil {
@@ -387,6 +440,47 @@ func commandSetShardIsPrimaryServing(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+var setShardLabelsOptions = struct {
+	RemoveLabels   []string
+	ExpectedLabels map[string]string
+}{}
+
+func commandSetShardLabels(cmd *cobra.Command, args []string) error {
+	keyspace, shard, err := topoproto.ParseKeyspaceShard(cmd.Flags().Arg(0))
+	if err != nil {
+		return err
+	}
+	labels, err := parseLabels(cmd.Flags().Args()[1:])
+	if err != nil {
+		return err
+	}
+	if len(labels) == 0 && len(setShardLabelsOptions.RemoveLabels) == 0 {
+		return errors.New("no labels to set or remove")
+	}
+
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.SetShardLabels(commandCtx, &vtctldatapb.SetShardLabelsRequest{
+		Keyspace:       keyspace,
+		Shard:          shard,
+		Labels:         labels,
+		RemoveLabels:   setShardLabelsOptions.RemoveLabels,
+		ExpectedLabels: setShardLabelsOptions.ExpectedLabels,
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+
+	return nil
+}
+
 var setShardTabletControlOptions = struct {
 	Cells               []string
 	DeniedTables        []string
@@ -662,6 +756,7 @@ func init() {
 	Root.AddCommand(DeleteShards)
 
 	Root.AddCommand(GetShard)
+	Root.AddCommand(GetShardLabels)
 	Root.AddCommand(GetShardReplication)
 	Root.AddCommand(GenerateShardRanges)
 
@@ -671,6 +766,10 @@ func init() {
 
 	Root.AddCommand(SetShardIsPrimaryServing)
 
+	SetShardLabels.Flags().StringSliceVar(&setShardLabelsOptions.RemoveLabels, "remove", nil, "The key of a label to remove from the shard. May be repeated.")
+	SetShardLabels.Flags().StringToStringVar(&setShardLabelsOptions.ExpectedLabels, "expect", nil, "A label the shard must have, as <key>=<value>, for the labels to change. May be repeated.")
+	Root.AddCommand(SetShardLabels)
+
 	SetShardTabletControl.Flags().StringSliceVarP(&setShardTabletControlOptions.Cells, "cells", "c", nil, "Specifies a comma-separated list of cells to update.")
 	SetShardTabletControl.Flags().StringSliceVar(&setShardTabletControlOptions.DeniedTables, "denied-tables", nil, "Specifies a comma-separated list of tables to add to the denylist (for MoveTables). Each table name is either an exact match, or a regular expression of the form '/regexp/'.")
 	SetShardTabletControl.Flags().BoolVarP(&setShardTabletControlOptions.Remove, "remove", "r", false, "Removes the specified cells for MoveTables operations.")
@@ -17,16 +17,95 @@ limitations under the License.
 package command
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
+	"google.golang.org/protobuf/encoding/protojson"
 
 	"vitess.io/vitess/go/cmd/vtctldclient/cli"
+	"vitess.io/vitess/go/protoutil"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/helpers"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+	"vitess.io/vitess/go/vt/topo/watchping"
 
 	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
 )
 
 var (
+	// ExportTopology makes an ExportTopology gRPC call to a vtctld, and
+	// writes the files to an archive.
+	ExportTopology = &cobra.Command{
+		Use:   "ExportTopology [--cells <cell1,cell2,...>] [--path <path>] [--format json|yaml] [--output <file>]",
+		Short: "Exports the files of a topology subtree to a JSON or YAML archive.",
+		Long: `Exports the files of a topology subtree to a JSON or YAML archive.
+
+The records of known types are rendered as JSON in the archive, so it can be
+reviewed and edited before it is imported with ImportTopology, for example to
+clone the metadata of a production environment into a staging one.`,
+		Example:               `ExportTopology --cells global --path keyspaces/commerce --output commerce.yaml`,
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.NoArgs,
+		RunE:                  commandExportTopology,
+	}
+	// ForceUnlock makes a ForceUnlock gRPC call to a vtctld.
+	ForceUnlock = &cobra.Command{
+		Use:   "ForceUnlock [--min-age <duration>] [--reason <reason>] <path>",
+		Short: "Breaks a stale lock held on a resource of the topology, and records it in the audit log of the topology.",
+		Long: `Breaks a stale lock held on a resource of the topology, and records it in the audit log of the topology.
+
+The path is the directory of the locked resource in the global topology, as
+listed by GetLocks. The lock is only broken if it is stale, as reported by
+GetLocks: the process of its holder is gone, so the lease or session backing
+the lock is no longer kept alive, and the lock is only left until it expires.
+Locks held by live processes, even stuck ones, are never broken: stopping the
+process releases them. The lock must also have been acquired at least
+--min-age ago, and still be held by the same holder when it is broken. The
+holder loses the lock, and fails when it checks it.`,
+		Example:               `ForceUnlock --reason "reparent stuck since the zone1 outage" keyspaces/commerce/shards/-`,
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(1),
+		RunE:                  commandForceUnlock,
+	}
+	// GetElections makes a GetElections gRPC call to a vtctld.
+	GetElections = &cobra.Command{
+		Use:   "GetElections",
+		Short: "Lists the leader elections of the topology, with their current primaries.",
+		Long: `Lists the leader elections of the topology, with their current primaries.
+
+The time a primary was elected is only known if it recorded it in the
+topology, which the participants of the elections do when they run for them
+through the topology server API.`,
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.NoArgs,
+		RunE:                  commandGetElections,
+	}
+	// GetLocks makes a GetLocks gRPC call to a vtctld.
+	GetLocks = &cobra.Command{
+		Use:   "GetLocks [--keyspace <keyspace> | --shard <keyspace/shard> | --path <path>]",
+		Short: "Lists the locks currently held on the keyspaces, shards and other resources of the topology.",
+		Long: `Lists the locks currently held on the keyspaces, shards and other resources of the topology.
+
+Each lock comes with the action, host and user of its holder, the time it was
+acquired, and the TTL of the lease or session backing it, for the topology
+servers that report one.
+Without flags, the locks of the routing rules, and of all the keyspaces and
+their shards are listed.`,
+		Example: `GetLocks --keyspace commerce
+GetLocks --path routing_rules`,
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.NoArgs,
+		RunE:                  commandGetLocks,
+	}
 	// GetTopologyPath makes a GetTopologyPath gRPC call to a vtctld.
 	GetTopologyPath = &cobra.Command{
 		Use:                   "GetTopologyPath <path>",
@@ -36,6 +115,112 @@ var (
 		RunE:                  commandGetTopologyPath,
 	}
 
+	// ImportTopology makes an ImportTopology gRPC call to a vtctld with the
+	// files of an archive.
+	ImportTopology = &cobra.Command{
+		Use:   "ImportTopology [--cell-mapping <from>=<to> ...] [--path-mapping <from>=<to> ...] [--overwrite] [--dry-run] <archive>",
+		Short: "Imports the files of a JSON or YAML archive, created by ExportTopology, into the topology.",
+		Long: `Imports the files of a JSON or YAML archive, created by ExportTopology, into the topology.
+
+Files that already exist are skipped, unless --overwrite is set. Paths can be
+rewritten with --path-mapping, where the longest mapped prefix of each path is
+replaced. Note that only the paths of the files are rewritten, not the names
+stored in their records.`,
+		Example:               `ImportTopology --path-mapping keyspaces/commerce=keyspaces/commerce_staging --dry-run commerce.yaml`,
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(1),
+		RunE:                  commandImportTopology,
+	}
+	// PingTopoWatch makes a PingTopoWatch gRPC call to a vtctld.
+	PingTopoWatch = &cobra.Command{
+		Use:   "PingTopoWatch --cell <cell> [--path <path>] [--component <tablet_alias|host:port> ...] [--threshold <duration>]",
+		Short: "Checks that the watches of a file of the topology deliver its changes, and reports how long they took.",
+		Long: `Checks that the watches of a file of the topology deliver its changes, and reports how long they took.
+
+The vtctld writes a canary value to the file, in the topology of the cell,
+and waits for its own watch of the file, and for the ones of the components,
+to deliver it. Components are given as tablet aliases, or as the host:port of
+the HTTP server of a vtgate or vttablet, which serve the watch-echo endpoint
+` + "`/debug/topo_watch_echo`" + `. The command fails if any of the watches did not
+deliver the canary within the threshold.`,
+		Example:               `PingTopoWatch --cell zone1 --component zone1-0000000100 --component vtgate-zone1:15001`,
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.NoArgs,
+		RunE:                  commandPingTopoWatch,
+	}
+	// ResignLeader makes a ResignLeader gRPC call to a vtctld.
+	ResignLeader = &cobra.Command{
+		Use:   "ResignLeader [--id <id>] [--reason <reason>] <name>",
+		Short: "Forces the primary of an election in the topology to resign, and records it in the audit log of the topology.",
+		Long: `Forces the primary of an election in the topology to resign, and records it in the audit log of the topology.
+
+The name is the name of the election in the global topology. The primary loses
+its primaryship, and another participant is elected; the old primary runs for
+election again if it was built to. With --id, the primary is only forced to
+resign if it is the given participant, so that another primary elected in the
+meantime is left alone. Some topology servers only notify the primary on its
+next check of its lock, a few seconds later.`,
+		Example:               `ResignLeader --id host1:15999 --reason "draining host1 for maintenance" my_election`,
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(1),
+		RunE:                  commandResignLeader,
+	}
+	// TopoCp copies files between the topology and the local filesystem,
+	// with ExportTopology and ImportTopology gRPC calls to a vtctld.
+	TopoCp = &cobra.Command{
+		Use:   "TopoCp [--cell <cell>] [--recursive] [--to-topo [--check-versions] [--dry-run]] <src> <dst>",
+		Short: "Copies a file, or with --recursive a directory, from the topology to the local filesystem, or the other way around.",
+		Long: `Copies a file, or with --recursive a directory, from the topology to the local filesystem, or the other way around.
+
+Topology paths are relative to the root of the cell. Files are copied as they
+are stored. When a directory is copied from the topology, the versions of its
+files are recorded in its ` + "`" + topoCpVersionsFile + "`" + ` file, so that they can be
+checked when the directory is copied back with --check-versions: only the
+files that did not change in the topology since are then replaced, and the
+others are reported as conflicts, which fails the command. Files without a
+recorded version are then only created.`,
+		Example: `TopoCp --recursive keyspaces/commerce ./topo/commerce
+TopoCp --recursive --to-topo --check-versions ./topo/commerce keyspaces/commerce`,
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(2),
+		RunE:                  commandTopoCp,
+	}
+	// WatchTopologyPath makes a WatchTopologyPath gRPC call to a vtctld.
+	WatchTopologyPath = &cobra.Command{
+		Use:   "WatchTopologyPath [--cell <cell>] [--include-initial] <path>",
+		Short: "Streams the changes of the files under a path of the topology, as JSON lines, until interrupted.",
+		Long: `Streams the changes of the files under a path of the topology, as JSON lines, until interrupted.
+
+The path is relative to the root of the cell. The records of known types are
+decoded as JSON in the "data" of the changes, and deleted files are reported
+with "deleted" set.`,
+		Example:               `WatchTopologyPath --cell zone1 keyspaces/commerce/SrvKeyspace`,
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(1),
+		RunE:                  commandWatchTopologyPath,
+	}
+	// TopoCat makes ExportTopology gRPC calls to a vtctld, and displays the
+	// files.
+	TopoCat = &cobra.Command{
+		Use:   "TopoCat [--cells <cell1,cell2,...>] [--recursive] [--filter <regex>] [--format prototext|json|yaml] <path> [<path> ...]",
+		Short: "Displays the files at the given paths of the topology, decoding the records of known types.",
+		Long: `Displays the files at the given paths of the topology, decoding the records of known types.
+
+A path can be a file, or a directory whose files are displayed, and those of
+its subdirectories with --recursive. Paths are relative to the root of the
+cells. The files can be restricted to those whose path matches the regular
+expression of --filter.
+
+The prototext format displays each file after a "# <cell>:<path>" header,
+with the records of unknown types displayed as is. The json and yaml formats
+display the files as an archive, as ExportTopology does.`,
+		Example: `TopoCat --cells zone1 tablets/zone1-0000000100/Tablet
+TopoCat --recursive --filter '/Shard$' --format yaml keyspaces/commerce`,
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.MinimumNArgs(1),
+		RunE:                  commandTopoCat,
+	}
+
 	// The version of the key/path to get. If not specified, the latest/current
 	// version is returned.
 	version int64 = 0
@@ -43,6 +228,200 @@ var (
 	dataAsJSON bool = false
 )
 
+var exportTopologyOptions = struct {
+	Cells  []string
+	Path   string
+	Format string
+	Output string
+}{
+	Cells: []string{topo.GlobalCell},
+}
+
+func commandExportTopology(cmd *cobra.Command, args []string) error {
+	format := exportTopologyOptions.Format
+	if format == "" {
+		switch filepath.Ext(exportTopologyOptions.Output) {
+		case ".yaml", ".yml":
+			format = helpers.ArchiveFormatYAML
+		default:
+			format = helpers.ArchiveFormatJSON
+		}
+	}
+
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.ExportTopology(commandCtx, &vtctldatapb.ExportTopologyRequest{
+		Cells: exportTopologyOptions.Cells,
+		Path:  exportTopologyOptions.Path,
+	})
+	if err != nil {
+		return err
+	}
+
+	archive, err := helpers.NewTopoArchive(resp.Files)
+	if err != nil {
+		return err
+	}
+	data, err := helpers.MarshalTopoArchive(archive, format)
+	if err != nil {
+		return err
+	}
+
+	if exportTopologyOptions.Output == "" {
+		fmt.Printf("%s\n", data)
+		return nil
+	}
+	return os.WriteFile(exportTopologyOptions.Output, data, 0o644)
+}
+
+var importTopologyOptions = struct {
+	CellMappings  map[string]string
+	PathMappings  map[string]string
+	Overwrite     bool
+	CheckVersions bool
+	DryRun        bool
+}{}
+
+func commandImportTopology(cmd *cobra.Command, args []string) error {
+	data, err := os.ReadFile(cmd.Flags().Arg(0))
+	if err != nil {
+		return err
+	}
+	archive, err := helpers.UnmarshalTopoArchive(data)
+	if err != nil {
+		return fmt.Errorf("cannot parse archive %v: %w", cmd.Flags().Arg(0), err)
+	}
+	files, err := archive.TopologyFiles()
+	if err != nil {
+		return err
+	}
+
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.ImportTopology(commandCtx, &vtctldatapb.ImportTopologyRequest{
+		Files:         files,
+		CellMappings:  importTopologyOptions.CellMappings,
+		PathMappings:  importTopologyOptions.PathMappings,
+		Overwrite:     importTopologyOptions.Overwrite,
+		CheckVersions: importTopologyOptions.CheckVersions,
+		DryRun:        importTopologyOptions.DryRun,
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err = cli.MarshalJSONPretty(resp)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+
+	return nil
+}
+
+var forceUnlockOptions = struct {
+	MinAge time.Duration
+	Reason string
+}{}
+
+func commandForceUnlock(cmd *cobra.Command, args []string) error {
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.ForceUnlock(commandCtx, &vtctldatapb.ForceUnlockRequest{
+		Path:   cmd.Flags().Arg(0),
+		MinAge: protoutil.DurationToProto(forceUnlockOptions.MinAge),
+		Reason: forceUnlockOptions.Reason,
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp.Lock)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Broke lock:\n%s\n", data)
+	return nil
+}
+
+var resignLeaderOptions = struct {
+	ID     string
+	Reason string
+}{}
+
+func commandResignLeader(cmd *cobra.Command, args []string) error {
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.ResignLeader(commandCtx, &vtctldatapb.ResignLeaderRequest{
+		Name:   cmd.Flags().Arg(0),
+		Id:     resignLeaderOptions.ID,
+		Reason: resignLeaderOptions.Reason,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Forced %s to resign\n", resp.LeaderId)
+	return nil
+}
+
+func commandGetElections(cmd *cobra.Command, args []string) error {
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.GetElections(commandCtx, &vtctldatapb.GetElectionsRequest{})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+	return nil
+}
+
+var getLocksOptions = struct {
+	Keyspace string
+	Shard    string
+	Path     string
+}{}
+
+func commandGetLocks(cmd *cobra.Command, args []string) error {
+	req := &vtctldatapb.GetLocksRequest{
+		Keyspace: getLocksOptions.Keyspace,
+		Path:     getLocksOptions.Path,
+	}
+	if getLocksOptions.Shard != "" {
+		if req.Keyspace != "" {
+			return errors.New("--keyspace and --shard are mutually exclusive")
+		}
+		keyspace, shard, err := topoproto.ParseKeyspaceShard(getLocksOptions.Shard)
+		if err != nil {
+			return err
+		}
+		req.Keyspace, req.Shard = keyspace, shard
+	}
+
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.GetLocks(commandCtx, req)
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+	return nil
+}
+
 func commandGetTopologyPath(cmd *cobra.Command, args []string) error {
 	path := cmd.Flags().Arg(0)
 
@@ -75,8 +454,346 @@ func commandGetTopologyPath(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+var pingTopoWatchOptions = struct {
+	Cell       string
+	Path       string
+	Components []string
+	Threshold  time.Duration
+}{}
+
+func commandPingTopoWatch(cmd *cobra.Command, args []string) error {
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.PingTopoWatch(commandCtx, &vtctldatapb.PingTopoWatchRequest{
+		Cell:       pingTopoWatchOptions.Cell,
+		Path:       pingTopoWatchOptions.Path,
+		Components: pingTopoWatchOptions.Components,
+		Threshold:  protoutil.DurationToProto(pingTopoWatchOptions.Threshold),
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+
+	failed := 0
+	for _, result := range resp.Results {
+		if result.Error != "" {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d watches did not deliver the canary", failed, len(resp.Results))
+	}
+
+	return nil
+}
+
+var topoCatOptions = struct {
+	Cells     []string
+	Recursive bool
+	Filter    string
+	Format    string
+}{
+	Cells:  []string{topo.GlobalCell},
+	Format: "prototext",
+}
+
+func commandTopoCat(cmd *cobra.Command, args []string) error {
+	switch topoCatOptions.Format {
+	case "prototext", helpers.ArchiveFormatJSON, helpers.ArchiveFormatYAML:
+	default:
+		return fmt.Errorf("unknown format %q, expected prototext, json or yaml", topoCatOptions.Format)
+	}
+
+	cli.FinishedParsing(cmd)
+
+	var files []*vtctldatapb.TopologyFile
+	for _, topoPath := range cmd.Flags().Args() {
+		resp, err := client.ExportTopology(commandCtx, &vtctldatapb.ExportTopologyRequest{
+			Cells:   topoCatOptions.Cells,
+			Path:    topoPath,
+			Filter:  topoCatOptions.Filter,
+			Shallow: !topoCatOptions.Recursive,
+		})
+		if err != nil {
+			return err
+		}
+		files = append(files, resp.Files...)
+	}
+
+	if topoCatOptions.Format != "prototext" {
+		archive, err := helpers.NewTopoArchive(files)
+		if err != nil {
+			return err
+		}
+		data, err := helpers.MarshalTopoArchive(archive, topoCatOptions.Format)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%s\n", data)
+		return nil
+	}
+
+	for _, file := range files {
+		decoded, err := topo.DecodeContent(file.Path, file.Data, false)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "cannot decode %v:%v: %v\n", file.Cell, file.Path, err)
+		}
+		fmt.Printf("# %v:%v\n%v", file.Cell, file.Path, decoded)
+		if !strings.HasSuffix(decoded, "\n") {
+			fmt.Println()
+		}
+	}
+
+	return nil
+}
+
+// topoCpVersionsFile is the file of a directory copied from the topology
+// with TopoCp that records the versions of its files.
+const topoCpVersionsFile = ".topo_versions.json"
+
+var topoCpOptions = struct {
+	Cell          string
+	Recursive     bool
+	ToTopo        bool
+	CheckVersions bool
+	DryRun        bool
+}{
+	Cell: topo.GlobalCell,
+}
+
+func commandTopoCp(cmd *cobra.Command, args []string) error {
+	src, dst := cmd.Flags().Arg(0), cmd.Flags().Arg(1)
+	if !topoCpOptions.ToTopo && (topoCpOptions.CheckVersions || topoCpOptions.DryRun) {
+		return errors.New("--check-versions and --dry-run only apply with --to-topo")
+	}
+
+	cli.FinishedParsing(cmd)
+
+	if topoCpOptions.ToTopo {
+		return topoCpToTopo(src, strings.Trim(dst, "/"))
+	}
+	return topoCpFromTopo(strings.Trim(src, "/"), dst)
+}
+
+func topoCpFromTopo(src, dst string) error {
+	resp, err := client.ExportTopology(commandCtx, &vtctldatapb.ExportTopologyRequest{
+		Cells:   []string{topoCpOptions.Cell},
+		Path:    src,
+		Shallow: !topoCpOptions.Recursive,
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(resp.Files) == 1 && resp.Files[0].Path == src {
+		return os.WriteFile(dst, resp.Files[0].Data, 0o644)
+	}
+	if !topoCpOptions.Recursive {
+		return fmt.Errorf("%v is not a file, use --recursive to copy a directory", src)
+	}
+
+	versions := make(map[string]string, len(resp.Files))
+	for _, file := range resp.Files {
+		rel := strings.TrimPrefix(strings.TrimPrefix(file.Path, src), "/")
+		localPath := filepath.Join(dst, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(localPath), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(localPath, file.Data, 0o644); err != nil {
+			return err
+		}
+		versions[rel] = file.Version
+	}
+	data, err := json.MarshalIndent(versions, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dst, 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dst, topoCpVersionsFile), append(data, '\n'), 0o644); err != nil {
+		return err
+	}
+
+	fmt.Printf("Copied %d files from %v:%v to %v\n", len(resp.Files), topoCpOptions.Cell, src, dst)
+	return nil
+}
+
+func topoCpToTopo(src, dst string) error {
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+
+	var files []*vtctldatapb.TopologyFile
+	if !info.IsDir() {
+		data, err := os.ReadFile(src)
+		if err != nil {
+			return err
+		}
+		files = append(files, &vtctldatapb.TopologyFile{Cell: topoCpOptions.Cell, Path: dst, Data: data})
+	} else {
+		if !topoCpOptions.Recursive {
+			return fmt.Errorf("%v is a directory, use --recursive to copy it", src)
+		}
+
+		versions := map[string]string{}
+		if data, err := os.ReadFile(filepath.Join(src, topoCpVersionsFile)); err == nil {
+			if err := json.Unmarshal(data, &versions); err != nil {
+				return fmt.Errorf("cannot parse %v: %w", topoCpVersionsFile, err)
+			}
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+
+		err := filepath.WalkDir(src, func(localPath string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+			rel, err := filepath.Rel(src, localPath)
+			if err != nil {
+				return err
+			}
+			rel = filepath.ToSlash(rel)
+			if rel == topoCpVersionsFile {
+				return nil
+			}
+			data, err := os.ReadFile(localPath)
+			if err != nil {
+				return err
+			}
+			files = append(files, &vtctldatapb.TopologyFile{
+				Cell:    topoCpOptions.Cell,
+				Path:    path.Join(dst, rel),
+				Data:    data,
+				Version: versions[rel],
+			})
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	resp, err := client.ImportTopology(commandCtx, &vtctldatapb.ImportTopologyRequest{
+		Files:         files,
+		Overwrite:     true,
+		CheckVersions: topoCpOptions.CheckVersions,
+		DryRun:        topoCpOptions.DryRun,
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSONPretty(resp)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s\n", data)
+
+	if len(resp.Conflicts) > 0 {
+		return fmt.Errorf("%d files changed in the topology since they were copied from it", len(resp.Conflicts))
+	}
+	return nil
+}
+
+var watchTopologyPathOptions = struct {
+	Cell           string
+	IncludeInitial bool
+}{
+	Cell: topo.GlobalCell,
+}
+
+func commandWatchTopologyPath(cmd *cobra.Command, args []string) error {
+	cli.FinishedParsing(cmd)
+
+	stream, err := client.WatchTopologyPath(commandCtx, &vtctldatapb.WatchTopologyPathRequest{
+		Cell:           watchTopologyPathOptions.Cell,
+		Path:           cmd.Flags().Arg(0),
+		IncludeInitial: watchTopologyPathOptions.IncludeInitial,
+	})
+	if err != nil {
+		return err
+	}
+
+	for {
+		resp, err := stream.Recv()
+		switch err {
+		case nil:
+			data, err := cli.MarshalJSON(resp, protojson.MarshalOptions{UseProtoNames: true})
+			if err != nil {
+				return err
+			}
+			fmt.Printf("%s\n", data)
+		case io.EOF:
+			return nil
+		default:
+			return err
+		}
+	}
+}
+
 func init() {
+	ExportTopology.Flags().StringSliceVar(&exportTopologyOptions.Cells, "cells", exportTopologyOptions.Cells, "The cells to export the files of. Use \"global\" for the global topology.")
+	ExportTopology.Flags().StringVar(&exportTopologyOptions.Path, "path", "", "The directory, or file, to export, relative to the root of the cells. Exports everything if empty.")
+	ExportTopology.Flags().StringVar(&exportTopologyOptions.Format, "format", "", "The format of the archive, json or yaml. Defaults to yaml for an --output ending in .yaml or .yml, and to json otherwise.")
+	ExportTopology.Flags().StringVarP(&exportTopologyOptions.Output, "output", "o", "", "The file to write the archive to. Writes it to stdout if empty.")
+	Root.AddCommand(ExportTopology)
+
+	ForceUnlock.Flags().DurationVar(&forceUnlockOptions.MinAge, "min-age", time.Hour, "How long ago a stale lock must have been acquired for it to be broken.")
+	ForceUnlock.Flags().StringVar(&forceUnlockOptions.Reason, "reason", "", "The reason for breaking the lock, recorded in the audit log of the topology.")
+	Root.AddCommand(ForceUnlock)
+
+	Root.AddCommand(GetElections)
+
+	GetLocks.Flags().StringVar(&getLocksOptions.Keyspace, "keyspace", "", "List the locks of this keyspace and of its shards.")
+	GetLocks.Flags().StringVar(&getLocksOptions.Shard, "shard", "", "List the locks of this shard, as <keyspace/shard>.")
+	GetLocks.Flags().StringVar(&getLocksOptions.Path, "path", "", "List the locks of the resource locked on this directory of the global topology, e.g. routing_rules.")
+	Root.AddCommand(GetLocks)
+
 	GetTopologyPath.Flags().Int64Var(&version, "version", version, "The version of the path's key to get. If not specified, the latest version is returned.")
 	GetTopologyPath.Flags().BoolVar(&dataAsJSON, "data-as-json", dataAsJSON, "If true, only the data is output and it is in JSON format rather than prototext.")
 	Root.AddCommand(GetTopologyPath)
+
+	ImportTopology.Flags().StringToStringVar(&importTopologyOptions.CellMappings, "cell-mapping", nil, "Writes the files of a cell of the archive to another cell, as <from>=<to>. May be repeated.")
+	ImportTopology.Flags().StringToStringVar(&importTopologyOptions.PathMappings, "path-mapping", nil, "Replaces a path prefix of the files of the archive, as <from>=<to>. May be repeated.")
+	ImportTopology.Flags().BoolVar(&importTopologyOptions.Overwrite, "overwrite", false, "Overwrite the files that already exist instead of skipping them.")
+	ImportTopology.Flags().BoolVar(&importTopologyOptions.CheckVersions, "check-versions", false, "Only replace the files that did not change since they were exported, and report the others as conflicts.")
+	ImportTopology.Flags().BoolVar(&importTopologyOptions.DryRun, "dry-run", false, "Report what would be imported without writing anything.")
+	Root.AddCommand(ImportTopology)
+
+	PingTopoWatch.Flags().StringVar(&pingTopoWatchOptions.Cell, "cell", "", "The cell whose topology the canary is written to.")
+	PingTopoWatch.Flags().StringVar(&pingTopoWatchOptions.Path, "path", watchping.DefaultPath, "The path of the canary file, relative to the root of the cell. An existing file that is not a canary is never overwritten.")
+	PingTopoWatch.Flags().StringSliceVar(&pingTopoWatchOptions.Components, "component", nil, "A component whose watch to check, as a tablet alias or as the host:port of its HTTP server. May be repeated.")
+	PingTopoWatch.Flags().DurationVar(&pingTopoWatchOptions.Threshold, "threshold", watchping.DefaultThreshold, "The time each watch has to start, and then to deliver the canary.")
+	PingTopoWatch.MarkFlagRequired("cell")
+	Root.AddCommand(PingTopoWatch)
+
+	ResignLeader.Flags().StringVar(&resignLeaderOptions.ID, "id", "", "Only force the primary to resign if it is the participant with this id.")
+	ResignLeader.Flags().StringVar(&resignLeaderOptions.Reason, "reason", "", "The reason for forcing the primary to resign, recorded in the audit log of the topology.")
+	Root.AddCommand(ResignLeader)
+
+	TopoCat.Flags().StringSliceVar(&topoCatOptions.Cells, "cells", topoCatOptions.Cells, "The cells to display the files of. Use \"global\" for the global topology.")
+	TopoCat.Flags().BoolVarP(&topoCatOptions.Recursive, "recursive", "r", false, "Also display the files of the subdirectories of the paths.")
+	TopoCat.Flags().StringVar(&topoCatOptions.Filter, "filter", "", "Only display the files whose path, relative to the root of their cell, matches this regular expression.")
+	TopoCat.Flags().StringVar(&topoCatOptions.Format, "format", topoCatOptions.Format, "The output format, prototext, json or yaml.")
+	Root.AddCommand(TopoCat)
+
+	TopoCp.Flags().StringVar(&topoCpOptions.Cell, "cell", topoCpOptions.Cell, "The cell of the topology to copy from or to. Use \"global\" for the global topology.")
+	TopoCp.Flags().BoolVarP(&topoCpOptions.Recursive, "recursive", "r", false, "Copy a directory and all the files under it.")
+	TopoCp.Flags().BoolVar(&topoCpOptions.ToTopo, "to-topo", false, "Copy from the local filesystem to the topology, instead of the other way around.")
+	TopoCp.Flags().BoolVar(&topoCpOptions.CheckVersions, "check-versions", false, "Only replace the files of the topology that did not change since they were copied from it, and report the others as conflicts.")
+	TopoCp.Flags().BoolVar(&topoCpOptions.DryRun, "dry-run", false, "Report what would be copied to the topology without writing anything.")
+	Root.AddCommand(TopoCp)
+
+	WatchTopologyPath.Flags().StringVar(&watchTopologyPathOptions.Cell, "cell", watchTopologyPathOptions.Cell, "The cell to watch the files of. Use \"global\" for the global topology.")
+	WatchTopologyPath.Flags().BoolVar(&watchTopologyPathOptions.IncludeInitial, "include-initial", false, "Also stream the current files under the path when the watch starts.")
+	Root.AddCommand(WatchTopologyPath)
 }
@@ -32,8 +32,13 @@ import (
 var (
 	// Validate makes a Validate gRPC call to a vtctld.
 	Validate = &cobra.Command{
-		Use:                   "Validate [--ping-tablets]",
-		Short:                 "Validates that all nodes reachable from the global replication graph, as well as all tablets in discoverable cells, are consistent.",
+		Use:   "Validate [--ping-tablets] [--cross-references]",
+		Short: "Validates that all nodes reachable from the global replication graph, as well as all tablets in discoverable cells, are consistent.",
+		Long: `Validates that all nodes reachable from the global replication graph, as well as all tablets in discoverable cells, are consistent.
+
+With --cross-references, also checks the references between the topology records: the cells of the cells aliases,
+the primaries of the shards, the tablets of the replication graphs, the shards of the tablets, the shards and keyspaces
+served in each cell, and the keyspaces of the routing rules.`,
 		DisableFlagsInUseLine: true,
 		Args:                  cobra.NoArgs,
 		RunE:                  commandValidate,
@@ -57,14 +62,16 @@ var (
 )
 
 var validateOptions = struct {
-	PingTablets bool
+	PingTablets     bool
+	CrossReferences bool
 }{}
 
 func commandValidate(cmd *cobra.Command, args []string) error {
 	cli.FinishedParsing(cmd)
 
 	resp, err := client.Validate(commandCtx, &vtctldatapb.ValidateRequest{
-		PingTablets: validateOptions.PingTablets,
+		PingTablets:     validateOptions.PingTablets,
+		CrossReferences: validateOptions.CrossReferences,
 	})
 	if err != nil {
 		return err
@@ -142,6 +149,10 @@ func consumeValidationResults(resp *vtctldatapb.ValidateResponse, buf *strings.B
 		fmt.Fprintf(buf, "- %s\n", result)
 	}
 
+	for _, issue := range resp.Issues {
+		fmt.Fprintf(buf, "- %s %s: %s (%s)\n", issue.Severity, issue.Path, issue.Message, issue.Check)
+	}
+
 	for keyspace, keyspaceResults := range resp.ResultsByKeyspace {
 		buf2 := &strings.Builder{}
 		if err := consumeKeyspaceValidationResults(keyspace, keyspaceResults, buf2); err != nil {
@@ -196,6 +207,7 @@ func init() {
 	pingTabletsUsage := "Indicates whether all tablets should be pinged during the validation process."
 
 	Validate.Flags().BoolVarP(&validateOptions.PingTablets, pingTabletsName, pingTabletsShort, pingTabletsDefault, pingTabletsUsage)
+	Validate.Flags().BoolVar(&validateOptions.CrossReferences, "cross-references", false, "Also check the references between the topology records, like the tablets of the replication graphs or the shards served in each cell.")
 	ValidateKeyspace.Flags().BoolVarP(&validateKeyspaceOptions.PingTablets, pingTabletsName, pingTabletsShort, pingTabletsDefault, pingTabletsUsage)
 	ValidateShard.Flags().BoolVarP(&validateShardOptions.PingTablets, pingTabletsName, pingTabletsShort, pingTabletsDefault, pingTabletsUsage)
 
@@ -40,6 +40,9 @@ var (
 		NoRoutingRules      bool
 		AtomicCopy          bool
 		WorkflowOptions     vtctldatapb.WorkflowOptions
+		// TargetSchemaAdjustments are only sent if one of their flags is set.
+		TargetSchemaAdjustments vtctldatapb.TargetSchemaAdjustments
+		TargetPartitioning      []string
 	}{}
 
 	// create makes a MoveTablesCreate gRPC call to a vtctld.
@@ -87,12 +90,42 @@ var (
 				return fmt.Errorf("cannot specify both --tenant-id (i.e. a multi-tenant migration) and --source-shards (i.e. a shard-by-shard migration)")
 			}
 
+			if err := parseTargetSchemaAdjustments(cmd); err != nil {
+				return err
+			}
+
 			return nil
 		},
 		RunE: commandCreate,
 	}
 )
 
+// parseTargetSchemaAdjustments sets the target schema adjustments of the
+// workflow options from the --target-* flags, if any of them was set.
+func parseTargetSchemaAdjustments(cmd *cobra.Command) error {
+	changed := false
+	for _, name := range []string{"target-drop-check-constraints", "target-drop-partitioning", "target-charset", "target-collation", "target-partitioning"} {
+		changed = changed || cmd.Flags().Lookup(name).Changed
+	}
+	if !changed {
+		return nil
+	}
+
+	adjustments := &createOptions.TargetSchemaAdjustments
+	for _, partitioning := range createOptions.TargetPartitioning {
+		table, clause, ok := strings.Cut(partitioning, "=")
+		if !ok || table == "" || clause == "" {
+			return fmt.Errorf("invalid --target-partitioning %q, expected <table>=<PARTITION BY clause>", partitioning)
+		}
+		if adjustments.Partitioning == nil {
+			adjustments.Partitioning = make(map[string]string)
+		}
+		adjustments.Partitioning[table] = clause
+	}
+	createOptions.WorkflowOptions.TargetSchemaAdjustments = adjustments
+	return nil
+}
+
 func commandCreate(cmd *cobra.Command, args []string) error {
 	format, err := common.GetOutputFormat(cmd)
 	if err != nil {
@@ -49,6 +49,11 @@ func registerCommands(root *cobra.Command) {
 	create.Flags().BoolVar(&createOptions.AtomicCopy, "atomic-copy", false, "(EXPERIMENTAL) A single copy phase is run for all tables from the source. Use this, for example, if your source keyspace has tables which use foreign key constraints.")
 	create.Flags().StringVar(&createOptions.WorkflowOptions.TenantId, "tenant-id", "", "(EXPERIMENTAL: Multi-tenant migrations only) The tenant ID to use for the MoveTables workflow into a multi-tenant keyspace.")
 	create.Flags().BoolVar(&createOptions.WorkflowOptions.StripShardedAutoIncrement, "remove-sharded-auto-increment", true, "If moving the table(s) to a sharded keyspace, remove any auto_increment clauses when copying the schema to the target as sharded keyspaces should rely on either user/application generated values or Vitess sequences to ensure uniqueness.")
+	create.Flags().BoolVar(&createOptions.TargetSchemaAdjustments.DropCheckConstraints, "target-drop-check-constraints", false, "Remove CHECK constraints from the tables created on the target.")
+	create.Flags().BoolVar(&createOptions.TargetSchemaAdjustments.DropPartitioning, "target-drop-partitioning", false, "Remove partitioning from the tables created on the target.")
+	create.Flags().StringVar(&createOptions.TargetSchemaAdjustments.Charset, "target-charset", "", "Default character set of the tables created on the target.")
+	create.Flags().StringVar(&createOptions.TargetSchemaAdjustments.Collation, "target-collation", "", "Default collation of the tables created on the target.")
+	create.Flags().StringArrayVar(&createOptions.TargetPartitioning, "target-partitioning", nil, "Partitioning of a table created on the target, as <table>=<PARTITION BY clause>. Can be repeated. The changes made to the source schema by the --target-* flags are recorded in the workflow options.")
 	create.Flags().StringSliceVar(&createOptions.WorkflowOptions.Shards, "shards", nil, "(EXPERIMENTAL: Multi-tenant migrations only) Specify that vreplication streams should only be created on this subset of target shards. Warning: you should first ensure that all rows on the source route to the specified subset of target shards using your VIndex of choice or you could lose data during the migration.")
 	base.AddCommand(create)
 
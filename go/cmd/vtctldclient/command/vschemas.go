@@ -38,9 +38,25 @@ var (
 		Args:                  cobra.ExactArgs(1),
 		RunE:                  commandGetVSchema,
 	}
+	// GetVSchemaHistory makes a GetVSchemaHistory gRPC call to a vtctld.
+	GetVSchemaHistory = &cobra.Command{
+		Use:                   "GetVSchemaHistory <keyspace>",
+		Short:                 "Prints the prior versions of a keyspace's VSchema, newest first, kept each time a VSchema is applied to it.",
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(1),
+		RunE:                  commandGetVSchemaHistory,
+	}
+	// RollbackVSchema makes a RollbackVSchema gRPC call to a vtctld.
+	RollbackVSchema = &cobra.Command{
+		Use:                   "RollbackVSchema --version=<version> [--cells=c1,c2,...] [--skip-rebuild] <keyspace>",
+		Short:                 "Applies a prior version of a keyspace's VSchema, from its VSchema history. Shows the result after application.",
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(1),
+		RunE:                  commandRollbackVSchema,
+	}
 	// ApplyVSchema makes an ApplyVSchema gRPC call to a vtctld.
 	ApplyVSchema = &cobra.Command{
-		Use:                   "ApplyVSchema {--vschema=<vschema> || --vschema-file=<vschema file> || --sql=<sql> || --sql-file=<sql file>} [--cells=c1,c2,...] [--skip-rebuild] [--dry-run] [--strict] <keyspace>",
+		Use:                   "ApplyVSchema {--vschema=<vschema> || --vschema-file=<vschema file> || --sql=<sql> || --sql-file=<sql file>} [--cells=c1,c2,...] [--skip-rebuild] [--dry-run] [--strict] [--validate-against-schema] <keyspace>",
 		Short:                 "Applies the VTGate routing schema to the provided keyspace. Shows the result after application.",
 		DisableFlagsInUseLine: true,
 		Args:                  cobra.ExactArgs(1),
@@ -57,6 +73,8 @@ var applyVSchemaOptions = struct {
 	SkipRebuild bool
 	Cells       []string
 	Strict      bool
+
+	ValidateAgainstSchema bool
 }{}
 
 func commandApplyVSchema(cmd *cobra.Command, args []string) error {
@@ -77,6 +95,8 @@ func commandApplyVSchema(cmd *cobra.Command, args []string) error {
 		Cells:       applyVSchemaOptions.Cells,
 		DryRun:      applyVSchemaOptions.DryRun,
 		Strict:      applyVSchemaOptions.Strict,
+
+		ValidateAgainstSchema: applyVSchemaOptions.ValidateAgainstSchema,
 	}
 
 	var err error
@@ -150,6 +170,55 @@ func commandGetVSchema(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+func commandGetVSchemaHistory(cmd *cobra.Command, args []string) error {
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.GetVSchemaHistory(commandCtx, &vtctldatapb.GetVSchemaHistoryRequest{
+		Keyspace: cmd.Flags().Arg(0),
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+
+	return nil
+}
+
+var rollbackVSchemaOptions = struct {
+	Version     int64
+	SkipRebuild bool
+	Cells       []string
+}{}
+
+func commandRollbackVSchema(cmd *cobra.Command, args []string) error {
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.RollbackVSchema(commandCtx, &vtctldatapb.RollbackVSchemaRequest{
+		Keyspace:    cmd.Flags().Arg(0),
+		Version:     rollbackVSchemaOptions.Version,
+		SkipRebuild: rollbackVSchemaOptions.SkipRebuild,
+		Cells:       rollbackVSchemaOptions.Cells,
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp.VSchema)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("New VSchema object:\n%s\n", data)
+
+	return nil
+}
+
 func init() {
 	ApplyVSchema.Flags().StringVar(&applyVSchemaOptions.VSchema, "vschema", "", "VSchema to apply, in JSON form.")
 	ApplyVSchema.Flags().StringVar(&applyVSchemaOptions.VSchemaFile, "vschema-file", "", "Path to a file containing the vschema to apply, in JSON form.")
@@ -159,7 +228,16 @@ func init() {
 	ApplyVSchema.Flags().BoolVar(&applyVSchemaOptions.SkipRebuild, "skip-rebuild", false, "Skip rebuilding the SrvSchema objects.")
 	ApplyVSchema.Flags().StringSliceVar(&applyVSchemaOptions.Cells, "cells", nil, "Limits the rebuild to the specified cells, after application. Ignored if --skip-rebuild is set.")
 	ApplyVSchema.Flags().BoolVar(&applyVSchemaOptions.Strict, "strict", false, "If set, treat unknown vindex params as errors.")
+	ApplyVSchema.Flags().BoolVar(&applyVSchemaOptions.ValidateAgainstSchema, "validate-against-schema", false, "If set, reject the VSchema if it refers to tables, or to columns of their vindexes, that are not in the schema of the primary of every shard of the keyspace.")
 	Root.AddCommand(ApplyVSchema)
 
 	Root.AddCommand(GetVSchema)
+
+	Root.AddCommand(GetVSchemaHistory)
+
+	RollbackVSchema.Flags().Int64Var(&rollbackVSchemaOptions.Version, "version", 0, "Version of the VSchema history entry to apply, as listed by GetVSchemaHistory.")
+	RollbackVSchema.Flags().BoolVar(&rollbackVSchemaOptions.SkipRebuild, "skip-rebuild", false, "Skip rebuilding the SrvSchema objects.")
+	RollbackVSchema.Flags().StringSliceVar(&rollbackVSchemaOptions.Cells, "cells", nil, "Limits the rebuild to the specified cells, after application. Ignored if --skip-rebuild is set.")
+	RollbackVSchema.MarkFlagRequired("version")
+	Root.AddCommand(RollbackVSchema)
 }
@@ -31,6 +31,7 @@ import (
 	"vitess.io/vitess/go/vt/srvtopo"
 	"vitess.io/vitess/go/vt/topo"
 	"vitess.io/vitess/go/vt/topo/topoproto"
+	"vitess.io/vitess/go/vt/topo/watchping"
 	"vitess.io/vitess/go/vt/vtenv"
 	"vitess.io/vitess/go/vt/vterrors"
 	"vitess.io/vitess/go/vt/vtgate"
@@ -188,6 +189,7 @@ func run(cmd *cobra.Command, args []string) error {
 		// Flags are parsed now. Parse the template using the actual flag value and overwrite the current template.
 		discovery.ParseTabletURLTemplateFromFlag()
 		addStatusParts(vtg)
+		watchping.RegisterEchoHandler(ts)
 	})
 	servenv.OnClose(func() {
 		_ = vtg.Gateway().Close(ctx)
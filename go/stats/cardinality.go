@@ -0,0 +1,82 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package stats
+
+import (
+	"strings"
+	"sync"
+
+	"vitess.io/vitess/go/vt/log"
+)
+
+// StatsOtherStr is the value of each label of the combination the label
+// combinations of a stat over its limit are aggregated into.
+const StatsOtherStr = "other"
+
+const labelCombinationsOverflowName = "StatsLabelCombinationsOverflow"
+
+var (
+	maxLabelCombinations    int
+	labelCombinationsLimits map[string]int
+
+	// labelCombinationsOverflow counts the updates of label combinations
+	// aggregated into the "other" combination, by stat. It has no limit
+	// itself, its label combinations being bounded by the number of stats.
+	// It is published on the first overflow, after the flags are parsed.
+	labelCombinationsOverflow = sync.OnceValue(func() *CountersWithSingleLabel {
+		return NewCountersWithSingleLabel(labelCombinationsOverflowName, "Updates of label combinations over the limit of their stat, aggregated into the other label combination", "Metric")
+	})
+
+	overflowWarningsMu sync.Mutex
+	overflowWarnings   = map[string]bool{}
+)
+
+// labelCombinationsLimit returns the maximum number of distinct label
+// combinations of the stat published as name, or 0 if it has no limit.
+func labelCombinationsLimit(name string) int {
+	if name == "" || name == labelCombinationsOverflowName {
+		return 0
+	}
+	if limit, ok := labelCombinationsLimits[name]; ok {
+		return limit
+	}
+	return maxLabelCombinations
+}
+
+// otherLabels returns the label combination, with labelCount labels, the label
+// combinations over the limit of a stat are aggregated into.
+func otherLabels(labelCount int) string {
+	others := make([]string, max(labelCount, 1))
+	for i := range others {
+		others[i] = StatsOtherStr
+	}
+	return strings.Join(others, ".")
+}
+
+// recordLabelCombinationsOverflow counts an update of the stat published as
+// name aggregated into its other label combination, and warns the first time
+// the stat reaches its limit.
+func recordLabelCombinationsOverflow(name string) {
+	labelCombinationsOverflow().Add(name, 1)
+
+	overflowWarningsMu.Lock()
+	defer overflowWarningsMu.Unlock()
+	if !overflowWarnings[name] {
+		overflowWarnings[name] = true
+		log.Warningf("Stat %s reached its limit of %d label combinations, new combinations are aggregated into %q", name, labelCombinationsLimit(name), StatsOtherStr)
+	}
+}
@@ -29,7 +29,13 @@ type counters struct {
 	mu     sync.Mutex
 	counts map[string]int64
 
-	help string
+	// name is the name the counters are published as, which their limit of
+	// label combinations is looked up by.
+	name string
+	// other is the label combination the label combinations over the limit
+	// are aggregated into.
+	other string
+	help  string
 }
 
 func (c *counters) String() string {
@@ -49,14 +55,49 @@ func (c *counters) String() string {
 
 func (c *counters) add(name string, value int64) {
 	c.mu.Lock()
-	defer c.mu.Unlock()
+	name, overflow := c.limitLabels(name)
 	c.counts[name] = c.counts[name] + value
+	c.mu.Unlock()
+
+	if overflow {
+		recordLabelCombinationsOverflow(c.name)
+	}
 }
 
 func (c *counters) set(name string, value int64) {
 	c.mu.Lock()
-	defer c.mu.Unlock()
+	name, overflow := c.limitLabels(name)
 	c.counts[name] = value
+	c.mu.Unlock()
+
+	if overflow {
+		recordLabelCombinationsOverflow(c.name)
+	}
+}
+
+// resetKey resets the value of name back to 0. A name over the limit of label
+// combinations has no value of its own to reset, so it is left out.
+func (c *counters) resetKey(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if name, overflow := c.limitLabels(name); !overflow {
+		c.counts[name] = 0
+	}
+}
+
+// limitLabels returns the label combination name is counted as: name itself,
+// unless it is a new combination and the counters already have as many as
+// their limit, in which case it is aggregated into the other combination.
+// c.mu must be held.
+func (c *counters) limitLabels(name string) (string, bool) {
+	if _, ok := c.counts[name]; ok {
+		return name, false
+	}
+	limit := labelCombinationsLimit(c.name)
+	if limit <= 0 || len(c.counts) < limit || name == c.other {
+		return name, false
+	}
+	return c.other, true
 }
 
 func (c *counters) reset() {
@@ -111,6 +152,8 @@ func NewCountersWithSingleLabel(name, help, label string, tags ...string) *Count
 	c := &CountersWithSingleLabel{
 		counters: counters{
 			counts: make(map[string]int64),
+			name:   name,
+			other:  otherLabels(1),
 			help:   help,
 		},
 		label:         label,
@@ -148,7 +191,7 @@ func (c *CountersWithSingleLabel) Reset(name string) {
 	if c.labelCombined {
 		name = StatsAllStr
 	}
-	c.counters.set(name, 0)
+	c.counters.resetKey(name)
 }
 
 // ResetAll clears the counters
@@ -171,6 +214,8 @@ func NewCountersWithMultiLabels(name, help string, labels []string) *CountersWit
 	t := &CountersWithMultiLabels{
 		counters: counters{
 			counts: make(map[string]int64),
+			name:   name,
+			other:  otherLabels(len(labels)),
 			help:   help},
 		labels:         labels,
 		combinedLabels: make([]bool, len(labels)),
@@ -206,7 +251,7 @@ func (mc *CountersWithMultiLabels) Reset(names []string) {
 		panic("CountersWithMultiLabels: wrong number of values in Reset")
 	}
 
-	mc.counters.set(safeJoinLabels(names, mc.combinedLabels), 0)
+	mc.counters.resetKey(safeJoinLabels(names, mc.combinedLabels))
 }
 
 // ResetAll clears the counters
@@ -303,6 +348,8 @@ func NewGaugesWithSingleLabel(name, help, label string, tags ...string) *GaugesW
 		CountersWithSingleLabel: CountersWithSingleLabel{
 			counters: counters{
 				counts: make(map[string]int64),
+				name:   name,
+				other:  otherLabels(1),
 				help:   help,
 			},
 			label: label,
@@ -359,6 +406,8 @@ func NewGaugesWithMultiLabels(name, help string, labels []string) *GaugesWithMul
 		CountersWithMultiLabels: CountersWithMultiLabels{
 			counters: counters{
 				counts: make(map[string]int64),
+				name:   name,
+				other:  otherLabels(len(labels)),
 				help:   help,
 			},
 			labels: labels,
@@ -392,7 +441,7 @@ func (mg *GaugesWithMultiLabels) Set(names []string, value int64) {
 // This is useful when you range over all internal counts and you want to reset
 // specific keys.
 func (mg *GaugesWithMultiLabels) ResetKey(key string) {
-	mg.counters.set(key, 0)
+	mg.counters.resetKey(key)
 }
 
 // GaugesFuncWithMultiLabels is a wrapper around CountersFuncWithMultiLabels
@@ -269,3 +269,42 @@ func TestCountersCombineDimension(t *testing.T) {
 	c4.Add([]string{"c4", "c2", "c5"}, 1)
 	assert.Equal(t, `{"all.c2.all": 2}`, c4.String())
 }
+
+func TestCountersLabelCombinationsLimit(t *testing.T) {
+	clearStats()
+	maxLabelCombinations = 2
+	labelCombinationsLimits = map[string]int{"counter_limit_override": 3}
+	labelCombinationsOverflow().ResetAll()
+
+	c := NewCountersWithSingleLabel("counter_limit", "help", "label")
+	c.Add("c1", 1)
+	c.Add("c2", 1)
+	c.Add("c3", 1)
+	c.Add("c4", 2)
+	// Existing combinations are still counted as themselves.
+	c.Add("c1", 1)
+	// Resetting a combination over the limit leaves the other one be.
+	c.Reset("c5")
+	assert.Equal(t, map[string]int64{"c1": 2, "c2": 1, "other": 3}, c.Counts())
+
+	mc := NewCountersWithMultiLabels("counter_limit_override", "help", []string{"a", "b"})
+	for _, names := range [][]string{{"a1", "b1"}, {"a1", "b2"}, {"a2", "b1"}, {"a2", "b2"}} {
+		mc.Add(names, 1)
+	}
+	assert.Equal(t, map[string]int64{"a1.b1": 1, "a1.b2": 1, "a2.b1": 1, "other.other": 1}, mc.Counts())
+
+	g := NewGaugesWithSingleLabel("gauge_limit", "help", "label")
+	g.Set("g1", 1)
+	g.Set("g2", 2)
+	g.Set("g3", 3)
+	assert.Equal(t, map[string]int64{"g1": 1, "g2": 2, "other": 3}, g.Counts())
+
+	assert.Equal(t, map[string]int64{"counter_limit": 2, "counter_limit_override": 1, "gauge_limit": 1}, labelCombinationsOverflow().Counts())
+
+	// Stats without a name have no limit.
+	unnamed := NewCountersWithSingleLabel("", "help", "label")
+	for _, name := range []string{"c1", "c2", "c3"} {
+		unnamed.Add(name, 1)
+	}
+	assert.Len(t, unnamed.Counts(), 3)
+}
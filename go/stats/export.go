@@ -61,6 +61,11 @@ func RegisterFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&combineDimensions, "stats_combine_dimensions", combineDimensions, `List of dimensions to be combined into a single "all" value in exported stats vars`)
 	fs.StringVar(&dropVariables, "stats_drop_variables", dropVariables, `Variables to be dropped from the list of exported variables.`)
 	fs.StringSliceVar(&CommonTags, "stats_common_tags", CommonTags, `Comma-separated list of common tags for the stats backend. It provides both label and values. Example: label1:value1,label2:value2`)
+	fs.IntVar(&maxLabelCombinations, "stats_max_label_combinations", maxLabelCombinations, `Maximum number of distinct label combinations of each stat with labels, beyond which the new combinations are aggregated into a single "other" one. 0 means no limit.`)
+	fs.StringToIntVar(&labelCombinationsLimits, "stats_label_combinations_limits", labelCombinationsLimits, `Per-stat overrides of --stats_max_label_combinations. Example: QueryCounts=1000,ErrorCounts=0`)
+	fs.StringSliceVar(&rateCounters, "stats_rate_counters", rateCounters, `Comma-separated list of counters to also emit the smoothed per-second rates of, as <counter>Rate gauges, to push-based backends lacking server-side rate functions`)
+	fs.DurationVar(&rateInterval, "stats_rate_interval", rateInterval, "Interval between samples of the counters of --stats_rate_counters")
+	fs.DurationVar(&rateWindow, "stats_rate_window", rateWindow, "Sliding window the rates of --stats_rate_counters are averaged over")
 }
 
 // StatsAllStr is the consolidated name if a dimension gets combined.
@@ -239,6 +244,9 @@ func RegisterPushBackend(name string, backend PushBackend) {
 		// Start a single goroutine to emit stats periodically
 		once.Do(func() {
 			go emitToBackend(&statsEmitPeriod)
+			if len(rateCounters) > 0 {
+				go trackRateGauges()
+			}
 		})
 	}
 }
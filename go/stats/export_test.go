@@ -31,6 +31,9 @@ func clearStats() {
 	dropVariables = ""
 	combinedDimensions = nil
 	droppedVars = nil
+	maxLabelCombinations = 0
+	labelCombinationsLimits = nil
+	rateCounters = nil
 }
 
 func TestNoHook(t *testing.T) {
@@ -0,0 +1,97 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package stats
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// Group is a named group of expensive stats, like high-cardinality
+// diagnostics, which is disabled by default and can be enabled and disabled
+// at runtime. The code recording the stats of a group should check that it is
+// Enabled first, so that a disabled group costs nothing.
+type Group struct {
+	name    string
+	help    string
+	enabled atomic.Bool
+}
+
+var (
+	groupsMu      sync.Mutex
+	groups        = map[string]*Group{}
+	enabledGroups []string
+)
+
+// NewGroup creates the group of stats named name. It panics if a group of the
+// same name already exists. The group is enabled if its name was part of the
+// last SetEnabledGroups.
+func NewGroup(name, help string) *Group {
+	groupsMu.Lock()
+	defer groupsMu.Unlock()
+
+	if _, ok := groups[name]; ok {
+		panic(fmt.Sprintf("stats group %s already exists", name))
+	}
+	g := &Group{name: name, help: help}
+	g.enabled.Store(slices.Contains(enabledGroups, name))
+	groups[name] = g
+	return g
+}
+
+// Name returns the name of the group.
+func (g *Group) Name() string {
+	return g.name
+}
+
+// Help returns the help string.
+func (g *Group) Help() string {
+	return g.help
+}
+
+// Enabled returns whether the stats of the group should be recorded.
+func (g *Group) Enabled() bool {
+	return g.enabled.Load()
+}
+
+// SetEnabledGroups enables the groups of stats named in names, including the
+// groups created later, and disables all the other groups.
+func SetEnabledGroups(names []string) {
+	groupsMu.Lock()
+	defer groupsMu.Unlock()
+
+	enabledGroups = slices.Clone(names)
+	for name, g := range groups {
+		g.enabled.Store(slices.Contains(names, name))
+	}
+}
+
+// Groups returns all the groups of stats, sorted by name.
+func Groups() []*Group {
+	groupsMu.Lock()
+	defer groupsMu.Unlock()
+
+	res := make([]*Group, 0, len(groups))
+	for _, g := range groups {
+		res = append(res, g)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].name < res[j].name })
+	return res
+}
@@ -0,0 +1,46 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package stats
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroups(t *testing.T) {
+	defer SetEnabledGroups(nil)
+
+	g1 := NewGroup("test_group1", "help")
+	assert.False(t, g1.Enabled())
+
+	SetEnabledGroups([]string{"test_group1", "test_group2"})
+	assert.True(t, g1.Enabled())
+
+	// The groups created later are enabled if they were named.
+	g2 := NewGroup("test_group2", "help")
+	assert.True(t, g2.Enabled())
+	g3 := NewGroup("test_group3", "help")
+	assert.False(t, g3.Enabled())
+
+	SetEnabledGroups([]string{"test_group3"})
+	assert.False(t, g1.Enabled())
+	assert.False(t, g2.Enabled())
+	assert.True(t, g3.Enabled())
+
+	assert.Panics(t, func() { NewGroup("test_group1", "help") })
+}
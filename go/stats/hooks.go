@@ -16,6 +16,11 @@ limitations under the License.
 
 package stats
 
+import (
+	"context"
+	"time"
+)
+
 type statsdHook struct {
 	timerHook     func(string, string, int64, *Timings)
 	histogramHook func(string, int64)
@@ -32,3 +37,12 @@ func RegisterTimerHook(hook func(string, string, int64, *Timings)) {
 func RegisterHistogramHook(hook func(string, int64)) {
 	defaultStatsdHook.histogramHook = hook
 }
+
+var timingsHook func(ctx context.Context, t *Timings, name string, elapsed time.Duration)
+
+// RegisterTimingsHook registers a hook called with each duration added to a
+// published Timings or MultiTimings, along with the name of its category and
+// the context it was added with (see Timings.AddContext), if any.
+func RegisterTimingsHook(hook func(ctx context.Context, t *Timings, name string, elapsed time.Duration)) {
+	timingsHook = hook
+}
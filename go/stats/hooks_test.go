@@ -16,7 +16,9 @@ limitations under the License.
 package stats
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -56,3 +58,35 @@ func TestStatsdHook(t *testing.T) {
 		defaultStatsdHook.histogramHook("dummyName", 42)
 	})
 }
+
+func TestRegisterTimingsHook(t *testing.T) {
+	clearStats()
+	defer RegisterTimingsHook(nil)
+
+	type timing struct {
+		ctx     context.Context
+		t       *Timings
+		name    string
+		elapsed time.Duration
+	}
+	var timings []timing
+	RegisterTimingsHook(func(ctx context.Context, t *Timings, name string, elapsed time.Duration) {
+		timings = append(timings, timing{ctx, t, name, elapsed})
+	})
+
+	type ctxKey struct{}
+	ctx := context.WithValue(context.Background(), ctxKey{}, "value")
+
+	tm := NewTimings("TimingsHookTest", "help", "category")
+	tm.Add("a", time.Second)
+	mt := NewMultiTimings("MultiTimingsHookTest", "help", []string{"l1", "l2"})
+	mt.AddContext(ctx, []string{"b", "c.d"}, time.Millisecond)
+
+	// Unpublished timings are not reported.
+	NewTimings("", "help", "category").Add("e", time.Second)
+
+	assert.Equal(t, []timing{
+		{context.Background(), tm, "a", time.Second},
+		{ctx, &mt.Timings, "b.c_d", time.Millisecond},
+	}, timings)
+}
@@ -150,6 +150,14 @@ func (dc *collector) addExpVar(kv expvar.KeyValue) {
 		for labelVal, val := range v.Counts() {
 			dc.addInt(k, val, makeLabel(v.Label(), labelVal))
 		}
+	case *stats.RateGauges:
+		for labelVals, val := range v.Rates() {
+			var tags map[string]string
+			if len(v.Labels()) > 0 {
+				tags = makeLabels(v.Labels(), labelVals)
+			}
+			dc.addFloat(k, val, tags)
+		}
 	default:
 		// Deal with generic expvars by converting them to JSON and pulling out
 		// all the floats. Strings and lists will not be exported to opentsdb.
@@ -0,0 +1,91 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package otlp
+
+import (
+	"context"
+	"time"
+
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/resource"
+
+	"vitess.io/vitess/go/stats"
+)
+
+// exporter is the subset of the sdkmetric.Exporter used by the backend.
+type exporter interface {
+	Export(ctx context.Context, rm *metricdata.ResourceMetrics) error
+	Shutdown(ctx context.Context) error
+}
+
+var _ exporter = (sdkmetric.Exporter)(nil)
+
+// backend implements stats.PushBackend
+type backend struct {
+	// namespace prefixes the names of the metrics, as in the Prometheus
+	// backend.
+	namespace string
+	resource  *resource.Resource
+	exporter  exporter
+	// start is the start time of the cumulative metrics.
+	start time.Time
+}
+
+func newBackend(namespace string, exporter exporter, resource *resource.Resource) *backend {
+	return &backend{
+		namespace: namespace,
+		resource:  resource,
+		exporter:  exporter,
+		start:     time.Now(),
+	}
+}
+
+// PushAll pushes all stats to the OpenTelemetry collector
+func (b *backend) PushAll() error {
+	c := b.collector()
+	c.collectAll()
+	return b.export(c)
+}
+
+// PushOne pushes a single stat to the OpenTelemetry collector
+func (b *backend) PushOne(name string, v stats.Variable) error {
+	c := b.collector()
+	c.collectOne(name, v)
+	return b.export(c)
+}
+
+func (b *backend) collector() *collector {
+	return &collector{
+		namespace: b.namespace,
+		start:     b.start,
+		now:       time.Now(),
+	}
+}
+
+func (b *backend) export(c *collector) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return b.exporter.Export(ctx, c.resourceMetrics(b.resource))
+}
+
+// shutdown flushes and closes the exporter.
+func (b *backend) shutdown() {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	_ = b.exporter.Shutdown(ctx)
+}
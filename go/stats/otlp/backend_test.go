@@ -0,0 +1,118 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package otlp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/resource"
+
+	"vitess.io/vitess/go/stats"
+)
+
+type fakeExporter struct {
+	exported []*metricdata.ResourceMetrics
+}
+
+func (e *fakeExporter) Export(ctx context.Context, rm *metricdata.ResourceMetrics) error {
+	e.exported = append(e.exported, rm)
+	return nil
+}
+
+func (e *fakeExporter) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+// pushOne pushes the stat with a new backend, and returns its metric.
+func pushOne(t *testing.T, name string, v stats.Variable) metricdata.Metrics {
+	t.Helper()
+
+	exporter := &fakeExporter{}
+	res := resource.NewSchemaless(attribute.String("service.name", "vtgate"))
+	b := newBackend("vtgate", exporter, res)
+	require.NoError(t, b.PushOne(name, v))
+
+	require.Len(t, exporter.exported, 1)
+	rm := exporter.exported[0]
+	assert.Equal(t, res, rm.Resource)
+	require.Len(t, rm.ScopeMetrics, 1)
+	require.Len(t, rm.ScopeMetrics[0].Metrics, 1)
+	return rm.ScopeMetrics[0].Metrics[0]
+}
+
+func TestCounter(t *testing.T) {
+	name := "OTLPCounter"
+	c := stats.NewCounter(name, "counter help")
+	c.Add(3)
+
+	m := pushOne(t, name, c)
+	assert.Equal(t, "vtgate_otlp_counter", m.Name)
+	assert.Equal(t, "counter help", m.Description)
+	sum, ok := m.Data.(metricdata.Sum[int64])
+	require.True(t, ok)
+	assert.True(t, sum.IsMonotonic)
+	assert.Equal(t, metricdata.CumulativeTemporality, sum.Temporality)
+	require.Len(t, sum.DataPoints, 1)
+	assert.EqualValues(t, 3, sum.DataPoints[0].Value)
+}
+
+func TestGaugesWithMultiLabels(t *testing.T) {
+	name := "OTLPGaugesWithMultiLabels"
+	g := stats.NewGaugesWithMultiLabels(name, "help", []string{"Keyspace", "ShardName"})
+	g.Set([]string{"ks", "-80"}, 5)
+
+	m := pushOne(t, name, g)
+	gauge, ok := m.Data.(metricdata.Gauge[int64])
+	require.True(t, ok)
+	require.Len(t, gauge.DataPoints, 1)
+	assert.EqualValues(t, 5, gauge.DataPoints[0].Value)
+	assert.Equal(t, attribute.NewSet(attribute.String("keyspace", "ks"), attribute.String("shard_name", "-80")), gauge.DataPoints[0].Attributes)
+}
+
+func TestMultiTimings(t *testing.T) {
+	name := "OTLPMultiTimings"
+	mt := stats.NewMultiTimings(name, "help", []string{"Operation", "Cell"})
+	mt.Add([]string{"Get", "zone1"}, 30*time.Millisecond)
+	mt.Add([]string{"Get", "zone1"}, 2*time.Second)
+
+	m := pushOne(t, name, mt)
+	assert.Equal(t, "vtgate_otlp_multi_timings", m.Name)
+	assert.Equal(t, "s", m.Unit)
+	hist, ok := m.Data.(metricdata.Histogram[float64])
+	require.True(t, ok)
+	require.Len(t, hist.DataPoints, 1)
+
+	dp := hist.DataPoints[0]
+	assert.Equal(t, attribute.NewSet(attribute.String("operation", "Get"), attribute.String("cell", "zone1")), dp.Attributes)
+	assert.EqualValues(t, 2, dp.Count)
+	assert.InDelta(t, 2.03, dp.Sum, 1e-9)
+	assert.Equal(t, []float64{0.0005, 0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 5, 10}, dp.Bounds)
+	assert.Equal(t, []uint64{0, 0, 0, 0, 1, 0, 0, 0, 1, 0, 0}, dp.BucketCounts)
+}
+
+func TestUnexportedStat(t *testing.T) {
+	b := newBackend("vtgate", &fakeExporter{}, resource.Empty())
+	c := b.collector()
+	c.collectOne("OTLPString", stats.NewString(""))
+	assert.Empty(t, c.metrics)
+}
@@ -0,0 +1,280 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package otlp
+
+import (
+	"expvar"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/resource"
+
+	"vitess.io/vitess/go/stats"
+)
+
+// scope is the instrumentation scope of the metrics.
+var scope = instrumentation.Scope{Name: "vitess.io/vitess/go/stats"}
+
+// collector tracks state for a single pass of stats reporting / data collection.
+type collector struct {
+	namespace string
+	start     time.Time
+	now       time.Time
+	metrics   []metricdata.Metrics
+}
+
+func (c *collector) collectAll() {
+	expvar.Do(func(kv expvar.KeyValue) {
+		c.addExpVar(kv)
+	})
+}
+
+func (c *collector) collectOne(name string, v expvar.Var) {
+	c.addExpVar(expvar.KeyValue{
+		Key:   name,
+		Value: v,
+	})
+}
+
+func (c *collector) resourceMetrics(res *resource.Resource) *metricdata.ResourceMetrics {
+	return &metricdata.ResourceMetrics{
+		Resource: res,
+		ScopeMetrics: []metricdata.ScopeMetrics{{
+			Scope:   scope,
+			Metrics: c.metrics,
+		}},
+	}
+}
+
+// addExpVar adds the metric of an expvar. How an expvar is translated depends
+// on its type, as in the Prometheus backend. The expvars of the other types,
+// like strings, are not exported.
+func (c *collector) addExpVar(kv expvar.KeyValue) {
+	k := kv.Key
+	switch v := kv.Value.(type) {
+	case *stats.Counter:
+		c.addSum(k, v.Help(), "", []int64{v.Get()}, nil)
+	case *stats.CounterFunc:
+		c.addSum(k, v.Help(), "", []int64{v.F()}, nil)
+	case *stats.Gauge:
+		c.addGauge(k, v.Help(), "", []int64{v.Get()}, nil)
+	case *stats.GaugeFunc:
+		c.addGauge(k, v.Help(), "", []int64{v.F()}, nil)
+	case *stats.GaugeFloat64:
+		c.addFloatGauge(k, v.Help(), "", v.Get())
+	case stats.FloatFunc:
+		c.addFloatGauge(k, v.Help(), "", v())
+	case *stats.CountersWithSingleLabel:
+		c.addCounts(k, v.Help(), []string{v.Label()}, v.Counts(), true)
+	case *stats.CountersWithMultiLabels:
+		c.addCounts(k, v.Help(), v.Labels(), v.Counts(), true)
+	case *stats.CountersFuncWithMultiLabels:
+		c.addCounts(k, v.Help(), v.Labels(), v.Counts(), true)
+	case *stats.GaugesWithSingleLabel:
+		c.addCounts(k, v.Help(), []string{v.Label()}, v.Counts(), false)
+	case *stats.GaugesWithMultiLabels:
+		c.addCounts(k, v.Help(), v.Labels(), v.Counts(), false)
+	case *stats.GaugesFuncWithMultiLabels:
+		c.addCounts(k, v.Help(), v.Labels(), v.Counts(), false)
+	case *stats.CounterDuration:
+		c.addFloatSum(k, v.Help(), "s", v.Get().Seconds())
+	case *stats.CounterDurationFunc:
+		c.addFloatSum(k, v.Help(), "s", v.F().Seconds())
+	case *stats.GaugeDuration:
+		c.addFloatGauge(k, v.Help(), "s", v.Get().Seconds())
+	case *stats.GaugeDurationFunc:
+		c.addFloatGauge(k, v.Help(), "s", v.F().Seconds())
+	case *stats.Timings:
+		c.addTimings(k, v, []string{v.Label()}, false)
+	case *stats.MultiTimings:
+		c.addTimings(k, &v.Timings, v.Labels(), true)
+	case *stats.Histogram:
+		c.addHistogram(k, v.Help(), "", []metricdata.HistogramDataPoint[float64]{c.histogramDataPoint(v, 1, attribute.NewSet())})
+	}
+}
+
+// addCounts adds the metric of the counts of a stat with labels, whose keys
+// are the label values joined with '.'.
+func (c *collector) addCounts(name, help string, labels []string, counts map[string]int64, monotonic bool) {
+	values := make([]int64, 0, len(counts))
+	attrs := make([]attribute.Set, 0, len(counts))
+	for labelValues, count := range counts {
+		values = append(values, count)
+		attrs = append(attrs, c.attributes(labels, labelValues, len(labels) > 1))
+	}
+	if monotonic {
+		c.addSum(name, help, "", values, attrs)
+	} else {
+		c.addGauge(name, help, "", values, attrs)
+	}
+}
+
+// addSum adds a cumulative, monotonic sum with a data point for each value,
+// with the attributes of the same index, if any.
+func (c *collector) addSum(name, help, unit string, values []int64, attrs []attribute.Set) {
+	c.metrics = append(c.metrics, metricdata.Metrics{
+		Name:        c.metricName(name),
+		Description: help,
+		Unit:        unit,
+		Data: metricdata.Sum[int64]{
+			DataPoints:  c.dataPoints(values, attrs),
+			Temporality: metricdata.CumulativeTemporality,
+			IsMonotonic: true,
+		},
+	})
+}
+
+// addGauge adds a gauge with a data point for each value, with the
+// attributes of the same index, if any.
+func (c *collector) addGauge(name, help, unit string, values []int64, attrs []attribute.Set) {
+	c.metrics = append(c.metrics, metricdata.Metrics{
+		Name:        c.metricName(name),
+		Description: help,
+		Unit:        unit,
+		Data: metricdata.Gauge[int64]{
+			DataPoints: c.dataPoints(values, attrs),
+		},
+	})
+}
+
+func (c *collector) addFloatSum(name, help, unit string, value float64) {
+	c.metrics = append(c.metrics, metricdata.Metrics{
+		Name:        c.metricName(name),
+		Description: help,
+		Unit:        unit,
+		Data: metricdata.Sum[float64]{
+			DataPoints: []metricdata.DataPoint[float64]{{
+				StartTime: c.start,
+				Time:      c.now,
+				Value:     value,
+			}},
+			Temporality: metricdata.CumulativeTemporality,
+			IsMonotonic: true,
+		},
+	})
+}
+
+func (c *collector) addFloatGauge(name, help, unit string, value float64) {
+	c.metrics = append(c.metrics, metricdata.Metrics{
+		Name:        c.metricName(name),
+		Description: help,
+		Unit:        unit,
+		Data: metricdata.Gauge[float64]{
+			DataPoints: []metricdata.DataPoint[float64]{{
+				Time:  c.now,
+				Value: value,
+			}},
+		},
+	})
+}
+
+// addTimings adds the histograms of a Timings, in seconds.
+func (c *collector) addTimings(name string, t *stats.Timings, labels []string, multi bool) {
+	histograms := t.Histograms()
+	dataPoints := make([]metricdata.HistogramDataPoint[float64], 0, len(histograms))
+	for labelValues, h := range histograms {
+		dataPoints = append(dataPoints, c.histogramDataPoint(h, 1e9, c.attributes(labels, labelValues, multi)))
+	}
+	c.addHistogram(name, t.Help(), "s", dataPoints)
+}
+
+func (c *collector) addHistogram(name, help, unit string, dataPoints []metricdata.HistogramDataPoint[float64]) {
+	c.metrics = append(c.metrics, metricdata.Metrics{
+		Name:        c.metricName(name),
+		Description: help,
+		Unit:        unit,
+		Data: metricdata.Histogram[float64]{
+			DataPoints:  dataPoints,
+			Temporality: metricdata.CumulativeTemporality,
+		},
+	})
+}
+
+// histogramDataPoint returns the data point of a Histogram, whose values are
+// divided by divideBy, e.g. to convert nanoseconds to seconds.
+func (c *collector) histogramDataPoint(h *stats.Histogram, divideBy float64, attrs attribute.Set) metricdata.HistogramDataPoint[float64] {
+	cutoffs := h.Cutoffs()
+	bounds := make([]float64, len(cutoffs))
+	for i, cutoff := range cutoffs {
+		bounds[i] = float64(cutoff) / divideBy
+	}
+	buckets := h.Buckets()
+	bucketCounts := make([]uint64, len(buckets))
+	for i, count := range buckets {
+		bucketCounts[i] = uint64(count)
+	}
+	return metricdata.HistogramDataPoint[float64]{
+		Attributes:   attrs,
+		StartTime:    c.start,
+		Time:         c.now,
+		Count:        uint64(h.Count()),
+		Bounds:       bounds,
+		BucketCounts: bucketCounts,
+		Sum:          float64(h.Total()) / divideBy,
+	}
+}
+
+func (c *collector) dataPoints(values []int64, attrs []attribute.Set) []metricdata.DataPoint[int64] {
+	dataPoints := make([]metricdata.DataPoint[int64], len(values))
+	for i, value := range values {
+		dataPoints[i] = metricdata.DataPoint[int64]{
+			StartTime: c.start,
+			Time:      c.now,
+			Value:     value,
+		}
+		if attrs != nil {
+			dataPoints[i].Attributes = attrs[i]
+		}
+	}
+	return dataPoints
+}
+
+// attributes returns the attributes of the labels of a stat, whose values are
+// joined with '.' if multi.
+func (c *collector) attributes(labels []string, labelValues string, multi bool) attribute.Set {
+	values := []string{labelValues}
+	if multi {
+		values = strings.Split(labelValues, ".")
+	}
+	kvs := make([]attribute.KeyValue, 0, len(labels))
+	for i, label := range labels {
+		if i < len(values) {
+			kvs = append(kvs, attribute.String(normalizeName(label), values[i]))
+		}
+	}
+	return attribute.NewSet(kvs...)
+}
+
+// metricName returns the name of the metric of a stat, as in the Prometheus
+// backend.
+func (c *collector) metricName(name string) string {
+	s := strings.TrimPrefix(normalizeName(name), c.namespace+"_")
+	if c.namespace == "" {
+		return s
+	}
+	return c.namespace + "_" + s
+}
+
+// normalizeName converts the name of a stat or label to snake case, as in the
+// Prometheus backend.
+func normalizeName(name string) string {
+	r := strings.NewReplacer("VSchema", "vschema", "VtGate", "vtgate")
+	return stats.GetSnakeName(r.Replace(name))
+}
@@ -0,0 +1,23 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package otlp adds support for pushing stats to an OpenTelemetry collector
+// over OTLP/gRPC, with --stats_backend=otlp and --emit_stats.
+//
+// The stats are exported with the names and labels of the Prometheus backend,
+// as cumulative sums, gauges and explicit-bucket histograms, with the
+// --stats_common_tags as attributes of the resource.
+package otlp
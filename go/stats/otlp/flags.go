@@ -0,0 +1,46 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package otlp
+
+import (
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"vitess.io/vitess/go/vt/servenv"
+)
+
+var (
+	endpoint string
+	insecure bool
+	headers  map[string]string
+	timeout  = 10 * time.Second
+)
+
+func registerFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&endpoint, "otlp-endpoint", endpoint, "host:port of the OTLP/gRPC endpoint of the OpenTelemetry collector to push the stats to with --stats_backend=otlp (defaults to the OTEL_EXPORTER_OTLP_ENDPOINT environment variable, or localhost:4317)")
+	fs.BoolVar(&insecure, "otlp-insecure", insecure, "push the stats to the --otlp-endpoint without TLS")
+	fs.StringToStringVar(&headers, "otlp-headers", headers, "headers, e.g. for authentication, to send with the stats pushed to the --otlp-endpoint")
+	fs.DurationVar(&timeout, "otlp-timeout", timeout, "timeout of each push of the stats to the --otlp-endpoint")
+}
+
+func init() {
+	servenv.OnParseFor("vtbackup", registerFlags)
+	servenv.OnParseFor("vtctld", registerFlags)
+	servenv.OnParseFor("vtgate", registerFlags)
+	servenv.OnParseFor("vttablet", registerFlags)
+}
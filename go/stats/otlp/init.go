@@ -0,0 +1,94 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package otlp
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/sdk/resource"
+
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/servenv"
+)
+
+// Init creates an OTLP backend pushing the stats of the service, whose name
+// also prefixes the names of the metrics, and registers it as the "otlp"
+// PushBackend. If it fails to create one, this is a noop.
+func Init(serviceName string) {
+	// Needs to happen in servenv.OnRun() instead of init because it requires flag parsing and logging
+	servenv.OnRun(func() {
+		log.Info("Initializing otlp backend...")
+		b, err := newOTLPBackend(serviceName)
+		if err != nil {
+			log.Infof("Failed to initialize otlp backend: %v", err)
+			return
+		}
+		stats.RegisterPushBackend("otlp", b)
+		servenv.OnTerm(b.shutdown)
+		log.Info("Initialized otlp backend.")
+	})
+}
+
+// InitWithoutServenv initializes the otlp backend without servenv.
+func InitWithoutServenv(serviceName string) (stats.PushBackend, error) {
+	b, err := newOTLPBackend(serviceName)
+	if err != nil {
+		return nil, err
+	}
+	stats.RegisterPushBackend("otlp", b)
+	return b, nil
+}
+
+// newOTLPBackend returns a backend pushing the stats with an OTLP/gRPC
+// exporter configured by the flags.
+func newOTLPBackend(serviceName string) (*backend, error) {
+	opts := []otlpmetricgrpc.Option{
+		otlpmetricgrpc.WithTimeout(timeout),
+	}
+	if endpoint != "" {
+		opts = append(opts, otlpmetricgrpc.WithEndpoint(endpoint))
+	}
+	if insecure {
+		opts = append(opts, otlpmetricgrpc.WithInsecure())
+	}
+	if len(headers) > 0 {
+		opts = append(opts, otlpmetricgrpc.WithHeaders(headers))
+	}
+
+	// The exporter connects lazily, so this does not wait for the collector.
+	exporter, err := otlpmetricgrpc.New(context.Background(), opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return newBackend(serviceName, exporter, newResource(serviceName)), nil
+}
+
+// newResource returns the resource the stats are pushed for: the service, with
+// the --stats_common_tags as attributes.
+func newResource(serviceName string) *resource.Resource {
+	attrs := []attribute.KeyValue{
+		attribute.String("service.name", serviceName),
+	}
+	for k, v := range stats.ParseCommonTags(stats.CommonTags) {
+		attrs = append(attrs, attribute.String(k, v))
+	}
+	return resource.NewSchemaless(attrs...)
+}
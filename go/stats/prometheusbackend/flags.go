@@ -0,0 +1,52 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package prometheusbackend
+
+import (
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"vitess.io/vitess/go/vt/servenv"
+)
+
+var (
+	timingsHistograms           bool
+	timingsBuckets              = []float64{0.0005, 0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 5, 10}
+	nativeHistogramBucketFactor float64
+	exemplars                   bool
+
+	pushgatewayURL      string
+	pushgatewayJob      string
+	pushgatewayInterval time.Duration
+)
+
+func registerFlags(fs *pflag.FlagSet) {
+	fs.BoolVar(&timingsHistograms, "prometheus-timings-histograms", timingsHistograms, "export the Timings and MultiTimings stats as Prometheus histograms observing each timing, with the buckets of --prometheus-timings-buckets, rather than with the fixed buckets of the stats")
+	fs.Float64SliceVar(&timingsBuckets, "prometheus-timings-buckets", timingsBuckets, "upper bounds, in seconds, of the buckets of the histograms exported with --prometheus-timings-histograms")
+	fs.Float64Var(&nativeHistogramBucketFactor, "prometheus-native-histogram-bucket-factor", nativeHistogramBucketFactor, "if greater than 1, the histograms exported with --prometheus-timings-histograms are also exported as native (exponential) histograms, whose consecutive buckets grow by at most this factor, to the scrapers that support them")
+	fs.BoolVar(&exemplars, "prometheus-exemplars", exemplars, "attach the ID of the trace of the timings observed by the histograms exported with --prometheus-timings-histograms, if any, to their samples as exemplars, and serve /metrics in the OpenMetrics format to the scrapers requesting it, which exemplars require")
+	fs.StringVar(&pushgatewayURL, "prometheus-pushgateway-url", pushgatewayURL, "URL of a Prometheus Pushgateway to push the metrics to when the process exits, and every --prometheus-pushgateway-interval if set, for the processes that do not live long enough to be scraped")
+	fs.StringVar(&pushgatewayJob, "prometheus-pushgateway-job", pushgatewayJob, "job label of the metrics pushed to the --prometheus-pushgateway-url (defaults to the name of the binary)")
+	fs.DurationVar(&pushgatewayInterval, "prometheus-pushgateway-interval", pushgatewayInterval, "interval between the pushes of the metrics to the --prometheus-pushgateway-url while the process runs, if not zero")
+}
+
+func init() {
+	for _, cmd := range []string{"mysqlctl", "mysqlctld", "vtbackup", "vtctld", "vtgate", "vtorc", "vttablet"} {
+		servenv.OnParseFor(cmd, registerFlags)
+	}
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package prometheusbackend
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/trace"
+	"vitess.io/vitess/go/vt/log"
+)
+
+// timingsHistogram is the histogram a Timings or MultiTimings is exported as
+// with --prometheus-timings-histograms.
+type timingsHistogram struct {
+	vec *prometheus.HistogramVec
+	// multi is whether the category names of the timings are compound names
+	// made of one value for each label, joined with '.'.
+	multi bool
+}
+
+var (
+	timingsHistogramsMu sync.RWMutex
+	histogramsByTimings = map[*stats.Timings]*timingsHistogram{}
+)
+
+func newTimingsHistogram(t *stats.Timings, name string, labels []string, multi bool) {
+	h := &timingsHistogram{
+		vec: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:                        name,
+			Help:                        t.Help(),
+			Buckets:                     timingsBuckets,
+			NativeHistogramBucketFactor: nativeHistogramBucketFactor,
+		}, labelsToSnake(labels)),
+		multi: multi,
+	}
+	prometheus.MustRegister(h.vec)
+
+	// Export the categories the timings were initialized with, as the
+	// collectors of the other stats do.
+	for cat := range t.Histograms() {
+		h.observer(cat)
+	}
+
+	timingsHistogramsMu.Lock()
+	defer timingsHistogramsMu.Unlock()
+	histogramsByTimings[t] = h
+}
+
+// observer returns the histogram of the category of timings, or nil if its
+// name does not have a value for each label.
+func (h *timingsHistogram) observer(name string) prometheus.Observer {
+	labelValues := []string{name}
+	if h.multi {
+		labelValues = strings.Split(name, ".")
+	}
+	observer, err := h.vec.GetMetricWithLabelValues(labelValues...)
+	if err != nil {
+		log.Errorf("Error adding metric: %v", err)
+		return nil
+	}
+	return observer
+}
+
+// observeTiming is registered with stats.RegisterTimingsHook to observe the
+// timings in their histograms. With --prometheus-exemplars, the ID of the trace
+// in ctx, if any, is attached to the sample as an exemplar.
+func observeTiming(ctx context.Context, t *stats.Timings, name string, elapsed time.Duration) {
+	timingsHistogramsMu.RLock()
+	h, ok := histogramsByTimings[t]
+	timingsHistogramsMu.RUnlock()
+	if !ok {
+		return
+	}
+
+	observer := h.observer(name)
+	if observer == nil {
+		return
+	}
+
+	if exemplars {
+		if traceID, ok := trace.TraceID(ctx); ok {
+			observer.(prometheus.ExemplarObserver).ObserveWithExemplar(elapsed.Seconds(), prometheus.Labels{"trace_id": traceID})
+			return
+		}
+	}
+	observer.Observe(elapsed.Seconds())
+}
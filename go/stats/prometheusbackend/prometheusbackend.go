@@ -39,9 +39,21 @@ var (
 
 // Init initializes the Prometheus be with the given namespace.
 func Init(namespace string) {
-	servenv.HTTPHandle("/metrics", promhttp.Handler())
+	servenv.HTTPHandle("/metrics", promhttp.InstrumentMetricHandler(
+		prometheus.DefaultRegisterer,
+		promhttp.HandlerFor(prometheus.DefaultGatherer, promhttp.HandlerOpts{
+			// Exemplars are only exposed in the OpenMetrics format.
+			EnableOpenMetrics: exemplars,
+		}),
+	))
 	be.namespace = namespace
+	if timingsHistograms {
+		stats.RegisterTimingsHook(observeTiming)
+	}
 	stats.Register(be.publishPrometheusMetric)
+	if pushgatewayURL != "" {
+		startPushing(namespace)
+	}
 }
 
 // publishPrometheusMetric is used to publish the metric to Prometheus.
@@ -80,14 +92,22 @@ func (be PromBackend) publishPrometheusMetric(name string, v expvar.Var) {
 	case *stats.GaugeDurationFunc:
 		newMetricFuncCollector(st, be.buildPromName(name), prometheus.GaugeValue, func() float64 { return st.F().Seconds() })
 	case *stats.Timings:
-		newTimingsCollector(st, be.buildPromName(name))
+		if timingsHistograms {
+			newTimingsHistogram(st, be.buildPromName(name), []string{st.Label()}, false)
+		} else {
+			newTimingsCollector(st, be.buildPromName(name))
+		}
 	case *stats.MultiTimings:
-		newMultiTimingsCollector(st, be.buildPromName(name))
+		if timingsHistograms {
+			newTimingsHistogram(&st.Timings, be.buildPromName(name), st.Labels(), true)
+		} else {
+			newMultiTimingsCollector(st, be.buildPromName(name))
+		}
 	case *stats.Histogram:
 		newHistogramCollector(st, be.buildPromName(name))
 	case *stats.StringMapFuncWithMultiLabels:
 		newStringMapFuncWithMultiLabelsCollector(st, be.buildPromName(name))
-	case *stats.String, stats.StringFunc, stats.StringMapFunc, *stats.Rates, *stats.RatesFunc:
+	case *stats.String, stats.StringFunc, stats.StringMapFunc, *stats.Rates, *stats.RatesFunc, *stats.RateGauges:
 		// Silently ignore these types since they don't make sense to
 		// export to Prometheus' data model.
 	default:
@@ -21,6 +21,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
@@ -338,6 +339,45 @@ func TestPrometheusMultiTimings(t *testing.T) {
 	}
 }
 
+func TestPrometheusTimingsHistograms(t *testing.T) {
+	timingsHistograms = true
+	timingsBuckets = []float64{0.1, 1}
+	stats.RegisterTimingsHook(observeTiming)
+	defer func() {
+		timingsHistograms = false
+		timingsBuckets = []float64{0.0005, 0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 5, 10}
+		stats.RegisterTimingsHook(nil)
+	}()
+
+	name := "blah_timings_histogram"
+	timing := stats.NewTimings(name, "help", "category", "cat1", "cat2")
+	timing.Add("cat1", 30*time.Millisecond)
+	timing.Add("cat1", 200*time.Millisecond)
+	timing.Add("cat1", 2*time.Second)
+
+	multiName := "blah_multitimings_histogram"
+	multiTiming := stats.NewMultiTimings(multiName, "help", []string{"Label1", "Label2"})
+	multiTiming.Add([]string{"foo", "bar.baz"}, 500*time.Millisecond)
+
+	response := testMetricsHandler(t)
+	expect := []string{
+		fmt.Sprintf("%s_%s_bucket{category=\"cat1\",le=\"0.1\"} 1", namespace, name),
+		fmt.Sprintf("%s_%s_bucket{category=\"cat1\",le=\"1\"} 2", namespace, name),
+		fmt.Sprintf("%s_%s_bucket{category=\"cat1\",le=\"+Inf\"} 3", namespace, name),
+		fmt.Sprintf("%s_%s_sum{category=\"cat1\"} 2.23", namespace, name),
+		fmt.Sprintf("%s_%s_count{category=\"cat1\"} 3", namespace, name),
+		// The categories the timings were initialized with are exported too.
+		fmt.Sprintf("%s_%s_count{category=\"cat2\"} 0", namespace, name),
+		fmt.Sprintf("%s_%s_bucket{label1=\"foo\",label2=\"bar_baz\",le=\"1\"} 1", namespace, multiName),
+		fmt.Sprintf("%s_%s_count{label1=\"foo\",label2=\"bar_baz\"} 1", namespace, multiName),
+	}
+	for _, line := range expect {
+		if !strings.Contains(response.Body.String(), line) {
+			t.Fatalf("Expected result to contain %s, got %s", line, response.Body.String())
+		}
+	}
+}
+
 func TestPrometheusMultiTimings_PanicWrongLength(t *testing.T) {
 	defer func() {
 		if r := recover(); r == nil {
@@ -372,6 +412,42 @@ func TestPrometheusHistogram(t *testing.T) {
 	}
 }
 
+func TestPushgateway(t *testing.T) {
+	var paths []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.Method+" "+r.URL.Path)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	defer func() {
+		pushgatewayURL = ""
+		pushgatewayJob = ""
+	}()
+	pushgatewayURL = srv.URL
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := newPusher("vtbackup").Push(); err != nil {
+		t.Fatal(err)
+	}
+	pushgatewayJob = "backups"
+	if err := newPusher("vtbackup").Push(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"PUT /metrics/job/vtbackup/instance/" + hostname,
+		"PUT /metrics/job/backups/instance/" + hostname,
+	}
+	if !reflect.DeepEqual(expected, paths) {
+		t.Fatalf("Expected pushes to %v, got %v", expected, paths)
+	}
+}
+
 func testMetricsHandler(t *testing.T) *httptest.ResponseRecorder {
 	req, _ := http.NewRequest("GET", "/metrics", nil)
 	response := httptest.NewRecorder()
@@ -0,0 +1,83 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package prometheusbackend
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/push"
+
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/servenv"
+)
+
+// pushTimeout bounds the time to push the metrics to the Pushgateway.
+const pushTimeout = 30 * time.Second
+
+// newPusher returns the pusher of the metrics of the default registry to the
+// --prometheus-pushgateway-url, grouped by job and instance, i.e. hostname.
+func newPusher(job string) *push.Pusher {
+	if pushgatewayJob != "" {
+		job = pushgatewayJob
+	}
+	pusher := push.New(pushgatewayURL, job).Gatherer(prometheus.DefaultGatherer)
+	if hostname, err := os.Hostname(); err == nil {
+		pusher = pusher.Grouping("instance", hostname)
+	}
+	return pusher
+}
+
+// startPushing pushes the metrics to the --prometheus-pushgateway-url when the
+// process terminates, and every --prometheus-pushgateway-interval until then,
+// if set.
+func startPushing(job string) {
+	pusher := newPusher(job)
+
+	pushMetrics := func() {
+		ctx, cancel := context.WithTimeout(context.Background(), pushTimeout)
+		defer cancel()
+		if err := pusher.PushContext(ctx); err != nil {
+			log.Warningf("Failed to push the metrics to the Prometheus Pushgateway %s: %v", pushgatewayURL, err)
+		}
+	}
+
+	done := make(chan struct{})
+	if pushgatewayInterval > 0 {
+		go func() {
+			ticker := time.NewTicker(pushgatewayInterval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-done:
+					return
+				case <-ticker.C:
+					pushMetrics()
+				}
+			}
+		}()
+	}
+
+	// The process waits for the OnTermSync hooks, so the final metrics are
+	// pushed before it exits.
+	servenv.OnTermSync(func() {
+		close(done)
+		pushMetrics()
+	})
+}
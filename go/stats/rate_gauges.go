@@ -0,0 +1,180 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package stats
+
+import (
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/vt/log"
+)
+
+var (
+	rateCounters []string
+	rateInterval = 10 * time.Second
+	rateWindow   = 1 * time.Minute
+)
+
+// RateGauges are the smoothed per-second rates of the categories of a counter,
+// computed in-process over a sliding window. They are derived from the
+// counters of --stats_rate_counters for the push-based backends, like statsd,
+// which lack server-side rate functions.
+type RateGauges struct {
+	mu      sync.Mutex
+	counter CountTracker
+	labels  []string
+	help    string
+	// samples are the counts of the counter sampled over the window, the
+	// oldest first.
+	samples []rateSample
+	size    int
+}
+
+type rateSample struct {
+	time   time.Time
+	counts map[string]int64
+}
+
+// newRateGauges creates the RateGauges of counter, whose categories are named
+// by labels, and publishes them if name is set. The rates are computed over
+// the last size samples.
+func newRateGauges(name, help string, counter CountTracker, labels []string, size int) *RateGauges {
+	rg := &RateGauges{
+		counter: counter,
+		labels:  labels,
+		help:    help,
+		size:    max(size, 2),
+	}
+	if name != "" {
+		publish(name, rg)
+	}
+	return rg
+}
+
+// snapshot samples the counts of the counter at now.
+func (rg *RateGauges) snapshot(now time.Time) {
+	counts := rg.counter.Counts()
+
+	rg.mu.Lock()
+	defer rg.mu.Unlock()
+	rg.samples = append(rg.samples, rateSample{time: now, counts: counts})
+	if len(rg.samples) > rg.size {
+		rg.samples = rg.samples[len(rg.samples)-rg.size:]
+	}
+}
+
+// Rates returns the rate per second of each category of the counter, between
+// the oldest and the latest of its samples. The categories are named as in the
+// counter. A counter with no labels has a single category named "".
+func (rg *RateGauges) Rates() map[string]float64 {
+	rg.mu.Lock()
+	defer rg.mu.Unlock()
+
+	rates := make(map[string]float64)
+	if len(rg.samples) < 2 {
+		return rates
+	}
+	oldest, latest := rg.samples[0], rg.samples[len(rg.samples)-1]
+	elapsed := latest.time.Sub(oldest.time).Seconds()
+	if elapsed <= 0 {
+		return rates
+	}
+	for k, v := range latest.counts {
+		// A category the counter reset has no rate until it is sampled again.
+		rates[k] = float64(max(v-oldest.counts[k], 0)) / elapsed
+	}
+	return rates
+}
+
+// Labels returns the labels of the counter the rates are derived from.
+func (rg *RateGauges) Labels() []string {
+	return rg.labels
+}
+
+// Help returns the help string.
+func (rg *RateGauges) Help() string {
+	return rg.help
+}
+
+// String is the implementation of expvar.Var.
+func (rg *RateGauges) String() string {
+	data, err := json.Marshal(rg.Rates())
+	if err != nil {
+		data, _ = json.Marshal(err.Error())
+	}
+	return string(data)
+}
+
+// rateCountTracker returns the CountTracker and the labels of a counter the
+// rates can be derived from.
+func rateCountTracker(v expvar.Var) (CountTracker, []string, bool) {
+	switch v := v.(type) {
+	case *Counter:
+		return wrappedCountTracker{f: func() map[string]int64 { return map[string]int64{"": v.Get()} }}, nil, true
+	case *CounterFunc:
+		return wrappedCountTracker{f: func() map[string]int64 { return map[string]int64{"": v.F()} }}, nil, true
+	case *CountersWithSingleLabel:
+		return v, []string{v.Label()}, true
+	case *CountersWithMultiLabels:
+		return v, v.Labels(), true
+	case *CountersFuncWithMultiLabels:
+		return v, v.Labels(), true
+	}
+	return nil, nil, false
+}
+
+// trackRateGauges samples the counters of --stats_rate_counters every
+// --stats_rate_interval, publishing their RateGauges as <name>Rate.
+func trackRateGauges() {
+	ticker := time.NewTicker(rateInterval)
+	defer ticker.Stop()
+
+	derived := make(map[string]*RateGauges, len(rateCounters))
+	for range ticker.C {
+		deriveRateGauges(derived, timeNow())
+	}
+}
+
+// deriveRateGauges samples the counters of --stats_rate_counters at now. The
+// RateGauges of a counter are created the first time it is found published,
+// and recorded in derived; the counters which are not published yet are
+// looked up again on the next call.
+func deriveRateGauges(derived map[string]*RateGauges, now time.Time) {
+	for _, name := range rateCounters {
+		rg, ok := derived[name]
+		if !ok {
+			v := expvar.Get(name)
+			if v == nil {
+				continue
+			}
+			counter, labels, ok := rateCountTracker(v)
+			if ok {
+				size := int(rateWindow/rateInterval) + 1
+				rg = newRateGauges(name+"Rate", fmt.Sprintf("Rate per second of %s over %v", name, rateWindow), counter, labels, size)
+			} else {
+				log.Warningf("Cannot derive the rates of stat %s: %T is not a counter", name, v)
+			}
+			derived[name] = rg
+		}
+		if rg != nil {
+			rg.snapshot(now)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package stats
+
+import (
+	"expvar"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateGauges(t *testing.T) {
+	clearStats()
+	c := NewCountersWithSingleLabel("rate_gauges_counter", "help", "cell")
+	rg := newRateGauges("", "help", c, []string{"cell"}, 3)
+
+	now := time.Now()
+	assert.Empty(t, rg.Rates())
+
+	rg.snapshot(now)
+	c.Add("cell1", 10)
+	rg.snapshot(now.Add(5 * time.Second))
+	assert.Equal(t, map[string]float64{"cell1": 2}, rg.Rates())
+
+	// The rates are averaged over the last 3 samples.
+	c.Add("cell1", 20)
+	c.Add("cell2", 10)
+	rg.snapshot(now.Add(10 * time.Second))
+	assert.Equal(t, map[string]float64{"cell1": 3, "cell2": 1}, rg.Rates())
+
+	rg.snapshot(now.Add(15 * time.Second))
+	assert.Equal(t, map[string]float64{"cell1": 2, "cell2": 1}, rg.Rates())
+
+	// A reset counter has no rate until the window is past the reset.
+	c.ResetAll()
+	rg.snapshot(now.Add(20 * time.Second))
+	assert.Equal(t, map[string]float64{}, rg.Rates())
+	assert.Equal(t, `{}`, rg.String())
+}
+
+func TestDeriveRateGauges(t *testing.T) {
+	clearStats()
+	rateCounters = []string{"DeriveRateCounter", "DeriveRateCounters", "DeriveRateGauge", "DeriveRateMissing"}
+	defer func() { rateCounters = nil }()
+
+	c := NewCounter("DeriveRateCounter", "help")
+	mc := NewCountersWithMultiLabels("DeriveRateCounters", "help", []string{"a", "b"})
+	NewGauge("DeriveRateGauge", "help")
+
+	derived := map[string]*RateGauges{}
+	now := time.Now()
+	deriveRateGauges(derived, now)
+	c.Add(30)
+	mc.Add([]string{"a1", "b1"}, 60)
+	deriveRateGauges(derived, now.Add(rateInterval))
+
+	assert.Len(t, derived, 3)
+	assert.Nil(t, derived["DeriveRateGauge"])
+	assert.Equal(t, map[string]float64{"": 3}, derived["DeriveRateCounter"].Rates())
+	assert.Equal(t, map[string]float64{"a1.b1": 6}, derived["DeriveRateCounters"].Rates())
+	assert.Equal(t, []string{"a", "b"}, derived["DeriveRateCounters"].Labels())
+
+	v := expvar.Get("DeriveRateCountersRate")
+	require.NotNil(t, v)
+	assert.Equal(t, `{"a1.b1":6}`, v.String())
+}
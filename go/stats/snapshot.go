@@ -0,0 +1,105 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package stats
+
+import (
+	"expvar"
+	"time"
+)
+
+// Snapshot is the integer values of the published stats at a point in time,
+// by stat name and then by label values joined with '.', as in the Counts of
+// the stats with labels. The value of a stat without labels is keyed by "".
+// The values of Timings and Histograms are their counts.
+//
+// Snapshots let tests assert the stats an operation recorded without reading
+// or resetting the package-level stats:
+//
+//	snapshot := stats.TakeSnapshot()
+//	conn.Get(ctx, "path")
+//	assert.Equal(t, stats.Snapshot{"TopologyConnOperations": {"Get.global": 1, "All": 1}}, snapshot.Diff())
+//
+// The stats are global, so such tests should not run in parallel with other
+// tests recording the same stats.
+type Snapshot map[string]map[string]int64
+
+// TakeSnapshot returns the current values of all the published stats with
+// integer values.
+func TakeSnapshot() Snapshot {
+	s := make(Snapshot)
+	expvar.Do(func(kv expvar.KeyValue) {
+		if values := snapshotValues(kv.Value); values != nil {
+			s[kv.Key] = values
+		}
+	})
+	return s
+}
+
+// snapshotValues returns the values of v, or nil if it has no integer values.
+func snapshotValues(v expvar.Var) map[string]int64 {
+	switch v := v.(type) {
+	case CountTracker:
+		return v.Counts()
+	case interface{ Get() int64 }:
+		return map[string]int64{"": v.Get()}
+	case interface{ Get() time.Duration }:
+		return map[string]int64{"": int64(v.Get())}
+	}
+	return nil
+}
+
+// Diff returns the changes of the values of the stats since s was taken. See
+// Sub.
+func (s Snapshot) Diff() Snapshot {
+	return TakeSnapshot().Sub(s)
+}
+
+// Sub returns the values of s less the values of before, leaving out the
+// values which are the same in both, and the stats with no changed values. The
+// values missing from either snapshot, like those of stats which were reset,
+// count as 0.
+func (s Snapshot) Sub(before Snapshot) Snapshot {
+	diff := make(Snapshot)
+	add := func(name, key string, delta int64) {
+		if delta == 0 {
+			return
+		}
+		if diff[name] == nil {
+			diff[name] = make(map[string]int64)
+		}
+		diff[name][key] = delta
+	}
+	for name, values := range s {
+		for key, value := range values {
+			add(name, key, value-before[name][key])
+		}
+	}
+	for name, values := range before {
+		for key, value := range values {
+			if _, ok := s[name][key]; !ok {
+				add(name, key, -value)
+			}
+		}
+	}
+	return diff
+}
+
+// Get returns the value of the stat name for the label values key, or 0 if the
+// snapshot has no such value.
+func (s Snapshot) Get(name, key string) int64 {
+	return s[name][key]
+}
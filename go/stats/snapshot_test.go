@@ -0,0 +1,55 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package stats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnapshot(t *testing.T) {
+	clearStats()
+	c := NewCounter("SnapshotCounter", "help")
+	g := NewGaugeDuration("SnapshotGaugeDuration", "help")
+	mc := NewCountersWithMultiLabels("SnapshotCounters", "help", []string{"a", "b"})
+	mt := NewMultiTimings("SnapshotTimings", "help", []string{"a", "b"})
+	NewString("SnapshotString").Set("value")
+
+	c.Add(1)
+	mc.Add([]string{"a1", "b1"}, 2)
+	snapshot := TakeSnapshot()
+	assert.EqualValues(t, 1, snapshot.Get("SnapshotCounter", ""))
+	assert.EqualValues(t, 2, snapshot.Get("SnapshotCounters", "a1.b1"))
+	assert.NotContains(t, snapshot, "SnapshotString")
+
+	c.Add(2)
+	g.Set(time.Second)
+	mc.Add([]string{"a1", "b2"}, 1)
+	mt.Add([]string{"a1", "b1"}, time.Millisecond)
+	assert.Equal(t, Snapshot{
+		"SnapshotCounter":       {"": 2},
+		"SnapshotGaugeDuration": {"": int64(time.Second)},
+		"SnapshotCounters":      {"a1.b2": 1},
+		"SnapshotTimings":       {"a1.b1": 1, "All": 1},
+	}, snapshot.Diff())
+
+	// The values which disappeared count as 0.
+	mc.ResetAll()
+	assert.Equal(t, map[string]int64{"a1.b1": -2}, snapshot.Diff()["SnapshotCounters"])
+}
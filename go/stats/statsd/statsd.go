@@ -59,6 +59,18 @@ func makeLabels(labelNames []string, labelValsCombined string) []string {
 	return tags
 }
 
+// makeTimingsLabels builds the tag list of a category of a Timings or
+// MultiTimings, with a tag for each of its labels.
+func makeTimingsLabels(timings *stats.Timings, category string) []string {
+	labels := timings.Labels()
+	if len(labels) == 1 {
+		// The category of a Timings is the value of its label, even if it
+		// contains a '.'.
+		return makeLabel(labels[0], category)
+	}
+	return makeLabels(labels, category)
+}
+
 func makeCommonTags(tags map[string]string) []string {
 	var commonTags []string
 	for k, v := range tags {
@@ -98,7 +110,7 @@ func InitWithoutServenv(namespace string) {
 	sb.sampleRate = statsdSampleRate
 	stats.RegisterPushBackend("statsd", sb)
 	stats.RegisterTimerHook(func(statsName, name string, value int64, timings *stats.Timings) {
-		tags := makeLabels(strings.Split(timings.Label(), "."), name)
+		tags := makeTimingsLabels(timings, name)
 		if err := statsdC.TimeInMilliseconds(statsName, float64(value), tags, sb.sampleRate); err != nil {
 			log.Errorf("Fail to TimeInMilliseconds %v: %v", statsName, err)
 		}
@@ -185,6 +197,16 @@ func (sb StatsBackend) addExpVar(kv expvar.KeyValue) {
 				log.Errorf("Failed to add GaugesWithSingleLabel %v for key %v", v, k)
 			}
 		}
+	case *stats.RateGauges:
+		for labelVals, val := range v.Rates() {
+			var tags []string
+			if len(v.Labels()) > 0 {
+				tags = makeLabels(v.Labels(), labelVals)
+			}
+			if err := sb.statsdClient.Gauge(k, val, tags, sb.sampleRate); err != nil {
+				log.Errorf("Failed to add RateGauges %v for key %v", v, k)
+			}
+		}
 	case *stats.Timings, *stats.MultiTimings, *stats.Histogram:
 		// it does not make sense to export static expvar to statsd,
 		// instead we rely on hooks to integrate with statsd' timing and histogram api directly
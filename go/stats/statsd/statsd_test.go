@@ -25,7 +25,7 @@ func getBackend(t *testing.T) (StatsBackend, *net.UDPConn) {
 	sb.sampleRate = 1
 	sb.statsdClient = client
 	stats.RegisterTimerHook(func(stats, name string, value int64, timings *stats.Timings) {
-		tags := makeLabels(strings.Split(timings.Label(), "."), name)
+		tags := makeTimingsLabels(timings, name)
 		client.TimeInMilliseconds(stats, float64(value), tags, sb.sampleRate)
 	})
 	stats.RegisterHistogramHook(func(name string, val int64) {
@@ -479,6 +479,14 @@ func TestStatsdHistogram(t *testing.T) {
 	}
 }
 
+func TestMakeTimingsLabels(t *testing.T) {
+	timings := stats.NewTimings("", "help", "Table")
+	assert.Equal(t, []string{"Table:ks.t1"}, makeTimingsLabels(timings, "ks.t1"))
+
+	multiTimings := stats.NewMultiTimings("", "help", []string{"Operation", "Cell"})
+	assert.Equal(t, []string{"Operation:Get", "Cell:zone1"}, makeTimingsLabels(&multiTimings.Timings, "Get.zone1"))
+}
+
 func TestMakeCommonTags(t *testing.T) {
 	res1 := makeCommonTags(map[string]string{})
 	assert.Equal(t, 0, len(res1))
@@ -17,6 +17,7 @@ limitations under the License.
 package stats
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"sync"
@@ -37,6 +38,8 @@ type Timings struct {
 	help          string
 	label         string
 	labelCombined bool
+	// labels are the names of the labels of a MultiTimings.
+	labels []string
 }
 
 // NewTimings creates a new Timings object, and publishes it if name is set.
@@ -72,6 +75,12 @@ func (t *Timings) Reset() {
 
 // Add will add a new value to the named histogram.
 func (t *Timings) Add(name string, elapsed time.Duration) {
+	t.AddContext(context.Background(), name, elapsed)
+}
+
+// AddContext is like Add, with the context of the event timed, such as one
+// carrying its trace, for the hooks observing the timings.
+func (t *Timings) AddContext(ctx context.Context, name string, elapsed time.Duration) {
 	if t.labelCombined {
 		name = StatsAllStr
 	}
@@ -82,17 +91,35 @@ func (t *Timings) Add(name string, elapsed time.Duration) {
 
 	// Create Histogram if it does not exist.
 	if !ok {
+		var overflow bool
 		t.mu.Lock()
 		hist, ok = t.histograms[name]
+		if !ok {
+			// Aggregate the new categories over the limit of label
+			// combinations into the other one.
+			if limit := labelCombinationsLimit(t.name); limit > 0 && len(t.histograms) >= limit {
+				if other := otherLabels(len(t.labels)); name != other {
+					name, overflow = other, true
+					hist, ok = t.histograms[name]
+				}
+			}
+		}
 		if !ok {
 			hist = NewGenericHistogram("", "", bucketCutoffs, bucketLabels, "Count", "Time")
 			t.histograms[name] = hist
 		}
 		t.mu.Unlock()
+
+		if overflow {
+			recordLabelCombinationsOverflow(t.name)
+		}
 	}
 	if defaultStatsdHook.timerHook != nil && t.name != "" {
 		defaultStatsdHook.timerHook(t.name, name, elapsed.Milliseconds(), t)
 	}
+	if timingsHook != nil && t.name != "" {
+		timingsHook(ctx, t, name, elapsed)
+	}
 
 	elapsedNs := int64(elapsed)
 	hist.Add(elapsedNs)
@@ -103,10 +130,16 @@ func (t *Timings) Add(name string, elapsed time.Duration) {
 // Record is a convenience function that records completion
 // timing data based on the provided start time of an event.
 func (t *Timings) Record(name string, startTime time.Time) {
+	t.RecordContext(context.Background(), name, startTime)
+}
+
+// RecordContext is like Record, with the context of the event timed (see
+// AddContext).
+func (t *Timings) RecordContext(ctx context.Context, name string, startTime time.Time) {
 	if t.labelCombined {
 		name = StatsAllStr
 	}
-	t.Add(name, time.Since(startTime))
+	t.AddContext(ctx, name, time.Since(startTime))
 }
 
 // String is for expvar.
@@ -181,6 +214,16 @@ func (t *Timings) Label() string {
 	return t.label
 }
 
+// Labels returns the names of the labels whose values make the names of the
+// categories: the label of a Timings, or the labels of a MultiTimings, whose
+// category names join the values of its labels with '.'.
+func (t *Timings) Labels() []string {
+	if t.labels != nil {
+		return t.labels
+	}
+	return []string{t.label}
+}
+
 var bucketCutoffs = []int64{5e5, 1e6, 5e6, 1e7, 5e7, 1e8, 5e8, 1e9, 5e9, 1e10}
 
 var bucketLabels []string
@@ -214,6 +257,7 @@ func NewMultiTimings(name string, help string, labels []string) *MultiTimings {
 			name:       name,
 			help:       help,
 			label:      safeJoinLabels(labels, combinedLabels),
+			labels:     labels,
 		},
 		labels:         labels,
 		combinedLabels: combinedLabels,
@@ -232,19 +276,31 @@ func (mt *MultiTimings) Labels() []string {
 
 // Add will add a new value to the named histogram.
 func (mt *MultiTimings) Add(names []string, elapsed time.Duration) {
+	mt.AddContext(context.Background(), names, elapsed)
+}
+
+// AddContext is like Add, with the context of the event timed (see
+// Timings.AddContext).
+func (mt *MultiTimings) AddContext(ctx context.Context, names []string, elapsed time.Duration) {
 	if len(names) != len(mt.labels) {
 		panic("MultiTimings: wrong number of values in Add")
 	}
-	mt.Timings.Add(safeJoinLabels(names, mt.combinedLabels), elapsed)
+	mt.Timings.AddContext(ctx, safeJoinLabels(names, mt.combinedLabels), elapsed)
 }
 
 // Record is a convenience function that records completion
 // timing data based on the provided start time of an event.
 func (mt *MultiTimings) Record(names []string, startTime time.Time) {
+	mt.RecordContext(context.Background(), names, startTime)
+}
+
+// RecordContext is like Record, with the context of the event timed (see
+// Timings.AddContext).
+func (mt *MultiTimings) RecordContext(ctx context.Context, names []string, startTime time.Time) {
 	if len(names) != len(mt.labels) {
 		panic("MultiTimings: wrong number of values in Record")
 	}
-	mt.Timings.Record(safeJoinLabels(names, mt.combinedLabels), startTime)
+	mt.Timings.RecordContext(ctx, safeJoinLabels(names, mt.combinedLabels), startTime)
 }
 
 // Cutoffs returns the cutoffs used in the component histograms.
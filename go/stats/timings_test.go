@@ -98,4 +98,32 @@ func TestTimingsCombineDimension(t *testing.T) {
 	t3.Add([]string{"c1", "c2", "c3"}, 1)
 	want = `{"TotalCount":1,"TotalTime":1,"Histograms":{"all.c2.all":{"500000":1,"1000000":0,"5000000":0,"10000000":0,"50000000":0,"100000000":0,"500000000":0,"1000000000":0,"5000000000":0,"10000000000":0,"inf":0,"Count":1,"Time":1}}}`
 	assert.Equal(t, want, t3.String())
+
+	// The labels are named after the dimensions, combined or not.
+	assert.Equal(t, []string{"a"}, t2.Labels())
+	assert.Equal(t, []string{"a", "b", "c"}, t3.Labels())
+	assert.Equal(t, []string{"a", "b", "c"}, t3.Timings.Labels())
+}
+
+func TestTimingsLabelCombinationsLimit(t *testing.T) {
+	clearStats()
+	maxLabelCombinations = 1
+	labelCombinationsOverflow().ResetAll()
+
+	tm := NewTimings("timings_limit", "help", "category")
+	tm.Add("t1", 1*time.Second)
+	tm.Add("t2", 1*time.Second)
+	tm.Add("t3", 1*time.Second)
+	tm.Add("t1", 1*time.Second)
+	histograms := tm.Histograms()
+	assert.Len(t, histograms, 2)
+	assert.EqualValues(t, 2, histograms["t1"].Count())
+	assert.EqualValues(t, 2, histograms["other"].Count())
+
+	mtm := NewMultiTimings("multitimings_limit", "help", []string{"a", "b"})
+	mtm.Add([]string{"a1", "b1"}, 1*time.Second)
+	mtm.Add([]string{"a2", "b2"}, 1*time.Second)
+	assert.Contains(t, mtm.Histograms(), "other.other")
+
+	assert.Equal(t, map[string]int64{"timings_limit": 2, "multitimings_limit": 1}, labelCombinationsOverflow().Counts())
 }
@@ -19,6 +19,8 @@ package vreplication
 var dryRunResultsSwitchWritesCustomerShard = []string{
 	"Lock keyspace product",
 	"Lock keyspace customer",
+	"Lock routing of keyspace product",
+	"Lock routing of keyspace customer",
 	"/Stop writes on keyspace product for tables [Lead,Lead-1,blüb_tbl,customer,db_order_test,geom_tbl,json_tbl,loadtest,reftable,vdiff_order]: [keyspace:product;shard:0;position:",
 	"Wait for vreplication on stopped streams to catchup for up to 30s",
 	"Create reverse vreplication workflow p2c_reverse",
@@ -29,22 +31,25 @@ var dryRunResultsSwitchWritesCustomerShard = []string{
 	"Switch writes completed, freeze and delete vreplication streams on: [tablet:200,tablet:300]",
 	"Start reverse vreplication streams on: [tablet:100]",
 	"Mark vreplication streams frozen on: [keyspace:customer;shard:-80;tablet:200;workflow:p2c;dbname:vt_customer,keyspace:customer;shard:80-;tablet:300;workflow:p2c;dbname:vt_customer]",
+	"Unlock routing of keyspace customer",
+	"Unlock routing of keyspace product",
 	"Unlock keyspace customer",
 	"Unlock keyspace product",
 	"", // Additional empty newline in the output
 }
 
 var dryRunResultsReadCustomerShard = []string{
-	"Lock keyspace product",
+	"Lock routing of keyspace product",
 	"Switch reads for tables [Lead,Lead-1,blüb_tbl,customer,db_order_test,geom_tbl,json_tbl,loadtest,reftable,vdiff_order] to keyspace customer for tablet types [RDONLY,REPLICA]",
 	"Routing rules for tables [Lead,Lead-1,blüb_tbl,customer,db_order_test,geom_tbl,json_tbl,loadtest,reftable,vdiff_order] will be updated",
 	"Serving VSchema will be rebuilt for the customer keyspace",
-	"Unlock keyspace product",
+	"Unlock routing of keyspace product",
 	"", // Additional empty newline in the output
 }
 
 var dryRunResultsSwitchWritesM2m3 = []string{
 	"Lock keyspace merchant-type",
+	"Lock routing of keyspace merchant-type",
 	"Stop streams on keyspace merchant-type",
 	"/      Id 2 Keyspace customer Shard -80 Rules rules:{match:\"morders\" filter:\"select oid, cid, mname, pid, price, qty, total from orders where in_keyrange(mname, 'merchant-type.md5', '-80')\"} at Position ",
 	"/      Id 2 Keyspace customer Shard -80 Rules rules:{match:\"morders\" filter:\"select oid, cid, mname, pid, price, qty, total from orders where in_keyrange(mname, 'merchant-type.md5', '80-')\"} at Position ",
@@ -80,11 +85,12 @@ var dryRunResultsSwitchWritesM2m3 = []string{
 	"       Keyspace merchant-type, Shard -40, Tablet 1600, Workflow m2m3, DbName vt_merchant-type",
 	"       Keyspace merchant-type, Shard 40-c0, Tablet 1700, Workflow m2m3, DbName vt_merchant-type",
 	"       Keyspace merchant-type, Shard c0-, Tablet 1800, Workflow m2m3, DbName vt_merchant-type",
+	"Unlock routing of keyspace merchant-type",
 	"Unlock keyspace merchant-type",
 }
 
 var dryRunResultsSwitchReadM2m3 = []string{
-	"Lock keyspace merchant-type",
+	"Lock routing of keyspace merchant-type",
 	"Switch reads from keyspace merchant-type to keyspace merchant-type for shards -80,80- to shards -40,40-c0,c0-",
-	"Unlock keyspace merchant-type",
+	"Unlock routing of keyspace merchant-type",
 }
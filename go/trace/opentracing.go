@@ -45,11 +45,18 @@ func (js openTracingSpan) Annotate(key string, value any) {
 }
 
 var _ tracingService = (*openTracingService)(nil)
+var _ traceIDService = (*openTracingService)(nil)
 
 type tracer interface {
 	GetOpenTracingTracer() opentracing.Tracer
 }
 
+// traceIDTracer is implemented by the tracers exposing the IDs of the traces
+// of their span contexts.
+type traceIDTracer interface {
+	TraceID(sc opentracing.SpanContext) (string, bool)
+}
+
 type openTracingService struct {
 	Tracer tracer
 }
@@ -124,6 +131,19 @@ func (jf openTracingService) FromContext(ctx context.Context) (Span, bool) {
 	return openTracingSpan{otSpan: innerSpan}, true
 }
 
+// TraceID is part of the traceIDService interface
+func (jf openTracingService) TraceID(s Span) (string, bool) {
+	span, ok := s.(openTracingSpan)
+	if !ok {
+		return "", false
+	}
+	t, ok := jf.Tracer.(traceIDTracer)
+	if !ok {
+		return "", false
+	}
+	return t.TraceID(span.otSpan.Context())
+}
+
 // NewContext is part of an interface implementation
 func (jf openTracingService) NewContext(parent context.Context, s Span) context.Context {
 	span, ok := s.(openTracingSpan)
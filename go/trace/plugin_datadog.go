@@ -4,9 +4,11 @@ import (
 	"fmt"
 	"io"
 	"net"
+	"strconv"
 
 	"github.com/opentracing/opentracing-go"
 	"github.com/spf13/pflag"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace"
 	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/opentracer"
 	ddtracer "gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
 
@@ -26,6 +28,15 @@ var (
 		dataDogConfigKey("agent.port"),
 		viperutil.Options[string]{
 			FlagName: "datadog-agent-port",
+			Validate: func(port string) error {
+				if port == "" {
+					return nil
+				}
+				if n, err := strconv.Atoi(port); err != nil || n <= 0 || n > 65535 {
+					return fmt.Errorf("invalid datadog agent port %q", port)
+				}
+				return nil
+			},
 		},
 	)
 )
@@ -87,3 +98,12 @@ type datadogTracer struct {
 func (dt *datadogTracer) GetOpenTracingTracer() opentracing.Tracer {
 	return dt.actual
 }
+
+// TraceID is part of the traceIDTracer interface.
+func (dt *datadogTracer) TraceID(sc opentracing.SpanContext) (string, bool) {
+	dsc, ok := sc.(ddtrace.SpanContext)
+	if !ok || dsc.TraceID() == 0 {
+		return "", false
+	}
+	return strconv.FormatUint(dsc.TraceID(), 10), true
+}
@@ -17,10 +17,13 @@ limitations under the License.
 package trace
 
 import (
+	"fmt"
 	"io"
+	"strings"
 
 	"github.com/opentracing/opentracing-go"
 	"github.com/spf13/pflag"
+	"github.com/uber/jaeger-client-go"
 	"github.com/uber/jaeger-client-go/config"
 
 	"vitess.io/vitess/go/viperutil"
@@ -47,6 +50,15 @@ var (
 			Default:  "const",
 			EnvVars:  []string{"JAEGER_SAMPLER_TYPE"},
 			FlagName: "tracing-sampling-type",
+			Validate: func(samplingType string) error {
+				// Like jaeger, which ignores the case of the type, and
+				// defaults to a remote sampler.
+				switch strings.ToLower(samplingType) {
+				case "", jaeger.SamplerTypeConst, jaeger.SamplerTypeProbabilistic, jaeger.SamplerTypeRateLimiting, jaeger.SamplerTypeRemote:
+					return nil
+				}
+				return fmt.Errorf("unknown jaeger sampling type %q", samplingType)
+			},
 		},
 	)
 	samplingRate = viperutil.Configure(
@@ -55,6 +67,12 @@ var (
 			Default:  0.1,
 			EnvVars:  []string{"JAEGER_SAMPLER_PARAM"},
 			FlagName: "tracing-sampling-rate",
+			Validate: func(rate float64) error {
+				if rate < 0 {
+					return fmt.Errorf("jaeger sampling rate must not be negative, not %v", rate)
+				}
+				return nil
+			},
 		},
 	)
 )
@@ -139,3 +157,13 @@ type jaegerTracer struct {
 func (jt *jaegerTracer) GetOpenTracingTracer() opentracing.Tracer {
 	return jt.actual
 }
+
+// TraceID is part of the traceIDTracer interface. Only the IDs of the sampled
+// traces are returned, since the others are not reported.
+func (jt *jaegerTracer) TraceID(sc opentracing.SpanContext) (string, bool) {
+	jsc, ok := sc.(jaeger.SpanContext)
+	if !ok || !jsc.IsValid() || !jsc.IsSampled() {
+		return "", false
+	}
+	return jsc.TraceID().String(), true
+}
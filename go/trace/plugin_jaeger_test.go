@@ -17,9 +17,12 @@ limitations under the License.
 package trace
 
 import (
+	"context"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/uber/jaeger-client-go"
 )
 
 func TestNewJaegerTracerFromEnv(t *testing.T) {
@@ -33,3 +36,25 @@ func TestNewJaegerTracerFromEnv(t *testing.T) {
 	require.Empty(t, tracingSvc)
 	require.Empty(t, closer)
 }
+
+func TestJaegerTraceID(t *testing.T) {
+	defer func(tracer tracingService) { currentTracer = tracer }(currentTracer)
+
+	_, ok := TraceID(context.Background())
+	assert.False(t, ok)
+
+	for _, sampled := range []bool{true, false} {
+		tracer, closer := jaeger.NewTracer("test", jaeger.NewConstSampler(sampled), jaeger.NewNullReporter())
+		defer closer.Close()
+		currentTracer = openTracingService{Tracer: &jaegerTracer{actual: tracer}}
+
+		span, ctx := NewSpan(context.Background(), "label")
+		traceID, ok := TraceID(ctx)
+		assert.Equal(t, sampled, ok)
+		if sampled {
+			otSpan := span.(openTracingSpan).otSpan
+			assert.Equal(t, otSpan.Context().(jaeger.SpanContext).TraceID().String(), traceID)
+		}
+		span.Finish()
+	}
+}
@@ -77,6 +77,20 @@ func FromContext(ctx context.Context) (Span, bool) {
 	return currentTracer.FromContext(ctx)
 }
 
+// TraceID returns the ID of the trace of the Span in ctx, if any, and if the
+// installed tracing plugin exposes it, e.g. to link a metric sample to the
+// trace as an exemplar.
+func TraceID(ctx context.Context) (string, bool) {
+	span, ok := currentTracer.FromContext(ctx)
+	if !ok {
+		return "", false
+	}
+	if t, ok := currentTracer.(traceIDService); ok {
+		return t.TraceID(span)
+	}
+	return "", false
+}
+
 // NewContext returns a context based on parent with a new Span value.
 func NewContext(parent context.Context, span Span) context.Context {
 	return currentTracer.NewContext(parent, span)
@@ -122,6 +136,14 @@ type tracingService interface {
 	AddGrpcClientOptions(addInterceptors func(s grpc.StreamClientInterceptor, u grpc.UnaryClientInterceptor))
 }
 
+// traceIDService is implemented by the tracing services exposing the IDs of
+// the traces of their spans.
+type traceIDService interface {
+	// TraceID returns the ID of the trace of the span, if it has one which
+	// is worth linking to, e.g. the trace is sampled.
+	TraceID(span Span) (string, bool)
+}
+
 // TracerFactory creates a tracing service for the service provided. It's important to close the provided io.Closer
 // object to make sure that all spans are sent to the backend before the process exits.
 type TracerFactory func(serviceName string) (tracingService, io.Closer, error)
@@ -143,6 +165,12 @@ var (
 		viperutil.Options[string]{
 			Default:  "noop",
 			FlagName: "tracer",
+			Validate: func(tracer string) error {
+				if _, ok := tracingBackendFactories[tracer]; !ok {
+					return fmt.Errorf("unknown tracer %q", tracer)
+				}
+				return nil
+			},
 		},
 	)
 	enableLogging = viperutil.Configure(
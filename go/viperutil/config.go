@@ -135,6 +135,9 @@ func RegisterFlags(fs *pflag.FlagSet) {
 // back to disk, with writes occuring no more frequently than the
 // --config-persistence-min-interval flag.
 //
+// The references to secrets in the values are resolved as the config is loaded
+// (see RegisterSecretProvider), and LoadConfig fails if one fails to resolve.
+//
 // A cancel function is returned to stop the re-persistence background thread,
 // if one was started.
 //
@@ -183,6 +186,11 @@ func LoadConfig() (context.CancelFunc, error) {
 		return nil, err
 	}
 
+	if err := registry.ResolveStatic(); err != nil {
+		return nil, err
+	}
+
+	registry.ValidateStatic()
 	return registry.Dynamic.Watch(context.Background(), registry.Static, configPersistenceMinInterval.Get())
 }
 
@@ -297,3 +305,39 @@ func (h *ConfigFileNotFoundHandling) String() string {
 }
 
 func (h *ConfigFileNotFoundHandling) Type() string { return "ConfigFileNotFoundHandling" }
+
+// ConfigGeneration is a version of the config of the dynamic values: each load
+// of the config file (or remote config) that changes it, and each rollback,
+// makes a new generation.
+type ConfigGeneration struct {
+	// Number increases with each generation.
+	Number int64 `json:"number"`
+	// Hash is the SHA-256 of the settings of the generation.
+	Hash string `json:"hash"`
+	// Time is when the generation was loaded.
+	Time time.Time `json:"time"`
+}
+
+// ConfigGenerations returns the last generations of the config of the dynamic
+// values, oldest first. The settings of the last few generations are kept in
+// memory, to roll back to with RollbackConfig.
+func ConfigGenerations() []ConfigGeneration {
+	generations := registry.Dynamic.Generations()
+	result := make([]ConfigGeneration, 0, len(generations))
+	for _, g := range generations {
+		result = append(result, ConfigGeneration{
+			Number: g.Number,
+			Hash:   g.Hash,
+			Time:   g.Time,
+		})
+	}
+	return result
+}
+
+// RollbackConfig rolls the dynamic values back to the config of the given
+// generation, which becomes a new generation. Like in-memory changes made with
+// Set, the rolled back config is persisted back to the watched config file,
+// if any, and lasts until the config next changes.
+func RollbackConfig(generation int64) error {
+	return registry.Dynamic.Rollback(generation)
+}
@@ -17,12 +17,15 @@ limitations under the License.
 package viperutil
 
 import (
+	"fmt"
 	"strings"
 	"testing"
 
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/viperutil/internal/registry"
 )
 
 func TestGetConfigHandlingValue(t *testing.T) {
@@ -47,3 +50,30 @@ duration: 10h
 	assert.Equal(t, IgnoreConfigFileNotFound, getHandlingValueFunc("duration"), "failed to get value on duration key")
 	assert.Equal(t, ExitOnConfigFileNotFound, getHandlingValueFunc("default"), "failed to get value on default key")
 }
+
+var validatedStatic = Configure("validated.static", Options[int]{
+	Default: 5,
+	EnvVars: []string{"VT_VALIDATED_STATIC"},
+	Validate: func(v int) error {
+		if v > 10 {
+			return fmt.Errorf("%d is more than 10", v)
+		}
+		return nil
+	},
+})
+
+func TestValidateStatic(t *testing.T) {
+	t.Setenv("VT_VALIDATED_STATIC", "7")
+	registry.ValidateStatic()
+	assert.Equal(t, 7, validatedStatic.Get())
+
+	t.Setenv("VT_VALIDATED_STATIC", "20")
+	registry.ValidateStatic()
+	assert.Equal(t, 5, validatedStatic.Get(), "invalid values must fall back to the default")
+
+	errs := ValidationErrors()
+	require.Len(t, errs, 1)
+	assert.Equal(t, "validated.static", errs[0].Key)
+	assert.Equal(t, "env VT_VALIDATED_STATIC", errs[0].Source)
+	assert.EqualError(t, errs[0], "invalid value for validated.static (from env VT_VALIDATED_STATIC): 20 is more than 10")
+}
@@ -17,11 +17,156 @@ limitations under the License.
 package debug
 
 import (
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/spf13/afero"
+	"github.com/spf13/viper"
+
 	"vitess.io/vitess/go/viperutil/internal/registry"
 )
 
+// Redacted is the value shown in place of the values of sensitive keys.
+const Redacted = "<redacted>"
+
+// sensitiveKeyParts are the parts of the keys whose values are redacted by
+// AllSettings.
+var sensitiveKeyParts = []string{"password", "passwd", "secret", "token", "credential", "private_key", "privatekey", "api_key", "apikey"}
+
 // Debug provides the Debug functionality normally accessible to a given viper
-// instance, but for a combination of the private static and dynamic registries.
+// instance, but for a combination of the private static and dynamic registries,
+// with the values redacted as by AllSettings.
 func Debug() {
-	registry.Combined().Debug()
+	combinedRedacted().Debug()
+}
+
+// WriteConfigAs writes the settings of AllSettings to the given file, in the
+// given format (see WriteConfigTo), or, if format is empty, in the format
+// given by the extension of the file, like viper.WriteConfigAs.
+func WriteConfigAs(filename string, format string) error {
+	if format == "" {
+		format = strings.TrimPrefix(filepath.Ext(filename), ".")
+	}
+
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	if err := WriteConfigTo(f, format); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// WriteConfigTo writes the settings of AllSettings to w, in the given format,
+// one of viper.SupportedExts (e.g. "yaml", "json" or "toml").
+func WriteConfigTo(w io.Writer, format string) error {
+	if !slices.Contains(viper.SupportedExts, format) {
+		return viper.UnsupportedConfigError(format)
+	}
+
+	// viper only writes configs to files, so render it to one in memory. It
+	// has no extension, so that viper uses the config type as the format.
+	const filename = "config"
+	fs := afero.NewMemMapFs()
+	v := combinedRedacted()
+	v.SetFs(fs)
+	v.SetConfigType(format)
+	if err := v.WriteConfigAs(filename); err != nil {
+		return err
+	}
+
+	data, err := afero.ReadFile(fs, filename)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// AllSettings returns the effective settings of the combined static and
+// dynamic registries, like viper.AllSettings, with the values of the secrets
+// (see viperutil.Options.Secret) and of the keys that look sensitive, such as
+// passwords and tokens, redacted.
+func AllSettings() map[string]any {
+	settings := registry.Combined().AllSettings()
+	redact(settings, "")
+	return settings
+}
+
+// Setting is the effective value of a setting, and where it comes from.
+type Setting struct {
+	Value any `json:"value"`
+	// Source is the layer of the config the value comes from: "default",
+	// "config <file>", "env <variable>", "flag --<name>" or "override".
+	Source string `json:"source"`
+}
+
+// AllSettingsWithSources returns the effective settings of AllSettings, by
+// key, along with where their values come from. Only the settings of the
+// values configured through viperutil are returned.
+func AllSettingsWithSources() map[string]Setting {
+	v := combinedRedacted()
+	sources := registry.Sources()
+	settings := make(map[string]Setting, len(sources))
+	for key, source := range sources {
+		settings[key] = Setting{
+			Value:  v.Get(key),
+			Source: source.String(),
+		}
+	}
+	return settings
+}
+
+// NonDefaultSettings returns the settings of AllSettingsWithSources whose
+// effective value differs from their default value.
+func NonDefaultSettings() map[string]Setting {
+	all := AllSettingsWithSources()
+	settings := make(map[string]Setting)
+	for _, key := range registry.NonDefaultKeys() {
+		if setting, ok := all[key]; ok {
+			settings[key] = setting
+		}
+	}
+	return settings
+}
+
+// combinedRedacted returns a viper holding the settings of AllSettings.
+func combinedRedacted() *viper.Viper {
+	v := viper.New()
+	_ = v.MergeConfigMap(AllSettings())
+	v.SetConfigFile(registry.Static.ConfigFileUsed())
+	return v
+}
+
+// redact redacts the settings in place. prefix is the dot-separated key of the
+// settings, when they are nested.
+func redact(settings map[string]any, prefix string) {
+	for key, value := range settings {
+		fullKey := key
+		if prefix != "" {
+			fullKey = prefix + "." + key
+		}
+		if registry.IsSecret(fullKey) || isSensitive(key) {
+			settings[key] = Redacted
+			continue
+		}
+		if nested, ok := value.(map[string]any); ok {
+			redact(nested, fullKey)
+		}
+	}
+}
+
+func isSensitive(key string) bool {
+	key = strings.ToLower(strings.ReplaceAll(key, "-", "_"))
+	for _, part := range sensitiveKeyParts {
+		if strings.Contains(key, part) {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,103 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package debug
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+
+	"vitess.io/vitess/go/acl"
+	"vitess.io/vitess/go/viperutil/internal/registry"
+)
+
+// EnvVar is an environment variable consulted for the value of a setting.
+type EnvVar struct {
+	// Name is the name of the environment variable.
+	Name string `json:"name"`
+	// Key is the key of the setting the environment variable is bound to.
+	Key string `json:"key"`
+	// Set is whether the environment variable is set in the environment of
+	// the process.
+	Set bool `json:"set"`
+	// Value is the value of the environment variable, if it is set. It is
+	// redacted as by AllSettings.
+	Value string `json:"value,omitempty"`
+	// Effective is whether the setting takes its effective value from the
+	// environment variable, rather than from another environment variable
+	// bound to it, or from a layer of the config with a higher precedence,
+	// such as a flag.
+	Effective bool `json:"effective"`
+}
+
+// EnvVars returns every environment variable consulted by the values
+// configured through viperutil, sorted by name.
+//
+// Only the environment variables given by viperutil.Options.EnvVars are
+// consulted: there is no naming scheme deriving environment variables from the
+// keys of the settings.
+func EnvVars() []EnvVar {
+	var envVars []EnvVar
+	for _, info := range registry.Values() {
+		if len(info.EnvVars) == 0 {
+			continue
+		}
+
+		source := registry.SourceOf(info.Key)
+		for _, name := range info.EnvVars {
+			value, set := os.LookupEnv(name)
+			if set && (registry.IsSecret(info.Key) || isSensitive(info.Key) || isSensitive(name)) {
+				value = Redacted
+			}
+			envVars = append(envVars, EnvVar{
+				Name:      name,
+				Key:       info.Key,
+				Set:       set,
+				Value:     value,
+				Effective: source == registry.Source{Kind: registry.SourceEnv, Name: name},
+			})
+		}
+	}
+
+	sort.SliceStable(envVars, func(i, j int) bool {
+		return envVars[i].Name < envVars[j].Name
+	})
+	return envVars
+}
+
+// EnvHandlerFunc provides an http.HandlerFunc that lists, as JSON, the
+// environment variables consulted by the values configured through viperutil
+// (see EnvVars).
+//
+// Example requests:
+//   - GET /debug/config/env
+func EnvHandlerFunc(w http.ResponseWriter, r *http.Request) {
+	if err := acl.CheckAccessHTTP(r, acl.DEBUGGING); err != nil {
+		acl.SendError(w, err)
+		return
+	}
+
+	data, err := json.MarshalIndent(EnvVars(), "", "  ")
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to render config environment variables: %v", err), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
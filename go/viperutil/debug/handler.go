@@ -17,66 +17,106 @@ limitations under the License.
 package debug
 
 import (
+	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
-	"os"
+	"strconv"
 	"strings"
 
 	"github.com/spf13/viper"
 
 	"vitess.io/vitess/go/acl"
 	"vitess.io/vitess/go/slice"
-	"vitess.io/vitess/go/viperutil/internal/registry"
+	"vitess.io/vitess/go/viperutil"
 )
 
 // HandlerFunc provides an http.HandlerFunc that renders the combined config
-// registry (both static and dynamic) for debugging purposes.
+// registry (both static and dynamic) for debugging purposes. The values of
+// sensitive keys are redacted, see AllSettings.
 //
 // By default, this writes the config in viper's "debug" format (what you get
 // if you call viper.Debug()). If the query parameter "format" is present, and
 // matches one of viper's supported config extensions (case-insensitively), the
 // combined config will be written to the response in that format.
 //
+// If the query parameter "sources" is present, the settings are written as
+// JSON instead, along with the layer of the config each value comes from (see
+// AllSettingsWithSources).
+//
 // Example requests:
 //   - GET /debug/config
 //   - GET /debug/config?format=json
 //   - GET /debug/config?format=yaml
+//   - GET /debug/config?sources
 func HandlerFunc(w http.ResponseWriter, r *http.Request) {
 	if err := acl.CheckAccessHTTP(r, acl.DEBUGGING); err != nil {
 		acl.SendError(w, err)
 		return
 	}
 
-	v := registry.Combined()
+	if r.URL.Query().Has("sources") {
+		data, err := json.MarshalIndent(AllSettingsWithSources(), "", "  ")
+		if err != nil {
+			http.Error(w, fmt.Sprintf("failed to render config sources: %v", err), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(data)
+		return
+	}
+
 	format := strings.ToLower(r.URL.Query().Get("format"))
 	switch {
 	case format == "":
-		v.DebugTo(w)
+		combinedRedacted().DebugTo(w)
 	case slice.Any(viper.SupportedExts, func(ext string) bool { return ext == format }):
-		// Got a supported format; write the config to a tempfile in that format,
-		// then copy it to the response.
-		//
-		// (Sadly, viper does not yet have a WriteConfigTo(w io.Writer), so we have
-		// to do this little hacky workaround).
-		v.SetConfigType(format)
-		tmp, err := os.CreateTemp("", "viper_debug")
-		if err != nil {
-			http.Error(w, fmt.Sprintf("failed to render config to tempfile: %v", err), http.StatusInternalServerError)
+		if err := WriteConfigTo(w, format); err != nil {
+			http.Error(w, fmt.Sprintf("failed to render config: %v", err), http.StatusInternalServerError)
 			return
 		}
-		defer os.Remove(tmp.Name())
+	default:
+		http.Error(w, "unsupported config format", http.StatusBadRequest)
+	}
+}
 
-		if err := v.WriteConfigAs(tmp.Name()); err != nil {
-			http.Error(w, fmt.Sprintf("failed to render config to tempfile: %v", err), http.StatusInternalServerError)
+// GenerationsHandlerFunc provides an http.HandlerFunc that lists, as JSON, the
+// generations of the config of the dynamic values (see
+// viperutil.ConfigGenerations), oldest first.
+//
+// A POST request with a "rollback" parameter rolls the dynamic values back to
+// the given generation (see viperutil.RollbackConfig), and requires the ADMIN
+// role.
+//
+// Example requests:
+//   - GET /debug/config/generations
+//   - POST /debug/config/generations?rollback=3
+func GenerationsHandlerFunc(w http.ResponseWriter, r *http.Request) {
+	role := acl.DEBUGGING
+	if r.Method == http.MethodPost {
+		role = acl.ADMIN
+	}
+	if err := acl.CheckAccessHTTP(r, role); err != nil {
+		acl.SendError(w, err)
+		return
+	}
+
+	if r.Method == http.MethodPost {
+		generation, err := strconv.ParseInt(r.FormValue("rollback"), 10, 64)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid generation to roll back to: %v", err), http.StatusBadRequest)
 			return
 		}
-
-		if _, err := io.Copy(w, tmp); err != nil {
-			http.Error(w, fmt.Sprintf("failed to write rendered config: %v", err), http.StatusInternalServerError)
+		if err := viperutil.RollbackConfig(generation); err != nil {
+			http.Error(w, fmt.Sprintf("failed to roll back the config: %v", err), http.StatusNotFound)
 			return
 		}
-	default:
-		http.Error(w, "unsupported config format", http.StatusBadRequest)
 	}
+
+	data, err := json.MarshalIndent(viperutil.ConfigGenerations(), "", "  ")
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to render config generations: %v", err), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
 }
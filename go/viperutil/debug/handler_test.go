@@ -0,0 +1,250 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package debug
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/viperutil"
+	"vitess.io/vitess/go/viperutil/internal/registry"
+)
+
+func TestHandlerFuncRedactsSecrets(t *testing.T) {
+	registry.Static.Set("db-app-password", "hunter2")
+	registry.Static.Set("grpc_auth_static_client_creds", "creds.json")
+	registry.Static.Set("backup.s3.secret_access_key", "shh")
+	registry.Static.Set("tablet-refresh-interval", "1m")
+	t.Cleanup(func() {
+		for _, key := range []string{"db-app-password", "grpc_auth_static_client_creds", "backup.s3.secret_access_key", "tablet-refresh-interval"} {
+			registry.Static.Set(key, nil)
+		}
+	})
+
+	w := httptest.NewRecorder()
+	HandlerFunc(w, httptest.NewRequest(http.MethodGet, "/debug/config?format=json", nil))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var settings map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &settings))
+	assert.Equal(t, Redacted, settings["db-app-password"])
+	assert.Equal(t, "creds.json", settings["grpc_auth_static_client_creds"])
+	assert.Equal(t, map[string]any{"s3": map[string]any{"secret_access_key": Redacted}}, settings["backup"])
+	assert.Equal(t, "1m", settings["tablet-refresh-interval"])
+
+	w = httptest.NewRecorder()
+	HandlerFunc(w, httptest.NewRequest(http.MethodGet, "/debug/config", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "hunter2")
+	assert.NotContains(t, w.Body.String(), "shh")
+}
+
+func TestHandlerFuncSources(t *testing.T) {
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	fs.String("provenance-flag", "", "")
+	fromDefault := viperutil.Configure("provenance-default", viperutil.Options[string]{Default: "d"})
+	fromFlag := viperutil.Configure("provenance-flag", viperutil.Options[string]{FlagName: "provenance-flag"})
+	fromEnv := viperutil.Configure("provenance-env", viperutil.Options[string]{EnvVars: []string{"VT_PROVENANCE_ENV"}})
+	fromSet := viperutil.Configure("provenance-set", viperutil.Options[string]{})
+	viperutil.BindFlags(fs, fromDefault, fromFlag, fromEnv, fromSet)
+
+	require.NoError(t, fs.Parse([]string{"--provenance-flag=f"}))
+	t.Setenv("VT_PROVENANCE_ENV", "e")
+	fromSet.Set("s")
+
+	w := httptest.NewRecorder()
+	HandlerFunc(w, httptest.NewRequest(http.MethodGet, "/debug/config?sources", nil))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var settings map[string]Setting
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &settings))
+	assert.Equal(t, Setting{Value: "d", Source: "default"}, settings["provenance-default"])
+	assert.Equal(t, Setting{Value: "f", Source: "flag --provenance-flag"}, settings["provenance-flag"])
+	assert.Equal(t, Setting{Value: "e", Source: "env VT_PROVENANCE_ENV"}, settings["provenance-env"])
+	assert.Equal(t, Setting{Value: "s", Source: "override"}, settings["provenance-set"])
+}
+
+func TestNonDefaultSettings(t *testing.T) {
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	fs.Int("non-default-flag", 0, "")
+	fs.Int("default-flag", 0, "")
+	changed := viperutil.Configure("non-default-flag", viperutil.Options[int]{FlagName: "non-default-flag", Default: 1})
+	unchanged := viperutil.Configure("default-flag", viperutil.Options[int]{FlagName: "default-flag", Default: 2})
+	setToDefault := viperutil.Configure("non-default-set-to-default", viperutil.Options[string]{Default: "x"})
+	viperutil.BindFlags(fs, changed, unchanged, setToDefault)
+
+	require.NoError(t, fs.Parse([]string{"--non-default-flag=3"}))
+	setToDefault.Set("x")
+
+	settings := NonDefaultSettings()
+	assert.Equal(t, Setting{Value: 3, Source: "flag --non-default-flag"}, settings["non-default-flag"])
+	assert.NotContains(t, settings, "default-flag")
+	assert.NotContains(t, settings, "non-default-set-to-default")
+}
+
+func TestSecretsAreRedacted(t *testing.T) {
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	fs.String("dsn", "", "")
+	secret := viperutil.Configure("upstream.dsn", viperutil.Options[string]{FlagName: "dsn", Secret: true})
+	viperutil.BindFlags(fs, secret)
+	require.NoError(t, fs.Parse([]string{"--dsn=user:hunter2@tcp(db:3306)/"}))
+
+	assert.Equal(t, map[string]any{"dsn": Redacted}, AllSettings()["upstream"])
+	assert.Equal(t, Redacted, AllSettingsWithSources()["upstream.dsn"].Value)
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, WriteConfigAs(path, ""))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "hunter2")
+
+	w := httptest.NewRecorder()
+	HandlerFunc(w, httptest.NewRequest(http.MethodGet, "/debug/config", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "hunter2")
+}
+
+func TestGenerationsHandlerFunc(t *testing.T) {
+	w := httptest.NewRecorder()
+	GenerationsHandlerFunc(w, httptest.NewRequest(http.MethodGet, "/debug/config/generations", nil))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var generations []viperutil.ConfigGeneration
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &generations))
+
+	w = httptest.NewRecorder()
+	GenerationsHandlerFunc(w, httptest.NewRequest(http.MethodPost, "/debug/config/generations?rollback=x", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	GenerationsHandlerFunc(w, httptest.NewRequest(http.MethodPost, "/debug/config/generations?rollback=1000", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "no generation 1000")
+}
+
+func TestWriteConfig(t *testing.T) {
+	registry.Static.Set("write-config.value", "x")
+	t.Cleanup(func() { registry.Static.Set("write-config.value", nil) })
+
+	var buf strings.Builder
+	require.NoError(t, WriteConfigTo(&buf, "toml"))
+	assert.Contains(t, buf.String(), "[write-config]")
+
+	buf.Reset()
+	require.NoError(t, WriteConfigTo(&buf, "json"))
+	var settings map[string]any
+	require.NoError(t, json.Unmarshal([]byte(buf.String()), &settings))
+	assert.Equal(t, map[string]any{"value": "x"}, settings["write-config"])
+
+	assert.Error(t, WriteConfigTo(&buf, "xml"))
+
+	// The explicit format wins over the extension.
+	path := filepath.Join(t.TempDir(), "config.txt")
+	require.NoError(t, WriteConfigAs(path, "yaml"))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "write-config:\n    value: x\n")
+
+	assert.Error(t, WriteConfigAs(path, ""), "txt is not a supported format")
+}
+
+func TestSchema(t *testing.T) {
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	fs.Duration("schema-interval", 0, "how often to do the thing")
+	interval := viperutil.Configure("schema.interval", viperutil.Options[time.Duration]{
+		FlagName: "schema-interval",
+		Default:  time.Minute,
+		Dynamic:  true,
+	})
+	names := viperutil.Configure("schema.names", viperutil.Options[[]string]{
+		Default:     []string{"a"},
+		EnvVars:     []string{"VT_SCHEMA_NAMES"},
+		Description: "the names",
+	})
+	secret := viperutil.Configure("schema.secret", viperutil.Options[string]{Secret: true, Default: "s"})
+	viperutil.BindFlags(fs, interval, names, secret)
+
+	w := httptest.NewRecorder()
+	SchemaHandlerFunc(w, httptest.NewRequest(http.MethodGet, "/debug/config/schema", nil))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var schema map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &schema))
+	assert.Equal(t, SchemaDraft, schema["$schema"])
+	assert.Equal(t, "object", schema["type"])
+
+	properties := schema["properties"].(map[string]any)["schema"].(map[string]any)["properties"].(map[string]any)
+	assert.Equal(t, map[string]any{
+		"type":             "string",
+		"format":           "duration",
+		"default":          "1m0s",
+		"description":      "how often to do the thing",
+		"x-vitess-dynamic": true,
+		"x-vitess-flag":    "--schema-interval",
+	}, properties["interval"])
+	assert.Equal(t, map[string]any{
+		"type":         "array",
+		"items":        map[string]any{"type": "string"},
+		"default":      []any{"a"},
+		"description":  "the names",
+		"x-vitess-env": []any{"VT_SCHEMA_NAMES"},
+	}, properties["names"])
+	assert.Equal(t, map[string]any{
+		"type":      "string",
+		"writeOnly": true,
+	}, properties["secret"])
+}
+
+func TestEnvVars(t *testing.T) {
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	fs.String("env-report-flag", "", "")
+	fromEnv := viperutil.Configure("env.report.value", viperutil.Options[string]{EnvVars: []string{"VT_ENV_REPORT_PRIMARY", "VT_ENV_REPORT_FALLBACK"}})
+	fromFlag := viperutil.Configure("env.report.flag", viperutil.Options[string]{FlagName: "env-report-flag", EnvVars: []string{"VT_ENV_REPORT_FLAG"}})
+	secret := viperutil.Configure("env.report.secret", viperutil.Options[string]{EnvVars: []string{"VT_ENV_REPORT_SECRET"}, Secret: true})
+	viperutil.BindFlags(fs, fromEnv, fromFlag, secret)
+
+	require.NoError(t, fs.Parse([]string{"--env-report-flag=f"}))
+	t.Setenv("VT_ENV_REPORT_FALLBACK", "e")
+	t.Setenv("VT_ENV_REPORT_FLAG", "ignored")
+	t.Setenv("VT_ENV_REPORT_SECRET", "hunter2")
+
+	w := httptest.NewRecorder()
+	EnvHandlerFunc(w, httptest.NewRequest(http.MethodGet, "/debug/config/env", nil))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var envVars []EnvVar
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &envVars))
+	envVars = slices.DeleteFunc(envVars, func(envVar EnvVar) bool {
+		return !strings.HasPrefix(envVar.Name, "VT_ENV_REPORT_")
+	})
+	assert.Equal(t, []EnvVar{
+		{Name: "VT_ENV_REPORT_FALLBACK", Key: "env.report.value", Set: true, Value: "e", Effective: true},
+		{Name: "VT_ENV_REPORT_FLAG", Key: "env.report.flag", Set: true, Value: "ignored"},
+		{Name: "VT_ENV_REPORT_PRIMARY", Key: "env.report.value"},
+		{Name: "VT_ENV_REPORT_SECRET", Key: "env.report.secret", Set: true, Value: Redacted, Effective: true},
+	}, envVars)
+}
@@ -0,0 +1,202 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package debug
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	"vitess.io/vitess/go/acl"
+	"vitess.io/vitess/go/viperutil/internal/registry"
+)
+
+// SchemaDraft is the JSON Schema dialect of the schemas returned by Schema.
+const SchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema returns a JSON Schema of the config files of the process, describing
+// the values configured through viperutil: their type, default value and
+// description. Keys are nested by their dot-separated parts, as in config
+// files.
+//
+// Besides the standard keywords, the schema of each value has the vitess
+// specific "x-vitess-dynamic" keyword, set for dynamic values, and the
+// "x-vitess-flag" and "x-vitess-env" keywords, giving the flag and the
+// environment variables the value is bound to, if any. Secrets are marked
+// "writeOnly", and their default value is left out.
+func Schema() map[string]any {
+	root := newObjectSchema()
+	root["$schema"] = SchemaDraft
+	root["title"] = "Vitess config"
+
+	for _, info := range registry.Values() {
+		parent := root
+		parts := strings.Split(info.Key, ".")
+		for _, part := range parts[:len(parts)-1] {
+			properties := parent["properties"].(map[string]any)
+			child, ok := properties[part].(map[string]any)
+			if _, isParent := child["properties"]; !ok || !isParent {
+				child = newObjectSchema()
+				properties[part] = child
+			}
+			parent = child
+		}
+		parent["properties"].(map[string]any)[parts[len(parts)-1]] = valueSchema(info)
+	}
+	return root
+}
+
+func newObjectSchema() map[string]any {
+	return map[string]any{
+		"type":       "object",
+		"properties": map[string]any{},
+	}
+}
+
+func valueSchema(info registry.ValueInfo) map[string]any {
+	schema := typeSchema(info.Type)
+
+	description := info.Description
+	if description == "" && info.Flag != nil {
+		description = info.Flag.Usage
+	}
+	if description != "" {
+		schema["description"] = description
+	}
+
+	if registry.IsSecret(info.Key) {
+		schema["writeOnly"] = true
+	} else if def, ok := defaultValue(info.Default); ok {
+		schema["default"] = def
+	}
+
+	if info.Dynamic {
+		schema["x-vitess-dynamic"] = true
+	}
+	if info.Flag != nil {
+		schema["x-vitess-flag"] = "--" + info.Flag.Name
+	}
+	if len(info.EnvVars) > 0 {
+		schema["x-vitess-env"] = info.EnvVars
+	}
+	return schema
+}
+
+var (
+	durationType = reflect.TypeOf(time.Duration(0))
+	stringerType = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+)
+
+// isStringer returns whether the values of typ, or pointers to them, are
+// fmt.Stringers.
+func isStringer(typ reflect.Type) bool {
+	return typ.Implements(stringerType) || reflect.PointerTo(typ).Implements(stringerType)
+}
+
+// typeSchema returns the schema of the values of the given type. Types that
+// viper can't decode from a config file without a custom GetFunc, and types
+// whose string form differs from their kind (such as enums), are left
+// unconstrained.
+func typeSchema(typ reflect.Type) map[string]any {
+	schema := map[string]any{}
+	if typ == nil {
+		return schema
+	}
+	if typ == durationType {
+		// viper parses durations from strings such as "1m30s".
+		schema["type"] = "string"
+		schema["format"] = "duration"
+		return schema
+	}
+	if typ.PkgPath() != "" && isStringer(typ) {
+		return schema
+	}
+
+	switch typ.Kind() {
+	case reflect.Bool:
+		schema["type"] = "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		schema["type"] = "integer"
+	case reflect.Float32, reflect.Float64:
+		schema["type"] = "number"
+	case reflect.String:
+		schema["type"] = "string"
+	case reflect.Slice, reflect.Array:
+		schema["type"] = "array"
+		if items := typeSchema(typ.Elem()); len(items) > 0 {
+			schema["items"] = items
+		}
+	case reflect.Map:
+		schema["type"] = "object"
+		if values := typeSchema(typ.Elem()); len(values) > 0 {
+			schema["additionalProperties"] = values
+		}
+	}
+	return schema
+}
+
+// defaultValue returns the default value as it would be written in a config
+// file, if it can be.
+func defaultValue(def any) (any, bool) {
+	if def == nil {
+		return nil, false
+	}
+	v := reflect.ValueOf(def)
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map, reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return nil, false
+		}
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		return nil, false
+	}
+
+	if isStringer(v.Type()) {
+		// e.g. durations, written as "1m30s".
+		ptr := reflect.New(v.Type())
+		ptr.Elem().Set(v)
+		return ptr.Interface().(fmt.Stringer).String(), true
+	}
+	if _, err := json.Marshal(def); err != nil {
+		return nil, false
+	}
+	return def, true
+}
+
+// SchemaHandlerFunc provides an http.HandlerFunc that writes the JSON Schema of
+// the config files of the process (see Schema).
+//
+// Example requests:
+//   - GET /debug/config/schema
+func SchemaHandlerFunc(w http.ResponseWriter, r *http.Request) {
+	if err := acl.CheckAccessHTTP(r, acl.DEBUGGING); err != nil {
+		acl.SendError(w, err)
+		return
+	}
+
+	data, err := json.MarshalIndent(Schema(), "", "  ")
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to render config schema: %v", err), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/schema+json")
+	w.Write(data)
+}
@@ -17,7 +17,11 @@ limitations under the License.
 package viperutil
 
 import (
+	"fmt"
+
+	"vitess.io/vitess/go/viperutil/internal/registry"
 	"vitess.io/vitess/go/viperutil/internal/sync"
+	"vitess.io/vitess/go/viperutil/internal/validation"
 	"vitess.io/vitess/go/viperutil/internal/value"
 )
 
@@ -26,8 +30,48 @@ var (
 	// single synced viper. Viper only supports reading/watching a single
 	// config file.
 	ErrDuplicateWatch = sync.ErrDuplicateWatch
+	// ErrRemoteConfigUnavailable is returned by WatchRemoteConfig when the
+	// remote config document can't be read yet. The watch keeps trying to
+	// read it, and can be canceled with the cancel function returned along.
+	ErrRemoteConfigUnavailable = sync.ErrRemoteConfigUnavailable
 	// ErrNoFlagDefined is returned from Value's Flag method when the value was
 	// configured to bind to a given FlagName but the provided flag set does not
 	// define a flag with that name.
 	ErrNoFlagDefined = value.ErrNoFlagDefined
 )
+
+// ValidationError is a value of a setting rejected by the validation of its
+// Value (see Options.Validate).
+type ValidationError struct {
+	Key string
+	// Source is where the rejected value comes from: "default",
+	// "config <file>", "env <variable>", "flag --<name>" or "override".
+	Source string
+	Err    error
+}
+
+// Error is part of the error interface.
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid value for %s (from %s): %v", e.Key, e.Source, e.Err)
+}
+
+// Unwrap returns the error of the validation.
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// ValidationErrors returns the settings whose current value from the config
+// failed their validation, and were kept at their previous value (or at their
+// default, for static values), sorted by key.
+func ValidationErrors() []*ValidationError {
+	rejections := validation.Rejected()
+	errs := make([]*ValidationError, 0, len(rejections))
+	for _, rejection := range rejections {
+		errs = append(errs, &ValidationError{
+			Key:    rejection.Key,
+			Source: registry.SourceOf(rejection.Key).String(),
+			Err:    rejection.Err,
+		})
+	}
+	return errs
+}
@@ -57,3 +57,37 @@ func Combined() *viper.Viper {
 	v.SetConfigFile(Static.ConfigFileUsed())
 	return v
 }
+
+var staticValidators []func()
+
+// AddStaticValidator registers a function validating a static value, to be
+// called by ValidateStatic.
+func AddStaticValidator(validate func()) {
+	staticValidators = append(staticValidators, validate)
+}
+
+// ValidateStatic validates the static values, once their config is loaded.
+func ValidateStatic() {
+	for _, validate := range staticValidators {
+		validate()
+	}
+}
+
+var staticResolvers []func() error
+
+// AddStaticResolver registers a function resolving the references to secrets
+// in a static value, to be called by ResolveStatic.
+func AddStaticResolver(resolve func() error) {
+	staticResolvers = append(staticResolvers, resolve)
+}
+
+// ResolveStatic resolves the references to secrets in the static values, once
+// their config is loaded. It returns the first error.
+func ResolveStatic() error {
+	for _, resolve := range staticResolvers {
+		if err := resolve(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
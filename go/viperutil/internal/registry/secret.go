@@ -0,0 +1,46 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"strings"
+	"sync"
+)
+
+var (
+	secretsMu sync.Mutex
+	secrets   = map[string]bool{}
+)
+
+// TrackSecret records that the settings of the given keys, i.e. the key of a
+// value and its aliases, hold a secret, whose value must never be shown or
+// written out.
+func TrackSecret(keys ...string) {
+	secretsMu.Lock()
+	defer secretsMu.Unlock()
+	for _, key := range keys {
+		secrets[strings.ToLower(key)] = true
+	}
+}
+
+// IsSecret returns whether the setting of key was registered as a secret.
+// Keys are case-insensitive, like viper keys.
+func IsSecret(key string) bool {
+	secretsMu.Lock()
+	defer secretsMu.Unlock()
+	return secrets[strings.ToLower(key)]
+}
@@ -0,0 +1,220 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"os"
+	"reflect"
+	"sort"
+	"sync"
+
+	"github.com/spf13/pflag"
+)
+
+// SourceKind is a layer of the config a setting can take its value from.
+type SourceKind string
+
+const (
+	// SourceDefault is the default value of the setting.
+	SourceDefault SourceKind = "default"
+	// SourceConfigFile is the config file, or the remote config document
+	// used in its place by the dynamic settings.
+	SourceConfigFile SourceKind = "config"
+	// SourceEnv is an environment variable.
+	SourceEnv SourceKind = "env"
+	// SourceFlag is a command-line flag.
+	SourceFlag SourceKind = "flag"
+	// SourceOverride is an explicit Set of the value by the process, e.g.
+	// through a debug endpoint.
+	SourceOverride SourceKind = "override"
+)
+
+// Source is where the effective value of a setting comes from.
+type Source struct {
+	Kind SourceKind
+	// Name is the flag, environment variable or config file the value comes
+	// from, if any.
+	Name string
+}
+
+// String is part of the fmt.Stringer interface.
+func (s Source) String() string {
+	if s.Name == "" {
+		return string(s.Kind)
+	}
+	return string(s.Kind) + " " + s.Name
+}
+
+// binding is how a setting is bound to the layers of the config.
+type binding struct {
+	dynamic bool
+	envVars []string
+	flag    *pflag.Flag
+	// isDefault returns whether the effective value of the setting is its
+	// default value.
+	isDefault func() bool
+
+	typ         reflect.Type
+	defaultVal  any
+	description string
+}
+
+var (
+	bindingsMu sync.Mutex
+	bindings   = map[string]*binding{}
+	// staticOverrides are the static settings explicitly Set. The dynamic
+	// ones are tracked by the Dynamic registry, as a config reload resets
+	// them.
+	staticOverrides = map[string]bool{}
+)
+
+// TrackBinding records that the setting of key is bound to the Static or
+// Dynamic registry, and to the given environment variables. isDefault tells
+// whether the effective value of the setting is its default value.
+func TrackBinding(key string, dynamic bool, envVars []string, isDefault func() bool) {
+	bindingsMu.Lock()
+	defer bindingsMu.Unlock()
+	bindings[key] = &binding{dynamic: dynamic, envVars: envVars, isDefault: isDefault}
+}
+
+// TrackFlag records that the setting of key is bound to the given flag.
+func TrackFlag(key string, flag *pflag.Flag) {
+	bindingsMu.Lock()
+	defer bindingsMu.Unlock()
+	if b, ok := bindings[key]; ok {
+		b.flag = flag
+	}
+}
+
+// TrackSchema records the type, default value and description of the setting
+// of key.
+func TrackSchema(key string, typ reflect.Type, defaultVal any, description string) {
+	bindingsMu.Lock()
+	defer bindingsMu.Unlock()
+	if b, ok := bindings[key]; ok {
+		b.typ = typ
+		b.defaultVal = defaultVal
+		b.description = description
+	}
+}
+
+// TrackOverride records that the static setting of key was explicitly Set.
+func TrackOverride(key string) {
+	bindingsMu.Lock()
+	defer bindingsMu.Unlock()
+	staticOverrides[key] = true
+}
+
+// Sources returns where the effective value of each tracked setting comes
+// from, following the precedence of viper: overrides, then flags set on the
+// command line, then environment variables, then the config file, then the
+// defaults.
+func Sources() map[string]Source {
+	bindingsMu.Lock()
+	defer bindingsMu.Unlock()
+
+	sources := make(map[string]Source, len(bindings))
+	for key, b := range bindings {
+		sources[key] = source(key, b)
+	}
+	return sources
+}
+
+// SourceOf returns where the effective value of the setting of key comes
+// from, as in Sources. Untracked settings come from their default.
+func SourceOf(key string) Source {
+	bindingsMu.Lock()
+	defer bindingsMu.Unlock()
+
+	b, ok := bindings[key]
+	if !ok {
+		return Source{Kind: SourceDefault}
+	}
+	return source(key, b)
+}
+
+// ValueInfo describes a tracked setting.
+type ValueInfo struct {
+	Key         string
+	Type        reflect.Type
+	Default     any
+	Description string
+	Dynamic     bool
+	EnvVars     []string
+	// Flag is the flag the setting is bound to, if any.
+	Flag *pflag.Flag
+}
+
+// Values returns the tracked settings, sorted by key.
+func Values() []ValueInfo {
+	bindingsMu.Lock()
+	defer bindingsMu.Unlock()
+
+	values := make([]ValueInfo, 0, len(bindings))
+	for key, b := range bindings {
+		values = append(values, ValueInfo{
+			Key:         key,
+			Type:        b.typ,
+			Default:     b.defaultVal,
+			Description: b.description,
+			Dynamic:     b.dynamic,
+			EnvVars:     b.envVars,
+			Flag:        b.flag,
+		})
+	}
+	sort.Slice(values, func(i, j int) bool {
+		return values[i].Key < values[j].Key
+	})
+	return values
+}
+
+// NonDefaultKeys returns the keys of the tracked settings whose effective
+// value differs from their default value.
+func NonDefaultKeys() []string {
+	bindingsMu.Lock()
+	defer bindingsMu.Unlock()
+
+	var keys []string
+	for key, b := range bindings {
+		if b.isDefault != nil && !b.isDefault() {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+func source(key string, b *binding) Source {
+	if (b.dynamic && Dynamic.IsOverridden(key)) || (!b.dynamic && staticOverrides[key]) {
+		return Source{Kind: SourceOverride}
+	}
+	if b.flag != nil && b.flag.Changed {
+		return Source{Kind: SourceFlag, Name: "--" + b.flag.Name}
+	}
+	for _, envVar := range b.envVars {
+		// viper ignores empty environment variables by default.
+		if value, ok := os.LookupEnv(envVar); ok && value != "" {
+			return Source{Kind: SourceEnv, Name: envVar}
+		}
+	}
+	if b.dynamic && Dynamic.InConfig(key) {
+		return Source{Kind: SourceConfigFile, Name: Dynamic.ConfigUsed()}
+	}
+	if !b.dynamic && Static.InConfig(key) {
+		return Source{Kind: SourceConfigFile, Name: Static.ConfigFileUsed()}
+	}
+	return Source{Kind: SourceDefault}
+}
@@ -0,0 +1,50 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSources(t *testing.T) {
+	config := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(config, []byte("from-config: c\nfrom-env: c\n"), 0o644))
+
+	for _, key := range []string{"from-config", "from-env", "from-default"} {
+		Static.SetDefault(key, "d")
+	}
+	TrackBinding("from-config", false, nil, nil)
+	TrackBinding("from-env", false, []string{"VT_SOURCES_FROM_ENV"}, nil)
+	TrackBinding("from-default", false, []string{"VT_SOURCES_UNSET"}, nil)
+	require.NoError(t, Static.BindEnv("from-env", "VT_SOURCES_FROM_ENV"))
+	t.Setenv("VT_SOURCES_FROM_ENV", "e")
+
+	Static.SetConfigFile(config)
+	require.NoError(t, Static.ReadInConfig())
+
+	sources := Sources()
+	assert.Equal(t, Source{Kind: SourceConfigFile, Name: config}, sources["from-config"])
+	assert.Equal(t, "config "+config, sources["from-config"].String())
+	assert.Equal(t, Source{Kind: SourceEnv, Name: "VT_SOURCES_FROM_ENV"}, sources["from-env"])
+	assert.Equal(t, Source{Kind: SourceDefault}, sources["from-default"])
+	assert.Equal(t, "e", Static.Get("from-env"))
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package secrets resolves the references to secrets found in the values of the
+config, through the secret providers registered for their schemes.
+
+A reference is a string of the form "<scheme>:<ref>", such as
+"vault:secret/path#key" or "enc:kms:<blob>", where scheme is the scheme of a
+registered provider, which may itself contain colons.
+*/
+package secrets
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/viperutil/internal/log"
+)
+
+// ResolveTimeout bounds the time to resolve a reference to a secret.
+const ResolveTimeout = 30 * time.Second
+
+// Provider resolves references to secrets.
+type Provider interface {
+	// ResolveSecret returns the secret ref refers to. ref is the reference
+	// without the scheme of the provider.
+	ResolveSecret(ctx context.Context, ref string) (string, error)
+}
+
+// Failures counts the references to secrets that failed to resolve, by key.
+var Failures = stats.NewCountersWithSingleLabel("ConfigSecretResolutionFailures", "References to secrets in the config that failed to resolve, by key", "Key")
+
+var (
+	providersMu sync.RWMutex
+	providers   = map[string]Provider{}
+)
+
+// Register makes provider resolve the references with the given scheme,
+// replacing any provider previously registered for it.
+func Register(scheme string, provider Provider) {
+	providersMu.Lock()
+	defer providersMu.Unlock()
+	providers[scheme] = provider
+}
+
+// lookup returns the provider of the reference s, and the reference without
+// its scheme. The longest registered scheme s starts with wins.
+func lookup(s string) (provider Provider, ref string, ok bool) {
+	providersMu.RLock()
+	defer providersMu.RUnlock()
+
+	var scheme string
+	for name, p := range providers {
+		if len(name) > len(scheme) && strings.HasPrefix(s, name+":") {
+			scheme, provider = name, p
+		}
+	}
+	if provider == nil {
+		return nil, "", false
+	}
+	return provider, s[len(scheme)+1:], true
+}
+
+// IsReference returns whether s is a reference to a secret, i.e. whether it
+// starts with the scheme of a registered provider.
+func IsReference(s string) bool {
+	_, _, ok := lookup(s)
+	return ok
+}
+
+// Resolve returns the secret s refers to, or s itself if it is not a
+// reference. key is the key of the setting s is the value of, by which
+// failures are logged and counted.
+func Resolve(key string, s string) (string, error) {
+	provider, ref, ok := lookup(s)
+	if !ok {
+		return s, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), ResolveTimeout)
+	defer cancel()
+
+	secret, err := provider.ResolveSecret(ctx, ref)
+	if err != nil {
+		Failures.Add(key, 1)
+		log.ERROR("failed to resolve the secret referenced by %s: %s", key, err.Error())
+		return "", err
+	}
+	return secret, nil
+}
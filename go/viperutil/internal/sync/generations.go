@@ -0,0 +1,164 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sync
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/spf13/viper"
+
+	"vitess.io/vitess/go/vt/proto/vtrpc"
+	"vitess.io/vitess/go/vt/vterrors"
+)
+
+// GenerationHistorySize is the number of generations of the live config kept
+// to roll back to.
+const GenerationHistorySize = 10
+
+// Generation is a version of the live config, as loaded from disk or rolled
+// back to.
+type Generation struct {
+	// Number increases with each generation.
+	Number int64
+	// Hash is the SHA-256 of the settings of the generation.
+	Hash string
+	// Time is when the generation was loaded.
+	Time time.Time
+
+	settings map[string]any
+}
+
+// stamp records the live config as a new generation, unless it is the same as
+// the latest one. It must be called with v.m held.
+func (v *Viper) stamp() {
+	settings := v.live.AllSettings()
+	// Maps are marshaled with sorted keys, so equal settings hash the same.
+	data, err := json.Marshal(settings)
+	if err != nil {
+		data = []byte(err.Error())
+	}
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+
+	var number int64 = 1
+	if n := len(v.generations); n > 0 {
+		latest := v.generations[n-1]
+		if latest.Hash == hash {
+			return
+		}
+		number = latest.Number + 1
+	}
+
+	v.generations = append(v.generations, &Generation{
+		Number:   number,
+		Hash:     hash,
+		Time:     time.Now(),
+		settings: settings,
+	})
+	if n := len(v.generations); n > GenerationHistorySize {
+		v.generations = v.generations[n-GenerationHistorySize:]
+	}
+}
+
+// Generations returns the generations of the live config kept, oldest first.
+func (v *Viper) Generations() []Generation {
+	v.m.Lock()
+	defer v.m.Unlock()
+
+	generations := make([]Generation, 0, len(v.generations))
+	for _, g := range v.generations {
+		generations = append(generations, *g)
+	}
+	return generations
+}
+
+// Rollback replaces the live config with the settings of the given generation,
+// which are stamped as a new generation. Like explicit calls to Set, they are
+// persisted back to disk if a config file is watched, and last until the
+// config next changes on disk. The keys explicitly Set since the config was
+// last loaded from disk keep their values, and stay overridden.
+//
+// The references to secrets in the settings are resolved again first, and
+// nothing is rolled back if one fails to.
+func (v *Viper) Rollback(number int64) error {
+	err := func() error {
+		v.loadMu.Lock()
+		defer v.loadMu.Unlock()
+
+		next, err := v.generation(number)
+		if err != nil {
+			return err
+		}
+
+		commit, err := v.resolve(next)
+		if err != nil {
+			return err
+		}
+
+		for _, m := range v.keys {
+			m.Lock()
+			// This won't fire until after the config has been updated on v.live.
+			defer m.Unlock()
+		}
+
+		v.m.Lock()
+		defer v.m.Unlock()
+
+		commit()
+
+		for key := range v.overrides {
+			next.Set(key, v.live.Get(key))
+		}
+		v.live = next
+		v.stamp()
+		v.notifySubscribers()
+		return nil
+	}()
+	if err != nil {
+		return err
+	}
+
+	v.runReloadHooks()
+
+	// Signal to persist, as Set does.
+	select {
+	case v.setCh <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// generation returns a viper holding the settings of the given generation.
+func (v *Viper) generation(number int64) (*viper.Viper, error) {
+	v.m.Lock()
+	defer v.m.Unlock()
+
+	for _, g := range v.generations {
+		if g.Number != number {
+			continue
+		}
+
+		next := viper.New()
+		next.SetFs(v.fs)
+		_ = next.MergeConfigMap(g.settings)
+		return next, nil
+	}
+	return nil, vterrors.Errorf(vtrpc.Code_NOT_FOUND, "no generation %d of the config to roll back to", number)
+}
@@ -0,0 +1,69 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sync
+
+import (
+	"time"
+
+	"vitess.io/vitess/go/stats"
+)
+
+// Reload results, the labels of Reloads.
+const (
+	ReloadSuccess = "Success"
+	ReloadFailure = "Failure"
+)
+
+var (
+	// ReloadAttempts counts the attempts to reload the config, whether
+	// triggered by a change to it or explicitly.
+	ReloadAttempts = stats.NewCounter("ConfigReloadAttempts", "Attempts to reload the config of the dynamic values")
+	// Reloads counts the reloads of the config, by result.
+	Reloads = stats.NewCountersWithSingleLabel("ConfigReloads", "Reloads of the config of the dynamic values, by result", "Result")
+	// ReloadChanges counts the changes of the values of the settings made by
+	// the reloads of the config, by key.
+	ReloadChanges = stats.NewCountersWithSingleLabel("ConfigReloadChanges", "Dynamic config settings changed by reloads of the config, by key", "Key")
+
+	// LastReloadAttempt is the Unix time of the last attempt to reload the
+	// config, in seconds.
+	LastReloadAttempt = stats.NewGauge("ConfigReloadLastAttemptTimestamp", "Unix time of the last attempt to reload the config of the dynamic values")
+	// LastReloadSuccess is the Unix time of the last successful reload of
+	// the config, in seconds.
+	LastReloadSuccess = stats.NewGauge("ConfigReloadLastSuccessTimestamp", "Unix time of the last successful reload of the config of the dynamic values")
+	// LastReloadFailure is the Unix time of the last failed reload of the
+	// config, in seconds.
+	LastReloadFailure = stats.NewGauge("ConfigReloadLastFailureTimestamp", "Unix time of the last failed reload of the config of the dynamic values")
+)
+
+// recordReload updates the reload metrics with the result of a reload of the
+// config attempted at the given time.
+func recordReload(at time.Time, changed []string, err error) {
+	ReloadAttempts.Add(1)
+	LastReloadAttempt.Set(at.Unix())
+
+	if err != nil {
+		Reloads.Add(ReloadFailure, 1)
+		LastReloadFailure.Set(at.Unix())
+		return
+	}
+
+	Reloads.Add(ReloadSuccess, 1)
+	LastReloadSuccess.Set(at.Unix())
+	for _, key := range changed {
+		ReloadChanges.Add(key, 1)
+	}
+}
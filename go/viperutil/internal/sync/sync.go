@@ -17,8 +17,13 @@ limitations under the License.
 package sync
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -28,6 +33,7 @@ import (
 	"github.com/spf13/viper"
 
 	"vitess.io/vitess/go/viperutil/internal/log"
+	"vitess.io/vitess/go/viperutil/internal/validation"
 	"vitess.io/vitess/go/vt/proto/vtrpc"
 	"vitess.io/vitess/go/vt/vterrors"
 )
@@ -41,14 +47,35 @@ import (
 // settings from. The "live" config only updates after blocking all values from
 // reading in order to swap in the most recently-loaded config from the "disk".
 type Viper struct {
-	m    sync.Mutex // prevents races between loadFromDisk and AllSettings
+	m    sync.Mutex // prevents races between load and AllSettings
 	disk *viper.Viper
 	live *viper.Viper
 	keys map[string]*sync.RWMutex
+	// overrides are the keys explicitly Set since the last load from disk.
+	overrides map[string]bool
+	// remote is the remote provider of the config, if it is read from one
+	// rather than from a file.
+	remote viper.RemoteProvider
+	// generations are the last generations of the live config, oldest
+	// first.
+	generations []*Generation
+	// validators validate the values of keys in the live config, by key.
+	validators map[string]func(v *viper.Viper) error
+	// resolvers resolve the references to secrets in each config about to
+	// be loaded into the live config.
+	resolvers []func(next *viper.Viper) (commit func(), err error)
+	// loadMu serializes the loads of the config into the live config.
+	loadMu sync.Mutex
+	// secrets are the keys whose values are only written back to disk as
+	// they were read from it.
+	secrets map[string]bool
 
 	subscribers    []chan<- struct{}
 	watchingConfig bool
 
+	hooksMu     sync.Mutex
+	reloadHooks []func()
+
 	fs afero.Fs
 
 	setCh chan struct{}
@@ -65,11 +92,14 @@ func (v *Viper) SetFs(fs afero.Fs) {
 // New returns a new synced Viper.
 func New() *Viper {
 	return &Viper{
-		disk:  viper.New(),
-		live:  viper.New(),
-		keys:  map[string]*sync.RWMutex{},
-		fs:    afero.NewOsFs(), // default Fs used by viper, but we need this set so loadFromDisk doesn't accidentally nil-out the live fs
-		setCh: make(chan struct{}, 1),
+		disk:       viper.New(),
+		live:       viper.New(),
+		keys:       map[string]*sync.RWMutex{},
+		overrides:  map[string]bool{},
+		secrets:    map[string]bool{},
+		validators: map[string]func(v *viper.Viper) error{},
+		fs:         afero.NewOsFs(), // default Fs used by viper, but we need this set so load doesn't accidentally nil-out the live fs
+		setCh:      make(chan struct{}, 1),
 	}
 }
 
@@ -89,6 +119,7 @@ func (v *Viper) Set(key string, value any) {
 	// We must not update v.disk here; explicit calls to Set will supercede all
 	// future config reloads.
 	v.live.Set(key, value)
+	v.overrides[key] = true
 
 	// Do a non-blocking signal to persist here. Our channel has a buffer of 1,
 	// so if we've signalled for some other Set call that hasn't been persisted
@@ -104,6 +135,11 @@ func (v *Viper) Set(key string, value any) {
 // has already started a watch.
 var ErrDuplicateWatch = vterrors.New(vtrpc.Code_FAILED_PRECONDITION, "duplicate watch")
 
+// ErrRemoteConfigUnavailable is returned by WatchRemote when the document of
+// the remote config can't be read yet, along with the cancel function of the
+// watch, which keeps trying to read it.
+var ErrRemoteConfigUnavailable = vterrors.New(vtrpc.Code_UNAVAILABLE, "remote config unavailable")
+
 // Watch starts watching the config used by the passed-in Viper. Before starting
 // the watch, the synced viper will perform an initial read and load from disk
 // so that the live config is ready for use without requiring an initial config
@@ -142,7 +178,34 @@ func (v *Viper) Watch(ctx context.Context, static *viper.Viper, minWaitInterval
 	cfg := static.ConfigFileUsed()
 	if cfg == "" {
 		// No config file to watch, just merge the settings and return.
-		return cancel, v.live.MergeConfigMap(static.AllSettings())
+		v.m.Lock()
+		prev := viper.New()
+		for key := range v.validators {
+			prev.Set(key, v.live.Get(key))
+		}
+		err := v.live.MergeConfigMap(static.AllSettings())
+		v.validate(prev, v.live)
+		v.m.Unlock()
+
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+
+		// Nothing reads the values yet, so there is no need to block them.
+		commit, err := v.resolve(v.live)
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		commit()
+
+		v.m.Lock()
+		v.stamp()
+		v.m.Unlock()
+
+		v.runReloadHooks()
+		return cancel, nil
 	}
 
 	v.disk.SetConfigFile(cfg)
@@ -151,29 +214,204 @@ func (v *Viper) Watch(ctx context.Context, static *viper.Viper, minWaitInterval
 		return nil, err
 	}
 
+	if _, err := v.load(); err != nil {
+		cancel()
+		return nil, err
+	}
 	v.watchingConfig = true
-	v.loadFromDisk()
+	v.runReloadHooks()
 	v.disk.OnConfigChange(func(in fsnotify.Event) {
-		for _, m := range v.keys {
-			m.Lock()
-			// This won't fire until after the config has been updated on v.live.
-			defer m.Unlock()
-		}
+		v.reload(func() error { return nil })
+	})
+	v.disk.WatchConfig()
+
+	go v.persistChanges(ctx, minWaitInterval)
+
+	return cancel, nil
+}
+
+// WatchRemote is like Watch, but reads the config from a remote provider,
+// served by viper.RemoteConfig, instead of a file: the settings of the config
+// document at the path of the provider take the place of those of a config
+// file. The format of the document is given by the extension of its path.
+//
+// Unlike with a config file, in-memory changes are not persisted back to the
+// remote provider: they last until the document next changes.
+//
+// If the document can't be read, e.g. because it doesn't exist yet or the
+// remote provider is down, the settings keep their values until the watch
+// reads it, and an ErrRemoteConfigUnavailable is returned along with the
+// cancel function of the watch.
+//
+// If this synced viper is already watching a config, this function returns an
+// ErrDuplicateWatch.
+func (v *Viper) WatchRemote(ctx context.Context, rp viper.RemoteProvider) (cancel context.CancelFunc, err error) {
+	if v.watchingConfig {
+		return nil, vterrors.Wrapf(ErrDuplicateWatch, "%s: viper is already watching %s", ErrDuplicateWatch.Error(), v.ConfigUsed())
+	}
+	if viper.RemoteConfig == nil {
+		return nil, vterrors.Errorf(vtrpc.Code_FAILED_PRECONDITION, "no remote config provider is registered to read %s", remoteConfigName(rp))
+	}
 
-		v.loadFromDisk()
+	configType := strings.TrimPrefix(filepath.Ext(rp.Path()), ".")
+	if configType == "" {
+		return nil, vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "remote config %s has no extension to tell its format", remoteConfigName(rp))
+	}
+	v.disk.SetConfigType(configType)
 
-		for _, ch := range v.subscribers {
+	var readErr error
+	reader, err := viper.RemoteConfig.Get(rp)
+	if err == nil {
+		err = v.disk.ReadConfig(reader)
+	}
+	if err != nil {
+		readErr = fmt.Errorf("%w: cannot read %s: %v", ErrRemoteConfigUnavailable, remoteConfigName(rp), err)
+	}
+
+	if _, err := v.load(); err != nil {
+		return nil, err
+	}
+
+	v.m.Lock()
+	v.remote = rp
+	v.m.Unlock()
+
+	v.watchingConfig = true
+	v.runReloadHooks()
+
+	ctx, cancel = context.WithCancel(ctx)
+	respc, quit := viper.RemoteConfig.WatchChannel(rp)
+	go func() {
+		defer close(quit)
+
+		for {
 			select {
-			case ch <- struct{}{}:
-			default:
+			case <-ctx.Done():
+				return
+			case resp, ok := <-respc:
+				if !ok {
+					return
+				}
+				if resp.Error != nil {
+					log.ERROR("failed to watch remote config %s: %s", remoteConfigName(rp), resp.Error.Error())
+					continue
+				}
+				v.loadFromRemote(rp, resp.Value)
 			}
 		}
+	}()
+
+	return cancel, readErr
+}
+
+// loadFromRemote loads a new version of the document of the remote config.
+func (v *Viper) loadFromRemote(rp viper.RemoteProvider, data []byte) {
+	v.reload(func() error {
+		v.m.Lock()
+		defer v.m.Unlock()
+
+		if err := v.disk.ReadConfig(bytes.NewReader(data)); err != nil {
+			log.ERROR("failed to read remote config %s: %s", remoteConfigName(rp), err.Error())
+			return err
+		}
+		return nil
 	})
-	v.disk.WatchConfig()
+}
 
-	go v.persistChanges(ctx, minWaitInterval)
+// reload calls read to update the disk config, then loads it into the live
+// config (see load), and finally notifies the subscribers and calls the reload
+// hooks. It returns the keys whose live values changed. Nothing is loaded if
+// read fails, or if a reference to a secret in the new config fails to
+// resolve.
+//
+// The result of the reload is recorded in the reload metrics.
+func (v *Viper) reload(read func() error) (changed []string, err error) {
+	defer func(at time.Time) { recordReload(at, changed, err) }(time.Now())
 
-	return cancel, nil
+	changed, err = func() ([]string, error) {
+		v.loadMu.Lock()
+		defer v.loadMu.Unlock()
+
+		if err := read(); err != nil {
+			return nil, err
+		}
+
+		changed, err := v.load()
+		if err != nil {
+			return nil, err
+		}
+		v.notifySubscribers()
+		return changed, nil
+	}()
+	if err != nil {
+		return nil, err
+	}
+
+	// The hooks read the values, so they run once they are unblocked.
+	v.runReloadHooks()
+	return changed, nil
+}
+
+// Reload re-reads the watched config, from its file or its remote provider,
+// and loads it into the live config as a change to it would be. It returns
+// the keys whose live values changed, including the keys explicitly Set since
+// the last load, which the reload resets.
+//
+// If no config is watched, there is nothing to reload, and the reload succeeds
+// without changing anything.
+func (v *Viper) Reload() (changed []string, err error) {
+	if !v.watchingConfig {
+		recordReload(time.Now(), nil, nil)
+		return nil, nil
+	}
+
+	return v.reload(func() error {
+		v.m.Lock()
+		defer v.m.Unlock()
+
+		if v.remote == nil {
+			return v.disk.ReadInConfig()
+		}
+
+		reader, err := viper.RemoteConfig.Get(v.remote)
+		if err != nil {
+			return err
+		}
+		return v.disk.ReadConfig(reader)
+	})
+}
+
+// OnReload adds a hook called after each load of the config into the live
+// config, once the values can be read again. Hooks are called synchronously,
+// so they should not block.
+func (v *Viper) OnReload(hook func()) {
+	v.hooksMu.Lock()
+	defer v.hooksMu.Unlock()
+
+	v.reloadHooks = append(v.reloadHooks, hook)
+}
+
+func (v *Viper) runReloadHooks() {
+	v.hooksMu.Lock()
+	hooks := v.reloadHooks
+	v.hooksMu.Unlock()
+
+	for _, hook := range hooks {
+		hook()
+	}
+}
+
+func (v *Viper) notifySubscribers() {
+	for _, ch := range v.subscribers {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func remoteConfigName(rp viper.RemoteProvider) string {
+	return rp.Provider() + ":" + rp.Path()
 }
 
 func (v *Viper) persistChanges(ctx context.Context, minWaitInterval time.Duration) {
@@ -243,9 +481,70 @@ func (v *Viper) WriteConfig() error {
 	v.m.Lock()
 	defer v.m.Unlock()
 
-	v.live.SetConfigFile(v.disk.ConfigFileUsed())
+	if len(v.secrets) == 0 {
+		v.live.SetConfigFile(v.disk.ConfigFileUsed())
+		return v.live.WriteConfig()
+	}
+
+	// Never write out the values of secrets that were not read from the
+	// config file, e.g. those from flags, environment variables or Set, and
+	// keep the others as they were on disk.
+	settings := v.live.AllSettings()
+	for key := range v.secrets {
+		if v.disk.InConfig(key) {
+			setNested(settings, key, v.disk.Get(key))
+		} else {
+			deleteNested(settings, key)
+		}
+	}
 
-	return v.live.WriteConfig()
+	out := viper.New()
+	out.SetFs(v.fs)
+	_ = out.MergeConfigMap(settings)
+	return out.WriteConfigAs(v.disk.ConfigFileUsed())
+}
+
+// MarkSecret records that the value of key is a secret: it is only written
+// back to disk by WriteConfig if it was read from the config file, and then
+// as it was read.
+//
+// It must be called prior to setting up a Watch; it will panic if a watch has
+// already been established on this synced Viper.
+func (v *Viper) MarkSecret(key string) {
+	if v.watchingConfig {
+		panic("cannot mark a secret after starting to watch a config")
+	}
+
+	v.secrets[strings.ToLower(key)] = true
+}
+
+// setNested sets the value of the dot-separated key in the nested settings
+// maps, as returned by viper's AllSettings.
+func setNested(settings map[string]any, key string, value any) {
+	parts := strings.Split(key, ".")
+	for _, part := range parts[:len(parts)-1] {
+		next, ok := settings[part].(map[string]any)
+		if !ok {
+			next = map[string]any{}
+			settings[part] = next
+		}
+		settings = next
+	}
+	settings[parts[len(parts)-1]] = value
+}
+
+// deleteNested deletes the dot-separated key from the nested settings maps,
+// as returned by viper's AllSettings.
+func deleteNested(settings map[string]any, key string) {
+	parts := strings.Split(key, ".")
+	for _, part := range parts[:len(parts)-1] {
+		next, ok := settings[part].(map[string]any)
+		if !ok {
+			return
+		}
+		settings = next
+	}
+	delete(settings, parts[len(parts)-1])
 }
 
 // Notify adds a subscription that this synced viper will attempt to notify on
@@ -273,19 +572,172 @@ func (v *Viper) AllSettings() map[string]any {
 	return v.live.AllSettings()
 }
 
-func (v *Viper) loadFromDisk() {
+// load loads the disk config into the live config, and returns the keys whose
+// live values changed, sorted.
+//
+// The references to secrets in the new config are resolved first, without
+// blocking the values from reading, and the config is only swapped in, while
+// blocking all values from reading, if they all resolved. Otherwise, nothing is
+// loaded and the error is returned.
+func (v *Viper) load() (changed []string, err error) {
+	next := v.nextFromDisk()
+
+	commit, err := v.resolve(next)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, m := range v.keys {
+		m.Lock()
+		// This won't fire until after the config has been updated on v.live.
+		defer m.Unlock()
+	}
+
 	v.m.Lock()
 	defer v.m.Unlock()
 
-	// Reset v.live so explicit Set calls don't win over what's just changed on
-	// disk.
-	v.live = viper.New()
-	v.live.SetFs(v.fs)
+	commit()
+
+	prev := v.live
+	// Replace v.live so explicit Set calls don't win over what's just
+	// changed on disk.
+	v.live = next
+	v.overrides = map[string]bool{}
+	v.stamp()
+
+	return changedKeys(prev, v.live), nil
+}
+
+// nextFromDisk returns the disk config as it is to be loaded into the live
+// config, with the invalid values replaced by their current live values.
+func (v *Viper) nextFromDisk() *viper.Viper {
+	v.m.Lock()
+	defer v.m.Unlock()
+
+	next := viper.New()
+	next.SetFs(v.fs)
 
 	// Fun fact! MergeConfigMap actually only ever returns nil. Maybe in an
 	// older version of viper it used to actually handle errors, but now it
 	// decidedly does not. See https://github.com/spf13/viper/blob/v1.8.1/viper.go#L1492-L1499.
-	_ = v.live.MergeConfigMap(v.disk.AllSettings())
+	_ = next.MergeConfigMap(v.disk.AllSettings())
+
+	v.validate(v.live, next)
+	return next
+}
+
+// changedKeys returns the keys whose values differ between two vipers, sorted.
+func changedKeys(prev, next *viper.Viper) []string {
+	keys := map[string]bool{}
+	for _, key := range prev.AllKeys() {
+		keys[key] = true
+	}
+	for _, key := range next.AllKeys() {
+		keys[key] = true
+	}
+
+	var changed []string
+	for key := range keys {
+		if !reflect.DeepEqual(prev.Get(key), next.Get(key)) {
+			changed = append(changed, key)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// AddValidator registers a function validating the value of key in a viper.
+// Each time the config is loaded into the live config, a key whose new value
+// is invalid keeps the value it had in the previous live config.
+//
+// It must be called prior to setting up a Watch; it will panic if a watch has
+// already been established on this synced Viper.
+func (v *Viper) AddValidator(key string, validate func(v *viper.Viper) error) {
+	if v.watchingConfig {
+		panic("cannot add a validator after starting to watch a config")
+	}
+
+	v.validators[key] = validate
+}
+
+// validate runs the validators against the next live config, restoring the
+// values the invalid keys had in prev. It must be called with v.m held.
+func (v *Viper) validate(prev, next *viper.Viper) {
+	for key, validate := range v.validators {
+		if err := validate(next); err != nil {
+			validation.Reject(key, err)
+			// Only set in the live config, so the rejected value is still
+			// replaced by the next load from disk.
+			next.Set(key, prev.Get(key))
+			continue
+		}
+		validation.Accept(key)
+	}
+}
+
+// AddResolver registers a function resolving the references to secrets in
+// the values of a config about to be loaded into the live config. It is
+// called, without blocking the values from reading, on each load of the
+// config. If it fails, the config is not loaded; otherwise, the returned commit
+// function is called as the config is swapped in, while the values are blocked
+// from reading, and so must not block.
+//
+// It must be called prior to setting up a Watch; it will panic if a watch has
+// already been established on this synced Viper.
+func (v *Viper) AddResolver(resolve func(next *viper.Viper) (commit func(), err error)) {
+	if v.watchingConfig {
+		panic("cannot add a resolver after starting to watch a config")
+	}
+
+	v.resolvers = append(v.resolvers, resolve)
+}
+
+// resolve runs the resolvers against the next live config, returning a
+// function committing all of them, or the first error.
+func (v *Viper) resolve(next *viper.Viper) (commit func(), err error) {
+	commits := make([]func(), 0, len(v.resolvers))
+	for _, resolve := range v.resolvers {
+		commit, err := resolve(next)
+		if err != nil {
+			return nil, err
+		}
+		commits = append(commits, commit)
+	}
+
+	return func() {
+		for _, commit := range commits {
+			commit()
+		}
+	}, nil
+}
+
+// ConfigUsed returns the name of the watched config: its file, or its remote
+// provider and path.
+func (v *Viper) ConfigUsed() string {
+	v.m.Lock()
+	defer v.m.Unlock()
+
+	if v.remote != nil {
+		return remoteConfigName(v.remote)
+	}
+	return v.disk.ConfigFileUsed()
+}
+
+// IsOverridden returns whether the key was explicitly Set since the config was
+// last loaded from disk.
+func (v *Viper) IsOverridden(key string) bool {
+	v.m.Lock()
+	defer v.m.Unlock()
+
+	return v.overrides[key]
+}
+
+// InConfig returns whether the watched config file sets the key.
+func (v *Viper) InConfig(key string) bool {
+	v.m.Lock()
+	defer v.m.Unlock()
+
+	return v.watchingConfig && v.disk.InConfig(key)
 }
 
 // begin implementation of registry.Bindable for sync.Viper
@@ -17,8 +17,11 @@ limitations under the License.
 package sync
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"io"
 	"math/rand/v2"
 	"testing"
 	"time"
@@ -27,6 +30,8 @@ import (
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/viperutil/internal/validation"
 )
 
 func TestPersistConfig(t *testing.T) {
@@ -131,6 +136,374 @@ func TestPersistConfig(t *testing.T) {
 	})
 }
 
+func TestPersistConfigSecrets(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "config.json", []byte(`{"db":{"password":"from-file"}}`), 0644))
+
+	static := viper.New()
+	static.SetFs(fs)
+	static.SetConfigFile("config.json")
+	require.NoError(t, static.ReadInConfig())
+
+	v := New()
+	getPassword := AdaptGetter("db.password", func(v *viper.Viper) func(key string) string { return v.GetString }, v)
+	getToken := AdaptGetter("db.token", func(v *viper.Viper) func(key string) string { return v.GetString }, v)
+	AdaptGetter("db.user", func(v *viper.Viper) func(key string) string { return v.GetString }, v)
+	v.MarkSecret("db.password")
+	v.MarkSecret("db.token")
+
+	ch := make(chan struct{}, 1)
+	v.onConfigWrite = func() { ch <- struct{}{} }
+	v.SetFs(fs)
+	cancel, err := v.Watch(context.Background(), static, 0)
+	require.NoError(t, err)
+	t.Cleanup(cancel)
+
+	v.Set("db.password", "set-password")
+	v.Set("db.token", "set-token")
+	v.Set("db.user", "app")
+	<-ch
+	assert.Equal(t, "set-password", getPassword("db.password"))
+	assert.Equal(t, "set-token", getToken("db.token"))
+
+	data, err := afero.ReadFile(fs, "config.json")
+	require.NoError(t, err)
+	var cfg map[string]map[string]any
+	require.NoError(t, json.Unmarshal(data, &cfg))
+	assert.Equal(t, map[string]any{"password": "from-file", "user": "app"}, cfg["db"])
+}
+
+type fakeRemoteConfig struct {
+	data  []byte
+	err   error
+	respc chan *viper.RemoteResponse
+}
+
+func (rc *fakeRemoteConfig) Get(rp viper.RemoteProvider) (io.Reader, error) {
+	if rc.err != nil {
+		return nil, rc.err
+	}
+	return bytes.NewReader(rc.data), nil
+}
+
+func (rc *fakeRemoteConfig) Watch(rp viper.RemoteProvider) (io.Reader, error) {
+	return rc.Get(rp)
+}
+
+func (rc *fakeRemoteConfig) WatchChannel(rp viper.RemoteProvider) (<-chan *viper.RemoteResponse, chan bool) {
+	return rc.respc, make(chan bool)
+}
+
+type fakeRemoteProvider struct{}
+
+func (fakeRemoteProvider) Provider() string      { return "fake" }
+func (fakeRemoteProvider) Endpoint() string      { return "" }
+func (fakeRemoteProvider) Path() string          { return "config/test.json" }
+func (fakeRemoteProvider) SecretKeyring() string { return "" }
+
+func TestWatchRemote(t *testing.T) {
+	rc := &fakeRemoteConfig{
+		data:  []byte(`{"foo": 1}`),
+		respc: make(chan *viper.RemoteResponse),
+	}
+	oldRemoteConfig := viper.RemoteConfig
+	viper.RemoteConfig = rc
+	t.Cleanup(func() { viper.RemoteConfig = oldRemoteConfig })
+
+	v := New()
+	get := AdaptGetter("foo", func(v *viper.Viper) func(key string) int { return v.GetInt }, v)
+	ch := make(chan struct{}, 1)
+	v.Notify(ch)
+
+	cancel, err := v.WatchRemote(context.Background(), fakeRemoteProvider{})
+	require.NoError(t, err)
+	t.Cleanup(cancel)
+	assert.Equal(t, 1, get("foo"))
+	assert.True(t, v.InConfig("foo"))
+	assert.Equal(t, "fake:config/test.json", v.ConfigUsed())
+
+	_, err = v.WatchRemote(context.Background(), fakeRemoteProvider{})
+	assert.ErrorContains(t, err, ErrDuplicateWatch.Error())
+
+	v.Set("foo", 5)
+	assert.Equal(t, 5, get("foo"))
+
+	rc.respc <- &viper.RemoteResponse{Error: errors.New("unavailable")}
+	rc.respc <- &viper.RemoteResponse{Value: []byte(`{"foo": 2}`)}
+	<-ch
+	assert.Equal(t, 2, get("foo"))
+	assert.False(t, v.IsOverridden("foo"))
+}
+
+func TestReload(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "config.json", []byte(`{"foo": 1, "bar": "a", "baz": true}`), 0644))
+
+	static := viper.New()
+	static.SetFs(fs)
+	static.SetConfigFile("config.json")
+	require.NoError(t, static.ReadInConfig())
+
+	v := New()
+	getFoo := AdaptGetter("foo", func(v *viper.Viper) func(key string) int { return v.GetInt }, v)
+	getBar := AdaptGetter("bar", func(v *viper.Viper) func(key string) string { return v.GetString }, v)
+	AdaptGetter("baz", func(v *viper.Viper) func(key string) bool { return v.GetBool }, v)
+	ch := make(chan struct{}, 1)
+	v.Notify(ch)
+	v.SetFs(fs)
+
+	changed, err := v.Reload()
+	require.NoError(t, err)
+	assert.Empty(t, changed, "nothing to reload before watching a config")
+
+	cancel, err := v.Watch(context.Background(), static, 0)
+	require.NoError(t, err)
+	t.Cleanup(cancel)
+
+	v.Set("bar", "b")
+	require.NoError(t, afero.WriteFile(fs, "config.json", []byte(`{"foo": 2, "bar": "a"}`), 0644))
+
+	attempts := ReloadAttempts.Get()
+	successes := Reloads.Counts()[ReloadSuccess]
+	failures := Reloads.Counts()[ReloadFailure]
+	fooChanges := ReloadChanges.Counts()["foo"]
+
+	changed, err = v.Reload()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"bar", "baz", "foo"}, changed)
+	assert.Equal(t, attempts+1, ReloadAttempts.Get())
+	assert.Equal(t, successes+1, Reloads.Counts()[ReloadSuccess])
+	assert.Equal(t, fooChanges+1, ReloadChanges.Counts()["foo"])
+	assert.NotZero(t, LastReloadSuccess.Get())
+	assert.Equal(t, 2, getFoo("foo"))
+	assert.Equal(t, "a", getBar("bar"), "reloads reset explicit Sets")
+	<-ch
+
+	changed, err = v.Reload()
+	require.NoError(t, err)
+	assert.Empty(t, changed)
+
+	require.NoError(t, afero.WriteFile(fs, "config.json", []byte(`{"foo": `), 0644))
+	_, err = v.Reload()
+	assert.Error(t, err)
+	assert.Equal(t, 2, getFoo("foo"), "configs failing to read are not loaded")
+	assert.Equal(t, failures+1, Reloads.Counts()[ReloadFailure])
+	assert.NotZero(t, LastReloadFailure.Get())
+}
+
+func TestReloadRemote(t *testing.T) {
+	rc := &fakeRemoteConfig{
+		data:  []byte(`{"foo": 1}`),
+		respc: make(chan *viper.RemoteResponse),
+	}
+	oldRemoteConfig := viper.RemoteConfig
+	viper.RemoteConfig = rc
+	t.Cleanup(func() { viper.RemoteConfig = oldRemoteConfig })
+
+	v := New()
+	get := AdaptGetter("foo", func(v *viper.Viper) func(key string) int { return v.GetInt }, v)
+	cancel, err := v.WatchRemote(context.Background(), fakeRemoteProvider{})
+	require.NoError(t, err)
+	t.Cleanup(cancel)
+
+	rc.data = []byte(`{"foo": 2}`)
+	changed, err := v.Reload()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"foo"}, changed)
+	assert.Equal(t, 2, get("foo"))
+}
+
+func TestValidate(t *testing.T) {
+	v := New()
+	get := AdaptGetter("validated", func(v *viper.Viper) func(key string) int { return v.GetInt }, v)
+	v.AddValidator("validated", func(v *viper.Viper) error {
+		if v.GetInt("validated") < 0 {
+			return errors.New("must not be negative")
+		}
+		return nil
+	})
+	rejections := validation.Rejections.Counts()["validated"]
+
+	v.disk.Set("validated", 1)
+	mustLoad(t, v)
+	assert.Equal(t, 1, get("validated"))
+
+	v.disk.Set("validated", -1)
+	mustLoad(t, v)
+	assert.Equal(t, 1, get("validated"), "invalid values must keep the previous value")
+	assert.Equal(t, rejections+1, validation.Rejections.Counts()["validated"])
+	assert.Contains(t, validation.Rejected(), validation.Rejection{Key: "validated", Err: errors.New("must not be negative")})
+
+	v.disk.Set("validated", 2)
+	mustLoad(t, v)
+	assert.Equal(t, 2, get("validated"))
+	assert.Empty(t, validation.Rejected(), "accepted values clear their rejection")
+}
+
+func TestResolvers(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "config.json", []byte(`{"password": "ref:a"}`), 0644))
+
+	static := viper.New()
+	static.SetFs(fs)
+	static.SetConfigFile("config.json")
+	require.NoError(t, static.ReadInConfig())
+
+	v := New()
+	get := AdaptGetter("password", func(v *viper.Viper) func(key string) string { return v.GetString }, v)
+
+	var (
+		resolved string
+		blocked  bool
+	)
+	secrets := map[string]string{"ref:a": "secret-a", "ref:b": "secret-b"}
+	v.AddResolver(func(next *viper.Viper) (func(), error) {
+		// The values must not be blocked from reading while resolving.
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			get("password")
+		}()
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			blocked = true
+		}
+
+		secret, ok := secrets[next.GetString("password")]
+		if !ok {
+			return nil, errors.New("no such secret")
+		}
+		return func() { resolved = secret }, nil
+	})
+	v.SetFs(fs)
+
+	cancel, err := v.Watch(context.Background(), static, 0)
+	require.NoError(t, err)
+	t.Cleanup(cancel)
+	assert.Equal(t, "secret-a", resolved)
+
+	require.NoError(t, afero.WriteFile(fs, "config.json", []byte(`{"password": "ref:b"}`), 0644))
+	_, err = v.Reload()
+	require.NoError(t, err)
+	assert.Equal(t, "secret-b", resolved)
+	assert.Equal(t, "ref:b", get("password"))
+
+	require.NoError(t, afero.WriteFile(fs, "config.json", []byte(`{"password": "ref:missing"}`), 0644))
+	_, err = v.Reload()
+	assert.ErrorContains(t, err, "no such secret")
+	assert.Equal(t, "secret-b", resolved)
+	assert.Equal(t, "ref:b", get("password"), "configs whose secrets fail to resolve are not loaded")
+
+	assert.False(t, blocked, "resolvers must not block the values from reading")
+}
+
+func TestRollback(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "config.json", []byte(`{"foo": 1, "bar": 1}`), 0644))
+
+	static := viper.New()
+	static.SetFs(fs)
+	static.SetConfigFile("config.json")
+	require.NoError(t, static.ReadInConfig())
+
+	v := New()
+	get := AdaptGetter("foo", func(v *viper.Viper) func(key string) int { return v.GetInt }, v)
+	getBar := AdaptGetter("bar", func(v *viper.Viper) func(key string) int { return v.GetInt }, v)
+	ch := make(chan struct{}, 1)
+	v.onConfigWrite = func() { ch <- struct{}{} }
+	v.SetFs(fs)
+	cancel, err := v.Watch(context.Background(), static, 0)
+	require.NoError(t, err)
+	t.Cleanup(cancel)
+
+	generations := v.Generations()
+	require.Len(t, generations, 1)
+	assert.EqualValues(t, 1, generations[0].Number)
+	first := generations[0].Hash
+
+	// Simulate reloads of the config file.
+	v.disk.Set("foo", 2)
+	mustLoad(t, v)
+	mustLoad(t, v)
+	generations = v.Generations()
+	require.Len(t, generations, 2, "unchanged configs are not new generations")
+	assert.EqualValues(t, 2, generations[1].Number)
+	assert.NotEqual(t, first, generations[1].Hash)
+	assert.Equal(t, 2, get("foo"))
+
+	assert.ErrorContains(t, v.Rollback(5), "no generation 5")
+
+	require.NoError(t, v.Rollback(1))
+	assert.Equal(t, 1, get("foo"))
+	generations = v.Generations()
+	require.Len(t, generations, 3)
+	assert.EqualValues(t, 3, generations[2].Number)
+	assert.Equal(t, first, generations[2].Hash)
+
+	<-ch
+	data, err := afero.ReadFile(fs, "config.json")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"foo": 1, "bar": 1}`, string(data))
+
+	// The keys explicitly Set stay overridden by a rollback.
+	v.Set("bar", 3)
+	<-ch
+	require.NoError(t, v.Rollback(2))
+	assert.Equal(t, 2, get("foo"))
+	assert.Equal(t, 3, getBar("bar"))
+	assert.True(t, v.IsOverridden("bar"))
+	assert.False(t, v.IsOverridden("foo"))
+
+	<-ch
+	data, err = afero.ReadFile(fs, "config.json")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"foo": 2, "bar": 3}`, string(data))
+
+	for i := 0; i < GenerationHistorySize; i++ {
+		v.disk.Set("foo", 10+i)
+		mustLoad(t, v)
+	}
+	generations = v.Generations()
+	require.Len(t, generations, GenerationHistorySize)
+	assert.EqualValues(t, 4+GenerationHistorySize, generations[GenerationHistorySize-1].Number)
+}
+
+// mustLoad simulates a reload of the config file, as loaded into v.disk.
+func mustLoad(t *testing.T, v *Viper) {
+	t.Helper()
+
+	_, err := v.load()
+	require.NoError(t, err)
+}
+
+func TestWatchRemoteUnavailable(t *testing.T) {
+	rc := &fakeRemoteConfig{
+		err:   errors.New("unavailable"),
+		respc: make(chan *viper.RemoteResponse),
+	}
+	oldRemoteConfig := viper.RemoteConfig
+	viper.RemoteConfig = rc
+	t.Cleanup(func() { viper.RemoteConfig = oldRemoteConfig })
+
+	v := New()
+	get := AdaptGetter("foo", func(v *viper.Viper) func(key string) int { return v.GetInt }, v)
+	ch := make(chan struct{}, 1)
+	v.Notify(ch)
+
+	// The document can't be read, but the watch still loads it once it
+	// can.
+	cancel, err := v.WatchRemote(context.Background(), fakeRemoteProvider{})
+	assert.ErrorIs(t, err, ErrRemoteConfigUnavailable)
+	require.NotNil(t, cancel)
+	t.Cleanup(cancel)
+	assert.Zero(t, get("foo"))
+
+	rc.respc <- &viper.RemoteResponse{Value: []byte(`{"foo": 2}`)}
+	<-ch
+	assert.Equal(t, 2, get("foo"))
+}
+
 func jitter(min, max int) int {
 	return min + rand.IntN(max-min+1)
 }
@@ -0,0 +1,80 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package validation reports the config values rejected by the validation
+functions of their Values.
+*/
+package validation
+
+import (
+	"sort"
+	"sync"
+
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/viperutil/internal/log"
+)
+
+// Rejections counts the config values rejected by their validation, by key.
+var Rejections = stats.NewCountersWithSingleLabel("ConfigValidationRejections", "Config values rejected by the validation of their setting, by key", "Key")
+
+// Rejection is the rejection of the value of a key by its validation.
+type Rejection struct {
+	Key string
+	Err error
+}
+
+var (
+	rejectedMu sync.Mutex
+	// rejected are the keys whose last validated value was rejected, with the
+	// error of their validation.
+	rejected = map[string]error{}
+)
+
+// Reject logs and counts the rejection of the new value of key, which keeps
+// its previous value. The values themselves are not logged, as they may be
+// secrets.
+func Reject(key string, err error) {
+	Rejections.Add(key, 1)
+	log.ERROR("rejecting the new value of %s, which keeps its previous value: %s", key, err.Error())
+
+	rejectedMu.Lock()
+	defer rejectedMu.Unlock()
+	rejected[key] = err
+}
+
+// Accept records that the new value of key passed its validation, clearing
+// any previous rejection of it.
+func Accept(key string) {
+	rejectedMu.Lock()
+	defer rejectedMu.Unlock()
+	delete(rejected, key)
+}
+
+// Rejected returns the keys whose last validated value was rejected, sorted.
+func Rejected() []Rejection {
+	rejectedMu.Lock()
+	defer rejectedMu.Unlock()
+
+	rejections := make([]Rejection, 0, len(rejected))
+	for key, err := range rejected {
+		rejections = append(rejections, Rejection{Key: key, Err: err})
+	}
+	sort.Slice(rejections, func(i, j int) bool {
+		return rejections[i].Key < rejections[j].Key
+	})
+	return rejections
+}
@@ -0,0 +1,132 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package value
+
+import (
+	"reflect"
+	"sync"
+
+	"github.com/spf13/viper"
+
+	"vitess.io/vitess/go/viperutil/internal/secrets"
+	"vitess.io/vitess/go/vt/vterrors"
+)
+
+// secretCache holds the secrets the references to secrets (see package
+// secrets) in the values of a string value resolved to, by reference.
+type secretCache[T any] struct {
+	typ reflect.Type
+	// get is the GetFunc of the value, returning the references unresolved.
+	get func(v *viper.Viper) func(key string) T
+
+	mu      sync.RWMutex
+	secrets map[string]T
+}
+
+// resolveSecrets wraps the GetFunc of string values so that their values which
+// are references to secrets read as the secrets they were resolved to, by
+// resolveSecretsIn or resolveSecret.
+//
+// Get never resolves a reference itself: a reference which was not resolved
+// reads as itself.
+func (val *Base[T]) resolveSecrets() {
+	typ := reflect.TypeOf(&val.DefaultVal).Elem()
+	if typ.Kind() != reflect.String {
+		return
+	}
+
+	cache := &secretCache[T]{
+		typ:     typ,
+		get:     val.GetFunc,
+		secrets: map[string]T{},
+	}
+	val.secrets = cache
+
+	val.GetFunc = func(v *viper.Viper) func(key string) T {
+		get := cache.get(v)
+		return func(key string) T {
+			value := get(key)
+
+			cache.mu.RLock()
+			defer cache.mu.RUnlock()
+
+			if secret, ok := cache.secrets[reflect.ValueOf(value).String()]; ok {
+				return secret
+			}
+			return value
+		}
+	}
+}
+
+// resolveSecretsIn resolves the reference to a secret the value has in the
+// config v, if any. The returned commit function makes the value read as the
+// secret, and forgets the secrets of its previous references.
+func (val *Base[T]) resolveSecretsIn(v *viper.Viper) (commit func(), err error) {
+	cache := val.secrets
+	if cache == nil {
+		return func() {}, nil
+	}
+
+	resolved := map[string]T{}
+	ref := reflect.ValueOf(cache.get(v)(val.Key())).String()
+	if secrets.IsReference(ref) {
+		secret, err := cache.resolve(val.Key(), ref)
+		if err != nil {
+			return nil, vterrors.Wrapf(err, "failed to resolve the secret referenced by %s", val.Key())
+		}
+		resolved[ref] = secret
+	}
+
+	return func() {
+		cache.mu.Lock()
+		defer cache.mu.Unlock()
+		cache.secrets = resolved
+	}, nil
+}
+
+// resolveSecret resolves value, about to be Set, if it is a reference to a
+// secret. A reference failing to resolve is logged and counted (see
+// secrets.Resolve), and reads as itself.
+func (val *Base[T]) resolveSecret(value T) {
+	cache := val.secrets
+	if cache == nil {
+		return
+	}
+
+	ref := reflect.ValueOf(value).String()
+	if !secrets.IsReference(ref) {
+		return
+	}
+
+	secret, err := cache.resolve(val.Key(), ref)
+	if err != nil {
+		return
+	}
+
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	cache.secrets[ref] = secret
+}
+
+func (cache *secretCache[T]) resolve(key string, ref string) (T, error) {
+	var secret T
+	s, err := secrets.Resolve(key, ref)
+	if err != nil {
+		return secret, err
+	}
+	return reflect.ValueOf(s).Convert(cache.typ).Interface().(T), nil
+}
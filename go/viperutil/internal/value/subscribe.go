@@ -0,0 +1,68 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package value
+
+import (
+	"reflect"
+	"sync"
+)
+
+// subscriptions are the subscribers to the changes of a value.
+type subscriptions[T any] struct {
+	mu sync.Mutex
+	// last is the value the subscribers last saw.
+	last T
+	fns  []func(old, new T)
+}
+
+// Subscribe registers fn to be called with the old and new value after each
+// change of the value: on Set and, for dynamic values, on config reloads.
+// fn is called synchronously by the goroutine making the change, so it should
+// not block.
+func (val *Base[T]) Subscribe(fn func(old, new T)) {
+	val.subs.mu.Lock()
+	defer val.subs.mu.Unlock()
+
+	if len(val.subs.fns) == 0 {
+		val.subs.last = val.Get()
+	}
+	val.subs.fns = append(val.subs.fns, fn)
+}
+
+// NotifyChange calls the subscribers if the value changed since they last
+// saw it. It is called on Set and after each reload of the dynamic registry,
+// and by registries that stand in for it, such as in package vipertest.
+func (val *Base[T]) NotifyChange() {
+	val.subs.mu.Lock()
+	if len(val.subs.fns) == 0 {
+		val.subs.mu.Unlock()
+		return
+	}
+	current := val.Get()
+	if reflect.DeepEqual(current, val.subs.last) {
+		val.subs.mu.Unlock()
+		return
+	}
+	old := val.subs.last
+	val.subs.last = current
+	fns := val.subs.fns
+	val.subs.mu.Unlock()
+
+	for _, fn := range fns {
+		fn(old, current)
+	}
+}
@@ -18,12 +18,14 @@ package value
 
 import (
 	"fmt"
+	"reflect"
 
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 
 	"vitess.io/vitess/go/viperutil/internal/registry"
 	"vitess.io/vitess/go/viperutil/internal/sync"
+	"vitess.io/vitess/go/viperutil/internal/validation"
 	"vitess.io/vitess/go/vt/proto/vtrpc"
 	"vitess.io/vitess/go/vt/vterrors"
 )
@@ -52,6 +54,14 @@ type Base[T any] struct {
 	Aliases  []string
 	FlagName string
 	EnvVars  []string
+	Secret   bool
+	Validate func(T) error
+
+	Description string
+
+	subs subscriptions[T]
+	// secrets are the secrets the value resolved to, for string values.
+	secrets *secretCache[T]
 }
 
 func (val *Base[T]) Key() string { return val.KeyName }
@@ -82,6 +92,11 @@ func (val *Base[T]) Flag(fs *pflag.FlagSet) (*pflag.Flag, error) {
 	return flag, nil
 }
 
+// isDefault returns whether the current value is the default value.
+func (val *Base[T]) isDefault() bool {
+	return reflect.DeepEqual(val.Get(), val.DefaultVal)
+}
+
 func (val *Base[T]) bind(v registry.Bindable) {
 	v.SetDefault(val.Key(), val.DefaultVal)
 
@@ -109,8 +124,15 @@ func BindFlags(fs *pflag.FlagSet, values ...Registerable) {
 		}
 
 		_ = val.Registry().BindPFlag(val.Key(), flag)
+		registry.TrackFlag(val.Key(), flag)
 		if flag.Name != val.Key() {
 			val.Registry().RegisterAlias(flag.Name, val.Key())
+			if registry.IsSecret(val.Key()) {
+				registry.TrackSecret(flag.Name)
+				if dynamic, ok := val.Registry().(*sync.Viper); ok {
+					dynamic.MarkSecret(flag.Name)
+				}
+			}
 		}
 	}
 }
@@ -125,8 +147,32 @@ type Static[T any] struct {
 // NewStatic returns a static value derived from the given base value, after
 // binding it to the static registry.
 func NewStatic[T any](base *Base[T]) *Static[T] {
+	base.resolveSecrets()
 	base.bind(registry.Static)
+	registry.AddStaticResolver(func() error {
+		commit, err := base.resolveSecretsIn(registry.Static)
+		if err != nil {
+			return err
+		}
+		commit()
+		return nil
+	})
 	base.BoundGetFunc = base.GetFunc(registry.Static)
+	registry.TrackBinding(base.Key(), false, base.EnvVars, base.isDefault)
+	registry.TrackSchema(base.Key(), reflect.TypeOf(&base.DefaultVal).Elem(), base.DefaultVal, base.Description)
+	if base.Secret {
+		registry.TrackSecret(append([]string{base.Key()}, base.Aliases...)...)
+	}
+	if base.Validate != nil {
+		registry.AddStaticValidator(func() {
+			if err := base.Validate(base.Get()); err != nil {
+				validation.Reject(base.Key(), err)
+				registry.Static.Set(base.Key(), base.DefaultVal)
+				return
+			}
+			validation.Accept(base.Key())
+		})
+	}
 
 	return &Static[T]{
 		Base: base,
@@ -138,7 +184,10 @@ func (val *Static[T]) Registry() registry.Bindable {
 }
 
 func (val *Static[T]) Set(v T) {
+	val.resolveSecret(v)
 	registry.Static.Set(val.KeyName, v)
+	registry.TrackOverride(val.KeyName)
+	val.NotifyChange()
 }
 
 // Dynamic is a dynamic value. Dynamic values register to the Dynamic registry,
@@ -153,18 +202,43 @@ type Dynamic[T any] struct {
 // binding it to the dynamic registry and wrapping its GetFunc to be threadsafe
 // with respect to config reloading.
 func NewDynamic[T any](base *Base[T]) *Dynamic[T] {
+	base.resolveSecrets()
+	// Resolve the references to secrets again on each load of the config,
+	// so that rotated secrets are picked up on reload.
+	registry.Dynamic.AddResolver(base.resolveSecretsIn)
 	base.bind(registry.Dynamic)
 	base.BoundGetFunc = sync.AdaptGetter(base.Key(), base.GetFunc, registry.Dynamic)
+	registry.TrackBinding(base.Key(), true, base.EnvVars, base.isDefault)
+	registry.TrackSchema(base.Key(), reflect.TypeOf(&base.DefaultVal).Elem(), base.DefaultVal, base.Description)
+	if base.Secret {
+		keys := append([]string{base.Key()}, base.Aliases...)
+		registry.TrackSecret(keys...)
+		for _, key := range keys {
+			registry.Dynamic.MarkSecret(key)
+		}
+	}
+	if base.Validate != nil {
+		registry.Dynamic.AddValidator(base.Key(), base.ValidateIn)
+	}
+	registry.Dynamic.OnReload(base.NotifyChange)
 
 	return &Dynamic[T]{
 		Base: base,
 	}
 }
 
+// ValidateIn validates the value of the key of base in v, e.g. the config
+// being reloaded, with its Validate function, which must be set.
+func (base *Base[T]) ValidateIn(v *viper.Viper) error {
+	return base.Validate(base.GetFunc(v)(base.Key()))
+}
+
 func (val *Dynamic[T]) Registry() registry.Bindable {
 	return registry.Dynamic
 }
 
 func (val *Dynamic[T]) Set(v T) {
+	val.resolveSecret(v)
 	registry.Dynamic.Set(val.KeyName, v)
+	val.NotifyChange()
 }
@@ -0,0 +1,119 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package viperutil
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/spf13/viper"
+
+	"vitess.io/vitess/go/viperutil/internal/log"
+	"vitess.io/vitess/go/viperutil/internal/registry"
+)
+
+// ConfigReload is the summary of a reload of the config by ReloadConfig.
+type ConfigReload struct {
+	// Changed are the keys of the dynamic settings whose values changed.
+	Changed []string
+	// RestartRequired are the keys of the static settings whose values changed
+	// in the config file. They only take effect once the process restarts.
+	RestartRequired []string
+}
+
+// ReloadConfig re-reads the config of the dynamic values, whether a config
+// file or a remote config (see WatchRemoteConfig), without waiting for a
+// change to it to be noticed. Their validations are run again, and the new
+// values are swapped in atomically, notifying the subscribers of their changes
+// (see OnChange and NotifyConfigReload). The keys changed by explicit calls to
+// Set since the last load of the config are reset.
+//
+// The config file is also read again for the static values, which keep their
+// values: the ones that changed are reported as requiring a restart.
+//
+// The summary of the reload is logged. Like the reloads triggered by changes
+// to the config, it is recorded in the ConfigReload* stats.
+func ReloadConfig() (*ConfigReload, error) {
+	reload, err := reloadConfig()
+	if err != nil {
+		log.ERROR("failed to reload config: %s", err.Error())
+		return nil, err
+	}
+
+	log.INFO("reloaded config: changed [%s]", strings.Join(reload.Changed, ", "))
+	if len(reload.RestartRequired) > 0 {
+		log.WARN("reloaded config: ignoring changes to static settings [%s], which require a restart", strings.Join(reload.RestartRequired, ", "))
+	}
+	return reload, nil
+}
+
+func reloadConfig() (*ConfigReload, error) {
+	changed, err := registry.Dynamic.Reload()
+	if err != nil {
+		return nil, err
+	}
+
+	restartRequired, err := staticChanges()
+	if err != nil {
+		return nil, err
+	}
+
+	return &ConfigReload{
+		Changed:         changed,
+		RestartRequired: restartRequired,
+	}, nil
+}
+
+// staticChanges reads the config file loaded by LoadConfig again, and returns
+// the keys of the static settings taking their values from the config file, or
+// from their defaults, whose values changed in it.
+func staticChanges() ([]string, error) {
+	file := registry.Static.ConfigFileUsed()
+	if file == "" {
+		return nil, nil
+	}
+
+	fresh := viper.New()
+	fresh.SetConfigFile(file)
+	if cfgType := configType.Get(); cfgType != "" {
+		fresh.SetConfigType(cfgType)
+	}
+	if err := fresh.ReadInConfig(); err != nil {
+		return nil, err
+	}
+
+	var changed []string
+	for _, info := range registry.Values() {
+		if info.Dynamic {
+			continue
+		}
+
+		switch registry.SourceOf(info.Key).Kind {
+		case registry.SourceConfigFile:
+			if !fresh.InConfig(info.Key) || !reflect.DeepEqual(fresh.Get(info.Key), registry.Static.Get(info.Key)) {
+				changed = append(changed, info.Key)
+			}
+		case registry.SourceDefault:
+			if fresh.InConfig(info.Key) {
+				changed = append(changed, info.Key)
+			}
+		}
+	}
+	sort.Strings(changed)
+	return changed, nil
+}
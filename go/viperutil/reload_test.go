@@ -0,0 +1,35 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package viperutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/viperutil/internal/sync"
+)
+
+func TestReloadConfig(t *testing.T) {
+	reloads := sync.Reloads.Counts()[sync.ReloadSuccess]
+	reload, err := ReloadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, reloads+1, sync.Reloads.Counts()[sync.ReloadSuccess])
+	assert.Empty(t, reload.Changed, "no config is loaded")
+	assert.Empty(t, reload.RestartRequired, "no config is loaded")
+}
@@ -0,0 +1,84 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package viperutil
+
+import (
+	"context"
+	"io"
+	"slices"
+
+	"github.com/spf13/viper"
+
+	"vitess.io/vitess/go/viperutil/internal/registry"
+)
+
+// RemoteConfigFactory is a viper remote config backend, which reads and
+// watches config documents for viper.RemoteConfig.
+type RemoteConfigFactory interface {
+	// Get returns the document at the path of the remote provider.
+	Get(rp viper.RemoteProvider) (io.Reader, error)
+	// Watch returns the document at the path of the remote provider.
+	Watch(rp viper.RemoteProvider) (io.Reader, error)
+	// WatchChannel sends every new version of the document at the path of
+	// the remote provider on the returned channel, until the returned quit
+	// channel is closed.
+	WatchChannel(rp viper.RemoteProvider) (<-chan *viper.RemoteResponse, chan bool)
+}
+
+// RegisterRemoteConfigProvider makes factory the viper remote config backend,
+// serving the given remote provider name.
+//
+// viper supports a single remote config backend per process, so this replaces
+// any backend previously registered.
+func RegisterRemoteConfigProvider(provider string, factory RemoteConfigFactory) {
+	viper.RemoteConfig = factory
+	if !slices.Contains(viper.SupportedRemoteProviders, provider) {
+		viper.SupportedRemoteProviders = append(viper.SupportedRemoteProviders, provider)
+	}
+}
+
+// WatchRemoteConfig loads the config document at the given path of a remote
+// provider registered with RegisterRemoteConfigProvider, for the dynamic
+// values to use in place of a config file, and watches it for changes. The
+// format of the document is given by the extension of its path.
+//
+// It fails if LoadConfig found a config file, as the dynamic values can only
+// watch one config. If the document can't be read yet, e.g. because the remote
+// provider is down, it returns an ErrRemoteConfigUnavailable along with the
+// cancel function of the watch, which loads the document once it can. Unlike with a config file, in-memory changes to dynamic
+// values are not persisted back to the remote provider.
+//
+// A cancel function is returned to stop the watch.
+func WatchRemoteConfig(provider, endpoint, path string) (context.CancelFunc, error) {
+	return registry.Dynamic.WatchRemote(context.Background(), &remoteProvider{
+		provider: provider,
+		endpoint: endpoint,
+		path:     path,
+	})
+}
+
+// remoteProvider implements viper.RemoteProvider.
+type remoteProvider struct {
+	provider string
+	endpoint string
+	path     string
+}
+
+func (rp *remoteProvider) Provider() string      { return rp.provider }
+func (rp *remoteProvider) Endpoint() string      { return rp.endpoint }
+func (rp *remoteProvider) Path() string          { return rp.path }
+func (rp *remoteProvider) SecretKeyring() string { return "" }
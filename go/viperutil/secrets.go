@@ -0,0 +1,56 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package viperutil
+
+import (
+	"context"
+
+	"vitess.io/vitess/go/viperutil/internal/secrets"
+)
+
+// SecretProvider resolves references to secrets, such as "vault:secret/db#password",
+// found in the values of the config, so that config files, flags and
+// environment variables need not hold the secrets themselves.
+type SecretProvider interface {
+	// ResolveSecret returns the secret ref refers to. ref is the reference
+	// without the scheme the provider is registered for, e.g.
+	// "secret/db#password".
+	ResolveSecret(ctx context.Context, ref string) (string, error)
+}
+
+// RegisterSecretProvider makes provider resolve the references to secrets of
+// the form "<scheme>:<ref>", replacing any provider previously registered for
+// the scheme. The scheme may itself contain colons, as in "enc:kms", in which
+// case the longest registered scheme a reference starts with is used.
+//
+// The values of type string (or of a type whose underlying type is string)
+// which are references resolve to the secrets they refer to. References are
+// resolved as the config is loaded, never by Get:
+//   - by LoadConfig, which fails if a reference fails to resolve.
+//   - on each reload of the config of the dynamic values, so that rotated
+//     secrets are picked up. A reload whose references fail to resolve is
+//     rejected, and the values keep their previous config and secrets.
+//   - by Set. A reference failing to resolve then reads as itself.
+//
+// The failures are logged and counted in the ConfigSecretResolutionFailures
+// stat. A reference which was never resolved, e.g. because the value was read
+// before LoadConfig, reads as itself.
+//
+// Providers must be registered before LoadConfig, usually in an init function.
+func RegisterSecretProvider(scheme string, provider SecretProvider) {
+	secrets.Register(scheme, provider)
+}
@@ -0,0 +1,223 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package awssecrets registers viperutil.SecretProviders resolving the references
+to secrets stored in AWS:
+
+  - "awssm:<secret-id>" or "awssm:<secret-id>#<key>" reference a secret of
+    AWS Secrets Manager, by its name or ARN. With a key, the secret must be a
+    JSON object, and the string value of key in it is used.
+  - "enc:kms:<ciphertext>" is a secret encrypted with AWS KMS, as the base64
+    encoding of its ciphertext blob, e.g. from `aws kms encrypt`.
+
+The AWS clients are configured by the default AWS session: the standard AWS
+environment variables, shared config and credentials files, and instance
+roles.
+*/
+package awssecrets
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/kms"
+	"github.com/aws/aws-sdk-go/service/kms/kmsiface"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/aws/aws-sdk-go/service/secretsmanager/secretsmanageriface"
+
+	"vitess.io/vitess/go/viperutil"
+	"vitess.io/vitess/go/vt/proto/vtrpc"
+	"vitess.io/vitess/go/vt/vterrors"
+)
+
+const (
+	// SecretsManagerScheme is the scheme of the references resolved by the
+	// SecretsManagerProvider.
+	SecretsManagerScheme = "awssm"
+	// KMSScheme is the scheme of the references resolved by the KMSProvider.
+	KMSScheme = "enc:kms"
+)
+
+var (
+	sessionMu sync.Mutex
+	sess      *session.Session
+)
+
+// getSession returns the default AWS session, created on first use.
+func getSession() (*session.Session, error) {
+	sessionMu.Lock()
+	defer sessionMu.Unlock()
+
+	if sess == nil {
+		s, err := session.NewSession()
+		if err != nil {
+			return nil, vterrors.Wrapf(err, "failed to create AWS session")
+		}
+		sess = s
+	}
+	return sess, nil
+}
+
+// SecretsManagerProvider resolves the references to secrets of AWS Secrets
+// Manager.
+type SecretsManagerProvider struct {
+	mu        sync.Mutex
+	client    secretsmanageriface.SecretsManagerAPI
+	newClient func() (secretsmanageriface.SecretsManagerAPI, error)
+}
+
+var _ viperutil.SecretProvider = (*SecretsManagerProvider)(nil)
+
+// NewSecretsManagerProvider returns a SecretsManagerProvider with a client
+// using the default AWS session. The client is created on first use.
+func NewSecretsManagerProvider() *SecretsManagerProvider {
+	return &SecretsManagerProvider{
+		newClient: func() (secretsmanageriface.SecretsManagerAPI, error) {
+			s, err := getSession()
+			if err != nil {
+				return nil, err
+			}
+			return secretsmanager.New(s), nil
+		},
+	}
+}
+
+// ResolveSecret is part of the viperutil.SecretProvider interface. ref is of
+// the form "<secret-id>" or "<secret-id>#<key>".
+func (p *SecretsManagerProvider) ResolveSecret(ctx context.Context, ref string) (string, error) {
+	id, key, hasKey := strings.Cut(ref, "#")
+	if id == "" || (hasKey && key == "") {
+		return "", vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "invalid AWS Secrets Manager secret reference %s: must be of the form <secret-id>[#<key>]", ref)
+	}
+
+	client, err := p.getClient()
+	if err != nil {
+		return "", err
+	}
+
+	out, err := client.GetSecretValueWithContext(ctx, &secretsmanager.GetSecretValueInput{
+		SecretId: aws.String(id),
+	})
+	if err != nil {
+		return "", vterrors.Wrapf(err, "failed to get AWS Secrets Manager secret %s", id)
+	}
+
+	var secret string
+	switch {
+	case out.SecretString != nil:
+		secret = *out.SecretString
+	default:
+		secret = string(out.SecretBinary)
+	}
+	if !hasKey {
+		return secret, nil
+	}
+
+	var values map[string]any
+	if err := json.Unmarshal([]byte(secret), &values); err != nil {
+		return "", vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "AWS Secrets Manager secret %s is not a JSON object, to get key %s from", id, key)
+	}
+	value, ok := values[key].(string)
+	if !ok {
+		return "", vterrors.Errorf(vtrpc.Code_NOT_FOUND, "AWS Secrets Manager secret %s has no string key %s", id, key)
+	}
+	return value, nil
+}
+
+func (p *SecretsManagerProvider) getClient() (secretsmanageriface.SecretsManagerAPI, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.client == nil {
+		client, err := p.newClient()
+		if err != nil {
+			return nil, err
+		}
+		p.client = client
+	}
+	return p.client, nil
+}
+
+// KMSProvider resolves the secrets encrypted with AWS KMS.
+type KMSProvider struct {
+	mu        sync.Mutex
+	client    kmsiface.KMSAPI
+	newClient func() (kmsiface.KMSAPI, error)
+}
+
+var _ viperutil.SecretProvider = (*KMSProvider)(nil)
+
+// NewKMSProvider returns a KMSProvider with a client using the default AWS
+// session. The client is created on first use.
+func NewKMSProvider() *KMSProvider {
+	return &KMSProvider{
+		newClient: func() (kmsiface.KMSAPI, error) {
+			s, err := getSession()
+			if err != nil {
+				return nil, err
+			}
+			return kms.New(s), nil
+		},
+	}
+}
+
+// ResolveSecret is part of the viperutil.SecretProvider interface. ref is the
+// base64 encoding of the ciphertext blob of the secret. The key it was
+// encrypted with is identified by the blob itself.
+func (p *KMSProvider) ResolveSecret(ctx context.Context, ref string) (string, error) {
+	blob, err := base64.StdEncoding.DecodeString(ref)
+	if err != nil {
+		return "", vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "invalid KMS encrypted secret: not base64: %v", err)
+	}
+
+	client, err := p.getClient()
+	if err != nil {
+		return "", err
+	}
+
+	out, err := client.DecryptWithContext(ctx, &kms.DecryptInput{
+		CiphertextBlob: blob,
+	})
+	if err != nil {
+		return "", vterrors.Wrapf(err, "failed to decrypt KMS encrypted secret")
+	}
+	return string(out.Plaintext), nil
+}
+
+func (p *KMSProvider) getClient() (kmsiface.KMSAPI, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.client == nil {
+		client, err := p.newClient()
+		if err != nil {
+			return nil, err
+		}
+		p.client = client
+	}
+	return p.client, nil
+}
+
+func init() {
+	viperutil.RegisterSecretProvider(SecretsManagerScheme, NewSecretsManagerProvider())
+	viperutil.RegisterSecretProvider(KMSScheme, NewKMSProvider())
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package awssecrets
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/kms"
+	"github.com/aws/aws-sdk-go/service/kms/kmsiface"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/aws/aws-sdk-go/service/secretsmanager/secretsmanageriface"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeSecretsManager struct {
+	secretsmanageriface.SecretsManagerAPI
+	secrets map[string]string
+}
+
+func (sm *fakeSecretsManager) GetSecretValueWithContext(ctx aws.Context, in *secretsmanager.GetSecretValueInput, opts ...request.Option) (*secretsmanager.GetSecretValueOutput, error) {
+	secret, ok := sm.secrets[aws.StringValue(in.SecretId)]
+	if !ok {
+		return nil, errors.New("ResourceNotFoundException")
+	}
+	return &secretsmanager.GetSecretValueOutput{SecretString: aws.String(secret)}, nil
+}
+
+func TestSecretsManagerProvider(t *testing.T) {
+	p := &SecretsManagerProvider{
+		newClient: func() (secretsmanageriface.SecretsManagerAPI, error) {
+			return &fakeSecretsManager{secrets: map[string]string{
+				"db":    `{"password": "hunter2", "port": 3306}`,
+				"plain": "shh",
+			}}, nil
+		},
+	}
+	ctx := context.Background()
+
+	secret, err := p.ResolveSecret(ctx, "db#password")
+	require.NoError(t, err)
+	assert.Equal(t, "hunter2", secret)
+
+	secret, err = p.ResolveSecret(ctx, "plain")
+	require.NoError(t, err)
+	assert.Equal(t, "shh", secret)
+
+	_, err = p.ResolveSecret(ctx, "db#port")
+	assert.ErrorContains(t, err, "has no string key port")
+	_, err = p.ResolveSecret(ctx, "plain#password")
+	assert.ErrorContains(t, err, "is not a JSON object")
+	_, err = p.ResolveSecret(ctx, "missing")
+	assert.ErrorContains(t, err, "ResourceNotFoundException")
+	_, err = p.ResolveSecret(ctx, "db#")
+	assert.ErrorContains(t, err, "must be of the form")
+}
+
+type fakeKMS struct {
+	kmsiface.KMSAPI
+}
+
+// DecryptWithContext "decrypts" blobs by reversing them.
+func (fakeKMS) DecryptWithContext(ctx aws.Context, in *kms.DecryptInput, opts ...request.Option) (*kms.DecryptOutput, error) {
+	plaintext := make([]byte, len(in.CiphertextBlob))
+	for i, b := range in.CiphertextBlob {
+		plaintext[len(plaintext)-1-i] = b
+	}
+	return &kms.DecryptOutput{Plaintext: plaintext}, nil
+}
+
+func TestKMSProvider(t *testing.T) {
+	p := &KMSProvider{
+		newClient: func() (kmsiface.KMSAPI, error) {
+			return fakeKMS{}, nil
+		},
+	}
+	ctx := context.Background()
+
+	secret, err := p.ResolveSecret(ctx, base64.StdEncoding.EncodeToString([]byte("2retnuh")))
+	require.NoError(t, err)
+	assert.Equal(t, "hunter2", secret)
+
+	_, err = p.ResolveSecret(ctx, "not base64!")
+	assert.ErrorContains(t, err, "not base64")
+}
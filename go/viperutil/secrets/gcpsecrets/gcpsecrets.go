@@ -0,0 +1,168 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package gcpsecrets registers a viperutil.SecretProvider resolving the
+references to secrets of GCP Secret Manager, of the form
+"gcpsm:projects/<project>/secrets/<secret>[/versions/<version>][#<key>]",
+e.g. "gcpsm:projects/my-project/secrets/vttablet-db#password". Without a
+version, the latest version of the secret is used. With a key, the secret must
+be a JSON object, and the string value of key in it is used.
+
+The secrets are read through the REST API of Secret Manager, authenticated
+with the Application Default Credentials: the GOOGLE_APPLICATION_CREDENTIALS
+environment variable, the gcloud credentials, or the metadata server.
+*/
+package gcpsecrets
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+
+	"golang.org/x/oauth2/google"
+
+	"vitess.io/vitess/go/viperutil"
+	"vitess.io/vitess/go/vt/proto/vtrpc"
+	"vitess.io/vitess/go/vt/vterrors"
+)
+
+const (
+	// Scheme is the scheme of the references resolved by the Provider.
+	Scheme = "gcpsm"
+
+	endpoint = "https://secretmanager.googleapis.com/v1"
+	scope    = "https://www.googleapis.com/auth/cloud-platform"
+)
+
+// nameRE matches the resource names of secrets and of their versions.
+var nameRE = regexp.MustCompile(`^projects/[^/]+/secrets/[^/]+(/versions/[^/]+)?$`)
+
+// Provider resolves the references to secrets of GCP Secret Manager.
+type Provider struct {
+	endpoint string
+
+	mu        sync.Mutex
+	client    *http.Client
+	newClient func() (*http.Client, error)
+}
+
+var _ viperutil.SecretProvider = (*Provider)(nil)
+
+// NewProvider returns a Provider with a client authenticated with the
+// Application Default Credentials. The client is created on first use.
+func NewProvider() *Provider {
+	return &Provider{
+		endpoint: endpoint,
+		newClient: func() (*http.Client, error) {
+			client, err := google.DefaultClient(context.Background(), scope)
+			if err != nil {
+				return nil, vterrors.Wrapf(err, "failed to create GCP client")
+			}
+			return client, nil
+		},
+	}
+}
+
+// accessResponse is the response of the access method of secret versions.
+type accessResponse struct {
+	Payload struct {
+		// Data is the base64 encoding of the secret.
+		Data string `json:"data"`
+	} `json:"payload"`
+}
+
+// ResolveSecret is part of the viperutil.SecretProvider interface. ref is of
+// the form "<name>" or "<name>#<key>", where name is the resource name of a
+// secret or of one of its versions.
+func (p *Provider) ResolveSecret(ctx context.Context, ref string) (string, error) {
+	name, key, hasKey := strings.Cut(ref, "#")
+	if !nameRE.MatchString(name) || (hasKey && key == "") {
+		return "", vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "invalid GCP Secret Manager secret reference %s: must be of the form projects/<project>/secrets/<secret>[/versions/<version>][#<key>]", ref)
+	}
+	if !strings.Contains(name, "/versions/") {
+		name += "/versions/latest"
+	}
+
+	client, err := p.getClient()
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/%s:access", p.endpoint, name), nil)
+	if err != nil {
+		return "", vterrors.Wrapf(err, "failed to get GCP Secret Manager secret %s", name)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", vterrors.Wrapf(err, "failed to get GCP Secret Manager secret %s", name)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", vterrors.Wrapf(err, "failed to get GCP Secret Manager secret %s", name)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", vterrors.Errorf(vtrpc.Code_UNAVAILABLE, "failed to get GCP Secret Manager secret %s: %s: %s", name, resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	var out accessResponse
+	if err := json.Unmarshal(body, &out); err != nil {
+		return "", vterrors.Wrapf(err, "failed to parse GCP Secret Manager secret %s", name)
+	}
+	data, err := base64.StdEncoding.DecodeString(out.Payload.Data)
+	if err != nil {
+		return "", vterrors.Wrapf(err, "failed to decode GCP Secret Manager secret %s", name)
+	}
+	if !hasKey {
+		return string(data), nil
+	}
+
+	var values map[string]any
+	if err := json.Unmarshal(data, &values); err != nil {
+		return "", vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "GCP Secret Manager secret %s is not a JSON object, to get key %s from", name, key)
+	}
+	value, ok := values[key].(string)
+	if !ok {
+		return "", vterrors.Errorf(vtrpc.Code_NOT_FOUND, "GCP Secret Manager secret %s has no string key %s", name, key)
+	}
+	return value, nil
+}
+
+func (p *Provider) getClient() (*http.Client, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.client == nil {
+		client, err := p.newClient()
+		if err != nil {
+			return nil, err
+		}
+		p.client = client
+	}
+	return p.client, nil
+}
+
+func init() {
+	viperutil.RegisterSecretProvider(Scheme, NewProvider())
+}
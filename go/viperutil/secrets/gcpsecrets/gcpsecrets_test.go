@@ -0,0 +1,74 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gcpsecrets
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProvider(t *testing.T) {
+	secrets := map[string]string{
+		"projects/p/secrets/db/versions/latest": `{"password": "hunter2", "port": 3306}`,
+		"projects/p/secrets/plain/versions/3":   "shh",
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name, ok := strings.CutSuffix(strings.TrimPrefix(r.URL.Path, "/v1/"), ":access")
+		secret, found := secrets[name]
+		if !ok || !found {
+			http.Error(w, `{"error": {"status": "NOT_FOUND"}}`, http.StatusNotFound)
+			return
+		}
+		fmt.Fprintf(w, `{"name": %q, "payload": {"data": %q}}`, name, base64.StdEncoding.EncodeToString([]byte(secret)))
+	}))
+	defer server.Close()
+
+	p := &Provider{
+		endpoint: server.URL + "/v1",
+		newClient: func() (*http.Client, error) {
+			return server.Client(), nil
+		},
+	}
+	ctx := context.Background()
+
+	secret, err := p.ResolveSecret(ctx, "projects/p/secrets/db#password")
+	require.NoError(t, err)
+	assert.Equal(t, "hunter2", secret)
+
+	secret, err = p.ResolveSecret(ctx, "projects/p/secrets/plain/versions/3")
+	require.NoError(t, err)
+	assert.Equal(t, "shh", secret)
+
+	_, err = p.ResolveSecret(ctx, "projects/p/secrets/db#port")
+	assert.ErrorContains(t, err, "has no string key port")
+	_, err = p.ResolveSecret(ctx, "projects/p/secrets/plain/versions/3#password")
+	assert.ErrorContains(t, err, "is not a JSON object")
+	_, err = p.ResolveSecret(ctx, "projects/p/secrets/missing")
+	assert.ErrorContains(t, err, "404 Not Found")
+	_, err = p.ResolveSecret(ctx, "db")
+	assert.ErrorContains(t, err, "must be of the form")
+	_, err = p.ResolveSecret(ctx, "projects/p/secrets/db#")
+	assert.ErrorContains(t, err, "must be of the form")
+}
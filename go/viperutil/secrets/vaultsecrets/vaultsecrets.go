@@ -0,0 +1,109 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package vaultsecrets registers a viperutil.SecretProvider resolving the
+references to secrets stored in HashiCorp Vault, of the form
+"vault:<path>#<key>", e.g. "vault:secret/vttablet/db#password".
+
+The Vault client is configured by the standard environment variables read by
+vaultlib.NewConfig: VAULT_ADDR, VAULT_TOKEN (or VAULT_ROLEID, VAULT_SECRETID
+and VAULT_MOUNTPOINT for AppRole authentication), VAULT_CACERT,
+VAULT_SKIP_VERIFY and VAULT_CLIENT_TIMEOUT.
+*/
+package vaultsecrets
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	vaultapi "github.com/aquarapid/vaultlib"
+
+	"vitess.io/vitess/go/viperutil"
+	"vitess.io/vitess/go/vt/proto/vtrpc"
+	"vitess.io/vitess/go/vt/vterrors"
+)
+
+// Scheme is the scheme of the references resolved by the Provider.
+const Scheme = "vault"
+
+// secretGetter is the subset of the vaultlib.Client used by the Provider.
+type secretGetter interface {
+	GetSecret(path string) (vaultapi.Secret, error)
+}
+
+// Provider resolves the references to secrets stored in Vault.
+type Provider struct {
+	mu        sync.Mutex
+	client    secretGetter
+	newClient func() (secretGetter, error)
+}
+
+var _ viperutil.SecretProvider = (*Provider)(nil)
+
+// NewProvider returns a Provider with a Vault client configured by the
+// environment of the process. The client is created on first use.
+func NewProvider() *Provider {
+	return &Provider{
+		newClient: func() (secretGetter, error) {
+			return vaultapi.NewClient(vaultapi.NewConfig())
+		},
+	}
+}
+
+// ResolveSecret is part of the viperutil.SecretProvider interface. ref is of
+// the form "<path>#<key>", where key is the key of the secret in the KV secret
+// at path.
+func (p *Provider) ResolveSecret(ctx context.Context, ref string) (string, error) {
+	path, key, ok := strings.Cut(ref, "#")
+	if !ok || path == "" || key == "" {
+		return "", vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "invalid vault secret reference %s: must be of the form <path>#<key>", ref)
+	}
+
+	client, err := p.getClient()
+	if err != nil {
+		return "", vterrors.Wrapf(err, "failed to create vault client")
+	}
+
+	secret, err := client.GetSecret(path)
+	if err != nil {
+		return "", vterrors.Wrapf(err, "failed to read vault secret %s", path)
+	}
+	value, ok := secret.KV[key]
+	if !ok {
+		return "", vterrors.Errorf(vtrpc.Code_NOT_FOUND, "vault secret %s has no key %s", path, key)
+	}
+	return value, nil
+}
+
+func (p *Provider) getClient() (secretGetter, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.client == nil {
+		client, err := p.newClient()
+		if err != nil {
+			return nil, err
+		}
+		p.client = client
+	}
+	return p.client, nil
+}
+
+func init() {
+	viperutil.RegisterSecretProvider(Scheme, NewProvider())
+}
@@ -0,0 +1,56 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package vaultsecrets
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	vaultapi "github.com/aquarapid/vaultlib"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeVault map[string]map[string]string
+
+func (v fakeVault) GetSecret(path string) (vaultapi.Secret, error) {
+	kv, ok := v[path]
+	if !ok {
+		return vaultapi.Secret{}, errors.New("not found")
+	}
+	return vaultapi.Secret{KV: kv}, nil
+}
+
+func TestResolveSecret(t *testing.T) {
+	p := &Provider{
+		newClient: func() (secretGetter, error) {
+			return fakeVault{"secret/db": {"password": "hunter2"}}, nil
+		},
+	}
+	ctx := context.Background()
+
+	secret, err := p.ResolveSecret(ctx, "secret/db#password")
+	require.NoError(t, err)
+	assert.Equal(t, "hunter2", secret)
+
+	_, err = p.ResolveSecret(ctx, "secret/db#user")
+	assert.ErrorContains(t, err, "has no key user")
+	_, err = p.ResolveSecret(ctx, "secret/other#password")
+	assert.ErrorContains(t, err, "failed to read vault secret secret/other")
+	_, err = p.ResolveSecret(ctx, "secret/db")
+	assert.ErrorContains(t, err, "must be of the form <path>#<key>")
+}
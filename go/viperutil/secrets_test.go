@@ -0,0 +1,96 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package viperutil
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/viperutil/internal/registry"
+)
+
+type fakeSecretProvider struct {
+	secrets  map[string]string
+	resolved []string
+}
+
+func (p *fakeSecretProvider) ResolveSecret(ctx context.Context, ref string) (string, error) {
+	p.resolved = append(p.resolved, ref)
+	secret, ok := p.secrets[ref]
+	if !ok {
+		return "", errors.New("no such secret")
+	}
+	return secret, nil
+}
+
+var (
+	secretStatic  = Configure("secrets.static", Options[string]{Default: "default"})
+	secretEnv     = Configure("secrets.env", Options[string]{EnvVars: []string{"VT_SECRETS_ENV"}})
+	secretDynamic = Configure("secrets.dynamic", Options[string]{Default: "default", Dynamic: true})
+	secretSlice   = Configure("secrets.slice", Options[[]string]{})
+)
+
+func TestSecretReferences(t *testing.T) {
+	fake := &fakeSecretProvider{secrets: map[string]string{
+		"db#password": "hunter2",
+		"blob":        "shh",
+	}}
+	RegisterSecretProvider("fake", fake)
+	other := &fakeSecretProvider{}
+	RegisterSecretProvider("fake:enc", other)
+
+	secretStatic.Set("fake:db#password")
+	assert.Equal(t, []string{"db#password"}, fake.resolved, "references are resolved by Set")
+	assert.Equal(t, "hunter2", secretStatic.Get())
+	assert.Equal(t, "hunter2", secretStatic.Get())
+	assert.Equal(t, []string{"db#password"}, fake.resolved, "references are not resolved by Get")
+
+	secretDynamic.Set("fake:blob")
+	assert.Equal(t, "shh", secretDynamic.Get())
+
+	secretDynamic.Set("fake:missing")
+	assert.Equal(t, "fake:missing", secretDynamic.Get(), "references failing to resolve read as themselves")
+
+	secretDynamic.Set("fake:enc:blob")
+	assert.Equal(t, "fake:enc:blob", secretDynamic.Get())
+	assert.Equal(t, []string{"blob"}, other.resolved, "the longest scheme wins")
+
+	secretDynamic.Set("unregistered:blob")
+	assert.Equal(t, "unregistered:blob", secretDynamic.Get())
+
+	secretSlice.Set([]string{"fake:blob"})
+	assert.Equal(t, []string{"fake:blob"}, secretSlice.Get(), "only string values are resolved")
+}
+
+func TestResolveStaticSecrets(t *testing.T) {
+	fake := &fakeSecretProvider{secrets: map[string]string{
+		"static": "s3cret",
+	}}
+	RegisterSecretProvider("fakestatic", fake)
+
+	t.Setenv("VT_SECRETS_ENV", "fakestatic:static")
+	require.NoError(t, registry.ResolveStatic())
+	assert.Equal(t, "s3cret", secretEnv.Get())
+
+	t.Setenv("VT_SECRETS_ENV", "fakestatic:missing")
+	assert.ErrorContains(t, registry.ResolveStatic(), "failed to resolve the secret referenced by secrets.env: no such secret")
+	assert.Equal(t, "fakestatic:missing", secretEnv.Get(), "references are not resolved by Get")
+}
@@ -0,0 +1,61 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package viperutil
+
+import (
+	"fmt"
+
+	"vitess.io/vitess/go/viperutil/internal/registry"
+)
+
+// Change is a change of the value of a Value, as passed to the callbacks
+// registered with OnChange.
+type Change[T any] struct {
+	// Key is the key of the Value.
+	Key string
+	Old T
+	New T
+	// Source is where the new value comes from: "default", "config <file>",
+	// "env <variable>", "flag --<name>" or "override".
+	Source string
+}
+
+// OnChange registers fn to be called after each change of the value of v,
+// so that subsystems can react to config changes, e.g. by resizing a pool,
+// instead of calling Get on every use. Dynamic values change when their config
+// is reloaded, and both static and dynamic values change when Set.
+//
+// fn is called synchronously by the goroutine making the change, once the new
+// value can be read with Get, so it should not block. It is only called when
+// the value actually changes.
+//
+// This function panics if v was not returned by Configure.
+func OnChange[T any](v Value[T], fn func(Change[T])) {
+	s, ok := v.(interface{ Subscribe(fn func(old, new T)) })
+	if !ok {
+		panic(fmt.Sprintf("cannot subscribe to the changes of %s: %T is not a configured value", v.Key(), v))
+	}
+
+	s.Subscribe(func(old, new T) {
+		fn(Change[T]{
+			Key:    v.Key(),
+			Old:    old,
+			New:    new,
+			Source: registry.SourceOf(v.Key()).String(),
+		})
+	})
+}
@@ -0,0 +1,58 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package viperutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeValue[T any] struct {
+	Value[T]
+}
+
+func (fakeValue[T]) Key() string { return "fake" }
+
+var (
+	onChangeStatic  = Configure("onchange.static", Options[int]{Default: 1})
+	onChangeDynamic = Configure("onchange.dynamic", Options[[]string]{Default: []string{"a"}, Dynamic: true})
+)
+
+func TestOnChange(t *testing.T) {
+	static, dynamic := onChangeStatic, onChangeDynamic
+	static.Set(1)
+	dynamic.Set([]string{"a"})
+
+	var staticChanges []Change[int]
+	OnChange(static, func(c Change[int]) { staticChanges = append(staticChanges, c) })
+	var dynamicChanges []Change[[]string]
+	OnChange(dynamic, func(c Change[[]string]) { dynamicChanges = append(dynamicChanges, c) })
+
+	static.Set(2)
+	static.Set(2)
+	assert.Equal(t, []Change[int]{{Key: "onchange.static", Old: 1, New: 2, Source: "override"}}, staticChanges)
+
+	dynamic.Set([]string{"a"})
+	assert.Empty(t, dynamicChanges, "setting the same value is not a change")
+	dynamic.Set([]string{"a", "b"})
+	assert.Equal(t, []Change[[]string]{{Key: "onchange.dynamic", Old: []string{"a"}, New: []string{"a", "b"}, Source: "override"}}, dynamicChanges)
+
+	assert.Panics(t, func() {
+		OnChange[int](fakeValue[int]{}, func(Change[int]) {})
+	})
+}
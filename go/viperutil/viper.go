@@ -110,6 +110,9 @@ type Options[T any] struct {
 	// zero value for the type T. This means if T is a pointer type, the default
 	// will be nil, not the zeroed out struct.
 	Default T
+	// Description, if set, describes the value in the config schema (see
+	// debug.Schema). If not set, the usage of its flag is used instead.
+	Description string
 
 	// Dynamic, if set, configures a value to be backed by the dynamic registry.
 	// If a config file is used (via LoadConfig), that file will be watched for
@@ -117,6 +120,24 @@ type Options[T any] struct {
 	// (whereas static values will only ever return the value loaded initially).
 	Dynamic bool
 
+	// Secret, if set, marks the value as a secret, such as a password. The
+	// value is redacted from the output of the debug package and the
+	// /debug/config endpoint, and a dynamic value is only persisted back to
+	// the config file if it was read from it.
+	Secret bool
+
+	// Validate, if set, is called to validate the value, e.g. to check that it
+	// is in range, when the config is loaded and, for dynamic values, each
+	// time it is reloaded. A value that fails its validation is rejected: it
+	// keeps its previous value (for the initial load, its default value), and
+	// the rejection is logged and counted in the ConfigValidationRejections
+	// metric. Values passed to Set are not validated.
+	//
+	// Validate may check constraints across values by calling their Get
+	// method, except for dynamic values, which are blocked while the config
+	// is reloaded.
+	Validate func(T) error
+
 	// GetFunc is the function used to get this value out of a viper.
 	//
 	// If omitted, GetFuncForType will attempt to provide a useful default for
@@ -145,12 +166,15 @@ func Configure[T any](key string, opts Options[T]) (v Value[T]) {
 	}
 
 	base := &value.Base[T]{
-		KeyName:    key,
-		DefaultVal: opts.Default,
-		GetFunc:    getfunc,
-		Aliases:    opts.Aliases,
-		FlagName:   opts.FlagName,
-		EnvVars:    opts.EnvVars,
+		KeyName:     key,
+		DefaultVal:  opts.Default,
+		GetFunc:     getfunc,
+		Aliases:     opts.Aliases,
+		FlagName:    opts.FlagName,
+		EnvVars:     opts.EnvVars,
+		Secret:      opts.Secret,
+		Validate:    opts.Validate,
+		Description: opts.Description,
 	}
 
 	switch {
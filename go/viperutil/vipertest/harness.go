@@ -0,0 +1,319 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vipertest
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	gosync "sync"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/viperutil"
+	"vitess.io/vitess/go/viperutil/internal/sync"
+	"vitess.io/vitess/go/viperutil/internal/value"
+)
+
+// DefaultReloadTimeout is the default amount of time a Harness waits for a
+// config reload to be observed before failing the test.
+var DefaultReloadTimeout = 10 * time.Second
+
+// Harness is a private, per-test config registry that mirrors the way a
+// vitess process loads and watches its config. Values are attached to the
+// harness via Track, after which the test can mutate the config file or the
+// environment and assert on exactly which values changed, and which
+// subscribers were notified, as a result.
+//
+// Config file mutations block until the reload has been applied to the live
+// config, so tests never need to sleep and hope a watch fired.
+//
+// A typical test looks like:
+//
+//	h := vipertest.NewHarness(t, "config.yaml", "foo: 1\n")
+//	vipertest.Track(h, foo)
+//	h.Subscribe("foo-listener")
+//	h.Start()
+//
+//	changes := h.WriteConfig("foo: 2\n")
+//	changes.AssertChanged(t, "foo")
+//	changes.AssertFired(t, "foo-listener")
+type Harness struct {
+	t *testing.T
+
+	configFile string
+	static     *viper.Viper
+	dynamic    *sync.Viper
+
+	values      []tracked
+	reloaded    chan struct{}
+	subscribers map[string]chan struct{}
+
+	started bool
+	cancel  context.CancelFunc
+
+	// mu serializes the change notifications of the tracked values, which
+	// run on config reloads, with their untracking at the end of the test.
+	mu        gosync.Mutex
+	untracked bool
+
+	// ReloadTimeout bounds how long WriteConfig waits for a reload. It
+	// defaults to DefaultReloadTimeout.
+	ReloadTimeout time.Duration
+}
+
+type tracked struct {
+	key string
+	get func() any
+}
+
+// NewHarness returns a Harness whose config file is created in a temporary
+// directory with the given name (the extension determines the config type)
+// and initial contents.
+func NewHarness(t *testing.T, name string, contents string) *Harness {
+	t.Helper()
+
+	h := &Harness{
+		t:             t,
+		configFile:    filepath.Join(t.TempDir(), name),
+		static:        viper.New(),
+		dynamic:       sync.New(),
+		reloaded:      make(chan struct{}, 1),
+		subscribers:   map[string]chan struct{}{},
+		ReloadTimeout: DefaultReloadTimeout,
+	}
+	require.NoError(t, os.WriteFile(h.configFile, []byte(contents), 0o644))
+
+	t.Cleanup(func() {
+		if h.cancel != nil {
+			h.cancel()
+		}
+	})
+
+	return h
+}
+
+// ConfigFile returns the path of the config file backing this harness.
+func (h *Harness) ConfigFile() string { return h.configFile }
+
+// Track rebinds the given value to the harness's registries for the duration
+// of the test. Static values are bound to the harness's static registry, and
+// dynamic values to its dynamic (watched) registry, matching how Configure
+// would have bound them in a real process, with their Validate function.
+//
+// Track must be called before Start.
+func Track[T any](h *Harness, val viperutil.Value[T]) {
+	h.t.Helper()
+	require.False(h.t, h.started, "cannot Track %s after the harness has started", val.Key())
+
+	var (
+		base    *value.Base[T]
+		dynamic bool
+	)
+	switch val := val.(type) {
+	case *value.Static[T]:
+		base = val.Base
+	case *value.Dynamic[T]:
+		base = val.Base
+		dynamic = true
+	default:
+		require.Fail(h.t, "unsupported value", "value %+v does not support tracking", val)
+		return
+	}
+
+	var reg interface {
+		BindEnv(vars ...string) error
+		RegisterAlias(alias string, key string)
+		SetDefault(key string, value any)
+	}
+	oldGet := base.BoundGetFunc
+	if dynamic {
+		reg = h.dynamic
+		base.BoundGetFunc = sync.AdaptGetter(base.Key(), base.GetFunc, h.dynamic)
+		// Like in a real process, the reloads of the config setting an
+		// invalid value are rejected.
+		if base.Validate != nil {
+			h.dynamic.AddValidator(base.Key(), base.ValidateIn)
+		}
+		h.dynamic.OnReload(func() {
+			h.mu.Lock()
+			defer h.mu.Unlock()
+			// The config may still be reloaded once the test is over.
+			if !h.untracked {
+				base.NotifyChange()
+			}
+		})
+	} else {
+		reg = h.static
+		base.BoundGetFunc = base.GetFunc(h.static)
+	}
+	h.t.Cleanup(func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		h.untracked = true
+		base.BoundGetFunc = oldGet
+	})
+
+	reg.SetDefault(base.Key(), base.DefaultVal)
+	for _, alias := range base.Aliases {
+		reg.RegisterAlias(alias, base.Key())
+	}
+	if len(base.EnvVars) > 0 {
+		_ = reg.BindEnv(append([]string{base.Key()}, base.EnvVars...)...)
+	}
+
+	h.values = append(h.values, tracked{
+		key: base.Key(),
+		get: func() any { return val.Get() },
+	})
+}
+
+// Subscribe registers a named subscriber for config reload notifications,
+// equivalent to a component calling viperutil.NotifyConfigReload. Use
+// Changes.Fired or Changes.AssertFired to check whether it was notified.
+//
+// Subscribe must be called before Start.
+func (h *Harness) Subscribe(name string) {
+	h.t.Helper()
+	require.False(h.t, h.started, "cannot Subscribe %s after the harness has started", name)
+
+	ch := make(chan struct{}, 1)
+	h.subscribers[name] = ch
+	h.dynamic.Notify(ch)
+}
+
+// Start loads the config file and begins watching it, as LoadConfig does
+// for a real process.
+func (h *Harness) Start() {
+	h.t.Helper()
+	require.False(h.t, h.started, "harness already started")
+
+	h.dynamic.Notify(h.reloaded)
+
+	h.static.SetConfigFile(h.configFile)
+	require.NoError(h.t, h.static.ReadInConfig())
+
+	cancel, err := h.dynamic.Watch(context.Background(), h.static, 0)
+	require.NoError(h.t, err)
+
+	h.cancel = cancel
+	h.started = true
+}
+
+// Changes describes the effects of a single mutation applied through a
+// Harness.
+type Changes struct {
+	// Keys is the sorted list of tracked keys whose values changed.
+	Keys []string
+	// Subscribers is the sorted list of subscribers that were notified.
+	Subscribers []string
+}
+
+// Changed returns whether the value with the given key changed.
+func (c *Changes) Changed(key string) bool {
+	i := sort.SearchStrings(c.Keys, key)
+	return i < len(c.Keys) && c.Keys[i] == key
+}
+
+// Fired returns whether the named subscriber was notified.
+func (c *Changes) Fired(name string) bool {
+	i := sort.SearchStrings(c.Subscribers, name)
+	return i < len(c.Subscribers) && c.Subscribers[i] == name
+}
+
+// AssertChanged asserts that exactly the given keys changed.
+func (c *Changes) AssertChanged(t *testing.T, keys ...string) bool {
+	t.Helper()
+	return assert.ElementsMatch(t, keys, c.Keys, "changed keys")
+}
+
+// AssertFired asserts that exactly the given subscribers were notified.
+func (c *Changes) AssertFired(t *testing.T, names ...string) bool {
+	t.Helper()
+	return assert.ElementsMatch(t, names, c.Subscribers, "fired subscribers")
+}
+
+// WriteConfig atomically replaces the contents of the config file and blocks
+// until the watched registry has reloaded it, failing the test if that does
+// not happen within ReloadTimeout.
+func (h *Harness) WriteConfig(contents string) *Changes {
+	h.t.Helper()
+	require.True(h.t, h.started, "harness must be started before writing config")
+
+	return h.mutate(func() {
+		// Writing to a tempfile and renaming over the config produces a
+		// single CREATE event, so viper reloads exactly once.
+		tmp := h.configFile + ".tmp"
+		require.NoError(h.t, os.WriteFile(tmp, []byte(contents), 0o644))
+		require.NoError(h.t, os.Rename(tmp, h.configFile))
+
+		select {
+		case <-h.reloaded:
+		case <-time.After(h.ReloadTimeout):
+			require.Fail(h.t, "timed out waiting for config reload", "config file %s", h.configFile)
+		}
+	})
+}
+
+// SetEnv sets an environment variable for the duration of the test (see
+// testing.T.Setenv). Environment variables are consulted on every Get, so no
+// reload is involved and no subscribers are expected to fire.
+func (h *Harness) SetEnv(key, val string) *Changes {
+	h.t.Helper()
+	return h.mutate(func() { h.t.Setenv(key, val) })
+}
+
+func (h *Harness) mutate(f func()) *Changes {
+	before := make([]any, len(h.values))
+	for i, v := range h.values {
+		before[i] = v.get()
+	}
+
+	// Drain stale notifications so only this mutation is reported.
+	h.drain()
+	f()
+
+	changes := &Changes{}
+	for i, v := range h.values {
+		if !reflect.DeepEqual(before[i], v.get()) {
+			changes.Keys = append(changes.Keys, v.key)
+		}
+	}
+	changes.Subscribers = h.drain()
+
+	sort.Strings(changes.Keys)
+	sort.Strings(changes.Subscribers)
+	return changes
+}
+
+func (h *Harness) drain() (fired []string) {
+	for name, ch := range h.subscribers {
+		select {
+		case <-ch:
+			fired = append(fired, name)
+		default:
+		}
+	}
+
+	return fired
+}
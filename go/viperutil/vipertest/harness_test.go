@@ -0,0 +1,112 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vipertest
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/viperutil"
+)
+
+var (
+	dyn = viperutil.Configure("harness.dynamic", viperutil.Options[int]{
+		Dynamic: true,
+		Default: 1,
+	})
+	other = viperutil.Configure("harness.other", viperutil.Options[string]{
+		Dynamic: true,
+		Default: "x",
+	})
+	static = viperutil.Configure("harness.static", viperutil.Options[int]{
+		EnvVars: []string{"VT_HARNESS_STATIC"},
+	})
+	validated = viperutil.Configure("harness.validated", viperutil.Options[int]{
+		Dynamic: true,
+		Default: 1,
+		Validate: func(v int) error {
+			if v <= 0 {
+				return fmt.Errorf("must be positive, not %d", v)
+			}
+			return nil
+		},
+	})
+)
+
+func TestHarness(t *testing.T) {
+	h := NewHarness(t, "config.yaml", "harness:\n  dynamic: 2\n  static: 10\n")
+	Track(h, dyn)
+	Track(h, other)
+	Track(h, static)
+	h.Subscribe("listener")
+	dynChanges := make(chan viperutil.Change[int], 10)
+	viperutil.OnChange(dyn, func(c viperutil.Change[int]) { dynChanges <- c })
+	h.Start()
+
+	assert.Equal(t, 2, dyn.Get())
+	assert.Equal(t, "x", other.Get())
+	assert.Equal(t, 10, static.Get())
+
+	changes := h.WriteConfig("harness:\n  dynamic: 3\n  static: 20\n")
+	changes.AssertChanged(t, "harness.dynamic")
+	changes.AssertFired(t, "listener")
+	assert.True(t, changes.Changed("harness.dynamic"))
+	assert.False(t, changes.Changed("harness.static"), "static values must not change on reload")
+	assert.Equal(t, 3, dyn.Get())
+	assert.Equal(t, 10, static.Get())
+
+	for _, want := range []int{2, 3} {
+		select {
+		case c := <-dynChanges:
+			assert.Equal(t, "harness.dynamic", c.Key)
+			assert.Equal(t, want, c.New)
+			if want == 3 {
+				assert.Equal(t, 2, c.Old)
+			}
+		case <-time.After(h.ReloadTimeout):
+			require.Fail(t, "timed out waiting for the change of harness.dynamic", "want %v", want)
+		}
+	}
+
+	changes = h.WriteConfig("harness:\n  dynamic: 3\n  other: y\n")
+	changes.AssertChanged(t, "harness.other")
+	assert.True(t, changes.Fired("listener"))
+
+	changes = h.SetEnv("VT_HARNESS_STATIC", "30")
+	changes.AssertChanged(t, "harness.static")
+	changes.AssertFired(t)
+	assert.Equal(t, 30, static.Get())
+}
+
+func TestHarnessValidate(t *testing.T) {
+	h := NewHarness(t, "config.yaml", "harness:\n  validated: 2\n")
+	Track(h, validated)
+	h.Start()
+	assert.Equal(t, 2, validated.Get())
+
+	changes := h.WriteConfig("harness:\n  validated: 3\n")
+	changes.AssertChanged(t, "harness.validated")
+	assert.Equal(t, 3, validated.Get())
+
+	// The reloads setting an invalid value are rejected.
+	h.WriteConfig("harness:\n  validated: -1\n")
+	assert.Equal(t, 3, validated.Get())
+}
@@ -36,6 +36,7 @@ var (
 			FlagName: "discovery_low_replication_lag",
 			Default:  30 * time.Second,
 			Dynamic:  true,
+			Validate: validateReplicationLag,
 		},
 	)
 	highReplicationLagMinServing = viperutil.Configure(
@@ -44,6 +45,7 @@ var (
 			FlagName: "discovery_high_replication_lag_minimum_serving",
 			Default:  2 * time.Hour,
 			Dynamic:  true,
+			Validate: validateReplicationLag,
 		},
 	)
 	minNumTablets = viperutil.Configure(
@@ -52,6 +54,12 @@ var (
 			FlagName: "min_number_serving_vttablets",
 			Default:  2,
 			Dynamic:  true,
+			Validate: func(n int) error {
+				if n < 0 {
+					return fmt.Errorf("min number of serving vttablets must not be negative, not %d", n)
+				}
+				return nil
+			},
 		},
 	)
 	legacyReplicationLagAlgorithm = viperutil.Configure(
@@ -63,6 +71,13 @@ var (
 	)
 )
 
+func validateReplicationLag(lag time.Duration) error {
+	if lag < 0 {
+		return fmt.Errorf("replication lag threshold must not be negative, not %v", lag)
+	}
+	return nil
+}
+
 func init() {
 	servenv.OnParseFor("vtgate", registerReplicationFlags)
 }
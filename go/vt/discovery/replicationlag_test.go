@@ -21,7 +21,10 @@ import (
 	"testing"
 	"time"
 
+	"github.com/stretchr/testify/assert"
+
 	"vitess.io/vitess/go/test/utils"
+	"vitess.io/vitess/go/viperutil/vipertest"
 
 	querypb "vitess.io/vitess/go/vt/proto/query"
 	"vitess.io/vitess/go/vt/topo"
@@ -44,6 +47,20 @@ func testSetMinNumTablets(newMin int) {
 	minNumTablets.Set(newMin)
 }
 
+func TestReplicationLagConfigValidation(t *testing.T) {
+	h := vipertest.NewHarness(t, "config.yaml", "discovery:\n  low_replication_lag: 10s\n  min_number_serving_vttablets: 3\n")
+	vipertest.Track(h, lowReplicationLag)
+	vipertest.Track(h, minNumTablets)
+	h.Start()
+	assert.Equal(t, 10*time.Second, lowReplicationLag.Get())
+	assert.Equal(t, 3, minNumTablets.Get())
+
+	// The negative thresholds are rejected, keeping the previous values.
+	h.WriteConfig("discovery:\n  low_replication_lag: -10s\n  min_number_serving_vttablets: -1\n")
+	assert.Equal(t, 10*time.Second, lowReplicationLag.Get())
+	assert.Equal(t, 3, minNumTablets.Get())
+}
+
 func TestFilterByReplicationLagUnhealthy(t *testing.T) {
 	defer utils.EnsureNoLeaks(t)
 	// 1 healthy serving tablet, 1 not healthy
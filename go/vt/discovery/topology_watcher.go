@@ -91,7 +91,9 @@ type TopologyWatcher struct {
 }
 
 // NewTopologyWatcher returns a TopologyWatcher that monitors all
-// the tablets in a cell, and reloads them as needed.
+// the tablets in a cell, and reloads them as needed. It reads up to
+// topoReadConcurrency tablets at a time, or, if it is 0, as many as the
+// --topo_read_concurrency of the moment of each reload.
 func NewTopologyWatcher(ctx context.Context, topoServer *topo.Server, hc HealthCheck, f TabletFilter, cell string, refreshInterval time.Duration, refreshKnownTablets bool, topoReadConcurrency int) *TopologyWatcher {
 	tw := &TopologyWatcher{
 		topoServer:          topoServer,
@@ -78,6 +78,12 @@ var (
 		viperutil.Options[int]{
 			Default:  100 << (10 * 2), // 100 MiB
 			FlagName: "azblob_buffer_size",
+			Validate: func(size int) error {
+				if size <= 0 {
+					return fmt.Errorf("azblob buffer size must be positive, not %d", size)
+				}
+				return nil
+			},
 		},
 	)
 
@@ -86,6 +92,12 @@ var (
 		viperutil.Options[int]{
 			Default:  1,
 			FlagName: "azblob_backup_parallelism",
+			Validate: func(parallelism int) error {
+				if parallelism <= 0 {
+					return fmt.Errorf("azblob backup parallelism must be positive, not %d", parallelism)
+				}
+				return nil
+			},
 		},
 	)
 )
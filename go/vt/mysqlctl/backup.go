@@ -151,6 +151,15 @@ func Backup(ctx context.Context, params BackupParams) error {
 	}
 	params.Logger.Infof("Starting backup %v", bh.Name())
 
+	// Keep the topo records of the shard in the MANIFEST. A backup without
+	// them is still usable, so failing to read them doesn't fail the backup.
+	if params.TopoServer != nil && params.TopoRecords == nil {
+		params.TopoRecords, err = GetBackupTopoRecords(ctx, params.TopoServer, params.Keyspace, params.Shard)
+		if err != nil {
+			params.Logger.Warningf("Cannot read the topo records of %v/%v to keep in the backup: %v", params.Keyspace, params.Shard, err)
+		}
+	}
+
 	// Scope stats to selected backup engine.
 	beParams := params.Copy()
 	beParams.Stats = params.Stats.Scope(
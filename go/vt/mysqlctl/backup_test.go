@@ -42,6 +42,9 @@ import (
 	"vitess.io/vitess/go/vt/logutil"
 	"vitess.io/vitess/go/vt/mysqlctl/backupstats"
 	"vitess.io/vitess/go/vt/mysqlctl/backupstorage"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
 )
 
 const mysqlShutdownTimeout = 1 * time.Minute
@@ -67,6 +70,24 @@ func TestBackupExecutesBackupWithScopedParams(t *testing.T) {
 	require.Equal(t, "Fake", executeBackupStats.ScopeV[backupstats.ScopeImplementation])
 }
 
+// TestBackupKeepsTopoRecords tests that Backup passes the topo records of the
+// shard to ExecuteBackup, for the MANIFEST.
+func TestBackupKeepsTopoRecords(t *testing.T) {
+	env := createFakeBackupRestoreEnv(t)
+	ts := memorytopo.NewServer(env.ctx, "zone1")
+	defer ts.Close()
+	require.NoError(t, ts.CreateKeyspace(env.ctx, "test", &topodatapb.Keyspace{}))
+	env.backupParams.TopoServer = ts
+
+	require.Nil(t, Backup(env.ctx, env.backupParams), env.logger.Events)
+
+	require.Equal(t, 1, len(env.backupEngine.ExecuteBackupCalls))
+	records := env.backupEngine.ExecuteBackupCalls[0].BackupParams.TopoRecords
+	require.NotNil(t, records)
+	require.Len(t, records.Records, 1)
+	require.Equal(t, "keyspaces/test/Keyspace", records.Records[0].Path)
+}
+
 // TestBackupNoStats tests that if BackupParams.Stats is nil, then Backup will
 // pass non-nil Stats to sub-components.
 func TestBackupNoStats(t *testing.T) {
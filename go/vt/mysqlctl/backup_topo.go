@@ -0,0 +1,173 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mysqlctl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// BackupTopoRecords are the topo records of the keyspace and shard of a
+// backup, as they were when the backup was taken. They are kept in the
+// MANIFEST, so the topo can be checked against the backups, or the records
+// recreated from them after the topo is lost.
+type BackupTopoRecords struct {
+	// CapturedTime is when the records were read (RFC 3339 format, UTC).
+	CapturedTime string
+
+	Records []*BackupTopoRecord
+}
+
+// BackupTopoRecord is a file of the global topo.
+type BackupTopoRecord struct {
+	// Path is the path of the file, relative to the global root.
+	Path string
+	// Version is the version of the file when it was read.
+	Version string
+	// Value is the JSON encoding of the record.
+	Value json.RawMessage
+}
+
+// BackupTopoRecordPaths returns the paths of the records kept in the
+// backups of a shard: its keyspace, shard and vschema records.
+func BackupTopoRecordPaths(keyspace, shard string) []string {
+	return []string{
+		path.Join(topo.KeyspacesPath, keyspace, topo.KeyspaceFile),
+		path.Join(topo.KeyspacesPath, keyspace, topo.ShardsPath, shard, topo.ShardFile),
+		path.Join(topo.KeyspacesPath, keyspace, topo.VSchemaFile),
+	}
+}
+
+// GetBackupTopoRecords reads the topo records to keep in a backup of the
+// given shard. Records that don't exist are left out.
+func GetBackupTopoRecords(ctx context.Context, ts *topo.Server, keyspace, shard string) (*BackupTopoRecords, error) {
+	conn, err := ts.ConnForCell(ctx, topo.GlobalCell)
+	if err != nil {
+		return nil, err
+	}
+	records := &BackupTopoRecords{CapturedTime: FormatRFC3339(time.Now().UTC())}
+	for _, filePath := range BackupTopoRecordPaths(keyspace, shard) {
+		data, version, err := conn.Get(ctx, filePath)
+		switch {
+		case topo.IsErrType(err, topo.NoNode):
+			continue
+		case err != nil:
+			return nil, vterrors.Wrapf(err, "cannot read %v", filePath)
+		}
+		value, err := encodeBackupTopoRecord(filePath, data)
+		if err != nil {
+			return nil, err
+		}
+		records.Records = append(records.Records, &BackupTopoRecord{Path: filePath, Version: version.String(), Value: value})
+	}
+	return records, nil
+}
+
+// BackupTopoRecordStatus is how a record of a backup compares to the topo.
+type BackupTopoRecordStatus string
+
+const (
+	// BackupTopoRecordMatches means the topo has the record of the backup.
+	BackupTopoRecordMatches BackupTopoRecordStatus = "matches"
+	// BackupTopoRecordDiffers means the topo has a different record.
+	BackupTopoRecordDiffers BackupTopoRecordStatus = "differs"
+	// BackupTopoRecordMissing means the topo doesn't have the record.
+	BackupTopoRecordMissing BackupTopoRecordStatus = "missing"
+	// BackupTopoRecordCreated means the record was missing, and was created
+	// from the backup.
+	BackupTopoRecordCreated BackupTopoRecordStatus = "created"
+)
+
+// CheckBackupTopoRecords compares the records of a backup to those of the
+// topo. With create, the missing records are created from the backup.
+// Records that differ are never changed: the topo is assumed to be more
+// recent than the backup.
+func CheckBackupTopoRecords(ctx context.Context, ts *topo.Server, records *BackupTopoRecords, create bool) (map[string]BackupTopoRecordStatus, error) {
+	conn, err := ts.ConnForCell(ctx, topo.GlobalCell)
+	if err != nil {
+		return nil, err
+	}
+	statuses := make(map[string]BackupTopoRecordStatus, len(records.Records))
+	for _, record := range records.Records {
+		want, err := decodeBackupTopoRecord(record)
+		if err != nil {
+			return nil, err
+		}
+		data, _, err := conn.Get(ctx, record.Path)
+		switch {
+		case topo.IsErrType(err, topo.NoNode):
+			if !create {
+				statuses[record.Path] = BackupTopoRecordMissing
+				continue
+			}
+			data, err := proto.Marshal(want)
+			if err != nil {
+				return nil, err
+			}
+			if _, err := conn.Create(ctx, record.Path, data); err != nil {
+				return nil, vterrors.Wrapf(err, "cannot create %v", record.Path)
+			}
+			statuses[record.Path] = BackupTopoRecordCreated
+		case err != nil:
+			return nil, vterrors.Wrapf(err, "cannot read %v", record.Path)
+		default:
+			got := topo.NewContentProto(record.Path)
+			if err := proto.Unmarshal(data, got); err != nil {
+				return nil, vterrors.Wrapf(err, "bad data in %v", record.Path)
+			}
+			if proto.Equal(want, got) {
+				statuses[record.Path] = BackupTopoRecordMatches
+			} else {
+				statuses[record.Path] = BackupTopoRecordDiffers
+			}
+		}
+	}
+	return statuses, nil
+}
+
+func encodeBackupTopoRecord(filePath string, data []byte) (json.RawMessage, error) {
+	m := topo.NewContentProto(filePath)
+	if m == nil {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INTERNAL, "unknown topo record type for %v", filePath)
+	}
+	if err := proto.Unmarshal(data, m); err != nil {
+		return nil, vterrors.Wrapf(err, "bad data in %v", filePath)
+	}
+	return protojson.MarshalOptions{UseProtoNames: true}.Marshal(m)
+}
+
+func decodeBackupTopoRecord(record *BackupTopoRecord) (proto.Message, error) {
+	m := topo.NewContentProto(record.Path)
+	if m == nil {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "unknown topo record type for %v", record.Path)
+	}
+	if err := protojson.Unmarshal(record.Value, m); err != nil {
+		return nil, fmt.Errorf("cannot parse the backup record of %v: %w", record.Path, err)
+	}
+	return m, nil
+}
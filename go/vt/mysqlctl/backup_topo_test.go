@@ -0,0 +1,90 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mysqlctl
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
+)
+
+func TestBackupTopoRecords(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "zone1")
+	require.NoError(t, ts.CreateKeyspace(ctx, "ks", &topodatapb.Keyspace{DurabilityPolicy: "semi_sync"}))
+	require.NoError(t, ts.CreateShard(ctx, "ks", "-80"))
+	require.NoError(t, ts.SaveVSchema(ctx, "ks", &vschemapb.Keyspace{Sharded: true}))
+
+	records, err := GetBackupTopoRecords(ctx, ts, "ks", "-80")
+	require.NoError(t, err)
+	require.Len(t, records.Records, 3)
+	assert.Equal(t, "keyspaces/ks/Keyspace", records.Records[0].Path)
+	assert.JSONEq(t, `{"durability_policy": "semi_sync"}`, string(records.Records[0].Value))
+
+	// The records survive the MANIFEST encoding.
+	data, err := json.Marshal(&BackupManifest{TopoRecords: records})
+	require.NoError(t, err)
+	manifest := &BackupManifest{}
+	require.NoError(t, json.Unmarshal(data, manifest))
+	records = manifest.TopoRecords
+
+	statuses, err := CheckBackupTopoRecords(ctx, ts, records, false)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]BackupTopoRecordStatus{
+		"keyspaces/ks/Keyspace":         BackupTopoRecordMatches,
+		"keyspaces/ks/shards/-80/Shard": BackupTopoRecordMatches,
+		"keyspaces/ks/VSchema":          BackupTopoRecordMatches,
+	}, statuses)
+
+	// The topo is lost, and a new one is rebuilt from the backup.
+	ts = memorytopo.NewServer(ctx, "zone1")
+	require.NoError(t, ts.CreateKeyspace(ctx, "ks", &topodatapb.Keyspace{}))
+	statuses, err = CheckBackupTopoRecords(ctx, ts, records, false)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]BackupTopoRecordStatus{
+		"keyspaces/ks/Keyspace":         BackupTopoRecordDiffers,
+		"keyspaces/ks/shards/-80/Shard": BackupTopoRecordMissing,
+		"keyspaces/ks/VSchema":          BackupTopoRecordMissing,
+	}, statuses)
+
+	statuses, err = CheckBackupTopoRecords(ctx, ts, records, true)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]BackupTopoRecordStatus{
+		"keyspaces/ks/Keyspace":         BackupTopoRecordDiffers,
+		"keyspaces/ks/shards/-80/Shard": BackupTopoRecordCreated,
+		"keyspaces/ks/VSchema":          BackupTopoRecordCreated,
+	}, statuses)
+	si, err := ts.GetShard(ctx, "ks", "-80")
+	require.NoError(t, err)
+	assert.True(t, si.IsPrimaryServing)
+	vs, err := ts.GetVSchema(ctx, "ks")
+	require.NoError(t, err)
+	assert.True(t, vs.Sharded)
+	// The differing keyspace record was left alone.
+	ki, err := ts.GetKeyspace(ctx, "ks")
+	require.NoError(t, err)
+	assert.Empty(t, ki.DurabilityPolicy)
+}
@@ -87,6 +87,8 @@ type BackupParams struct {
 	UpgradeSafe bool
 	// MysqlShutdownTimeout defines how long we wait during MySQL shutdown if that is part of the backup process.
 	MysqlShutdownTimeout time.Duration
+	// TopoRecords are the topo records of the keyspace and shard, to keep in the MANIFEST
+	TopoRecords *BackupTopoRecords
 }
 
 func (b *BackupParams) Copy() BackupParams {
@@ -105,6 +107,7 @@ func (b *BackupParams) Copy() BackupParams {
 		Stats:                b.Stats,
 		UpgradeSafe:          b.UpgradeSafe,
 		MysqlShutdownTimeout: b.MysqlShutdownTimeout,
+		TopoRecords:          b.TopoRecords,
 	}
 }
 
@@ -326,6 +329,11 @@ type BackupManifest struct {
 
 	// IncrementalDetails is nil for non-incremental backups
 	IncrementalDetails *IncrementalBackupDetails
+
+	// TopoRecords are the topo records of the keyspace and shard when the backup
+	// was started. It is nil for backups taken without access to the topo, or
+	// before the field was added.
+	TopoRecords *BackupTopoRecords `json:",omitempty"`
 }
 
 func (m *BackupManifest) HashKey() string {
@@ -686,6 +686,7 @@ func (be *BuiltinBackupEngine) backupFiles(
 			MySQLVersion:       mysqlVersion,
 			UpgradeSafe:        params.UpgradeSafe,
 			IncrementalDetails: incrDetails,
+			TopoRecords:        params.TopoRecords,
 		},
 
 		// Builtin-specific fields
@@ -1192,7 +1193,7 @@ func getPrimaryPosition(ctx context.Context, tmc tmclient.TabletManagerClient, t
 	if topoproto.TabletAliasIsZero(si.PrimaryAlias) {
 		return replication.Position{}, fmt.Errorf("shard %v/%v has no primary", keyspace, shard)
 	}
-	ti, err := ts.GetTablet(ctx, si.PrimaryAlias)
+	ti, err := ts.GetCachedTablet(ctx, si.PrimaryAlias)
 	if err != nil {
 		return replication.Position{}, fmt.Errorf("can't get primary tablet record %v: %v", topoproto.TabletAliasString(si.PrimaryAlias), err)
 	}
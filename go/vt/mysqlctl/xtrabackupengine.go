@@ -258,6 +258,7 @@ func (be *XtrabackupEngine) executeFullBackup(ctx context.Context, params Backup
 			// xtrabackup backups are always created such that they
 			// are safe to use for upgrades later on.
 			UpgradeSafe: true,
+			TopoRecords: params.TopoRecords,
 		},
 
 		// XtraBackup-specific fields
@@ -121,7 +121,7 @@ func (exec *TabletExecutor) Open(ctx context.Context, keyspace string) error {
 		if !shardInfo.HasPrimary() {
 			return fmt.Errorf("shard: %s does not have a primary", shardName)
 		}
-		tabletInfo, err := exec.ts.GetTablet(ctx, shardInfo.PrimaryAlias)
+		tabletInfo, err := exec.ts.GetCachedTablet(ctx, shardInfo.PrimaryAlias)
 		if err != nil {
 			return fmt.Errorf("unable to get primary tablet info, keyspace: %s, shard: %s, error: %v", keyspace, shardName, err)
 		}
@@ -251,7 +251,12 @@ func (exec *TabletExecutor) executeAlterMigrationThrottle(ctx context.Context, a
 		throttlerConfig.ThrottledApps[req.ThrottledApp.Name] = req.ThrottledApp
 		return throttlerConfig
 	}
-	// We have already locked the keyspace
+	// We have already locked the keyspace, but not its routing.
+	ctx, unlock, lockErr := exec.ts.LockKeyspaceRouting(ctx, req.Keyspace, "UpdateThrottlerConfig")
+	if lockErr != nil {
+		return lockErr
+	}
+	defer unlock(&err)
 	ki, err := exec.ts.GetKeyspace(ctx, req.Keyspace)
 	if err != nil {
 		return err
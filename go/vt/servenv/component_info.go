@@ -0,0 +1,161 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package servenv
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/spf13/pflag"
+
+	"vitess.io/vitess/go/acl"
+	"vitess.io/vitess/go/stats"
+)
+
+// ComponentInfoPath is the URL path of the endpoint serving the
+// ComponentInfo of the binary as JSON.
+const ComponentInfoPath = "/debug/component_info"
+
+// ComponentInfo describes the build and the configuration of a binary, so
+// the versions and the configurations of a fleet can be compared.
+type ComponentInfo struct {
+	// Component is the name of the binary.
+	Component string `json:"component"`
+	Version   string `json:"version"`
+	// GitRevision is the git revision the binary was built from.
+	GitRevision string `json:"git_revision"`
+	// FeatureFlags are the boolean flags set to a value other than their
+	// default, as <name>=<value>, sorted.
+	FeatureFlags []string `json:"feature_flags"`
+	// ConfigHash is a hash of all the flags set to a value other than their
+	// default, but the instanceFlags, which is the same for the binaries
+	// configured the same way.
+	ConfigHash string `json:"config_hash"`
+}
+
+// instanceFlags are the flags which identify an instance of a binary, or
+// where it runs, rather than how it is configured, so they are left out of
+// the ConfigHash. Their names are normalized with dashes.
+var instanceFlags = map[string]bool{
+	"bind-address":      true,
+	"cell":              true,
+	"db-socket":         true,
+	"grpc-bind-address": true,
+	"grpc-port":         true,
+	"init-keyspace":     true,
+	"init-shard":        true,
+	"init-tablet-type":  true,
+	"log-dir":           true,
+	"mysql-port":        true,
+	"mysql-server-port": true,
+	"mysql-socket":      true,
+	"mysqlctl-socket":   true,
+	"pid-file":          true,
+	"port":              true,
+	"tablet-dir":        true,
+	"tablet-hostname":   true,
+	"tablet-path":       true,
+}
+
+// isInstanceFlag returns whether the flag of the given name is one of the
+// instanceFlags, whether it is spelled with dashes or underscores.
+func isInstanceFlag(name string) bool {
+	return instanceFlags[strings.ReplaceAll(name, "_", "-")]
+}
+
+var (
+	// componentFlags are the flags of the binary, which its ComponentInfo
+	// describes once they are parsed.
+	componentFlags *pflag.FlagSet
+
+	componentInfoMu sync.Mutex
+	componentInfo   ComponentInfo
+
+	statsComponentInfo = stats.NewGaugesWithMultiLabels(
+		"ComponentInfo",
+		"Build and configuration of the component, exposed via labels",
+		[]string{"Component", "Version", "GitRevision", "FeatureFlags", "ConfigHash"})
+)
+
+// GetComponentInfo returns the ComponentInfo of the binary. It is only
+// complete once the binary runs.
+func GetComponentInfo() ComponentInfo {
+	componentInfoMu.Lock()
+	defer componentInfoMu.Unlock()
+	return componentInfo
+}
+
+// newComponentInfo returns the ComponentInfo of a binary with the given
+// flags.
+func newComponentInfo(component string, fs *pflag.FlagSet) ComponentInfo {
+	info := ComponentInfo{
+		Component:    component,
+		Version:      AppVersion.version,
+		GitRevision:  AppVersion.buildGitRev,
+		FeatureFlags: []string{},
+	}
+
+	var config []string
+	if fs != nil {
+		fs.VisitAll(func(f *pflag.Flag) {
+			value := f.Value.String()
+			if value == f.DefValue {
+				return
+			}
+			setting := f.Name + "=" + value
+			if !isInstanceFlag(f.Name) {
+				config = append(config, setting)
+			}
+			if f.Value.Type() == "bool" {
+				info.FeatureFlags = append(info.FeatureFlags, setting)
+			}
+		})
+	}
+	// VisitAll visits the flags in lexicographical order already.
+	sum := sha256.Sum256([]byte(strings.Join(config, "\n")))
+	info.ConfigHash = hex.EncodeToString(sum[:8])
+	sort.Strings(info.FeatureFlags)
+	return info
+}
+
+func publishComponentInfo() {
+	info := newComponentInfo(binaryName, componentFlags)
+
+	componentInfoMu.Lock()
+	componentInfo = info
+	componentInfoMu.Unlock()
+
+	statsComponentInfo.Set([]string{info.Component, info.Version, info.GitRevision, strings.Join(info.FeatureFlags, ","), info.ConfigHash}, 1)
+
+	HTTPHandleFunc(ComponentInfoPath, func(w http.ResponseWriter, r *http.Request) {
+		if err := acl.CheckAccessHTTP(r, acl.MONITORING); err != nil {
+			acl.SendError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(GetComponentInfo())
+	})
+}
+
+func init() {
+	OnRun(publishComponentInfo)
+}
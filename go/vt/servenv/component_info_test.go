@@ -0,0 +1,63 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package servenv
+
+import (
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewComponentInfo(t *testing.T) {
+	newFlags := func(args ...string) *pflag.FlagSet {
+		fs := pflag.NewFlagSet("vtgate", pflag.ContinueOnError)
+		fs.Bool("enable-buffer", false, "")
+		fs.Bool("enable-direct-ddl", true, "")
+		fs.String("cell", "", "")
+		fs.String("tablet_path", "", "")
+		fs.String("topo_global_root", "", "")
+		fs.Int("port", 0, "")
+		require.NoError(t, fs.Parse(args))
+		return fs
+	}
+
+	defaults := newComponentInfo("vtgate", newFlags())
+	assert.Equal(t, "vtgate", defaults.Component)
+	assert.Equal(t, AppVersion.version, defaults.Version)
+	assert.Empty(t, defaults.FeatureFlags)
+
+	info := newComponentInfo("vtgate", newFlags("--enable-buffer", "--enable-direct-ddl=false", "--topo_global_root", "/vitess/global"))
+	assert.Equal(t, []string{"enable-buffer=true", "enable-direct-ddl=false"}, info.FeatureFlags)
+	assert.NotEqual(t, defaults.ConfigHash, info.ConfigHash)
+
+	// Setting a flag to its default leaves the config hash alone, and the
+	// order of the flags does not matter.
+	assert.Equal(t, defaults.ConfigHash, newComponentInfo("vtgate", newFlags("--port", "0")).ConfigHash)
+	assert.Equal(t, info, newComponentInfo("vtgate", newFlags("--topo_global_root", "/vitess/global", "--enable-direct-ddl=false", "--enable-buffer")))
+
+	// A different value of a flag that is not a feature flag changes the
+	// config hash only.
+	other := newComponentInfo("vtgate", newFlags("--enable-buffer", "--enable-direct-ddl=false", "--topo_global_root", "/vitess/other"))
+	assert.Equal(t, info.FeatureFlags, other.FeatureFlags)
+	assert.NotEqual(t, info.ConfigHash, other.ConfigHash)
+
+	// But the flags of the instance don't change it.
+	instance := newComponentInfo("vtgate", newFlags("--enable-buffer", "--enable-direct-ddl=false", "--topo_global_root", "/vitess/global", "--cell", "zone2", "--port", "15001", "--tablet_path", "zone2-100"))
+	assert.Equal(t, info, instance)
+}
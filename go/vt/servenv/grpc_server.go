@@ -269,9 +269,23 @@ func interceptors() []grpc.ServerOption {
 
 	trace.AddGrpcServerOptions(interceptors.Add)
 
+	for i := range registeredInterceptors.streamInterceptors {
+		interceptors.Add(registeredInterceptors.streamInterceptors[i], registeredInterceptors.unaryInterceptors[i])
+	}
+
 	return interceptors.Build()
 }
 
+// registeredInterceptors are the interceptors of RegisterGRPCServerInterceptors.
+var registeredInterceptors serverInterceptorBuilder
+
+// RegisterGRPCServerInterceptors registers interceptors for the gRPC server,
+// run after the built-in ones, for the packages servenv can't depend on. It
+// must be called before the server is created, e.g. in an init function.
+func RegisterGRPCServerInterceptors(s grpc.StreamServerInterceptor, u grpc.UnaryServerInterceptor) {
+	registeredInterceptors.Add(s, u)
+}
+
 func serveGRPC() {
 	if grpccommon.EnableGRPCPrometheus() {
 		grpc_prometheus.Register(GRPCServer)
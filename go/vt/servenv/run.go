@@ -55,10 +55,12 @@ func Run(bindAddress string, port int) {
 		}
 	}()
 
+	stopSIGHUP := handleSIGHUP()
 	ExitChan = make(chan os.Signal, 1)
 	signal.Notify(ExitChan, syscall.SIGTERM, syscall.SIGINT)
 	// Wait for signal
 	<-ExitChan
+	stopSIGHUP()
 	l.Close()
 
 	startTime := time.Now()
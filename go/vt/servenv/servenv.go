@@ -29,6 +29,7 @@ limitations under the License.
 package servenv
 
 import (
+	"encoding/json"
 	"flag"
 	"fmt"
 	"net/url"
@@ -294,12 +295,78 @@ func getFlagHooksFor(cmd string) (hooks []func(fs *pflag.FlagSet)) {
 
 // Needed because some tests require multiple parse passes, so we guard against
 // that here.
-var debugConfigRegisterOnce sync.Once
+var configHooksOnce sync.Once
+
+// registerConfigHooks registers the /debug/config handlers, and the reload
+// of the config on SIGHUP.
+func registerConfigHooks() {
+	configHooksOnce.Do(func() {
+		HTTPHandleFunc("/debug/config", viperdebug.HandlerFunc)
+		HTTPHandleFunc("/debug/config/generations", viperdebug.GenerationsHandlerFunc)
+		HTTPHandleFunc("/debug/config/schema", viperdebug.SchemaHandlerFunc)
+		HTTPHandleFunc("/debug/config/env", viperdebug.EnvHandlerFunc)
+		OnSIGHUP(func() {
+			_, _ = viperutil.ReloadConfig()
+		})
+	})
+}
+
+// printNonDefaultConfig is whether to print the config settings that differ
+// from their defaults, and exit.
+var printNonDefaultConfig bool
+
+// printConfigSchema is whether to print the JSON Schema of the config files,
+// and exit.
+var printConfigSchema bool
+
+// validateConfig is whether to print the config settings failing their
+// validation, and exit.
+var validateConfig bool
+
+func registerPrintConfigFlags(fs *pflag.FlagSet) {
+	fs.BoolVar(&printNonDefaultConfig, "print-non-default-config", printNonDefaultConfig, "print the config settings whose effective value differs from their default, along with where their values come from, and exit")
+	fs.BoolVar(&printConfigSchema, "config-schema", printConfigSchema, "print the JSON Schema of the config files of this binary, with the types, defaults and descriptions of its config settings, and exit")
+	fs.BoolVar(&validateConfig, "validate-config", validateConfig, "validate the config settings of this binary, which can be set in its config files, print those failing their validation along with where their values come from, and exit, with a non-zero status if any failed. The other flags are not validated")
+}
+
+// maybePrintConfig prints the JSON Schema of the config files, if
+// --config-schema is set, the config settings that differ from their defaults
+// as JSON, if --print-non-default-config is set, or the config settings failing
+// their validation, if --validate-config is set, and exits.
+func maybePrintConfig() {
+	switch {
+	case validateConfig:
+		errs := viperutil.ValidationErrors()
+		for _, err := range errs {
+			fmt.Fprintln(os.Stderr, err)
+		}
+		if len(errs) > 0 {
+			os.Exit(1)
+		}
+		fmt.Println("config is valid")
+		os.Exit(0)
+	case printConfigSchema:
+		data, err := json.MarshalIndent(viperdebug.Schema(), "", "  ")
+		if err != nil {
+			log.Exitf("failed to render the config schema: %v", err)
+		}
+		fmt.Println(string(data))
+		os.Exit(0)
+	case printNonDefaultConfig:
+		data, err := json.MarshalIndent(viperdebug.NonDefaultSettings(), "", "  ")
+		if err != nil {
+			log.Exitf("failed to render the non-default config: %v", err)
+		}
+		fmt.Println(string(data))
+		os.Exit(0)
+	}
+}
 
 // ParseFlags initializes flags and handles the common case when no positional
 // arguments are expected.
 func ParseFlags(cmd string) {
 	fs := GetFlagSetFor(cmd)
+	componentFlags = fs
 
 	viperutil.BindFlags(fs)
 
@@ -317,6 +384,7 @@ func ParseFlags(cmd string) {
 	}
 
 	loadViper(cmd)
+	maybePrintConfig()
 
 	logutil.PurgeLogs()
 }
@@ -352,6 +420,7 @@ func MovePersistentFlagsToCobraCommand(cmd *cobra.Command) {
 
 func moveFlags(name string, fs *pflag.FlagSet) {
 	fs.AddFlagSet(GetFlagSetFor(name))
+	componentFlags = fs
 
 	// glog flags, no better way to do this
 	_flag.PreventGlogVFlagFromClobberingVersionFlagShorthand(fs)
@@ -377,7 +446,8 @@ func CobraPreRunE(cmd *cobra.Command, args []string) error {
 	}
 
 	OnTerm(watchCancel)
-	HTTPHandleFunc("/debug/config", viperdebug.HandlerFunc)
+	registerConfigHooks()
+	maybePrintConfig()
 
 	logutil.PurgeLogs()
 
@@ -398,6 +468,7 @@ func GetFlagSetFor(cmd string) *pflag.FlagSet {
 // ParseFlagsWithArgs initializes flags and returns the positional arguments
 func ParseFlagsWithArgs(cmd string) []string {
 	fs := GetFlagSetFor(cmd)
+	componentFlags = fs
 
 	viperutil.BindFlags(fs)
 
@@ -414,6 +485,7 @@ func ParseFlagsWithArgs(cmd string) []string {
 	}
 
 	loadViper(cmd)
+	maybePrintConfig()
 
 	logutil.PurgeLogs()
 
@@ -426,9 +498,7 @@ func loadViper(cmd string) {
 		log.Exitf("%s: failed to read in config: %s", cmd, err.Error())
 	}
 	OnTerm(watchCancel)
-	debugConfigRegisterOnce.Do(func() {
-		HTTPHandleFunc("/debug/config", viperdebug.HandlerFunc)
-	})
+	registerConfigHooks()
 }
 
 // Flag installations for packages that servenv imports. We need to register
@@ -475,6 +545,7 @@ func init() {
 		"vtorc",
 	} {
 		OnParseFor(cmd, stats.RegisterFlags)
+		OnParseFor(cmd, registerStatsGroupsFlags)
 	}
 
 	// Flags in package log are installed for all binaries.
@@ -483,6 +554,7 @@ func init() {
 	OnParse(logutil.RegisterFlags)
 	// Flags in package viperutil/config are installed for all binaries.
 	OnParse(viperutil.RegisterFlags)
+	OnParse(registerPrintConfigFlags)
 }
 
 func RegisterFlagsForTopoBinaries(registerFlags func(fs *pflag.FlagSet)) {
@@ -0,0 +1,60 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package servenv
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	"vitess.io/vitess/go/event"
+	"vitess.io/vitess/go/vt/log"
+)
+
+var onSIGHUPHooks event.Hooks
+
+// OnSIGHUP registers f to be run each time the process receives a SIGHUP,
+// once it runs (see Run). All hooks are run in parallel.
+func OnSIGHUP(f func()) {
+	onSIGHUPHooks.Add(f)
+}
+
+// FireSIGHUPHooks fires the hooks registered by OnSIGHUP, as a SIGHUP does.
+func FireSIGHUPHooks() {
+	onSIGHUPHooks.Fire()
+}
+
+// handleSIGHUP fires the OnSIGHUP hooks each time the process receives a
+// SIGHUP, until the returned function is called.
+func handleSIGHUP() func() {
+	sigChan := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(sigChan, syscall.SIGHUP)
+	go func() {
+		defer close(done)
+		for range sigChan {
+			log.Info("Received SIGHUP, firing the OnSIGHUP hooks")
+			FireSIGHUPHooks()
+		}
+	}()
+
+	return func() {
+		signal.Stop(sigChan)
+		close(sigChan)
+		<-done
+	}
+}
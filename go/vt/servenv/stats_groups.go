@@ -0,0 +1,50 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package servenv
+
+import (
+	"github.com/spf13/pflag"
+
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/viperutil"
+)
+
+// statsEnabledGroups are the names of the groups of expensive stats to record
+// (see stats.Group). It is dynamic, so that operators can turn high-cardinality
+// diagnostics on temporarily by reloading the config, without a restart.
+var statsEnabledGroups = viperutil.Configure(
+	"stats.enabled_groups",
+	viperutil.Options[[]string]{
+		FlagName: "stats_enabled_groups",
+		Dynamic:  true,
+	},
+)
+
+func registerStatsGroupsFlags(fs *pflag.FlagSet) {
+	fs.StringSlice("stats_enabled_groups", statsEnabledGroups.Default(), "Comma-separated list of the groups of expensive stats to record, e.g. topo_paths. It can be changed at runtime by reloading the config.")
+
+	viperutil.BindFlags(fs, statsEnabledGroups)
+}
+
+func init() {
+	OnInit(func() {
+		stats.SetEnabledGroups(statsEnabledGroups.Get())
+	})
+	viperutil.OnChange(statsEnabledGroups, func(change viperutil.Change[[]string]) {
+		stats.SetEnabledGroups(change.New)
+	})
+}
@@ -54,11 +54,15 @@ func TestFindAllTargets(t *testing.T) {
 	defer cancel()
 	ts := memorytopo.NewServer(ctx, "cell1", "cell2")
 
+	// The lists of keyspaces are listed again on each call, instead of
+	// eventually catching up with the changes through a watch.
 	srvTopoCacheRefresh = 0
 	srvTopoCacheTTL = 0
+	srvTopoWatchKeyspaceNames = false
 	defer func() {
 		srvTopoCacheRefresh = 1 * time.Second
 		srvTopoCacheTTL = 1 * time.Second
+		srvTopoWatchKeyspaceNames = true
 
 	}()
 	counts := stats.NewCountersWithSingleLabel("", "Resilient srvtopo server operations", "type")
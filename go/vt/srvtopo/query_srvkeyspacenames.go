@@ -18,23 +18,39 @@ package srvtopo
 
 import (
 	"context"
+	"sync"
 	"time"
 
 	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/log"
 	"vitess.io/vitess/go/vt/topo"
 )
 
+// SrvKeyspaceNamesQuery serves the lists of keyspaces of the cells. With
+// --srv_topo_watch_keyspace_names, the list of a cell is kept up to date by a
+// watch, instead of being listed again every --srv_topo_cache_refresh. The
+// cells whose topo server cannot watch directories are polled.
 type SrvKeyspaceNamesQuery struct {
 	rq *resilientQuery
+	rw *resilientWatcher
+
+	mu sync.Mutex
+	// unwatchableCells are the cells whose topo server does not support
+	// watching the list of keyspaces.
+	unwatchableCells map[string]bool
 }
 
-func NewSrvKeyspaceNamesQuery(topoServer *topo.Server, counts *stats.CountersWithSingleLabel, cacheRefresh, cacheTTL time.Duration) *SrvKeyspaceNamesQuery {
+func NewSrvKeyspaceNamesQuery(ctx context.Context, topoServer *topo.Server, counts *stats.CountersWithSingleLabel, cacheRefresh, cacheTTL time.Duration) *SrvKeyspaceNamesQuery {
+	q := &SrvKeyspaceNamesQuery{
+		unwatchableCells: make(map[string]bool),
+	}
+
 	query := func(ctx context.Context, entry *queryEntry) (any, error) {
 		cell := entry.key.(cellName)
 		return topoServer.GetSrvKeyspaceNames(ctx, string(cell))
 	}
 
-	rq := &resilientQuery{
+	q.rq = &resilientQuery{
 		query:                query,
 		counts:               counts,
 		cacheRefreshInterval: cacheRefresh,
@@ -42,10 +58,73 @@ func NewSrvKeyspaceNamesQuery(topoServer *topo.Server, counts *stats.CountersWit
 		entries:              make(map[string]*queryEntry),
 	}
 
-	return &SrvKeyspaceNamesQuery{rq}
+	if !srvTopoWatchKeyspaceNames {
+		return q
+	}
+
+	watch := func(entry *watchEntry) {
+		cell := entry.key.(cellName)
+		requestCtx, requestCancel := context.WithCancel(ctx)
+		defer requestCancel()
+
+		current, changes, err := topoServer.WatchSrvKeyspaceNames(requestCtx, string(cell))
+		if err != nil {
+			if topo.IsErrType(err, topo.NoImplementation) {
+				log.Infof("Cannot watch the keyspaces of cell %v, polling them instead: %v", cell, err)
+				q.mu.Lock()
+				q.unwatchableCells[string(cell)] = true
+				q.mu.Unlock()
+			}
+			entry.update(ctx, nil, err, true)
+			return
+		}
+
+		entry.update(ctx, current.Value, current.Err, true)
+		if current.Err != nil {
+			return
+		}
+
+		for c := range changes {
+			entry.update(ctx, c.Value, c.Err, false)
+			if c.Err != nil {
+				return
+			}
+		}
+	}
+
+	q.rw = &resilientWatcher{
+		watcher:              watch,
+		counts:               counts,
+		cacheRefreshInterval: cacheRefresh,
+		cacheTTL:             cacheTTL,
+		entries:              make(map[string]*watchEntry),
+	}
+
+	return q
+}
+
+// watching returns whether the list of keyspaces of cell is watched.
+func (q *SrvKeyspaceNamesQuery) watching(cell string) bool {
+	if q.rw == nil {
+		return false
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return !q.unwatchableCells[cell]
 }
 
+// GetSrvKeyspaceNames returns the list of keyspaces of cell. staleOK only
+// applies to the cells which are polled, the watched lists being always up to
+// date while the watch runs.
 func (q *SrvKeyspaceNamesQuery) GetSrvKeyspaceNames(ctx context.Context, cell string, staleOK bool) ([]string, error) {
+	if q.watching(cell) {
+		v, err := q.rw.getValue(ctx, cellName(cell))
+		if !topo.IsErrType(err, topo.NoImplementation) {
+			names, _ := v.([]string)
+			return names, err
+		}
+	}
+
 	v, err := q.rq.getCurrentValue(ctx, cellName(cell), staleOK)
 	names, _ := v.([]string)
 	return names, err
@@ -53,8 +132,6 @@ func (q *SrvKeyspaceNamesQuery) GetSrvKeyspaceNames(ctx context.Context, cell st
 
 func (q *SrvKeyspaceNamesQuery) srvKeyspaceNamesCacheStatus() (result []*SrvKeyspaceNamesCacheStatus) {
 	q.rq.mutex.Lock()
-	defer q.rq.mutex.Unlock()
-
 	for _, entry := range q.rq.entries {
 		entry.mutex.Lock()
 		value, _ := entry.value.([]string)
@@ -67,5 +144,32 @@ func (q *SrvKeyspaceNamesQuery) srvKeyspaceNamesCacheStatus() (result []*SrvKeys
 		})
 		entry.mutex.Unlock()
 	}
+	q.rq.mutex.Unlock()
+
+	if q.rw == nil {
+		return
+	}
+
+	q.rw.mutex.Lock()
+	defer q.rw.mutex.Unlock()
+	for _, entry := range q.rw.entries {
+		if !q.watching(entry.key.String()) {
+			continue
+		}
+		entry.mutex.Lock()
+		expirationTime := time.Now().Add(q.rw.cacheTTL)
+		if entry.watchState != watchStateRunning {
+			expirationTime = entry.lastValueTime.Add(q.rw.cacheTTL)
+		}
+		value, _ := entry.value.([]string)
+		result = append(result, &SrvKeyspaceNamesCacheStatus{
+			Cell:           entry.key.String(),
+			Value:          value,
+			ExpirationTime: expirationTime,
+			LastQueryTime:  entry.lastValueTime,
+			LastError:      entry.lastError,
+		})
+		entry.mutex.Unlock()
+	}
 	return
 }
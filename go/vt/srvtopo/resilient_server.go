@@ -32,7 +32,8 @@ var (
 	// srvTopoCacheTTL and srvTopoCacheRefresh control the behavior of
 	// the caching for both watched and unwatched values.
 	//
-	// For entries we don't watch (like the list of Keyspaces), we refresh
+	// For entries we don't watch (like the list of Keyspaces, if its topo
+	// server cannot watch it), we refresh
 	// the cached list from the topo after srv_topo_cache_refresh elapses.
 	// If the fetch fails, we hold onto the cached value until
 	// srv_topo_cache_ttl elapses.
@@ -44,12 +45,17 @@ var (
 	srvTopoTimeout      = 5 * time.Second
 	srvTopoCacheTTL     = 1 * time.Second
 	srvTopoCacheRefresh = 1 * time.Second
+
+	// srvTopoWatchKeyspaceNames is whether the lists of keyspaces are
+	// watched instead of being refreshed every srv_topo_cache_refresh.
+	srvTopoWatchKeyspaceNames = true
 )
 
 func registerFlags(fs *pflag.FlagSet) {
 	fs.DurationVar(&srvTopoTimeout, "srv_topo_timeout", srvTopoTimeout, "topo server timeout")
 	fs.DurationVar(&srvTopoCacheTTL, "srv_topo_cache_ttl", srvTopoCacheTTL, "how long to use cached entries for topology")
 	fs.DurationVar(&srvTopoCacheRefresh, "srv_topo_cache_refresh", srvTopoCacheRefresh, "how frequently to refresh the topology for cached entries")
+	fs.BoolVar(&srvTopoWatchKeyspaceNames, "srv_topo_watch_keyspace_names", srvTopoWatchKeyspaceNames, "watch the lists of keyspaces of the cells for changes instead of refreshing them periodically, if the topo server supports it")
 }
 
 func init() {
@@ -87,7 +93,7 @@ func NewResilientServer(ctx context.Context, base *topo.Server, counts *stats.Co
 		topoServer:            base,
 		SrvKeyspaceWatcher:    NewSrvKeyspaceWatcher(ctx, base, counts, srvTopoCacheRefresh, srvTopoCacheTTL),
 		SrvVSchemaWatcher:     NewSrvVSchemaWatcher(ctx, base, counts, srvTopoCacheRefresh, srvTopoCacheTTL),
-		SrvKeyspaceNamesQuery: NewSrvKeyspaceNamesQuery(base, counts, srvTopoCacheRefresh, srvTopoCacheTTL),
+		SrvKeyspaceNamesQuery: NewSrvKeyspaceNamesQuery(ctx, base, counts, srvTopoCacheRefresh, srvTopoCacheTTL),
 	}
 }
 
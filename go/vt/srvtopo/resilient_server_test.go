@@ -530,9 +530,12 @@ func TestGetSrvKeyspaceNames(t *testing.T) {
 
 	srvTopoCacheTTL = 100 * time.Millisecond
 	srvTopoCacheRefresh = 40 * time.Millisecond
+	// This test covers the caching of the polled lists.
+	srvTopoWatchKeyspaceNames = false
 	defer func() {
 		srvTopoCacheTTL = 1 * time.Second
 		srvTopoCacheRefresh = 1 * time.Second
+		srvTopoWatchKeyspaceNames = true
 	}()
 	counts := stats.NewCountersWithSingleLabel("", "Resilient srvtopo server operations", "type")
 	rs := NewResilientServer(ctx, ts, counts)
@@ -668,6 +671,53 @@ func TestGetSrvKeyspaceNames(t *testing.T) {
 	factory.Unlock()
 }
 
+func TestWatchSrvKeyspaceNames(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts, factory := memorytopo.NewServerAndFactory(ctx, "test_cell", "other_cell")
+
+	// The lists are not refreshed during the test, so the changes can only
+	// be seen through the watch.
+	srvTopoCacheTTL = time.Hour
+	srvTopoCacheRefresh = time.Hour
+	defer func() {
+		srvTopoCacheTTL = 1 * time.Second
+		srvTopoCacheRefresh = 1 * time.Second
+	}()
+	counts := stats.NewCountersWithSingleLabel("", "Resilient srvtopo server operations", "type")
+	rs := NewResilientServer(ctx, ts, counts)
+
+	require.NoError(t, ts.UpdateSrvKeyspace(ctx, "test_cell", "test_ks", &topodatapb.SrvKeyspace{}))
+	names, err := rs.GetSrvKeyspaceNames(ctx, "test_cell", false)
+	require.NoError(t, err)
+	require.Equal(t, []string{"test_ks"}, names)
+
+	require.NoError(t, ts.UpdateSrvKeyspace(ctx, "test_cell", "test_ks2", &topodatapb.SrvKeyspace{}))
+	require.Eventually(t, func() bool {
+		names, err = rs.GetSrvKeyspaceNames(ctx, "test_cell", false)
+		return err == nil && reflect.DeepEqual(names, []string{"test_ks", "test_ks2"})
+	}, 5*time.Second, 10*time.Millisecond, "names: %v, err: %v", names, err)
+
+	require.NoError(t, ts.DeleteSrvKeyspace(ctx, "test_cell", "test_ks2"))
+	require.Eventually(t, func() bool {
+		names, err = rs.GetSrvKeyspaceNames(ctx, "test_cell", false)
+		return err == nil && reflect.DeepEqual(names, []string{"test_ks"})
+	}, 5*time.Second, 10*time.Millisecond, "names: %v, err: %v", names, err)
+
+	// A cell whose topo server cannot watch the list is polled instead.
+	factory.AddOperationError(memorytopo.WatchRecursive, "keyspaces", topo.NewError(topo.NoImplementation, "keyspaces"))
+	require.NoError(t, ts.UpdateSrvKeyspace(ctx, "other_cell", "test_ks", &topodatapb.SrvKeyspace{}))
+	names, err = rs.GetSrvKeyspaceNames(ctx, "other_cell", false)
+	require.NoError(t, err)
+	require.Equal(t, []string{"test_ks"}, names)
+
+	status := rs.CacheStatus()
+	require.Len(t, status.SrvKeyspaceNames, 2)
+	for _, s := range status.SrvKeyspaceNames {
+		require.Equal(t, []string{"test_ks"}, s.Value)
+	}
+}
+
 type watched struct {
 	keyspace *topodatapb.SrvKeyspace
 	err      error
@@ -0,0 +1,293 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+var _ Conn = (*ACLConn)(nil)
+
+var topoACLDenied = stats.NewCountersWithMultiLabels(
+	"TopologyACLDenied",
+	"TopologyACLDenied topo operations denied by the --topo_acl_policy_file to the identity of the process",
+	[]string{"Identity", "Operation", "Cell"})
+
+// The access classes of the topo operations, which the rules of an
+// ACLPolicy allow.
+const (
+	// ACLRead allows ListDir, Get, GetVersion, List, GetLock, Watch and
+	// WatchRecursive.
+	ACLRead = "read"
+	// ACLWrite allows Create, Update, Delete and ForceUnlock.
+	ACLWrite = "write"
+	// ACLLock allows Lock, TryLock and NewLeaderParticipation, whose path is
+	// the elections directory of the election.
+	ACLLock = "lock"
+)
+
+// ACLPolicy maps the identities of the components, e.g. vtgate or
+// vttablet, to the topo operations they are allowed. An identity is only
+// allowed the operations one of its rules allows, so an identity without
+// rules is denied everything.
+//
+// A policy which only lets vtgate read everything, and vttablet write its
+// tablet records, looks like:
+//
+//	{
+//	  "identities": {
+//	    "vtgate": [{"access": ["read"], "paths": ["/"]}],
+//	    "vttablet": [
+//	      {"access": ["read"], "paths": ["/"]},
+//	      {"access": ["write"], "cells": ["zone1"], "paths": ["/tablets"]}
+//	    ]
+//	  }
+//	}
+type ACLPolicy struct {
+	Identities map[string][]ACLRule `json:"identities"`
+}
+
+// ACLRule allows some access classes to the paths under some prefixes.
+type ACLRule struct {
+	// Access are the access classes allowed: read, write or lock.
+	Access []string `json:"access"`
+	// Cells are the cells the rule applies to, including global. Empty
+	// applies it to all of them.
+	Cells []string `json:"cells,omitempty"`
+	// Paths are the prefixes of the paths the rule applies to, relative to
+	// the root of the cell. A prefix matches whole path elements: /keyspaces
+	// matches /keyspaces/ks/Keyspace but not /keyspaces2.
+	Paths []string `json:"paths"`
+}
+
+// LoadACLPolicy reads an ACLPolicy from a JSON file.
+func LoadACLPolicy(file string) (*ACLPolicy, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	policy := &ACLPolicy{}
+	if err := json.Unmarshal(data, policy); err != nil {
+		return nil, fmt.Errorf("invalid topo ACL policy %v: %v", file, err)
+	}
+	for identity, rules := range policy.Identities {
+		for _, rule := range rules {
+			for _, access := range rule.Access {
+				if access != ACLRead && access != ACLWrite && access != ACLLock {
+					return nil, fmt.Errorf("invalid topo ACL policy %v: unknown access %q for %v", file, access, identity)
+				}
+			}
+		}
+	}
+	return policy, nil
+}
+
+// ACLConn is a Conn that only lets through the topo operations its rules
+// allow, and denies the others with a PERMISSION_DENIED error, so that a
+// component can't, e.g., delete the keyspaces by mistake.
+//
+// It is a guardrail against accidental misuse only, not a security boundary:
+// the policy is enforced by the process itself, for the identity the process
+// claims (see --topo_acl_identity), so a compromised or misconfigured process
+// is not held to it. Restricting what a component may do to the topology
+// server is the job of the access control of the topology server itself, e.g.
+// etcd RBAC or ZooKeeper ACLs with per-component client certificates.
+type ACLConn struct {
+	cell     string
+	conn     Conn
+	identity string
+	rules    []ACLRule
+}
+
+// NewACLConn returns an ACLConn for conn, allowing identity the operations
+// policy allows it on cell.
+func NewACLConn(cell string, conn Conn, identity string, policy *ACLPolicy) *ACLConn {
+	var rules []ACLRule
+	for _, rule := range policy.Identities[identity] {
+		if len(rule.Cells) == 0 || slices.Contains(rule.Cells, cell) {
+			rules = append(rules, rule)
+		}
+	}
+	return &ACLConn{
+		cell:     cell,
+		conn:     conn,
+		identity: identity,
+		rules:    rules,
+	}
+}
+
+// check returns the PERMISSION_DENIED error of an operation of the access
+// class on filePath not allowed by the rules.
+func (ac *ACLConn) check(operation, access, filePath string) error {
+	p := path.Clean("/" + filePath)
+	for _, rule := range ac.rules {
+		if !slices.Contains(rule.Access, access) {
+			continue
+		}
+		for _, prefix := range rule.Paths {
+			prefix = path.Clean("/" + prefix)
+			if prefix == "/" || p == prefix || strings.HasPrefix(p, prefix+"/") {
+				return nil
+			}
+		}
+	}
+	topoACLDenied.Add([]string{ac.identity, operation, ac.cell}, 1)
+	return vterrors.Errorf(vtrpcpb.Code_PERMISSION_DENIED, "%v is not allowed to %v %v in cell %v", ac.identity, operation, p, ac.cell)
+}
+
+// ListDir is part of the Conn interface.
+func (ac *ACLConn) ListDir(ctx context.Context, dirPath string, full bool) ([]DirEntry, error) {
+	if err := ac.check("ListDir", ACLRead, dirPath); err != nil {
+		return nil, err
+	}
+	return ac.conn.ListDir(ctx, dirPath, full)
+}
+
+// Create is part of the Conn interface.
+func (ac *ACLConn) Create(ctx context.Context, filePath string, contents []byte) (Version, error) {
+	if err := ac.check("Create", ACLWrite, filePath); err != nil {
+		return nil, err
+	}
+	return ac.conn.Create(ctx, filePath, contents)
+}
+
+// Update is part of the Conn interface.
+func (ac *ACLConn) Update(ctx context.Context, filePath string, contents []byte, version Version) (Version, error) {
+	if err := ac.check("Update", ACLWrite, filePath); err != nil {
+		return nil, err
+	}
+	return ac.conn.Update(ctx, filePath, contents, version)
+}
+
+// Get is part of the Conn interface.
+func (ac *ACLConn) Get(ctx context.Context, filePath string) ([]byte, Version, error) {
+	if err := ac.check("Get", ACLRead, filePath); err != nil {
+		return nil, nil, err
+	}
+	return ac.conn.Get(ctx, filePath)
+}
+
+// GetVersion is part of the Conn interface.
+func (ac *ACLConn) GetVersion(ctx context.Context, filePath string, version int64) ([]byte, error) {
+	if err := ac.check("GetVersion", ACLRead, filePath); err != nil {
+		return nil, err
+	}
+	return ac.conn.GetVersion(ctx, filePath, version)
+}
+
+// List is part of the Conn interface.
+func (ac *ACLConn) List(ctx context.Context, filePathPrefix string) ([]KVInfo, error) {
+	if err := ac.check("List", ACLRead, filePathPrefix); err != nil {
+		return nil, err
+	}
+	return ac.conn.List(ctx, filePathPrefix)
+}
+
+// Delete is part of the Conn interface.
+func (ac *ACLConn) Delete(ctx context.Context, filePath string, version Version) error {
+	if err := ac.check("Delete", ACLWrite, filePath); err != nil {
+		return err
+	}
+	return ac.conn.Delete(ctx, filePath, version)
+}
+
+// Lock is part of the Conn interface.
+func (ac *ACLConn) Lock(ctx context.Context, dirPath, contents string) (LockDescriptor, error) {
+	if err := ac.check("Lock", ACLLock, dirPath); err != nil {
+		return nil, err
+	}
+	return ac.conn.Lock(ctx, dirPath, contents)
+}
+
+// TryLock is part of the Conn interface.
+func (ac *ACLConn) TryLock(ctx context.Context, dirPath, contents string) (LockDescriptor, error) {
+	if err := ac.check("TryLock", ACLLock, dirPath); err != nil {
+		return nil, err
+	}
+	return ac.conn.TryLock(ctx, dirPath, contents)
+}
+
+// LockNameWithTTL is part of the NamedLockConn interface.
+func (ac *ACLConn) LockNameWithTTL(ctx context.Context, dirPath, contents string, ttl time.Duration) (LockDescriptor, error) {
+	if err := ac.check("LockNameWithTTL", ACLLock, dirPath); err != nil {
+		return nil, err
+	}
+	return lockNameWithTTL(ctx, ac.conn, dirPath, contents, ttl)
+}
+
+// GetLock is part of the LockHolderConn interface.
+func (ac *ACLConn) GetLock(ctx context.Context, dirPath string) (*LockInfo, error) {
+	if err := ac.check("GetLock", ACLRead, dirPath); err != nil {
+		return nil, err
+	}
+	return getLock(ctx, ac.conn, dirPath)
+}
+
+// ForceUnlock is part of the LockHolderConn interface.
+func (ac *ACLConn) ForceUnlock(ctx context.Context, dirPath, contents string) error {
+	if err := ac.check("ForceUnlock", ACLWrite, dirPath); err != nil {
+		return err
+	}
+	return forceUnlock(ctx, ac.conn, dirPath, contents)
+}
+
+// Watch is part of the Conn interface.
+func (ac *ACLConn) Watch(ctx context.Context, filePath string) (*WatchData, <-chan *WatchData, error) {
+	if err := ac.check("Watch", ACLRead, filePath); err != nil {
+		return nil, nil, err
+	}
+	return ac.conn.Watch(ctx, filePath)
+}
+
+// WatchRecursive is part of the Conn interface.
+func (ac *ACLConn) WatchRecursive(ctx context.Context, path string) ([]*WatchDataRecursive, <-chan *WatchDataRecursive, error) {
+	if err := ac.check("WatchRecursive", ACLRead, path); err != nil {
+		return nil, nil, err
+	}
+	return ac.conn.WatchRecursive(ctx, path)
+}
+
+// NewLeaderParticipation is part of the Conn interface.
+func (ac *ACLConn) NewLeaderParticipation(name, id string) (LeaderParticipation, error) {
+	return ac.NewLeaderParticipationWithTTL(name, id, 0)
+}
+
+// NewLeaderParticipationWithTTL is part of the LeaderParticipationTTLConn
+// interface.
+func (ac *ACLConn) NewLeaderParticipationWithTTL(name, id string, ttl time.Duration) (LeaderParticipation, error) {
+	if err := ac.check("NewLeaderParticipation", ACLLock, path.Join(ElectionsPath, name)); err != nil {
+		return nil, err
+	}
+	return newLeaderParticipationWithTTL(ac.conn, name, id, ttl)
+}
+
+// Close is part of the Conn interface.
+func (ac *ACLConn) Close() {
+	ac.conn.Close()
+}
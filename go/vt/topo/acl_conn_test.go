@@ -0,0 +1,94 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topo_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+const testACLPolicy = `{
+  "identities": {
+    "vtgate": [{"access": ["read"], "paths": ["/"]}],
+    "vttablet": [
+      {"access": ["read"], "paths": ["/"]},
+      {"access": ["write"], "cells": ["zone1"], "paths": ["/tablets"]}
+    ]
+  }
+}`
+
+func TestACLConn(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	_, factory := memorytopo.NewServerAndFactory(ctx, "zone1", "zone2")
+
+	policyFile := filepath.Join(t.TempDir(), "policy.json")
+	require.NoError(t, os.WriteFile(policyFile, []byte(testACLPolicy), 0o644))
+	policy, err := topo.LoadACLPolicy(policyFile)
+	require.NoError(t, err)
+
+	conn, err := factory.Create("zone1", "", "")
+	require.NoError(t, err)
+	_, err = conn.Create(ctx, "keyspaces/ks/Keyspace", []byte("ks"))
+	require.NoError(t, err)
+
+	// vtgate can read, but not delete the keyspaces.
+	vtgate := topo.NewACLConn("zone1", conn, "vtgate", policy)
+	contents, _, err := vtgate.Get(ctx, "keyspaces/ks/Keyspace")
+	require.NoError(t, err)
+	assert.Equal(t, "ks", string(contents))
+	snapshot := stats.TakeSnapshot()
+	err = vtgate.Delete(ctx, "keyspaces/ks/Keyspace", nil)
+	assert.Equal(t, vtrpcpb.Code_PERMISSION_DENIED, vterrors.Code(err), "%v", err)
+	_, err = vtgate.Lock(ctx, "keyspaces/ks", "lock")
+	assert.Equal(t, vtrpcpb.Code_PERMISSION_DENIED, vterrors.Code(err), "%v", err)
+	assert.Equal(t, map[string]int64{"vtgate.Delete.zone1": 1, "vtgate.Lock.zone1": 1}, snapshot.Diff()["TopologyACLDenied"])
+
+	// vttablet can write its tablet records, in its cell only.
+	vttablet := topo.NewACLConn("zone1", conn, "vttablet", policy)
+	_, err = vttablet.Create(ctx, "tablets/zone1-0000000100/Tablet", []byte("tablet"))
+	require.NoError(t, err)
+	_, err = vttablet.Create(ctx, "tablets2/zone1-0000000100/Tablet", []byte("tablet"))
+	assert.Equal(t, vtrpcpb.Code_PERMISSION_DENIED, vterrors.Code(err), "%v", err)
+	vttablet = topo.NewACLConn("zone2", conn, "vttablet", policy)
+	_, err = vttablet.Create(ctx, "tablets/zone2-0000000100/Tablet", []byte("tablet"))
+	assert.Equal(t, vtrpcpb.Code_PERMISSION_DENIED, vterrors.Code(err), "%v", err)
+
+	// The identities without rules are denied everything.
+	unknown := topo.NewACLConn("zone1", conn, "unknown", policy)
+	_, _, err = unknown.Get(ctx, "keyspaces/ks/Keyspace")
+	assert.Equal(t, vtrpcpb.Code_PERMISSION_DENIED, vterrors.Code(err), "%v", err)
+}
+
+func TestLoadACLPolicy(t *testing.T) {
+	policyFile := filepath.Join(t.TempDir(), "policy.json")
+	require.NoError(t, os.WriteFile(policyFile, []byte(`{"identities": {"vtgate": [{"access": ["delete"], "paths": ["/"]}]}}`), 0o644))
+	_, err := topo.LoadACLPolicy(policyFile)
+	assert.ErrorContains(t, err, `unknown access "delete"`)
+}
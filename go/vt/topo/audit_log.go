@@ -0,0 +1,140 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topo
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"os"
+	"path"
+	"time"
+
+	"vitess.io/vitess/go/protoutil"
+	"vitess.io/vitess/go/vt/callerid"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+// This file contains the utility methods to manage the audit log of the
+// topo: the operator actions that bypass the usual safeguards, each stored in
+// a file of the audit_log directory of the global cell, named so that the
+// files sort by the time of the action.
+
+// AuditLogSize is the number of entries kept in the audit log.
+const AuditLogSize = 1000
+
+// AddAuditLogEntry records an action in the audit log, filling its time and
+// host name if unset, and its caller with the caller of the request in ctx if
+// unset and known. It prunes the oldest entries beyond AuditLogSize.
+func (ts *Server) AddAuditLogEntry(ctx context.Context, entry *topodatapb.AuditLogEntry) error {
+	now := time.Now()
+	if entry.Time == nil {
+		entry.Time = protoutil.TimeToProto(now)
+	} else {
+		now = protoutil.TimeFromProto(entry.Time)
+	}
+	if entry.HostName == "" {
+		if h, err := os.Hostname(); err == nil {
+			entry.HostName = h
+		}
+	}
+	if entry.Caller == "" {
+		entry.Caller = requestCaller(ctx)
+	}
+	data, err := entry.MarshalVT()
+	if err != nil {
+		return err
+	}
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return err
+	}
+	name := now.UTC().Format("20060102T150405.000000") + "-" + hex.EncodeToString(suffix)
+	if _, err := ts.globalCell.Create(ctx, path.Join(AuditLogPath, name), data); err != nil {
+		return err
+	}
+
+	names, err := ts.getAuditLogNames(ctx)
+	if err != nil {
+		return err
+	}
+	if len(names) <= AuditLogSize {
+		return nil
+	}
+	for _, name := range names[:len(names)-AuditLogSize] {
+		if err := ts.globalCell.Delete(ctx, path.Join(AuditLogPath, name), nil); err != nil && !IsErrType(err, NoNode) {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetAuditLog returns the entries of the audit log, oldest first.
+func (ts *Server) GetAuditLog(ctx context.Context) ([]*topodatapb.AuditLogEntry, error) {
+	names, err := ts.getAuditLogNames(ctx)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]*topodatapb.AuditLogEntry, 0, len(names))
+	for _, name := range names {
+		data, _, err := ts.globalCell.Get(ctx, path.Join(AuditLogPath, name))
+		if err != nil {
+			if IsErrType(err, NoNode) {
+				// Pruned since we listed the entries.
+				continue
+			}
+			return nil, err
+		}
+		entry := &topodatapb.AuditLogEntry{}
+		if err := entry.UnmarshalVT(data); err != nil {
+			return nil, vterrors.Wrapf(err, "bad audit log data: %q", data)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// getAuditLogNames returns the names of the files of the audit log, oldest
+// first.
+func (ts *Server) getAuditLogNames(ctx context.Context) ([]string, error) {
+	children, err := ts.globalCell.ListDir(ctx, AuditLogPath, false /*full*/)
+	switch {
+	case err == nil:
+	case IsErrType(err, NoNode):
+		return nil, nil
+	default:
+		return nil, err
+	}
+	// ListDir returns the entries sorted by name.
+	names := make([]string, 0, len(children))
+	for _, child := range children {
+		names = append(names, child.Name)
+	}
+	return names, nil
+}
+
+// requestCaller returns the caller of the request in ctx: the principal of
+// its effective caller, or else the user name of its immediate caller, or ""
+// if it has neither.
+func requestCaller(ctx context.Context) string {
+	if principal := callerid.GetPrincipal(callerid.EffectiveCallerIDFromContext(ctx)); principal != "" {
+		return principal
+	}
+	return callerid.GetUsername(callerid.ImmediateCallerIDFromContext(ctx))
+}
@@ -0,0 +1,73 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topo_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/protoutil"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+func TestAuditLog(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "zone1")
+	defer ts.Close()
+
+	entries, err := ts.GetAuditLog(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	require.NoError(t, ts.AddAuditLogEntry(ctx, &topodatapb.AuditLogEntry{
+		Action: "ForceUnlock",
+		Path:   "keyspaces/ks",
+		Caller: "alice",
+	}))
+	entries, err = ts.GetAuditLog(ctx)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "ForceUnlock", entries[0].Action)
+	assert.Equal(t, "keyspaces/ks", entries[0].Path)
+	assert.Equal(t, "alice", entries[0].Caller)
+	assert.NotNil(t, entries[0].Time)
+	assert.NotEmpty(t, entries[0].HostName)
+
+	// The oldest entries are pruned.
+	start := time.Now().Add(time.Hour)
+	for i := 0; i < topo.AuditLogSize; i++ {
+		require.NoError(t, ts.AddAuditLogEntry(ctx, &topodatapb.AuditLogEntry{
+			Time:   protoutil.TimeToProto(start.Add(time.Duration(i) * time.Second)),
+			Action: "ForceUnlock",
+			Path:   fmt.Sprintf("keyspaces/ks%d", i),
+		}))
+	}
+	entries, err = ts.GetAuditLog(ctx)
+	require.NoError(t, err)
+	require.Len(t, entries, topo.AuditLogSize)
+	assert.Equal(t, "keyspaces/ks0", entries[0].Path)
+	assert.Equal(t, fmt.Sprintf("keyspaces/ks%d", topo.AuditLogSize-1), entries[len(entries)-1].Path)
+}
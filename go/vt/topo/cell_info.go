@@ -81,8 +81,14 @@ func (ts *Server) GetCellInfo(ctx context.Context, cell string, strongRead bool)
 	return ci, nil
 }
 
-// CreateCellInfo creates a new CellInfo with the provided content.
+// CreateCellInfo creates a new CellInfo with the provided content. Its root
+// must not overlap the root of the global cell or of another cell stored in
+// the same topology server.
 func (ts *Server) CreateCellInfo(ctx context.Context, cell string, ci *topodatapb.CellInfo) error {
+	if err := ts.checkCellRoot(ctx, cell, ci); err != nil {
+		return err
+	}
+
 	// Pack the content.
 	contents, err := ci.MarshalVT()
 	if err != nil {
@@ -99,7 +105,9 @@ func (ts *Server) CreateCellInfo(ctx context.Context, cell string, ci *topodatap
 // object, update its fields, and then write it back. If the write fails due to
 // a version mismatch, it will re-read the record and retry the update.
 // If the update method returns ErrNoUpdateNeeded, nothing is written,
-// and nil is returned.
+// and nil is returned. Like with CreateCellInfo, the root of a created
+// CellInfo, or the updated root or server address of an existing one, must
+// not overlap another root.
 func (ts *Server) UpdateCellInfoFields(ctx context.Context, cell string, update func(*topodatapb.CellInfo) error) error {
 	filePath := pathForCellInfo(cell)
 	for {
@@ -107,16 +115,19 @@ func (ts *Server) UpdateCellInfoFields(ctx context.Context, cell string, update
 
 		// Read the file, unpack the contents.
 		contents, version, err := ts.globalCell.Get(ctx, filePath)
+		exists := false
 		switch {
 		case err == nil:
 			if err := ci.UnmarshalVT(contents); err != nil {
 				return err
 			}
+			exists = true
 		case IsErrType(err, NoNode):
 			// Nothing to do.
 		default:
 			return err
 		}
+		root, serverAddress := ci.Root, ci.ServerAddress
 
 		// Call update method.
 		if err = update(ci); err != nil {
@@ -126,6 +137,14 @@ func (ts *Server) UpdateCellInfoFields(ctx context.Context, cell string, update
 			return err
 		}
 
+		// The cells whose root already overlaps another one, e.g. created
+		// before it was checked, can still be updated otherwise.
+		if !exists || ci.Root != root || ci.ServerAddress != serverAddress {
+			if err := ts.checkCellRoot(ctx, cell, ci); err != nil {
+				return err
+			}
+		}
+
 		// Pack and save.
 		contents, err = ci.MarshalVT()
 		if err != nil {
@@ -17,12 +17,16 @@ limitations under the License.
 package topo
 
 import (
+	"context"
 	"fmt"
 	"path"
+	"slices"
+	"sort"
 
-	"context"
+	"vitess.io/vitess/go/vt/vterrors"
 
 	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
 )
 
 // This file provides the utility methods to save / retrieve CellsAliases
@@ -196,3 +200,161 @@ func validateAlias(currentAliases map[string]*topodatapb.CellsAlias, newAliasNam
 	}
 	return nil
 }
+
+// CellsAliasImpact describes how changing the cells of an alias affects
+// routing.
+type CellsAliasImpact struct {
+	// AddedCells are the cells that join the alias.
+	AddedCells []string
+	// RemovedCells are the cells that leave the alias.
+	RemovedCells []string
+	// AffectedKeyspaces are the keyspaces whose replica or rdonly tablets
+	// become reachable from (or unreachable from) vtgates in another cell,
+	// as vtgates route those tablet types within an alias: the ones serving
+	// replica or rdonly traffic in a cell that gains or loses a peer in the
+	// alias. Keyspaces only served by primaries are not affected.
+	AffectedKeyspaces []string
+}
+
+// ValidateCellsAlias checks that cellsAlias is a valid value for the alias
+// with the given name, beyond the overlap check done on every write: the
+// alias must not be named after a cell, and it must list at least one cell,
+// each of which must exist and appear only once.
+func (ts *Server) ValidateCellsAlias(ctx context.Context, alias string, cellsAlias *topodatapb.CellsAlias) error {
+	if len(cellsAlias.GetCells()) == 0 {
+		return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "cells alias %v must contain at least one cell", alias)
+	}
+
+	knownCells, err := ts.GetCellInfoNames(ctx)
+	if err != nil {
+		return err
+	}
+	if slices.Contains(knownCells, alias) {
+		return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "cells alias %v has the same name as an existing cell", alias)
+	}
+
+	seen := make(map[string]bool, len(cellsAlias.Cells))
+	for _, cell := range cellsAlias.Cells {
+		if seen[cell] {
+			return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "cells alias %v lists cell %v more than once", alias, cell)
+		}
+		seen[cell] = true
+
+		if !slices.Contains(knownCells, cell) {
+			return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "cells alias %v contains unknown cell %v", alias, cell)
+		}
+	}
+
+	currentAliases, err := ts.GetCellsAliases(ctx, true)
+	if err != nil {
+		return err
+	}
+	if err := validateAlias(currentAliases, alias, cellsAlias); err != nil {
+		return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "cells alias %v is not valid: %v", alias, err)
+	}
+
+	return nil
+}
+
+// AnalyzeCellsAliasChange returns the impact of setting the cells of the
+// given alias to cells, without changing anything. The alias does not need
+// to exist yet. A nil cells describes deleting the alias.
+func (ts *Server) AnalyzeCellsAliasChange(ctx context.Context, alias string, cells []string) (*CellsAliasImpact, error) {
+	var currentCells []string
+	current, err := ts.GetCellsAlias(ctx, alias, true)
+	switch {
+	case err == nil:
+		currentCells = current.Cells
+	case IsErrType(err, NoNode):
+	default:
+		return nil, err
+	}
+
+	impact := &CellsAliasImpact{}
+	for _, cell := range cells {
+		if !slices.Contains(currentCells, cell) {
+			impact.AddedCells = append(impact.AddedCells, cell)
+		}
+	}
+	for _, cell := range currentCells {
+		if !slices.Contains(cells, cell) {
+			impact.RemovedCells = append(impact.RemovedCells, cell)
+		}
+	}
+	if len(impact.AddedCells) == 0 && len(impact.RemovedCells) == 0 {
+		return impact, nil
+	}
+
+	// Vtgates route replica and rdonly traffic to the tablets in the other
+	// cells of the alias of their cell. The tablets whose reachability
+	// changes are the ones in the cells that join or leave the peers of
+	// any cell.
+	var changedPeers []string
+	for _, cell := range slices.Concat(currentCells, impact.AddedCells) {
+		for _, peer := range slices.Concat(currentCells, impact.AddedCells) {
+			if peer == cell || slices.Contains(changedPeers, peer) {
+				continue
+			}
+			before := slices.Contains(currentCells, cell) && slices.Contains(currentCells, peer)
+			after := slices.Contains(cells, cell) && slices.Contains(cells, peer)
+			if before != after {
+				changedPeers = append(changedPeers, peer)
+			}
+		}
+	}
+
+	keyspaces := map[string]bool{}
+	for _, cell := range changedPeers {
+		names, err := ts.GetSrvKeyspaceNames(ctx, cell)
+		switch {
+		case err == nil:
+		case IsErrType(err, NoNode):
+			// The cell does not exist (anymore), so nothing is served there.
+			continue
+		default:
+			return nil, vterrors.Wrapf(err, "cannot list keyspaces served in cell %v", cell)
+		}
+		for _, name := range names {
+			if keyspaces[name] {
+				continue
+			}
+			served, err := ts.servesReplicaTraffic(ctx, cell, name)
+			if err != nil {
+				return nil, err
+			}
+			keyspaces[name] = served
+		}
+	}
+	for name, affected := range keyspaces {
+		if affected {
+			impact.AffectedKeyspaces = append(impact.AffectedKeyspaces, name)
+		}
+	}
+
+	sort.Strings(impact.AddedCells)
+	sort.Strings(impact.RemovedCells)
+	sort.Strings(impact.AffectedKeyspaces)
+	return impact, nil
+}
+
+// servesReplicaTraffic returns whether the given keyspace serves replica or
+// rdonly traffic in the given cell, the only traffic routed within an alias.
+func (ts *Server) servesReplicaTraffic(ctx context.Context, cell, keyspace string) (bool, error) {
+	srvKeyspace, err := ts.GetSrvKeyspace(ctx, cell, keyspace)
+	switch {
+	case err == nil:
+	case IsErrType(err, NoNode):
+		return false, nil
+	default:
+		return false, vterrors.Wrapf(err, "cannot read keyspace %v served in cell %v", keyspace, cell)
+	}
+	for _, partition := range srvKeyspace.GetPartitions() {
+		switch partition.ServedType {
+		case topodatapb.TabletType_REPLICA, topodatapb.TabletType_RDONLY:
+			if len(partition.ShardReferences) > 0 {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,523 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topo
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/log"
+)
+
+var _ Conn = (*ChunkingConn)(nil)
+
+var topoChunkedWrites = stats.NewCountersWithSingleLabel(
+	"TopologyChunkedWrites",
+	"TopologyChunkedWrites values written split in chunks, because they were larger than the maximum value size",
+	"Cell")
+
+// chunkManifestHeader starts the contents of the files whose value is split
+// in chunks, followed by their chunkManifest in JSON.
+const chunkManifestHeader = "vitess-topo-chunked-value-v1\n"
+
+// chunkedReadAttempts is how many times a chunked value is read, when its
+// chunks are deleted while they are read because the value was replaced.
+const chunkedReadAttempts = 3
+
+// ChunksDirSuffix is the suffix of the name of the directory of the chunks of
+// a file, next to it: the chunks of the value of keyspaces/ks/VSchema are
+// written under keyspaces/ks/VSchema.chunks, in a directory per value.
+const ChunksDirSuffix = ".chunks"
+
+// chunksDir returns the directory of the chunks of the values of filePath.
+func chunksDir(filePath string) string {
+	return filePath + ChunksDirSuffix
+}
+
+// isChunkPath returns whether filePath is the path of a chunk.
+func isChunkPath(filePath string) bool {
+	return strings.Contains(filePath, ChunksDirSuffix+"/")
+}
+
+// chunkManifest describes the chunks of a value.
+type chunkManifest struct {
+	// Dir is the directory of the chunks, relative to the root directory of
+	// the cell. The chunks are named by their index.
+	Dir    string `json:"dir"`
+	Chunks int    `json:"chunks"`
+	Size   int    `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// ChunkingConn is a Conn that splits the values larger than the maximum value
+// size of the topology server in chunks, written in files of their own in the
+// directory of chunks next to the file of the value, see ChunksDirSuffix. The
+// file of the value then holds a manifest of the chunks, which the reads
+// reassemble the value from. This lets the large records, like the VSchema of
+// a big keyspace or the routing rules, exceed the value size limit of the
+// topology server, e.g. 1.5MiB for etcd.
+//
+// The chunks are written before the manifest, and the chunks of the values
+// it replaces deleted after it, so the readers never see a partial value: a
+// read which finds the chunks it reads deleted reads the value again. The
+// writes of chunked values delete the chunks of all the other values of their
+// file, and so do the deletes when the ChunkingConn splits the values. The
+// other writes and deletes only delete the chunks of the value the
+// ChunkingConn last read or wrote, so that they don't read the value they
+// replace: the chunks of a chunked value written by another process and
+// replaced by a small value without being read are left until the next
+// chunked write or the delete of the file by a ChunkingConn splitting the
+// values.
+//
+// The processes which don't use a ChunkingConn read the manifests in place
+// of the chunked values, so the values must only be split in chunks once
+// all the processes using the topology server use one.
+type ChunkingConn struct {
+	Conn
+	cell         string
+	maxValueSize int
+
+	mu sync.Mutex
+	// manifests are the manifests of the chunked values last read or
+	// written, by path of their file.
+	manifests map[string]*chunkManifest
+}
+
+// NewChunkingConn returns a ChunkingConn splitting the values larger than
+// maxValueSize bytes. If maxValueSize is 0, no value is split, but the
+// chunked values are still reassembled when they are read, and only the
+// chunks of the values read are deleted.
+func NewChunkingConn(cell string, conn Conn, maxValueSize int) *ChunkingConn {
+	return &ChunkingConn{
+		Conn:         conn,
+		cell:         cell,
+		maxValueSize: maxValueSize,
+		manifests:    make(map[string]*chunkManifest),
+	}
+}
+
+// setManifest records m as the manifest of the current value of filePath,
+// nil if it is not chunked.
+func (cc *ChunkingConn) setManifest(filePath string, m *chunkManifest) {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	if m == nil {
+		delete(cc.manifests, filePath)
+		return
+	}
+	cc.manifests[filePath] = m
+}
+
+// knownManifest returns the manifest of the chunked value of filePath last
+// read or written, if any.
+func (cc *ChunkingConn) knownManifest(filePath string) *chunkManifest {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	return cc.manifests[filePath]
+}
+
+// writeChunks writes contents in chunks, and returns the manifest to write
+// in the file of filePath in its place.
+func (cc *ChunkingConn) writeChunks(ctx context.Context, filePath string, contents []byte) ([]byte, *chunkManifest, error) {
+	// Each value has its own directory of chunks, so that the chunks of the
+	// value it replaces can be read until it is replaced.
+	generation := make([]byte, 8)
+	if _, err := rand.Read(generation); err != nil {
+		return nil, nil, err
+	}
+	sum := sha256.Sum256(contents)
+	m := &chunkManifest{
+		Dir:    path.Join(chunksDir(filePath), hex.EncodeToString(generation)),
+		Size:   len(contents),
+		SHA256: hex.EncodeToString(sum[:]),
+	}
+	for len(contents) > 0 {
+		n := min(len(contents), cc.maxValueSize)
+		if _, err := cc.Conn.Create(ctx, path.Join(m.Dir, strconv.Itoa(m.Chunks)), contents[:n]); err != nil {
+			cc.deleteChunks(ctx, m)
+			return nil, nil, err
+		}
+		contents = contents[n:]
+		m.Chunks++
+	}
+
+	data, err := json.Marshal(m)
+	if err != nil {
+		cc.deleteChunks(ctx, m)
+		return nil, nil, err
+	}
+	return append([]byte(chunkManifestHeader), data...), m, nil
+}
+
+// deleteChunks deletes the chunks of m, logging the failures.
+func (cc *ChunkingConn) deleteChunks(ctx context.Context, m *chunkManifest) {
+	for i := range m.Chunks {
+		chunkPath := path.Join(m.Dir, strconv.Itoa(i))
+		if err := cc.Conn.Delete(ctx, chunkPath, nil); err != nil && !IsErrType(err, NoNode) {
+			log.Warningf("Cannot delete the chunk %v of cell %v: %v", chunkPath, cc.cell, err)
+		}
+	}
+}
+
+// deleteOtherChunks deletes the chunks of the values of filePath but the one
+// whose chunks are in keep, if any, logging the failures.
+func (cc *ChunkingConn) deleteOtherChunks(ctx context.Context, filePath, keep string) {
+	dir := chunksDir(filePath)
+	entries, err := cc.Conn.ListDir(ctx, dir, false /*full*/)
+	if err != nil {
+		if !IsErrType(err, NoNode) {
+			log.Warningf("Cannot list the chunks of %v of cell %v: %v", filePath, cc.cell, err)
+		}
+		return
+	}
+	for _, entry := range entries {
+		valueDir := path.Join(dir, entry.Name)
+		if valueDir == keep {
+			continue
+		}
+		chunks, err := cc.Conn.ListDir(ctx, valueDir, false /*full*/)
+		if err != nil {
+			if !IsErrType(err, NoNode) {
+				log.Warningf("Cannot list the chunks in %v of cell %v: %v", valueDir, cc.cell, err)
+			}
+			continue
+		}
+		cc.deleteChunks(ctx, &chunkManifest{Dir: valueDir, Chunks: len(chunks)})
+	}
+}
+
+// parseChunkManifest returns the manifest contents holds, or nil if they
+// are a value which is not chunked.
+func parseChunkManifest(contents []byte) (*chunkManifest, error) {
+	data, ok := bytes.CutPrefix(contents, []byte(chunkManifestHeader))
+	if !ok {
+		return nil, nil
+	}
+	m := &chunkManifest{}
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, fmt.Errorf("invalid manifest of a chunked value: %v", err)
+	}
+	return m, nil
+}
+
+// assemble returns the value of contents, reassembled from its chunks if
+// they are a manifest. It returns a NoNode error if a chunk is missing.
+func (cc *ChunkingConn) assemble(ctx context.Context, contents []byte) ([]byte, error) {
+	m, err := parseChunkManifest(contents)
+	if m == nil || err != nil {
+		return contents, err
+	}
+	return cc.readChunks(ctx, m)
+}
+
+// readChunks returns the value reassembled from the chunks of m. It returns
+// a NoNode error if a chunk is missing.
+func (cc *ChunkingConn) readChunks(ctx context.Context, m *chunkManifest) ([]byte, error) {
+	value := make([]byte, 0, m.Size)
+	for i := range m.Chunks {
+		chunk, _, err := cc.Conn.Get(ctx, path.Join(m.Dir, strconv.Itoa(i)))
+		if err != nil {
+			return nil, err
+		}
+		value = append(value, chunk...)
+	}
+	sum := sha256.Sum256(value)
+	if len(value) != m.Size || hex.EncodeToString(sum[:]) != m.SHA256 {
+		return nil, fmt.Errorf("the chunks in %v don't match their manifest", m.Dir)
+	}
+	return value, nil
+}
+
+// needsChunks returns whether contents are too large to be written as is.
+func (cc *ChunkingConn) needsChunks(contents []byte) bool {
+	return cc.maxValueSize > 0 && len(contents) > cc.maxValueSize
+}
+
+// Create is part of the Conn interface.
+func (cc *ChunkingConn) Create(ctx context.Context, filePath string, contents []byte) (Version, error) {
+	if !cc.needsChunks(contents) {
+		return cc.Conn.Create(ctx, filePath, contents)
+	}
+
+	manifest, m, err := cc.writeChunks(ctx, filePath, contents)
+	if err != nil {
+		return nil, err
+	}
+	version, err := cc.Conn.Create(ctx, filePath, manifest)
+	if err != nil {
+		cc.deleteChunks(ctx, m)
+		return nil, err
+	}
+	topoChunkedWrites.Add(cc.cell, 1)
+	cc.setManifest(filePath, m)
+	return version, nil
+}
+
+// Update is part of the Conn interface.
+func (cc *ChunkingConn) Update(ctx context.Context, filePath string, contents []byte, version Version) (Version, error) {
+	var m *chunkManifest
+	if cc.needsChunks(contents) {
+		var err error
+		if contents, m, err = cc.writeChunks(ctx, filePath, contents); err != nil {
+			return nil, err
+		}
+	}
+	newVersion, err := cc.Conn.Update(ctx, filePath, contents, version)
+	if err != nil {
+		if m != nil {
+			cc.deleteChunks(ctx, m)
+		}
+		return nil, err
+	}
+	if m != nil {
+		topoChunkedWrites.Add(cc.cell, 1)
+		cc.deleteOtherChunks(ctx, filePath, m.Dir)
+	} else if replaced := cc.knownManifest(filePath); replaced != nil {
+		cc.deleteChunks(ctx, replaced)
+	}
+	cc.setManifest(filePath, m)
+	return newVersion, nil
+}
+
+// Get is part of the Conn interface.
+func (cc *ChunkingConn) Get(ctx context.Context, filePath string) ([]byte, Version, error) {
+	for attempt := 1; ; attempt++ {
+		contents, version, err := cc.Conn.Get(ctx, filePath)
+		if err != nil {
+			return nil, nil, err
+		}
+		m, err := parseChunkManifest(contents)
+		if err != nil {
+			return nil, nil, err
+		}
+		cc.setManifest(filePath, m)
+		if m == nil {
+			return contents, version, nil
+		}
+		value, err := cc.readChunks(ctx, m)
+		if IsErrType(err, NoNode) {
+			if attempt < chunkedReadAttempts {
+				// The value was replaced while its chunks were read.
+				continue
+			}
+			return nil, nil, fmt.Errorf("cannot read the chunks of the value of %v: %v", filePath, err)
+		}
+		return value, version, err
+	}
+}
+
+// GetVersion is part of the Conn interface. The chunks of the old versions of
+// a value are deleted when it is replaced, so only the current version of a
+// chunked value can be read.
+func (cc *ChunkingConn) GetVersion(ctx context.Context, filePath string, version int64) ([]byte, error) {
+	contents, err := cc.Conn.GetVersion(ctx, filePath, version)
+	if err != nil {
+		return nil, err
+	}
+	value, err := cc.assemble(ctx, contents)
+	if IsErrType(err, NoNode) {
+		return nil, fmt.Errorf("the chunks of version %v of the value of %v were deleted: %v", version, filePath, err)
+	}
+	return value, err
+}
+
+// List is part of the Conn interface. The chunks are not listed.
+func (cc *ChunkingConn) List(ctx context.Context, filePathPrefix string) ([]KVInfo, error) {
+	kvs, err := cc.Conn.List(ctx, filePathPrefix)
+	if err != nil {
+		return kvs, err
+	}
+	values := kvs[:0]
+	for _, kv := range kvs {
+		if isChunkPath(string(kv.Key)) {
+			continue
+		}
+		if kv.Value, err = cc.assemble(ctx, kv.Value); err != nil {
+			return nil, fmt.Errorf("cannot read the chunks of the value of %s: %v", kv.Key, err)
+		}
+		values = append(values, kv)
+	}
+	if len(values) == 0 && len(kvs) > 0 {
+		// Only chunks have the prefix.
+		return nil, NewError(NoNode, filePathPrefix)
+	}
+	return values, nil
+}
+
+// ListDir is part of the Conn interface. The directories of chunks are not
+// listed, unless dirPath is in one.
+func (cc *ChunkingConn) ListDir(ctx context.Context, dirPath string, full bool) ([]DirEntry, error) {
+	entries, err := cc.Conn.ListDir(ctx, dirPath, full)
+	if err != nil || isChunkPath(dirPath+"/") {
+		return entries, err
+	}
+	dirs := entries[:0]
+	for _, entry := range entries {
+		if strings.HasSuffix(entry.Name, ChunksDirSuffix) {
+			continue
+		}
+		dirs = append(dirs, entry)
+	}
+	if len(dirs) == 0 && len(entries) > 0 {
+		// Only chunks are in the directory.
+		return nil, NewError(NoNode, dirPath)
+	}
+	return dirs, nil
+}
+
+// Delete is part of the Conn interface. If the ChunkingConn splits the
+// values, it deletes all the chunks of the file. Otherwise, it only deletes
+// the chunks of the value it last read, if it was chunked.
+func (cc *ChunkingConn) Delete(ctx context.Context, filePath string, version Version) error {
+	if err := cc.Conn.Delete(ctx, filePath, version); err != nil {
+		return err
+	}
+	known := cc.knownManifest(filePath)
+	cc.setManifest(filePath, nil)
+	switch {
+	case cc.maxValueSize > 0:
+		cc.deleteOtherChunks(ctx, filePath, "")
+	case known != nil:
+		cc.deleteChunks(ctx, known)
+	}
+	return nil
+}
+
+// Watch is part of the Conn interface. The changes to a chunked value whose
+// chunks are deleted before they are read, because it was replaced again,
+// are skipped: the watch sends the value which replaced it next.
+func (cc *ChunkingConn) Watch(ctx context.Context, filePath string) (*WatchData, <-chan *WatchData, error) {
+	watchCtx, cancel := context.WithCancel(ctx)
+	current, changes, err := cc.Conn.Watch(watchCtx, filePath)
+	if err != nil {
+		cancel()
+		return nil, nil, err
+	}
+	if current.Contents, err = cc.assemble(ctx, current.Contents); err != nil {
+		cancel()
+		go drain(changes)
+		return nil, nil, err
+	}
+
+	out := make(chan *WatchData, cap(changes))
+	go func() {
+		defer close(out)
+		defer cancel()
+		for wd := range changes {
+			if wd.Err == nil {
+				var err error
+				if wd.Contents, err = cc.assemble(watchCtx, wd.Contents); IsErrType(err, NoNode) {
+					continue
+				} else if err != nil {
+					out <- &WatchData{Err: err}
+					cancel()
+					drain(changes)
+					return
+				}
+			}
+			out <- wd
+		}
+	}()
+	return current, out, nil
+}
+
+// WatchRecursive is part of the Conn interface. Like with Watch, the changes
+// to a chunked value whose chunks were deleted before they are read are
+// skipped, and so are the changes to the chunks.
+func (cc *ChunkingConn) WatchRecursive(ctx context.Context, dirPath string) ([]*WatchDataRecursive, <-chan *WatchDataRecursive, error) {
+	watchCtx, cancel := context.WithCancel(ctx)
+	current, changes, err := cc.Conn.WatchRecursive(watchCtx, dirPath)
+	if err != nil {
+		cancel()
+		return nil, nil, err
+	}
+	values := current[:0]
+	for _, wd := range current {
+		if isChunkPath(wd.Path) {
+			continue
+		}
+		if wd.Contents, err = cc.assemble(ctx, wd.Contents); err != nil {
+			cancel()
+			go drain(changes)
+			return nil, nil, err
+		}
+		values = append(values, wd)
+	}
+	current = values
+
+	out := make(chan *WatchDataRecursive, cap(changes))
+	go func() {
+		defer close(out)
+		defer cancel()
+		for wd := range changes {
+			if wd.Err == nil {
+				if isChunkPath(wd.Path) {
+					continue
+				}
+				var err error
+				if wd.Contents, err = cc.assemble(watchCtx, wd.Contents); IsErrType(err, NoNode) {
+					continue
+				} else if err != nil {
+					out <- &WatchDataRecursive{Path: wd.Path, WatchData: WatchData{Err: err}}
+					cancel()
+					drain(changes)
+					return
+				}
+			}
+			out <- wd
+		}
+	}()
+	return current, out, nil
+}
+
+// LockNameWithTTL is part of the NamedLockConn interface.
+func (cc *ChunkingConn) LockNameWithTTL(ctx context.Context, dirPath, contents string, ttl time.Duration) (LockDescriptor, error) {
+	return lockNameWithTTL(ctx, cc.Conn, dirPath, contents, ttl)
+}
+
+// GetLock is part of the LockHolderConn interface.
+func (cc *ChunkingConn) GetLock(ctx context.Context, dirPath string) (*LockInfo, error) {
+	return getLock(ctx, cc.Conn, dirPath)
+}
+
+// ForceUnlock is part of the LockHolderConn interface.
+func (cc *ChunkingConn) ForceUnlock(ctx context.Context, dirPath, contents string) error {
+	return forceUnlock(ctx, cc.Conn, dirPath, contents)
+}
+
+// NewLeaderParticipationWithTTL is part of the LeaderParticipationTTLConn
+// interface.
+func (cc *ChunkingConn) NewLeaderParticipationWithTTL(name, id string, ttl time.Duration) (LeaderParticipation, error) {
+	return newLeaderParticipationWithTTL(cc.Conn, name, id, ttl)
+}
+
+// drain reads the changes of a canceled watch until it ends.
+func drain[T any](changes <-chan T) {
+	for range changes {
+	}
+}
@@ -0,0 +1,210 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topo_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+)
+
+// chunkFiles returns the number of chunks of the values of filePath in conn.
+func chunkFiles(ctx context.Context, t *testing.T, conn topo.Conn, filePath string) int {
+	kvs, err := conn.List(ctx, filePath+topo.ChunksDirSuffix+"/")
+	if topo.IsErrType(err, topo.NoNode) {
+		return 0
+	}
+	require.NoError(t, err)
+	return len(kvs)
+}
+
+func TestChunkingConn(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	_, factory := memorytopo.NewServerAndFactory(ctx, "zone1")
+
+	conn, err := factory.Create("zone1", "", "")
+	require.NoError(t, err)
+	cc := topo.NewChunkingConn("zone1", conn, 10)
+
+	// The small values are written as is.
+	_, err = cc.Create(ctx, "small", []byte("contents"))
+	require.NoError(t, err)
+	contents, _, err := conn.Get(ctx, "small")
+	require.NoError(t, err)
+	assert.Equal(t, "contents", string(contents))
+	assert.Zero(t, chunkFiles(ctx, t, conn, "small"))
+
+	// The large ones are split in chunks, and reassembled.
+	large := strings.Repeat("0123456789", 3) + "end"
+	snapshot := stats.TakeSnapshot()
+	version, err := cc.Create(ctx, "large", []byte(large))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int64{"zone1": 1}, snapshot.Diff()["TopologyChunkedWrites"])
+	assert.Equal(t, 4, chunkFiles(ctx, t, conn, "large"))
+	contents, got, err := cc.Get(ctx, "large")
+	require.NoError(t, err)
+	assert.Equal(t, large, string(contents))
+	assert.Equal(t, version.String(), got.String())
+
+	// The chunks are next to their file, but not listed.
+	_, _, err = conn.Get(ctx, "large.chunks")
+	assert.True(t, topo.IsErrType(err, topo.NoNode), "%v", err)
+	kvs, err := cc.List(ctx, "large")
+	require.NoError(t, err)
+	require.Len(t, kvs, 1)
+	assert.Equal(t, large, string(kvs[0].Value))
+
+	current, changes, err := cc.Watch(ctx, "large")
+	require.NoError(t, err)
+	assert.Equal(t, large, string(current.Contents))
+
+	// Updating the value deletes the chunks it replaces, without reading
+	// the value it replaces.
+	larger := large + strings.Repeat("x", 20)
+	getCalls := factory.GetCallStats().Counts()["Get"]
+	_, err = cc.Update(ctx, "large", []byte(larger), version)
+	require.NoError(t, err)
+	assert.Equal(t, getCalls, factory.GetCallStats().Counts()["Get"])
+	assert.Equal(t, 6, chunkFiles(ctx, t, conn, "large"))
+	contents, _, err = cc.Get(ctx, "large")
+	require.NoError(t, err)
+	assert.Equal(t, larger, string(contents))
+
+	select {
+	case wd := <-changes:
+		require.NoError(t, wd.Err)
+		assert.Equal(t, larger, string(wd.Contents))
+	case <-time.After(10 * time.Second):
+		t.Fatal("the watch did not send the update")
+	}
+
+	// And so does replacing it with a small value.
+	_, err = cc.Update(ctx, "large", []byte("small"), nil)
+	require.NoError(t, err)
+	assert.Zero(t, chunkFiles(ctx, t, conn, "large"))
+
+	// Deleting a chunked value deletes its chunks.
+	_, err = cc.Update(ctx, "large", []byte(large), nil)
+	require.NoError(t, err)
+	assert.Equal(t, 4, chunkFiles(ctx, t, conn, "large"))
+	require.NoError(t, cc.Delete(ctx, "large", nil))
+	assert.Zero(t, chunkFiles(ctx, t, conn, "large"))
+	_, _, err = cc.Get(ctx, "large")
+	assert.True(t, topo.IsErrType(err, topo.NoNode), "%v", err)
+}
+
+func TestChunkingConnUnknownChunks(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	_, factory := memorytopo.NewServerAndFactory(ctx, "zone1")
+
+	conn, err := factory.Create("zone1", "", "")
+	require.NoError(t, err)
+	writer := topo.NewChunkingConn("zone1", conn, 10)
+	other := topo.NewChunkingConn("zone1", conn, 10)
+
+	// A ChunkingConn which doesn't split the values still reads the
+	// chunked values.
+	reader := topo.NewChunkingConn("zone1", conn, 0)
+	large := strings.Repeat("0123456789", 3) + "end"
+	_, err = writer.Create(ctx, "large", []byte(large))
+	require.NoError(t, err)
+	contents, _, err := reader.Get(ctx, "large")
+	require.NoError(t, err)
+	assert.Equal(t, large, string(contents))
+	_, err = reader.Create(ctx, "other", []byte(large))
+	require.NoError(t, err)
+	contents, _, err = conn.Get(ctx, "other")
+	require.NoError(t, err)
+	assert.Equal(t, large, string(contents))
+
+	// The chunks of a value another process wrote are deleted by the next
+	// chunked write of the file, or its delete.
+	_, err = other.Update(ctx, "large", []byte("small"), nil)
+	require.NoError(t, err)
+	assert.Equal(t, 4, chunkFiles(ctx, t, conn, "large"))
+	_, err = other.Update(ctx, "large", []byte(large), nil)
+	require.NoError(t, err)
+	assert.Equal(t, 4, chunkFiles(ctx, t, conn, "large"))
+	require.NoError(t, writer.Delete(ctx, "large", nil))
+	assert.Zero(t, chunkFiles(ctx, t, conn, "large"))
+
+	// A ChunkingConn which doesn't split the values doesn't look for the
+	// chunks of the files it deletes without having read them.
+	_, err = writer.Create(ctx, "large", []byte(large))
+	require.NoError(t, err)
+	listDirCalls := factory.GetCallStats().Counts()["ListDir"]
+	require.NoError(t, reader.Delete(ctx, "large", nil))
+	assert.Equal(t, listDirCalls, factory.GetCallStats().Counts()["ListDir"])
+	assert.Equal(t, 4, chunkFiles(ctx, t, conn, "large"))
+
+	// But it deletes the chunks of the values it read.
+	_, err = writer.Create(ctx, "large", []byte(large))
+	require.NoError(t, err)
+	assert.Equal(t, 8, chunkFiles(ctx, t, conn, "large"))
+	_, _, err = reader.Get(ctx, "large")
+	require.NoError(t, err)
+	require.NoError(t, reader.Delete(ctx, "large", nil))
+	assert.Equal(t, 4, chunkFiles(ctx, t, conn, "large"))
+}
+
+func TestChunkingConnListDir(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	_, factory := memorytopo.NewServerAndFactory(ctx, "zone1")
+
+	conn, err := factory.Create("zone1", "", "")
+	require.NoError(t, err)
+	cc := topo.NewChunkingConn("zone1", conn, 10)
+
+	_, err = cc.Create(ctx, "dir/large", []byte(strings.Repeat("0123456789", 3)))
+	require.NoError(t, err)
+	_, err = cc.Create(ctx, "dir/small", []byte("contents"))
+	require.NoError(t, err)
+
+	// The directories of the chunks are only listed by the Conn.
+	entries, err := conn.ListDir(ctx, "dir", false /*full*/)
+	require.NoError(t, err)
+	assert.Len(t, entries, 3)
+	for _, full := range []bool{false, true} {
+		entries, err = cc.ListDir(ctx, "dir", full)
+		require.NoError(t, err)
+		require.Len(t, entries, 2)
+		assert.Equal(t, "large", entries[0].Name)
+		assert.Equal(t, "small", entries[1].Name)
+	}
+
+	// The chunks can still be listed.
+	entries, err = cc.ListDir(ctx, "dir/large"+topo.ChunksDirSuffix, false /*full*/)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	// A directory of chunks left behind doesn't make its directory exist.
+	require.NoError(t, conn.Delete(ctx, "dir/large", nil))
+	require.NoError(t, cc.Delete(ctx, "dir/small", nil))
+	_, err = cc.ListDir(ctx, "dir", false /*full*/)
+	assert.True(t, topo.IsErrType(err, topo.NoNode), "%v", err)
+}
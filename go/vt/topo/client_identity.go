@@ -0,0 +1,85 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topo
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+
+	"vitess.io/vitess/go/stats"
+)
+
+var topoClientIdentity = stats.NewGaugesWithMultiLabels(
+	"TopologyClientIdentity",
+	"TopologyClientIdentity identity of the client certificate the process presents to the topology server of each cell, set to 1",
+	[]string{"Cell", "Identity"})
+
+// ClientIdentifier is implemented by the Conns which authenticate to their
+// topology server with a client certificate. Each component can present a
+// certificate of its own, so that the topology server can enforce ACLs per
+// component class, and the identity it presents is surfaced in the
+// TopologyClientIdentity stat, the /debug/topo page and the audit log.
+type ClientIdentifier interface {
+	// ClientIdentity returns the identity of the client certificate the
+	// Conn presents, or "" if it presents none.
+	ClientIdentity() string
+}
+
+// ClientIdentityNotifier is implemented by the ClientIdentifiers whose
+// identity can change while they are used, e.g. when their client
+// certificate is reloaded.
+type ClientIdentityNotifier interface {
+	// OnClientIdentityChange registers f to be called after the identity
+	// the Conn presents may have changed.
+	OnClientIdentityChange(f func())
+}
+
+// CertIdentity returns the identity of a certificate: its first URI SAN,
+// like a SPIFFE ID, or else the common name of its subject.
+func CertIdentity(cert *tls.Certificate) string {
+	if cert == nil || len(cert.Certificate) == 0 {
+		return ""
+	}
+	leaf := cert.Leaf
+	if leaf == nil {
+		var err error
+		if leaf, err = x509.ParseCertificate(cert.Certificate[0]); err != nil {
+			return ""
+		}
+	}
+	if len(leaf.URIs) > 0 {
+		return leaf.URIs[0].String()
+	}
+	return leaf.Subject.CommonName
+}
+
+// clientIdentity returns the identity conn presents to its topology
+// server, or "" if it presents none.
+func clientIdentity(conn Conn) string {
+	if ci, ok := conn.(ClientIdentifier); ok {
+		return ci.ClientIdentity()
+	}
+	return ""
+}
+
+// onClientIdentityChange registers f to be called after the identity conn
+// presents may have changed, if it can change.
+func onClientIdentityChange(conn Conn, f func()) {
+	if n, ok := conn.(ClientIdentityNotifier); ok {
+		n.OnClientIdentityChange(f)
+	}
+}
@@ -0,0 +1,197 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topo_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/callerid"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+// newTestCert returns a self-signed certificate for the common name and
+// URIs.
+func newTestCert(t *testing.T, commonName string, uris ...string) *tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	for _, uri := range uris {
+		u, err := url.Parse(uri)
+		require.NoError(t, err)
+		template.URIs = append(template.URIs, u)
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	return &tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+func TestCertIdentity(t *testing.T) {
+	assert.Equal(t, "vtgate", topo.CertIdentity(newTestCert(t, "vtgate")))
+	assert.Equal(t, "spiffe://vitess/vtgate", topo.CertIdentity(newTestCert(t, "vtgate", "spiffe://vitess/vtgate")))
+	assert.Equal(t, "", topo.CertIdentity(nil))
+}
+
+// identityFactory is a Factory whose Conns present a client identity, which
+// can change like that of a reloaded certificate.
+type identityFactory struct {
+	topo.Factory
+
+	mu       sync.Mutex
+	identity string
+	onChange []func()
+}
+
+type identityConn struct {
+	topo.Conn
+	f *identityFactory
+}
+
+func (c *identityConn) ClientIdentity() string {
+	c.f.mu.Lock()
+	defer c.f.mu.Unlock()
+	return c.f.identity
+}
+
+func (c *identityConn) OnClientIdentityChange(f func()) {
+	c.f.mu.Lock()
+	defer c.f.mu.Unlock()
+	c.f.onChange = append(c.f.onChange, f)
+}
+
+func (f *identityFactory) Create(cell, serverAddr, root string) (topo.Conn, error) {
+	conn, err := f.Factory.Create(cell, serverAddr, root)
+	if err != nil {
+		return nil, err
+	}
+	return &identityConn{Conn: conn, f: f}, nil
+}
+
+// setIdentity changes the identity, like a reload of the certificate.
+func (f *identityFactory) setIdentity(identity string) {
+	f.mu.Lock()
+	f.identity = identity
+	onChange := f.onChange
+	f.mu.Unlock()
+	for _, fn := range onChange {
+		fn()
+	}
+}
+
+func TestClientIdentity(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	_, factory := memorytopo.NewServerAndFactory(ctx, "zone1")
+
+	snapshot := stats.TakeSnapshot()
+	f := &identityFactory{Factory: factory, identity: "vtctld"}
+	ts, err := topo.NewWithFactory(f, "", "")
+	require.NoError(t, err)
+	defer ts.Close()
+	_, err = ts.ConnForCell(ctx, "zone1")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int64{"global.vtctld": 1, "zone1.vtctld": 1}, snapshot.Diff()["TopologyClientIdentity"])
+
+	statuses := ts.ConnStatuses()
+	require.Len(t, statuses, 2)
+	assert.Equal(t, "vtctld", statuses[0].ClientIdentity)
+	assert.Equal(t, "vtctld", statuses[1].ClientIdentity)
+
+	// A reloaded certificate is exported right away.
+	f.setIdentity("vtctld-2")
+	snapshot = stats.TakeSnapshot()
+	for _, cell := range []string{topo.GlobalCell, "zone1"} {
+		assert.EqualValues(t, 0, snapshot.Get("TopologyClientIdentity", cell+".vtctld"))
+		assert.EqualValues(t, 1, snapshot.Get("TopologyClientIdentity", cell+".vtctld-2"))
+	}
+	statuses = ts.ConnStatuses()
+	require.Len(t, statuses, 2)
+	assert.Equal(t, "vtctld-2", statuses[0].ClientIdentity)
+	assert.Equal(t, "vtctld-2", statuses[1].ClientIdentity)
+
+	// The audit log entries without a caller are attributed to the caller
+	// of the request, not to the identity of the process.
+	require.NoError(t, ts.AddAuditLogEntry(ctx, &topodatapb.AuditLogEntry{Action: "ForceUnlock"}))
+	bobCtx := callerid.NewContext(ctx, nil, callerid.NewImmediateCallerID("bob"))
+	require.NoError(t, ts.AddAuditLogEntry(bobCtx, &topodatapb.AuditLogEntry{Action: "ForceUnlock"}))
+	require.NoError(t, ts.AddAuditLogEntry(bobCtx, &topodatapb.AuditLogEntry{Action: "ForceUnlock", Caller: "alice"}))
+	entries, err := ts.GetAuditLog(ctx)
+	require.NoError(t, err)
+	var callers []string
+	for _, entry := range entries {
+		callers = append(callers, entry.Caller)
+	}
+	assert.ElementsMatch(t, []string{"", "bob", "alice"}, callers)
+}
+
+func TestHealthCheckConnClientIdentity(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	_, factory := memorytopo.NewServerAndFactory(ctx, "zone1")
+
+	f := &identityFactory{Factory: factory, identity: "vtctld"}
+	conn, err := f.Create("zone1", "", "")
+	require.NoError(t, err)
+	// The new connections present another certificate.
+	f2 := &identityFactory{Factory: factory, identity: "vtctld-2"}
+	create := func() (topo.Conn, error) {
+		return f2.Create("zone1", "", "")
+	}
+	hc := topo.NewHealthCheckConn("zone1", conn, create, 10*time.Millisecond, time.Second, 1)
+	defer hc.Close()
+
+	var changes atomic.Int32
+	hc.OnClientIdentityChange(func() { changes.Add(1) })
+	assert.Equal(t, "vtctld", hc.ClientIdentity())
+	f.setIdentity("vtctld-1")
+	assert.Equal(t, "vtctld-1", hc.ClientIdentity())
+	assert.EqualValues(t, 1, changes.Load())
+
+	// The replaced connection is followed.
+	conn.Close()
+	assert.Eventually(t, func() bool {
+		return hc.ClientIdentity() == "vtctld-2"
+	}, 10*time.Second, 10*time.Millisecond)
+	assert.Eventually(t, func() bool {
+		return changes.Load() == 2
+	}, 10*time.Second, 10*time.Millisecond)
+	f2.setIdentity("vtctld-3")
+	assert.EqualValues(t, 3, changes.Load())
+}
@@ -19,6 +19,7 @@ package topo
 import (
 	"context"
 	"sort"
+	"time"
 )
 
 // Conn defines the interface that must be implemented by topology
@@ -233,6 +234,69 @@ type Conn interface {
 	Close()
 }
 
+// LeaderParticipationTTLConn is implemented by the Conns whose leader
+// elections can be backed by a lease or session of a given TTL. It is kept
+// out of Conn so that the Conn implementations of plugins don't have to
+// implement it, see Server.NewLeaderParticipationWithTTL.
+type LeaderParticipationTTLConn interface {
+	// NewLeaderParticipationWithTTL is like NewLeaderParticipation, with
+	// ttl the time to live of the lease or session backing the
+	// primaryship: the time it takes the other participants to take
+	// over from a primary that died or lost its connection to the
+	// topology server. A short ttl detects the death of the primary
+	// quickly, at the cost of elections on brief network blips. If
+	// ttl is 0, the default of the topology server is used, e.g.
+	// --topo_etcd_lease_ttl. Topology servers may round ttl, or bound
+	// it: consul sessions live between 10s and 24h, and zookeeper
+	// sessions between 2 and 20 ticks of the server.
+	// Returns ErrNoImplementation if ttl is not supported.
+	NewLeaderParticipationWithTTL(name, id string, ttl time.Duration) (LeaderParticipation, error)
+}
+
+// NamedLockConn is implemented by the Conns which can lock a directory that
+// doesn't exist, for the named locks. It is kept out of Conn so that the Conn
+// implementations of plugins don't have to implement it: the named locks of
+// the others create a file in the directory to lock it, and only support the
+// default TTL.
+type NamedLockConn interface {
+	// LockNameWithTTL takes a lock on the given directory like Lock,
+	// but the directory doesn't need to exist: it is used for the named
+	// locks, whose resources have no directory of their own.
+	// ttl is the time to live of the lease or session backing the lock:
+	// the time after which the lock of a holder that died or lost its
+	// connection to the topology server is released. If ttl is 0, the
+	// one of the other locks is used. The TTLs are supported as follows:
+	//   - etcd rounds ttl up to a whole number of seconds, and its
+	//     servers extend the leases shorter than their minimum TTL.
+	//   - consul sessions live between 10s and 24h.
+	//   - zookeeper and memorytopo locks live as long as the connection
+	//     of their holder, so only support a ttl of 0.
+	// Returns ErrNoImplementation if ttl is not supported.
+	// Returns ErrTimeout if ctx expires.
+	// Returns ErrInterrupted if ctx is canceled.
+	LockNameWithTTL(ctx context.Context, dirPath, contents string, ttl time.Duration) (LockDescriptor, error)
+}
+
+// LockHolderConn is implemented by the Conns which can report the holders of
+// their locks, and break them, for the operators to inspect the locks and
+// release the ones of stuck holders. It is kept out of Conn so that the Conn
+// implementations of plugins don't have to implement it: the locks of the
+// others can't be inspected, nor broken.
+type LockHolderConn interface {
+	// GetLock returns the holder of the lock on the given directory,
+	// for inspection: it doesn't take part in the locking.
+	// Returns ErrNoNode if the directory is not locked.
+	GetLock(ctx context.Context, dirPath string) (*LockInfo, error)
+
+	// ForceUnlock releases the lock on the given directory on behalf of
+	// its holder, whose Check will then fail. It is meant to break the
+	// locks of stuck holders, so the lock is only released if it is still
+	// held with the given contents, as returned by GetLock.
+	// Returns ErrNoNode if the directory is not locked.
+	// Returns ErrBadVersion if the lock is held with other contents.
+	ForceUnlock(ctx context.Context, dirPath, contents string) error
+}
+
 // DirEntryType is the type of an entry in a directory.
 type DirEntryType int
 
@@ -302,6 +366,21 @@ type LockDescriptor interface {
 	Unlock(ctx context.Context) error
 }
 
+// LockInfo describes the holder of a lock, as returned by GetLock.
+type LockInfo struct {
+	// Contents are the contents the lock was taken with.
+	Contents string
+
+	// TTL is the time to live of the lease or session backing the lock,
+	// for the implementations that have one. Zero otherwise.
+	TTL time.Duration
+
+	// Stale is set if the lease or session backing the lock is no longer
+	// kept alive by its holder, i.e. the process holding the lock is gone,
+	// and the lock is only left until it expires.
+	Stale bool
+}
+
 // CancelFunc is returned by the Watch method.
 type CancelFunc func()
 
@@ -333,7 +412,8 @@ type WatchData struct {
 // path of the entry that the recursive watch applies to, since an entire
 // file prefix can be watched.
 type WatchDataRecursive struct {
-	// Path is the path that has changed
+	// Path is the path that has changed, relative to the root directory
+	// of the cell.
 	Path string
 
 	WatchData
@@ -417,3 +497,130 @@ type LeaderParticipation interface {
 	// efficiently than needing a busy wait loop.
 	WaitForNewLeader(ctx context.Context) (<-chan string, error)
 }
+
+// ForceResignLeaderParticipation is implemented by the LeaderParticipations
+// which can force the primary of their election to resign. It is kept out of
+// LeaderParticipation so that the topo implementations of plugins don't have
+// to implement it, see Server.ResignLeader.
+type ForceResignLeaderParticipation interface {
+	// ForceResign forces the current primary, whose id must be id, to
+	// resign, e.g. so that primaryship can be drained from its host before
+	// a maintenance. It can be called on any participation in the
+	// election, including one that never called WaitForLeadership, such as
+	// one created by an operator's tool. The context WaitForLeadership
+	// returned to the primary is canceled, and another participant is
+	// elected; the old primary runs for election again when it calls
+	// WaitForLeadership.
+	// It returns a NoNode error if there is no primary, and a BadVersion
+	// error if the primary is not id.
+	ForceResign(ctx context.Context, id string) error
+}
+
+// ReadConsistency is the consistency the reads made with a context require
+// (see WithConsistency).
+type ReadConsistency int
+
+const (
+	// DefaultConsistency is the consistency of the reads of the contexts
+	// which don't set one: that of the topo implementation, which is
+	// linearizable for all the real ones.
+	DefaultConsistency = ReadConsistency(iota)
+
+	// Quorum requires linearizable reads, which see all the writes done
+	// before them, e.g. for the checks a reparent makes under its lock.
+	Quorum
+
+	// Relaxed tolerates slightly stale reads, like those of a UI, a
+	// validator or a bulk scan. The topo implementations which support it
+	// may then serve them from any member of the topology server, to spare
+	// its leader (see --topo_etcd_serializable_reads).
+	Relaxed
+)
+
+// readConsistencyKeyType is the type of the context key of WithConsistency.
+type readConsistencyKeyType int
+
+var readConsistencyKey readConsistencyKeyType
+
+// WithConsistency returns a context requiring the given consistency for the
+// reads made with it, overriding that of ctx. It lets a critical path force
+// linearizable reads even when its caller tolerates stale ones.
+func WithConsistency(ctx context.Context, consistency ReadConsistency) context.Context {
+	return context.WithValue(ctx, readConsistencyKey, consistency)
+}
+
+// ConsistencyOf returns the consistency required for the reads made with
+// ctx.
+func ConsistencyOf(ctx context.Context) ReadConsistency {
+	consistency, _ := ctx.Value(readConsistencyKey).(ReadConsistency)
+	return consistency
+}
+
+// RequestMetadata describes the Vitess operation the requests made with a
+// context are made for. The topo implementations which support it pass it
+// along their requests to the topology server, as etcd gRPC metadata or
+// consul HTTP headers, so that its audit logs can be correlated with the
+// Vitess operations. The gRPC servers of the components set it for the
+// requests they serve, and the locks set their action.
+type RequestMetadata struct {
+	// Component is the Vitess component making the requests. It defaults to
+	// --topo_acl_identity, or else the name of the binary.
+	Component string
+	// Action is the Vitess operation, e.g. PlannedReparentShard.
+	Action string
+	// RequestID identifies the request of the caller the operation serves.
+	RequestID string
+}
+
+// The keys of the RequestMetadata passed to the topology server.
+const (
+	RequestMetadataComponentKey = "x-vitess-component"
+	RequestMetadataActionKey    = "x-vitess-action"
+	RequestMetadataRequestIDKey = "x-vitess-request-id"
+)
+
+// Headers returns the non-empty fields of the metadata by key.
+func (md RequestMetadata) Headers() map[string]string {
+	headers := make(map[string]string, 3)
+	for key, value := range map[string]string{
+		RequestMetadataComponentKey: md.Component,
+		RequestMetadataActionKey:    md.Action,
+		RequestMetadataRequestIDKey: md.RequestID,
+	} {
+		if value != "" {
+			headers[key] = value
+		}
+	}
+	return headers
+}
+
+// requestMetadataKeyType is the type of the context key of
+// WithRequestMetadata.
+type requestMetadataKeyType int
+
+var requestMetadataKey requestMetadataKeyType
+
+// WithRequestMetadata returns a context whose requests carry md. Its empty
+// fields keep the values of ctx.
+func WithRequestMetadata(ctx context.Context, md RequestMetadata) context.Context {
+	parent, _ := ctx.Value(requestMetadataKey).(RequestMetadata)
+	if md.Component == "" {
+		md.Component = parent.Component
+	}
+	if md.Action == "" {
+		md.Action = parent.Action
+	}
+	if md.RequestID == "" {
+		md.RequestID = parent.RequestID
+	}
+	return context.WithValue(ctx, requestMetadataKey, md)
+}
+
+// RequestMetadataOf returns the metadata of the requests made with ctx.
+func RequestMetadataOf(ctx context.Context) RequestMetadata {
+	md, _ := ctx.Value(requestMetadataKey).(RequestMetadata)
+	if md.Component == "" {
+		md.Component = processIdentity()
+	}
+	return md
+}
@@ -39,7 +39,7 @@ func (s *Server) ListDir(ctx context.Context, dirPath string, full bool) ([]topo
 		isRoot = true
 	}
 
-	keys, _, err := s.kv.Keys(nodePath, "", nil)
+	keys, _, err := s.kv.Keys(nodePath, "", queryOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -19,6 +19,8 @@ package consultopo
 import (
 	"context"
 	"path"
+	"sync"
+	"time"
 
 	"github.com/hashicorp/consul/api"
 
@@ -28,10 +30,20 @@ import (
 
 // NewLeaderParticipation is part of the topo.Server interface
 func (s *Server) NewLeaderParticipation(name, id string) (topo.LeaderParticipation, error) {
+	return s.NewLeaderParticipationWithTTL(name, id, 0)
+}
+
+// NewLeaderParticipationWithTTL is part of the topo.LeaderParticipationTTLConn
+// interface. Consul sessions live between 10s and 24h.
+func (s *Server) NewLeaderParticipationWithTTL(name, id string, ttl time.Duration) (topo.LeaderParticipation, error) {
+	if err := checkSessionTTL(ttl); err != nil {
+		return nil, err
+	}
 	return &consulLeaderParticipation{
 		s:    s,
 		name: name,
 		id:   id,
+		ttl:  ttl,
 		stop: make(chan struct{}),
 		done: make(chan struct{}),
 	}, nil
@@ -51,28 +63,45 @@ type consulLeaderParticipation struct {
 	// id is the process's current id.
 	id string
 
+	// ttl is the TTL of the session of the lock, or 0 for the default
+	// of the consul API.
+	ttl time.Duration
+
 	// stop is a channel closed when Stop is called.
 	stop chan struct{}
 
 	// done is a channel closed when we're done processing the Stop
 	done chan struct{}
+
+	// wg tracks the goroutines of the calls of WaitForLeadership, which
+	// release the lock when Stop is called.
+	wg sync.WaitGroup
 }
 
 // WaitForLeadership is part of the topo.LeaderParticipation interface.
 func (mp *consulLeaderParticipation) WaitForLeadership() (context.Context, error) {
 
 	electionPath := path.Join(mp.s.root, electionsPath, mp.name)
-	l, err := mp.s.client.LockOpts(&api.LockOptions{
-		Key:   electionPath,
-		Value: []byte(mp.id),
-	})
+	lockOpts := &api.LockOptions{
+		Key:       electionPath,
+		Value:     []byte(mp.id),
+		Namespace: mp.s.namespace,
+	}
+	if mp.ttl > 0 {
+		lockOpts.SessionTTL = mp.ttl.String()
+	}
+	client, err := mp.s.lockClient(topo.WithRequestMetadata(context.Background(), topo.RequestMetadata{Action: "WaitForLeadership"}))
+	if err != nil {
+		return nil, err
+	}
+	l, err := client.LockOpts(lockOpts)
 	if err != nil {
 		return nil, err
 	}
 
-	// If Stop was already called, mp.done is closed, so we are interrupted.
+	// If Stop was already called, mp.stop is closed, so we are interrupted.
 	select {
-	case <-mp.done:
+	case <-mp.stop:
 		return nil, topo.NewError(topo.Interrupted, "Leadership")
 	default:
 	}
@@ -80,18 +109,14 @@ func (mp *consulLeaderParticipation) WaitForLeadership() (context.Context, error
 	// Try to lock until mp.stop is closed.
 	lost, err := l.Lock(mp.stop)
 	if err != nil {
-		// We can't lock. See if it was because we got canceled.
-		select {
-		case <-mp.stop:
-			close(mp.done)
-		default:
-		}
 		return nil, err
 	}
 
 	// We have the lock, keep primaryship until we lose it.
 	lockCtx, lockCancel := context.WithCancel(context.Background())
+	mp.wg.Add(1)
 	go func() {
+		defer mp.wg.Done()
 		select {
 		case <-lost:
 			lockCancel()
@@ -107,7 +132,6 @@ func (mp *consulLeaderParticipation) WaitForLeadership() (context.Context, error
 			if err := l.Unlock(); err != nil {
 				log.Errorf("Leader election(%v) Unlock failed: %v", mp.name, err)
 			}
-			close(mp.done)
 		}
 	}()
 
@@ -117,13 +141,16 @@ func (mp *consulLeaderParticipation) WaitForLeadership() (context.Context, error
 // Stop is part of the topo.LeaderParticipation interface
 func (mp *consulLeaderParticipation) Stop() {
 	close(mp.stop)
-	<-mp.done
+	// The lock may have been lost already, in which case there is nothing
+	// left to release.
+	mp.wg.Wait()
+	close(mp.done)
 }
 
 // GetCurrentLeaderID is part of the topo.LeaderParticipation interface
 func (mp *consulLeaderParticipation) GetCurrentLeaderID(ctx context.Context) (string, error) {
 	electionPath := path.Join(mp.s.root, electionsPath, mp.name)
-	pair, _, err := mp.s.kv.Get(electionPath, nil)
+	pair, _, err := mp.s.kv.Get(electionPath, queryOptions(ctx))
 	if err != nil {
 		return "", err
 	}
@@ -140,3 +167,22 @@ func (mp *consulLeaderParticipation) WaitForNewLeader(context.Context) (<-chan s
 	// See also how WatchRecursive could be implemented as well.
 	return nil, topo.NewError(topo.NoImplementation, "wait for leader not supported in Consul topo")
 }
+
+// ForceResign is part of the topo.ForceResignLeaderParticipation interface.
+// It destroys the session of the lock of the primary, which makes it lose
+// the lock.
+func (mp *consulLeaderParticipation) ForceResign(ctx context.Context, id string) error {
+	electionPath := path.Join(mp.s.root, electionsPath, mp.name)
+	pair, _, err := mp.s.kv.Get(electionPath, queryOptions(ctx))
+	if err != nil {
+		return err
+	}
+	if pair == nil || pair.Session == "" {
+		return topo.NewError(topo.NoNode, electionPath)
+	}
+	if string(pair.Value) != id {
+		return topo.NewError(topo.BadVersion, electionPath)
+	}
+	_, err = mp.s.client.Session().Destroy(pair.Session, writeOptions(ctx))
+	return err
+}
@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"path"
+	"time"
 
 	"github.com/hashicorp/consul/api"
 
@@ -49,7 +50,7 @@ func (s *Server) Lock(ctx context.Context, dirPath, contents string) (topo.LockD
 		return nil, convertError(err, dirPath)
 	}
 
-	return s.lock(ctx, dirPath, contents)
+	return s.lock(ctx, dirPath, contents, 0)
 }
 
 // TryLock is part of the topo.Conn interface.
@@ -74,19 +75,46 @@ func (s *Server) TryLock(ctx context.Context, dirPath, contents string) (topo.Lo
 	}
 
 	// everything is good let's acquire the lock.
-	return s.lock(ctx, dirPath, contents)
+	return s.lock(ctx, dirPath, contents, 0)
 }
 
-// Lock is part of the topo.Conn interface.
-func (s *Server) lock(ctx context.Context, dirPath, contents string) (topo.LockDescriptor, error) {
+// LockNameWithTTL is part of the topo.NamedLockConn interface.
+// The lock is held by a session whose TTL is ttl.
+func (s *Server) LockNameWithTTL(ctx context.Context, dirPath, contents string, ttl time.Duration) (topo.LockDescriptor, error) {
+	if err := checkSessionTTL(ttl); err != nil {
+		return nil, err
+	}
+	return s.lock(ctx, dirPath, contents, ttl)
+}
+
+// The consul sessions live between minSessionTTL and maxSessionTTL.
+const (
+	minSessionTTL = 10 * time.Second
+	maxSessionTTL = 24 * time.Hour
+)
+
+// checkSessionTTL returns ErrNoImplementation if ttl is neither 0, for the
+// default, nor a TTL the consul sessions support.
+func checkSessionTTL(ttl time.Duration) error {
+	if ttl != 0 && (ttl < minSessionTTL || ttl > maxSessionTTL) {
+		return topo.NewError(topo.NoImplementation, fmt.Sprintf("consul sessions with a TTL of %v, out of [%v, %v]", ttl, minSessionTTL, maxSessionTTL))
+	}
+	return nil
+}
+
+// lock is used by Lock, TryLock and LockNameWithTTL. ttl is the TTL of the
+// session of the lock, or 0 for --topo_consul_lock_session_ttl.
+func (s *Server) lock(ctx context.Context, dirPath, contents string, ttl time.Duration) (topo.LockDescriptor, error) {
 	lockPath := path.Join(s.root, dirPath, locksFilename)
 
 	lockOpts := &api.LockOptions{
-		Key:   lockPath,
-		Value: []byte(contents),
+		Key:       lockPath,
+		Value:     []byte(contents),
+		Namespace: s.namespace,
 		SessionOpts: &api.SessionEntry{
-			Name: api.DefaultLockSessionName,
-			TTL:  api.DefaultLockSessionTTL,
+			Name:      api.DefaultLockSessionName,
+			TTL:       api.DefaultLockSessionTTL,
+			Namespace: s.namespace,
 		},
 	}
 	lockOpts.SessionOpts.Checks = s.lockChecks
@@ -96,8 +124,15 @@ func (s *Server) lock(ctx context.Context, dirPath, contents string) (topo.LockD
 	if s.lockTTL != "" {
 		lockOpts.SessionOpts.TTL = s.lockTTL
 	}
+	if ttl > 0 {
+		lockOpts.SessionOpts.TTL = ttl.String()
+	}
 	// Build the lock structure.
-	l, err := s.client.LockOpts(lockOpts)
+	client, err := s.lockClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	l, err := client.LockOpts(lockOpts)
 	if err != nil {
 		return nil, err
 	}
@@ -151,6 +186,57 @@ func (s *Server) lock(ctx context.Context, dirPath, contents string) (topo.LockD
 	}, nil
 }
 
+// GetLock is part of the topo.LockHolderConn interface.
+// A lock file is only held while it is acquired by a session, whose TTL is
+// the one of the lock.
+func (s *Server) GetLock(ctx context.Context, dirPath string) (*topo.LockInfo, error) {
+	lockPath := path.Join(s.root, dirPath, locksFilename)
+	pair, _, err := s.kv.Get(lockPath, queryOptions(ctx))
+	if err != nil {
+		return nil, err
+	}
+	if pair == nil || pair.Session == "" {
+		return nil, topo.NewError(topo.NoNode, dirPath)
+	}
+
+	info := &topo.LockInfo{Contents: string(pair.Value)}
+	session, _, err := s.client.Session().Info(pair.Session, queryOptions(ctx))
+	if err != nil {
+		return nil, err
+	}
+	if session == nil {
+		// The session of the holder was invalidated since we read the
+		// lock, so its process is gone.
+		info.Stale = true
+		return info, nil
+	}
+	if session.TTL != "" {
+		if info.TTL, err = time.ParseDuration(session.TTL); err != nil {
+			return nil, vterrors.Wrapf(err, "bad TTL for session %v", pair.Session)
+		}
+	}
+	return info, nil
+}
+
+// ForceUnlock is part of the topo.LockHolderConn interface.
+// It destroys the session of the holder, which releases the lock and makes
+// its Check fail.
+func (s *Server) ForceUnlock(ctx context.Context, dirPath, contents string) error {
+	lockPath := path.Join(s.root, dirPath, locksFilename)
+	pair, _, err := s.kv.Get(lockPath, queryOptions(ctx))
+	if err != nil {
+		return err
+	}
+	if pair == nil || pair.Session == "" {
+		return topo.NewError(topo.NoNode, dirPath)
+	}
+	if string(pair.Value) != contents {
+		return topo.NewError(topo.BadVersion, dirPath)
+	}
+	_, err = s.client.Session().Destroy(pair.Session, writeOptions(ctx))
+	return err
+}
+
 // Check is part of the topo.LockDescriptor interface.
 func (ld *consulLockDescriptor) Check(ctx context.Context) error {
 	select {
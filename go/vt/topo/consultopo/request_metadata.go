@@ -0,0 +1,76 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package consultopo
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/hashicorp/consul/api"
+
+	"vitess.io/vitess/go/vt/topo"
+)
+
+// requestMetadataTransport sets the topo.RequestMetadata of the context of
+// the requests as HTTP headers, so that the consul audit logs can be
+// correlated with the Vitess operations.
+type requestMetadataTransport struct {
+	http.RoundTripper
+
+	// metadata, if set, is the metadata of all the requests instead, for
+	// the clients making their requests without a context.
+	metadata *topo.RequestMetadata
+}
+
+// RoundTrip is part of the http.RoundTripper interface.
+func (t *requestMetadataTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	md := t.metadata
+	if md == nil {
+		ctxMetadata := topo.RequestMetadataOf(req.Context())
+		md = &ctxMetadata
+	}
+	headers := md.Headers()
+	if len(headers) == 0 {
+		return t.RoundTripper.RoundTrip(req)
+	}
+	// A RoundTripper must not modify the request it is given.
+	req = req.Clone(req.Context())
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	return t.RoundTripper.RoundTrip(req)
+}
+
+// lockClient returns a client for the locks taken with ctx. The consul locks
+// make their requests without a context, e.g. to renew their session, so the
+// client sets the topo.RequestMetadata of ctx on all of them.
+func (s *Server) lockClient(ctx context.Context) (*api.Client, error) {
+	md := topo.RequestMetadataOf(ctx)
+	cfg := s.config
+	cfg.HttpClient = &http.Client{Transport: &requestMetadataTransport{RoundTripper: s.transport, metadata: &md}}
+	return api.NewClient(&cfg)
+}
+
+// queryOptions returns the options of the read requests made with ctx.
+func queryOptions(ctx context.Context) *api.QueryOptions {
+	return (&api.QueryOptions{}).WithContext(ctx)
+}
+
+// writeOptions returns the options of the write requests made with ctx.
+func writeOptions(ctx context.Context) *api.WriteOptions {
+	return (&api.WriteOptions{}).WithContext(ctx)
+}
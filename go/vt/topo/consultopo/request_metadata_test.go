@@ -0,0 +1,126 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package consultopo
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/topo"
+)
+
+func TestRequestMetadataTransport(t *testing.T) {
+	var headers http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = r.Header.Clone()
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: &requestMetadataTransport{RoundTripper: http.DefaultTransport}}
+	ctx := topo.WithRequestMetadata(context.Background(), topo.RequestMetadata{Component: "vtctld", Action: "Reshard", RequestID: "42"})
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, "vtctld", headers.Get(topo.RequestMetadataComponentKey))
+	assert.Equal(t, "Reshard", headers.Get(topo.RequestMetadataActionKey))
+	assert.Equal(t, "42", headers.Get(topo.RequestMetadataRequestIDKey))
+	// The request of the caller is left untouched.
+	assert.Empty(t, req.Header.Get(topo.RequestMetadataComponentKey))
+}
+
+// fakeConsul is a consul HTTP API recording the metadata of the requests.
+type fakeConsul struct {
+	mu      sync.Mutex
+	actions map[string]string
+}
+
+func (f *fakeConsul) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	f.actions[r.URL.Path] = r.Header.Get(topo.RequestMetadataActionKey)
+	f.mu.Unlock()
+
+	switch {
+	case r.URL.Path == "/v1/session/create":
+		w.Write([]byte(`{"ID":"session1"}`))
+	case r.URL.Query().Get("index") != "":
+		// Block the watches until they are canceled.
+		<-r.Context().Done()
+	case r.URL.Path == "/v1/kv/root/file":
+		w.Header().Set("X-Consul-Index", "1")
+		w.Write([]byte(`[{"Key":"root/file","Value":"dGVzdA==","ModifyIndex":1}]`))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (f *fakeConsul) action(path string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.actions[path]
+}
+
+func TestRequestMetadataUnixSocket(t *testing.T) {
+	fake := &fakeConsul{actions: make(map[string]string)}
+	server := httptest.NewUnstartedServer(fake)
+	socketPath := path.Join(t.TempDir(), "consul.sock")
+	listener, err := net.Listen("unix", socketPath)
+	require.NoError(t, err)
+	server.Listener = listener
+	server.Start()
+	defer server.Close()
+
+	s, err := NewServer("zone1", "unix://"+socketPath, "root")
+	require.NoError(t, err)
+	defer s.Close()
+
+	ctx, cancel := context.WithCancel(topo.WithRequestMetadata(context.Background(), topo.RequestMetadata{Action: "Reshard"}))
+	defer cancel()
+
+	// The requests made with a context.
+	_, _, err = s.Get(ctx, "missing")
+	require.True(t, topo.IsErrType(err, topo.NoNode), "%v", err)
+	assert.Equal(t, "Reshard", fake.action("/v1/kv/root/missing"))
+
+	// The watches.
+	wd, changes, err := s.Watch(topo.WithRequestMetadata(ctx, topo.RequestMetadata{Action: "WatchFile"}), "file")
+	require.NoError(t, err)
+	assert.Equal(t, "test", string(wd.Contents))
+	assert.Equal(t, "WatchFile", fake.action("/v1/kv/root/file"))
+
+	// The locks, whose requests have no context.
+	client, err := s.lockClient(topo.WithRequestMetadata(ctx, topo.RequestMetadata{Action: "LockShard"}))
+	require.NoError(t, err)
+	id, _, err := client.Session().Create(nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "session1", id)
+	assert.Equal(t, "LockShard", fake.action("/v1/session/create"))
+
+	cancel()
+	for range changes {
+	}
+}
@@ -20,7 +20,12 @@ Package consultopo implements topo.Server with consul as the backend.
 package consultopo
 
 import (
+	"context"
+	"crypto/tls"
 	"encoding/json"
+	"net"
+	"net/http"
+	"net/url"
 	"os"
 	"strings"
 	"sync"
@@ -30,9 +35,11 @@ import (
 	"github.com/spf13/pflag"
 
 	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/proto/vtrpc"
 	"vitess.io/vitess/go/vt/servenv"
 	"vitess.io/vitess/go/vt/topo"
 	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vttls"
 )
 
 var (
@@ -41,6 +48,14 @@ var (
 	consulLockSessionChecks = "serfHealth"
 	consulLockSessionTTL    string
 	consulLockDelay         = 15 * time.Second
+
+	consulTLSCertPath string
+	consulTLSKeyPath  string
+	consulTLSCaPath   string
+	consulTLSWatch    bool
+
+	consulNamespace string
+	consulPartition string
 )
 
 func init() {
@@ -52,6 +67,12 @@ func registerServerFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&consulLockSessionChecks, "topo_consul_lock_session_checks", consulLockSessionChecks, "List of checks for consul session.")
 	fs.StringVar(&consulLockSessionTTL, "topo_consul_lock_session_ttl", consulLockSessionTTL, "TTL for consul session.")
 	fs.DurationVar(&consulLockDelay, "topo_consul_lock_delay", consulLockDelay, "LockDelay for consul session.")
+	fs.StringVar(&consulTLSCertPath, "topo_consul_tls_cert", consulTLSCertPath, "path to the client cert to use to connect to the consul topo server, requires topo_consul_tls_key, enables TLS")
+	fs.StringVar(&consulTLSKeyPath, "topo_consul_tls_key", consulTLSKeyPath, "path to the client key to use to connect to the consul topo server, enables TLS")
+	fs.StringVar(&consulTLSCaPath, "topo_consul_tls_ca", consulTLSCaPath, "path to the ca to use to validate the server cert when connecting to the consul topo server")
+	fs.StringVar(&consulNamespace, "topo_consul_namespace", consulNamespace, "Consul Enterprise namespace to store topo data, sessions and locks in. Can be overridden per cell with a namespace query parameter on the server address.")
+	fs.StringVar(&consulPartition, "topo_consul_partition", consulPartition, "Consul Enterprise admin partition to store topo data, sessions and locks in. Can be overridden per cell with a partition query parameter on the server address.")
+	fs.BoolVar(&consulTLSWatch, "topo_consul_tls_watch", consulTLSWatch, "watch the consul topo TLS cert, key and ca files and reload them when they change")
 }
 
 // ClientAuthCred credential to use for consul clusters
@@ -101,9 +122,19 @@ type Server struct {
 	client *api.Client
 	kv     *api.KV
 
+	// config is the configuration of client, and transport the transport
+	// of its requests before the metadata is set, for lockClient.
+	config    api.Config
+	transport http.RoundTripper
+
 	// root is the root path for this client.
 	root string
 
+	// namespace and partition are the Consul Enterprise namespace and admin
+	// partition used by this client. Empty means the server defaults.
+	namespace string
+	partition string
+
 	// mu protects the following fields.
 	mu sync.Mutex
 	// locks is a map of *lockInstance structures.
@@ -113,6 +144,13 @@ type Server struct {
 	lockChecks []string
 	lockTTL    string
 	lockDelay  time.Duration
+
+	// certReloader is set when the TLS files are watched for changes.
+	certReloader *vttls.CertReloader
+
+	// clientCert is the client certificate presented to consul, when the
+	// TLS files are not watched.
+	clientCert *tls.Certificate
 }
 
 // lockInstance keeps track of one lock held by this client.
@@ -130,8 +168,14 @@ func NewServer(cell, serverAddr, root string) (*Server, error) {
 	if err != nil {
 		return nil, err
 	}
+	addr, namespace, partition, err := parseServerAddr(serverAddr)
+	if err != nil {
+		return nil, err
+	}
 	cfg := api.DefaultConfig()
-	cfg.Address = serverAddr
+	cfg.Address = addr
+	cfg.Namespace = namespace
+	cfg.Partition = partition
 	if creds != nil {
 		if creds[cell] != nil {
 			cfg.Token = creds[cell].ACLToken
@@ -140,22 +184,127 @@ func NewServer(cell, serverAddr, root string) (*Server, error) {
 		}
 	}
 
+	var (
+		certReloader *vttls.CertReloader
+		clientCert   *tls.Certificate
+	)
+	if consulTLSCertPath != "" && consulTLSKeyPath != "" {
+		cfg.Scheme = "https"
+		if consulTLSWatch {
+			certReloader, err = vttls.NewCertReloader(consulTLSCertPath, consulTLSKeyPath, consulTLSCaPath, true)
+			if err != nil {
+				return nil, err
+			}
+			cfg.Transport.TLSClientConfig = certReloader.ClientConfig()
+		} else {
+			cfg.TLSConfig.CertFile = consulTLSCertPath
+			cfg.TLSConfig.KeyFile = consulTLSKeyPath
+			cfg.TLSConfig.CAFile = consulTLSCaPath
+			// The consul client loads the files itself, so they are only
+			// loaded here to know the identity they present.
+			if cert, err := tls.LoadX509KeyPair(consulTLSCertPath, consulTLSKeyPath); err == nil {
+				clientCert = &cert
+			}
+		}
+	}
+
+	// The consul client replaces the HTTP client of the unix sockets, and
+	// with it the transport setting the metadata, so the transport dials
+	// them itself.
+	if socketPath, ok := strings.CutPrefix(cfg.Address, "unix://"); ok {
+		cfg.Transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", socketPath)
+		}
+		cfg.Address = socketPath
+	}
+
+	// The requests carry the topo.RequestMetadata of their context.
+	httpClient, err := api.NewHttpClient(cfg.Transport, cfg.TLSConfig)
+	if err != nil {
+		if certReloader != nil {
+			certReloader.Close()
+		}
+		return nil, err
+	}
+	transport := httpClient.Transport
+	httpClient.Transport = &requestMetadataTransport{RoundTripper: transport}
+	cfg.HttpClient = httpClient
+
 	client, err := api.NewClient(cfg)
 	if err != nil {
+		if certReloader != nil {
+			certReloader.Close()
+		}
 		return nil, err
 	}
 
 	return &Server{
-		client:     client,
-		kv:         client.KV(),
-		root:       root,
-		locks:      make(map[string]*lockInstance),
-		lockChecks: parseConsulLockSessionChecks(consulLockSessionChecks),
-		lockTTL:    consulLockSessionTTL,
-		lockDelay:  consulLockDelay,
+		client:       client,
+		kv:           client.KV(),
+		config:       *cfg,
+		transport:    transport,
+		root:         root,
+		namespace:    namespace,
+		partition:    partition,
+		locks:        make(map[string]*lockInstance),
+		lockChecks:   parseConsulLockSessionChecks(consulLockSessionChecks),
+		lockTTL:      consulLockSessionTTL,
+		lockDelay:    consulLockDelay,
+		certReloader: certReloader,
+		clientCert:   clientCert,
 	}, nil
 }
 
+// ClientIdentity is part of the topo.ClientIdentifier interface. It returns
+// the identity of the client certificate presented to consul, the current
+// one if the TLS files are watched.
+func (s *Server) ClientIdentity() string {
+	if s.certReloader != nil {
+		return topo.CertIdentity(s.certReloader.Certificate())
+	}
+	return topo.CertIdentity(s.clientCert)
+}
+
+// OnClientIdentityChange is part of the topo.ClientIdentityNotifier
+// interface. The identity only changes when the watched TLS files are
+// reloaded.
+func (s *Server) OnClientIdentityChange(f func()) {
+	if s.certReloader != nil {
+		s.certReloader.OnReload(f)
+	}
+}
+
+// parseServerAddr splits an optional query string off the server address. The
+// namespace and partition query parameters select the Consul Enterprise
+// namespace and admin partition for the cell, taking precedence over the
+// --topo_consul_namespace and --topo_consul_partition flags. For example:
+//
+//	consul.example.com:8500?namespace=vitess&partition=team-a
+func parseServerAddr(serverAddr string) (addr, namespace, partition string, err error) {
+	addr, query, _ := strings.Cut(serverAddr, "?")
+	namespace, partition = consulNamespace, consulPartition
+	if query == "" {
+		return addr, namespace, partition, nil
+	}
+
+	params, err := url.ParseQuery(query)
+	if err != nil {
+		return "", "", "", vterrors.Wrapf(err, "invalid consul server address %v", serverAddr)
+	}
+	for key := range params {
+		switch key {
+		case "namespace", "ns":
+			namespace = params.Get(key)
+		case "partition":
+			partition = params.Get(key)
+		default:
+			return "", "", "", vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "unknown parameter %v in consul server address %v", key, serverAddr)
+		}
+	}
+	return addr, namespace, partition, nil
+}
+
 func parseConsulLockSessionChecks(s string) []string {
 	var res []string
 	if len(s) == 0 {
@@ -168,6 +317,9 @@ func parseConsulLockSessionChecks(s string) []string {
 // It will nil out the global and cells fields, so any attempt to
 // re-use this server will panic.
 func (s *Server) Close() {
+	if s.certReloader != nil {
+		s.certReloader.Close()
+	}
 	s.client = nil
 	s.kv = nil
 	s.mu.Lock()
@@ -0,0 +1,79 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package consultopo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseServerAddr(t *testing.T) {
+	defer func(ns, partition string) {
+		consulNamespace, consulPartition = ns, partition
+	}(consulNamespace, consulPartition)
+	consulNamespace, consulPartition = "flag-ns", "flag-partition"
+
+	tests := []struct {
+		in        string
+		addr      string
+		namespace string
+		partition string
+		wantErr   bool
+	}{
+		{
+			in:        "localhost:8500",
+			addr:      "localhost:8500",
+			namespace: "flag-ns",
+			partition: "flag-partition",
+		},
+		{
+			in:        "localhost:8500?namespace=vitess",
+			addr:      "localhost:8500",
+			namespace: "vitess",
+			partition: "flag-partition",
+		},
+		{
+			in:        "https://localhost:8500?ns=vitess&partition=team-a",
+			addr:      "https://localhost:8500",
+			namespace: "vitess",
+			partition: "team-a",
+		},
+		{
+			in:      "localhost:8500?datacenter=dc1",
+			wantErr: true,
+		},
+		{
+			in:      "localhost:8500?namespace=%zz",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			addr, namespace, partition, err := parseServerAddr(tt.in)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.addr, addr)
+			assert.Equal(t, tt.namespace, namespace)
+			assert.Equal(t, tt.partition, partition)
+		})
+	}
+}
@@ -21,7 +21,6 @@ import (
 	"path"
 	"time"
 
-	"github.com/hashicorp/consul/api"
 	"github.com/spf13/pflag"
 
 	"vitess.io/vitess/go/vt/servenv"
@@ -44,12 +43,11 @@ func registerWatchFlags(fs *pflag.FlagSet) {
 func (s *Server) Watch(ctx context.Context, filePath string) (*topo.WatchData, <-chan *topo.WatchData, error) {
 	// Initial get.
 	nodePath := path.Join(s.root, filePath)
-	options := &api.QueryOptions{}
 
 	initialCtx, initialCancel := context.WithTimeout(ctx, topo.RemoteOperationTimeout)
 	defer initialCancel()
 
-	pair, _, err := s.kv.Get(nodePath, options.WithContext(initialCtx))
+	pair, _, err := s.kv.Get(nodePath, queryOptions(initialCtx))
 	if err != nil {
 		return nil, nil, err
 	}
@@ -82,20 +80,19 @@ func (s *Server) Watch(ctx context.Context, filePath string) (*topo.WatchData, <
 			// if it didn't change. So we just check for that
 			// and swallow the notifications when version matches.
 			waitIndex := pair.ModifyIndex
-			opts := &api.QueryOptions{
-				WaitIndex: waitIndex,
-				WaitTime:  watchPollDuration,
-			}
 
 			// Make a new Context for just this one Get() call.
 			// The server should send us something after WaitTime at the latest.
 			// If it takes more than 2x that long, assume we've lost contact.
 			// This essentially uses WaitTime as a heartbeat interval to detect
-			// a dead connection.
+			// a dead connection. It carries the topo.RequestMetadata of ctx.
 			cancelGetCtx()
-			getCtx, cancelGetCtx = context.WithTimeout(ctx, 2*opts.WaitTime)
+			getCtx, cancelGetCtx = context.WithTimeout(ctx, 2*watchPollDuration)
+			opts := queryOptions(getCtx)
+			opts.WaitIndex = waitIndex
+			opts.WaitTime = watchPollDuration
 
-			pair, _, err = s.kv.Get(nodePath, opts.WithContext(getCtx))
+			pair, _, err = s.kv.Get(nodePath, opts)
 			if err != nil {
 				// Serious error or context timeout/cancelled.
 				notifications <- &topo.WatchData{
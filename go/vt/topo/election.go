@@ -0,0 +1,198 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topo
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"time"
+
+	"vitess.io/vitess/go/protoutil"
+	"vitess.io/vitess/go/vt/log"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+// This file contains the utility methods to manage the leader elections of
+// the global cell. The topology servers keep the elections in the
+// ElectionsPath directory. The primaries of the elections started with
+// Server.NewLeaderParticipation record when they were elected in the
+// ElectionLeadersPath directory, which is kept apart so that the topology
+// servers never mistake the records for participants.
+
+// NewLeaderParticipation creates a LeaderParticipation in the election name
+// of the global cell, see Conn.NewLeaderParticipation. When the participant
+// is elected, it records it in the ElectionLeadersPath directory, for
+// GetElections to report when the primary was elected.
+func (ts *Server) NewLeaderParticipation(name, id string) (LeaderParticipation, error) {
+	return ts.NewLeaderParticipationWithTTL(name, id, 0)
+}
+
+// NewLeaderParticipationWithTTL is like NewLeaderParticipation, with the
+// primaryship backed by a lease or session of the given TTL, see
+// LeaderParticipationTTLConn. If ttl is 0, the default of the topology server
+// is used. Returns ErrNoImplementation if the topology server doesn't support
+// a TTL.
+func (ts *Server) NewLeaderParticipationWithTTL(name, id string, ttl time.Duration) (LeaderParticipation, error) {
+	mp, err := newLeaderParticipationWithTTL(ts.globalCell, name, id, ttl)
+	if err != nil {
+		return nil, err
+	}
+	return &recordingLeaderParticipation{
+		LeaderParticipation: mp,
+		ts:                  ts,
+		name:                name,
+		id:                  id,
+	}, nil
+}
+
+// newLeaderParticipationWithTTL creates a LeaderParticipation on conn with
+// the given TTL, if conn implements LeaderParticipationTTLConn. The Conns
+// wrapping another Conn use it to forward the TTL.
+func newLeaderParticipationWithTTL(conn Conn, name, id string, ttl time.Duration) (LeaderParticipation, error) {
+	if ttl == 0 {
+		return conn.NewLeaderParticipation(name, id)
+	}
+	tc, ok := conn.(LeaderParticipationTTLConn)
+	if !ok {
+		return nil, NewError(NoImplementation, fmt.Sprintf("leader elections with a TTL of %v", ttl))
+	}
+	return tc.NewLeaderParticipationWithTTL(name, id, ttl)
+}
+
+// recordingLeaderParticipation is a LeaderParticipation that records in the
+// global cell when it is elected.
+type recordingLeaderParticipation struct {
+	LeaderParticipation
+	ts       *Server
+	name, id string
+}
+
+// WaitForLeadership is part of the LeaderParticipation interface.
+func (mp *recordingLeaderParticipation) WaitForLeadership() (context.Context, error) {
+	ctx, err := mp.LeaderParticipation.WaitForLeadership()
+	if err != nil {
+		return nil, err
+	}
+
+	// The record is only informational: failing to write it must not cost
+	// us the primaryship.
+	election := &topodatapb.Election{
+		Name:        mp.name,
+		LeaderId:    mp.id,
+		LeaderSince: protoutil.TimeToProto(time.Now()),
+	}
+	data, err := election.MarshalVT()
+	if err == nil {
+		shortCtx, cancel := context.WithTimeout(ctx, RemoteOperationTimeout)
+		_, err = mp.ts.globalCell.Update(shortCtx, path.Join(ElectionLeadersPath, mp.name), data, nil)
+		cancel()
+	}
+	if err != nil {
+		log.Warningf("Failed to record the primaryship of %v in election %v: %v", mp.id, mp.name, err)
+	}
+	return ctx, nil
+}
+
+// ForceResign is part of the ForceResignLeaderParticipation interface.
+func (mp *recordingLeaderParticipation) ForceResign(ctx context.Context, id string) error {
+	return forceResign(ctx, mp.LeaderParticipation, id)
+}
+
+// GetElections returns the leader elections of the global cell, sorted by
+// name, with their current primaries. The time the primary was elected is
+// only known if it recorded it, see NewLeaderParticipation.
+func (ts *Server) GetElections(ctx context.Context) ([]*topodatapb.Election, error) {
+	entries, err := ts.globalCell.ListDir(ctx, ElectionsPath, false /*full*/)
+	if err != nil {
+		if IsErrType(err, NoNode) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	elections := make([]*topodatapb.Election, 0, len(entries))
+	for _, entry := range entries {
+		mp, err := ts.globalCell.NewLeaderParticipation(entry.Name, "")
+		if err != nil {
+			return nil, err
+		}
+		leaderID, err := mp.GetCurrentLeaderID(ctx)
+		if err != nil {
+			return nil, err
+		}
+		election := &topodatapb.Election{
+			Name:     entry.Name,
+			LeaderId: leaderID,
+		}
+
+		// The record may be the one of a former primary.
+		data, _, err := ts.globalCell.Get(ctx, path.Join(ElectionLeadersPath, entry.Name))
+		switch {
+		case err == nil:
+			record := &topodatapb.Election{}
+			if err := record.UnmarshalVT(data); err == nil && leaderID != "" && record.LeaderId == leaderID {
+				election.LeaderSince = record.LeaderSince
+			}
+		case !IsErrType(err, NoNode):
+			return nil, err
+		}
+		elections = append(elections, election)
+	}
+	sort.Slice(elections, func(i, j int) bool { return elections[i].Name < elections[j].Name })
+	return elections, nil
+}
+
+// ResignLeader forces the primary of the election name in the global cell to
+// resign, and returns its id. If id is set, the primary is only forced to
+// resign if it is id, so that a participant elected in the meantime is left
+// alone. See ForceResignLeaderParticipation.ForceResign. Returns
+// ErrNoImplementation if the topology server can't force a primary to
+// resign.
+func (ts *Server) ResignLeader(ctx context.Context, name, id string) (string, error) {
+	// The participation is only used to look at the election: it never runs
+	// for it, so it does not need to be stopped.
+	mp, err := ts.globalCell.NewLeaderParticipation(name, "")
+	if err != nil {
+		return "", err
+	}
+	if id == "" {
+		if id, err = mp.GetCurrentLeaderID(ctx); err != nil {
+			return "", err
+		}
+		if id == "" {
+			return "", NewError(NoNode, name)
+		}
+	}
+	if err := forceResign(ctx, mp, id); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+// forceResign forces the primary of the election of mp to resign, if mp
+// implements ForceResignLeaderParticipation. The LeaderParticipations
+// wrapping another LeaderParticipation use it to forward ForceResign.
+func forceResign(ctx context.Context, mp LeaderParticipation, id string) error {
+	rp, ok := mp.(ForceResignLeaderParticipation)
+	if !ok {
+		return NewError(NoImplementation, "forcing the primary of an election to resign")
+	}
+	return rp.ForceResign(ctx, id)
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topo_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/protoutil"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+)
+
+func TestGetElections(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "zone1")
+	defer ts.Close()
+
+	elections, err := ts.GetElections(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, elections)
+
+	// A primary elected through the topo.Server records when it was.
+	before := time.Now()
+	vtorc1, err := ts.NewLeaderParticipation("vtorc", "vtorc1:15000")
+	require.NoError(t, err)
+	_, err = vtorc1.WaitForLeadership()
+	require.NoError(t, err)
+	defer vtorc1.Stop()
+
+	// One elected through the Conn doesn't.
+	conn, err := ts.ConnForCell(ctx, topo.GlobalCell)
+	require.NoError(t, err)
+	other, err := conn.NewLeaderParticipation("other", "other1")
+	require.NoError(t, err)
+	_, err = other.WaitForLeadership()
+	require.NoError(t, err)
+
+	elections, err = ts.GetElections(ctx)
+	require.NoError(t, err)
+	require.Len(t, elections, 2)
+	assert.Equal(t, "other", elections[0].Name)
+	assert.Equal(t, "other1", elections[0].LeaderId)
+	assert.Nil(t, elections[0].LeaderSince)
+	assert.Equal(t, "vtorc", elections[1].Name)
+	assert.Equal(t, "vtorc1:15000", elections[1].LeaderId)
+	require.NotNil(t, elections[1].LeaderSince)
+	assert.False(t, protoutil.TimeFromProto(elections[1].LeaderSince).Before(before.Truncate(time.Second)))
+
+	// The record of a former primary isn't reported for the current one.
+	other.Stop()
+	vtorc2, err := conn.NewLeaderParticipation("vtorc", "vtorc2:15000")
+	require.NoError(t, err)
+	_, err = ts.ResignLeader(ctx, "vtorc", "vtorc1:15000")
+	require.NoError(t, err)
+	_, err = vtorc2.WaitForLeadership()
+	require.NoError(t, err)
+	defer vtorc2.Stop()
+
+	elections, err = ts.GetElections(ctx)
+	require.NoError(t, err)
+	require.Len(t, elections, 2)
+	assert.Equal(t, "", elections[0].LeaderId)
+	assert.Equal(t, "vtorc2:15000", elections[1].LeaderId)
+	assert.Nil(t, elections[1].LeaderSince)
+}
+
+func TestNewLeaderParticipationWithTTL(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "zone1")
+	defer ts.Close()
+
+	// memorytopo has no leases, so it only runs the elections without TTL.
+	mp, err := ts.NewLeaderParticipationWithTTL("vtorc", "vtorc1:15000", 0)
+	require.NoError(t, err)
+	_, err = mp.WaitForLeadership()
+	require.NoError(t, err)
+	mp.Stop()
+
+	_, err = ts.NewLeaderParticipationWithTTL("vtorc", "vtorc1:15000", 5*time.Second)
+	assert.True(t, topo.IsErrType(err, topo.NoImplementation), "%v", err)
+}
+
+func TestResignLeaderNoImplementation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts, factory := memorytopo.NewServerAndFactory(ctx, "zone1")
+	defer ts.Close()
+
+	// The participations wrapping those of memorytopo forward ForceResign.
+	mp, err := ts.NewLeaderParticipation("vtorc", "vtorc1:15000")
+	require.NoError(t, err)
+	_, ok := mp.(topo.ForceResignLeaderParticipation)
+	assert.True(t, ok)
+
+	plainTS, err := topo.NewWithFactory(&plainFactory{Factory: factory}, "", "")
+	require.NoError(t, err)
+	defer plainTS.Close()
+	_, err = plainTS.ResignLeader(ctx, "vtorc", "vtorc1:15000")
+	assert.True(t, topo.IsErrType(err, topo.NoImplementation), "%v", err)
+}
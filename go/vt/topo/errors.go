@@ -37,6 +37,8 @@ const (
 	NoImplementation
 	NoReadOnlyImplementation
 	ResourceExhausted
+	ValueTooLarge
+	QuotaExceeded
 )
 
 // Error represents a topo error.
@@ -71,6 +73,10 @@ func NewError(code ErrorCode, node string) error {
 		message = fmt.Sprintf("no read-only topology implementation %s", node)
 	case ResourceExhausted:
 		message = fmt.Sprintf("server resource exhausted: %s", node)
+	case ValueTooLarge:
+		message = fmt.Sprintf("value too large: %s", node)
+	case QuotaExceeded:
+		message = fmt.Sprintf("quota exceeded: %s", node)
 	default:
 		message = fmt.Sprintf("unknown code: %s", node)
 	}
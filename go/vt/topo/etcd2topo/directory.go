@@ -35,10 +35,10 @@ func (s *Server) ListDir(ctx context.Context, dirPath string, full bool) ([]topo
 		// we would end up with "//". in that case, we want "/".
 		nodePath = "/"
 	}
-	resp, err := s.cli.Get(ctx, nodePath,
+	resp, err := s.cli.Get(ctx, nodePath, readOptions(ctx,
 		clientv3.WithPrefix(),
 		clientv3.WithSort(clientv3.SortByKey, clientv3.SortAscend),
-		clientv3.WithKeysOnly())
+		clientv3.WithKeysOnly())...)
 	if err != nil {
 		return nil, convertError(err, dirPath)
 	}
@@ -19,6 +19,8 @@ package etcd2topo
 import (
 	"context"
 	"path"
+	"sync"
+	"time"
 
 	clientv3 "go.etcd.io/etcd/client/v3"
 
@@ -26,12 +28,23 @@ import (
 	"vitess.io/vitess/go/vt/topo"
 )
 
+// leadershipCheckInterval is how often the primary checks that it still holds
+// the lock of its primaryship.
+var leadershipCheckInterval = 5 * time.Second
+
 // NewLeaderParticipation is part of the topo.Server interface
 func (s *Server) NewLeaderParticipation(name, id string) (topo.LeaderParticipation, error) {
+	return s.NewLeaderParticipationWithTTL(name, id, 0)
+}
+
+// NewLeaderParticipationWithTTL is part of the topo.LeaderParticipationTTLConn
+// interface. ttl is rounded up to a whole number of seconds.
+func (s *Server) NewLeaderParticipationWithTTL(name, id string, ttl time.Duration) (topo.LeaderParticipation, error) {
 	return &etcdLeaderParticipation{
 		s:    s,
 		name: name,
 		id:   id,
+		ttl:  ttl,
 		stop: make(chan struct{}),
 		done: make(chan struct{}),
 	}, nil
@@ -52,18 +65,26 @@ type etcdLeaderParticipation struct {
 	// id is the process's current id.
 	id string
 
+	// ttl is the TTL of the lease of the primaryship, or 0 for
+	// --topo_etcd_lease_ttl.
+	ttl time.Duration
+
 	// stop is a channel closed when Stop is called.
 	stop chan struct{}
 
 	// done is a channel closed when we're done processing the Stop
 	done chan struct{}
+
+	// wg tracks the goroutines of the calls of WaitForLeadership, which
+	// release the lock when Stop is called.
+	wg sync.WaitGroup
 }
 
 // WaitForLeadership is part of the topo.LeaderParticipation interface.
 func (mp *etcdLeaderParticipation) WaitForLeadership() (context.Context, error) {
-	// If Stop was already called, mp.done is closed, so we are interrupted.
+	// If Stop was already called, mp.stop is closed, so we are interrupted.
 	select {
-	case <-mp.done:
+	case <-mp.stop:
 		return nil, topo.NewError(topo.Interrupted, "Leadership")
 	default:
 	}
@@ -74,10 +95,17 @@ func (mp *etcdLeaderParticipation) WaitForLeadership() (context.Context, error)
 	// We use a cancelable context here. If stop is closed,
 	// we just cancel that context.
 	lockCtx, lockCancel := context.WithCancel(context.Background())
+	// lost is closed when we lose the lock, e.g. to ForceResign.
+	lost := make(chan struct{})
+	mp.wg.Add(1)
 	go func() {
+		defer mp.wg.Done()
 		select {
 		case <-mp.s.running:
 			return
+		case <-lost:
+			lockCancel()
+			return
 		case <-mp.stop:
 		}
 		if ld != nil {
@@ -86,26 +114,62 @@ func (mp *etcdLeaderParticipation) WaitForLeadership() (context.Context, error)
 			}
 		}
 		lockCancel()
-		close(mp.done)
 	}()
 
 	// Try to get the primaryship, by getting a lock.
 	var err error
-	ld, err = mp.s.lock(lockCtx, electionPath, mp.id)
+	ld, err = mp.s.lock(lockCtx, electionPath, mp.id, mp.leaseTTL())
 	if err != nil {
 		// It can be that we were interrupted.
 		return nil, err
 	}
 
 	// We got the lock. Return the lockContext. If Stop() is called,
-	// it will cancel the lockCtx, and cancel the returned context.
+	// or if we lose the lock, it will cancel the lockCtx, and cancel the
+	// returned context.
+	go mp.checkLeadership(lockCtx, ld, lost)
 	return lockCtx, nil
 }
 
+// leaseTTL returns the TTL of the lease of the primaryship, in seconds.
+func (mp *etcdLeaderParticipation) leaseTTL() int {
+	return leaseTTLSeconds(mp.ttl)
+}
+
+// checkLeadership checks the lock of the primaryship every
+// leadershipCheckInterval, or more often for a short TTL, until ctx is
+// canceled, and closes lost if its lease is gone, e.g. revoked by ForceResign
+// or expired.
+func (mp *etcdLeaderParticipation) checkLeadership(ctx context.Context, ld topo.LockDescriptor, lost chan struct{}) {
+	interval := leadershipCheckInterval
+	if mp.ttl > 0 {
+		// Notice the loss of a short lease about as fast as the
+		// other participants do.
+		interval = min(interval, mp.ttl/3)
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if err := ld.Check(ctx); topo.IsErrType(err, topo.NoNode) {
+			log.Warningf("Lost the lock of election %v, canceling leadership: %v", mp.name, err)
+			close(lost)
+			return
+		}
+	}
+}
+
 // Stop is part of the topo.LeaderParticipation interface
 func (mp *etcdLeaderParticipation) Stop() {
 	close(mp.stop)
-	<-mp.done
+	// The lock may have been lost already, in which case there is nothing
+	// left to release.
+	mp.wg.Wait()
+	close(mp.done)
 }
 
 // GetCurrentLeaderID is part of the topo.LeaderParticipation interface
@@ -190,3 +254,28 @@ func (mp *etcdLeaderParticipation) WaitForNewLeader(ctx context.Context) (<-chan
 
 	return notifications, nil
 }
+
+// ForceResign is part of the topo.ForceResignLeaderParticipation interface.
+// It revokes the lease of the lock of the primary, which notices on its next
+// check of the lock.
+func (mp *etcdLeaderParticipation) ForceResign(ctx context.Context, id string) error {
+	electionPath := path.Join(mp.s.root, electionsPath, mp.name)
+
+	resp, err := mp.s.cli.Get(ctx, electionPath+"/",
+		clientv3.WithPrefix(),
+		clientv3.WithSort(clientv3.SortByModRevision, clientv3.SortAscend),
+		clientv3.WithLimit(1))
+	if err != nil {
+		return convertError(err, electionPath)
+	}
+	if len(resp.Kvs) == 0 {
+		return topo.NewError(topo.NoNode, electionPath)
+	}
+	if string(resp.Kvs[0].Value) != id {
+		return topo.NewError(topo.BadVersion, electionPath)
+	}
+	if _, err := mp.s.cli.Revoke(ctx, clientv3.LeaseID(resp.Kvs[0].Lease)); err != nil {
+		return convertError(err, electionPath)
+	}
+	return nil
+}
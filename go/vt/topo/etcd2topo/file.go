@@ -73,11 +73,20 @@ func (s *Server) Update(ctx context.Context, filePath string, contents []byte, v
 	return EtcdVersion(resp.Header.Revision), nil
 }
 
+// readOptions returns opts, with the serializable read option if the read
+// has a relaxed consistency and the serializable reads are enabled.
+func readOptions(ctx context.Context, opts ...clientv3.OpOption) []clientv3.OpOption {
+	if serializableReads && topo.ConsistencyOf(ctx) == topo.Relaxed {
+		opts = append(opts, clientv3.WithSerializable())
+	}
+	return opts
+}
+
 // Get is part of the topo.Conn interface.
 func (s *Server) Get(ctx context.Context, filePath string) ([]byte, topo.Version, error) {
 	nodePath := path.Join(s.root, filePath)
 
-	resp, err := s.cli.Get(ctx, nodePath)
+	resp, err := s.cli.Get(ctx, nodePath, readOptions(ctx)...)
 	if err != nil {
 		return nil, nil, convertError(err, nodePath)
 	}
@@ -92,7 +101,7 @@ func (s *Server) Get(ctx context.Context, filePath string) ([]byte, topo.Version
 func (s *Server) GetVersion(ctx context.Context, filePath string, version int64) ([]byte, error) {
 	nodePath := path.Join(s.root, filePath)
 
-	resp, err := s.cli.Get(ctx, nodePath, clientv3.WithRev(version))
+	resp, err := s.cli.Get(ctx, nodePath, readOptions(ctx, clientv3.WithRev(version))...)
 	if err != nil {
 		return nil, convertError(err, nodePath)
 	}
@@ -107,7 +116,7 @@ func (s *Server) GetVersion(ctx context.Context, filePath string, version int64)
 func (s *Server) List(ctx context.Context, filePathPrefix string) ([]topo.KVInfo, error) {
 	nodePathPrefix := path.Join(s.root, filePathPrefix)
 
-	resp, err := s.cli.Get(ctx, nodePathPrefix, clientv3.WithPrefix())
+	resp, err := s.cli.Get(ctx, nodePathPrefix, readOptions(ctx, clientv3.WithPrefix())...)
 	if err != nil {
 		return []topo.KVInfo{}, err
 	}
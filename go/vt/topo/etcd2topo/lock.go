@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"path"
+	"time"
 
 	"github.com/spf13/pflag"
 
@@ -153,7 +154,7 @@ func (s *Server) TryLock(ctx context.Context, dirPath, contents string) (topo.Lo
 	}
 
 	// everything is good let's acquire the lock.
-	return s.lock(ctx, dirPath, contents)
+	return s.lock(ctx, dirPath, contents, leaseTTL)
 }
 
 // Lock is part of the topo.Conn interface.
@@ -168,15 +169,87 @@ func (s *Server) Lock(ctx context.Context, dirPath, contents string) (topo.LockD
 		return nil, convertError(err, dirPath)
 	}
 
-	return s.lock(ctx, dirPath, contents)
+	return s.lock(ctx, dirPath, contents, leaseTTL)
 }
 
-// lock is used by both Lock() and primary election.
-func (s *Server) lock(ctx context.Context, nodePath, contents string) (topo.LockDescriptor, error) {
+// LockNameWithTTL is part of the topo.NamedLockConn interface.
+// The lock is a file of the locks directory like for Lock, whose lease
+// expires ttl after its holder stops keeping it alive. ttl is rounded up to
+// a whole number of seconds, and the etcd servers extend the leases shorter
+// than their minimum TTL.
+func (s *Server) LockNameWithTTL(ctx context.Context, dirPath, contents string, ttl time.Duration) (topo.LockDescriptor, error) {
+	return s.lock(ctx, dirPath, contents, leaseTTLSeconds(ttl))
+}
+
+// leaseTTLSeconds returns ttl in seconds, rounded up, or
+// --topo_etcd_lease_ttl if ttl is 0.
+func leaseTTLSeconds(ttl time.Duration) int {
+	if ttl <= 0 {
+		return leaseTTL
+	}
+	return int((ttl + time.Second - 1) / time.Second)
+}
+
+// GetLock is part of the topo.LockHolderConn interface.
+// The holder of the lock is the oldest of the files in the locks directory,
+// the others are waiting for it. The lock is stale if the lease of the file
+// expired, or missed its last renewal: the holders keep their lease alive by
+// renewing it every third of its TTL.
+func (s *Server) GetLock(ctx context.Context, dirPath string) (*topo.LockInfo, error) {
+	nodePath := path.Join(s.root, dirPath, locksPath)
+	resp, err := s.cli.Get(ctx, nodePath+"/", clientv3.WithFirstCreate()...)
+	if err != nil {
+		return nil, convertError(err, nodePath)
+	}
+	if len(resp.Kvs) == 0 {
+		return nil, topo.NewError(topo.NoNode, dirPath)
+	}
+
+	kv := resp.Kvs[0]
+	info := &topo.LockInfo{Contents: string(kv.Value)}
+	ttl, err := s.cli.TimeToLive(ctx, clientv3.LeaseID(kv.Lease))
+	if err != nil {
+		return nil, convertError(err, nodePath)
+	}
+	if ttl.TTL > 0 {
+		info.TTL = time.Duration(ttl.TTL) * time.Second
+	}
+	info.Stale = ttl.TTL <= 0 || ttl.TTL < ttl.GrantedTTL*2/3-leaseRenewalSlack
+	return info, nil
+}
+
+// ForceUnlock is part of the topo.LockHolderConn interface.
+// It revokes the lease of the holder, which deletes its file in the locks
+// directory and makes its Check fail.
+func (s *Server) ForceUnlock(ctx context.Context, dirPath, contents string) error {
+	nodePath := path.Join(s.root, dirPath, locksPath)
+	resp, err := s.cli.Get(ctx, nodePath+"/", clientv3.WithFirstCreate()...)
+	if err != nil {
+		return convertError(err, nodePath)
+	}
+	if len(resp.Kvs) == 0 {
+		return topo.NewError(topo.NoNode, dirPath)
+	}
+	if string(resp.Kvs[0].Value) != contents {
+		return topo.NewError(topo.BadVersion, dirPath)
+	}
+	if _, err := s.cli.Revoke(ctx, clientv3.LeaseID(resp.Kvs[0].Lease)); err != nil {
+		return convertError(err, nodePath)
+	}
+	return nil
+}
+
+// leaseRenewalSlack is how many seconds a lease can be renewed late, e.g. by
+// network delays, before its holder is considered gone.
+const leaseRenewalSlack = 2
+
+// lock is used by both Lock() and primary election. ttl is the TTL of the
+// lease of the lock, in seconds.
+func (s *Server) lock(ctx context.Context, nodePath, contents string, ttl int) (topo.LockDescriptor, error) {
 	nodePath = path.Join(s.root, nodePath, locksPath)
 
 	// Get a lease, set its KeepAlive.
-	lease, err := s.cli.Grant(ctx, int64(leaseTTL))
+	lease, err := s.cli.Grant(ctx, int64(ttl))
 	if err != nil {
 		return nil, convertError(err, nodePath)
 	}
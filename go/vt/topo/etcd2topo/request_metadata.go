@@ -0,0 +1,49 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package etcd2topo
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"vitess.io/vitess/go/vt/topo"
+)
+
+// withRequestMetadata returns ctx with the topo.RequestMetadata of ctx
+// appended to its outgoing gRPC metadata, so that the etcd audit logs can
+// be correlated with the Vitess operations.
+func withRequestMetadata(ctx context.Context) context.Context {
+	headers := topo.RequestMetadataOf(ctx).Headers()
+	if len(headers) == 0 {
+		return ctx
+	}
+	kv := make([]string, 0, 2*len(headers))
+	for k, v := range headers {
+		kv = append(kv, k, v)
+	}
+	return metadata.AppendToOutgoingContext(ctx, kv...)
+}
+
+func requestMetadataUnaryInterceptor(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	return invoker(withRequestMetadata(ctx), method, req, reply, cc, opts...)
+}
+
+func requestMetadataStreamInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	return streamer(withRequestMetadata(ctx), desc, cc, method, opts...)
+}
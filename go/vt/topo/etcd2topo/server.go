@@ -47,12 +47,19 @@ import (
 
 	"vitess.io/vitess/go/vt/servenv"
 	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/vttls"
 )
 
 var (
 	clientCertPath string
 	clientKeyPath  string
 	serverCaPath   string
+	watchTLSFiles  bool
+
+	// serializableReads is whether the reads with a topo.Relaxed
+	// consistency are serializable reads, served by any member of the etcd cluster,
+	// instead of linearizable reads going through its leader.
+	serializableReads bool
 )
 
 // Factory is the consul topo.Factory implementation.
@@ -77,6 +84,13 @@ type Server struct {
 	root string
 
 	running chan struct{}
+
+	// certReloader is set when the TLS files are watched for changes.
+	certReloader *vttls.CertReloader
+
+	// clientCert is the client certificate presented to etcd, when the TLS
+	// files are not watched.
+	clientCert *tls.Certificate
 }
 
 func init() {
@@ -90,6 +104,8 @@ func registerEtcd2TopoFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&clientCertPath, "topo_etcd_tls_cert", clientCertPath, "path to the client cert to use to connect to the etcd topo server, requires topo_etcd_tls_key, enables TLS")
 	fs.StringVar(&clientKeyPath, "topo_etcd_tls_key", clientKeyPath, "path to the client key to use to connect to the etcd topo server, enables TLS")
 	fs.StringVar(&serverCaPath, "topo_etcd_tls_ca", serverCaPath, "path to the ca to use to validate the server cert when connecting to the etcd topo server")
+	fs.BoolVar(&watchTLSFiles, "topo_etcd_tls_watch", watchTLSFiles, "watch the etcd topo TLS cert, key and ca files and reload them when they change")
+	fs.BoolVar(&serializableReads, "topo_etcd_serializable_reads", serializableReads, "if set, the reads which tolerate stale data, like those of the UIs, validators and metrics scans, are serializable reads served by any etcd member instead of quorum reads, to spare the etcd leader")
 }
 
 // Close implements topo.Server.Close.
@@ -99,6 +115,9 @@ func (s *Server) Close() {
 	close(s.running)
 	s.cli.Close()
 	s.cli = nil
+	if s.certReloader != nil {
+		s.certReloader.Close()
+	}
 }
 
 func newTLSConfig(certPath, keyPath, caPath string) (*tls.Config, error) {
@@ -141,28 +160,71 @@ func NewServerWithOpts(serverAddr, root, certPath, keyPath, caPath string) (*Ser
 	config := clientv3.Config{
 		Endpoints:   strings.Split(serverAddr, ","),
 		DialTimeout: 5 * time.Second,
-		DialOptions: []grpc.DialOption{grpc.WithBlock()}, // nolint:staticcheck
+		DialOptions: []grpc.DialOption{
+			grpc.WithBlock(), // nolint:staticcheck
+			grpc.WithChainUnaryInterceptor(requestMetadataUnaryInterceptor),
+			grpc.WithChainStreamInterceptor(requestMetadataStreamInterceptor),
+		},
 	}
 
-	tlscfg, err := newTLSConfig(certPath, keyPath, caPath)
-	if err != nil {
-		return nil, err
+	var (
+		certReloader *vttls.CertReloader
+		clientCert   *tls.Certificate
+	)
+	if watchTLSFiles && certPath != "" && keyPath != "" {
+		var err error
+		certReloader, err = vttls.NewCertReloader(certPath, keyPath, caPath, true)
+		if err != nil {
+			return nil, err
+		}
+		config.TLS = certReloader.ClientConfig()
+	} else {
+		tlscfg, err := newTLSConfig(certPath, keyPath, caPath)
+		if err != nil {
+			return nil, err
+		}
+		config.TLS = tlscfg
+		if tlscfg != nil && len(tlscfg.Certificates) > 0 {
+			clientCert = &tlscfg.Certificates[0]
+		}
 	}
 
-	config.TLS = tlscfg
-
 	cli, err := clientv3.New(config)
 	if err != nil {
+		if certReloader != nil {
+			certReloader.Close()
+		}
 		return nil, err
 	}
 
 	return &Server{
-		cli:     cli,
-		root:    root,
-		running: make(chan struct{}),
+		cli:          cli,
+		root:         root,
+		running:      make(chan struct{}),
+		certReloader: certReloader,
+		clientCert:   clientCert,
 	}, nil
 }
 
+// ClientIdentity is part of the topo.ClientIdentifier interface. It returns
+// the identity of the client certificate presented to etcd, the current one
+// if the TLS files are watched.
+func (s *Server) ClientIdentity() string {
+	if s.certReloader != nil {
+		return topo.CertIdentity(s.certReloader.Certificate())
+	}
+	return topo.CertIdentity(s.clientCert)
+}
+
+// OnClientIdentityChange is part of the topo.ClientIdentityNotifier
+// interface. The identity only changes when the watched TLS files are
+// reloaded.
+func (s *Server) OnClientIdentityChange(f func()) {
+	if s.certReloader != nil {
+		s.certReloader.OnReload(f)
+	}
+}
+
 // NewServer returns a new etcdtopo.Server.
 func NewServer(serverAddr, root string) (*Server, error) {
 	// TODO: Rename this to a name to signifies this function uses the process-wide TLS settings.
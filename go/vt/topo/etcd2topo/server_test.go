@@ -33,6 +33,7 @@ import (
 	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
 	"vitess.io/vitess/go/vt/tlstest"
 	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
 	"vitess.io/vitess/go/vt/topo/test"
 
 	clientv3 "go.etcd.io/etcd/client/v3"
@@ -220,6 +221,25 @@ func TestEtcd2TLS(t *testing.T) {
 	if string(val) != testVal {
 		t.Fatalf("Value returned doesn't match %s, err: %v", testVal, err)
 	}
+
+	// Same thing, with the certificates watched for changes.
+	watchTLSFiles = true
+	defer func() { watchTLSFiles = false }()
+	watchServer, err := NewServerWithOpts(clientAddr, testRoot, certs.ClientCert, certs.ClientKey, certs.ServerCA)
+	if err != nil {
+		t.Fatalf("NewServerWithOpts failed: %v", err)
+	}
+	defer watchServer.Close()
+	if watchServer.certReloader == nil {
+		t.Fatalf("expected certReloader to be set")
+	}
+	val, _, err = watchServer.Get(testCtx, testKey)
+	if err != nil {
+		t.Fatalf("Failed to retrieve value at key with watched certs: %v", err)
+	}
+	if string(val) != testVal {
+		t.Fatalf("Value returned doesn't match %s, err: %v", testVal, err)
+	}
 }
 
 func TestEtcd2Topo(t *testing.T) {
@@ -262,6 +282,80 @@ func TestEtcd2Topo(t *testing.T) {
 	ts.Close()
 }
 
+// TestEtcd2TopoLockQuorumReads checks that the reads made under a lock are
+// quorum reads when the serializable reads are enabled, even if the caller
+// of the lock tolerates stale data.
+func TestEtcd2TopoLockQuorumReads(t *testing.T) {
+	defer func(enabled bool) {
+		serializableReads = enabled
+	}(serializableReads)
+	serializableReads = true
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "zone1")
+	defer ts.Close()
+	require.NoError(t, ts.CreateKeyspace(ctx, "ks", &topodatapb.Keyspace{}))
+	require.NoError(t, ts.CreateShard(ctx, "ks", "-"))
+
+	staleCtx := topo.WithConsistency(ctx, topo.Relaxed)
+	require.Len(t, readOptions(staleCtx), 1)
+
+	lockCtx, unlock, err := ts.LockKeyspace(staleCtx, "ks", "keyspace")
+	require.NoError(t, err)
+	require.Empty(t, readOptions(lockCtx))
+	unlock(&err)
+	require.NoError(t, err)
+
+	lockCtx, unlock, err = ts.LockShard(staleCtx, "ks", "-", "shard")
+	require.NoError(t, err)
+	require.Empty(t, readOptions(lockCtx))
+	unlock(&err)
+	require.NoError(t, err)
+
+	lockCtx, unlock, err = ts.LockName(staleCtx, "name", "named")
+	require.NoError(t, err)
+	require.Empty(t, readOptions(lockCtx))
+	unlock(&err)
+	require.NoError(t, err)
+
+	// The reads of the caller are still relaxed.
+	require.Len(t, readOptions(staleCtx), 1)
+}
+
+// TestEtcd2TopoSerializableReads checks that the reads which tolerate stale
+// data are served when the serializable reads are enabled.
+func TestEtcd2TopoSerializableReads(t *testing.T) {
+	ctx := context.Background()
+	staleCtx := topo.WithConsistency(ctx, topo.Relaxed)
+	require.Empty(t, readOptions(staleCtx))
+
+	defer func(enabled bool) {
+		serializableReads = enabled
+	}(serializableReads)
+	serializableReads = true
+	require.Empty(t, readOptions(ctx))
+	require.Len(t, readOptions(staleCtx, clientv3.WithPrefix()), 2)
+	require.Empty(t, readOptions(topo.WithConsistency(staleCtx, topo.Quorum)))
+
+	clientAddr, _ := startEtcd(t, 0)
+	s, err := NewServer(clientAddr, "/test-serializable")
+	require.NoError(t, err)
+	defer s.Close()
+
+	_, err = s.Create(ctx, "dir/file", []byte("contents"))
+	require.NoError(t, err)
+	contents, _, err := s.Get(staleCtx, "dir/file")
+	require.NoError(t, err)
+	require.Equal(t, "contents", string(contents))
+	entries, err := s.ListDir(staleCtx, "dir", false)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	kvs, err := s.List(staleCtx, "dir/")
+	require.NoError(t, err)
+	require.Len(t, kvs, 1)
+}
+
 // TestEtcd2TopoGetTabletsPartialResults confirms that GetTablets handles partial results
 // correctly when etcd2 is used along with the normal vtctldclient <-> vtctld client/server
 // path.
@@ -175,9 +175,9 @@ func (s *Server) WatchRecursive(ctx context.Context, dirpath string) ([]*topo.Wa
 
 	for _, kv := range initial.Kvs {
 		var wd topo.WatchDataRecursive
-		wd.Path = string(kv.Key)
+		wd.Path = s.relativePath(kv.Key)
 		wd.Contents = kv.Value
-		wd.Version = EtcdVersion(initial.Kvs[0].ModRevision)
+		wd.Version = EtcdVersion(kv.ModRevision)
 		initialwd = append(initialwd, &wd)
 	}
 
@@ -256,7 +256,7 @@ func (s *Server) WatchRecursive(ctx context.Context, dirpath string) ([]*topo.Wa
 					switch ev.Type {
 					case mvccpb.PUT:
 						notifications <- &topo.WatchDataRecursive{
-							Path: string(ev.Kv.Key),
+							Path: s.relativePath(ev.Kv.Key),
 							WatchData: topo.WatchData{
 								Contents: ev.Kv.Value,
 								Version:  EtcdVersion(ev.Kv.ModRevision),
@@ -264,7 +264,7 @@ func (s *Server) WatchRecursive(ctx context.Context, dirpath string) ([]*topo.Wa
 						}
 					case mvccpb.DELETE:
 						notifications <- &topo.WatchDataRecursive{
-							Path: string(ev.Kv.Key),
+							Path: s.relativePath(ev.Kv.Key),
 							WatchData: topo.WatchData{
 								Err: topo.NewError(topo.NoNode, nodePath),
 							},
@@ -277,3 +277,9 @@ func (s *Server) WatchRecursive(ctx context.Context, dirpath string) ([]*topo.Wa
 
 	return initialwd, notifications, nil
 }
+
+// relativePath returns the path of a key relative to the root directory of
+// the cell, as WatchDataRecursive.Path expects.
+func (s *Server) relativePath(key []byte) string {
+	return strings.TrimPrefix(strings.TrimPrefix(string(key), s.root), "/")
+}
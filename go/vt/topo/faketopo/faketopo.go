@@ -20,6 +20,7 @@ import (
 	"context"
 	"strings"
 	"sync"
+	"time"
 
 	"vitess.io/vitess/go/vt/log"
 	"vitess.io/vitess/go/vt/topo"
@@ -296,6 +297,21 @@ func (f *FakeConn) TryLock(ctx context.Context, dirPath, contents string) (topo.
 	return f.Lock(ctx, dirPath, contents)
 }
 
+// LockNameWithTTL implements the NamedLockConn interface
+func (f *FakeConn) LockNameWithTTL(ctx context.Context, dirPath, contents string, ttl time.Duration) (topo.LockDescriptor, error) {
+	return f.Lock(ctx, dirPath, contents)
+}
+
+// GetLock implements the LockHolderConn interface
+func (f *FakeConn) GetLock(ctx context.Context, dirPath string) (*topo.LockInfo, error) {
+	return nil, topo.NewError(topo.NoNode, dirPath)
+}
+
+// ForceUnlock implements the LockHolderConn interface
+func (f *FakeConn) ForceUnlock(ctx context.Context, dirPath, contents string) error {
+	return topo.NewError(topo.NoNode, dirPath)
+}
+
 // Watch implements the Conn interface
 func (f *FakeConn) Watch(ctx context.Context, filePath string) (*topo.WatchData, <-chan *topo.WatchData, error) {
 	f.mu.Lock()
@@ -329,8 +345,10 @@ func (f *FakeConn) Watch(ctx context.Context, filePath string) (*topo.WatchData,
 	return current, notifications, nil
 }
 
+// WatchRecursive implements the Conn interface. It is not supported, so that
+// the callers fall back to listing the directories.
 func (f *FakeConn) WatchRecursive(ctx context.Context, path string) ([]*topo.WatchDataRecursive, <-chan *topo.WatchDataRecursive, error) {
-	panic("implement me")
+	return nil, nil, topo.NewError(topo.NoImplementation, path)
 }
 
 // NewLeaderParticipation implements the Conn interface
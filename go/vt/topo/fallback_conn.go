@@ -0,0 +1,369 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topo
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+var _ Conn = (*FallbackConn)(nil)
+
+var (
+	topoFallbackReads = stats.NewCountersWithMultiLabels(
+		"TopologyFallbackReads",
+		"TopologyFallbackReads reads served from the local cache because the topology server was unreachable",
+		[]string{"Operation", "Cell"})
+
+	topoFallbackActive = stats.NewGaugesWithSingleLabel(
+		"TopologyFallbackActive",
+		"TopologyFallbackActive is 1 while reads are served from the local cache, until the topology server answers again",
+		"Cell")
+
+	topoFallbackStaleness = stats.NewGaugesWithSingleLabel(
+		"TopologyFallbackStalenessSeconds",
+		"TopologyFallbackStalenessSeconds is the age of the oldest record served from the local cache since the topology server became unreachable",
+		"Cell")
+)
+
+// Kinds of cached reads.
+const (
+	fallbackGet     = "Get"
+	fallbackList    = "List"
+	fallbackListDir = "ListDir"
+)
+
+// FallbackConn is a Conn that persists the records it reads to a local
+// directory, and serves reads from it when the topology server can't be
+// reached. It is a degraded, read-only mode: writes, locks, watches and
+// elections always go to the topology server, and fail while it is down.
+//
+// A read falls back to the cache when the topology server is unavailable or
+// doesn't answer in time, including when the deadline of the caller expires
+// waiting for it: the cache is local, and doesn't need the context of the
+// caller. Semantic errors, like the record not existing, are returned as
+// they are, and so are the errors of the callers that canceled. Reads
+// served from the cache are counted in TopologyFallbackReads, and
+// TopologyFallbackActive and TopologyFallbackStalenessSeconds tell how long
+// the records served are out of date.
+type FallbackConn struct {
+	cell string
+	conn Conn
+	dir  string
+
+	mu sync.Mutex
+	// versions are the versions of the records in the cache, so those that
+	// didn't change aren't written again.
+	versions map[string]string
+	// oldest is the time the oldest record served from the cache was
+	// cached, since the topology server became unreachable.
+	oldest time.Time
+}
+
+// fallbackRecord is a record in the cache.
+type fallbackRecord struct {
+	Kind string    `json:"kind"`
+	Path string    `json:"path"`
+	Time time.Time `json:"time"`
+
+	// Get
+	Contents []byte `json:"contents,omitempty"`
+	Version  string `json:"version,omitempty"`
+	// List
+	KVs []fallbackKV `json:"kvs,omitempty"`
+	// ListDir
+	Full    bool       `json:"full,omitempty"`
+	Entries []DirEntry `json:"entries,omitempty"`
+}
+
+type fallbackKV struct {
+	Key     []byte `json:"key"`
+	Value   []byte `json:"value"`
+	Version string `json:"version"`
+}
+
+// fallbackVersion is the Version of a cached record.
+type fallbackVersion string
+
+// String is part of the Version interface.
+func (v fallbackVersion) String() string {
+	return string(v)
+}
+
+// NewFallbackConn returns a FallbackConn caching the reads of conn in dir,
+// which is created if needed.
+func NewFallbackConn(cell string, conn Conn, dir string) (*FallbackConn, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	topoFallbackActive.Set(cell, 0)
+	topoFallbackStaleness.Set(cell, 0)
+	return &FallbackConn{
+		cell:     cell,
+		conn:     conn,
+		dir:      dir,
+		versions: make(map[string]string),
+	}, nil
+}
+
+// ListDir is part of the Conn interface.
+func (fc *FallbackConn) ListDir(ctx context.Context, dirPath string, full bool) ([]DirEntry, error) {
+	entries, err := fc.conn.ListDir(ctx, dirPath, full)
+	key := fallbackKey(fallbackListDir, dirPath, full)
+	if err == nil {
+		fc.live()
+		fc.store(key, "", &fallbackRecord{Kind: fallbackListDir, Path: dirPath, Full: full, Entries: entries})
+		return entries, nil
+	}
+	if !fc.shouldFallBack(ctx, err) {
+		return nil, err
+	}
+	record := fc.load(key, fallbackListDir, err)
+	if record == nil {
+		return nil, err
+	}
+	return record.Entries, nil
+}
+
+// Create is part of the Conn interface.
+func (fc *FallbackConn) Create(ctx context.Context, filePath string, contents []byte) (Version, error) {
+	return fc.conn.Create(ctx, filePath, contents)
+}
+
+// Update is part of the Conn interface.
+func (fc *FallbackConn) Update(ctx context.Context, filePath string, contents []byte, version Version) (Version, error) {
+	return fc.conn.Update(ctx, filePath, contents, version)
+}
+
+// Get is part of the Conn interface.
+func (fc *FallbackConn) Get(ctx context.Context, filePath string) ([]byte, Version, error) {
+	contents, version, err := fc.conn.Get(ctx, filePath)
+	key := fallbackKey(fallbackGet, filePath, false)
+	if err == nil {
+		fc.live()
+		fc.store(key, version.String(), &fallbackRecord{Kind: fallbackGet, Path: filePath, Contents: contents, Version: version.String()})
+		return contents, version, nil
+	}
+	if !fc.shouldFallBack(ctx, err) {
+		return nil, nil, err
+	}
+	record := fc.load(key, fallbackGet, err)
+	if record == nil {
+		return nil, nil, err
+	}
+	return record.Contents, fallbackVersion(record.Version), nil
+}
+
+// GetVersion is part of the Conn interface. Past versions are not cached.
+func (fc *FallbackConn) GetVersion(ctx context.Context, filePath string, version int64) ([]byte, error) {
+	return fc.conn.GetVersion(ctx, filePath, version)
+}
+
+// List is part of the Conn interface.
+func (fc *FallbackConn) List(ctx context.Context, filePathPrefix string) ([]KVInfo, error) {
+	kvs, err := fc.conn.List(ctx, filePathPrefix)
+	key := fallbackKey(fallbackList, filePathPrefix, false)
+	if err == nil {
+		fc.live()
+		record := &fallbackRecord{Kind: fallbackList, Path: filePathPrefix, KVs: make([]fallbackKV, 0, len(kvs))}
+		for _, kv := range kvs {
+			record.KVs = append(record.KVs, fallbackKV{Key: kv.Key, Value: kv.Value, Version: kv.Version.String()})
+		}
+		fc.store(key, "", record)
+		return kvs, nil
+	}
+	if !fc.shouldFallBack(ctx, err) {
+		return nil, err
+	}
+	record := fc.load(key, fallbackList, err)
+	if record == nil {
+		return nil, err
+	}
+	kvs = make([]KVInfo, 0, len(record.KVs))
+	for _, kv := range record.KVs {
+		kvs = append(kvs, KVInfo{Key: kv.Key, Value: kv.Value, Version: fallbackVersion(kv.Version)})
+	}
+	return kvs, nil
+}
+
+// Delete is part of the Conn interface.
+func (fc *FallbackConn) Delete(ctx context.Context, filePath string, version Version) error {
+	return fc.conn.Delete(ctx, filePath, version)
+}
+
+// Lock is part of the Conn interface.
+func (fc *FallbackConn) Lock(ctx context.Context, dirPath, contents string) (LockDescriptor, error) {
+	return fc.conn.Lock(ctx, dirPath, contents)
+}
+
+// TryLock is part of the Conn interface.
+func (fc *FallbackConn) TryLock(ctx context.Context, dirPath, contents string) (LockDescriptor, error) {
+	return fc.conn.TryLock(ctx, dirPath, contents)
+}
+
+// LockNameWithTTL is part of the NamedLockConn interface.
+func (fc *FallbackConn) LockNameWithTTL(ctx context.Context, dirPath, contents string, ttl time.Duration) (LockDescriptor, error) {
+	return lockNameWithTTL(ctx, fc.conn, dirPath, contents, ttl)
+}
+
+// GetLock is part of the LockHolderConn interface.
+func (fc *FallbackConn) GetLock(ctx context.Context, dirPath string) (*LockInfo, error) {
+	return getLock(ctx, fc.conn, dirPath)
+}
+
+// ForceUnlock is part of the LockHolderConn interface.
+func (fc *FallbackConn) ForceUnlock(ctx context.Context, dirPath, contents string) error {
+	return forceUnlock(ctx, fc.conn, dirPath, contents)
+}
+
+// Watch is part of the Conn interface.
+func (fc *FallbackConn) Watch(ctx context.Context, filePath string) (*WatchData, <-chan *WatchData, error) {
+	return fc.conn.Watch(ctx, filePath)
+}
+
+// WatchRecursive is part of the Conn interface.
+func (fc *FallbackConn) WatchRecursive(ctx context.Context, path string) ([]*WatchDataRecursive, <-chan *WatchDataRecursive, error) {
+	return fc.conn.WatchRecursive(ctx, path)
+}
+
+// NewLeaderParticipation is part of the Conn interface.
+func (fc *FallbackConn) NewLeaderParticipation(name, id string) (LeaderParticipation, error) {
+	return fc.conn.NewLeaderParticipation(name, id)
+}
+
+// NewLeaderParticipationWithTTL is part of the LeaderParticipationTTLConn
+// interface.
+func (fc *FallbackConn) NewLeaderParticipationWithTTL(name, id string, ttl time.Duration) (LeaderParticipation, error) {
+	return newLeaderParticipationWithTTL(fc.conn, name, id, ttl)
+}
+
+// Close is part of the Conn interface.
+func (fc *FallbackConn) Close() {
+	fc.conn.Close()
+}
+
+// shouldFallBack returns true if a failed read should be served from the
+// cache: the topology server couldn't be reached, or didn't answer before the
+// deadline, and the caller didn't cancel.
+func (fc *FallbackConn) shouldFallBack(ctx context.Context, err error) bool {
+	if errors.Is(ctx.Err(), context.Canceled) {
+		return false
+	}
+	var netErr net.Error
+	switch {
+	case IsErrType(err, Timeout), errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr):
+		return true
+	}
+	switch vterrors.Code(err) {
+	case vtrpcpb.Code_UNAVAILABLE, vtrpcpb.Code_DEADLINE_EXCEEDED:
+		return true
+	}
+	return false
+}
+
+// live records that the topology server answered.
+func (fc *FallbackConn) live() {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	if !fc.oldest.IsZero() {
+		log.Infof("Topology server of cell %v is reachable again, no longer serving reads from the local cache", fc.cell)
+		fc.oldest = time.Time{}
+		topoFallbackActive.Set(fc.cell, 0)
+		topoFallbackStaleness.Set(fc.cell, 0)
+	}
+}
+
+// store writes a record to the cache, unless it has the version already
+// cached. An empty version means records are always written.
+func (fc *FallbackConn) store(key, version string, record *fallbackRecord) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	if version != "" && fc.versions[key] == version {
+		return
+	}
+	record.Time = time.Now()
+	data, err := json.Marshal(record)
+	if err != nil {
+		log.Warningf("Cannot encode topo record %v for the local cache: %v", record.Path, err)
+		return
+	}
+	// Write to a temporary file first, so a record is never half written.
+	p := filepath.Join(fc.dir, key)
+	if err := os.WriteFile(p+".tmp", data, 0o600); err != nil {
+		log.Warningf("Cannot cache topo record %v: %v", record.Path, err)
+		return
+	}
+	if err := os.Rename(p+".tmp", p); err != nil {
+		log.Warningf("Cannot cache topo record %v: %v", record.Path, err)
+		return
+	}
+	if version != "" {
+		fc.versions[key] = version
+	}
+}
+
+// load returns a record from the cache, or nil if it's not cached.
+func (fc *FallbackConn) load(key, kind string, cause error) *fallbackRecord {
+	data, err := os.ReadFile(filepath.Join(fc.dir, key))
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Warningf("Cannot read the local topo cache: %v", err)
+		}
+		return nil
+	}
+	record := &fallbackRecord{}
+	if err := json.Unmarshal(data, record); err != nil {
+		log.Warningf("Cannot decode the local topo cache: %v", err)
+		return nil
+	}
+	topoFallbackReads.Add([]string{kind, fc.cell}, 1)
+
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	if fc.oldest.IsZero() {
+		log.Warningf("Topology server of cell %v is unreachable, serving reads from the local cache: %v", fc.cell, cause)
+		topoFallbackActive.Set(fc.cell, 1)
+	}
+	if fc.oldest.IsZero() || record.Time.Before(fc.oldest) {
+		fc.oldest = record.Time
+	}
+	topoFallbackStaleness.Set(fc.cell, int64(time.Since(fc.oldest).Seconds()))
+	return record
+}
+
+// fallbackKey returns the name of the cache file of a read.
+func fallbackKey(kind, path string, full bool) string {
+	if full {
+		kind += "-full"
+	}
+	sum := sha256.Sum256([]byte(path))
+	return kind + "-" + hex.EncodeToString(sum[:16])
+}
@@ -0,0 +1,132 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topo_test
+
+import (
+	"context"
+	"expvar"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+func TestFallbackConn(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	dir := t.TempDir()
+
+	_, factory := memorytopo.NewServerAndFactory(ctx, "zone1")
+	newConn := func() *topo.FallbackConn {
+		conn, err := factory.Create(topo.GlobalCell, "", "")
+		require.NoError(t, err)
+		fc, err := topo.NewFallbackConn(topo.GlobalCell, conn, dir)
+		require.NoError(t, err)
+		return fc
+	}
+	fc := newConn()
+
+	_, err := fc.Create(ctx, "keyspaces/ks/Keyspace", []byte("ks"))
+	require.NoError(t, err)
+	contents, version, err := fc.Get(ctx, "keyspaces/ks/Keyspace")
+	require.NoError(t, err)
+	assert.Equal(t, "ks", string(contents))
+	entries, err := fc.ListDir(ctx, "keyspaces", false)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	_, err = fc.List(ctx, "keyspaces/")
+	require.NoError(t, err)
+	assert.Equal(t, `{"global": 0}`, expvar.Get("TopologyFallbackActive").String())
+
+	// The global topo goes away: reads are served from the cache, even by a
+	// new conn after a restart, and writes fail.
+	factory.SetError(topo.NewError(topo.Timeout, "global"))
+	defer factory.SetError(nil)
+	fc = newConn()
+
+	contents, cachedVersion, err := fc.Get(ctx, "keyspaces/ks/Keyspace")
+	require.NoError(t, err)
+	assert.Equal(t, "ks", string(contents))
+	assert.Equal(t, version.String(), cachedVersion.String())
+	cachedEntries, err := fc.ListDir(ctx, "keyspaces", false)
+	require.NoError(t, err)
+	assert.Equal(t, entries, cachedEntries)
+	kvs, err := fc.List(ctx, "keyspaces/")
+	require.NoError(t, err)
+	require.Len(t, kvs, 1)
+	assert.Equal(t, "ks", string(kvs[0].Value))
+	assert.Equal(t, `{"global": 1}`, expvar.Get("TopologyFallbackActive").String())
+
+	_, err = fc.Update(ctx, "keyspaces/ks/Keyspace", []byte("ks2"), nil)
+	assert.True(t, topo.IsErrType(err, topo.Timeout))
+	// Records that were never read aren't cached.
+	_, _, err = fc.Get(ctx, "keyspaces/other/Keyspace")
+	assert.True(t, topo.IsErrType(err, topo.Timeout))
+	// Callers that gave up get their error.
+	canceledCtx, cancelGet := context.WithCancel(ctx)
+	cancelGet()
+	_, _, err = fc.Get(canceledCtx, "keyspaces/ks/Keyspace")
+	assert.Error(t, err)
+	// But the callers whose deadline expired waiting for the topology server
+	// are served from the cache.
+	expiredCtx, cancelExpired := context.WithDeadline(ctx, time.Now().Add(-time.Second))
+	defer cancelExpired()
+	contents, _, err = fc.Get(expiredCtx, "keyspaces/ks/Keyspace")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("ks"), contents)
+	// The semantic errors are not hidden by the cache.
+	factory.SetError(topo.NewError(topo.ResourceExhausted, "global"))
+	_, _, err = fc.Get(ctx, "keyspaces/ks/Keyspace")
+	assert.True(t, topo.IsErrType(err, topo.ResourceExhausted))
+	factory.SetError(vterrors.Errorf(vtrpcpb.Code_UNAVAILABLE, "connection refused"))
+	_, _, err = fc.Get(ctx, "keyspaces/ks/Keyspace")
+	require.NoError(t, err)
+
+	// The global topo is back.
+	factory.SetError(nil)
+	_, _, err = fc.Get(ctx, "keyspaces/ks/Keyspace")
+	require.NoError(t, err)
+	assert.Equal(t, `{"global": 0}`, expvar.Get("TopologyFallbackActive").String())
+	assert.Equal(t, `{"global": 0}`, expvar.Get("TopologyFallbackStalenessSeconds").String())
+}
+
+func TestFallbackConnNoNode(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	_, factory := memorytopo.NewServerAndFactory(ctx, "zone1")
+	conn, err := factory.Create(topo.GlobalCell, "", "")
+	require.NoError(t, err)
+	fc, err := topo.NewFallbackConn(topo.GlobalCell, conn, t.TempDir())
+	require.NoError(t, err)
+
+	_, err = fc.Create(ctx, "keyspaces/ks/Keyspace", []byte("ks"))
+	require.NoError(t, err)
+	_, _, err = fc.Get(ctx, "keyspaces/ks/Keyspace")
+	require.NoError(t, err)
+	require.NoError(t, fc.Delete(ctx, "keyspaces/ks/Keyspace", nil))
+
+	// A deleted record isn't served from the cache.
+	_, _, err = fc.Get(ctx, "keyspaces/ks/Keyspace")
+	assert.True(t, topo.IsErrType(err, topo.NoNode))
+}
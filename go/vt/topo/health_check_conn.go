@@ -0,0 +1,450 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topo
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/log"
+)
+
+var _ Conn = (*HealthCheckConn)(nil)
+
+var (
+	topoConnHealthy = stats.NewGaugesWithSingleLabel(
+		"TopologyConnHealthy",
+		"TopologyConnHealthy is 1 while the health probes of the topology server of the cell succeed, and 0 once one failed, until one succeeds again or the connection is re-established",
+		"Cell")
+
+	topoConnReconnects = stats.NewCountersWithSingleLabel(
+		"TopologyConnReconnects",
+		"TopologyConnReconnects connections to the topology server of the cell re-established after failed health probes",
+		"Cell")
+)
+
+// HealthProbePath is the well-known path the health probes of a
+// HealthCheckConn read. It is never written: the NoNode error of the read is
+// the answer of a healthy topology server.
+const HealthProbePath = "health_probe"
+
+// HealthCheckConn is a Conn that probes its topology server in the
+// background, with a cheap Get of HealthProbePath, and re-establishes the
+// connection once a number of consecutive probes failed. The health of the
+// connection is exported in the TopologyConnHealthy stat, so a broken
+// connection is noticed before the next operation hangs on it.
+//
+// The connection is only replaced once a new one answers a probe. The
+// operations started after that use the new one, while the old one is only
+// closed once the locks, watches and elections using it are released, so a
+// transient failure of the probes doesn't interrupt them.
+type HealthCheckConn struct {
+	cell     string
+	create   func() (Conn, error)
+	interval time.Duration
+	timeout  time.Duration
+	failures int
+
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+
+	// mu protects conn and onIdentityChange.
+	mu   sync.Mutex
+	conn *healthCheckedConn
+	// onIdentityChange are called after the client identity may have
+	// changed, including when the connection is replaced.
+	onIdentityChange []func()
+}
+
+// NewHealthCheckConn returns a HealthCheckConn for conn, which probes it
+// every interval, failing the probes which take longer than timeout, and
+// replaces it once failures consecutive probes failed. create opens a new
+// connection to the same topology server, to replace conn with.
+func NewHealthCheckConn(cell string, conn Conn, create func() (Conn, error), interval, timeout time.Duration, failures int) *HealthCheckConn {
+	hc := &HealthCheckConn{
+		cell:     cell,
+		create:   create,
+		interval: interval,
+		timeout:  timeout,
+		failures: max(failures, 1),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+		conn:     &healthCheckedConn{Conn: conn},
+	}
+	topoConnHealthy.Set(cell, 1)
+	go hc.run()
+	return hc
+}
+
+func (hc *HealthCheckConn) run() {
+	defer close(hc.done)
+
+	ticker := time.NewTicker(hc.interval)
+	defer ticker.Stop()
+	failures := 0
+	for {
+		select {
+		case <-hc.stop:
+			return
+		case <-ticker.C:
+		}
+
+		err := hc.probe(hc.current())
+		if err == nil {
+			failures = 0
+			topoConnHealthy.Set(hc.cell, 1)
+			continue
+		}
+		failures++
+		topoConnHealthy.Set(hc.cell, 0)
+		if failures < hc.failures {
+			log.Warningf("Health probe of the topology server of cell %v failed (%v/%v): %v", hc.cell, failures, hc.failures, err)
+			continue
+		}
+		log.Warningf("Health probe of the topology server of cell %v failed (%v/%v), reconnecting: %v", hc.cell, failures, hc.failures, err)
+		if hc.reconnect() {
+			failures = 0
+		}
+	}
+}
+
+// probe returns the error of a read of HealthProbePath with conn, if the
+// topology server did not answer it.
+func (hc *HealthCheckConn) probe(conn Conn) error {
+	ctx, cancel := context.WithTimeout(context.Background(), hc.timeout)
+	defer cancel()
+
+	_, _, err := conn.Get(ctx, HealthProbePath)
+	if err == nil || IsErrType(err, NoNode) {
+		return nil
+	}
+	return err
+}
+
+// reconnect replaces the connection with a new one, if it answers a probe,
+// and returns whether it did.
+func (hc *HealthCheckConn) reconnect() bool {
+	conn, err := hc.create()
+	if err != nil {
+		log.Warningf("Cannot reconnect to the topology server of cell %v: %v", hc.cell, err)
+		return false
+	}
+	if err := hc.probe(conn); err != nil {
+		conn.Close()
+		log.Warningf("Cannot reconnect to the topology server of cell %v: %v", hc.cell, err)
+		return false
+	}
+
+	hc.mu.Lock()
+	old := hc.conn
+	hc.conn = &healthCheckedConn{Conn: conn}
+	onIdentityChange := hc.onIdentityChange
+	for _, f := range onIdentityChange {
+		onClientIdentityChange(conn, f)
+	}
+	hc.mu.Unlock()
+	old.retire()
+	for _, f := range onIdentityChange {
+		f()
+	}
+
+	topoConnHealthy.Set(hc.cell, 1)
+	topoConnReconnects.Add(hc.cell, 1)
+	log.Infof("Reconnected to the topology server of cell %v", hc.cell)
+	return true
+}
+
+// ClientIdentity is part of the ClientIdentifier interface. It returns the
+// identity the current connection presents.
+func (hc *HealthCheckConn) ClientIdentity() string {
+	return clientIdentity(hc.current().Conn)
+}
+
+// OnClientIdentityChange is part of the ClientIdentityNotifier interface.
+func (hc *HealthCheckConn) OnClientIdentityChange(f func()) {
+	hc.mu.Lock()
+	defer hc.mu.Unlock()
+	hc.onIdentityChange = append(hc.onIdentityChange, f)
+	onClientIdentityChange(hc.conn.Conn, f)
+}
+
+// current returns the connection the operations use.
+func (hc *HealthCheckConn) current() *healthCheckedConn {
+	hc.mu.Lock()
+	defer hc.mu.Unlock()
+	return hc.conn
+}
+
+// acquire returns the connection the operations use, for a lock, watch or
+// election which keeps using it until it releases it.
+func (hc *HealthCheckConn) acquire() *healthCheckedConn {
+	hc.mu.Lock()
+	defer hc.mu.Unlock()
+	hc.conn.acquire()
+	return hc.conn
+}
+
+// ListDir is part of the Conn interface.
+func (hc *HealthCheckConn) ListDir(ctx context.Context, dirPath string, full bool) ([]DirEntry, error) {
+	return hc.current().ListDir(ctx, dirPath, full)
+}
+
+// Create is part of the Conn interface.
+func (hc *HealthCheckConn) Create(ctx context.Context, filePath string, contents []byte) (Version, error) {
+	return hc.current().Create(ctx, filePath, contents)
+}
+
+// Update is part of the Conn interface.
+func (hc *HealthCheckConn) Update(ctx context.Context, filePath string, contents []byte, version Version) (Version, error) {
+	return hc.current().Update(ctx, filePath, contents, version)
+}
+
+// Get is part of the Conn interface.
+func (hc *HealthCheckConn) Get(ctx context.Context, filePath string) ([]byte, Version, error) {
+	return hc.current().Get(ctx, filePath)
+}
+
+// GetVersion is part of the Conn interface.
+func (hc *HealthCheckConn) GetVersion(ctx context.Context, filePath string, version int64) ([]byte, error) {
+	return hc.current().GetVersion(ctx, filePath, version)
+}
+
+// List is part of the Conn interface.
+func (hc *HealthCheckConn) List(ctx context.Context, filePathPrefix string) ([]KVInfo, error) {
+	return hc.current().List(ctx, filePathPrefix)
+}
+
+// Delete is part of the Conn interface.
+func (hc *HealthCheckConn) Delete(ctx context.Context, filePath string, version Version) error {
+	return hc.current().Delete(ctx, filePath, version)
+}
+
+// Lock is part of the Conn interface.
+func (hc *HealthCheckConn) Lock(ctx context.Context, dirPath, contents string) (LockDescriptor, error) {
+	conn := hc.acquire()
+	return conn.lockDescriptor(conn.Lock(ctx, dirPath, contents))
+}
+
+// TryLock is part of the Conn interface.
+func (hc *HealthCheckConn) TryLock(ctx context.Context, dirPath, contents string) (LockDescriptor, error) {
+	conn := hc.acquire()
+	return conn.lockDescriptor(conn.TryLock(ctx, dirPath, contents))
+}
+
+// LockNameWithTTL is part of the NamedLockConn interface.
+func (hc *HealthCheckConn) LockNameWithTTL(ctx context.Context, dirPath, contents string, ttl time.Duration) (LockDescriptor, error) {
+	conn := hc.acquire()
+	return conn.lockDescriptor(lockNameWithTTL(ctx, conn.Conn, dirPath, contents, ttl))
+}
+
+// GetLock is part of the LockHolderConn interface.
+func (hc *HealthCheckConn) GetLock(ctx context.Context, dirPath string) (*LockInfo, error) {
+	return getLock(ctx, hc.current(), dirPath)
+}
+
+// ForceUnlock is part of the LockHolderConn interface.
+func (hc *HealthCheckConn) ForceUnlock(ctx context.Context, dirPath, contents string) error {
+	return forceUnlock(ctx, hc.current(), dirPath, contents)
+}
+
+// Watch is part of the Conn interface.
+func (hc *HealthCheckConn) Watch(ctx context.Context, filePath string) (*WatchData, <-chan *WatchData, error) {
+	conn := hc.acquire()
+	current, changes, err := conn.Watch(ctx, filePath)
+	if err != nil {
+		conn.release()
+		return nil, nil, err
+	}
+	return current, releaseWhenClosed(changes, conn.release), nil
+}
+
+// WatchRecursive is part of the Conn interface.
+func (hc *HealthCheckConn) WatchRecursive(ctx context.Context, path string) ([]*WatchDataRecursive, <-chan *WatchDataRecursive, error) {
+	conn := hc.acquire()
+	current, changes, err := conn.WatchRecursive(ctx, path)
+	if err != nil {
+		conn.release()
+		return nil, nil, err
+	}
+	return current, releaseWhenClosed(changes, conn.release), nil
+}
+
+// NewLeaderParticipation is part of the Conn interface.
+func (hc *HealthCheckConn) NewLeaderParticipation(name, id string) (LeaderParticipation, error) {
+	return hc.NewLeaderParticipationWithTTL(name, id, 0)
+}
+
+// NewLeaderParticipationWithTTL is part of the LeaderParticipationTTLConn
+// interface.
+func (hc *HealthCheckConn) NewLeaderParticipationWithTTL(name, id string, ttl time.Duration) (LeaderParticipation, error) {
+	conn := hc.current()
+	mp, err := newLeaderParticipationWithTTL(conn.Conn, name, id, ttl)
+	if err != nil {
+		return nil, err
+	}
+	return &healthCheckedLeaderParticipation{LeaderParticipation: mp, conn: conn}, nil
+}
+
+// Close is part of the Conn interface. It stops the health probes.
+func (hc *HealthCheckConn) Close() {
+	hc.closeOnce.Do(func() {
+		close(hc.stop)
+		<-hc.done
+		hc.current().Close()
+	})
+}
+
+// healthCheckedConn is a connection of a HealthCheckConn, with the count of
+// the locks, watches and elections using it, so that it is only closed once
+// they are all released after it was replaced.
+type healthCheckedConn struct {
+	Conn
+
+	// mu protects the following fields.
+	mu sync.Mutex
+	// users is the count of the locks, watches and elections using the
+	// connection.
+	users int
+	// retired is whether the connection was replaced, and is closed once
+	// it has no users.
+	retired bool
+	// closed is whether the connection was closed.
+	closed bool
+}
+
+func (c *healthCheckedConn) acquire() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.users++
+}
+
+func (c *healthCheckedConn) release() {
+	c.mu.Lock()
+	c.users--
+	closeConn := c.retired && c.users == 0 && !c.closed
+	c.closed = c.closed || closeConn
+	c.mu.Unlock()
+	if closeConn {
+		c.Conn.Close()
+	}
+}
+
+// retire closes the connection once it has no users.
+func (c *healthCheckedConn) retire() {
+	c.mu.Lock()
+	c.retired = true
+	closeConn := c.users == 0 && !c.closed
+	c.closed = c.closed || closeConn
+	c.mu.Unlock()
+	if closeConn {
+		c.Conn.Close()
+	}
+}
+
+// lockDescriptor returns ld, releasing the connection when it is unlocked,
+// or releases it right away if the lock failed.
+func (c *healthCheckedConn) lockDescriptor(ld LockDescriptor, err error) (LockDescriptor, error) {
+	if err != nil {
+		c.release()
+		return nil, err
+	}
+	return &healthCheckedLockDescriptor{LockDescriptor: ld, conn: c}, nil
+}
+
+// healthCheckedLockDescriptor is a lock taken through a HealthCheckConn.
+type healthCheckedLockDescriptor struct {
+	LockDescriptor
+	conn        *healthCheckedConn
+	releaseOnce sync.Once
+}
+
+// Unlock is part of the LockDescriptor interface.
+func (ld *healthCheckedLockDescriptor) Unlock(ctx context.Context) error {
+	err := ld.LockDescriptor.Unlock(ctx)
+	ld.releaseOnce.Do(ld.conn.release)
+	return err
+}
+
+// healthCheckedLeaderParticipation is an election run through a
+// HealthCheckConn. It uses its connection from its first WaitForLeadership
+// until Stop, as the participations only read the current primary don't need
+// to be stopped.
+type healthCheckedLeaderParticipation struct {
+	LeaderParticipation
+	conn *healthCheckedConn
+
+	// mu protects campaigning.
+	mu sync.Mutex
+	// campaigning is whether the participation uses the connection.
+	campaigning bool
+}
+
+// WaitForLeadership is part of the LeaderParticipation interface.
+func (mp *healthCheckedLeaderParticipation) WaitForLeadership() (context.Context, error) {
+	mp.mu.Lock()
+	if !mp.campaigning {
+		mp.campaigning = true
+		mp.conn.acquire()
+	}
+	mp.mu.Unlock()
+	return mp.LeaderParticipation.WaitForLeadership()
+}
+
+// WaitForNewLeader is part of the LeaderParticipation interface.
+func (mp *healthCheckedLeaderParticipation) WaitForNewLeader(ctx context.Context) (<-chan string, error) {
+	mp.conn.acquire()
+	leaders, err := mp.LeaderParticipation.WaitForNewLeader(ctx)
+	if err != nil {
+		mp.conn.release()
+		return nil, err
+	}
+	return releaseWhenClosed(leaders, mp.conn.release), nil
+}
+
+// ForceResign is part of the ForceResignLeaderParticipation interface.
+func (mp *healthCheckedLeaderParticipation) ForceResign(ctx context.Context, id string) error {
+	return forceResign(ctx, mp.LeaderParticipation, id)
+}
+
+// Stop is part of the LeaderParticipation interface.
+func (mp *healthCheckedLeaderParticipation) Stop() {
+	mp.LeaderParticipation.Stop()
+
+	mp.mu.Lock()
+	defer mp.mu.Unlock()
+	if mp.campaigning {
+		mp.campaigning = false
+		mp.conn.release()
+	}
+}
+
+// releaseWhenClosed returns a channel forwarding the values of in, which
+// calls release once in is closed.
+func releaseWhenClosed[T any](in <-chan T, release func()) <-chan T {
+	out := make(chan T)
+	go func() {
+		defer release()
+		defer close(out)
+		for v := range in {
+			out <- v
+		}
+	}()
+	return out
+}
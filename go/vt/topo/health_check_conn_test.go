@@ -0,0 +1,151 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topo_test
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+)
+
+func TestHealthCheckConn(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	_, factory := memorytopo.NewServerAndFactory(ctx, "zone1")
+
+	conn, err := factory.Create("zone1", "", "")
+	require.NoError(t, err)
+	create := func() (topo.Conn, error) {
+		return factory.Create("zone1", "", "")
+	}
+	hc := topo.NewHealthCheckConn("zone1", conn, create, 10*time.Millisecond, time.Second, 1)
+	defer hc.Close()
+
+	healthy := func() int64 {
+		return stats.TakeSnapshot().Get("TopologyConnHealthy", "zone1")
+	}
+	snapshot := stats.TakeSnapshot()
+	_, err = hc.Create(ctx, "file", []byte("contents"))
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, healthy())
+
+	// A broken connection is replaced.
+	conn.Close()
+	assert.Eventually(t, func() bool {
+		return snapshot.Diff().Get("TopologyConnReconnects", "zone1") == 1
+	}, 10*time.Second, 10*time.Millisecond)
+	contents, _, err := hc.Get(ctx, "file")
+	require.NoError(t, err)
+	assert.Equal(t, "contents", string(contents))
+	assert.EqualValues(t, 1, healthy())
+
+	// An unreachable topo server is reported until it answers again.
+	factory.SetError(fmt.Errorf("topo server down"))
+	assert.Eventually(t, func() bool {
+		return healthy() == 0
+	}, 10*time.Second, 10*time.Millisecond)
+	factory.SetError(nil)
+	assert.Eventually(t, func() bool {
+		return healthy() == 1
+	}, 10*time.Second, 10*time.Millisecond)
+	_, _, err = hc.Get(ctx, "file")
+	require.NoError(t, err)
+}
+
+// flakyProbeConn is a Conn whose health probes fail on demand, and which
+// records when it is closed.
+type flakyProbeConn struct {
+	topo.Conn
+	failures atomic.Int32
+	closed   atomic.Bool
+}
+
+func (c *flakyProbeConn) Get(ctx context.Context, filePath string) ([]byte, topo.Version, error) {
+	if filePath == topo.HealthProbePath && c.failures.Add(-1) >= 0 {
+		return nil, nil, topo.NewError(topo.Timeout, filePath)
+	}
+	return c.Conn.Get(ctx, filePath)
+}
+
+func (c *flakyProbeConn) Close() {
+	c.closed.Store(true)
+	c.Conn.Close()
+}
+
+func TestHealthCheckConnFailures(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	_, factory := memorytopo.NewServerAndFactory(ctx, "zone1")
+
+	memConn, err := factory.Create("zone1", "", "")
+	require.NoError(t, err)
+	conn := &flakyProbeConn{Conn: memConn}
+	create := func() (topo.Conn, error) {
+		return factory.Create("zone1", "", "")
+	}
+	hc := topo.NewHealthCheckConn("zone1", conn, create, 10*time.Millisecond, time.Second, 3)
+	defer hc.Close()
+
+	_, err = hc.Create(ctx, "dir/file", []byte("contents"))
+	require.NoError(t, err)
+	ld, err := hc.Lock(ctx, "dir", "test")
+	require.NoError(t, err)
+	watchCtx, watchCancel := context.WithCancel(ctx)
+	defer watchCancel()
+	_, changes, err := hc.Watch(watchCtx, "dir/file")
+	require.NoError(t, err)
+
+	// Fewer consecutive failed probes than needed don't replace the
+	// connection.
+	snapshot := stats.TakeSnapshot()
+	conn.failures.Store(2)
+	assert.Eventually(t, func() bool {
+		return conn.failures.Load() < 0 && stats.TakeSnapshot().Get("TopologyConnHealthy", "zone1") == 1
+	}, 10*time.Second, 10*time.Millisecond)
+	assert.Zero(t, snapshot.Diff().Get("TopologyConnReconnects", "zone1"))
+
+	// Enough of them do, but the connection is only closed once the lock
+	// and the watch using it are released.
+	conn.failures.Store(1 << 30)
+	assert.Eventually(t, func() bool {
+		return snapshot.Diff().Get("TopologyConnReconnects", "zone1") == 1
+	}, 10*time.Second, 10*time.Millisecond)
+	conn.failures.Store(0)
+	assert.False(t, conn.closed.Load())
+	require.NoError(t, ld.Check(ctx))
+	_, err = hc.Update(ctx, "dir/file", []byte("new contents"), nil)
+	require.NoError(t, err)
+	wd := <-changes
+	require.NoError(t, wd.Err)
+	assert.Equal(t, "new contents", string(wd.Contents))
+
+	require.NoError(t, ld.Unlock(ctx))
+	assert.False(t, conn.closed.Load())
+	watchCancel()
+	for range changes {
+	}
+	assert.Eventually(t, conn.closed.Load, 10*time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,198 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topo
+
+import (
+	"context"
+	"time"
+
+	"vitess.io/vitess/go/stats"
+)
+
+var _ Conn = (*HedgingConn)(nil)
+
+var topoHedgedReads = stats.NewCountersWithMultiLabels(
+	"TopologyHedgedReads",
+	"TopologyHedgedReads second reads issued because the first one was slower than the hedging delay, by result: Sent for each of them, Won when the second read returned first",
+	[]string{"Operation", "Cell", "Result"})
+
+// Results of the hedged reads.
+const (
+	hedgeSent = "Sent"
+	hedgeWon  = "Won"
+)
+
+// HedgingConn is a Conn that hedges its reads: when a read has not returned
+// after the hedging delay, it issues the same read a second time, returns the
+// result of whichever read returns first, and cancels the other. It trims the
+// tail latency of the reads served by a slow member of the topology server,
+// at the cost of more reads.
+//
+// Only the reads are hedged: ListDir, Get, GetVersion and List. The other
+// operations are forwarded as is.
+type HedgingConn struct {
+	cell  string
+	conn  Conn
+	delay time.Duration
+}
+
+// NewHedgingConn returns a HedgingConn for conn, which hedges the reads that
+// take longer than delay.
+func NewHedgingConn(cell string, conn Conn, delay time.Duration) *HedgingConn {
+	return &HedgingConn{
+		cell:  cell,
+		conn:  conn,
+		delay: delay,
+	}
+}
+
+// hedge calls read, and calls it a second time if it has not returned after
+// the hedging delay, returning the result of the first call to return.
+func hedge[T any](ctx context.Context, hc *HedgingConn, operation string, read func(ctx context.Context) (T, error)) (T, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type result struct {
+		value  T
+		err    error
+		hedged bool
+	}
+	// The results channel is buffered, so that the read which loses does not
+	// block once hedge has returned.
+	results := make(chan result, 2)
+	call := func(hedged bool) {
+		value, err := read(ctx)
+		results <- result{value: value, err: err, hedged: hedged}
+	}
+
+	go call(false)
+	timer := time.NewTimer(hc.delay)
+	defer timer.Stop()
+	select {
+	case r := <-results:
+		return r.value, r.err
+	case <-timer.C:
+	}
+
+	topoHedgedReads.Add([]string{operation, hc.cell, hedgeSent}, 1)
+	go call(true)
+	r := <-results
+	if r.hedged {
+		topoHedgedReads.Add([]string{operation, hc.cell, hedgeWon}, 1)
+	}
+	return r.value, r.err
+}
+
+// ListDir is part of the Conn interface.
+func (hc *HedgingConn) ListDir(ctx context.Context, dirPath string, full bool) ([]DirEntry, error) {
+	return hedge(ctx, hc, "ListDir", func(ctx context.Context) ([]DirEntry, error) {
+		return hc.conn.ListDir(ctx, dirPath, full)
+	})
+}
+
+// Create is part of the Conn interface.
+func (hc *HedgingConn) Create(ctx context.Context, filePath string, contents []byte) (Version, error) {
+	return hc.conn.Create(ctx, filePath, contents)
+}
+
+// Update is part of the Conn interface.
+func (hc *HedgingConn) Update(ctx context.Context, filePath string, contents []byte, version Version) (Version, error) {
+	return hc.conn.Update(ctx, filePath, contents, version)
+}
+
+// Get is part of the Conn interface.
+func (hc *HedgingConn) Get(ctx context.Context, filePath string) ([]byte, Version, error) {
+	type getResult struct {
+		contents []byte
+		version  Version
+	}
+	r, err := hedge(ctx, hc, "Get", func(ctx context.Context) (getResult, error) {
+		contents, version, err := hc.conn.Get(ctx, filePath)
+		return getResult{contents: contents, version: version}, err
+	})
+	return r.contents, r.version, err
+}
+
+// GetVersion is part of the Conn interface.
+func (hc *HedgingConn) GetVersion(ctx context.Context, filePath string, version int64) ([]byte, error) {
+	return hedge(ctx, hc, "GetVersion", func(ctx context.Context) ([]byte, error) {
+		return hc.conn.GetVersion(ctx, filePath, version)
+	})
+}
+
+// List is part of the Conn interface.
+func (hc *HedgingConn) List(ctx context.Context, filePathPrefix string) ([]KVInfo, error) {
+	return hedge(ctx, hc, "List", func(ctx context.Context) ([]KVInfo, error) {
+		return hc.conn.List(ctx, filePathPrefix)
+	})
+}
+
+// Delete is part of the Conn interface.
+func (hc *HedgingConn) Delete(ctx context.Context, filePath string, version Version) error {
+	return hc.conn.Delete(ctx, filePath, version)
+}
+
+// Lock is part of the Conn interface.
+func (hc *HedgingConn) Lock(ctx context.Context, dirPath, contents string) (LockDescriptor, error) {
+	return hc.conn.Lock(ctx, dirPath, contents)
+}
+
+// TryLock is part of the Conn interface.
+func (hc *HedgingConn) TryLock(ctx context.Context, dirPath, contents string) (LockDescriptor, error) {
+	return hc.conn.TryLock(ctx, dirPath, contents)
+}
+
+// LockNameWithTTL is part of the NamedLockConn interface.
+func (hc *HedgingConn) LockNameWithTTL(ctx context.Context, dirPath, contents string, ttl time.Duration) (LockDescriptor, error) {
+	return lockNameWithTTL(ctx, hc.conn, dirPath, contents, ttl)
+}
+
+// GetLock is part of the LockHolderConn interface.
+func (hc *HedgingConn) GetLock(ctx context.Context, dirPath string) (*LockInfo, error) {
+	return getLock(ctx, hc.conn, dirPath)
+}
+
+// ForceUnlock is part of the LockHolderConn interface.
+func (hc *HedgingConn) ForceUnlock(ctx context.Context, dirPath, contents string) error {
+	return forceUnlock(ctx, hc.conn, dirPath, contents)
+}
+
+// Watch is part of the Conn interface.
+func (hc *HedgingConn) Watch(ctx context.Context, filePath string) (*WatchData, <-chan *WatchData, error) {
+	return hc.conn.Watch(ctx, filePath)
+}
+
+// WatchRecursive is part of the Conn interface.
+func (hc *HedgingConn) WatchRecursive(ctx context.Context, path string) ([]*WatchDataRecursive, <-chan *WatchDataRecursive, error) {
+	return hc.conn.WatchRecursive(ctx, path)
+}
+
+// NewLeaderParticipation is part of the Conn interface.
+func (hc *HedgingConn) NewLeaderParticipation(name, id string) (LeaderParticipation, error) {
+	return hc.conn.NewLeaderParticipation(name, id)
+}
+
+// NewLeaderParticipationWithTTL is part of the LeaderParticipationTTLConn
+// interface.
+func (hc *HedgingConn) NewLeaderParticipationWithTTL(name, id string, ttl time.Duration) (LeaderParticipation, error) {
+	return newLeaderParticipationWithTTL(hc.conn, name, id, ttl)
+}
+
+// Close is part of the Conn interface.
+func (hc *HedgingConn) Close() {
+	hc.conn.Close()
+}
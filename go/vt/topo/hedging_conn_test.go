@@ -0,0 +1,80 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topo_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+)
+
+// slowFirstGetConn is a Conn whose first Get hangs until it is canceled.
+type slowFirstGetConn struct {
+	topo.Conn
+	gets     atomic.Int32
+	canceled chan struct{}
+}
+
+func (c *slowFirstGetConn) Get(ctx context.Context, filePath string) ([]byte, topo.Version, error) {
+	if c.gets.Add(1) == 1 {
+		<-ctx.Done()
+		close(c.canceled)
+		return nil, nil, ctx.Err()
+	}
+	return c.Conn.Get(ctx, filePath)
+}
+
+func TestHedgingConn(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	_, factory := memorytopo.NewServerAndFactory(ctx, "zone1")
+
+	conn, err := factory.Create("zone1", "", "")
+	require.NoError(t, err)
+	_, err = conn.Create(ctx, "file", []byte("contents"))
+	require.NoError(t, err)
+
+	// The fast reads are not hedged.
+	hc := topo.NewHedgingConn("zone1", conn, time.Minute)
+	snapshot := stats.TakeSnapshot()
+	contents, _, err := hc.Get(ctx, "file")
+	require.NoError(t, err)
+	assert.Equal(t, "contents", string(contents))
+	assert.Zero(t, snapshot.Diff()["TopologyHedgedReads"])
+
+	// The slow ones are, and the first read is canceled.
+	slow := &slowFirstGetConn{Conn: conn, canceled: make(chan struct{})}
+	hc = topo.NewHedgingConn("zone1", slow, 10*time.Millisecond)
+	snapshot = stats.TakeSnapshot()
+	contents, _, err = hc.Get(ctx, "file")
+	require.NoError(t, err)
+	assert.Equal(t, "contents", string(contents))
+	assert.Equal(t, map[string]int64{"Get.zone1.Sent": 1, "Get.zone1.Won": 1}, snapshot.Diff()["TopologyHedgedReads"])
+	select {
+	case <-slow.canceled:
+	case <-time.After(10 * time.Second):
+		t.Fatal("the slow read was not canceled")
+	}
+}
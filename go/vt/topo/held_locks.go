@@ -0,0 +1,175 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"time"
+
+	"vitess.io/vitess/go/vt/vterrors"
+
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// HeldLock is a lock held on a resource of the topology, as returned by
+// GetHeldLocks.
+type HeldLock struct {
+	// Path is the directory of the locked resource in the global cell.
+	Path string
+
+	// Shared is set for shared locks.
+	Shared bool
+
+	// Lock describes the holder of the lock. It is nil when the contents of
+	// the lock can't be parsed, e.g. for locks not taken by a Server.
+	Lock *Lock
+
+	// Contents are the raw contents of the lock.
+	Contents string
+
+	// TTL is the TTL of the lease or session backing the lock reported by
+	// the topology server, if any.
+	TTL time.Duration
+
+	// Stale is set for the locks whose holder no longer keeps the lease or
+	// session backing them alive. ForceUnlock can break the exclusive ones,
+	// and the exclusive locks break the shared ones.
+	Stale bool
+}
+
+// GetHeldLocks returns the locks held on the resource locked on the given
+// directory of the global cell: its exclusive lock first, if any, then its
+// shared locks. Returns ErrNoImplementation if the topology server can't
+// report the holders of its locks, see LockHolderConn.
+func (ts *Server) GetHeldLocks(ctx context.Context, dirPath string) ([]*HeldLock, error) {
+	var locks []*HeldLock
+
+	info, err := getLock(ctx, ts.globalCell, dirPath)
+	switch {
+	case err == nil:
+		lock := newHeldLock(dirPath, false, info.Contents, info.TTL)
+		lock.Stale = info.Stale
+		locks = append(locks, lock)
+	case IsErrType(err, NoNode):
+	default:
+		return nil, err
+	}
+
+	sharedLocksPath := path.Join(dirPath, SharedLocksPath)
+	entries, err := ts.globalCell.ListDir(ctx, sharedLocksPath, false /*full*/)
+	switch {
+	case err == nil:
+	case IsErrType(err, NoNode):
+		return locks, nil
+	default:
+		return nil, err
+	}
+	for _, entry := range entries {
+		info, err := getLock(ctx, ts.globalCell, path.Join(sharedLocksPath, entry.Name))
+		if err != nil {
+			if IsErrType(err, NoNode) {
+				// Released, possibly since we listed them.
+				continue
+			}
+			return nil, err
+		}
+		lock := newHeldLock(dirPath, true, info.Contents, info.TTL)
+		if lock.Lock == nil {
+			continue
+		}
+		lock.Stale = info.Stale
+		locks = append(locks, lock)
+	}
+	return locks, nil
+}
+
+// ForceUnlock breaks the exclusive lock held on the given directory of the
+// global cell, and returns its holder. The lock is only broken if it is
+// stale, i.e. the lease or session backing it is no longer kept alive by its
+// holder, whose process is gone, and if it was taken at least minAge ago. The
+// locks of live holders, even stuck ones, are never broken: they are
+// released by stopping their process. Locks whose contents can't be parsed
+// have no known age, and are never broken either. The stale shared locks are
+// broken by the exclusive locks waiting for them. Returns ErrNoImplementation
+// if the topology server can't report the holders of its locks, and break
+// them, see LockHolderConn.
+func (ts *Server) ForceUnlock(ctx context.Context, dirPath string, minAge time.Duration) (*HeldLock, error) {
+	info, err := getLock(ctx, ts.globalCell, dirPath)
+	if err != nil {
+		return nil, err
+	}
+	lock := newHeldLock(dirPath, false, info.Contents, info.TTL)
+	lock.Stale = info.Stale
+	if lock.Lock == nil {
+		return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "cannot parse the lock on %v, refusing to break it: %q", dirPath, info.Contents)
+	}
+	if !lock.Stale {
+		return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "lock on %v is held by %v on %v, which still keeps its session alive: refusing to break it", dirPath, lock.Lock.UserName, lock.Lock.HostName)
+	}
+	taken, err := time.Parse(time.RFC3339, lock.Lock.Time)
+	if err != nil {
+		return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "cannot parse the time of the lock on %v, refusing to break it: %q", dirPath, lock.Lock.Time)
+	}
+	if age := time.Since(taken); age < minAge {
+		return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "lock on %v was taken by %v on %v %v ago, which is less than %v: refusing to break it", dirPath, lock.Lock.UserName, lock.Lock.HostName, age.Truncate(time.Second), minAge)
+	}
+	if err := forceUnlock(ctx, ts.globalCell, dirPath, info.Contents); err != nil {
+		return nil, err
+	}
+	return lock, nil
+}
+
+// getLock returns the holder of the lock on dirPath with conn, see
+// LockHolderConn.GetLock. Returns ErrNoImplementation if conn doesn't
+// implement LockHolderConn. The Conns wrapping another Conn use it to forward
+// GetLock.
+func getLock(ctx context.Context, conn Conn, dirPath string) (*LockInfo, error) {
+	lc, ok := conn.(LockHolderConn)
+	if !ok {
+		return nil, NewError(NoImplementation, fmt.Sprintf("inspecting the lock on %v", dirPath))
+	}
+	return lc.GetLock(ctx, dirPath)
+}
+
+// forceUnlock breaks the lock on dirPath with conn, see
+// LockHolderConn.ForceUnlock. Returns ErrNoImplementation if conn doesn't
+// implement LockHolderConn. The Conns wrapping another Conn use it to forward
+// ForceUnlock.
+func forceUnlock(ctx context.Context, conn Conn, dirPath, contents string) error {
+	lc, ok := conn.(LockHolderConn)
+	if !ok {
+		return NewError(NoImplementation, fmt.Sprintf("breaking the lock on %v", dirPath))
+	}
+	return lc.ForceUnlock(ctx, dirPath, contents)
+}
+
+func newHeldLock(dirPath string, shared bool, contents string, ttl time.Duration) *HeldLock {
+	lock := &HeldLock{
+		Path:     dirPath,
+		Shared:   shared,
+		Contents: contents,
+		TTL:      ttl,
+	}
+	l := &Lock{}
+	if err := json.Unmarshal([]byte(contents), l); err == nil {
+		lock.Lock = l
+	}
+	return lock
+}
@@ -0,0 +1,153 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topo_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+)
+
+func TestGetHeldLocks(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "zone1")
+	defer ts.Close()
+
+	const shardPath = "keyspaces/ks/shards/-"
+	_, err := ts.GetOrCreateShard(ctx, "ks", "-")
+	require.NoError(t, err)
+
+	locks, err := ts.GetHeldLocks(ctx, shardPath)
+	require.NoError(t, err)
+	assert.Empty(t, locks)
+
+	_, unlock, err := ts.LockShard(ctx, "ks", "-", "reparent")
+	require.NoError(t, err)
+	locks, err = ts.GetHeldLocks(ctx, shardPath)
+	require.NoError(t, err)
+	require.Len(t, locks, 1)
+	assert.Equal(t, shardPath, locks[0].Path)
+	assert.False(t, locks[0].Shared)
+	require.NotNil(t, locks[0].Lock)
+	assert.Equal(t, "reparent", locks[0].Lock.Action)
+	assert.NotEmpty(t, locks[0].Lock.Time)
+	unlock(&err)
+	require.NoError(t, err)
+
+	_, unlockShared, err := ts.LockShardShared(ctx, "ks", "-", "validate")
+	require.NoError(t, err)
+	locks, err = ts.GetHeldLocks(ctx, shardPath)
+	require.NoError(t, err)
+	require.Len(t, locks, 1)
+	assert.True(t, locks[0].Shared)
+	require.NotNil(t, locks[0].Lock)
+	assert.Equal(t, "validate", locks[0].Lock.Action)
+	assert.False(t, locks[0].Stale)
+	unlockShared(&err)
+	require.NoError(t, err)
+
+	locks, err = ts.GetHeldLocks(ctx, shardPath)
+	require.NoError(t, err)
+	assert.Empty(t, locks)
+}
+
+func TestHeldLocksNoImplementation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	_, factory := memorytopo.NewServerAndFactory(ctx, "zone1")
+
+	ts, err := topo.NewWithFactory(&plainFactory{Factory: factory}, "", "")
+	require.NoError(t, err)
+	defer ts.Close()
+
+	// The topology servers which don't report the holders of their locks
+	// fail, rather than report no lock, and can't break them.
+	_, err = ts.GetOrCreateShard(ctx, "ks", "-")
+	require.NoError(t, err)
+	_, unlock, err := ts.LockShard(ctx, "ks", "-", "reparent")
+	require.NoError(t, err)
+	_, err2 := ts.GetHeldLocks(ctx, "keyspaces/ks/shards/-")
+	assert.True(t, topo.IsErrType(err2, topo.NoImplementation), "expected NoImplementation, got %v", err2)
+	_, err2 = ts.ForceUnlock(ctx, "keyspaces/ks/shards/-", 0)
+	assert.True(t, topo.IsErrType(err2, topo.NoImplementation), "expected NoImplementation, got %v", err2)
+	unlock(&err)
+	require.NoError(t, err)
+}
+
+func TestForceUnlock(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts, factory := memorytopo.NewServerAndFactory(ctx, "zone1")
+	defer ts.Close()
+
+	const shardPath = "keyspaces/ks/shards/-"
+	_, err := ts.GetOrCreateShard(ctx, "ks", "-")
+	require.NoError(t, err)
+
+	_, err = ts.ForceUnlock(ctx, shardPath, 0)
+	assert.True(t, topo.IsErrType(err, topo.NoNode), "ForceUnlock(unlocked): %v", err)
+
+	// The lock of a live holder is never broken, however old.
+	lockCtx, unlock, err := ts.LockShard(ctx, "ks", "-", "busy")
+	require.NoError(t, err)
+	locks, err := ts.GetHeldLocks(ctx, shardPath)
+	require.NoError(t, err)
+	require.Len(t, locks, 1)
+	assert.False(t, locks[0].Stale)
+	_, err = ts.ForceUnlock(ctx, shardPath, 0)
+	assert.ErrorContains(t, err, "still keeps its session alive")
+	require.NoError(t, topo.CheckShardLocked(lockCtx, "ks", "-"))
+	var unlockErr error
+	unlock(&unlockErr)
+	require.NoError(t, unlockErr)
+
+	// The holder's process goes away, closing its connection, while it
+	// holds the lock.
+	holder, err := topo.NewWithFactory(factory, "" /*serverAddress*/, "" /*root*/)
+	require.NoError(t, err)
+	_, unlock, err = holder.LockShard(ctx, "ks", "-", "stuck")
+	require.NoError(t, err)
+	holder.Close()
+
+	locks, err = ts.GetHeldLocks(ctx, shardPath)
+	require.NoError(t, err)
+	require.Len(t, locks, 1)
+	assert.True(t, locks[0].Stale)
+
+	_, err = ts.ForceUnlock(ctx, shardPath, time.Hour)
+	assert.ErrorContains(t, err, "refusing to break it")
+
+	lock, err := ts.ForceUnlock(ctx, shardPath, 0)
+	require.NoError(t, err)
+	assert.Equal(t, "stuck", lock.Lock.Action)
+	assert.True(t, lock.Stale)
+
+	locks, err = ts.GetHeldLocks(ctx, shardPath)
+	require.NoError(t, err)
+	assert.Empty(t, locks)
+
+	unlockErr = nil
+	unlock(&unlockErr)
+	assert.Error(t, unlockErr)
+}
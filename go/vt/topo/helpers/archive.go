@@ -0,0 +1,363 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helpers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"sigs.k8s.io/yaml"
+
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// Topo archive formats.
+const (
+	ArchiveFormatJSON = "json"
+	ArchiveFormatYAML = "yaml"
+)
+
+// TopoArchive is a portable copy of topo files, where the records of known
+// types are rendered as JSON so they can be reviewed and edited.
+type TopoArchive struct {
+	Files []*TopoArchiveFile `json:"files"`
+}
+
+// TopoArchiveFile is a file of a TopoArchive.
+type TopoArchiveFile struct {
+	// Cell is the cell of the file, "global" for the global topo.
+	Cell string `json:"cell"`
+	// Path is the path of the file, relative to the root of the cell.
+	Path string `json:"path"`
+	// Type is the full name of the protobuf message stored in the file, if
+	// it is known.
+	Type string `json:"type,omitempty"`
+	// Value is the JSON encoding of the message, if its type is known.
+	Value json.RawMessage `json:"value,omitempty"`
+	// RawValue is the contents of the file, if its type is not known.
+	RawValue []byte `json:"raw_value,omitempty"`
+	// Version is the version of the file when it was exported.
+	Version string `json:"version,omitempty"`
+}
+
+// ExportOptions control ExportTopo.
+type ExportOptions struct {
+	// Filter, if set, only exports the files whose path, relative to the
+	// root of their cell, matches it.
+	Filter *regexp.Regexp
+	// Shallow only exports the files directly in dirPath, rather than all
+	// the files under it.
+	Shallow bool
+}
+
+// ExportTopo returns the files under dirPath, in the global cell or the
+// given cells, sorted by cell and path. dirPath is relative to the root
+// directory of the cells, and an empty dirPath exports everything.
+func ExportTopo(ctx context.Context, ts *topo.Server, cells []string, dirPath string, opts ExportOptions) ([]*vtctldatapb.TopologyFile, error) {
+	var files []*vtctldatapb.TopologyFile
+	for _, cell := range cells {
+		conn, err := ts.ConnForCell(ctx, cell)
+		if err != nil {
+			return nil, err
+		}
+
+		// dirPath may also be a single file.
+		if dirPath != "" {
+			data, version, err := conn.Get(ctx, dirPath)
+			switch {
+			case err == nil:
+				if opts.Filter == nil || opts.Filter.MatchString(dirPath) {
+					files = append(files, &vtctldatapb.TopologyFile{Cell: cell, Path: dirPath, Data: data, Version: version.String()})
+				}
+				continue
+			case !topo.IsErrType(err, topo.NoNode):
+				return nil, fmt.Errorf("Get(%v, %v): %w", cell, dirPath, err)
+			}
+		}
+
+		walk := walkFiles
+		if opts.Shallow {
+			walk = walkDirFiles
+		}
+		var cellFiles []*vtctldatapb.TopologyFile
+		err = walk(ctx, conn, dirPath, func(filePath string) error {
+			if opts.Filter != nil && !opts.Filter.MatchString(filePath) {
+				return nil
+			}
+			data, version, err := conn.Get(ctx, filePath)
+			switch {
+			case topo.IsErrType(err, topo.NoNode):
+				// Deleted since we listed its directory.
+				return nil
+			case err != nil:
+				return fmt.Errorf("Get(%v, %v): %w", cell, filePath, err)
+			}
+			cellFiles = append(cellFiles, &vtctldatapb.TopologyFile{Cell: cell, Path: filePath, Data: data, Version: version.String()})
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		sort.Slice(cellFiles, func(i, j int) bool { return cellFiles[i].Path < cellFiles[j].Path })
+		files = append(files, cellFiles...)
+	}
+	return files, nil
+}
+
+// walkDirFiles calls fn on the files directly in dirPath, unlike walkFiles
+// which calls it on all the files under it.
+func walkDirFiles(ctx context.Context, conn topo.Conn, dirPath string, fn func(filePath string) error) error {
+	entries, err := conn.ListDir(ctx, dirPath, true /* full */)
+	switch {
+	case topo.IsErrType(err, topo.NoNode):
+		return nil
+	case err != nil:
+		return err
+	}
+
+	for _, entry := range entries {
+		if entry.Ephemeral || entry.Type == topo.TypeDirectory {
+			continue
+		}
+		if err := fn(path.Join(dirPath, entry.Name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NewTopoArchive renders files for an archive.
+func NewTopoArchive(files []*vtctldatapb.TopologyFile) (*TopoArchive, error) {
+	archive := &TopoArchive{Files: make([]*TopoArchiveFile, 0, len(files))}
+	for _, file := range files {
+		af := &TopoArchiveFile{Cell: file.Cell, Path: file.Path, Version: file.Version}
+		if m := topo.NewContentProto(file.Path); m != nil {
+			if err := proto.Unmarshal(file.Data, m); err != nil {
+				return nil, fmt.Errorf("cannot decode %v:%v: %w", file.Cell, file.Path, err)
+			}
+			value, err := (protojson.MarshalOptions{UseProtoNames: true}).Marshal(m)
+			if err != nil {
+				return nil, fmt.Errorf("cannot render %v:%v: %w", file.Cell, file.Path, err)
+			}
+			af.Type = string(m.ProtoReflect().Descriptor().FullName())
+			af.Value = value
+		} else {
+			af.RawValue = file.Data
+		}
+		archive.Files = append(archive.Files, af)
+	}
+	return archive, nil
+}
+
+// TopologyFiles encodes the files of the archive as they are stored.
+func (archive *TopoArchive) TopologyFiles() ([]*vtctldatapb.TopologyFile, error) {
+	files := make([]*vtctldatapb.TopologyFile, 0, len(archive.Files))
+	for _, af := range archive.Files {
+		if af.Cell == "" || af.Path == "" {
+			return nil, fmt.Errorf("archive file %q has no cell or path", af.Cell+":"+af.Path)
+		}
+		file := &vtctldatapb.TopologyFile{Cell: af.Cell, Path: af.Path, Data: af.RawValue, Version: af.Version}
+		if af.Value != nil {
+			m := topo.NewContentProto(af.Path)
+			if m == nil {
+				return nil, fmt.Errorf("unknown topo protobuf type for %v:%v", af.Cell, af.Path)
+			}
+			if err := protojson.Unmarshal(af.Value, m); err != nil {
+				return nil, fmt.Errorf("cannot parse the value of %v:%v: %w", af.Cell, af.Path, err)
+			}
+			data, err := proto.Marshal(m)
+			if err != nil {
+				return nil, err
+			}
+			file.Data = data
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+// MarshalTopoArchive encodes an archive in the given format.
+func MarshalTopoArchive(archive *TopoArchive, format string) ([]byte, error) {
+	data, err := json.MarshalIndent(archive, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	switch format {
+	case ArchiveFormatJSON:
+		return data, nil
+	case ArchiveFormatYAML:
+		return yaml.JSONToYAML(data)
+	default:
+		return nil, fmt.Errorf("unknown archive format %q, expected %q or %q", format, ArchiveFormatJSON, ArchiveFormatYAML)
+	}
+}
+
+// UnmarshalTopoArchive decodes an archive in either format.
+func UnmarshalTopoArchive(data []byte) (*TopoArchive, error) {
+	// JSON is valid YAML.
+	data, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		return nil, err
+	}
+	archive := &TopoArchive{}
+	if err := json.Unmarshal(data, archive); err != nil {
+		return nil, err
+	}
+	return archive, nil
+}
+
+// ImportOptions control ImportTopo.
+type ImportOptions struct {
+	// CellMappings renames cells: the files of a cell that is a key are
+	// written to the cell of the value.
+	CellMappings map[string]string
+	// PathMappings rewrites paths: the longest key that is a prefix of the
+	// path of a file, on a directory boundary, is replaced with its value.
+	// Only the paths are rewritten, not the names stored in the records.
+	PathMappings map[string]string
+	// Overwrite replaces the files that already exist, which are otherwise
+	// skipped.
+	Overwrite bool
+	// CheckVersions only replaces the files that still have the version
+	// they were read with, whatever Overwrite is, and reports the others as
+	// conflicts. Files without a version, or whose cell or path was
+	// remapped, must not exist yet.
+	CheckVersions bool
+	// DryRun only reports what would be imported.
+	DryRun bool
+}
+
+// ImportResult lists the "<cell>:<path>" of the imported files.
+type ImportResult struct {
+	Created []string
+	Updated []string
+	Skipped []string
+	// Conflicts are the files that changed since they were read, when
+	// checking versions.
+	Conflicts []string
+}
+
+// ImportTopo writes files, typically returned by ExportTopo for another topo
+// server, to ts. The files of the global cell are written first, so an
+// archive can create the cells its other files are in.
+func ImportTopo(ctx context.Context, ts *topo.Server, files []*vtctldatapb.TopologyFile, opts ImportOptions) (*ImportResult, error) {
+	files = remapFiles(files, opts)
+	sort.SliceStable(files, func(i, j int) bool {
+		return files[i].Cell == topo.GlobalCell && files[j].Cell != topo.GlobalCell
+	})
+
+	result := &ImportResult{}
+	for _, file := range files {
+		if file.Path == "" {
+			return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "file in cell %v has no path", file.Cell)
+		}
+		name := file.Cell + ":" + file.Path
+		conn, err := ts.ConnForCell(ctx, file.Cell)
+		if err != nil {
+			return nil, err
+		}
+
+		data, version, err := conn.Get(ctx, file.Path)
+		switch {
+		case topo.IsErrType(err, topo.NoNode):
+			if !opts.DryRun {
+				if _, err := conn.Create(ctx, file.Path, file.Data); err != nil {
+					return nil, fmt.Errorf("Create(%v): %w", name, err)
+				}
+			}
+			result.Created = append(result.Created, name)
+		case err != nil:
+			return nil, fmt.Errorf("Get(%v): %w", name, err)
+		case opts.CheckVersions && bytes.Equal(data, file.Data):
+			result.Skipped = append(result.Skipped, name)
+		case opts.CheckVersions && (file.Version == "" || file.Version != version.String()):
+			result.Conflicts = append(result.Conflicts, name)
+		case opts.CheckVersions:
+			if !opts.DryRun {
+				if _, err := conn.Update(ctx, file.Path, file.Data, version); err != nil {
+					if topo.IsErrType(err, topo.BadVersion) {
+						// Changed since we read it.
+						result.Conflicts = append(result.Conflicts, name)
+						continue
+					}
+					return nil, fmt.Errorf("Update(%v): %w", name, err)
+				}
+			}
+			result.Updated = append(result.Updated, name)
+		case opts.Overwrite:
+			if !opts.DryRun {
+				if _, err := conn.Update(ctx, file.Path, file.Data, version); err != nil {
+					return nil, fmt.Errorf("Update(%v): %w", name, err)
+				}
+			}
+			result.Updated = append(result.Updated, name)
+		default:
+			result.Skipped = append(result.Skipped, name)
+		}
+	}
+	return result, nil
+}
+
+// remapFiles returns copies of files with the mappings applied.
+func remapFiles(files []*vtctldatapb.TopologyFile, opts ImportOptions) []*vtctldatapb.TopologyFile {
+	remapped := make([]*vtctldatapb.TopologyFile, 0, len(files))
+	for _, file := range files {
+		cell := file.Cell
+		if mapped, ok := opts.CellMappings[cell]; ok {
+			cell = mapped
+		}
+		rf := &vtctldatapb.TopologyFile{
+			Cell: cell,
+			Path: remapPath(file.Path, opts.PathMappings),
+			Data: file.Data,
+		}
+		// The version of a file says nothing about another one.
+		if rf.Cell == file.Cell && rf.Path == file.Path {
+			rf.Version = file.Version
+		}
+		remapped = append(remapped, rf)
+	}
+	return remapped
+}
+
+// remapPath replaces the longest prefix of filePath that is a key of
+// mappings with its value.
+func remapPath(filePath string, mappings map[string]string) string {
+	best, replacement := "", ""
+	for prefix, value := range mappings {
+		prefix = strings.Trim(prefix, "/")
+		if len(prefix) > len(best) && (filePath == prefix || strings.HasPrefix(filePath, prefix+"/")) {
+			best, replacement = prefix, value
+		}
+	}
+	if best == "" {
+		return filePath
+	}
+	return path.Join(strings.Trim(replacement, "/"), strings.TrimPrefix(filePath, best))
+}
@@ -0,0 +1,177 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helpers
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+)
+
+func TestExportImportTopo(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	fromTS, toTS := createSetup(ctx, t)
+
+	files, err := ExportTopo(ctx, fromTS, []string{topo.GlobalCell, "test_cell"}, "", ExportOptions{})
+	require.NoError(t, err)
+	var names []string
+	for _, file := range files {
+		names = append(names, file.Cell+":"+file.Path)
+	}
+	assert.Equal(t, []string{
+		"global:RoutingRules",
+		"global:cells/test_cell/CellInfo",
+		"global:keyspaces/test_keyspace/Keyspace",
+		"global:keyspaces/test_keyspace/shards/0/Shard",
+		"test_cell:keyspaces/test_keyspace/shards/0/ShardReplication",
+		"test_cell:tablets/test_cell-0000000123/Tablet",
+		"test_cell:tablets/test_cell-0000000234/Tablet",
+	}, names)
+
+	// Exporting a single file.
+	tabletFiles, err := ExportTopo(ctx, fromTS, []string{"test_cell"}, "tablets/test_cell-0000000123/Tablet", ExportOptions{})
+	require.NoError(t, err)
+	require.Len(t, tabletFiles, 1)
+	assert.Equal(t, files[5].Data, tabletFiles[0].Data)
+
+	// Exporting the files that match a filter, or only those directly in a
+	// directory.
+	filtered, err := ExportTopo(ctx, fromTS, []string{topo.GlobalCell, "test_cell"}, "", ExportOptions{Filter: regexp.MustCompile(`/(Tablet|Shard)$`)})
+	require.NoError(t, err)
+	require.Len(t, filtered, 3)
+	assert.Equal(t, "keyspaces/test_keyspace/shards/0/Shard", filtered[0].Path)
+	shallow, err := ExportTopo(ctx, fromTS, []string{topo.GlobalCell}, "keyspaces/test_keyspace", ExportOptions{Shallow: true})
+	require.NoError(t, err)
+	require.Len(t, shallow, 1)
+	assert.Equal(t, "keyspaces/test_keyspace/Keyspace", shallow[0].Path)
+
+	// Round trip through a YAML archive.
+	archive, err := NewTopoArchive(files)
+	require.NoError(t, err)
+	assert.Equal(t, "topodata.Keyspace", archive.Files[2].Type)
+	data, err := MarshalTopoArchive(archive, ArchiveFormatYAML)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "hostname: primaryhost")
+	archive, err = UnmarshalTopoArchive(data)
+	require.NoError(t, err)
+	files, err = archive.TopologyFiles()
+	require.NoError(t, err)
+
+	// Import with remapped paths.
+	opts := ImportOptions{
+		PathMappings: map[string]string{"keyspaces/test_keyspace": "keyspaces/staging_keyspace"},
+		DryRun:       true,
+	}
+	result, err := ImportTopo(ctx, toTS, files, opts)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"global:cells/test_cell/CellInfo"}, result.Skipped)
+	assert.Contains(t, result.Created, "global:keyspaces/staging_keyspace/shards/0/Shard")
+	_, err = toTS.GetKeyspace(ctx, "staging_keyspace")
+	assert.True(t, topo.IsErrType(err, topo.NoNode), "dry run should not write, got %v", err)
+
+	opts.DryRun = false
+	_, err = ImportTopo(ctx, toTS, files, opts)
+	require.NoError(t, err)
+	_, err = toTS.GetShard(ctx, "staging_keyspace", "0")
+	require.NoError(t, err)
+	alias, err := topoproto.ParseTabletAlias("test_cell-0000000123")
+	require.NoError(t, err)
+	tablet, err := toTS.GetTablet(ctx, alias)
+	require.NoError(t, err)
+	assert.Equal(t, "primaryhost", tablet.Hostname)
+
+	// Existing files are only replaced when asked to.
+	result, err = ImportTopo(ctx, toTS, files, opts)
+	require.NoError(t, err)
+	assert.Empty(t, result.Created)
+	assert.Len(t, result.Skipped, len(files))
+	opts.Overwrite = true
+	result, err = ImportTopo(ctx, toTS, files, opts)
+	require.NoError(t, err)
+	assert.Len(t, result.Updated, len(files))
+}
+
+func TestImportTopoCheckVersions(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts, _ := createSetup(ctx, t)
+
+	files, err := ExportTopo(ctx, ts, []string{topo.GlobalCell}, "keyspaces/test_keyspace", ExportOptions{})
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+	assert.NotEmpty(t, files[0].Version)
+
+	// Edit both exported files, while the shard also changes in the topo.
+	keyspaceData, err := (&topodatapb.Keyspace{DurabilityPolicy: "semi_sync"}).MarshalVT()
+	require.NoError(t, err)
+	files[0].Data = keyspaceData
+	files[1].Data = append(files[1].Data, files[1].Data...)
+	_, err = ts.UpdateShardFields(ctx, "test_keyspace", "0", func(si *topo.ShardInfo) error {
+		si.IsPrimaryServing = !si.IsPrimaryServing
+		return nil
+	})
+	require.NoError(t, err)
+	files = append(files, &vtctldatapb.TopologyFile{Cell: topo.GlobalCell, Path: "keyspaces/test_keyspace/notes", Data: []byte("new")})
+
+	result, err := ImportTopo(ctx, ts, files, ImportOptions{CheckVersions: true})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"global:keyspaces/test_keyspace/Keyspace"}, result.Updated)
+	assert.Equal(t, []string{"global:keyspaces/test_keyspace/shards/0/Shard"}, result.Conflicts)
+	assert.Equal(t, []string{"global:keyspaces/test_keyspace/notes"}, result.Created)
+	keyspace, err := ts.GetKeyspace(ctx, "test_keyspace")
+	require.NoError(t, err)
+	assert.Equal(t, "semi_sync", keyspace.DurabilityPolicy)
+
+	// The file created without a version now exists, so it conflicts
+	// unless it is unchanged.
+	result, err = ImportTopo(ctx, ts, files[2:], ImportOptions{CheckVersions: true})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"global:keyspaces/test_keyspace/notes"}, result.Skipped)
+	files[2].Data = []byte("newer")
+	result, err = ImportTopo(ctx, ts, files[2:], ImportOptions{CheckVersions: true})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"global:keyspaces/test_keyspace/notes"}, result.Conflicts)
+}
+
+func TestRemapPath(t *testing.T) {
+	mappings := map[string]string{
+		"keyspaces/ks":        "keyspaces/ks_staging",
+		"keyspaces/ks/shards": "/keyspaces/other/shards/",
+	}
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"keyspaces/ks/Keyspace", "keyspaces/ks_staging/Keyspace"},
+		{"keyspaces/ks/shards/0/Shard", "keyspaces/other/shards/0/Shard"},
+		{"keyspaces/ks2/Keyspace", "keyspaces/ks2/Keyspace"},
+		{"keyspaces/ks", "keyspaces/ks_staging"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, remapPath(tt.in, mappings), tt.in)
+	}
+}
@@ -0,0 +1,197 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helpers
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// CellDecommission is the plan to remove a cell from the topology.
+//
+// A cell can only be removed once nothing runs in it: Tablets and Streams
+// list what still does, and must be empty. The removal then takes the cell
+// out of the CellsAliases, deletes the Files of the cell-local topology,
+// and deletes the CellInfo of the cell.
+type CellDecommission struct {
+	Cell string
+
+	// Tablets are the aliases of the tablets of the cell.
+	Tablets []string
+	// Streams are the "<keyspace>.<workflow>/<shard>/<id>" of the
+	// replication streams that run in the cell or read from it.
+	Streams []string
+
+	// CellsAliases are the aliases the cell is removed from. Those that are
+	// left without cells are deleted.
+	CellsAliases []string
+	// Files are the files of the cell-local topology, relative to its root.
+	Files []string
+}
+
+// Blocked returns true if the cell still has tablets or streams.
+func (d *CellDecommission) Blocked() bool {
+	return len(d.Tablets) > 0 || len(d.Streams) > 0
+}
+
+// Steps describes what removing the cell does, in order.
+func (d *CellDecommission) Steps() []string {
+	var steps []string
+	for _, tablet := range d.Tablets {
+		steps = append(steps, "BLOCKED: tablet "+tablet+" must be deleted first")
+	}
+	for _, stream := range d.Streams {
+		steps = append(steps, "BLOCKED: stream "+stream+" must be moved to another cell or deleted first")
+	}
+	for _, alias := range d.CellsAliases {
+		steps = append(steps, fmt.Sprintf("remove cell %v from cells alias %v", d.Cell, alias))
+	}
+	if len(d.Files) > 0 {
+		steps = append(steps, fmt.Sprintf("delete the %d files of the cell-local topology: %v", len(d.Files), strings.Join(d.Files, ", ")))
+	}
+	steps = append(steps, fmt.Sprintf("delete the CellInfo of cell %v", d.Cell))
+	return steps
+}
+
+// PlanCellDecommission returns the plan to remove a cell, given the
+// workflows of all the keyspaces. It changes nothing.
+func PlanCellDecommission(ctx context.Context, ts *topo.Server, cell string, workflows map[string][]*vtctldatapb.Workflow) (*CellDecommission, error) {
+	if _, err := ts.GetCellInfo(ctx, cell, true /* strongRead */); err != nil {
+		return nil, vterrors.Wrapf(err, "cannot read the CellInfo of cell %v", cell)
+	}
+	d := &CellDecommission{Cell: cell}
+
+	aliases, err := ts.GetTabletAliasesByCell(ctx, cell)
+	if err != nil && !topo.IsErrType(err, topo.NoNode) {
+		return nil, vterrors.Wrapf(err, "cannot list the tablets of cell %v", cell)
+	}
+	for _, alias := range aliases {
+		d.Tablets = append(d.Tablets, topoproto.TabletAliasString(alias))
+	}
+	sort.Strings(d.Tablets)
+
+	cellsAliases, err := ts.GetCellsAliases(ctx, true /* strongRead */)
+	if err != nil {
+		return nil, err
+	}
+	for name, alias := range cellsAliases {
+		if slices.Contains(alias.Cells, cell) {
+			d.CellsAliases = append(d.CellsAliases, name)
+		}
+	}
+	sort.Strings(d.CellsAliases)
+
+	// A stream reads from the cell if it lists the cell, or an alias the
+	// cell is in.
+	names := append([]string{cell}, d.CellsAliases...)
+	for keyspace, wfs := range workflows {
+		for _, wf := range wfs {
+			for _, shardStreams := range wf.ShardStreams {
+				for _, stream := range shardStreams.Streams {
+					if stream.Tablet.GetCell() != cell && !slices.ContainsFunc(stream.Cells, func(c string) bool { return slices.Contains(names, c) }) {
+						continue
+					}
+					d.Streams = append(d.Streams, fmt.Sprintf("%v.%v/%v/%d", keyspace, wf.Name, stream.Shard, stream.Id))
+				}
+			}
+		}
+	}
+	sort.Strings(d.Streams)
+
+	conn, err := ts.ConnForCell(ctx, cell)
+	if err != nil {
+		return nil, vterrors.Wrapf(err, "cannot connect to the topology of cell %v", cell)
+	}
+	err = walkFiles(ctx, conn, "", func(filePath string) error {
+		d.Files = append(d.Files, filePath)
+		return nil
+	})
+	if err != nil {
+		return nil, vterrors.Wrapf(err, "cannot list the files of cell %v", cell)
+	}
+	sort.Strings(d.Files)
+	return d, nil
+}
+
+// DecommissionCell runs the plan returned by PlanCellDecommission. The plan
+// must not be blocked.
+func DecommissionCell(ctx context.Context, ts *topo.Server, d *CellDecommission) error {
+	if d.Blocked() {
+		return vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "cell %v still has %d tablets and %d streams", d.Cell, len(d.Tablets), len(d.Streams))
+	}
+	// Tablets may have started in the cell since the plan was made.
+	aliases, err := ts.GetTabletAliasesByCell(ctx, d.Cell)
+	if err != nil && !topo.IsErrType(err, topo.NoNode) {
+		return vterrors.Wrapf(err, "cannot list the tablets of cell %v", d.Cell)
+	}
+	if len(aliases) > 0 {
+		return vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "cell %v has new tablets, starting with %v", d.Cell, topoproto.TabletAliasString(aliases[0]))
+	}
+
+	for _, name := range d.CellsAliases {
+		alias, err := ts.GetCellsAlias(ctx, name, true /* strongRead */)
+		switch {
+		case topo.IsErrType(err, topo.NoNode):
+			continue
+		case err != nil:
+			return err
+		}
+		if len(alias.Cells) == 1 && alias.Cells[0] == d.Cell {
+			if err := ts.DeleteCellsAlias(ctx, name); err != nil {
+				return vterrors.Wrapf(err, "cannot delete cells alias %v", name)
+			}
+			log.Infof("Deleted cells alias %v, its only cell was %v", name, d.Cell)
+			continue
+		}
+		err = ts.UpdateCellsAlias(ctx, name, func(alias *topodatapb.CellsAlias) error {
+			alias.Cells = slices.DeleteFunc(alias.Cells, func(c string) bool { return c == d.Cell })
+			return nil
+		})
+		if err != nil {
+			return vterrors.Wrapf(err, "cannot remove cell %v from cells alias %v", d.Cell, name)
+		}
+		log.Infof("Removed cell %v from cells alias %v", d.Cell, name)
+	}
+
+	conn, err := ts.ConnForCell(ctx, d.Cell)
+	if err != nil {
+		return vterrors.Wrapf(err, "cannot connect to the topology of cell %v", d.Cell)
+	}
+	// Files created since the plan was made are deleted too.
+	err = walkFiles(ctx, conn, "", func(filePath string) error {
+		return deleteFile(ctx, conn, filePath)
+	})
+	if err != nil {
+		return vterrors.Wrapf(err, "cannot delete the files of cell %v", d.Cell)
+	}
+	log.Infof("Deleted the cell-local topology of cell %v", d.Cell)
+
+	// The cell-local topology is empty, so nothing is served there anymore.
+	return ts.DeleteCellInfo(ctx, d.Cell, true /* force */)
+}
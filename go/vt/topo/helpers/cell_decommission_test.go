@@ -0,0 +1,93 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helpers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+)
+
+func TestCellDecommission(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "zone1", "zone2", "zone3")
+	require.NoError(t, ts.CreateCellsAlias(ctx, "east", &topodatapb.CellsAlias{Cells: []string{"zone1", "zone2"}}))
+	require.NoError(t, ts.CreateCellsAlias(ctx, "west", &topodatapb.CellsAlias{Cells: []string{"zone3"}}))
+	require.NoError(t, ts.UpdateSrvVSchema(ctx, "zone1", &vschemapb.SrvVSchema{}))
+	require.NoError(t, ts.UpdateSrvVSchema(ctx, "zone3", &vschemapb.SrvVSchema{}))
+	tablet := &topodatapb.Tablet{Alias: &topodatapb.TabletAlias{Cell: "zone1", Uid: 100}, Keyspace: "ks", Shard: "0"}
+	require.NoError(t, ts.CreateTablet(ctx, tablet))
+
+	workflows := map[string][]*vtctldatapb.Workflow{
+		"ks": {{
+			Name: "wf",
+			ShardStreams: map[string]*vtctldatapb.Workflow_ShardStream{
+				"0/zone2-0000000200": {Streams: []*vtctldatapb.Workflow_Stream{{
+					Id:     1,
+					Shard:  "0",
+					Tablet: &topodatapb.TabletAlias{Cell: "zone2", Uid: 200},
+					Cells:  []string{"east"},
+				}}},
+			},
+		}},
+	}
+
+	d, err := PlanCellDecommission(ctx, ts, "zone1", workflows)
+	require.NoError(t, err)
+	assert.True(t, d.Blocked())
+	assert.Equal(t, []string{"zone1-0000000100"}, d.Tablets)
+	assert.Equal(t, []string{"ks.wf/0/1"}, d.Streams)
+	assert.Equal(t, []string{"east"}, d.CellsAliases)
+	assert.Equal(t, []string{"SrvVSchema", "keyspaces/ks/shards/0/ShardReplication", "tablets/zone1-0000000100/Tablet"}, d.Files)
+	err = DecommissionCell(ctx, ts, d)
+	assert.ErrorContains(t, err, "cell zone1 still has 1 tablets and 1 streams")
+
+	require.NoError(t, ts.DeleteTablet(ctx, tablet.Alias))
+	d, err = PlanCellDecommission(ctx, ts, "zone1", nil)
+	require.NoError(t, err)
+	assert.False(t, d.Blocked())
+	assert.Equal(t, []string{
+		"remove cell zone1 from cells alias east",
+		"delete the 2 files of the cell-local topology: SrvVSchema, keyspaces/ks/shards/0/ShardReplication",
+		"delete the CellInfo of cell zone1",
+	}, d.Steps())
+	require.NoError(t, DecommissionCell(ctx, ts, d))
+
+	cells, err := ts.GetCellInfoNames(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"zone2", "zone3"}, cells)
+	east, err := ts.GetCellsAlias(ctx, "east", true)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"zone2"}, east.Cells)
+
+	// An alias that only has the cell is deleted with it.
+	d, err = PlanCellDecommission(ctx, ts, "zone3", nil)
+	require.NoError(t, err)
+	require.NoError(t, DecommissionCell(ctx, ts, d))
+	_, err = ts.GetCellsAlias(ctx, "west", true)
+	assert.True(t, topo.IsErrType(err, topo.NoNode))
+}
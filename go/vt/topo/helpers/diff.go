@@ -0,0 +1,163 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helpers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+
+	"google.golang.org/protobuf/proto"
+
+	"vitess.io/vitess/go/vt/topo"
+)
+
+// The kinds of TopoDiff.
+const (
+	// DiffMissingFromDestination is a file that only exists in the source.
+	DiffMissingFromDestination = "missing_from_destination"
+	// DiffMissingFromSource is a file that only exists in the destination.
+	DiffMissingFromSource = "missing_from_source"
+	// DiffValue is a file whose contents differ.
+	DiffValue = "value"
+	// DiffVersion is a file whose contents are the same, but whose versions
+	// differ.
+	DiffVersion = "version"
+)
+
+// TopoDiff is a file that differs between two topo servers.
+type TopoDiff struct {
+	Cell string `json:"cell"`
+	Path string `json:"path"`
+	Kind string `json:"kind"`
+
+	FromVersion string `json:"from_version,omitempty"`
+	ToVersion   string `json:"to_version,omitempty"`
+}
+
+// TopoDiffReport is the result of DiffTopos.
+type TopoDiffReport struct {
+	// Cells are the cells that were compared, the global cell included.
+	Cells []string `json:"cells"`
+	// FilesCompared is the number of distinct files found in either topo
+	// server.
+	FilesCompared int `json:"files_compared"`
+	// Diffs are the differences found, sorted by cell and path.
+	Diffs []*TopoDiff `json:"diffs"`
+}
+
+// DiffTopos walks the global cell and the given cells of both topo servers,
+// and reports the files selected by filter that are missing from either of
+// them or whose contents differ. If compareVersions is set, files whose
+// versions differ are reported too; this is only meaningful when toTS was
+// restored from a backup of fromTS in the same implementation, as versions
+// are not preserved by a copy.
+//
+// Unlike the Compare* functions, DiffTopos compares the raw files, so it
+// covers any data type and reports all the differences rather than the first
+// one.
+func DiffTopos(ctx context.Context, fromTS, toTS *topo.Server, cells []string, filter FileFilter, compareVersions bool) (*TopoDiffReport, error) {
+	report := &TopoDiffReport{
+		Cells: append([]string{topo.GlobalCell}, cells...),
+		Diffs: []*TopoDiff{},
+	}
+	for _, cell := range report.Cells {
+		if err := diffCell(ctx, fromTS, toTS, cell, filter, compareVersions, report); err != nil {
+			return nil, err
+		}
+	}
+
+	sort.SliceStable(report.Diffs, func(i, j int) bool {
+		if report.Diffs[i].Cell != report.Diffs[j].Cell {
+			return report.Diffs[i].Cell < report.Diffs[j].Cell
+		}
+		return report.Diffs[i].Path < report.Diffs[j].Path
+	})
+	return report, nil
+}
+
+func diffCell(ctx context.Context, fromTS, toTS *topo.Server, cell string, filter FileFilter, compareVersions bool, report *TopoDiffReport) error {
+	fromConn, err := fromTS.ConnForCell(ctx, cell)
+	if err != nil {
+		return fmt.Errorf("cannot connect to source cell %v: %w", cell, err)
+	}
+	toConn, err := toTS.ConnForCell(ctx, cell)
+	if err != nil {
+		return fmt.Errorf("cannot connect to destination cell %v: %w", cell, err)
+	}
+
+	seen := make(map[string]bool)
+	if err := walkFiles(ctx, fromConn, "", func(filePath string) error {
+		if !filter(cell, filePath) {
+			return nil
+		}
+		seen[filePath] = true
+		report.FilesCompared++
+
+		fromData, fromVersion, err := fromConn.Get(ctx, filePath)
+		if err != nil {
+			return fmt.Errorf("source Get(%v, %v): %w", cell, filePath, err)
+		}
+		toData, toVersion, err := toConn.Get(ctx, filePath)
+		switch {
+		case topo.IsErrType(err, topo.NoNode):
+			report.Diffs = append(report.Diffs, &TopoDiff{Cell: cell, Path: filePath, Kind: DiffMissingFromDestination, FromVersion: fromVersion.String()})
+			return nil
+		case err != nil:
+			return fmt.Errorf("destination Get(%v, %v): %w", cell, filePath, err)
+		}
+
+		diff := &TopoDiff{Cell: cell, Path: filePath, FromVersion: fromVersion.String(), ToVersion: toVersion.String()}
+		switch {
+		case !contentsEqual(filePath, fromData, toData):
+			diff.Kind = DiffValue
+		case compareVersions && diff.FromVersion != diff.ToVersion:
+			diff.Kind = DiffVersion
+		default:
+			return nil
+		}
+		report.Diffs = append(report.Diffs, diff)
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	return walkFiles(ctx, toConn, "", func(filePath string) error {
+		if seen[filePath] || !filter(cell, filePath) {
+			return nil
+		}
+		report.FilesCompared++
+		report.Diffs = append(report.Diffs, &TopoDiff{Cell: cell, Path: filePath, Kind: DiffMissingFromSource})
+		return nil
+	})
+}
+
+// contentsEqual returns whether two versions of a file are equal. Known
+// types are compared as protos, as their encoding is not deterministic (e.g.
+// for maps).
+func contentsEqual(filePath string, a, b []byte) bool {
+	if bytes.Equal(a, b) {
+		return true
+	}
+
+	pa, pb := topo.NewContentProto(filePath), topo.NewContentProto(filePath)
+	if pa == nil || proto.Unmarshal(a, pa) != nil || proto.Unmarshal(b, pb) != nil {
+		return false
+	}
+	return proto.Equal(pa, pb)
+}
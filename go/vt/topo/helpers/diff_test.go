@@ -0,0 +1,86 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helpers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/topo"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+func TestDiffTopos(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	fromTS, toTS := createSetup(ctx, t)
+	cells := []string{"test_cell"}
+
+	report, err := DiffTopos(ctx, fromTS, toTS, cells, syncAllButCells, false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{topo.GlobalCell, "test_cell"}, report.Cells)
+	var missing []string
+	for _, diff := range report.Diffs {
+		assert.Equal(t, DiffMissingFromDestination, diff.Kind)
+		missing = append(missing, diff.Cell+":"+diff.Path)
+	}
+	assert.Contains(t, missing, "global:keyspaces/test_keyspace/Keyspace")
+	assert.Contains(t, missing, "test_cell:tablets/test_cell-0000000123/Tablet")
+
+	require.NoError(t, CopyKeyspaces(ctx, fromTS, toTS, nil))
+	require.NoError(t, CopyShards(ctx, fromTS, toTS))
+	require.NoError(t, CopyShardReplications(ctx, fromTS, toTS))
+	require.NoError(t, CopyTablets(ctx, fromTS, toTS))
+	require.NoError(t, CopyRoutingRules(ctx, fromTS, toTS))
+
+	report, err = DiffTopos(ctx, fromTS, toTS, cells, syncAllButCells, false)
+	require.NoError(t, err)
+	assert.Empty(t, report.Diffs)
+	assert.NotZero(t, report.FilesCompared)
+
+	// Versions are not preserved by copies.
+	report, err = DiffTopos(ctx, fromTS, toTS, cells, syncAllButCells, true)
+	require.NoError(t, err)
+	require.NotEmpty(t, report.Diffs)
+	assert.Equal(t, DiffVersion, report.Diffs[0].Kind)
+
+	require.NoError(t, toTS.CreateKeyspace(ctx, "other_keyspace", &topodatapb.Keyspace{}))
+	_, err = fromTS.UpdateTabletFields(ctx, &topodatapb.TabletAlias{Cell: "test_cell", Uid: 123}, func(tablet *topodatapb.Tablet) error {
+		tablet.Hostname = "newhost"
+		return nil
+	})
+	require.NoError(t, err)
+
+	report, err = DiffTopos(ctx, fromTS, toTS, cells, syncAllButCells, false)
+	require.NoError(t, err)
+	assert.Equal(t, []*TopoDiff{{
+		Cell: topo.GlobalCell,
+		Path: "keyspaces/other_keyspace/Keyspace",
+		Kind: DiffMissingFromSource,
+	}, {
+		Cell:        "test_cell",
+		Path:        "tablets/test_cell-0000000123/Tablet",
+		Kind:        DiffValue,
+		FromVersion: report.Diffs[1].FromVersion,
+		ToVersion:   report.Diffs[1].ToVersion,
+	}}, report.Diffs)
+}
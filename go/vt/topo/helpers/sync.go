@@ -0,0 +1,185 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helpers
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/topo"
+)
+
+// SyncRetryDelay is how long SyncTopos waits before re-copying a cell after
+// its watch failed.
+var SyncRetryDelay = 5 * time.Second
+
+// FileFilter returns whether the file at filePath in the given cell is synced
+// by SyncTopos or compared by DiffTopos. filePath is relative to the root
+// directory of the cell.
+type FileFilter func(cell, filePath string) bool
+
+// SyncTopos copies the files selected by filter from fromTS to toTS, in the
+// global cell and in the given cells, and then keeps toTS up to date by
+// streaming the changes made to fromTS, until ctx is done. Unlike the Copy*
+// functions, writes to fromTS can go on while it runs, so it can be used to
+// migrate a cluster between topo servers by letting toTS catch up and then
+// cutting over.
+//
+// Files are copied as-is, so the cells must have the same names in both
+// topo servers. Files deleted from fromTS are deleted from toTS. If a watch
+// is interrupted, its cell is copied again from a new snapshot.
+//
+// The implementation of fromTS must support WatchRecursive.
+func SyncTopos(ctx context.Context, fromTS, toTS *topo.Server, cells []string, filter FileFilter) error {
+	eg, ctx := errgroup.WithContext(ctx)
+	for _, cell := range append([]string{topo.GlobalCell}, cells...) {
+		eg.Go(func() error {
+			return syncCell(ctx, fromTS, toTS, cell, filter)
+		})
+	}
+	return eg.Wait()
+}
+
+// syncCell syncs a single cell until ctx is done, starting over from a new
+// snapshot whenever the sync is interrupted.
+func syncCell(ctx context.Context, fromTS, toTS *topo.Server, cell string, filter FileFilter) error {
+	for {
+		err := syncCellOnce(ctx, fromTS, toTS, cell, filter)
+		switch {
+		case ctx.Err() != nil:
+			return nil
+		case topo.IsErrType(err, topo.NoImplementation):
+			return fmt.Errorf("cannot watch cell %v: %w", cell, err)
+		}
+
+		log.Warningf("Sync of cell %v interrupted, copying it again in %v: %v", cell, SyncRetryDelay, err)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(SyncRetryDelay):
+		}
+	}
+}
+
+func syncCellOnce(ctx context.Context, fromTS, toTS *topo.Server, cell string, filter FileFilter) error {
+	fromConn, err := fromTS.ConnForCell(ctx, cell)
+	if err != nil {
+		return err
+	}
+	toConn, err := toTS.ConnForCell(ctx, cell)
+	if err != nil {
+		return err
+	}
+
+	watchCtx, cancel := context.WithCancel(ctx)
+	initial, changes, err := fromConn.WatchRecursive(watchCtx, "")
+	if err != nil {
+		cancel()
+		return err
+	}
+	defer func() {
+		cancel()
+		// The changes channel must be drained until it is closed.
+		for range changes {
+		}
+	}()
+
+	// Copy the snapshot, and remove what is not in it anymore.
+	snapshot := make(map[string]bool, len(initial))
+	for _, wd := range initial {
+		if !filter(cell, wd.Path) {
+			continue
+		}
+		snapshot[wd.Path] = true
+		if _, err := toConn.Update(ctx, wd.Path, wd.Contents, nil); err != nil {
+			return fmt.Errorf("Update(%v, %v): %w", cell, wd.Path, err)
+		}
+	}
+	deleted := 0
+	if err := walkFiles(ctx, toConn, "", func(filePath string) error {
+		if snapshot[filePath] || !filter(cell, filePath) {
+			return nil
+		}
+		deleted++
+		return deleteFile(ctx, toConn, filePath)
+	}); err != nil {
+		return fmt.Errorf("cannot remove stale files from cell %v: %w", cell, err)
+	}
+	log.Infof("Copied %v files to cell %v and removed %v, now streaming changes", len(snapshot), cell, deleted)
+
+	for wd := range changes {
+		switch {
+		case wd.Err == nil:
+			if !filter(cell, wd.Path) {
+				continue
+			}
+			if _, err := toConn.Update(ctx, wd.Path, wd.Contents, nil); err != nil {
+				return fmt.Errorf("Update(%v, %v): %w", cell, wd.Path, err)
+			}
+		case topo.IsErrType(wd.Err, topo.NoNode) && wd.Path != "":
+			if !filter(cell, wd.Path) {
+				continue
+			}
+			if err := deleteFile(ctx, toConn, wd.Path); err != nil {
+				return err
+			}
+		default:
+			return wd.Err
+		}
+	}
+	return topo.NewError(topo.Interrupted, cell)
+}
+
+// walkFiles calls fn for every file under dirPath.
+func walkFiles(ctx context.Context, conn topo.Conn, dirPath string, fn func(filePath string) error) error {
+	entries, err := conn.ListDir(ctx, dirPath, true /* full */)
+	switch {
+	case topo.IsErrType(err, topo.NoNode):
+		return nil
+	case err != nil:
+		return err
+	}
+
+	for _, entry := range entries {
+		if entry.Ephemeral {
+			// Locks and elections are not data.
+			continue
+		}
+		entryPath := path.Join(dirPath, entry.Name)
+		if entry.Type == topo.TypeDirectory {
+			err = walkFiles(ctx, conn, entryPath, fn)
+		} else {
+			err = fn(entryPath)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func deleteFile(ctx context.Context, conn topo.Conn, filePath string) error {
+	if err := conn.Delete(ctx, filePath, nil); err != nil && !topo.IsErrType(err, topo.NoNode) {
+		return fmt.Errorf("Delete(%v): %w", filePath, err)
+	}
+	return nil
+}
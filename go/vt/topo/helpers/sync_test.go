@@ -0,0 +1,96 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helpers
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+// syncAllButCells syncs everything but the CellInfo records.
+func syncAllButCells(cell, filePath string) bool {
+	return !strings.HasPrefix(filePath, topo.CellsPath+"/")
+}
+
+func TestSyncTopos(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	fromTS, toTS := createSetup(ctx, t)
+	require.NoError(t, toTS.CreateKeyspace(ctx, "stale_keyspace", &topodatapb.Keyspace{}))
+
+	done := make(chan error)
+	go func() {
+		done <- SyncTopos(ctx, fromTS, toTS, []string{"test_cell"}, syncAllButCells)
+	}()
+
+	// The existing data is copied, and stale data removed.
+	assert.Eventually(t, func() bool {
+		keyspaces, err := toTS.GetKeyspaces(ctx)
+		return err == nil && len(keyspaces) == 1 && keyspaces[0] == "test_keyspace"
+	}, 10*time.Second, 10*time.Millisecond)
+	tablets, err := toTS.GetTabletAliasesByCell(ctx, "test_cell")
+	require.NoError(t, err)
+	assert.Len(t, tablets, 2)
+
+	// Changes to the source are streamed.
+	require.NoError(t, fromTS.CreateKeyspace(ctx, "new_keyspace", &topodatapb.Keyspace{DurabilityPolicy: "semi_sync"}))
+	_, err = fromTS.UpdateTabletFields(ctx, tablets[0], func(tablet *topodatapb.Tablet) error {
+		tablet.Hostname = "newhost"
+		return nil
+	})
+	require.NoError(t, err)
+	require.NoError(t, fromTS.DeleteTablet(ctx, tablets[1]))
+
+	assert.Eventually(t, func() bool {
+		ki, err := toTS.GetKeyspace(ctx, "new_keyspace")
+		if err != nil || ki.DurabilityPolicy != "semi_sync" {
+			return false
+		}
+		ti, err := toTS.GetTablet(ctx, tablets[0])
+		if err != nil || ti.Hostname != "newhost" {
+			return false
+		}
+		_, err = toTS.GetTablet(ctx, tablets[1])
+		return topo.IsErrType(err, topo.NoNode)
+	}, 10*time.Second, 10*time.Millisecond)
+
+	cancel()
+	assert.NoError(t, <-done)
+}
+
+func TestSyncToposNoWatch(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	fromTS, fromFactory := memorytopo.NewServerAndFactory(ctx, "test_cell")
+	toTS := memorytopo.NewServer(ctx, "test_cell")
+	fromFactory.AddOperationError(memorytopo.WatchRecursive, ".*", topo.NewError(topo.NoImplementation, "WatchRecursive"))
+
+	err := SyncTopos(ctx, fromTS, toTS, []string{"test_cell"}, syncAllButCells)
+	assert.True(t, topo.IsErrType(err, topo.NoImplementation), "expected NoImplementation, got %v", err)
+}
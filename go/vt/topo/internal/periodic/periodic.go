@@ -0,0 +1,87 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package periodic runs the periodic tasks of vtctld on the topo, like its
+// backups, its scans for orphaned objects and the counts of its objects.
+package periodic
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Runner runs a task periodically, in a goroutine of its own.
+type Runner struct {
+	interval time.Duration
+	timeout  time.Duration
+	run      func(ctx context.Context)
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewRunner returns a runner of run every interval. Each run is bounded by
+// timeout, or by interval if timeout is 0.
+func NewRunner(interval, timeout time.Duration, run func(ctx context.Context)) *Runner {
+	if timeout == 0 {
+		timeout = interval
+	}
+	return &Runner{
+		interval: interval,
+		timeout:  timeout,
+		run:      run,
+	}
+}
+
+// Start starts running the task, the first time right away.
+func (r *Runner) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+		for {
+			r.runOnce(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop stops running the task, and waits for the run in progress, if any, to
+// be interrupted.
+func (r *Runner) Stop() {
+	if r.cancel == nil {
+		return
+	}
+	r.cancel()
+	r.wg.Wait()
+	r.cancel = nil
+}
+
+func (r *Runner) runOnce(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	r.run(ctx)
+}
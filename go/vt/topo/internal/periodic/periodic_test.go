@@ -0,0 +1,63 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package periodic
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunner(t *testing.T) {
+	runs := make(chan time.Time, 10)
+	r := NewRunner(10*time.Millisecond, 0, func(ctx context.Context) {
+		deadline, ok := ctx.Deadline()
+		assert.True(t, ok, "runs are bounded by the interval by default")
+		runs <- deadline
+	})
+	r.Start()
+	for i := 0; i < 3; i++ {
+		select {
+		case <-runs:
+		case <-time.After(10 * time.Second):
+			require.FailNow(t, "no run of the task")
+		}
+	}
+	r.Stop()
+	r.Stop()
+
+	// No run starts once stopped.
+	for len(runs) > 0 {
+		<-runs
+	}
+	time.Sleep(50 * time.Millisecond)
+	assert.Empty(t, runs)
+}
+
+func TestRunnerStopInterrupts(t *testing.T) {
+	started := make(chan struct{})
+	r := NewRunner(time.Hour, 0, func(ctx context.Context) {
+		close(started)
+		<-ctx.Done()
+	})
+	r.Start()
+	<-started
+	r.Stop()
+}
@@ -18,8 +18,11 @@ package topo
 
 import (
 	"context"
+	"fmt"
 	"path"
+	"slices"
 	"sort"
+	"strings"
 	"sync"
 
 	"github.com/spf13/pflag"
@@ -27,6 +30,7 @@ import (
 
 	"vitess.io/vitess/go/constants/sidecar"
 	"vitess.io/vitess/go/sqlescape"
+	"vitess.io/vitess/go/viperutil"
 	"vitess.io/vitess/go/vt/key"
 	"vitess.io/vitess/go/vt/servenv"
 	"vitess.io/vitess/go/vt/vterrors"
@@ -42,15 +46,81 @@ import (
 // This file contains keyspace utility functions.
 
 // Default concurrency to use in order to avoid overhwelming the topo server.
+// It is the default of --topo_read_concurrency, see ReadConcurrency.
 var DefaultConcurrency = 32
 
+// topoReadConcurrency is the concurrency of the topo reads, to avoid
+// overwhelming the topo server. It is dynamic, so that operators can loosen or
+// tighten it during an incident by reloading the config, without a restart.
+var topoReadConcurrency = viperutil.Configure(
+	"topo.read_concurrency",
+	viperutil.Options[int]{
+		FlagName: "topo_read_concurrency",
+		Default:  DefaultConcurrency,
+		Dynamic:  true,
+		Validate: func(concurrency int) error {
+			if concurrency <= 0 {
+				return fmt.Errorf("topo read concurrency must be positive, not %d", concurrency)
+			}
+			return nil
+		},
+	},
+)
+
+// The concurrency limits of the reads and the writes of the global topo
+// server and of the topo servers of the cells, which are usually far less
+// sensitive to load. Like topoReadConcurrency, they are dynamic. Zero leaves
+// them unlimited.
+var (
+	topoGlobalReadConcurrency  = configureConcurrencyLimit("topo.global_read_concurrency", "topo_global_read_concurrency")
+	topoGlobalWriteConcurrency = configureConcurrencyLimit("topo.global_write_concurrency", "topo_global_write_concurrency")
+	topoCellReadConcurrency    = configureConcurrencyLimit("topo.cell_read_concurrency", "topo_cell_read_concurrency")
+	topoCellWriteConcurrency   = configureConcurrencyLimit("topo.cell_write_concurrency", "topo_cell_write_concurrency")
+)
+
+func configureConcurrencyLimit(key, flagName string) viperutil.Value[int] {
+	return viperutil.Configure(
+		key,
+		viperutil.Options[int]{
+			FlagName: flagName,
+			Dynamic:  true,
+			Validate: func(limit int) error {
+				if limit < 0 {
+					return fmt.Errorf("topo concurrency limit must not be negative, not %d", limit)
+				}
+				return nil
+			},
+		},
+	)
+}
+
+// ReadConcurrency returns the concurrency to use in order to avoid
+// overwhelming the topo server, as currently configured by
+// --topo_read_concurrency.
+func ReadConcurrency() int {
+	return topoReadConcurrency.Get()
+}
+
+// cappedReadConcurrency returns the concurrency of the reads fanned out by
+// the helpers: ReadConcurrency, capped by the read concurrency limit of the
+// topo servers they read, topoGlobalReadConcurrency or
+// topoCellReadConcurrency, as the reads beyond it would only wait.
+func cappedReadConcurrency(limit viperutil.Value[int]) int {
+	if l := limit.Get(); l > 0 {
+		return min(ReadConcurrency(), l)
+	}
+	return ReadConcurrency()
+}
+
 // shardKeySuffix is the suffix of a shard key.
 // The full key looks like this:
 // /vitess/global/keyspaces/customer/shards/80-/Shard
 const shardKeySuffix = "Shard"
 
 func registerFlags(fs *pflag.FlagSet) {
-	fs.IntVar(&DefaultConcurrency, "topo_read_concurrency", DefaultConcurrency, "Concurrency of topo reads.")
+	fs.Int("topo_read_concurrency", topoReadConcurrency.Default(), "Concurrency of topo reads. It can be changed at runtime by reloading the config.")
+
+	viperutil.BindFlags(fs, topoReadConcurrency)
 }
 
 func init() {
@@ -211,7 +281,7 @@ func (ts *Server) FindAllShardsInKeyspace(ctx context.Context, keyspace string,
 		opt = &FindAllShardsInKeyspaceOptions{}
 	}
 	if opt.Concurrency <= 0 {
-		opt.Concurrency = DefaultConcurrency
+		opt.Concurrency = cappedReadConcurrency(topoGlobalReadConcurrency)
 	}
 
 	// Unescape the keyspace name as this can e.g. come from the VSchema where
@@ -381,6 +451,12 @@ func (ts *Server) DeleteKeyspace(ctx context.Context, keyspace string) error {
 	if err := ts.DeleteVSchema(ctx, keyspace); err != nil && !IsErrType(err, NoNode) {
 		return err
 	}
+	if err := ts.deleteVSchemaHistory(ctx, keyspace); err != nil {
+		return err
+	}
+	if err := ts.deleteKeyspaceRoutingLock(ctx, keyspace); err != nil {
+		return err
+	}
 
 	event.Dispatch(&events.KeyspaceChange{
 		KeyspaceName: keyspace,
@@ -403,6 +479,111 @@ func (ts *Server) GetKeyspaces(ctx context.Context) ([]string, error) {
 	}
 }
 
+// WatchKeyspaceNamesData is returned / streamed by WatchKeyspaces and
+// WatchSrvKeyspaceNames. Exactly one of Value or Err will be set.
+type WatchKeyspaceNamesData struct {
+	Value []string
+	Err   error
+}
+
+// WatchKeyspaces watches the list of keyspaces in the topology. It returns
+// the current sorted list of keyspaces, and a channel streaming the new list
+// each time a keyspace is created or deleted. The last value on the channel
+// has Err set, and the channel is closed right after.
+//
+// It returns a NoImplementation error if the global topo server does not
+// support WatchRecursive, in which case the caller should poll GetKeyspaces.
+func (ts *Server) WatchKeyspaces(ctx context.Context) (*WatchKeyspaceNamesData, <-chan *WatchKeyspaceNamesData, error) {
+	return watchKeyspaceNames(ctx, ts.globalCell)
+}
+
+// watchKeyspaceNames watches the names of the keyspace directories of the
+// cell of conn. It has the contract of WatchKeyspaces.
+func watchKeyspaceNames(ctx context.Context, conn Conn) (*WatchKeyspaceNamesData, <-chan *WatchKeyspaceNamesData, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	initial, wdChannel, err := conn.WatchRecursive(ctx, KeyspacesPath)
+	if err != nil {
+		cancel()
+		return nil, nil, err
+	}
+
+	// files are the paths of the files of each keyspace: a keyspace is
+	// listed as long as it has at least one file, as in ListDir.
+	files := make(map[string]map[string]struct{})
+	update := func(wd *WatchDataRecursive) {
+		keyspace, ok := keyspaceOfPath(wd.Path)
+		if !ok {
+			return
+		}
+		if wd.Err != nil {
+			delete(files[keyspace], wd.Path)
+			if len(files[keyspace]) == 0 {
+				delete(files, keyspace)
+			}
+			return
+		}
+		if files[keyspace] == nil {
+			files[keyspace] = make(map[string]struct{})
+		}
+		files[keyspace][wd.Path] = struct{}{}
+	}
+	names := func() []string {
+		res := make([]string, 0, len(files))
+		for keyspace := range files {
+			res = append(res, keyspace)
+		}
+		sort.Strings(res)
+		return res
+	}
+
+	for _, wd := range initial {
+		update(wd)
+	}
+	current := names()
+
+	changes := make(chan *WatchKeyspaceNamesData, 10)
+
+	// The background routine reads any event from the watch channel, and
+	// sends the new list to the caller only if a keyspace was created or
+	// deleted. If cancel() is called, the underlying WatchRecursive() code
+	// will send an ErrInterrupted and then close the channel. We'll just
+	// propagate that back to our caller.
+	go func() {
+		defer cancel()
+		defer close(changes)
+
+		last := current
+		for wd := range wdChannel {
+			if wd.Err != nil && !IsErrType(wd.Err, NoNode) {
+				// Last error value, we're done.
+				// wdChannel will be closed right after
+				// this, no need to do anything.
+				changes <- &WatchKeyspaceNamesData{Err: wd.Err}
+				return
+			}
+
+			update(wd)
+			if next := names(); !slices.Equal(next, last) {
+				last = next
+				changes <- &WatchKeyspaceNamesData{Value: next}
+			}
+		}
+	}()
+
+	return &WatchKeyspaceNamesData{Value: current}, changes, nil
+}
+
+// keyspaceOfPath returns the keyspace of a path relative to the root of a
+// cell, like keyspaces/<keyspace>/Keyspace.
+func keyspaceOfPath(filePath string) (string, bool) {
+	rest, ok := strings.CutPrefix(strings.TrimPrefix(filePath, "/"), KeyspacesPath+"/")
+	if !ok {
+		return "", false
+	}
+	keyspace, _, _ := strings.Cut(rest, "/")
+	return keyspace, keyspace != ""
+}
+
 // GetShardNames returns the list of shards in a keyspace.
 func (ts *Server) GetShardNames(ctx context.Context, keyspace string) ([]string, error) {
 	shardsPath := path.Join(KeyspacesPath, keyspace, ShardsPath)
@@ -219,3 +219,67 @@ func TestServerGetServingShards(t *testing.T) {
 		})
 	}
 }
+
+func TestWatchKeyspaces(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "zone1")
+	defer ts.Close()
+
+	require.NoError(t, ts.CreateKeyspace(ctx, "ks1", &topodatapb.Keyspace{}))
+
+	watchCtx, watchCancel := context.WithCancel(ctx)
+	defer watchCancel()
+	current, changes, err := ts.WatchKeyspaces(watchCtx)
+	require.NoError(t, err)
+	require.Equal(t, []string{"ks1"}, current.Value)
+
+	// New files of an existing keyspace do not change the list.
+	require.NoError(t, ts.CreateShard(ctx, "ks1", "0"))
+	require.NoError(t, ts.CreateKeyspace(ctx, "ks2", &topodatapb.Keyspace{}))
+	wd := <-changes
+	require.NoError(t, wd.Err)
+	require.Equal(t, []string{"ks1", "ks2"}, wd.Value)
+
+	require.NoError(t, ts.DeleteKeyspace(ctx, "ks2"))
+	wd = <-changes
+	require.NoError(t, wd.Err)
+	require.Equal(t, []string{"ks1"}, wd.Value)
+
+	watchCancel()
+	wd = <-changes
+	require.True(t, topo.IsErrType(wd.Err, topo.Interrupted), "unexpected error: %v", wd.Err)
+	_, ok := <-changes
+	require.False(t, ok, "changes should be closed")
+}
+
+func TestWatchSrvKeyspaceNames(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "zone1")
+	defer ts.Close()
+
+	require.NoError(t, ts.UpdateSrvKeyspace(ctx, "zone1", "ks1", &topodatapb.SrvKeyspace{}))
+
+	current, changes, err := ts.WatchSrvKeyspaceNames(ctx, "zone1")
+	require.NoError(t, err)
+	require.NoError(t, current.Err)
+	require.Equal(t, []string{"ks1"}, current.Value)
+
+	// Updating a SrvKeyspace does not change the list.
+	require.NoError(t, ts.UpdateSrvKeyspace(ctx, "zone1", "ks1", &topodatapb.SrvKeyspace{}))
+	require.NoError(t, ts.UpdateSrvKeyspace(ctx, "zone1", "ks2", &topodatapb.SrvKeyspace{}))
+	wd := <-changes
+	require.NoError(t, wd.Err)
+	require.Equal(t, []string{"ks1", "ks2"}, wd.Value)
+
+	require.NoError(t, ts.DeleteSrvKeyspace(ctx, "zone1", "ks2"))
+	wd = <-changes
+	require.NoError(t, wd.Err)
+	require.Equal(t, []string{"ks1"}, wd.Value)
+
+	// An unknown cell is reported in the current value.
+	current, _, err = ts.WatchSrvKeyspaceNames(ctx, "unknown")
+	require.NoError(t, err)
+	require.True(t, topo.IsErrType(current.Err, topo.NoNode), "unexpected error: %v", current.Err)
+}
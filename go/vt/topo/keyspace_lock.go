@@ -46,7 +46,20 @@ func (s *keyspaceLock) Path() string {
 func (ts *Server) LockKeyspace(ctx context.Context, keyspace, action string) (context.Context, func(*error), error) {
 	return ts.internalLock(ctx, &keyspaceLock{
 		keyspace: keyspace,
-	}, action, true)
+	}, action, lockBlocking)
+}
+
+// LockKeyspaceShared will lock the keyspace in shared mode, and return:
+// - a context with a locksInfo structure for future reference.
+// - an unlock method
+// - an error if anything failed.
+//
+// Any number of processes can hold a shared lock on a keyspace at once,
+// but not along with the lock taken by LockKeyspace. See LockShardShared.
+func (ts *Server) LockKeyspaceShared(ctx context.Context, keyspace, action string) (context.Context, func(*error), error) {
+	return ts.internalLock(ctx, &keyspaceLock{
+		keyspace: keyspace,
+	}, action, lockShared)
 }
 
 // CheckKeyspaceLocked can be called on a context to make sure we have the lock
@@ -0,0 +1,96 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topo
+
+import (
+	"context"
+	"path"
+)
+
+type keyspaceRoutingLock struct {
+	keyspace string
+}
+
+var _ iTopoLock = (*keyspaceRoutingLock)(nil)
+
+func (s *keyspaceRoutingLock) Type() string {
+	return "keyspace routing"
+}
+
+func (s *keyspaceRoutingLock) ResourceName() string {
+	return path.Join(s.keyspace, KeyspaceRoutingPath)
+}
+
+func (s *keyspaceRoutingLock) Path() string {
+	return KeyspaceRoutingLockPath(s.keyspace)
+}
+
+// KeyspaceRoutingLockPath returns the directory of the global cell the
+// routing lock of the keyspace is taken on, e.g. for GetHeldLocks.
+func KeyspaceRoutingLockPath(keyspace string) string {
+	return path.Join(KeyspacesPath, keyspace, KeyspaceRoutingPath)
+}
+
+// LockKeyspaceRouting will lock the routing of the keyspace, i.e. its
+// SrvKeyspaces, and return:
+// - a context with a locksInfo structure for future reference.
+// - an unlock method
+// - an error if anything failed.
+//
+// The routing lock is distinct from the keyspace lock and from the shard
+// locks: the routing changes of a keyspace, like its rebuilds and the
+// switches of its read traffic, are serialized with each other, but not with
+// the reparents and the backups. The writers of the SrvKeyspaces that hold the
+// keyspace lock, like the switches of the write traffic, take the routing
+// lock as well.
+//
+// The locks are taken in this order, so that their holders can't deadlock:
+//  1. the keyspace locks,
+//  2. the keyspace routing locks, of the source keyspace of a workflow before
+//     the one of its target keyspace,
+//  3. the routing rules lock (see LockRoutingRules).
+func (ts *Server) LockKeyspaceRouting(ctx context.Context, keyspace, action string) (context.Context, func(*error), error) {
+	lt := &keyspaceRoutingLock{keyspace: keyspace}
+	lockCtx, unlock, err := ts.internalLock(ctx, lt, action, lockBlocking)
+	if IsErrType(err, NoNode) {
+		// The directory of the lock is created the first time it is taken.
+		filePath := path.Join(lt.Path(), KeyspaceRoutingFile)
+		if _, err = ts.globalCell.Create(ctx, filePath, []byte{}); err == nil || IsErrType(err, NodeExists) {
+			lockCtx, unlock, err = ts.internalLock(ctx, lt, action, lockBlocking)
+		}
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return lockCtx, unlock, nil
+}
+
+// CheckKeyspaceRoutingLocked can be called on a context to make sure we
+// have the routing lock for a given keyspace.
+func CheckKeyspaceRoutingLocked(ctx context.Context, keyspace string) error {
+	return checkLocked(ctx, &keyspaceRoutingLock{keyspace: keyspace})
+}
+
+// deleteKeyspaceRoutingLock deletes the directory of the routing lock of
+// the keyspace, if any.
+func (ts *Server) deleteKeyspaceRoutingLock(ctx context.Context, keyspace string) error {
+	filePath := path.Join(KeyspaceRoutingLockPath(keyspace), KeyspaceRoutingFile)
+	if err := ts.globalCell.Delete(ctx, filePath, nil); err != nil && !IsErrType(err, NoNode) {
+		return err
+	}
+	return nil
+}
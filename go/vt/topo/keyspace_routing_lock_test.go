@@ -0,0 +1,97 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topo_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+)
+
+func TestTopoKeyspaceRoutingLock(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "zone1")
+	defer ts.Close()
+
+	currentTopoLockTimeout := topo.LockTimeout
+	topo.LockTimeout = time.Second
+	defer func() {
+		topo.LockTimeout = currentTopoLockTimeout
+	}()
+
+	ks := "ks"
+	shard := "80-"
+	_, err := ts.GetOrCreateShard(ctx, ks, shard)
+	require.NoError(t, err)
+
+	routingCtx, unlockRouting, err := ts.LockKeyspaceRouting(ctx, ks, "SwitchTraffic")
+	require.NoError(t, err)
+	require.NoError(t, topo.CheckKeyspaceRoutingLocked(routingCtx, ks))
+	require.ErrorContains(t, topo.CheckKeyspaceLocked(routingCtx, ks), "is not locked")
+
+	// The routing lock is exposed along the other locks.
+	locks, err := ts.GetHeldLocks(ctx, topo.KeyspaceRoutingLockPath(ks))
+	require.NoError(t, err)
+	require.Len(t, locks, 1)
+	require.Equal(t, "SwitchTraffic", locks[0].Lock.Action)
+
+	// The shard locks are not held up by the routing lock.
+	_, unlockShard, err := ts.LockShard(ctx, ks, shard, "PlannedReparentShard")
+	require.NoError(t, err)
+	unlockShard(&err)
+	require.NoError(t, err)
+
+	// Nor is the keyspace lock.
+	_, unlockKeyspace, err := ts.LockKeyspace(ctx, ks, "ApplySchema")
+	require.NoError(t, err)
+	unlockKeyspace(&err)
+	require.NoError(t, err)
+
+	// But the other routing changes are.
+	_, _, err2 := ts.LockKeyspaceRouting(ctx, ks, "RebuildKeyspace")
+	require.True(t, topo.IsErrType(err2, topo.Timeout), "expected Timeout, got %v", err2)
+
+	unlockRouting(&err)
+	require.NoError(t, err)
+
+	// The keyspace lock is not enough for the routing changes, whose lock is
+	// taken after it.
+	keyspaceCtx, unlockKeyspace, err := ts.LockKeyspace(ctx, ks, "SwitchWrites")
+	require.NoError(t, err)
+	require.ErrorContains(t, topo.CheckKeyspaceRoutingLocked(keyspaceCtx, ks), "is not locked")
+	routingCtx, unlockRouting, err = ts.LockKeyspaceRouting(keyspaceCtx, ks, "SwitchWrites")
+	require.NoError(t, err)
+	require.NoError(t, topo.CheckKeyspaceRoutingLocked(routingCtx, ks))
+	require.NoError(t, topo.CheckKeyspaceLocked(routingCtx, ks))
+	unlockRouting(&err)
+	require.NoError(t, err)
+	unlockKeyspace(&err)
+	require.NoError(t, err)
+
+	// Deleting the keyspace deletes the directory of its routing lock.
+	require.NoError(t, ts.DeleteShard(ctx, ks, shard))
+	require.NoError(t, ts.DeleteKeyspace(ctx, ks))
+	keyspaces, err := ts.GetKeyspaces(ctx)
+	require.NoError(t, err)
+	require.Empty(t, keyspaces)
+}
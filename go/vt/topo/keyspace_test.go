@@ -0,0 +1,57 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"vitess.io/vitess/go/viperutil/vipertest"
+)
+
+func TestReadConcurrencyReload(t *testing.T) {
+	h := vipertest.NewHarness(t, "config.yaml", "topo:\n  read_concurrency: 8\n")
+	vipertest.Track(h, topoReadConcurrency)
+	h.Start()
+	assert.Equal(t, 8, ReadConcurrency())
+
+	changes := h.WriteConfig("topo:\n  read_concurrency: 16\n")
+	changes.AssertChanged(t, "topo.read_concurrency")
+	assert.Equal(t, 16, ReadConcurrency())
+
+	// A concurrency which would block all the reads is rejected.
+	h.WriteConfig("topo:\n  read_concurrency: 0\n")
+	assert.Equal(t, 16, ReadConcurrency())
+}
+
+func TestCappedReadConcurrency(t *testing.T) {
+	h := vipertest.NewHarness(t, "config.yaml", "topo:\n  read_concurrency: 8\n")
+	vipertest.Track(h, topoReadConcurrency)
+	vipertest.Track(h, topoGlobalReadConcurrency)
+	vipertest.Track(h, topoCellReadConcurrency)
+	h.Start()
+	assert.Equal(t, 8, cappedReadConcurrency(topoGlobalReadConcurrency))
+	assert.Equal(t, 8, cappedReadConcurrency(topoCellReadConcurrency))
+
+	// The reads fanned out are capped by the concurrency limit of the topo
+	// server they read.
+	changes := h.WriteConfig("topo:\n  read_concurrency: 8\n  global_read_concurrency: 4\n  cell_read_concurrency: 16\n")
+	changes.AssertChanged(t, "topo.global_read_concurrency", "topo.cell_read_concurrency")
+	assert.Equal(t, 4, cappedReadConcurrency(topoGlobalReadConcurrency))
+	assert.Equal(t, 8, cappedReadConcurrency(topoCellReadConcurrency))
+}
@@ -0,0 +1,281 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/trace"
+	"vitess.io/vitess/go/vt/log"
+)
+
+var (
+	// LockContentionThreshold is how long a lock is waited for before the
+	// chain of the holders blocking it is captured. 0 disables it.
+	LockContentionThreshold = 10 * time.Second
+
+	topoLockContentions = stats.NewCountersWithSingleLabel(
+		"TopologyLockContentions",
+		"TopologyLockContentions locks waited for longer than --lock-contention-threshold, by type of resource",
+		"Type")
+
+	topoLockDeadlocks = stats.NewCountersWithSingleLabel(
+		"TopologyLockDeadlocks",
+		"TopologyLockDeadlocks locks waited for longer than --lock-contention-threshold whose chain of holders is a cycle, by type of resource",
+		"Type")
+
+	// lockWaitSeq makes the names of the LockWaits of the process unique.
+	lockWaitSeq atomic.Int64
+)
+
+// LockWait is a wait for a lock, by a process holding other locks, that
+// exceeded LockContentionThreshold. It is registered under LockWaitsPath in
+// the global cell while it lasts, so that the processes waiting for the
+// locks it holds can tell what it is waiting on.
+type LockWait struct {
+	// Path is the directory of the resource waited for.
+	Path string
+	// Holding are the directories of the resources whose lock is held.
+	Holding []string
+	// Waiter describes the waiting process and its action.
+	Waiter *Lock
+	// Since is when the wait started.
+	Since string
+}
+
+// LockChainLink is a link of the chain of the holders blocking a lock, as
+// returned by GetLockChain.
+type LockChainLink struct {
+	// Path is the directory of the locked resource.
+	Path string
+	// Holder is the holder of the lock.
+	Holder *HeldLock
+	// WaitingOn is the directory of the resource whose lock Holder is
+	// waiting for, if any, and WaitingSince is when it started waiting.
+	WaitingOn    string
+	WaitingSince string
+}
+
+// holder returns a description of the holder of the lock of the link.
+func (link *LockChainLink) holder() string {
+	l := link.Holder.Lock
+	if l == nil {
+		return "an unknown holder"
+	}
+	return fmt.Sprintf("%v (%v@%v since %v)", l.Action, l.UserName, l.HostName, l.Time)
+}
+
+// FormatLockChain returns a description of the chain of holders blocking
+// a lock, as returned by GetLockChain.
+func FormatLockChain(chain []*LockChainLink, deadlock bool) string {
+	if len(chain) == 0 {
+		return "no holder found"
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%v is held by %v", chain[0].Path, chain[0].holder())
+	for _, link := range chain[1:] {
+		fmt.Fprintf(&sb, ", which waits on %v, held by %v", link.Path, link.holder())
+	}
+	if deadlock {
+		fmt.Fprintf(&sb, ", which waits on %v: deadlock", chain[len(chain)-1].WaitingOn)
+	}
+	return sb.String()
+}
+
+// GetLockChain returns the chain of the holders blocking the lock of the
+// resource on the given directory of the global cell: its holder, then the
+// holder of the lock the previous holder is waiting for, if any, and so
+// on. Only the waits that exceeded LockContentionThreshold are known.
+// deadlock is set if the last holder waits on a resource of the chain.
+func (ts *Server) GetLockChain(ctx context.Context, dirPath string) (chain []*LockChainLink, deadlock bool, err error) {
+	waits, err := ts.getLockWaits(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	visited := make(map[string]bool)
+	for !visited[dirPath] {
+		visited[dirPath] = true
+		locks, err := ts.GetHeldLocks(ctx, dirPath)
+		if err != nil {
+			return nil, false, err
+		}
+		if len(locks) == 0 {
+			// Released meanwhile.
+			return chain, false, nil
+		}
+		link := &LockChainLink{Path: dirPath, Holder: locks[0]}
+		chain = append(chain, link)
+
+		i := slices.IndexFunc(waits, func(w *LockWait) bool {
+			return slices.Contains(w.Holding, dirPath)
+		})
+		if i < 0 {
+			return chain, false, nil
+		}
+		link.WaitingOn = waits[i].Path
+		link.WaitingSince = waits[i].Since
+		dirPath = waits[i].Path
+	}
+	return chain, true, nil
+}
+
+// getLockWaits returns the current LockWaits.
+func (ts *Server) getLockWaits(ctx context.Context) ([]*LockWait, error) {
+	entries, err := ts.globalCell.ListDir(ctx, LockWaitsPath, false /*full*/)
+	switch {
+	case err == nil:
+	case IsErrType(err, NoNode):
+		return nil, nil
+	default:
+		return nil, err
+	}
+
+	// A wait can't outlast the timeout of the lock, so the older ones were
+	// left behind by processes that died, and are deleted.
+	oldest := time.Now().Add(-LockTimeout - RemoteOperationTimeout)
+	var waits []*LockWait
+	for _, entry := range entries {
+		filePath := path.Join(LockWaitsPath, entry.Name)
+		data, version, err := ts.globalCell.Get(ctx, filePath)
+		if err != nil {
+			if IsErrType(err, NoNode) {
+				continue
+			}
+			return nil, err
+		}
+		w := &LockWait{}
+		if err := json.Unmarshal(data, w); err != nil {
+			ts.deleteStaleLockWait(ctx, filePath, version)
+			continue
+		}
+		if since, err := time.Parse(time.RFC3339, w.Since); err != nil || since.Before(oldest) {
+			ts.deleteStaleLockWait(ctx, filePath, version)
+			continue
+		}
+		waits = append(waits, w)
+	}
+	return waits, nil
+}
+
+// deleteStaleLockWait deletes the file of a LockWait left behind, unless it
+// changed since it was read at version. The errors are only logged, as the
+// next reader will try again.
+func (ts *Server) deleteStaleLockWait(ctx context.Context, filePath string, version Version) {
+	log.Infof("Deleting stale lock wait %v", filePath)
+	if err := ts.globalCell.Delete(ctx, filePath, version); err != nil && !IsErrType(err, NoNode) && !IsErrType(err, BadVersion) {
+		log.Warningf("cannot delete stale lock wait %v: %v", filePath, err)
+	}
+}
+
+// watchLockContention captures the chain of the holders of the lock of lt
+// if it is waited for longer than LockContentionThreshold, and registers
+// the wait if the caller holds other locks. The returned function must be
+// called once the wait is over, and returns the description of the chain,
+// if it was captured.
+func (ts *Server) watchLockContention(ctx context.Context, lt iTopoLock, l *Lock, holding []string) func() string {
+	if LockContentionThreshold <= 0 {
+		return func() string { return "" }
+	}
+
+	done := make(chan struct{})
+	var (
+		wg    sync.WaitGroup
+		chain string
+	)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		since := time.Now()
+		timer := time.NewTimer(LockContentionThreshold)
+		defer timer.Stop()
+		select {
+		case <-done:
+			return
+		case <-timer.C:
+		}
+
+		// The wait may outlast the context of the lock.
+		ctx := trace.CopySpan(context.TODO(), ctx)
+		topoLockContentions.Add(lt.Type(), 1)
+
+		if len(holding) > 0 {
+			filePath, err := ts.registerLockWait(ctx, lt, l, holding, since)
+			if err != nil {
+				log.Warningf("cannot register the wait for the lock on %v %v: %v", lt.Type(), lt.ResourceName(), err)
+			} else {
+				defer func() {
+					ctx, cancel := context.WithTimeout(ctx, RemoteOperationTimeout)
+					defer cancel()
+					if err := ts.globalCell.Delete(ctx, filePath, nil); err != nil {
+						log.Warningf("cannot unregister the wait for the lock on %v %v: %v", lt.Type(), lt.ResourceName(), err)
+					}
+				}()
+			}
+		}
+
+		getCtx, cancel := context.WithTimeout(ctx, RemoteOperationTimeout)
+		links, deadlock, err := ts.GetLockChain(getCtx, lt.Path())
+		cancel()
+		if err != nil {
+			log.Warningf("Waited more than %v for the lock on %v %v for action %v, and cannot get its holders: %v", LockContentionThreshold, lt.Type(), lt.ResourceName(), l.Action, err)
+		} else {
+			if deadlock {
+				topoLockDeadlocks.Add(lt.Type(), 1)
+			}
+			chain = FormatLockChain(links, deadlock)
+			log.Warningf("Waited more than %v for the lock on %v %v for action %v: %v", LockContentionThreshold, lt.Type(), lt.ResourceName(), l.Action, chain)
+		}
+		<-done
+	}()
+
+	return func() string {
+		close(done)
+		wg.Wait()
+		return chain
+	}
+}
+
+// registerLockWait registers the wait for the lock of lt under
+// LockWaitsPath, and returns the path of its file.
+func (ts *Server) registerLockWait(ctx context.Context, lt iTopoLock, l *Lock, holding []string, since time.Time) (string, error) {
+	data, err := json.MarshalIndent(&LockWait{
+		Path:    lt.Path(),
+		Holding: holding,
+		Waiter:  l,
+		Since:   since.Format(time.RFC3339),
+	}, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	filePath := path.Join(LockWaitsPath, fmt.Sprintf("%v-%v-%v", l.HostName, os.Getpid(), lockWaitSeq.Add(1)))
+	ctx, cancel := context.WithTimeout(ctx, RemoteOperationTimeout)
+	defer cancel()
+	if _, err := ts.globalCell.Create(ctx, filePath, data); err != nil {
+		return "", err
+	}
+	return filePath, nil
+}
@@ -0,0 +1,161 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topo_test
+
+import (
+	"context"
+	"encoding/json"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+)
+
+func TestLockContention(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "zone1")
+	defer ts.Close()
+
+	// The waits only end when the test ends them, so that they last while
+	// their chains are checked.
+	currentTopoLockTimeout := topo.LockTimeout
+	currentLockContentionThreshold := topo.LockContentionThreshold
+	topo.LockTimeout = time.Minute
+	topo.LockContentionThreshold = 100 * time.Millisecond
+	defer func() {
+		topo.LockTimeout = currentTopoLockTimeout
+		topo.LockContentionThreshold = currentLockContentionThreshold
+	}()
+
+	_, err := ts.GetOrCreateShard(ctx, "ks", "-80")
+	require.NoError(t, err)
+	conn, err := ts.ConnForCell(ctx, topo.GlobalCell)
+	require.NoError(t, err)
+	waitForLockWaits := func(n int) {
+		require.Eventually(t, func() bool {
+			entries, err := conn.ListDir(ctx, topo.LockWaitsPath, false)
+			return err == nil && len(entries) == n
+		}, 10*time.Second, 10*time.Millisecond)
+	}
+	snapshot := stats.TakeSnapshot()
+
+	// A reparent holds the shard lock, and a reshard the keyspace lock.
+	reparentCtx, unlockShard, err := ts.LockShard(ctx, "ks", "-80", "PlannedReparentShard")
+	require.NoError(t, err)
+	reshardCtx, unlockKeyspace, err := ts.LockKeyspace(ctx, "ks", "Reshard")
+	require.NoError(t, err)
+
+	// Then the reshard waits for the shard lock.
+	type lockResult struct {
+		unlock func(*error)
+		err    error
+	}
+	reshardResult := make(chan lockResult)
+	go func() {
+		_, unlock, err := ts.LockShard(reshardCtx, "ks", "-80", "Reshard")
+		reshardResult <- lockResult{unlock, err}
+	}()
+	waitForLockWaits(1)
+
+	// The chain of the holders of the keyspace lock shows the reshard waits
+	// for the reparent.
+	chain, deadlock, err := ts.GetLockChain(ctx, "keyspaces/ks")
+	require.NoError(t, err)
+	assert.False(t, deadlock)
+	require.Len(t, chain, 2)
+	assert.Equal(t, "Reshard", chain[0].Holder.Lock.Action)
+	assert.Equal(t, "keyspaces/ks/shards/-80", chain[0].WaitingOn)
+	assert.Equal(t, "PlannedReparentShard", chain[1].Holder.Lock.Action)
+	assert.Empty(t, chain[1].WaitingOn)
+
+	// And the reparent waiting for the keyspace lock is a deadlock, which
+	// is reported in the lock timeout error.
+	reparentCtx, reparentCancel := context.WithTimeout(reparentCtx, 2*time.Second)
+	defer reparentCancel()
+	_, _, err = ts.LockKeyspace(reparentCtx, "ks", "PlannedReparentShard")
+	require.True(t, topo.IsErrType(err, topo.Timeout), "expected Timeout, got %v", err)
+	assert.ErrorContains(t, err, "keyspaces/ks is held by Reshard")
+	assert.ErrorContains(t, err, "which waits on keyspaces/ks/shards/-80, held by PlannedReparentShard")
+	assert.ErrorContains(t, err, "which waits on keyspaces/ks: deadlock")
+
+	// Releasing the shard lock ends the wait of the reshard.
+	unlockShard(&err)
+	result := <-reshardResult
+	require.NoError(t, result.err)
+	result.unlock(&err)
+	unlockKeyspace(&err)
+
+	assert.Equal(t, map[string]int64{"keyspace": 1, "shard": 1}, snapshot.Diff()["TopologyLockContentions"])
+	assert.Equal(t, map[string]int64{"keyspace": 1}, snapshot.Diff()["TopologyLockDeadlocks"])
+
+	// The waits are unregistered once over.
+	_, err = conn.ListDir(ctx, topo.LockWaitsPath, false)
+	require.True(t, topo.IsErrType(err, topo.NoNode), "expected NoNode, got %v", err)
+}
+
+func TestLockContentionStaleWaits(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "zone1")
+	defer ts.Close()
+
+	_, err := ts.GetOrCreateShard(ctx, "ks", "-80")
+	require.NoError(t, err)
+	_, unlockShard, err := ts.LockShard(ctx, "ks", "-80", "PlannedReparentShard")
+	require.NoError(t, err)
+	defer unlockShard(&err)
+
+	// Waits left behind by processes that died, one of them unreadable,
+	// and a current one.
+	conn, err := ts.ConnForCell(ctx, topo.GlobalCell)
+	require.NoError(t, err)
+	newLockWait := func(since time.Time) []byte {
+		data, err := json.Marshal(&topo.LockWait{
+			Path:    "keyspaces/ks2",
+			Holding: []string{"keyspaces/ks/shards/-80"},
+			Waiter:  &topo.Lock{Action: "Reshard"},
+			Since:   since.Format(time.RFC3339),
+		})
+		require.NoError(t, err)
+		return data
+	}
+	_, err = conn.Create(ctx, path.Join(topo.LockWaitsPath, "old"), newLockWait(time.Now().Add(-time.Hour)))
+	require.NoError(t, err)
+	_, err = conn.Create(ctx, path.Join(topo.LockWaitsPath, "bad"), []byte("bad"))
+	require.NoError(t, err)
+	_, err = conn.Create(ctx, path.Join(topo.LockWaitsPath, "current"), newLockWait(time.Now()))
+	require.NoError(t, err)
+
+	chain, deadlock, err := ts.GetLockChain(ctx, "keyspaces/ks/shards/-80")
+	require.NoError(t, err)
+	assert.False(t, deadlock)
+	require.Len(t, chain, 1)
+	assert.Equal(t, "keyspaces/ks2", chain[0].WaitingOn)
+
+	// Reading the waits deleted the stale ones.
+	entries, err := conn.ListDir(ctx, topo.LockWaitsPath, false)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "current", entries[0].Name)
+}
@@ -0,0 +1,247 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/trace"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/proto/vtrpc"
+	"vitess.io/vitess/go/vt/vterrors"
+)
+
+// An emergency lock request registers a preemption request in the global
+// cell at lock_preemptions/<resource path>/<id>, with the Lock of the
+// requester as contents, while it waits for the lock. The requests are kept
+// out of the directory of the resource, so that they don't create it when
+// the resource doesn't exist. The holders of the
+// low priority locks of the resource poll for these requests, and cancel
+// the context of their lock when they find one, so that their action
+// aborts and releases it.
+
+// LockPriority is the priority of the locks taken with a context (see
+// WithLockPriority).
+type LockPriority int
+
+const (
+	// NormalLockPriority is the priority of the locks taken with the
+	// contexts which don't set one: they neither preempt the other holders
+	// nor are preempted.
+	NormalLockPriority = LockPriority(iota)
+
+	// LowLockPriority is for the locks of the routine actions that can be
+	// aborted at any point, e.g. PlannedReparentShard: they are preempted by
+	// the emergency lock requests of their resource.
+	LowLockPriority
+
+	// EmergencyLockPriority is for the locks of the actions that must not
+	// wait behind routine ones, e.g. EmergencyReparentShard: while they
+	// wait for the lock, they preempt the holders of the low priority
+	// locks of the resource.
+	EmergencyLockPriority
+)
+
+// lockPriorityKeyType is the type of the context key of WithLockPriority.
+type lockPriorityKeyType int
+
+var lockPriorityKey lockPriorityKeyType
+
+// WithLockPriority returns a context taking the locks with the given
+// priority.
+func WithLockPriority(ctx context.Context, priority LockPriority) context.Context {
+	return context.WithValue(ctx, lockPriorityKey, priority)
+}
+
+// LockPriorityOf returns the priority of the locks taken with ctx.
+func LockPriorityOf(ctx context.Context) LockPriority {
+	priority, _ := ctx.Value(lockPriorityKey).(LockPriority)
+	return priority
+}
+
+var (
+	// lockPreemptionPollInterval is how often the holders of the low
+	// priority locks check whether they are preempted.
+	lockPreemptionPollInterval = time.Second
+
+	topoLockPreemptions = stats.NewCountersWithSingleLabel(
+		"TopologyLockPreemptions",
+		"TopologyLockPreemptions low priority locks preempted by an emergency lock request, by type of resource",
+		"Type")
+
+	// lockPreemptionSeq makes the names of the preemption requests of the
+	// process unique.
+	lockPreemptionSeq atomic.Int64
+)
+
+// requestLockPreemption registers a preemption request for the lock of lt,
+// and returns the function unregistering it, to be called once the wait
+// for the lock is over. The errors are logged, as the lock can still be
+// waited for without preempting its holders.
+func (ts *Server) requestLockPreemption(ctx context.Context, lt iTopoLock, l *Lock) func() {
+	j, err := l.ToJSON()
+	if err != nil {
+		log.Warningf("cannot request the preemption of the lock on %v %v: %v", lt.Type(), lt.ResourceName(), err)
+		return func() {}
+	}
+	filePath := path.Join(LockPreemptionsPath, lt.Path(), fmt.Sprintf("%v-%v-%v", l.HostName, os.Getpid(), lockPreemptionSeq.Add(1)))
+	createCtx, cancel := context.WithTimeout(ctx, RemoteOperationTimeout)
+	_, err = ts.globalCell.Create(createCtx, filePath, []byte(j))
+	cancel()
+	if err != nil {
+		log.Warningf("cannot request the preemption of the lock on %v %v: %v", lt.Type(), lt.ResourceName(), err)
+		return func() {}
+	}
+
+	return func() {
+		// The wait may have outlasted the context of the lock.
+		ctx := trace.CopySpan(context.TODO(), ctx)
+		ctx, cancel := context.WithTimeout(ctx, RemoteOperationTimeout)
+		defer cancel()
+		if err := ts.globalCell.Delete(ctx, filePath, nil); err != nil && !IsErrType(err, NoNode) {
+			log.Warningf("cannot remove the preemption request of the lock on %v %v: %v", lt.Type(), lt.ResourceName(), err)
+		}
+	}
+}
+
+// lockPreemptionWatch cancels the context of a low priority lock once it
+// is preempted.
+type lockPreemptionWatch struct {
+	action   string
+	cancel   context.CancelCauseFunc
+	stopPoll context.CancelFunc
+	done     chan struct{}
+
+	// mu protects preempted, which is set when the lock is preempted.
+	mu        sync.Mutex
+	preempted error
+}
+
+// watchLockPreemption returns a context derived from ctx which is canceled
+// when an emergency request for the lock of lt is registered, and the
+// watch, to be stopped when the lock is released.
+func (ts *Server) watchLockPreemption(ctx context.Context, lt iTopoLock, l *Lock) (context.Context, *lockPreemptionWatch) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	pollCtx, stopPoll := context.WithCancel(trace.CopySpan(context.Background(), ctx))
+	w := &lockPreemptionWatch{
+		action:   l.Action,
+		cancel:   cancel,
+		stopPoll: stopPoll,
+		done:     make(chan struct{}),
+	}
+	go w.poll(pollCtx, ts, lt)
+	return ctx, w
+}
+
+// poll checks for the preemption requests until ctx is canceled or the
+// lock is preempted.
+func (w *lockPreemptionWatch) poll(ctx context.Context, ts *Server, lt iTopoLock) {
+	defer close(w.done)
+
+	ticker := time.NewTicker(lockPreemptionPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		getCtx, cancel := context.WithTimeout(ctx, RemoteOperationTimeout)
+		requester, err := ts.getLockPreemptionRequester(getCtx, lt)
+		cancel()
+		if err != nil {
+			log.Warningf("cannot check the preemption of the lock on %v %v: %v", lt.Type(), lt.ResourceName(), err)
+			continue
+		}
+		if requester == nil {
+			continue
+		}
+
+		err = vterrors.Errorf(vtrpc.Code_ABORTED, "lock on %v %v for action %v was preempted by %v (%v@%v)", lt.Type(), lt.ResourceName(), w.action, requester.Action, requester.UserName, requester.HostName)
+		log.Warning(err)
+		topoLockPreemptions.Add(lt.Type(), 1)
+		w.mu.Lock()
+		w.preempted = err
+		w.mu.Unlock()
+		w.cancel(err)
+		return
+	}
+}
+
+// stop stops the watch, and cancels the context of the lock.
+func (w *lockPreemptionWatch) stop() {
+	w.stopPoll()
+	<-w.done
+	w.cancel(nil)
+}
+
+// err returns the error the context of the lock was canceled with, if it
+// was preempted.
+func (w *lockPreemptionWatch) err() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.preempted
+}
+
+// getLockPreemptionRequester returns the Lock of the requester of a
+// pending preemption request for the lock of lt, if any. The requests
+// older than a lock wait can be are removed, as they were left behind by
+// processes that died.
+func (ts *Server) getLockPreemptionRequester(ctx context.Context, lt iTopoLock) (*Lock, error) {
+	dirPath := path.Join(LockPreemptionsPath, lt.Path())
+	entries, err := ts.globalCell.ListDir(ctx, dirPath, false /*full*/)
+	switch {
+	case err == nil:
+	case IsErrType(err, NoNode):
+		return nil, nil
+	default:
+		return nil, err
+	}
+
+	oldest := time.Now().Add(-LockTimeout - RemoteOperationTimeout)
+	for _, entry := range entries {
+		filePath := path.Join(dirPath, entry.Name)
+		data, _, err := ts.globalCell.Get(ctx, filePath)
+		if err != nil {
+			if IsErrType(err, NoNode) {
+				continue
+			}
+			return nil, err
+		}
+		requester := &Lock{}
+		if err := json.Unmarshal(data, requester); err == nil {
+			if since, err := time.Parse(time.RFC3339, requester.Time); err == nil && !since.Before(oldest) {
+				return requester, nil
+			}
+		}
+
+		log.Warningf("Removing expired preemption request of the lock on %v %v: %s", lt.Type(), lt.ResourceName(), data)
+		if err := ts.globalCell.Delete(ctx, filePath, nil); err != nil && !IsErrType(err, NoNode) {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topo_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+func TestLockPreemption(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "zone1")
+	defer ts.Close()
+
+	currentTopoLockTimeout := topo.LockTimeout
+	topo.LockTimeout = 3 * time.Second
+	defer func() {
+		topo.LockTimeout = currentTopoLockTimeout
+	}()
+
+	_, err := ts.GetOrCreateShard(ctx, "ks", "-80")
+	require.NoError(t, err)
+	conn, err := ts.ConnForCell(ctx, topo.GlobalCell)
+	require.NoError(t, err)
+	snapshot := stats.TakeSnapshot()
+
+	for _, shared := range []bool{false, true} {
+		// A planned reparent holds the shard lock with a low priority.
+		lowCtx := topo.WithLockPriority(ctx, topo.LowLockPriority)
+		var (
+			prsCtx    context.Context
+			unlockPRS func(*error)
+		)
+		if shared {
+			prsCtx, unlockPRS, err = ts.LockShardShared(lowCtx, "ks", "-80", "PlannedReparentShard")
+		} else {
+			prsCtx, unlockPRS, err = ts.LockShard(lowCtx, "ks", "-80", "PlannedReparentShard")
+		}
+		require.NoError(t, err)
+
+		// An emergency reparent preempts it.
+		emergencyCtx := topo.WithLockPriority(ctx, topo.EmergencyLockPriority)
+		ersDone := make(chan error)
+		go func() {
+			_, unlock, err := ts.LockShard(emergencyCtx, "ks", "-80", "EmergencyReparentShard")
+			if err == nil {
+				unlock(&err)
+			}
+			ersDone <- err
+		}()
+
+		select {
+		case <-prsCtx.Done():
+		case <-time.After(topo.LockTimeout):
+			require.FailNow(t, "the planned reparent was not preempted")
+		}
+		cause := context.Cause(prsCtx)
+		assert.Equal(t, vtrpcpb.Code_ABORTED, vterrors.Code(cause))
+		assert.ErrorContains(t, cause, "preempted by EmergencyReparentShard")
+		if !shared {
+			assert.Equal(t, cause, topo.CheckShardLocked(prsCtx, "ks", "-80"))
+		}
+
+		// The planned reparent aborts and releases the lock, which lets the
+		// emergency reparent through.
+		err = cause
+		unlockPRS(&err)
+		require.NoError(t, <-ersDone)
+
+		// The preemption request is gone.
+		entries, err := conn.ListDir(ctx, topo.LockPreemptionsPath+"/keyspaces/ks/shards/-80", false)
+		if err == nil {
+			assert.Empty(t, entries)
+		} else {
+			assert.True(t, topo.IsErrType(err, topo.NoNode), "expected NoNode, got %v", err)
+		}
+	}
+	assert.Equal(t, map[string]int64{"shard": 2}, snapshot.Diff()["TopologyLockPreemptions"])
+
+	// The locks of a normal priority are not preempted.
+	_, unlockReparent, err := ts.LockShard(ctx, "ks", "-80", "DeleteTablet")
+	require.NoError(t, err)
+	_, _, err = ts.LockShard(topo.WithLockPriority(ctx, topo.EmergencyLockPriority), "ks", "-80", "EmergencyReparentShard")
+	require.True(t, topo.IsErrType(err, topo.Timeout), "expected Timeout, got %v", err)
+	unlockReparent(&err)
+
+	// Nor are the low priority locks without an emergency request.
+	prsCtx, unlockPRS, err := ts.LockShard(topo.WithLockPriority(ctx, topo.LowLockPriority), "ks", "-80", "PlannedReparentShard")
+	require.NoError(t, err)
+	time.Sleep(2 * time.Second)
+	require.NoError(t, prsCtx.Err())
+	require.NoError(t, topo.CheckShardLocked(prsCtx, "ks", "-80"))
+	unlockPRS(&err)
+	require.NoError(t, err)
+	assert.ErrorIs(t, prsCtx.Err(), context.Canceled)
+
+	// The emergency lock requests of the shards which don't exist fail.
+	_, _, err = ts.LockShard(topo.WithLockPriority(ctx, topo.EmergencyLockPriority), "ks", "80-", "EmergencyReparentShard")
+	assert.True(t, topo.IsErrType(err, topo.NoNode), "expected NoNode, got %v", err)
+}
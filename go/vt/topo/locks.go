@@ -19,6 +19,7 @@ package topo
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"os"
 	"os/user"
 	"sync"
@@ -44,6 +45,13 @@ var (
 	// call out to another process.
 	// Used for RPC calls (including topo server calls)
 	RemoteOperationTimeout = 15 * time.Second
+
+	// SharedLockTTL is the time to live of the lease or session backing a
+	// shared lock: the time after which the shared lock of a holder that
+	// died is released. If 0, the one of the exclusive locks is used. The
+	// topology servers may round it, or not support it, see
+	// NamedLockConn.LockNameWithTTL.
+	SharedLockTTL time.Duration
 )
 
 // Lock describes a long-running lock on a keyspace or a shard.
@@ -68,6 +76,8 @@ func init() {
 func registerTopoLockFlags(fs *pflag.FlagSet) {
 	fs.DurationVar(&RemoteOperationTimeout, "remote_operation_timeout", RemoteOperationTimeout, "time to wait for a remote operation")
 	fs.DurationVar(&LockTimeout, "lock-timeout", LockTimeout, "Maximum time to wait when attempting to acquire a lock from the topo server")
+	fs.DurationVar(&SharedLockTTL, "shared-lock-ttl", SharedLockTTL, "Time to live of the lease or session backing a shared lock on the topo server: the time after which the shared lock of a process that died is released. 0 uses the one of the exclusive locks. Only etcd and consul support other values.")
+	fs.DurationVar(&LockContentionThreshold, "lock-contention-threshold", LockContentionThreshold, "Time waited for a lock from the topo server after which the chain of the holders blocking it is logged and reported in the lock timeout errors. 0 disables it.")
 }
 
 // newLock creates a new Lock.
@@ -101,6 +111,10 @@ func (l *Lock) ToJSON() (string, error) {
 type lockInfo struct {
 	lockDescriptor LockDescriptor
 	actionNode     *Lock
+	shared         bool
+	path           string
+	// preemption watches the low priority locks, and is nil otherwise.
+	preemption *lockPreemptionWatch
 }
 
 // locksInfo is the structure used to remember which locks we took
@@ -120,6 +134,19 @@ type locksKeyType int
 
 var locksKey locksKeyType
 
+// lockMode is the way a resource is locked.
+type lockMode int
+
+const (
+	// lockBlocking takes an exclusive lock, waiting for the current holders.
+	lockBlocking lockMode = iota
+	// lockNonBlocking takes an exclusive lock, failing if it is held.
+	lockNonBlocking
+	// lockShared takes a lock that can be held by several processes at
+	// once, but not along with an exclusive lock.
+	lockShared
+)
+
 // iTopoLock is the interface for knowing the resource that is being locked.
 // It allows for better controlling nuances for different lock types and log messages.
 type iTopoLock interface {
@@ -129,8 +156,23 @@ type iTopoLock interface {
 }
 
 // perform the topo lock operation
-func (l *Lock) lock(ctx context.Context, ts *Server, lt iTopoLock, isBlocking bool) (LockDescriptor, error) {
-	log.Infof("Locking %v %v for action %v", lt.Type(), lt.ResourceName(), l.Action)
+//
+// Shared locks are locks on the entries of the SharedLocksPath directory of
+// the resource, taken while holding the exclusive lock of the resource.
+// Exclusive locks wait for these locks to be released once they hold the
+// exclusive lock, so that no new shared lock can be taken meanwhile.
+//
+// holding are the directories of the other locks held by the caller, for
+// the processes its wait blocks to know what it's waiting on.
+//
+// The emergency lock requests preempt the holders of the low priority
+// locks of the resource while they wait (see LockPriority).
+func (l *Lock) lock(ctx context.Context, ts *Server, lt iTopoLock, mode lockMode, holding []string) (LockDescriptor, error) {
+	if mode == lockShared {
+		log.Infof("Locking %v %v shared for action %v", lt.Type(), lt.ResourceName(), l.Action)
+	} else {
+		log.Infof("Locking %v %v for action %v", lt.Type(), lt.ResourceName(), l.Action)
+	}
 
 	ctx, cancel := context.WithTimeout(ctx, LockTimeout)
 	defer cancel()
@@ -143,10 +185,53 @@ func (l *Lock) lock(ctx context.Context, ts *Server, lt iTopoLock, isBlocking bo
 	if err != nil {
 		return nil, err
 	}
-	if isBlocking {
-		return ts.globalCell.Lock(ctx, lt.Path(), j)
+	if mode != lockNonBlocking && LockPriorityOf(ctx) == EmergencyLockPriority {
+		defer ts.requestLockPreemption(ctx, lt, l)()
+	}
+
+	var lockDescriptor LockDescriptor
+	if mode == lockNonBlocking {
+		lockDescriptor, err = ts.globalCell.TryLock(ctx, lt.Path(), j)
+	} else {
+		stopWatching := ts.watchLockContention(ctx, lt, l, holding)
+		if nl, ok := lt.(*namedLock); ok {
+			lockDescriptor, err = lockNameWithTTL(ctx, ts.globalCell, lt.Path(), j, nl.ttl)
+		} else {
+			lockDescriptor, err = ts.globalCell.Lock(ctx, lt.Path(), j)
+		}
+		if chain := stopWatching(); chain != "" && IsErrType(err, Timeout) {
+			err = NewError(Timeout, fmt.Sprintf("%v (%v)", lt.Path(), chain))
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if mode == lockShared {
+		sharedLockDescriptor, err := ts.createSharedLock(ctx, lt, l)
+		releaseLockDescriptor(ctx, lt, lockDescriptor)
+		if err != nil {
+			return nil, err
+		}
+		return sharedLockDescriptor, nil
+	}
+
+	if err := ts.waitForSharedLocks(ctx, lt, j, mode == lockBlocking); err != nil {
+		releaseLockDescriptor(ctx, lt, lockDescriptor)
+		return nil, err
+	}
+	return lockDescriptor, nil
+}
+
+// releaseLockDescriptor releases a lock taken by lock before it could be
+// returned, logging the errors.
+func releaseLockDescriptor(ctx context.Context, lt iTopoLock, lockDescriptor LockDescriptor) {
+	ctx = trace.CopySpan(context.TODO(), ctx)
+	ctx, cancel := context.WithTimeout(ctx, RemoteOperationTimeout)
+	defer cancel()
+	if err := lockDescriptor.Unlock(ctx); err != nil {
+		log.Warningf("unlock %v %v failed: %v", lt.Type(), lt.ResourceName(), err)
 	}
-	return ts.globalCell.TryLock(ctx, lt.Path(), j)
 }
 
 // unlock unlocks a previously locked key.
@@ -174,7 +259,7 @@ func (l *Lock) unlock(ctx context.Context, lt iTopoLock, lockDescriptor LockDesc
 	return lockDescriptor.Unlock(ctx)
 }
 
-func (ts *Server) internalLock(ctx context.Context, lt iTopoLock, action string, isBlocking bool) (context.Context, func(*error), error) {
+func (ts *Server) internalLock(ctx context.Context, lt iTopoLock, action string, mode lockMode) (context.Context, func(*error), error) {
 	i, ok := ctx.Value(locksKey).(*locksInfo)
 	if !ok {
 		i = &locksInfo{
@@ -189,17 +274,38 @@ func (ts *Server) internalLock(ctx context.Context, lt iTopoLock, action string,
 		return nil, nil, vterrors.Errorf(vtrpc.Code_INTERNAL, "lock for %v %v is already held", lt.Type(), lt.ResourceName())
 	}
 
+	// The requests made under the lock are those of its action, unless the
+	// caller already named theirs.
+	if RequestMetadataOf(ctx).Action == "" {
+		ctx = WithRequestMetadata(ctx, RequestMetadata{Action: action})
+	}
+
 	// lock it
+	holding := make([]string, 0, len(i.info))
+	for _, li := range i.info {
+		holding = append(holding, li.path)
+	}
 	l := newLock(action)
-	lockDescriptor, err := l.lock(ctx, ts, lt, isBlocking)
+	lockDescriptor, err := l.lock(ctx, ts, lt, mode, holding)
 	if err != nil {
 		return nil, nil, err
 	}
+	// The low priority locks are released by their action once preempted,
+	// which is signaled by canceling their context.
+	var preemption *lockPreemptionWatch
+	if LockPriorityOf(ctx) == LowLockPriority {
+		ctx, preemption = ts.watchLockPreemption(ctx, lt, l)
+	}
 	// and update our structure
 	i.info[lt.ResourceName()] = &lockInfo{
 		lockDescriptor: lockDescriptor,
 		actionNode:     l,
+		shared:         mode == lockShared,
+		path:           lt.Path(),
+		preemption:     preemption,
 	}
+	// The decisions made under the lock must not rely on stale reads.
+	ctx = WithConsistency(ctx, Quorum)
 	return ctx, func(finalErr *error) {
 		i.mu.Lock()
 		defer i.mu.Unlock()
@@ -213,6 +319,9 @@ func (ts *Server) internalLock(ctx context.Context, lt iTopoLock, action string,
 			return
 		}
 
+		if preemption != nil {
+			preemption.stop()
+		}
 		err := l.unlock(ctx, lt, lockDescriptor, *finalErr)
 		// if we have an error, we log it, but we still want to delete the lock
 		if *finalErr != nil {
@@ -227,7 +336,7 @@ func (ts *Server) internalLock(ctx context.Context, lt iTopoLock, action string,
 	}, nil
 }
 
-// checkLocked checks that the given resource is locked.
+// checkLocked checks that the given resource is locked, exclusively.
 func checkLocked(ctx context.Context, lt iTopoLock) error {
 	// extract the locksInfo pointer
 	i, ok := ctx.Value(locksKey).(*locksInfo)
@@ -242,6 +351,14 @@ func checkLocked(ctx context.Context, lt iTopoLock) error {
 	if !ok {
 		return vterrors.Errorf(vtrpc.Code_INTERNAL, "%v %v is not locked (no lockInfo in map)", lt.Type(), lt.ResourceName())
 	}
+	if li.shared {
+		return vterrors.Errorf(vtrpc.Code_INTERNAL, "%v %v is only locked shared", lt.Type(), lt.ResourceName())
+	}
+	if li.preemption != nil {
+		if err := li.preemption.err(); err != nil {
+			return err
+		}
+	}
 
 	// Check the lock server implementation still holds the lock.
 	return li.lockDescriptor.Check(ctx)
@@ -19,6 +19,7 @@ package memorytopo
 import (
 	"context"
 	"path"
+	"sync"
 
 	"vitess.io/vitess/go/vt/log"
 	"vitess.io/vitess/go/vt/topo"
@@ -74,6 +75,10 @@ type cLeaderParticipation struct {
 
 	// done is a channel closed when we're done processing the Stop
 	done chan struct{}
+
+	// wg tracks the goroutines of the calls of WaitForLeadership, which
+	// release the lock when Stop is called.
+	wg sync.WaitGroup
 }
 
 // WaitForLeadership is part of the topo.LeaderParticipation interface.
@@ -82,9 +87,9 @@ func (mp *cLeaderParticipation) WaitForLeadership() (context.Context, error) {
 		return nil, ErrConnectionClosed
 	}
 
-	// If Stop was already called, mp.done is closed, so we are interrupted.
+	// If Stop was already called, mp.stop is closed, so we are interrupted.
 	select {
-	case <-mp.done:
+	case <-mp.stop:
 		return nil, topo.NewError(topo.Interrupted, "Leadership")
 	default:
 	}
@@ -99,29 +104,39 @@ func (mp *cLeaderParticipation) WaitForLeadership() (context.Context, error) {
 	ld, err := mp.c.Lock(lockCtx, electionPath, mp.id)
 	if err != nil {
 		lockCancel()
-		close(mp.done)
 		// It can be that we were interrupted.
 		return nil, err
 	}
 
+	mp.wg.Add(1)
 	go func() {
-		<-mp.stop
+		defer mp.wg.Done()
+		select {
+		case <-ld.(*memoryTopoLockDescriptor).lock:
+			// Our lock was broken, e.g. by ForceResign.
+			lockCancel()
+			return
+		case <-mp.stop:
+		}
 		if err := ld.Unlock(context.Background()); err != nil {
 			log.Errorf("failed to unlock LockDescriptor %v: %v", electionPath, err)
 		}
 		lockCancel()
-		close(mp.done)
 	}()
 
-	// We got the lock. Return the lockContext. If Stop() is called,
-	// it will cancel the lockCtx, and cancel the returned context.
+	// We got the lock. Return the lockContext. If Stop() is called, or if
+	// we lose the lock, it will cancel the lockCtx, and cancel the returned
+	// context.
 	return lockCtx, nil
 }
 
 // Stop is part of the topo.LeaderParticipation interface
 func (mp *cLeaderParticipation) Stop() {
 	close(mp.stop)
-	<-mp.done
+	// The lock may have been lost already, in which case there is nothing
+	// left to release.
+	mp.wg.Wait()
+	close(mp.done)
 }
 
 // GetCurrentLeaderID is part of the topo.LeaderParticipation interface
@@ -185,3 +200,9 @@ func (mp *cLeaderParticipation) WaitForNewLeader(ctx context.Context) (<-chan st
 
 	return notifications, nil
 }
+
+// ForceResign is part of the topo.ForceResignLeaderParticipation interface.
+// It breaks the lock of the primary, which is watching it.
+func (mp *cLeaderParticipation) ForceResign(ctx context.Context, id string) error {
+	return mp.c.ForceUnlock(ctx, path.Join(electionsPath, mp.name), id)
+}
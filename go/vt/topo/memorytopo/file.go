@@ -122,6 +122,14 @@ func (c *Conn) Update(ctx context.Context, filePath string, contents []byte, ver
 		}
 		n = c.factory.newFile(file, contents, p)
 		p.children[file] = n
+
+		n.propagateRecursiveWatch(&topo.WatchDataRecursive{
+			Path: filePath,
+			WatchData: topo.WatchData{
+				Contents: n.contents,
+				Version:  NodeVersion(n.version),
+			},
+		})
 		return NodeVersion(n.version), nil
 	}
 
@@ -19,6 +19,7 @@ package memorytopo
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"vitess.io/vitess/go/vt/topo"
 )
@@ -38,6 +39,9 @@ func convertError(err error, nodePath string) error {
 type memoryTopoLockDescriptor struct {
 	c       *Conn
 	dirPath string
+	// lock is the channel of the node while it is locked by this
+	// descriptor, to tell whether ForceUnlock broke the lock.
+	lock chan struct{}
 }
 
 // TryLock is part of the topo.Conn interface. Its implementation is same as Lock
@@ -68,6 +72,29 @@ func (c *Conn) Lock(ctx context.Context, dirPath, contents string) (topo.LockDes
 	return c.lock(ctx, dirPath, contents)
 }
 
+// LockNameWithTTL is part of the topo.NamedLockConn interface. The directory
+// of the lock is created if needed. The locks can only be lost to ForceUnlock
+// in this implementation, so only a ttl of 0 is supported.
+func (c *Conn) LockNameWithTTL(ctx context.Context, dirPath, contents string, ttl time.Duration) (topo.LockDescriptor, error) {
+	c.factory.callstats.Add([]string{"LockNameWithTTL"}, 1)
+
+	if ttl != 0 {
+		return nil, topo.NewError(topo.NoImplementation, fmt.Sprintf("memorytopo locks with a TTL of %v", ttl))
+	}
+
+	c.factory.mu.Lock()
+	err := c.factory.getOperationError(LockName, dirPath)
+	if err == nil && c.factory.getOrCreatePath(c.cell, dirPath) == nil {
+		err = topo.NewError(topo.NoNode, dirPath)
+	}
+	c.factory.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	return c.lock(ctx, dirPath, contents)
+}
+
 // Lock is part of the topo.Conn interface.
 func (c *Conn) lock(ctx context.Context, dirPath, contents string) (topo.LockDescriptor, error) {
 	for {
@@ -104,6 +131,7 @@ func (c *Conn) lock(ctx context.Context, dirPath, contents string) (topo.LockDes
 		// No one has the lock, grab it.
 		n.lock = make(chan struct{})
 		n.lockContents = contents
+		n.lockHolder = c
 		for _, w := range n.watches {
 			if w.lock == nil {
 				continue
@@ -114,22 +142,88 @@ func (c *Conn) lock(ctx context.Context, dirPath, contents string) (topo.LockDes
 		return &memoryTopoLockDescriptor{
 			c:       c,
 			dirPath: dirPath,
+			lock:    n.lock,
 		}, nil
 	}
 }
 
+// GetLock is part of the topo.LockHolderConn interface.
+func (c *Conn) GetLock(ctx context.Context, dirPath string) (*topo.LockInfo, error) {
+	c.factory.callstats.Add([]string{"GetLock"}, 1)
+
+	if err := c.dial(ctx); err != nil {
+		return nil, err
+	}
+
+	c.factory.mu.Lock()
+	defer c.factory.mu.Unlock()
+
+	if err := c.factory.getOperationError(GetLock, dirPath); err != nil {
+		return nil, err
+	}
+	if c.factory.err != nil {
+		return nil, c.factory.err
+	}
+
+	n := c.factory.nodeByPath(c.cell, dirPath)
+	if n == nil || n.lock == nil {
+		return nil, topo.NewError(topo.NoNode, dirPath)
+	}
+	// The lock is stale once the connection that took it is closed.
+	return &topo.LockInfo{Contents: n.lockContents, Stale: n.lockHolder.closed.Load()}, nil
+}
+
+// ForceUnlock is part of the topo.LockHolderConn interface.
+func (c *Conn) ForceUnlock(ctx context.Context, dirPath, contents string) error {
+	c.factory.callstats.Add([]string{"ForceUnlock"}, 1)
+
+	if err := c.dial(ctx); err != nil {
+		return err
+	}
+
+	c.factory.mu.Lock()
+	defer c.factory.mu.Unlock()
+
+	if err := c.factory.getOperationError(ForceUnlock, dirPath); err != nil {
+		return err
+	}
+	if c.factory.err != nil {
+		return c.factory.err
+	}
+
+	n := c.factory.nodeByPath(c.cell, dirPath)
+	if n == nil || n.lock == nil {
+		return topo.NewError(topo.NoNode, dirPath)
+	}
+	if n.lockContents != contents {
+		return topo.NewError(topo.BadVersion, dirPath)
+	}
+	close(n.lock)
+	n.lock = nil
+	n.lockContents = ""
+	n.lockHolder = nil
+	return nil
+}
+
 // Check is part of the topo.LockDescriptor interface.
-// We can never lose a lock in this implementation.
+// The lock can only be lost to ForceUnlock in this implementation.
 func (ld *memoryTopoLockDescriptor) Check(ctx context.Context) error {
+	ld.c.factory.mu.Lock()
+	defer ld.c.factory.mu.Unlock()
+
+	n := ld.c.factory.nodeByPath(ld.c.cell, ld.dirPath)
+	if n == nil || n.lock != ld.lock {
+		return fmt.Errorf("lock on %v was broken", ld.dirPath)
+	}
 	return nil
 }
 
 // Unlock is part of the topo.LockDescriptor interface.
 func (ld *memoryTopoLockDescriptor) Unlock(ctx context.Context) error {
-	return ld.c.unlock(ctx, ld.dirPath)
+	return ld.c.unlock(ctx, ld.dirPath, ld.lock)
 }
 
-func (c *Conn) unlock(ctx context.Context, dirPath string) error {
+func (c *Conn) unlock(ctx context.Context, dirPath string, lock chan struct{}) error {
 	if c.closed.Load() {
 		return ErrConnectionClosed
 	}
@@ -144,8 +238,13 @@ func (c *Conn) unlock(ctx context.Context, dirPath string) error {
 	if n.lock == nil {
 		return fmt.Errorf("node %v is not locked", dirPath)
 	}
+	if n.lock != lock {
+		// ForceUnlock broke our lock, and someone else took it since.
+		return fmt.Errorf("lock on %v was broken", dirPath)
+	}
 	close(n.lock)
 	n.lock = nil
 	n.lockContents = ""
+	n.lockHolder = nil
 	return nil
 }
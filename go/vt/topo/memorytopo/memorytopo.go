@@ -23,6 +23,7 @@ import (
 	"context"
 	"errors"
 	"math/rand/v2"
+	"path"
 	"regexp"
 	"strings"
 	"sync"
@@ -64,6 +65,9 @@ const (
 	Delete
 	Lock
 	TryLock
+	LockName
+	GetLock
+	ForceUnlock
 	Watch
 	WatchRecursive
 	NewLeaderParticipation
@@ -212,19 +216,24 @@ type node struct {
 	// For regular locks, it has the contents that was passed in.
 	// For primary election, it has the id of the election leader.
 	lockContents string
+
+	// lockHolder is the connection the lock was taken with.
+	lockHolder *Conn
 }
 
 func (n *node) isDirectory() bool {
 	return n.children != nil
 }
 
-func (n *node) recurseContents(callback func(n *node)) {
+// recurseContents calls callback for every file under n, with their path
+// relative to the root of the cell. filePath is the path of n.
+func (n *node) recurseContents(filePath string, callback func(filePath string, n *node)) {
 	if n.isDirectory() {
 		for _, child := range n.children {
-			child.recurseContents(callback)
+			child.recurseContents(path.Join(filePath, child.name), callback)
 		}
 	} else {
-		callback(n)
+		callback(filePath, n)
 	}
 }
 
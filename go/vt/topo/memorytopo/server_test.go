@@ -30,5 +30,5 @@ func TestMemoryTopo(t *testing.T) {
 	defer cancel()
 	test.TopoServerTestSuite(t, ctx, func() *topo.Server {
 		return NewServer(ctx, test.LocalCellName)
-	}, []string{"checkTryLock", "checkShardWithLock"})
+	}, []string{"checkTryLock", "checkShardWithLock", "checkElectionTTL"})
 }
@@ -102,9 +102,9 @@ func (c *Conn) WatchRecursive(ctx context.Context, dirpath string) ([]*topo.Watc
 	}
 
 	var initialwd []*topo.WatchDataRecursive
-	n.recurseContents(func(n *node) {
+	n.recurseContents(dirpath, func(filePath string, n *node) {
 		initialwd = append(initialwd, &topo.WatchDataRecursive{
-			Path: n.name,
+			Path: filePath,
 			WatchData: topo.WatchData{
 				Contents: n.contents,
 				Version:  NodeVersion(n.version),
@@ -0,0 +1,279 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topo
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/log"
+)
+
+var _ Conn = (*MirrorConn)(nil)
+
+var (
+	topoMirrorOperations = stats.NewCountersWithMultiLabels(
+		"TopologyMirrorOperations",
+		"TopologyMirrorOperations mutations applied to the shadow topology server",
+		[]string{"Operation", "Cell"})
+
+	topoMirrorDivergences = stats.NewCountersWithMultiLabels(
+		"TopologyMirrorDivergences",
+		"TopologyMirrorDivergences mutations that succeeded on the primary topology server but could not be applied as-is to the shadow",
+		[]string{"Operation", "Cell"})
+
+	topoMirrorDropped = stats.NewCountersWithSingleLabel(
+		"TopologyMirrorDropped",
+		"TopologyMirrorDropped mutations not applied to the shadow topology server because the mirror queue was full, or the connection closed",
+		"Cell")
+)
+
+// MirrorMode controls when a MirrorConn applies mutations to its shadow.
+type MirrorMode string
+
+const (
+	// MirrorSync applies every mutation to the shadow before returning to
+	// the caller.
+	MirrorSync MirrorMode = "sync"
+	// MirrorAsync queues mutations and applies them to the shadow in the
+	// background, in the order they were made on the primary.
+	MirrorAsync MirrorMode = "async"
+)
+
+// MirrorConn is a Conn that reads from a primary Conn and applies every
+// successful mutation to a shadow Conn too. It is meant to prepare a
+// migration between topo implementations: the shadow receives production
+// traffic while the primary stays the source of truth, until the shadow can
+// be cut over to.
+//
+// Errors from the shadow are never returned to the caller. Instead, they are
+// logged and counted in the TopologyMirrorDivergences stat, so the shadow can
+// be re-synced (e.g. with topo2topo) if it diverged.
+//
+// The primary enforces versions on Update and Delete, and the shadow gets
+// the resulting unconditional write, as versions are not comparable across
+// implementations. Locks, watches and elections only use the primary.
+type MirrorConn struct {
+	cell    string
+	primary Conn
+	shadow  Conn
+
+	queue     chan mirrorMutation
+	wg        sync.WaitGroup
+	closeOnce sync.Once
+
+	// mu protects closed, and the sends to queue.
+	mu sync.Mutex
+	// closed is whether Close was called, after which the mutations are
+	// not queued anymore.
+	closed bool
+}
+
+type mirrorMutation struct {
+	op    string
+	path  string
+	apply func(ctx context.Context) error
+}
+
+// NewMirrorConn returns a MirrorConn. In MirrorAsync mode, up to queueSize
+// mutations are buffered for the shadow, and newer ones are dropped while
+// the queue is full.
+func NewMirrorConn(cell string, primary, shadow Conn, mode MirrorMode, queueSize int) *MirrorConn {
+	mc := &MirrorConn{
+		cell:    cell,
+		primary: primary,
+		shadow:  shadow,
+	}
+	if mode == MirrorAsync {
+		mc.queue = make(chan mirrorMutation, queueSize)
+		mc.wg.Add(1)
+		go mc.drain()
+	}
+	return mc
+}
+
+func (mc *MirrorConn) drain() {
+	defer mc.wg.Done()
+	for m := range mc.queue {
+		ctx, cancel := context.WithTimeout(context.Background(), RemoteOperationTimeout)
+		mc.record(m, m.apply(ctx))
+		cancel()
+	}
+}
+
+// mirror applies a mutation to the shadow, or queues it in async mode.
+func (mc *MirrorConn) mirror(ctx context.Context, op, path string, apply func(ctx context.Context) error) {
+	m := mirrorMutation{op: op, path: path, apply: apply}
+	if mc.queue == nil {
+		mc.record(m, apply(ctx))
+		return
+	}
+
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	if mc.closed {
+		topoMirrorDropped.Add(mc.cell, 1)
+		log.Warningf("topo mirror for cell %v is closed, dropping %v of %v", mc.cell, op, path)
+		return
+	}
+	select {
+	case mc.queue <- m:
+	default:
+		topoMirrorDropped.Add(mc.cell, 1)
+		log.Warningf("topo mirror queue for cell %v is full, dropping %v of %v", mc.cell, op, path)
+	}
+}
+
+func (mc *MirrorConn) record(m mirrorMutation, err error) {
+	statsKey := []string{m.op, mc.cell}
+	topoMirrorOperations.Add(statsKey, 1)
+	if err != nil {
+		topoMirrorDivergences.Add(statsKey, 1)
+		log.Warningf("topo mirror: %v of %v in cell %v failed on the shadow: %v", m.op, m.path, mc.cell, err)
+	}
+}
+
+// ListDir is part of the Conn interface.
+func (mc *MirrorConn) ListDir(ctx context.Context, dirPath string, full bool) ([]DirEntry, error) {
+	return mc.primary.ListDir(ctx, dirPath, full)
+}
+
+// Create is part of the Conn interface.
+func (mc *MirrorConn) Create(ctx context.Context, filePath string, contents []byte) (Version, error) {
+	version, err := mc.primary.Create(ctx, filePath, contents)
+	if err != nil {
+		return nil, err
+	}
+
+	mc.mirror(ctx, "Create", filePath, func(ctx context.Context) error {
+		_, err := mc.shadow.Create(ctx, filePath, contents)
+		if IsErrType(err, NodeExists) {
+			// The shadow has data the primary did not. Overwrite it so the
+			// shadow converges, but still report the divergence.
+			if _, uerr := mc.shadow.Update(ctx, filePath, contents, nil); uerr != nil {
+				return uerr
+			}
+		}
+		return err
+	})
+	return version, nil
+}
+
+// Update is part of the Conn interface.
+func (mc *MirrorConn) Update(ctx context.Context, filePath string, contents []byte, version Version) (Version, error) {
+	newVersion, err := mc.primary.Update(ctx, filePath, contents, version)
+	if err != nil {
+		return nil, err
+	}
+
+	mc.mirror(ctx, "Update", filePath, func(ctx context.Context) error {
+		_, err := mc.shadow.Update(ctx, filePath, contents, nil)
+		return err
+	})
+	return newVersion, nil
+}
+
+// Get is part of the Conn interface.
+func (mc *MirrorConn) Get(ctx context.Context, filePath string) ([]byte, Version, error) {
+	return mc.primary.Get(ctx, filePath)
+}
+
+// GetVersion is part of the Conn interface.
+func (mc *MirrorConn) GetVersion(ctx context.Context, filePath string, version int64) ([]byte, error) {
+	return mc.primary.GetVersion(ctx, filePath, version)
+}
+
+// List is part of the Conn interface.
+func (mc *MirrorConn) List(ctx context.Context, filePathPrefix string) ([]KVInfo, error) {
+	return mc.primary.List(ctx, filePathPrefix)
+}
+
+// Delete is part of the Conn interface.
+func (mc *MirrorConn) Delete(ctx context.Context, filePath string, version Version) error {
+	if err := mc.primary.Delete(ctx, filePath, version); err != nil {
+		return err
+	}
+
+	mc.mirror(ctx, "Delete", filePath, func(ctx context.Context) error {
+		return mc.shadow.Delete(ctx, filePath, nil)
+	})
+	return nil
+}
+
+// Lock is part of the Conn interface.
+func (mc *MirrorConn) Lock(ctx context.Context, dirPath, contents string) (LockDescriptor, error) {
+	return mc.primary.Lock(ctx, dirPath, contents)
+}
+
+// TryLock is part of the Conn interface.
+func (mc *MirrorConn) TryLock(ctx context.Context, dirPath, contents string) (LockDescriptor, error) {
+	return mc.primary.TryLock(ctx, dirPath, contents)
+}
+
+// LockNameWithTTL is part of the NamedLockConn interface.
+func (mc *MirrorConn) LockNameWithTTL(ctx context.Context, dirPath, contents string, ttl time.Duration) (LockDescriptor, error) {
+	return lockNameWithTTL(ctx, mc.primary, dirPath, contents, ttl)
+}
+
+// GetLock is part of the LockHolderConn interface.
+func (mc *MirrorConn) GetLock(ctx context.Context, dirPath string) (*LockInfo, error) {
+	return getLock(ctx, mc.primary, dirPath)
+}
+
+// ForceUnlock is part of the LockHolderConn interface.
+func (mc *MirrorConn) ForceUnlock(ctx context.Context, dirPath, contents string) error {
+	return forceUnlock(ctx, mc.primary, dirPath, contents)
+}
+
+// Watch is part of the Conn interface.
+func (mc *MirrorConn) Watch(ctx context.Context, filePath string) (*WatchData, <-chan *WatchData, error) {
+	return mc.primary.Watch(ctx, filePath)
+}
+
+// WatchRecursive is part of the Conn interface.
+func (mc *MirrorConn) WatchRecursive(ctx context.Context, path string) ([]*WatchDataRecursive, <-chan *WatchDataRecursive, error) {
+	return mc.primary.WatchRecursive(ctx, path)
+}
+
+// NewLeaderParticipation is part of the Conn interface.
+func (mc *MirrorConn) NewLeaderParticipation(name, id string) (LeaderParticipation, error) {
+	return mc.primary.NewLeaderParticipation(name, id)
+}
+
+// NewLeaderParticipationWithTTL is part of the LeaderParticipationTTLConn
+// interface.
+func (mc *MirrorConn) NewLeaderParticipationWithTTL(name, id string, ttl time.Duration) (LeaderParticipation, error) {
+	return newLeaderParticipationWithTTL(mc.primary, name, id, ttl)
+}
+
+// Close is part of the Conn interface. In async mode, it waits for the
+// queued mutations to be applied to the shadow first.
+func (mc *MirrorConn) Close() {
+	mc.closeOnce.Do(func() {
+		if mc.queue != nil {
+			mc.mu.Lock()
+			mc.closed = true
+			close(mc.queue)
+			mc.mu.Unlock()
+			mc.wg.Wait()
+		}
+		mc.primary.Close()
+		mc.shadow.Close()
+	})
+}
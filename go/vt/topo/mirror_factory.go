@@ -0,0 +1,199 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topo
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sync"
+
+	"github.com/spf13/pflag"
+
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/servenv"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+var (
+	topoMirrorPrimaryImplementation string
+	topoMirrorShadowImplementation  string
+	topoMirrorShadowServerAddress   string
+	topoMirrorShadowRoot            string
+	topoMirrorMode                  = string(MirrorSync)
+	topoMirrorQueueSize             = 10000
+)
+
+func init() {
+	for _, cmd := range FlagBinaries {
+		servenv.OnParseFor(cmd, registerMirrorFlags)
+	}
+	RegisterFactory("mirror", &mirrorFlagsFactory{})
+}
+
+func registerMirrorFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&topoMirrorPrimaryImplementation, "topo_mirror_primary_implementation", topoMirrorPrimaryImplementation, "the topology implementation that serves reads and whose mutations are mirrored, when using the mirror topo implementation. It uses the topo_global_server_address and topo_global_root flags.")
+	fs.StringVar(&topoMirrorShadowImplementation, "topo_mirror_shadow_implementation", topoMirrorShadowImplementation, "the topology implementation mutations are mirrored to, when using the mirror topo implementation")
+	fs.StringVar(&topoMirrorShadowServerAddress, "topo_mirror_shadow_global_server_address", topoMirrorShadowServerAddress, "the address of the global topology server mutations are mirrored to")
+	fs.StringVar(&topoMirrorShadowRoot, "topo_mirror_shadow_global_root", topoMirrorShadowRoot, "the path of the global topology data in the global topology server mutations are mirrored to")
+	fs.StringVar(&topoMirrorMode, "topo_mirror_mode", topoMirrorMode, "when to apply mirrored mutations: 'sync' applies them before returning to the caller, 'async' queues them and applies them in the background")
+	fs.IntVar(&topoMirrorQueueSize, "topo_mirror_queue_size", topoMirrorQueueSize, "the number of mutations queued per topology connection in async mirror mode. Mutations are dropped while the queue is full.")
+}
+
+// MirrorFactory is a Factory implementing a topo server on top of two
+// others: a primary one that serves all reads and is the source of truth,
+// and a shadow one that every mutation is mirrored to (see MirrorConn). It is
+// registered as the mirror implementation, set up from the
+// --topo_mirror_* flags.
+//
+// This is used to migrate between topo implementations without downtime:
+//
+//  1. Copy the topo data to the new implementation with topo2topo, and point
+//     the copied CellInfo records at the new implementation's cell servers.
+//  2. Restart the Vitess components with --topo_implementation=mirror, using
+//     the old implementation as the primary and the new one as the shadow.
+//  3. Verify the shadow (e.g. with topo2topo --compare) and watch the
+//     TopologyMirrorDivergences stat.
+//  4. Cut over by restarting the components on the new implementation.
+type MirrorFactory struct {
+	primary          Factory
+	shadow           Factory
+	shadowServerAddr string
+	shadowRoot       string
+	mode             MirrorMode
+	queueSize        int
+}
+
+// NewMirrorFactory returns a MirrorFactory that mirrors the connections created by
+// primary to the ones created by shadow. The shadow global cell is found at
+// shadowServerAddr and shadowRoot, and the shadow cells are found from the
+// CellInfo records in the shadow global cell.
+func NewMirrorFactory(primary, shadow Factory, shadowServerAddr, shadowRoot string, mode MirrorMode, queueSize int) (*MirrorFactory, error) {
+	switch mode {
+	case MirrorSync, MirrorAsync:
+	default:
+		return nil, fmt.Errorf("invalid topo mirror mode %q, expected %q or %q", mode, MirrorSync, MirrorAsync)
+	}
+
+	return &MirrorFactory{
+		primary:          primary,
+		shadow:           shadow,
+		shadowServerAddr: shadowServerAddr,
+		shadowRoot:       shadowRoot,
+		mode:             mode,
+		queueSize:        queueSize,
+	}, nil
+}
+
+// HasGlobalReadOnlyCell is part of the Factory interface.
+func (f *MirrorFactory) HasGlobalReadOnlyCell(serverAddr, root string) bool {
+	return f.primary.HasGlobalReadOnlyCell(serverAddr, root)
+}
+
+// Create is part of the Factory interface.
+func (f *MirrorFactory) Create(cell, serverAddr, root string) (Conn, error) {
+	primary, err := f.primary.Create(cell, serverAddr, root)
+	if err != nil {
+		return nil, err
+	}
+
+	// The read-only global cell is never written to, so there is nothing to
+	// mirror.
+	if cell == GlobalReadOnlyCell {
+		return primary, nil
+	}
+
+	// Failing to reach the shadow must not take the primary down with it.
+	shadow, err := f.createShadow(cell)
+	if err != nil {
+		log.Errorf("Cannot connect to the shadow topology server for cell %v, its mutations will not be mirrored: %v", cell, err)
+		return primary, nil
+	}
+
+	return NewMirrorConn(cell, primary, shadow, f.mode, f.queueSize), nil
+}
+
+func (f *MirrorFactory) createShadow(cell string) (Conn, error) {
+	if cell == GlobalCell {
+		return f.shadow.Create(GlobalCell, f.shadowServerAddr, f.shadowRoot)
+	}
+
+	// The shadow global cell is only needed to look up the shadow CellInfo
+	// record.
+	shadowGlobal, err := f.shadow.Create(GlobalCell, f.shadowServerAddr, f.shadowRoot)
+	if err != nil {
+		return nil, err
+	}
+	defer shadowGlobal.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), RemoteOperationTimeout)
+	defer cancel()
+	data, _, err := shadowGlobal.Get(ctx, path.Join(CellsPath, cell, CellInfoFile))
+	if err != nil {
+		return nil, err
+	}
+	ci := &topodatapb.CellInfo{}
+	if err := ci.UnmarshalVT(data); err != nil {
+		return nil, err
+	}
+
+	return f.shadow.Create(cell, ci.ServerAddress, ci.Root)
+}
+
+// mirrorFlagsFactory is the Factory registered as the mirror implementation.
+// It is set up from the command-line flags the first time it is used.
+type mirrorFlagsFactory struct {
+	once    sync.Once
+	factory *MirrorFactory
+	err     error
+}
+
+func (ff *mirrorFlagsFactory) get() (*MirrorFactory, error) {
+	ff.once.Do(func() {
+		primary, ok := GetFactory(topoMirrorPrimaryImplementation)
+		if !ok {
+			ff.err = NewError(NoImplementation, fmt.Sprintf("topo_mirror_primary_implementation %q", topoMirrorPrimaryImplementation))
+			return
+		}
+		shadow, ok := GetFactory(topoMirrorShadowImplementation)
+		if !ok {
+			ff.err = NewError(NoImplementation, fmt.Sprintf("topo_mirror_shadow_implementation %q", topoMirrorShadowImplementation))
+			return
+		}
+		ff.factory, ff.err = NewMirrorFactory(primary, shadow, topoMirrorShadowServerAddress, topoMirrorShadowRoot, MirrorMode(topoMirrorMode), topoMirrorQueueSize)
+	})
+	return ff.factory, ff.err
+}
+
+// HasGlobalReadOnlyCell is part of the Factory interface.
+func (ff *mirrorFlagsFactory) HasGlobalReadOnlyCell(serverAddr, root string) bool {
+	f, err := ff.get()
+	if err != nil {
+		return false
+	}
+	return f.HasGlobalReadOnlyCell(serverAddr, root)
+}
+
+// Create is part of the Factory interface.
+func (ff *mirrorFlagsFactory) Create(cell, serverAddr, root string) (Conn, error) {
+	f, err := ff.get()
+	if err != nil {
+		return nil, err
+	}
+	return f.Create(cell, serverAddr, root)
+}
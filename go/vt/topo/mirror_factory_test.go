@@ -0,0 +1,167 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topo_test
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+	"vitess.io/vitess/go/vt/topo/test"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+func newMirror(t *testing.T, ctx context.Context, mode topo.MirrorMode, cells ...string) (mirror, shadow *topo.Server, shadowFactory *memorytopo.Factory) {
+	t.Helper()
+
+	_, primaryFactory := memorytopo.NewServerAndFactory(ctx, cells...)
+	shadow, shadowFactory = memorytopo.NewServerAndFactory(ctx, cells...)
+
+	f, err := topo.NewMirrorFactory(primaryFactory, shadowFactory, "", "", mode, 100)
+	require.NoError(t, err)
+	mirror, err = topo.NewWithFactory(f, "", "")
+	require.NoError(t, err)
+	return mirror, shadow, shadowFactory
+}
+
+func updateKeyspace(t *testing.T, ctx context.Context, ts *topo.Server, ki *topo.KeyspaceInfo) {
+	t.Helper()
+
+	lctx, unlock, err := ts.LockKeyspace(ctx, ki.KeyspaceName(), "updateKeyspace")
+	require.NoError(t, err)
+	defer unlock(&err)
+	require.NoError(t, ts.UpdateKeyspace(lctx, ki))
+}
+
+func TestMirrorFactory(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	test.TopoServerTestSuite(t, ctx, func() *topo.Server {
+		mirror, _, _ := newMirror(t, ctx, topo.MirrorSync, test.LocalCellName)
+		return mirror
+	}, []string{"checkTryLock", "checkShardWithLock", "checkElectionTTL"})
+}
+
+func TestMirrorMutations(t *testing.T) {
+	for _, mode := range []topo.MirrorMode{topo.MirrorSync, topo.MirrorAsync} {
+		t.Run(string(mode), func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			mirror, shadow, _ := newMirror(t, ctx, mode, "zone1")
+
+			require.NoError(t, mirror.CreateKeyspace(ctx, "ks1", &topodatapb.Keyspace{}))
+			require.NoError(t, mirror.CreateKeyspace(ctx, "ks2", &topodatapb.Keyspace{}))
+			ki, err := mirror.GetKeyspace(ctx, "ks1")
+			require.NoError(t, err)
+			ki.DurabilityPolicy = "semi_sync"
+			updateKeyspace(t, ctx, mirror, ki)
+			require.NoError(t, mirror.DeleteKeyspace(ctx, "ks2"))
+			require.NoError(t, mirror.UpdateSrvKeyspace(ctx, "zone1", "ks1", &topodatapb.SrvKeyspace{}))
+
+			// Closing waits for the queued mutations in async mode.
+			mirror.Close()
+
+			ki, err = shadow.GetKeyspace(ctx, "ks1")
+			require.NoError(t, err)
+			assert.Equal(t, "semi_sync", ki.DurabilityPolicy)
+			_, err = shadow.GetKeyspace(ctx, "ks2")
+			assert.True(t, topo.IsErrType(err, topo.NoNode), "ks2 should have been deleted from the shadow, got %v", err)
+			_, err = shadow.GetSrvKeyspace(ctx, "zone1", "ks1")
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestMirrorDivergence(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	mirror, shadow, shadowFactory := newMirror(t, ctx, topo.MirrorSync, "zone1")
+	defer mirror.Close()
+
+	divergences := expvar.Get("TopologyMirrorDivergences").(*stats.CountersWithMultiLabels)
+	before := divergences.Counts()
+
+	// Shadow errors are not returned to the caller.
+	shadowFactory.AddOperationError(memorytopo.Update, "keyspaces/ks1/Keyspace", errors.New("shadow is down"))
+	require.NoError(t, mirror.CreateKeyspace(ctx, "ks1", &topodatapb.Keyspace{}))
+	ki, err := mirror.GetKeyspace(ctx, "ks1")
+	require.NoError(t, err)
+	ki.DurabilityPolicy = "semi_sync"
+	updateKeyspace(t, ctx, mirror, ki)
+
+	// Data that only exists on the shadow is overwritten, but counted.
+	require.NoError(t, shadow.CreateKeyspace(ctx, "ks2", &topodatapb.Keyspace{DurabilityPolicy: "none"}))
+	require.NoError(t, mirror.CreateKeyspace(ctx, "ks2", &topodatapb.Keyspace{DurabilityPolicy: "semi_sync"}))
+	ki, err = shadow.GetKeyspace(ctx, "ks2")
+	require.NoError(t, err)
+	assert.Equal(t, "semi_sync", ki.DurabilityPolicy)
+
+	after := divergences.Counts()
+	assert.EqualValues(t, 1, after["Update.global"]-before["Update.global"])
+	assert.EqualValues(t, 1, after["Create.global"]-before["Create.global"])
+
+	// The primary is unaffected.
+	ki, err = mirror.GetKeyspace(ctx, "ks1")
+	require.NoError(t, err)
+	assert.Equal(t, "semi_sync", ki.DurabilityPolicy)
+}
+
+func TestNewMirrorFactory(t *testing.T) {
+	_, err := topo.NewMirrorFactory(&memorytopo.Factory{}, &memorytopo.Factory{}, "", "", "sometimes", 0)
+	assert.Error(t, err)
+}
+
+func TestMirrorConnCloseWhileMirroring(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	_, primaryFactory := memorytopo.NewServerAndFactory(ctx, "zone1")
+	_, shadowFactory := memorytopo.NewServerAndFactory(ctx, "zone1")
+	primary, err := primaryFactory.Create(topo.GlobalCell, "", "")
+	require.NoError(t, err)
+	shadow, err := shadowFactory.Create(topo.GlobalCell, "", "")
+	require.NoError(t, err)
+	mc := topo.NewMirrorConn(topo.GlobalCell, primary, shadow, topo.MirrorAsync, 1)
+
+	// The mutations made while the connection closes are either applied or
+	// dropped.
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; ; j++ {
+				if _, err := mc.Create(ctx, fmt.Sprintf("file-%d-%d", i, j), []byte("contents")); err != nil {
+					return
+				}
+			}
+		}()
+	}
+	mc.Close()
+	wg.Wait()
+}
@@ -0,0 +1,116 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topo
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"time"
+)
+
+// namedLockFile is the file the named locks of the Conns which don't
+// implement NamedLockConn create in their directory, to lock it.
+const namedLockFile = "named_lock"
+
+type namedLock struct {
+	name string
+	ttl  time.Duration
+}
+
+var _ iTopoLock = (*namedLock)(nil)
+
+func (s *namedLock) Type() string {
+	return "named"
+}
+
+// ResourceName is the path of the lock, so that the names don't collide
+// with the names of the keyspaces.
+func (s *namedLock) ResourceName() string {
+	return s.Path()
+}
+
+func (s *namedLock) Path() string {
+	return NamedLockPath(s.name)
+}
+
+// NamedLockPath returns the directory of the global cell the named lock is
+// taken on, e.g. for GetHeldLocks.
+func NamedLockPath(name string) string {
+	return path.Join(NamedLocksPath, name)
+}
+
+// LockName will lock the given name, for the resources that have no
+// directory of their own in the topology, e.g. a workflow, and return:
+// - a context with a locksInfo structure for future reference.
+// - an unlock method
+// - an error if anything failed.
+//
+// The lock of a holder that died is released like the locks of the
+// keyspaces and shards, when its lease or session expires.
+func (ts *Server) LockName(ctx context.Context, name, action string) (context.Context, func(*error), error) {
+	return ts.LockNameWithTTL(ctx, name, action, 0)
+}
+
+// LockNameWithTTL is like LockName, but the lock of a holder that died is
+// released after the given ttl, so that the others can take over promptly.
+// The topology servers may round ttl, or not support it, see
+// NamedLockConn.LockNameWithTTL.
+func (ts *Server) LockNameWithTTL(ctx context.Context, name, action string, ttl time.Duration) (context.Context, func(*error), error) {
+	return ts.internalLock(ctx, &namedLock{name: name, ttl: ttl}, action, lockBlocking)
+}
+
+// lockNameWithTTL takes a lock on dirPath with conn, see
+// NamedLockConn.LockNameWithTTL. If conn doesn't implement NamedLockConn, it
+// creates a file in dirPath so that Lock can lock it, and only supports a ttl
+// of 0. The Conns wrapping another Conn use it to forward the named locks.
+func lockNameWithTTL(ctx context.Context, conn Conn, dirPath, contents string, ttl time.Duration) (LockDescriptor, error) {
+	if nc, ok := conn.(NamedLockConn); ok {
+		return nc.LockNameWithTTL(ctx, dirPath, contents, ttl)
+	}
+	if ttl != 0 {
+		return nil, NewError(NoImplementation, fmt.Sprintf("locks with a TTL of %v", ttl))
+	}
+	if _, err := conn.Create(ctx, path.Join(dirPath, namedLockFile), nil); err != nil && !IsErrType(err, NodeExists) {
+		return nil, err
+	}
+	return conn.Lock(ctx, dirPath, contents)
+}
+
+// CheckNameLocked can be called on a context to make sure we have the lock
+// for a given name.
+func CheckNameLocked(ctx context.Context, name string) error {
+	return checkLocked(ctx, &namedLock{name: name})
+}
+
+// GetLockNames returns the names locked with LockName. The names whose
+// lock was released may be returned too, depending on the topology server.
+func (ts *Server) GetLockNames(ctx context.Context) ([]string, error) {
+	entries, err := ts.globalCell.ListDir(ctx, NamedLocksPath, false /*full*/)
+	switch {
+	case err == nil:
+	case IsErrType(err, NoNode):
+		return nil, nil
+	default:
+		return nil, err
+	}
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, entry.Name)
+	}
+	return names, nil
+}
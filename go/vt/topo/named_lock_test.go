@@ -0,0 +1,201 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topo_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+func TestTopoNamedLock(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "zone1")
+	defer ts.Close()
+
+	currentTopoLockTimeout := topo.LockTimeout
+	topo.LockTimeout = time.Second
+	defer func() {
+		topo.LockTimeout = currentTopoLockTimeout
+	}()
+
+	names, err := ts.GetLockNames(ctx)
+	require.NoError(t, err)
+	require.Empty(t, names)
+
+	name := "ks"
+	require.NoError(t, ts.CreateKeyspace(ctx, name, &topodatapb.Keyspace{}))
+	lockCtx, unlock, err := ts.LockName(ctx, name, "vdiff")
+	require.NoError(t, err)
+	require.NoError(t, topo.CheckNameLocked(lockCtx, name))
+	require.ErrorContains(t, topo.CheckKeyspaceLocked(lockCtx, name), "is not locked")
+
+	// The named lock doesn't collide with the keyspace of the same name.
+	_, unlockKeyspace, err := ts.LockKeyspace(ctx, name, "Reshard")
+	require.NoError(t, err)
+	unlockKeyspace(&err)
+	require.NoError(t, err)
+
+	// But it does with the named lock.
+	_, _, err2 := ts.LockName(ctx, name, "vdiff")
+	require.True(t, topo.IsErrType(err2, topo.Timeout), "expected Timeout, got %v", err2)
+
+	names, err = ts.GetLockNames(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []string{name}, names)
+	locks, err := ts.GetHeldLocks(ctx, topo.NamedLockPath(name))
+	require.NoError(t, err)
+	require.Len(t, locks, 1)
+	require.Equal(t, "vdiff", locks[0].Lock.Action)
+
+	unlock(&err)
+	require.NoError(t, err)
+	require.ErrorContains(t, topo.CheckNameLocked(ctx, name), "is not locked")
+}
+
+// ttlFactory is a Factory whose Conns record the ttl of the named locks, and
+// take them with the default one.
+type ttlFactory struct {
+	topo.Factory
+	mu   sync.Mutex
+	ttls []time.Duration
+}
+
+type ttlConn struct {
+	topo.Conn
+	f *ttlFactory
+}
+
+func (c *ttlConn) LockNameWithTTL(ctx context.Context, dirPath, contents string, ttl time.Duration) (topo.LockDescriptor, error) {
+	c.f.mu.Lock()
+	c.f.ttls = append(c.f.ttls, ttl)
+	c.f.mu.Unlock()
+	return c.Conn.(topo.NamedLockConn).LockNameWithTTL(ctx, dirPath, contents, 0)
+}
+
+func (f *ttlFactory) Create(cell, serverAddr, root string) (topo.Conn, error) {
+	conn, err := f.Factory.Create(cell, serverAddr, root)
+	if err != nil {
+		return nil, err
+	}
+	return &ttlConn{Conn: conn, f: f}, nil
+}
+
+func TestTopoNamedLockTTL(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	_, factory := memorytopo.NewServerAndFactory(ctx, "zone1")
+
+	f := &ttlFactory{Factory: factory}
+	ts, err := topo.NewWithFactory(f, "", "")
+	require.NoError(t, err)
+	defer ts.Close()
+
+	_, unlock, err := ts.LockName(ctx, "wf1", "vdiff")
+	require.NoError(t, err)
+	unlock(&err)
+	require.NoError(t, err)
+
+	_, unlock, err = ts.LockNameWithTTL(ctx, "wf1", "vdiff", time.Minute)
+	require.NoError(t, err)
+	unlock(&err)
+	require.NoError(t, err)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	require.Equal(t, []time.Duration{0, time.Minute}, f.ttls)
+}
+
+func TestTopoNamedLockUnsupportedTTL(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "zone1")
+	defer ts.Close()
+
+	// memorytopo has no leases.
+	_, _, err := ts.LockNameWithTTL(ctx, "wf1", "vdiff", time.Minute)
+	require.True(t, topo.IsErrType(err, topo.NoImplementation), "expected NoImplementation, got %v", err)
+}
+
+// plainFactory is a Factory whose Conns, and their LeaderParticipations,
+// don't implement the optional interfaces.
+type plainFactory struct {
+	topo.Factory
+}
+
+type plainConn struct {
+	topo.Conn
+}
+
+func (f *plainFactory) Create(cell, serverAddr, root string) (topo.Conn, error) {
+	conn, err := f.Factory.Create(cell, serverAddr, root)
+	if err != nil {
+		return nil, err
+	}
+	return &plainConn{Conn: conn}, nil
+}
+
+func (c *plainConn) NewLeaderParticipation(name, id string) (topo.LeaderParticipation, error) {
+	mp, err := c.Conn.NewLeaderParticipation(name, id)
+	if err != nil {
+		return nil, err
+	}
+	return struct{ topo.LeaderParticipation }{mp}, nil
+}
+
+func TestTopoNamedLockFallback(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	_, factory := memorytopo.NewServerAndFactory(ctx, "zone1")
+
+	ts, err := topo.NewWithFactory(&plainFactory{Factory: factory}, "", "")
+	require.NoError(t, err)
+	defer ts.Close()
+
+	currentTopoLockTimeout := topo.LockTimeout
+	topo.LockTimeout = time.Second
+	defer func() {
+		topo.LockTimeout = currentTopoLockTimeout
+	}()
+
+	// The named locks lock a directory they create.
+	lockCtx, unlock, err := ts.LockName(ctx, "wf1", "vdiff")
+	require.NoError(t, err)
+	require.NoError(t, topo.CheckNameLocked(lockCtx, "wf1"))
+	_, _, err2 := ts.LockName(ctx, "wf1", "vdiff")
+	require.True(t, topo.IsErrType(err2, topo.Timeout), "expected Timeout, got %v", err2)
+	unlock(&err)
+	require.NoError(t, err)
+
+	_, unlock, err = ts.LockName(ctx, "wf1", "vdiff")
+	require.NoError(t, err)
+	unlock(&err)
+	require.NoError(t, err)
+
+	// But only with the default TTL.
+	_, _, err = ts.LockNameWithTTL(ctx, "wf1", "vdiff", time.Minute)
+	require.True(t, topo.IsErrType(err, topo.NoImplementation), "expected NoImplementation, got %v", err)
+}
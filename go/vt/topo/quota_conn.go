@@ -0,0 +1,280 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topo
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/stats"
+)
+
+var _ Conn = (*QuotaConn)(nil)
+
+// quotaListingDuration is how long the listing of a directory with a quota is
+// used to check the writes, before the directory is listed again.
+var quotaListingDuration = 10 * time.Second
+
+var topoQuotaRejections = stats.NewCountersWithMultiLabels(
+	"TopologyQuotaRejections",
+	"TopologyQuotaRejections writes and locks rejected because they would have created an entry in a directory holding as many entries as its quota",
+	[]string{"Cell", "Quota"})
+
+// QuotaConn is a Conn that limits the number of entries of the directories,
+// to protect the topology server from runaway object creation. A quota is
+// set by the name of the directories it applies to, e.g. tablets for the
+// tablets of a cell, or named_locks for the named locks, and limits each of
+// these directories separately.
+//
+// Only the writes creating a new entry in a directory, Create and Update
+// without a version, and the locks, whose directory is an entry of its
+// parent, are checked. The entries of a directory are listed on its first
+// check, and then each quotaListingDuration, the writes of the process being
+// added to the listing, and its deletes and unlocks having the directory
+// listed again on its next check. The entries created by the other processes
+// are then only counted with the next listing, and the concurrent writes may
+// overshoot the quota by as many writes.
+type QuotaConn struct {
+	Conn
+	cell   string
+	quotas map[string]int
+
+	// mu protects listings.
+	mu sync.Mutex
+	// listings are the entries of the directories with a quota, by path.
+	listings map[string]*quotaListing
+}
+
+// quotaListing is the listing of the entries of a directory with a quota.
+type quotaListing struct {
+	entries  map[string]bool
+	listedAt time.Time
+}
+
+// NewQuotaConn returns a QuotaConn enforcing quotas, the maximum number of
+// entries of the directories by their name.
+func NewQuotaConn(cell string, conn Conn, quotas map[string]int) *QuotaConn {
+	return &QuotaConn{
+		Conn:     conn,
+		cell:     cell,
+		quotas:   quotas,
+		listings: make(map[string]*quotaListing),
+	}
+}
+
+// quotaDir is a directory with a quota containing a path, and the entry of
+// the path in it.
+type quotaDir struct {
+	name  string
+	path  string
+	entry string
+	quota int
+}
+
+// quotaDirs returns the directories with a quota containing filePath.
+func (qc *QuotaConn) quotaDirs(filePath string) []quotaDir {
+	parts := strings.Split(strings.Trim(filePath, "/"), "/")
+	var dirs []quotaDir
+	// The last part is the file itself, which is not a directory.
+	for i, name := range parts[:len(parts)-1] {
+		if quota, ok := qc.quotas[name]; ok {
+			dirs = append(dirs, quotaDir{
+				name:  name,
+				path:  path.Join(parts[:i+1]...),
+				entry: parts[i+1],
+				quota: quota,
+			})
+		}
+	}
+	return dirs
+}
+
+// entries returns the entries of the directory, from its listing if it is
+// recent enough.
+func (qc *QuotaConn) entries(ctx context.Context, dirPath string) (map[string]bool, error) {
+	qc.mu.Lock()
+	listing, ok := qc.listings[dirPath]
+	if ok && time.Since(listing.listedAt) < quotaListingDuration {
+		entries := maps.Clone(listing.entries)
+		qc.mu.Unlock()
+		return entries, nil
+	}
+	qc.mu.Unlock()
+
+	listedAt := time.Now()
+	dirEntries, err := qc.Conn.ListDir(ctx, dirPath, false)
+	if err != nil && !IsErrType(err, NoNode) {
+		return nil, err
+	}
+	entries := make(map[string]bool, len(dirEntries))
+	for _, e := range dirEntries {
+		entries[e.Name] = true
+	}
+
+	qc.mu.Lock()
+	defer qc.mu.Unlock()
+	qc.listings[dirPath] = &quotaListing{
+		entries:  maps.Clone(entries),
+		listedAt: listedAt,
+	}
+	return entries, nil
+}
+
+// checkQuotas returns a QuotaExceeded error if writing filePath would create
+// an entry in a directory holding as many entries as its quota.
+func (qc *QuotaConn) checkQuotas(ctx context.Context, filePath string) error {
+	for _, dir := range qc.quotaDirs(filePath) {
+		entries, err := qc.entries(ctx, dir.path)
+		if err != nil {
+			return err
+		}
+		if len(entries) >= dir.quota && !entries[dir.entry] {
+			topoQuotaRejections.Add([]string{qc.cell, dir.name}, 1)
+			return NewError(QuotaExceeded, fmt.Sprintf("%v already has %v entries, the quota of the %v directories, cannot create %v", dir.path, len(entries), dir.name, filePath))
+		}
+	}
+	return nil
+}
+
+// written adds the entries of filePath to the listings of the directories
+// with a quota containing it.
+func (qc *QuotaConn) written(filePath string) {
+	qc.mu.Lock()
+	defer qc.mu.Unlock()
+	for _, dir := range qc.quotaDirs(filePath) {
+		if listing, ok := qc.listings[dir.path]; ok {
+			listing.entries[dir.entry] = true
+		}
+	}
+}
+
+// removed drops the listings of the directories with a quota containing
+// filePath, whose entry may be gone, so they are listed again.
+func (qc *QuotaConn) removed(filePath string) {
+	qc.mu.Lock()
+	defer qc.mu.Unlock()
+	for _, dir := range qc.quotaDirs(filePath) {
+		delete(qc.listings, dir.path)
+	}
+}
+
+// Create is part of the Conn interface.
+func (qc *QuotaConn) Create(ctx context.Context, filePath string, contents []byte) (Version, error) {
+	if err := qc.checkQuotas(ctx, filePath); err != nil {
+		return nil, err
+	}
+	version, err := qc.Conn.Create(ctx, filePath, contents)
+	if err == nil {
+		qc.written(filePath)
+	}
+	return version, err
+}
+
+// Update is part of the Conn interface. The updates with a version only
+// replace an existing file, so they are not checked.
+func (qc *QuotaConn) Update(ctx context.Context, filePath string, contents []byte, version Version) (Version, error) {
+	if version == nil {
+		if err := qc.checkQuotas(ctx, filePath); err != nil {
+			return nil, err
+		}
+	}
+	newVersion, err := qc.Conn.Update(ctx, filePath, contents, version)
+	if err == nil {
+		qc.written(filePath)
+	}
+	return newVersion, err
+}
+
+// Delete is part of the Conn interface.
+func (qc *QuotaConn) Delete(ctx context.Context, filePath string, version Version) error {
+	err := qc.Conn.Delete(ctx, filePath, version)
+	if err == nil {
+		qc.removed(filePath)
+	}
+	return err
+}
+
+// Lock is part of the Conn interface. The lock of dirPath is an entry of its
+// parent directory.
+func (qc *QuotaConn) Lock(ctx context.Context, dirPath, contents string) (LockDescriptor, error) {
+	return qc.lock(ctx, dirPath, func() (LockDescriptor, error) {
+		return qc.Conn.Lock(ctx, dirPath, contents)
+	})
+}
+
+// TryLock is part of the Conn interface.
+func (qc *QuotaConn) TryLock(ctx context.Context, dirPath, contents string) (LockDescriptor, error) {
+	return qc.lock(ctx, dirPath, func() (LockDescriptor, error) {
+		return qc.Conn.TryLock(ctx, dirPath, contents)
+	})
+}
+
+// LockNameWithTTL is part of the NamedLockConn interface.
+func (qc *QuotaConn) LockNameWithTTL(ctx context.Context, dirPath, contents string, ttl time.Duration) (LockDescriptor, error) {
+	return qc.lock(ctx, dirPath, func() (LockDescriptor, error) {
+		return lockNameWithTTL(ctx, qc.Conn, dirPath, contents, ttl)
+	})
+}
+
+// GetLock is part of the LockHolderConn interface.
+func (qc *QuotaConn) GetLock(ctx context.Context, dirPath string) (*LockInfo, error) {
+	return getLock(ctx, qc.Conn, dirPath)
+}
+
+// ForceUnlock is part of the LockHolderConn interface.
+func (qc *QuotaConn) ForceUnlock(ctx context.Context, dirPath, contents string) error {
+	return forceUnlock(ctx, qc.Conn, dirPath, contents)
+}
+
+// NewLeaderParticipationWithTTL is part of the LeaderParticipationTTLConn
+// interface.
+func (qc *QuotaConn) NewLeaderParticipationWithTTL(name, id string, ttl time.Duration) (LeaderParticipation, error) {
+	return newLeaderParticipationWithTTL(qc.Conn, name, id, ttl)
+}
+
+// lock checks the quotas of the lock of dirPath, and takes it with lockFn.
+func (qc *QuotaConn) lock(ctx context.Context, dirPath string, lockFn func() (LockDescriptor, error)) (LockDescriptor, error) {
+	if err := qc.checkQuotas(ctx, dirPath); err != nil {
+		return nil, err
+	}
+	ld, err := lockFn()
+	if err != nil {
+		return nil, err
+	}
+	qc.written(dirPath)
+	return &quotaLockDescriptor{LockDescriptor: ld, qc: qc, dirPath: dirPath}, nil
+}
+
+// quotaLockDescriptor is the LockDescriptor of a lock taken through a
+// QuotaConn, whose release may remove its entry.
+type quotaLockDescriptor struct {
+	LockDescriptor
+	qc      *QuotaConn
+	dirPath string
+}
+
+// Unlock is part of the LockDescriptor interface.
+func (ld *quotaLockDescriptor) Unlock(ctx context.Context) error {
+	err := ld.LockDescriptor.Unlock(ctx)
+	ld.qc.removed(ld.dirPath)
+	return err
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topo_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+)
+
+func TestQuotaConn(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	_, factory := memorytopo.NewServerAndFactory(ctx, "zone1")
+
+	conn, err := factory.Create("zone1", "", "")
+	require.NoError(t, err)
+	qc := topo.NewQuotaConn("zone1", conn, map[string]int{"tablets": 2})
+
+	_, err = qc.Create(ctx, "tablets/zone1-0000000100/Tablet", []byte("100"))
+	require.NoError(t, err)
+	_, err = qc.Update(ctx, "tablets/zone1-0000000101/Tablet", []byte("101"), nil)
+	require.NoError(t, err)
+
+	// The directory is full.
+	snapshot := stats.TakeSnapshot()
+	_, err = qc.Create(ctx, "tablets/zone1-0000000102/Tablet", []byte("102"))
+	assert.True(t, topo.IsErrType(err, topo.QuotaExceeded), "%v", err)
+	_, err = qc.Update(ctx, "tablets/zone1-0000000102/Tablet", []byte("102"), nil)
+	assert.True(t, topo.IsErrType(err, topo.QuotaExceeded), "%v", err)
+	assert.Equal(t, map[string]int64{"zone1.tablets": 2}, snapshot.Diff()["TopologyQuotaRejections"])
+
+	// But its entries can still be written.
+	_, err = qc.Update(ctx, "tablets/zone1-0000000100/Tablet", []byte("100"), nil)
+	require.NoError(t, err)
+	_, err = qc.Create(ctx, "tablets/zone1-0000000100/Other", []byte("100"))
+	require.NoError(t, err)
+
+	// And the other directories are not limited.
+	for _, keyspace := range []string{"ks1", "ks2", "ks3"} {
+		_, err = qc.Create(ctx, "keyspaces/"+keyspace+"/Keyspace", []byte(keyspace))
+		require.NoError(t, err)
+	}
+
+	// Once an entry is deleted, another one can be created.
+	require.NoError(t, qc.Delete(ctx, "tablets/zone1-0000000101/Tablet", nil))
+	_, err = qc.Create(ctx, "tablets/zone1-0000000102/Tablet", []byte("102"))
+	require.NoError(t, err)
+
+	// The directory is not listed again for each write.
+	listDirCalls := factory.GetCallStats().Counts()["ListDir"]
+	for range 3 {
+		_, err = qc.Update(ctx, "tablets/zone1-0000000100/Tablet", []byte("100"), nil)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, listDirCalls, factory.GetCallStats().Counts()["ListDir"])
+}
+
+func TestQuotaConnLocks(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	_, factory := memorytopo.NewServerAndFactory(ctx, "zone1")
+
+	conn, err := factory.Create("zone1", "", "")
+	require.NoError(t, err)
+	qc := topo.NewQuotaConn("zone1", conn, map[string]int{"named_locks": 1})
+
+	ld, err := qc.LockNameWithTTL(ctx, "named_locks/lock1", "test", 0)
+	require.NoError(t, err)
+
+	// The directory of the named locks is full.
+	snapshot := stats.TakeSnapshot()
+	_, err = qc.LockNameWithTTL(ctx, "named_locks/lock2", "test", 0)
+	assert.True(t, topo.IsErrType(err, topo.QuotaExceeded), "%v", err)
+	assert.Equal(t, map[string]int64{"zone1.named_locks": 1}, snapshot.Diff()["TopologyQuotaRejections"])
+
+	// But the locked name can be locked again.
+	require.NoError(t, ld.Unlock(ctx))
+	ld, err = qc.LockNameWithTTL(ctx, "named_locks/lock1", "test", 0)
+	require.NoError(t, err)
+	require.NoError(t, ld.Unlock(ctx))
+}
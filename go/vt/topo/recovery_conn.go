@@ -0,0 +1,263 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topo
+
+import (
+	"context"
+	"time"
+
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/tb"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+var _ Conn = (*RecoveryConn)(nil)
+
+var topoPanics = stats.NewCountersWithMultiLabels(
+	"TopologyPanics",
+	"TopologyPanics panics of the topo implementations recovered as errors",
+	[]string{"Cell", "Operation"})
+
+// RecoveryConn is a Conn that recovers the panics of the Conn of a topo
+// implementation, e.g. a third-party driver, and returns them as INTERNAL
+// errors, so that a bug in the driver fails the topo operation instead of
+// the whole process. The LockDescriptors and LeaderParticipations it
+// returns recover the panics of the driver too.
+//
+// The panics of the goroutines the driver starts, e.g. to serve its
+// watches, can't be recovered this way.
+type RecoveryConn struct {
+	cell string
+	conn Conn
+}
+
+// NewRecoveryConn returns a RecoveryConn for the Conn of the cell.
+func NewRecoveryConn(cell string, conn Conn) *RecoveryConn {
+	return &RecoveryConn{
+		cell: cell,
+		conn: conn,
+	}
+}
+
+// createConn creates the Conn of the cell with the factory, recovering the
+// panics of the factory and of the Conn.
+func createConn(factory Factory, cell, serverAddress, root string) (conn Conn, err error) {
+	defer recoverPanic(cell, "Create", &err)
+	conn, err = factory.Create(cell, serverAddress, root)
+	if err != nil {
+		return nil, err
+	}
+	return NewRecoveryConn(cell, conn), nil
+}
+
+// recoverPanic recovers a panic of the operation, if any, logs it with its
+// stack and sets *err to an error describing it. It must be deferred.
+func recoverPanic(cell, operation string, err *error) {
+	x := recover()
+	if x == nil {
+		return
+	}
+	log.Errorf("Uncaught panic in topo %v of cell %v:\n%v\n%s", operation, cell, x, tb.Stack(4))
+	topoPanics.Add([]string{cell, operation}, 1)
+	if err != nil {
+		*err = vterrors.Errorf(vtrpcpb.Code_INTERNAL, "panic in topo %v of cell %v: %v", operation, cell, x)
+	}
+}
+
+// ClientIdentity is part of the ClientIdentifier interface.
+func (rc *RecoveryConn) ClientIdentity() (identity string) {
+	defer recoverPanic(rc.cell, "ClientIdentity", nil)
+	return clientIdentity(rc.conn)
+}
+
+// OnClientIdentityChange is part of the ClientIdentityNotifier interface.
+func (rc *RecoveryConn) OnClientIdentityChange(f func()) {
+	defer recoverPanic(rc.cell, "OnClientIdentityChange", nil)
+	onClientIdentityChange(rc.conn, f)
+}
+
+// ListDir is part of the Conn interface.
+func (rc *RecoveryConn) ListDir(ctx context.Context, dirPath string, full bool) (entries []DirEntry, err error) {
+	defer recoverPanic(rc.cell, "ListDir", &err)
+	return rc.conn.ListDir(ctx, dirPath, full)
+}
+
+// Create is part of the Conn interface.
+func (rc *RecoveryConn) Create(ctx context.Context, filePath string, contents []byte) (version Version, err error) {
+	defer recoverPanic(rc.cell, "Create", &err)
+	return rc.conn.Create(ctx, filePath, contents)
+}
+
+// Update is part of the Conn interface.
+func (rc *RecoveryConn) Update(ctx context.Context, filePath string, contents []byte, version Version) (newVersion Version, err error) {
+	defer recoverPanic(rc.cell, "Update", &err)
+	return rc.conn.Update(ctx, filePath, contents, version)
+}
+
+// Get is part of the Conn interface.
+func (rc *RecoveryConn) Get(ctx context.Context, filePath string) (contents []byte, version Version, err error) {
+	defer recoverPanic(rc.cell, "Get", &err)
+	return rc.conn.Get(ctx, filePath)
+}
+
+// GetVersion is part of the Conn interface.
+func (rc *RecoveryConn) GetVersion(ctx context.Context, filePath string, version int64) (contents []byte, err error) {
+	defer recoverPanic(rc.cell, "GetVersion", &err)
+	return rc.conn.GetVersion(ctx, filePath, version)
+}
+
+// List is part of the Conn interface.
+func (rc *RecoveryConn) List(ctx context.Context, filePathPrefix string) (kvs []KVInfo, err error) {
+	defer recoverPanic(rc.cell, "List", &err)
+	return rc.conn.List(ctx, filePathPrefix)
+}
+
+// Delete is part of the Conn interface.
+func (rc *RecoveryConn) Delete(ctx context.Context, filePath string, version Version) (err error) {
+	defer recoverPanic(rc.cell, "Delete", &err)
+	return rc.conn.Delete(ctx, filePath, version)
+}
+
+// Lock is part of the Conn interface.
+func (rc *RecoveryConn) Lock(ctx context.Context, dirPath, contents string) (ld LockDescriptor, err error) {
+	defer recoverPanic(rc.cell, "Lock", &err)
+	return rc.lockDescriptor(rc.conn.Lock(ctx, dirPath, contents))
+}
+
+// TryLock is part of the Conn interface.
+func (rc *RecoveryConn) TryLock(ctx context.Context, dirPath, contents string) (ld LockDescriptor, err error) {
+	defer recoverPanic(rc.cell, "TryLock", &err)
+	return rc.lockDescriptor(rc.conn.TryLock(ctx, dirPath, contents))
+}
+
+// LockNameWithTTL is part of the NamedLockConn interface.
+func (rc *RecoveryConn) LockNameWithTTL(ctx context.Context, dirPath, contents string, ttl time.Duration) (ld LockDescriptor, err error) {
+	defer recoverPanic(rc.cell, "LockNameWithTTL", &err)
+	return rc.lockDescriptor(lockNameWithTTL(ctx, rc.conn, dirPath, contents, ttl))
+}
+
+// lockDescriptor wraps the LockDescriptor returned by the Conn, if any.
+func (rc *RecoveryConn) lockDescriptor(ld LockDescriptor, err error) (LockDescriptor, error) {
+	if err != nil {
+		return nil, err
+	}
+	return &recoveryLockDescriptor{cell: rc.cell, ld: ld}, nil
+}
+
+// GetLock is part of the LockHolderConn interface.
+func (rc *RecoveryConn) GetLock(ctx context.Context, dirPath string) (info *LockInfo, err error) {
+	defer recoverPanic(rc.cell, "GetLock", &err)
+	return getLock(ctx, rc.conn, dirPath)
+}
+
+// ForceUnlock is part of the LockHolderConn interface.
+func (rc *RecoveryConn) ForceUnlock(ctx context.Context, dirPath, contents string) (err error) {
+	defer recoverPanic(rc.cell, "ForceUnlock", &err)
+	return forceUnlock(ctx, rc.conn, dirPath, contents)
+}
+
+// Watch is part of the Conn interface.
+func (rc *RecoveryConn) Watch(ctx context.Context, filePath string) (current *WatchData, changes <-chan *WatchData, err error) {
+	defer recoverPanic(rc.cell, "Watch", &err)
+	return rc.conn.Watch(ctx, filePath)
+}
+
+// WatchRecursive is part of the Conn interface.
+func (rc *RecoveryConn) WatchRecursive(ctx context.Context, path string) (current []*WatchDataRecursive, changes <-chan *WatchDataRecursive, err error) {
+	defer recoverPanic(rc.cell, "WatchRecursive", &err)
+	return rc.conn.WatchRecursive(ctx, path)
+}
+
+// NewLeaderParticipation is part of the Conn interface.
+func (rc *RecoveryConn) NewLeaderParticipation(name, id string) (LeaderParticipation, error) {
+	return rc.NewLeaderParticipationWithTTL(name, id, 0)
+}
+
+// NewLeaderParticipationWithTTL is part of the LeaderParticipationTTLConn
+// interface.
+func (rc *RecoveryConn) NewLeaderParticipationWithTTL(name, id string, ttl time.Duration) (lp LeaderParticipation, err error) {
+	defer recoverPanic(rc.cell, "NewLeaderParticipation", &err)
+	lp, err = newLeaderParticipationWithTTL(rc.conn, name, id, ttl)
+	if err != nil {
+		return nil, err
+	}
+	return &recoveryLeaderParticipation{cell: rc.cell, lp: lp}, nil
+}
+
+// Close is part of the Conn interface.
+func (rc *RecoveryConn) Close() {
+	defer recoverPanic(rc.cell, "Close", nil)
+	rc.conn.Close()
+}
+
+// recoveryLockDescriptor is the LockDescriptor of a RecoveryConn.
+type recoveryLockDescriptor struct {
+	cell string
+	ld   LockDescriptor
+}
+
+// Check is part of the LockDescriptor interface.
+func (rld *recoveryLockDescriptor) Check(ctx context.Context) (err error) {
+	defer recoverPanic(rld.cell, "Check", &err)
+	return rld.ld.Check(ctx)
+}
+
+// Unlock is part of the LockDescriptor interface.
+func (rld *recoveryLockDescriptor) Unlock(ctx context.Context) (err error) {
+	defer recoverPanic(rld.cell, "Unlock", &err)
+	return rld.ld.Unlock(ctx)
+}
+
+// recoveryLeaderParticipation is the LeaderParticipation of a
+// RecoveryConn.
+type recoveryLeaderParticipation struct {
+	cell string
+	lp   LeaderParticipation
+}
+
+// WaitForLeadership is part of the LeaderParticipation interface.
+func (rlp *recoveryLeaderParticipation) WaitForLeadership() (ctx context.Context, err error) {
+	defer recoverPanic(rlp.cell, "WaitForLeadership", &err)
+	return rlp.lp.WaitForLeadership()
+}
+
+// Stop is part of the LeaderParticipation interface.
+func (rlp *recoveryLeaderParticipation) Stop() {
+	defer recoverPanic(rlp.cell, "Stop", nil)
+	rlp.lp.Stop()
+}
+
+// GetCurrentLeaderID is part of the LeaderParticipation interface.
+func (rlp *recoveryLeaderParticipation) GetCurrentLeaderID(ctx context.Context) (id string, err error) {
+	defer recoverPanic(rlp.cell, "GetCurrentLeaderID", &err)
+	return rlp.lp.GetCurrentLeaderID(ctx)
+}
+
+// WaitForNewLeader is part of the LeaderParticipation interface.
+func (rlp *recoveryLeaderParticipation) WaitForNewLeader(ctx context.Context) (leaders <-chan string, err error) {
+	defer recoverPanic(rlp.cell, "WaitForNewLeader", &err)
+	return rlp.lp.WaitForNewLeader(ctx)
+}
+
+// ForceResign is part of the ForceResignLeaderParticipation interface.
+func (rlp *recoveryLeaderParticipation) ForceResign(ctx context.Context, id string) (err error) {
+	defer recoverPanic(rlp.cell, "ForceResign", &err)
+	return forceResign(ctx, rlp.lp, id)
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topo_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// panickingConn is a Conn whose Get of the keyspaces and lock Unlock panic.
+type panickingConn struct {
+	topo.Conn
+}
+
+func (c *panickingConn) Get(ctx context.Context, filePath string) ([]byte, topo.Version, error) {
+	if strings.HasPrefix(filePath, "keyspaces/") {
+		panic("driver bug")
+	}
+	return c.Conn.Get(ctx, filePath)
+}
+
+func (c *panickingConn) Lock(ctx context.Context, dirPath, contents string) (topo.LockDescriptor, error) {
+	ld, err := c.Conn.Lock(ctx, dirPath, contents)
+	if err != nil {
+		return nil, err
+	}
+	return &panickingLockDescriptor{LockDescriptor: ld}, nil
+}
+
+type panickingLockDescriptor struct {
+	topo.LockDescriptor
+}
+
+func (ld *panickingLockDescriptor) Unlock(ctx context.Context) error {
+	panic("driver bug")
+}
+
+// panickingFactory is a Factory whose Conns panic, and which panics
+// creating the Conns of zone2.
+type panickingFactory struct {
+	topo.Factory
+}
+
+func (f *panickingFactory) Create(cell, serverAddr, root string) (topo.Conn, error) {
+	if cell == "zone2" {
+		panic("driver bug")
+	}
+	conn, err := f.Factory.Create(cell, serverAddr, root)
+	if err != nil {
+		return nil, err
+	}
+	return &panickingConn{Conn: conn}, nil
+}
+
+func TestRecoveryConn(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	_, factory := memorytopo.NewServerAndFactory(ctx, "zone1", "zone2")
+
+	ts, err := topo.NewWithFactory(&panickingFactory{Factory: factory}, "", "")
+	require.NoError(t, err)
+	defer ts.Close()
+	conn, err := ts.ConnForCell(ctx, "zone1")
+	require.NoError(t, err)
+
+	_, err = conn.Create(ctx, "keyspaces/ks/Keyspace", []byte("ks"))
+	require.NoError(t, err)
+
+	snapshot := stats.TakeSnapshot()
+	_, _, err = conn.Get(ctx, "keyspaces/ks/Keyspace")
+	assert.Equal(t, vtrpcpb.Code_INTERNAL, vterrors.Code(err), "%v", err)
+	assert.ErrorContains(t, err, "panic in topo Get of cell zone1: driver bug")
+
+	ld, err := conn.Lock(ctx, "keyspaces/ks", "lock")
+	require.NoError(t, err)
+	err = ld.Unlock(ctx)
+	assert.Equal(t, vtrpcpb.Code_INTERNAL, vterrors.Code(err), "%v", err)
+
+	// The panics creating the Conns are recovered too.
+	_, err = ts.ConnForCell(ctx, "zone2")
+	assert.ErrorContains(t, err, "panic in topo Create of cell zone2: driver bug")
+
+	assert.Equal(t, map[string]int64{"zone1.Get": 1, "zone1.Unlock": 1, "zone2.Create": 1}, snapshot.Diff()["TopologyPanics"])
+}
@@ -0,0 +1,159 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topo
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/spf13/viper"
+
+	"vitess.io/vitess/go/viperutil"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/servenv"
+)
+
+// RemoteConfigProvider is the name of the viper remote provider serving the
+// config documents stored in the global cell of a Server. The path of the
+// remote provider is the path of the document in the global cell, and its
+// endpoint is ignored.
+const RemoteConfigProvider = "topo"
+
+// remoteConfigRetryDelay is how long to wait before watching a remote config
+// document again after its watch failed.
+var remoteConfigRetryDelay = 5 * time.Second
+
+// RemoteConfig is the viper remote config backend serving the config
+// documents stored in the global cell of a Server, with RemoteConfigProvider
+// as their provider. It implements viperutil.RemoteConfigFactory.
+type RemoteConfig struct {
+	ts *Server
+}
+
+var _ viperutil.RemoteConfigFactory = (*RemoteConfig)(nil)
+
+// NewRemoteConfig returns the remote config backend reading the global cell
+// of ts.
+func NewRemoteConfig(ts *Server) *RemoteConfig {
+	return &RemoteConfig{ts: ts}
+}
+
+// Get is part of the viperutil.RemoteConfigFactory interface.
+func (rc *RemoteConfig) Get(rp viper.RemoteProvider) (io.Reader, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), RemoteOperationTimeout)
+	defer cancel()
+
+	data, _, err := rc.ts.globalCell.Get(ctx, rp.Path())
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(data), nil
+}
+
+// Watch is part of the viperutil.RemoteConfigFactory interface.
+func (rc *RemoteConfig) Watch(rp viper.RemoteProvider) (io.Reader, error) {
+	return rc.Get(rp)
+}
+
+// WatchChannel is part of the viperutil.RemoteConfigFactory interface. The
+// current version of the document is sent first. Failed watches are retried
+// until quit is closed, and their errors sent along the way.
+func (rc *RemoteConfig) WatchChannel(rp viper.RemoteProvider) (<-chan *viper.RemoteResponse, chan bool) {
+	respc := make(chan *viper.RemoteResponse)
+	quit := make(chan bool)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-quit
+		cancel()
+	}()
+
+	go func() {
+		// last is the version of the document last sent, as watches may
+		// send the same version twice.
+		var last []byte
+		send := func(resp *viper.RemoteResponse) bool {
+			if resp.Error == nil {
+				if last != nil && bytes.Equal(resp.Value, last) {
+					return true
+				}
+				last = resp.Value
+			}
+			select {
+			case respc <- resp:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		for {
+			current, changes, err := rc.ts.globalCell.Watch(ctx, rp.Path())
+			if err == nil && send(&viper.RemoteResponse{Value: current.Contents}) {
+				for wd := range changes {
+					if wd.Err != nil {
+						err = wd.Err
+						break
+					}
+					if !send(&viper.RemoteResponse{Value: wd.Contents}) {
+						break
+					}
+				}
+			}
+			if changes != nil {
+				// The watch ended, or ctx is done and cancels it: either way,
+				// changes has to be drained.
+				for range changes {
+				}
+			}
+			if ctx.Err() != nil {
+				return
+			}
+			if err != nil && !send(&viper.RemoteResponse{Error: err}) {
+				return
+			}
+
+			select {
+			case <-time.After(remoteConfigRetryDelay):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return respc, quit
+}
+
+// watchRemoteConfig makes the dynamic config values read the config document
+// at path in the global cell of ts, in place of a config file. If the document
+// can't be read yet, because it is missing or the global topo is down, the
+// dynamic values keep their values until the watch, which retries, reads it.
+func watchRemoteConfig(ts *Server, path string) error {
+	viperutil.RegisterRemoteConfigProvider(RemoteConfigProvider, NewRemoteConfig(ts))
+	cancel, err := viperutil.WatchRemoteConfig(RemoteConfigProvider, ts.globalServerAddress, path)
+	if cancel != nil {
+		servenv.OnClose(cancel)
+	}
+	if errors.Is(err, viperutil.ErrRemoteConfigUnavailable) {
+		log.Warningf("Cannot read the config at %v in the global topo yet, retrying in the background: %v", path, err)
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topo_test
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+)
+
+type remoteProvider struct {
+	path string
+}
+
+func (rp *remoteProvider) Provider() string      { return topo.RemoteConfigProvider }
+func (rp *remoteProvider) Endpoint() string      { return "" }
+func (rp *remoteProvider) Path() string          { return rp.path }
+func (rp *remoteProvider) SecretKeyring() string { return "" }
+
+func TestRemoteConfig(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "zone1")
+	defer ts.Close()
+
+	conn, err := ts.ConnForCell(ctx, topo.GlobalCell)
+	require.NoError(t, err)
+	version, err := conn.Create(ctx, "config/vtgate.yaml", []byte("foo: 1\n"))
+	require.NoError(t, err)
+
+	rc := topo.NewRemoteConfig(ts)
+	rp := &remoteProvider{path: "config/vtgate.yaml"}
+
+	r, err := rc.Get(rp)
+	require.NoError(t, err)
+	data, err := io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, "foo: 1\n", string(data))
+
+	_, err = rc.Get(&remoteProvider{path: "config/nope.yaml"})
+	assert.True(t, topo.IsErrType(err, topo.NoNode), "Get(nope): %v", err)
+
+	respc, quit := rc.WatchChannel(rp)
+	defer close(quit)
+
+	next := func() *viper.RemoteResponse {
+		t.Helper()
+		select {
+		case resp := <-respc:
+			return resp
+		case <-time.After(10 * time.Second):
+			require.FailNow(t, "timed out waiting for the remote config")
+			return nil
+		}
+	}
+
+	resp := next()
+	require.NoError(t, resp.Error)
+	assert.Equal(t, "foo: 1\n", string(resp.Value))
+
+	_, err = conn.Update(ctx, "config/vtgate.yaml", []byte("foo: 1\n"), version)
+	require.NoError(t, err)
+	_, err = conn.Update(ctx, "config/vtgate.yaml", []byte("foo: 2\n"), nil)
+	require.NoError(t, err)
+	// The unchanged version is skipped.
+	resp = next()
+	require.NoError(t, resp.Error)
+	assert.Equal(t, "foo: 2\n", string(resp.Value))
+}
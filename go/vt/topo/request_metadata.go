@@ -0,0 +1,64 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topo
+
+import (
+	"context"
+	"path"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"vitess.io/vitess/go/vt/servenv"
+)
+
+// The gRPC servers of the components set the RequestMetadata of the
+// requests they serve, so that the topo requests made for them carry the
+// name of the RPC as action, and a request ID: the one the client passed in
+// the RequestMetadataRequestIDKey metadata, or else a new one.
+func init() {
+	servenv.RegisterGRPCServerInterceptors(requestMetadataStreamInterceptor, requestMetadataUnaryInterceptor)
+}
+
+// withServedRequestMetadata returns ctx with the RequestMetadata of the
+// gRPC request to method it serves.
+func withServedRequestMetadata(ctx context.Context, method string) context.Context {
+	md := RequestMetadata{
+		Component: processIdentity(),
+		Action:    path.Base(method),
+	}
+	if incoming, ok := metadata.FromIncomingContext(ctx); ok {
+		if ids := incoming.Get(RequestMetadataRequestIDKey); len(ids) > 0 {
+			md.RequestID = ids[0]
+		}
+	}
+	if md.RequestID == "" {
+		md.RequestID = uuid.NewString()
+	}
+	return WithRequestMetadata(ctx, md)
+}
+
+func requestMetadataUnaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	return handler(withServedRequestMetadata(ctx, info.FullMethod), req)
+}
+
+func requestMetadataStreamInterceptor(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	wrapped := servenv.WrapServerStream(stream)
+	wrapped.WrappedContext = withServedRequestMetadata(wrapped.WrappedContext, info.FullMethod)
+	return handler(srv, wrapped)
+}
@@ -0,0 +1,49 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestRequestMetadataUnaryInterceptor(t *testing.T) {
+	info := &grpc.UnaryServerInfo{FullMethod: "/vtctlservice.Vtctld/PlannedReparentShard"}
+	var md RequestMetadata
+	handler := func(ctx context.Context, req any) (any, error) {
+		md = RequestMetadataOf(ctx)
+		return nil, nil
+	}
+
+	// The request ID of the client is kept.
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(RequestMetadataRequestIDKey, "42"))
+	_, err := requestMetadataUnaryInterceptor(ctx, nil, info, handler)
+	require.NoError(t, err)
+	assert.Equal(t, RequestMetadata{Component: processIdentity(), Action: "PlannedReparentShard", RequestID: "42"}, md)
+
+	// Or else a new one is generated.
+	_, err = requestMetadataUnaryInterceptor(context.Background(), nil, info, handler)
+	require.NoError(t, err)
+	assert.Equal(t, "PlannedReparentShard", md.Action)
+	assert.NotEmpty(t, md.RequestID)
+	assert.NotEqual(t, "42", md.RequestID)
+}
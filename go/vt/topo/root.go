@@ -0,0 +1,163 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topo
+
+import (
+	"context"
+	"path"
+	"slices"
+	"strings"
+
+	"vitess.io/vitess/go/vt/vterrors"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// This file contains the helpers for the roots of the topology servers.
+//
+// The root of the global cell, and the roots of the other cells, are the
+// directories of the topology servers their files are stored under. They
+// namespace the files of the cells, so that several cells, and several
+// Vitess clusters, can share one topology server, as long as their roots
+// don't overlap: no root may be another, or one of its ancestors. The empty
+// root is the default of the implementation, and is not namespaced.
+
+// ValidateRoot returns an error if root is not a valid root: it must not
+// have .. elements, which would escape its namespace.
+func ValidateRoot(root string) error {
+	for _, elem := range strings.Split(root, "/") {
+		if elem == ".." {
+			return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid topo root %q: it must not have .. elements", root)
+		}
+	}
+	return nil
+}
+
+// RootsOverlap returns whether the files of two roots of the same topology
+// server may collide, because one of them is the other or one of its
+// ancestors. The empty roots never overlap, as they are not namespaced.
+func RootsOverlap(a, b string) bool {
+	if a == "" || b == "" {
+		return false
+	}
+	a, b = path.Clean(a), path.Clean(b)
+	return a == b || isAncestorRoot(a, b) || isAncestorRoot(b, a)
+}
+
+// isAncestorRoot returns whether the clean root a is an ancestor of b.
+func isAncestorRoot(a, b string) bool {
+	return strings.HasPrefix(b, strings.TrimSuffix(a, "/")+"/")
+}
+
+// RelativeToRoot translates the key of a file in the topology server into
+// its path relative to root, e.g. /vitess/global/keyspaces/ks/Keyspace into
+// /keyspaces/ks/Keyspace for the root /vitess/global. It returns false if
+// the key is not under root.
+func RelativeToRoot(root, key string) (string, bool) {
+	root, key = path.Clean("/"+root), path.Clean("/"+key)
+	if root == key {
+		return "/", true
+	}
+	if !isAncestorRoot(root, key) {
+		return "", false
+	}
+	return strings.TrimPrefix(key, strings.TrimSuffix(root, "/")), true
+}
+
+// RootOf returns the root of a cell.
+func (ts *Server) RootOf(ctx context.Context, cell string) (string, error) {
+	if cell == GlobalCell || cell == GlobalReadOnlyCell {
+		return ts.globalRoot, nil
+	}
+	ci, err := ts.GetCellInfo(ctx, cell, false /* strongRead */)
+	if err != nil {
+		return "", err
+	}
+	return ci.Root, nil
+}
+
+// serverEndpoints returns the endpoints of the address of a topology server,
+// a comma-separated list, normalized so that the same endpoints written
+// differently compare equal: without their scheme and their trailing slashes,
+// and with the case of their host folded.
+func serverEndpoints(serverAddress string) []string {
+	var endpoints []string
+	for _, endpoint := range strings.Split(serverAddress, ",") {
+		endpoint = strings.TrimSpace(endpoint)
+		if _, rest, ok := strings.Cut(endpoint, "://"); ok {
+			endpoint = rest
+		}
+		endpoint = strings.TrimRight(endpoint, "/")
+		if endpoint == "" {
+			continue
+		}
+		if !strings.HasPrefix(endpoint, "/") {
+			// Unlike the paths of the unix sockets, the hosts are
+			// case-insensitive.
+			endpoint = strings.ToLower(endpoint)
+		}
+		endpoints = append(endpoints, endpoint)
+	}
+	return endpoints
+}
+
+// sameServer returns whether two addresses are of the same topology server,
+// because they have an endpoint in common.
+func sameServer(a, b string) bool {
+	if a == b {
+		return true
+	}
+	endpoints := serverEndpoints(a)
+	for _, endpoint := range serverEndpoints(b) {
+		if slices.Contains(endpoints, endpoint) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkCellRoot returns an error if the root of the CellInfo ci of cell is
+// invalid, or overlaps the root of the global cell or of another cell
+// stored in the same topology server. The servers are the same if their
+// addresses have an endpoint in common, see serverEndpoints.
+func (ts *Server) checkCellRoot(ctx context.Context, cell string, ci *topodatapb.CellInfo) error {
+	if err := ValidateRoot(ci.Root); err != nil {
+		return err
+	}
+	if sameServer(ci.ServerAddress, ts.globalServerAddress) && RootsOverlap(ci.Root, ts.globalRoot) {
+		return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "the root %q of cell %v overlaps the global root %q on %v", ci.Root, cell, ts.globalRoot, ci.ServerAddress)
+	}
+
+	cells, err := ts.GetCellInfoNames(ctx)
+	if err != nil {
+		return err
+	}
+	for _, other := range cells {
+		if other == cell {
+			continue
+		}
+		oci, err := ts.GetCellInfo(ctx, other, true /* strongRead */)
+		if err != nil {
+			return err
+		}
+		if sameServer(oci.ServerAddress, ci.ServerAddress) && RootsOverlap(ci.Root, oci.Root) {
+			return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "the root %q of cell %v overlaps the root %q of cell %v on %v", ci.Root, cell, oci.Root, other, ci.ServerAddress)
+		}
+	}
+	return nil
+}
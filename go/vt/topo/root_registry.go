@@ -0,0 +1,259 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topo
+
+import (
+	"context"
+	"encoding/json"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"vitess.io/vitess/go/vt/vterrors"
+
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// This file contains the registry of the roots of the Vitess clusters sharing
+// one topology server, so that a platform team can provision a cluster per
+// team without standing up a topology server for each of them.
+//
+// The registry is stored in the ClusterRootsFile at the root of the registry,
+// as a JSON list of ClusterRoot, which is written with compare-and-swap, so
+// that the roots of the clusters registered concurrently never overlap.
+
+// ClusterRoot is a Vitess cluster registered in a RootRegistry.
+type ClusterRoot struct {
+	// Name is the unique name of the cluster.
+	Name string `json:"name"`
+	// Root is the directory of the topology server the files of the cluster
+	// are stored under: those of its global cell in GlobalRoot, and those
+	// of its cells in CellRoot.
+	Root string `json:"root"`
+	// Owner is a free-form description of the owner of the cluster.
+	Owner string `json:"owner,omitempty"`
+	// Created is when the cluster was registered.
+	Created time.Time `json:"created"`
+	// Archived is when the cluster was archived, or zero if it is active.
+	Archived time.Time `json:"archived"`
+}
+
+// GlobalRoot returns the root of the global cell of the cluster.
+func (cr *ClusterRoot) GlobalRoot() string {
+	return path.Join(cr.Root, GlobalCell)
+}
+
+// CellRoot returns the root of a cell of the cluster.
+func (cr *ClusterRoot) CellRoot(cell string) string {
+	return path.Join(cr.Root, cell)
+}
+
+// IsArchived returns whether the cluster is archived.
+func (cr *ClusterRoot) IsArchived() bool {
+	return !cr.Archived.IsZero()
+}
+
+// RootRegistry manages the roots of the Vitess clusters sharing a topology
+// server.
+type RootRegistry struct {
+	factory       Factory
+	serverAddress string
+	root          string
+	conn          Conn
+}
+
+// OpenRootRegistry returns the RootRegistry stored at root in the topology
+// server at serverAddress, using the provided implementation.
+func OpenRootRegistry(implementation, serverAddress, root string) (*RootRegistry, error) {
+	factory, ok := factories[implementation]
+	if !ok {
+		return nil, NewError(NoImplementation, implementation)
+	}
+	return NewRootRegistryWithFactory(factory, serverAddress, root)
+}
+
+// NewRootRegistryWithFactory returns the RootRegistry stored at root in the
+// topology server at serverAddress, using the given Factory.
+func NewRootRegistryWithFactory(factory Factory, serverAddress, root string) (*RootRegistry, error) {
+	if err := ValidateRoot(root); err != nil {
+		return nil, err
+	}
+	conn, err := createConn(factory, GlobalCell, serverAddress, root)
+	if err != nil {
+		return nil, err
+	}
+	return &RootRegistry{
+		factory:       factory,
+		serverAddress: serverAddress,
+		root:          root,
+		conn:          conn,
+	}, nil
+}
+
+// Close closes the connection of the registry.
+func (r *RootRegistry) Close() {
+	r.conn.Close()
+}
+
+// ListClusterRoots returns the clusters of the registry, including the
+// archived ones, sorted by name.
+func (r *RootRegistry) ListClusterRoots(ctx context.Context) ([]*ClusterRoot, error) {
+	clusters, _, err := r.read(ctx)
+	return clusters, err
+}
+
+// GetClusterRoot returns a cluster of the registry, or a NoNode error if
+// there is none with this name.
+func (r *RootRegistry) GetClusterRoot(ctx context.Context, name string) (*ClusterRoot, error) {
+	clusters, _, err := r.read(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, cr := range clusters {
+		if cr.Name == name {
+			return cr, nil
+		}
+	}
+	return nil, NewError(NoNode, name)
+}
+
+// CreateClusterRoot registers a cluster named name, whose files are stored
+// under root, or under the directory of its name in the root of the
+// registry if root is empty. The root must not overlap the root of another
+// cluster, even an archived one, nor the registry itself. It returns a
+// NodeExists error if a cluster with this name is already registered.
+func (r *RootRegistry) CreateClusterRoot(ctx context.Context, name, root, owner string) (*ClusterRoot, error) {
+	if name == "" || name == "." || name == ".." || strings.Contains(name, "/") {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid cluster name %q", name)
+	}
+	if root == "" {
+		root = path.Join("/", r.root, name)
+	}
+	if err := ValidateRoot(root); err != nil {
+		return nil, err
+	}
+	if registryFile := path.Join("/", r.root, ClusterRootsFile); RootsOverlap(root, registryFile) {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "the root %q of cluster %v overlaps the registry file %v", root, name, registryFile)
+	}
+
+	created := &ClusterRoot{
+		Name:    name,
+		Root:    root,
+		Owner:   owner,
+		Created: time.Now().UTC(),
+	}
+	err := r.update(ctx, func(clusters []*ClusterRoot) ([]*ClusterRoot, error) {
+		for _, cr := range clusters {
+			if cr.Name == name {
+				return nil, NewError(NodeExists, name)
+			}
+			if RootsOverlap(root, cr.Root) {
+				return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "the root %q of cluster %v overlaps the root %q of cluster %v", root, name, cr.Root, cr.Name)
+			}
+		}
+		clusters = append(clusters, created)
+		slices.SortFunc(clusters, func(a, b *ClusterRoot) int { return strings.Compare(a.Name, b.Name) })
+		return clusters, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// ArchiveClusterRoot archives a cluster of the registry: it can no longer be
+// opened, but it keeps its name and root, so that its files are never
+// overwritten by another cluster. Archiving an archived cluster does
+// nothing.
+func (r *RootRegistry) ArchiveClusterRoot(ctx context.Context, name string) error {
+	return r.update(ctx, func(clusters []*ClusterRoot) ([]*ClusterRoot, error) {
+		for _, cr := range clusters {
+			if cr.Name != name {
+				continue
+			}
+			if cr.IsArchived() {
+				return nil, NewError(NoUpdateNeeded, name)
+			}
+			cr.Archived = time.Now().UTC()
+			return clusters, nil
+		}
+		return nil, NewError(NoNode, name)
+	})
+}
+
+// OpenCluster returns a Server for the global cell of an active cluster of
+// the registry.
+func (r *RootRegistry) OpenCluster(ctx context.Context, name string) (*Server, error) {
+	cr, err := r.GetClusterRoot(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if cr.IsArchived() {
+		return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "cluster %v was archived at %v", name, cr.Archived)
+	}
+	return NewWithFactory(r.factory, r.serverAddress, cr.GlobalRoot())
+}
+
+// read returns the clusters of the registry, and the version of its file,
+// which is nil if it does not exist yet.
+func (r *RootRegistry) read(ctx context.Context) ([]*ClusterRoot, Version, error) {
+	data, version, err := r.conn.Get(ctx, ClusterRootsFile)
+	switch {
+	case IsErrType(err, NoNode):
+		return nil, nil, nil
+	case err != nil:
+		return nil, nil, err
+	}
+	var clusters []*ClusterRoot
+	if err := json.Unmarshal(data, &clusters); err != nil {
+		return nil, nil, vterrors.Wrapf(err, "bad cluster roots data: %q", data)
+	}
+	return clusters, version, nil
+}
+
+// update reads the clusters of the registry, calls f to update them, and
+// writes them back if the file was not changed in the meantime, retrying
+// otherwise. If f returns a NoUpdateNeeded error, nothing is written, and
+// nil is returned.
+func (r *RootRegistry) update(ctx context.Context, f func([]*ClusterRoot) ([]*ClusterRoot, error)) error {
+	for {
+		clusters, version, err := r.read(ctx)
+		if err != nil {
+			return err
+		}
+		if clusters, err = f(clusters); err != nil {
+			if IsErrType(err, NoUpdateNeeded) {
+				return nil
+			}
+			return err
+		}
+		data, err := json.MarshalIndent(clusters, "", "  ")
+		if err != nil {
+			return err
+		}
+		if version == nil {
+			_, err = r.conn.Create(ctx, ClusterRootsFile, data)
+		} else {
+			_, err = r.conn.Update(ctx, ClusterRootsFile, data, version)
+		}
+		if !IsErrType(err, BadVersion) && !IsErrType(err, NodeExists) {
+			// This includes the 'err=nil' case.
+			return err
+		}
+	}
+}
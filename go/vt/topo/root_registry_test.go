@@ -0,0 +1,119 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topo_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+)
+
+func TestRootRegistry(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	_, factory := memorytopo.NewServerAndFactory(ctx, "zone1")
+
+	r, err := topo.NewRootRegistryWithFactory(factory, "", "/vitess")
+	require.NoError(t, err)
+	defer r.Close()
+
+	clusters, err := r.ListClusterRoots(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, clusters)
+
+	// The root of a cluster defaults to its name under the registry.
+	teamB, err := r.CreateClusterRoot(ctx, "team-b", "", "team B")
+	require.NoError(t, err)
+	assert.Equal(t, "/vitess/team-b", teamB.Root)
+	assert.Equal(t, "/vitess/team-b/global", teamB.GlobalRoot())
+	assert.Equal(t, "/vitess/team-b/zone1", teamB.CellRoot("zone1"))
+	_, err = r.CreateClusterRoot(ctx, "team-a", "/teams/a", "team A")
+	require.NoError(t, err)
+
+	// The names are unique, and the roots may not overlap.
+	_, err = r.CreateClusterRoot(ctx, "team-a", "/teams/other", "")
+	assert.True(t, topo.IsErrType(err, topo.NodeExists), "%v", err)
+	_, err = r.CreateClusterRoot(ctx, "team-c", "/teams/a/c", "")
+	assert.ErrorContains(t, err, "overlaps the root")
+	_, err = r.CreateClusterRoot(ctx, "team-c", "/", "")
+	assert.ErrorContains(t, err, "overlaps the registry file")
+	_, err = r.CreateClusterRoot(ctx, "team/c", "", "")
+	assert.ErrorContains(t, err, "invalid cluster name")
+
+	clusters, err = r.ListClusterRoots(ctx)
+	require.NoError(t, err)
+	require.Len(t, clusters, 2)
+	assert.Equal(t, "team-a", clusters[0].Name)
+	assert.Equal(t, "team-b", clusters[1].Name)
+
+	ts, err := r.OpenCluster(ctx, "team-a")
+	require.NoError(t, err)
+	ts.Close()
+
+	// The archived clusters keep their name and root, but can't be opened.
+	require.NoError(t, r.ArchiveClusterRoot(ctx, "team-a"))
+	require.NoError(t, r.ArchiveClusterRoot(ctx, "team-a"))
+	teamA, err := r.GetClusterRoot(ctx, "team-a")
+	require.NoError(t, err)
+	assert.True(t, teamA.IsArchived())
+	_, err = r.OpenCluster(ctx, "team-a")
+	assert.ErrorContains(t, err, "was archived")
+	_, err = r.CreateClusterRoot(ctx, "team-c", "/teams/a", "")
+	assert.ErrorContains(t, err, "overlaps the root")
+
+	err = r.ArchiveClusterRoot(ctx, "unknown")
+	assert.True(t, topo.IsErrType(err, topo.NoNode), "%v", err)
+	_, err = r.GetClusterRoot(ctx, "unknown")
+	assert.True(t, topo.IsErrType(err, topo.NoNode), "%v", err)
+}
+
+func TestRootRegistryConcurrentCreates(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	_, factory := memorytopo.NewServerAndFactory(ctx, "zone1")
+
+	r, err := topo.NewRootRegistryWithFactory(factory, "", "/vitess")
+	require.NoError(t, err)
+	defer r.Close()
+
+	// Of the concurrent registrations of the same root, only one succeeds.
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for _, name := range []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := r.CreateClusterRoot(ctx, name, "/shared", "")
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	succeeded := 0
+	for err := range errs {
+		if err == nil {
+			succeeded++
+		}
+	}
+	assert.Equal(t, 1, succeeded)
+}
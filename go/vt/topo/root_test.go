@@ -0,0 +1,136 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topo_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+func TestValidateRoot(t *testing.T) {
+	for _, root := range []string{"", "/", "/vitess/global", "/vitess/global/", "global"} {
+		assert.NoError(t, topo.ValidateRoot(root), root)
+	}
+	for _, root := range []string{"..", "/vitess/../other", "/vitess/.."} {
+		assert.Error(t, topo.ValidateRoot(root), root)
+	}
+}
+
+func TestRootsOverlap(t *testing.T) {
+	tcs := []struct {
+		a, b    string
+		overlap bool
+	}{
+		{"/vitess/global", "/vitess/global", true},
+		{"/vitess/global", "/vitess/global/", true},
+		{"/vitess", "/vitess/global", true},
+		{"/vitess/global", "/vitess", true},
+		{"/", "/vitess/zone1", true},
+		{"/vitess/global", "/vitess/zone1", false},
+		{"/vitess/global", "/vitess/global2", false},
+		{"global", "zone1", false},
+		{"", "", false},
+		{"", "/vitess", false},
+	}
+	for _, tc := range tcs {
+		assert.Equal(t, tc.overlap, topo.RootsOverlap(tc.a, tc.b), "%q %q", tc.a, tc.b)
+	}
+}
+
+func TestRelativeToRoot(t *testing.T) {
+	tcs := []struct {
+		root, key string
+		path      string
+		ok        bool
+	}{
+		{"/vitess/global", "/vitess/global/keyspaces/ks/Keyspace", "/keyspaces/ks/Keyspace", true},
+		{"/vitess/global/", "/vitess/global/keyspaces/*", "/keyspaces/*", true},
+		{"/vitess/global", "/vitess/global", "/", true},
+		{"global", "global/cells", "/cells", true},
+		{"", "/keyspaces", "/keyspaces", true},
+		{"/vitess/global", "/vitess/global2/keyspaces", "", false},
+		{"/vitess/global", "/vitess/zone1/tablets", "", false},
+	}
+	for _, tc := range tcs {
+		p, ok := topo.RelativeToRoot(tc.root, tc.key)
+		assert.Equal(t, tc.ok, ok, "%q %q", tc.root, tc.key)
+		assert.Equal(t, tc.path, p, "%q %q", tc.root, tc.key)
+	}
+}
+
+func TestCellInfoRootOverlap(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx)
+
+	require.NoError(t, ts.CreateCellInfo(ctx, "zone1", &topodatapb.CellInfo{ServerAddress: "etcd:2379", Root: "/cluster1/zone1"}))
+	require.NoError(t, ts.CreateCellInfo(ctx, "zone2", &topodatapb.CellInfo{ServerAddress: "etcd:2379", Root: "/cluster1/zone2"}))
+	// The same root on another server does not overlap.
+	require.NoError(t, ts.CreateCellInfo(ctx, "zone3", &topodatapb.CellInfo{ServerAddress: "other:2379", Root: "/cluster1/zone1"}))
+
+	err := ts.CreateCellInfo(ctx, "zone4", &topodatapb.CellInfo{ServerAddress: "etcd:2379", Root: "/cluster1"})
+	assert.ErrorContains(t, err, "overlaps the root")
+	err = ts.CreateCellInfo(ctx, "zone4", &topodatapb.CellInfo{ServerAddress: "etcd:2379", Root: "/cluster1/../cluster2"})
+	assert.ErrorContains(t, err, "invalid topo root")
+	// The addresses of the same server are compared by their endpoints.
+	err = ts.CreateCellInfo(ctx, "zone4", &topodatapb.CellInfo{ServerAddress: "http://ETCD:2379/", Root: "/cluster1/zone1"})
+	assert.ErrorContains(t, err, "overlaps the root")
+	err = ts.CreateCellInfo(ctx, "zone4", &topodatapb.CellInfo{ServerAddress: "etcd0:2379, etcd:2379", Root: "/cluster1/zone2"})
+	assert.ErrorContains(t, err, "overlaps the root")
+
+	err = ts.UpdateCellInfoFields(ctx, "zone2", func(ci *topodatapb.CellInfo) error {
+		ci.Root = "/cluster1/zone1/sub"
+		return nil
+	})
+	assert.ErrorContains(t, err, "overlaps the root")
+	err = ts.UpdateCellInfoFields(ctx, "zone2", func(ci *topodatapb.CellInfo) error {
+		ci.Root = "/cluster2/zone2"
+		return nil
+	})
+	require.NoError(t, err)
+
+	root, err := ts.RootOf(ctx, "zone2")
+	require.NoError(t, err)
+	assert.Equal(t, "/cluster2/zone2", root)
+
+	// The cells whose root already overlaps another one can still be
+	// updated, as long as their root and server address don't change.
+	conn, err := ts.ConnForCell(ctx, topo.GlobalCell)
+	require.NoError(t, err)
+	contents, err := (&topodatapb.CellInfo{ServerAddress: "etcd:2379", Root: "/cluster1/zone1"}).MarshalVT()
+	require.NoError(t, err)
+	_, err = conn.Create(ctx, "cells/zone5/CellInfo", contents)
+	require.NoError(t, err)
+	err = ts.UpdateCellInfoFields(ctx, "zone5", func(ci *topodatapb.CellInfo) error {
+		ci.Root = "/cluster1/zone1"
+		return nil
+	})
+	require.NoError(t, err)
+	err = ts.UpdateCellInfoFields(ctx, "zone5", func(ci *topodatapb.CellInfo) error {
+		ci.ServerAddress = "http://etcd:2379"
+		return nil
+	})
+	assert.ErrorContains(t, err, "overlaps the root")
+}
@@ -29,8 +29,8 @@ In tests, we do not mock this package. Instead, we just use a memorytopo.
 
 We also support copying data across topo servers (using helpers/copy.go
 and the topo2topo cmd binary), and writing to two topo servers at the same
-time (using helpers/tee.go). This is to facilitate migrations between
-topo servers.
+time (using MirrorConn and the mirror implementation, see MirrorFactory). This
+is to facilitate migrations between topo servers.
 
 There are two test sub-packages associated with this code:
   - test/ contains a test suite that is run against all of our implementations.
@@ -45,11 +45,15 @@ package topo
 import (
 	"context"
 	"fmt"
+	"os"
 	"path"
+	"path/filepath"
 	"sync"
+	"time"
 
 	"github.com/spf13/pflag"
 
+	"vitess.io/vitess/go/viperutil"
 	"vitess.io/vitess/go/vt/log"
 	"vitess.io/vitess/go/vt/proto/topodata"
 	"vitess.io/vitess/go/vt/servenv"
@@ -81,6 +85,8 @@ const (
 	ExternalClustersFile   = "ExternalClusters"
 	ShardRoutingRulesFile  = "ShardRoutingRules"
 	CommonRoutingRulesFile = "Rules"
+	ClusterRootsFile       = "ClusterRoots"
+	KeyspaceRoutingFile    = "Routing"
 )
 
 // Path for all object types.
@@ -90,10 +96,21 @@ const (
 	KeyspacesPath            = "keyspaces"
 	ShardsPath               = "shards"
 	TabletsPath              = "tablets"
+	TabletTagsPath           = "tablet_tags"
+	TabletLeasesPath         = "tablet_leases"
+	VSchemaHistoryPath       = "vschema_history"
+	SharedLocksPath          = "shared_locks"
+	AuditLogPath             = "audit_log"
 	MetadataPath             = "metadata"
+	ElectionsPath            = "elections"
+	ElectionLeadersPath      = "election_leaders"
 	ExternalClusterVitess    = "vitess"
 	RoutingRulesPath         = "routing_rules"
 	KeyspaceRoutingRulesPath = "keyspace"
+	KeyspaceRoutingPath      = "routing"
+	LockWaitsPath            = "lock_waits"
+	NamedLocksPath           = "named_locks"
+	LockPreemptionsPath      = "lock_preemptions"
 )
 
 // Factory is a factory interface to create Conn objects.
@@ -137,6 +154,13 @@ type Server struct {
 	// It is set at construction time.
 	factory Factory
 
+	// implementation, globalServerAddress and globalRoot describe the
+	// global topo service, for the /debug/topo page. implementation is only
+	// set by OpenServer.
+	implementation      string
+	globalServerAddress string
+	globalRoot          string
+
 	// mu protects the following fields.
 	mu sync.Mutex
 	// cellConns contains clients configured to talk to a list of
@@ -145,6 +169,8 @@ type Server struct {
 	// will read the list of addresses for that cell from the
 	// global cluster and create clients as needed.
 	cellConns map[string]cellConn
+	// tabletCache is the cache of GetCachedTablet, nil if it is disabled.
+	tabletCache *tabletCache
 }
 
 type cellConn struct {
@@ -170,6 +196,69 @@ var (
 	// server.
 	topoGlobalRoot string
 
+	// topoGlobalFallbackCacheDir is the directory of the local cache the
+	// global topology reads are served from when the global topology
+	// server is unreachable. Empty disables the cache.
+	topoGlobalFallbackCacheDir string
+
+	// topoValidateWrites is whether the files written to well-known paths
+	// are checked by a ValidatingConn.
+	topoValidateWrites bool
+
+	// topoHealthCheckInterval is how often the connections to the topology
+	// servers are probed, and re-established if the probes fail. Zero
+	// disables the probes.
+	topoHealthCheckInterval time.Duration
+
+	// topoHealthCheckTimeout is how long a health probe may take before it
+	// fails.
+	topoHealthCheckTimeout = 5 * time.Second
+
+	// topoHealthCheckFailures is how many consecutive health probes must
+	// fail before the connection is re-established.
+	topoHealthCheckFailures = 3
+
+	// topoHedgeReadDelay is how long the reads of the topology servers may
+	// take before they are hedged with a second read. Zero disables it.
+	topoHedgeReadDelay time.Duration
+
+	// topoChunkValues is whether the values larger than topoMaxValueSize
+	// are split in chunks by a ChunkingConn.
+	topoChunkValues bool
+
+	// topoMaxValueSize is the size in bytes above which the values are
+	// split in chunks, if topoChunkValues is set.
+	topoMaxValueSize = 1024 * 1024
+
+	// topoMaxValueSizeHardLimit is the size in bytes above which the writes
+	// of values are rejected. Zero disables it.
+	topoMaxValueSizeHardLimit int
+
+	// topoQuotas are the maximum numbers of entries of the directories, by
+	// their name, enforced by a QuotaConn. Empty disables it.
+	topoQuotas map[string]int
+
+	// topoACLPolicyFile is the path of the ACLPolicy the topo operations of
+	// the process are checked against. Empty disables the checks.
+	topoACLPolicyFile string
+
+	// topoACLIdentity is the identity of the process in the ACLPolicy. Empty
+	// defaults to the name of its binary.
+	topoACLIdentity string
+
+	// topoSlowOperationThreshold is the duration above which the topo
+	// operations are counted and logged as slow. Zero disables it.
+	topoSlowOperationThreshold time.Duration
+
+	// topoTabletCache is whether the tablet cache of the servers is
+	// enabled.
+	topoTabletCache bool
+
+	// topoConfigPath is the path of the config document in the global cell
+	// that the dynamic config values read in place of a config file. Empty
+	// disables it.
+	topoConfigPath string
+
 	// factories has the factories for the Conn objects.
 	factories = make(map[string]Factory)
 
@@ -191,6 +280,26 @@ func registerTopoFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&topoImplementation, "topo_implementation", topoImplementation, "the topology implementation to use")
 	fs.StringVar(&topoGlobalServerAddress, "topo_global_server_address", topoGlobalServerAddress, "the address of the global topology server")
 	fs.StringVar(&topoGlobalRoot, "topo_global_root", topoGlobalRoot, "the path of the global topology data in the global topology server")
+	fs.StringVar(&topoGlobalFallbackCacheDir, "topo_global_fallback_cache_dir", topoGlobalFallbackCacheDir, "if set, the global topology records read are cached in this directory, and served from it, read-only, while the global topology server is unreachable")
+	fs.StringVar(&topoConfigPath, "topo_config_path", topoConfigPath, "if set, the path in the global topology server of a config document, whose extension gives its format (e.g. .yaml), that the dynamic config settings read and watch for changes in place of a config file, so they can be changed for the whole cluster at once")
+	fs.DurationVar(&topoHealthCheckInterval, "topo_health_check_interval", topoHealthCheckInterval, "if set, how often the connections to the topology servers are probed with a cheap read, exporting their health in the TopologyConnHealthy stat, and re-established when --topo_health_check_failures consecutive probes fail")
+	fs.DurationVar(&topoHealthCheckTimeout, "topo_health_check_timeout", topoHealthCheckTimeout, "how long a health probe of a connection to a topology server may take before it fails")
+	fs.IntVar(&topoHealthCheckFailures, "topo_health_check_failures", topoHealthCheckFailures, "how many consecutive health probes of a connection to a topology server must fail before it is re-established. The old connection is only closed once the locks, watches and elections using it are released")
+	fs.Int("topo_global_read_concurrency", topoGlobalReadConcurrency.Default(), "if set, the maximum number of concurrent reads of the global topology server by the process, which is usually more sensitive to load than the topology servers of the cells. The reads fanned out --topo_read_concurrency at a time are capped by it too. It can be changed at runtime by reloading the config.")
+	fs.Int("topo_global_write_concurrency", topoGlobalWriteConcurrency.Default(), "if set, the maximum number of concurrent writes to the global topology server by the process. It can be changed at runtime by reloading the config.")
+	fs.Int("topo_cell_read_concurrency", topoCellReadConcurrency.Default(), "if set, the maximum number of concurrent reads of the topology server of each cell by the process. The reads fanned out --topo_read_concurrency at a time are capped by it too. It can be changed at runtime by reloading the config.")
+	fs.Int("topo_cell_write_concurrency", topoCellWriteConcurrency.Default(), "if set, the maximum number of concurrent writes to the topology server of each cell by the process. It can be changed at runtime by reloading the config.")
+	viperutil.BindFlags(fs, topoGlobalReadConcurrency, topoGlobalWriteConcurrency, topoCellReadConcurrency, topoCellWriteConcurrency)
+	fs.DurationVar(&topoHedgeReadDelay, "topo_hedge_read_delay", topoHedgeReadDelay, "if set, the reads of the topology servers that did not return after this delay are issued a second time, returning the result of the first of the two to return, to trim the tail latency of the reads")
+	fs.BoolVar(&topoChunkValues, "topo_chunk_values", topoChunkValues, "if set, the values larger than --topo_max_value_size are split in chunks written in files of their own next to their file, to store values larger than the value size limit of the topology server. The chunked values are reassembled by the reads of all the processes of this version, whether they set it or not, but the older versions read them as garbage: only set it once all the processes using the topology server were upgraded")
+	fs.IntVar(&topoMaxValueSize, "topo_max_value_size", topoMaxValueSize, "the size in bytes above which the values are split in chunks with --topo_chunk_values")
+	fs.IntVar(&topoMaxValueSizeHardLimit, "topo_max_value_size_hard_limit", topoMaxValueSizeHardLimit, "if set, the writes of values larger than this many bytes to the topology servers are rejected with a ValueTooLarge error. The sizes of the values written are exported in the TopologyConnValueSizes stat")
+	fs.StringToIntVar(&topoQuotas, "topo_quotas", topoQuotas, "maximum numbers of entries of the directories of the topology servers, by directory name, e.g. tablets=10000,shared_locks=100. The writes and locks that would create an entry above the quota are rejected with a QuotaExceeded error, and counted in the TopologyQuotaRejections stat")
+	fs.StringVar(&topoACLPolicyFile, "topo_acl_policy_file", topoACLPolicyFile, "if set, the path of a JSON file mapping the identities of the components to the topo operations and paths they are allowed. The other operations of the process are denied with a PERMISSION_DENIED error, and counted in the TopologyACLDenied stat. The policy is enforced by the process itself, as a guardrail against mistakes: it is no protection against a compromised process, which the access control of the topology server must provide")
+	fs.StringVar(&topoACLIdentity, "topo_acl_identity", topoACLIdentity, "the identity the process claims in the --topo_acl_policy_file. It is not authenticated. Defaults to the name of its binary, e.g. vtgate")
+	fs.DurationVar(&topoSlowOperationThreshold, "topo_slow_operation_threshold", topoSlowOperationThreshold, "if set, the topo operations taking longer than this are counted in the TopologyConnSlowOperations stat and logged, with their path and error, at most once per second")
+	fs.BoolVar(&topoTabletCache, "topo_tablet_cache", topoTabletCache, "if set, the tablet records read through the shared tablet cache of the process are kept up to date by a watch of the tablets of their cell, instead of being read from the topology server each time, if it supports it")
+	fs.BoolVar(&topoValidateWrites, "topo_validate_writes", topoValidateWrites, "if set, the records written to well-known topology paths (Tablet, Shard, Keyspace, VSchema...) are rejected when they don't unmarshal into the expected proto or break its basic invariants")
 }
 
 // RegisterFactory registers a Factory for an implementation for a Server.
@@ -203,32 +312,155 @@ func RegisterFactory(name string, factory Factory) {
 	factories[name] = factory
 }
 
+// GetFactory returns the Factory registered for an implementation, if any.
+// This lets topo implementations wrap other implementations.
+func GetFactory(name string) (Factory, bool) {
+	factory, ok := factories[name]
+	return factory, ok
+}
+
 // NewWithFactory creates a new Server based on the given Factory.
 // It also opens the global cell connection.
 func NewWithFactory(factory Factory, serverAddress, root string) (*Server, error) {
-	conn, err := factory.Create(GlobalCell, serverAddress, root)
+	if err := ValidateRoot(root); err != nil {
+		return nil, err
+	}
+	conn, err := createConn(factory, GlobalCell, serverAddress, root)
 	if err != nil {
 		return nil, err
 	}
-	conn = NewStatsConn(GlobalCell, conn)
+	conn = withHealthCheck(GlobalCell, conn, func() (Conn, error) {
+		return createConn(factory, GlobalCell, serverAddress, root)
+	})
+	identified := conn
+	conn = withHedgedReads(GlobalCell, conn)
+	if topoGlobalFallbackCacheDir != "" {
+		if conn, err = NewFallbackConn(GlobalCell, conn, filepath.Join(topoGlobalFallbackCacheDir, GlobalCell)); err != nil {
+			return nil, err
+		}
+	}
+	conn = withChunking(GlobalCell, conn)
+	conn = withQuotas(GlobalCell, conn)
+	if topoValidateWrites {
+		conn = NewValidatingConn(GlobalCell, conn)
+	}
+	if conn, err = withACL(GlobalCell, conn); err != nil {
+		return nil, err
+	}
+	conn = newLimitedStatsConn(GlobalCell, conn, identified)
 
 	var connReadOnly Conn
 	if factory.HasGlobalReadOnlyCell(serverAddress, root) {
-		connReadOnly, err = factory.Create(GlobalReadOnlyCell, serverAddress, root)
+		connReadOnly, err = createConn(factory, GlobalReadOnlyCell, serverAddress, root)
 		if err != nil {
 			return nil, err
 		}
-		connReadOnly = NewStatsConn(GlobalReadOnlyCell, connReadOnly)
+		connReadOnly = withHealthCheck(GlobalReadOnlyCell, connReadOnly, func() (Conn, error) {
+			return createConn(factory, GlobalReadOnlyCell, serverAddress, root)
+		})
+		identifiedReadOnly := connReadOnly
+		connReadOnly = withHedgedReads(GlobalReadOnlyCell, connReadOnly)
+		if topoGlobalFallbackCacheDir != "" {
+			if connReadOnly, err = NewFallbackConn(GlobalReadOnlyCell, connReadOnly, filepath.Join(topoGlobalFallbackCacheDir, GlobalReadOnlyCell)); err != nil {
+				return nil, err
+			}
+		}
+		connReadOnly = withChunking(GlobalReadOnlyCell, connReadOnly)
+		if connReadOnly, err = withACL(GlobalReadOnlyCell, connReadOnly); err != nil {
+			return nil, err
+		}
+		connReadOnly = newLimitedStatsConn(GlobalReadOnlyCell, connReadOnly, identifiedReadOnly)
 	} else {
 		connReadOnly = conn
 	}
 
-	return &Server{
-		globalCell:         conn,
-		globalReadOnlyCell: connReadOnly,
-		factory:            factory,
-		cellConns:          make(map[string]cellConn),
-	}, nil
+	ts := &Server{
+		globalCell:          conn,
+		globalReadOnlyCell:  connReadOnly,
+		factory:             factory,
+		globalServerAddress: serverAddress,
+		globalRoot:          root,
+		cellConns:           make(map[string]cellConn),
+	}
+	if topoTabletCache {
+		ts.EnableTabletCache()
+	}
+	return ts, nil
+}
+
+// withHealthCheck returns conn, wrapped in a HealthCheckConn if the health
+// probes are enabled.
+func withHealthCheck(cell string, conn Conn, create func() (Conn, error)) Conn {
+	if topoHealthCheckInterval <= 0 {
+		return conn
+	}
+	return NewHealthCheckConn(cell, conn, create, topoHealthCheckInterval, topoHealthCheckTimeout, topoHealthCheckFailures)
+}
+
+// withHedgedReads returns conn, wrapped in a HedgingConn if the reads are
+// hedged.
+func withHedgedReads(cell string, conn Conn) Conn {
+	if topoHedgeReadDelay <= 0 {
+		return conn
+	}
+	return NewHedgingConn(cell, conn, topoHedgeReadDelay)
+}
+
+// withChunking returns conn, wrapped in a ChunkingConn. The large values are
+// only split in chunks with --topo_chunk_values, but the chunked values are
+// always reassembled, so that it can be set once all the processes can read
+// them. Without it, the ChunkingConn only reads the manifests of the values:
+// it doesn't look for chunks otherwise.
+func withChunking(cell string, conn Conn) Conn {
+	maxValueSize := 0
+	if topoChunkValues {
+		maxValueSize = topoMaxValueSize
+	}
+	return NewChunkingConn(cell, conn, maxValueSize)
+}
+
+// withQuotas returns conn, wrapped in a QuotaConn if quotas are set.
+func withQuotas(cell string, conn Conn) Conn {
+	if len(topoQuotas) == 0 {
+		return conn
+	}
+	return NewQuotaConn(cell, conn, topoQuotas)
+}
+
+// withACL returns conn, wrapped in an ACLConn if an ACL policy is set.
+func withACL(cell string, conn Conn) (Conn, error) {
+	if topoACLPolicyFile == "" {
+		return conn, nil
+	}
+	policy, err := LoadACLPolicy(topoACLPolicyFile)
+	if err != nil {
+		return nil, err
+	}
+	return NewACLConn(cell, conn, processIdentity(), policy), nil
+}
+
+// processIdentity returns the identity of the process in the ACL policy and
+// the RequestMetadata: --topo_acl_identity, or else the name of its binary.
+func processIdentity() string {
+	if topoACLIdentity != "" {
+		return topoACLIdentity
+	}
+	return filepath.Base(os.Args[0])
+}
+
+// newLimitedStatsConn returns a StatsConn for conn, with the concurrency
+// limits of the global topology server or of the cells, the value size
+// limit, and the client identity identified, which conn wraps, presents.
+func newLimitedStatsConn(cell string, conn, identified Conn) *StatsConn {
+	st := NewStatsConn(cell, conn)
+	st.SetClientIdentifier(identified)
+	if cell == GlobalCell || cell == GlobalReadOnlyCell {
+		st.SetConcurrencyLimits(topoGlobalReadConcurrency.Get, topoGlobalWriteConcurrency.Get)
+	} else {
+		st.SetConcurrencyLimits(topoCellReadConcurrency.Get, topoCellWriteConcurrency.Get)
+	}
+	st.SetMaxValueSize(topoMaxValueSizeHardLimit)
+	return st
 }
 
 // OpenServer returns a Server using the provided implementation,
@@ -238,7 +470,12 @@ func OpenServer(implementation, serverAddress, root string) (*Server, error) {
 	if !ok {
 		return nil, NewError(NoImplementation, implementation)
 	}
-	return NewWithFactory(factory, serverAddress, root)
+	ts, err := NewWithFactory(factory, serverAddress, root)
+	if err != nil {
+		return nil, err
+	}
+	ts.implementation = implementation
+	return ts, nil
 }
 
 // Open returns a Server using the command line parameter flags
@@ -254,6 +491,12 @@ func Open() *Server {
 	if err != nil {
 		log.Exitf("Failed to open topo server (%v,%v,%v): %v", topoImplementation, topoGlobalServerAddress, topoGlobalRoot, err)
 	}
+	registerDebugTopoHandler(ts)
+	if topoConfigPath != "" {
+		if err := watchRemoteConfig(ts, topoConfigPath); err != nil {
+			log.Exitf("Failed to watch the config at %v in the global topo: %v", topoConfigPath, err)
+		}
+	}
 	return ts
 }
 
@@ -274,6 +517,9 @@ func (ts *Server) ConnForCell(ctx context.Context, cell string) (Conn, error) {
 	if err != nil {
 		return nil, err
 	}
+	if err := ValidateRoot(ci.Root); err != nil {
+		return nil, err
+	}
 
 	// Return a cached client if present.
 	ts.mu.Lock()
@@ -296,10 +542,25 @@ func (ts *Server) ConnForCell(ctx context.Context, cell string) (Conn, error) {
 	// Connect to the cell topo server, while holding the lock.
 	// This ensures only one connection is established at any given time.
 	// Create the connection and cache it
-	conn, err := ts.factory.Create(cell, ci.ServerAddress, ci.Root)
+	conn, err := createConn(ts.factory, cell, ci.ServerAddress, ci.Root)
 	switch {
 	case err == nil:
-		conn = NewStatsConn(cell, conn)
+		conn = withHealthCheck(cell, conn, func() (Conn, error) {
+			return createConn(ts.factory, cell, ci.ServerAddress, ci.Root)
+		})
+		identified := conn
+		conn = withHedgedReads(cell, conn)
+		conn = withChunking(cell, conn)
+		conn = withQuotas(cell, conn)
+		if topoValidateWrites {
+			conn = NewValidatingConn(cell, conn)
+		}
+		aclConn, err := withACL(cell, conn)
+		if err != nil {
+			conn.Close()
+			return nil, err
+		}
+		conn = newLimitedStatsConn(cell, aclConn, identified)
 		ts.cellConns[cell] = cellConn{ci, conn}
 		return conn, nil
 	case IsErrType(err, NoNode):
@@ -353,6 +614,10 @@ func (ts *Server) Close() {
 		cc.conn.Close()
 	}
 	ts.cellConns = make(map[string]cellConn)
+	if ts.tabletCache != nil {
+		ts.tabletCache.cancel()
+		ts.tabletCache = nil
+	}
 }
 
 func (ts *Server) clearCellAliasesCache() {
@@ -248,6 +248,43 @@ func (ts *Server) UpdateShardFields(ctx context.Context, keyspace, shard string,
 	}
 }
 
+// UpdateShardLabels sets the labels of a shard, and removes those in
+// removeLabels, all at once. The update only happens if the shard has the
+// expectedLabels, with their values, where an empty value also matches a
+// label that is not set; otherwise a FAILED_PRECONDITION error is returned. The
+// labels are compared and updated with the version of the shard record, so
+// that concurrent updates cannot overwrite each other.
+func (ts *Server) UpdateShardLabels(ctx context.Context, keyspace, shard string, labels map[string]string, removeLabels []string, expectedLabels map[string]string) (*ShardInfo, error) {
+	for key, value := range labels {
+		if err := topoproto.ValidateLabel(key, value); err != nil {
+			return nil, vterrors.New(vtrpc.Code_INVALID_ARGUMENT, err.Error())
+		}
+		if slices.Contains(removeLabels, key) {
+			return nil, vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "label %v cannot be both set and removed", key)
+		}
+	}
+	return ts.UpdateShardFields(ctx, keyspace, shard, func(si *ShardInfo) error {
+		for key, expected := range expectedLabels {
+			if value := si.Labels[key]; value != expected {
+				return vterrors.Errorf(vtrpc.Code_FAILED_PRECONDITION, "label %v of shard %v/%v is %q, expected %q", key, keyspace, shard, value, expected)
+			}
+		}
+		for _, key := range removeLabels {
+			delete(si.Labels, key)
+		}
+		for key, value := range labels {
+			if si.Labels == nil {
+				si.Labels = make(map[string]string, len(labels))
+			}
+			si.Labels[key] = value
+		}
+		if len(si.Labels) == 0 {
+			si.Labels = nil
+		}
+		return nil
+	})
+}
+
 // CreateShard creates a new shard and tries to fill in the right information.
 // This will lock the Keyspace, as we may be looking at other shard servedTypes.
 // Using GetOrCreateShard is probably a better idea for most use cases.
@@ -44,6 +44,9 @@ func (s *shardLock) Path() string {
 // - an unlock method
 // - an error if anything failed.
 //
+// The reads made with the returned context are Quorum reads, so that the
+// decisions made under the lock don't rely on stale data.
+//
 // We are currently only using this method to lock actions that would
 // impact each-other. Most changes of the Shard object are done by
 // UpdateShardFields, which is not locking the shard object. The
@@ -63,7 +66,27 @@ func (ts *Server) LockShard(ctx context.Context, keyspace, shard, action string)
 	return ts.internalLock(ctx, &shardLock{
 		keyspace: keyspace,
 		shard:    shard,
-	}, action, true)
+	}, action, lockBlocking)
+}
+
+// LockShardShared will lock the shard in shared mode, and return:
+// - a context with a locksInfo structure for future reference.
+// - an unlock method
+// - an error if anything failed.
+//
+// Any number of processes can hold a shared lock on a shard at once, but
+// not along with the lock taken by LockShard: a shared lock waits for the
+// current LockShard to be released, and LockShard waits for the current
+// shared locks to be released. It is meant for the read-only operations
+// that need the shard to not be reparented under them, but that don't
+// conflict with each other, like validations.
+//
+// CheckShardLocked fails on a context holding a shared lock.
+func (ts *Server) LockShardShared(ctx context.Context, keyspace, shard, action string) (context.Context, func(*error), error) {
+	return ts.internalLock(ctx, &shardLock{
+		keyspace: keyspace,
+		shard:    shard,
+	}, action, lockShared)
 }
 
 // TryLockShard will lock the shard, and return:
@@ -85,7 +108,7 @@ func (ts *Server) TryLockShard(ctx context.Context, keyspace, shard, action stri
 	return ts.internalLock(ctx, &shardLock{
 		keyspace: keyspace,
 		shard:    shard,
-	}, action, false)
+	}, action, lockNonBlocking)
 }
 
 // CheckShardLocked can be called on a context to make sure we have the lock
@@ -19,6 +19,7 @@ package topo_test
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
@@ -77,8 +78,92 @@ func TestTopoShardLock(t *testing.T) {
 	err = topo.CheckShardLocked(ctx2, ks, shard2)
 	require.NoError(t, err)
 
-	// confirm that the lock can be re-acquired after unlocking
-	_, unlock, err = ts.TryLockShard(origCtx, ks, shard1, "ks80-")
+	// confirm that the lock can be re-acquired after unlocking, and that the
+	// reads made under it are quorum reads, even for a relaxed caller
+	relaxedCtx := topo.WithConsistency(origCtx, topo.Relaxed)
+	ctx, unlock, err = ts.TryLockShard(relaxedCtx, ks, shard1, "ks80-")
 	require.NoError(t, err)
 	defer unlock(&err)
+	require.Equal(t, topo.Quorum, topo.ConsistencyOf(ctx))
+	require.Equal(t, topo.DefaultConsistency, topo.ConsistencyOf(origCtx))
+}
+
+// TestTopoShardLockShared tests shared shard lock operations.
+func TestTopoShardLockShared(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts, factory := memorytopo.NewServerAndFactory(ctx, "zone1")
+	defer ts.Close()
+
+	currentTopoLockTimeout := topo.LockTimeout
+	topo.LockTimeout = time.Second
+	defer func() {
+		topo.LockTimeout = currentTopoLockTimeout
+	}()
+
+	ks := "ks"
+	shard := "80-"
+	_, err := ts.GetOrCreateShard(ctx, ks, shard)
+	require.NoError(t, err)
+
+	// Several shared locks can be held at once.
+	sharedCtx1, unlockShared1, err := ts.LockShardShared(ctx, ks, shard, "validate1")
+	require.NoError(t, err)
+	_, unlockShared2, err := ts.LockShardShared(ctx, ks, shard, "validate2")
+	require.NoError(t, err)
+
+	// A shared lock is not enough for the operations that need the shard
+	// locked.
+	err2 := topo.CheckShardLocked(sharedCtx1, ks, shard)
+	require.ErrorContains(t, err2, "is only locked shared")
+
+	// The exclusive lock waits for the shared locks.
+	_, _, err2 = ts.TryLockShard(ctx, ks, shard, "reparent")
+	require.True(t, topo.IsErrType(err2, topo.NodeExists), "expected NodeExists, got %v", err2)
+	_, _, err2 = ts.LockShard(ctx, ks, shard, "reparent")
+	require.True(t, topo.IsErrType(err2, topo.Timeout), "expected Timeout, got %v", err2)
+
+	unlockShared1(&err)
+	require.NoError(t, err)
+	unlockShared2(&err)
+	require.NoError(t, err)
+
+	// And the shared locks wait for the exclusive lock.
+	_, unlock, err := ts.LockShard(ctx, ks, shard, "reparent")
+	require.NoError(t, err)
+	_, _, err2 = ts.LockShardShared(ctx, ks, shard, "validate")
+	require.True(t, topo.IsErrType(err2, topo.Timeout), "expected Timeout, got %v", err2)
+	unlock(&err)
+	require.NoError(t, err)
+
+	// The exclusive lock waits for a shared lock to be released.
+	_, unlockShared, err := ts.LockShardShared(ctx, ks, shard, "validate")
+	require.NoError(t, err)
+	locked := make(chan error)
+	go func() {
+		_, unlock, err := ts.LockShard(ctx, ks, shard, "reparent")
+		if err == nil {
+			unlock(&err)
+		}
+		locked <- err
+	}()
+	select {
+	case err := <-locked:
+		t.Fatalf("the exclusive lock did not wait for the shared lock: %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+	unlockShared(&err)
+	require.NoError(t, err)
+	require.NoError(t, <-locked)
+
+	// Stale shared locks, left behind by processes that died, are broken.
+	holder, err := topo.NewWithFactory(factory, "" /*serverAddress*/, "" /*root*/)
+	require.NoError(t, err)
+	_, _, err = holder.LockShardShared(ctx, ks, shard, "validate")
+	require.NoError(t, err)
+	holder.Close()
+	_, unlock, err = ts.LockShard(ctx, ks, shard, "reparent")
+	require.NoError(t, err)
+	unlock(&err)
+	require.NoError(t, err)
 }
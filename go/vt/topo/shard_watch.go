@@ -0,0 +1,161 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topo
+
+import (
+	"context"
+	"path"
+	"slices"
+	"sync"
+
+	"google.golang.org/protobuf/proto"
+
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+// This file contains a view of a set of shard records kept up to date by
+// watches, for the processes which react to the changes of their primary,
+// of their serving state or of their tablet controls.
+
+// ShardsWatchCallbacks are called by a ShardsView with the changes of its
+// shards, with the previous and the new record of the shard. Each of them
+// may be nil. They are called one at a time, from the goroutines of the
+// watches, so they should not block. A change of several fields calls the
+// callbacks in the order of the fields below.
+type ShardsWatchCallbacks struct {
+	// OnPrimaryChange is called when the primary of a shard, or the start
+	// of its term, changes.
+	OnPrimaryChange func(old, new *ShardInfo)
+	// OnIsPrimaryServingChange is called when IsPrimaryServing flips.
+	OnIsPrimaryServingChange func(old, new *ShardInfo)
+	// OnTabletControlsChange is called when the tablet controls of a shard
+	// change.
+	OnTabletControlsChange func(old, new *ShardInfo)
+}
+
+// ShardsView is the view of a set of shard records, kept up to date by
+// watches. It is created by Server.WatchShards.
+type ShardsView struct {
+	*watchedView
+
+	callbacks ShardsWatchCallbacks
+
+	// callbacksMu serializes the calls of the callbacks.
+	callbacksMu sync.Mutex
+
+	// mu protects shards.
+	mu sync.Mutex
+	// shards are the shards by keyspace/shard.
+	shards map[string]*ShardInfo
+}
+
+// WatchShards watches the records of the shards, calling the callbacks
+// with their changes. It returns once the view has the current records of
+// the shards. The watches run until ctx is canceled, and are set again if
+// they fail, the view then calling the callbacks with the changes they
+// missed.
+func (ts *Server) WatchShards(ctx context.Context, shards []KeyspaceShard, callbacks *ShardsWatchCallbacks) (*ShardsView, error) {
+	wv, ctx := newWatchedView(ctx)
+	sv := &ShardsView{
+		watchedView: wv,
+		shards:      make(map[string]*ShardInfo, len(shards)),
+	}
+	if callbacks != nil {
+		sv.callbacks = *callbacks
+	}
+
+	for _, ks := range shards {
+		err := startWatch(ctx, wv, &viewWatch[*WatchData]{
+			name: "shard " + path.Join(ks.Keyspace, ks.Shard),
+			watch: func(ctx context.Context) (<-chan *WatchData, error) {
+				current, changes, err := ts.globalCell.Watch(ctx, shardFilePath(ks.Keyspace, ks.Shard))
+				if err != nil {
+					return nil, err
+				}
+				sv.update(ks, current)
+				return changes, nil
+			},
+			apply: func(changes <-chan *WatchData) error {
+				return sv.apply(ks, changes)
+			},
+		})
+		if err != nil {
+			wv.abort()
+			return nil, err
+		}
+	}
+	wv.started()
+	return sv, nil
+}
+
+// apply applies the changes of the watch of a shard until it fails, and
+// returns its error.
+func (sv *ShardsView) apply(ks KeyspaceShard, changes <-chan *WatchData) error {
+	for wd := range changes {
+		if wd.Err != nil {
+			return wd.Err
+		}
+		sv.update(ks, wd)
+	}
+	return NewError(Interrupted, shardFilePath(ks.Keyspace, ks.Shard))
+}
+
+// update replaces the record of a shard, and calls the callbacks with its
+// changes, if the view had a previous record.
+func (sv *ShardsView) update(ks KeyspaceShard, wd *WatchData) {
+	value := &topodatapb.Shard{}
+	if err := value.UnmarshalVT(wd.Contents); err != nil {
+		log.Warningf("Cannot unpack the record of shard %v/%v: %v", ks.Keyspace, ks.Shard, err)
+		return
+	}
+	si := NewShardInfo(ks.Keyspace, ks.Shard, value, wd.Version)
+
+	key := path.Join(ks.Keyspace, ks.Shard)
+	sv.mu.Lock()
+	old, ok := sv.shards[key]
+	sv.shards[key] = si
+	sv.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	sv.callbacksMu.Lock()
+	defer sv.callbacksMu.Unlock()
+	if sv.callbacks.OnPrimaryChange != nil && (!topoproto.TabletAliasEqual(old.PrimaryAlias, si.PrimaryAlias) || !proto.Equal(old.PrimaryTermStartTime, si.PrimaryTermStartTime)) {
+		sv.callbacks.OnPrimaryChange(old, si)
+	}
+	if sv.callbacks.OnIsPrimaryServingChange != nil && old.IsPrimaryServing != si.IsPrimaryServing {
+		sv.callbacks.OnIsPrimaryServingChange(old, si)
+	}
+	if sv.callbacks.OnTabletControlsChange != nil && !slices.EqualFunc(old.TabletControls, si.TabletControls, func(a, b *topodatapb.Shard_TabletControl) bool {
+		return proto.Equal(a, b)
+	}) {
+		sv.callbacks.OnTabletControlsChange(old, si)
+	}
+}
+
+// GetShard returns the record of a shard of the view.
+func (sv *ShardsView) GetShard(keyspace, shard string) (*ShardInfo, bool) {
+	sv.mu.Lock()
+	defer sv.mu.Unlock()
+
+	si, ok := sv.shards[path.Join(keyspace, shard)]
+	return si, ok
+}
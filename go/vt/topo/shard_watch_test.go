@@ -0,0 +1,149 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topo_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/protoutil"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+// shardEvent is a call of the ShardsWatchCallbacks, recorded by a test.
+type shardEvent struct {
+	op    string
+	shard string
+}
+
+func TestWatchShards(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts, factory := memorytopo.NewServerAndFactory(ctx, "zone1")
+	defer ts.Close()
+
+	_, err := ts.GetOrCreateShard(ctx, "ks", "-80")
+	require.NoError(t, err)
+	_, err = ts.GetOrCreateShard(ctx, "ks", "80-")
+	require.NoError(t, err)
+	updateShard := func(shard string, update func(*topo.ShardInfo)) {
+		_, err := ts.UpdateShardFields(ctx, "ks", shard, func(si *topo.ShardInfo) error {
+			update(si)
+			return nil
+		})
+		require.NoError(t, err)
+	}
+	updateShard("-80", func(si *topo.ShardInfo) {
+		si.IsPrimaryServing = true
+	})
+
+	events := make(chan shardEvent, 10)
+	record := func(op string) func(old, new *topo.ShardInfo) {
+		return func(old, new *topo.ShardInfo) {
+			events <- shardEvent{op: op, shard: new.ShardName()}
+		}
+	}
+	callbacks := &topo.ShardsWatchCallbacks{
+		OnPrimaryChange: func(old, new *topo.ShardInfo) {
+			assert.Nil(t, old.PrimaryAlias)
+			record("primary")(old, new)
+		},
+		OnIsPrimaryServingChange: record("serving"),
+		OnTabletControlsChange:   record("tablet controls"),
+	}
+
+	watchCtx, watchCancel := context.WithCancel(ctx)
+	defer watchCancel()
+	sv, err := ts.WatchShards(watchCtx, []topo.KeyspaceShard{{Keyspace: "ks", Shard: "-80"}, {Keyspace: "ks", Shard: "80-"}}, callbacks)
+	require.NoError(t, err)
+
+	// The current records are there before WatchShards returns, without
+	// calling the callbacks.
+	si, ok := sv.GetShard("ks", "-80")
+	require.True(t, ok)
+	assert.True(t, si.IsPrimaryServing)
+	_, ok = sv.GetShard("ks", "-")
+	assert.False(t, ok)
+	assert.Empty(t, events)
+
+	// A change of several fields calls each of their callbacks.
+	updateShard("-80", func(si *topo.ShardInfo) {
+		si.PrimaryAlias = &topodatapb.TabletAlias{Cell: "zone1", Uid: 1}
+		si.IsPrimaryServing = false
+	})
+	assert.Equal(t, shardEvent{op: "primary", shard: "-80"}, <-events)
+	assert.Equal(t, shardEvent{op: "serving", shard: "-80"}, <-events)
+
+	updateShard("80-", func(si *topo.ShardInfo) {
+		si.TabletControls = []*topodatapb.Shard_TabletControl{{TabletType: topodatapb.TabletType_RDONLY, Frozen: true}}
+	})
+	assert.Equal(t, shardEvent{op: "tablet controls", shard: "80-"}, <-events)
+
+	// So does a new term of the primary.
+	updateShard("80-", func(si *topo.ShardInfo) {
+		si.PrimaryTermStartTime = protoutil.TimeToProto(time.Now())
+	})
+	assert.Equal(t, shardEvent{op: "primary", shard: "80-"}, <-events)
+
+	// The other changes don't call them.
+	updateShard("80-", func(si *topo.ShardInfo) {
+		si.SourceShards = []*topodatapb.Shard_SourceShard{{Uid: 1, Keyspace: "source", Shard: "0"}}
+	})
+
+	// The changes made while the watches fail are caught up with once they
+	// are set again.
+	factory.SetError(errors.New("connection lost"))
+	require.Eventually(t, func() bool {
+		return !sv.StaleSince().IsZero()
+	}, 10*time.Second, 10*time.Millisecond)
+	factory.SetError(nil)
+	updateShard("80-", func(si *topo.ShardInfo) {
+		si.PrimaryAlias = &topodatapb.TabletAlias{Cell: "zone1", Uid: 2}
+	})
+	select {
+	case event := <-events:
+		assert.Equal(t, shardEvent{op: "primary", shard: "80-"}, event)
+	case <-time.After(10 * time.Second):
+		t.Fatal("the change was not caught up with")
+	}
+	require.Eventually(t, func() bool {
+		return sv.StaleSince().IsZero()
+	}, 10*time.Second, 10*time.Millisecond)
+	si, ok = sv.GetShard("ks", "80-")
+	require.True(t, ok)
+	assert.EqualValues(t, 2, si.PrimaryAlias.Uid)
+
+	watchCancel()
+	select {
+	case <-sv.Done():
+	case <-time.After(10 * time.Second):
+		t.Fatal("the watches did not stop")
+	}
+	assert.Empty(t, events)
+
+	// The shards which can't be watched fail WatchShards.
+	_, err = ts.WatchShards(ctx, []topo.KeyspaceShard{{Keyspace: "ks", Shard: "-80"}, {Keyspace: "ks", Shard: "-"}}, callbacks)
+	assert.True(t, topo.IsErrType(err, topo.NoNode), "unexpected error: %v", err)
+}
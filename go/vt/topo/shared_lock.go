@@ -0,0 +1,103 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topo
+
+import (
+	"context"
+	"fmt"
+	"path"
+
+	"github.com/google/uuid"
+
+	"vitess.io/vitess/go/vt/log"
+)
+
+// A shared lock is a lock of the global cell on
+// <resource path>/shared_locks/<uuid>, taken with the Lock of its holder as
+// contents. Like the exclusive locks, it is backed by a lease or a session of
+// the topology server, kept alive by its holder, so the shared lock of a
+// process that died is released by the topology server after SharedLockTTL.
+// The exclusive locks wait for the shared locks by taking their locks in
+// turn, which the topology servers notify them of, and break the stale ones.
+
+// createSharedLock registers a shared lock on a resource. The caller must
+// hold the exclusive lock of the resource.
+func (ts *Server) createSharedLock(ctx context.Context, lt iTopoLock, l *Lock) (LockDescriptor, error) {
+	j, err := l.ToJSON()
+	if err != nil {
+		return nil, err
+	}
+	dirPath := path.Join(lt.Path(), SharedLocksPath, uuid.NewString())
+	return lockNameWithTTL(ctx, ts.globalCell, dirPath, j, SharedLockTTL)
+}
+
+// waitForSharedLocks waits until the shared locks of a resource are
+// released, breaking the stale ones. If isBlocking is false, it fails instead
+// of waiting. The caller must hold the exclusive lock of the resource, with
+// the contents j, so that no shared lock is taken meanwhile.
+func (ts *Server) waitForSharedLocks(ctx context.Context, lt iTopoLock, j string, isBlocking bool) error {
+	dirPath := path.Join(lt.Path(), SharedLocksPath)
+	entries, err := ts.globalCell.ListDir(ctx, dirPath, false /*full*/)
+	switch {
+	case err == nil:
+	case IsErrType(err, NoNode):
+		return nil
+	default:
+		return err
+	}
+
+	for _, entry := range entries {
+		sharedLockPath := path.Join(dirPath, entry.Name)
+		info, err := getLock(ctx, ts.globalCell, sharedLockPath)
+		switch {
+		case err == nil:
+		case IsErrType(err, NoNode):
+			// Released, the topology server may keep its directory.
+			continue
+		case IsErrType(err, NoImplementation):
+			// The topology server can't tell whether it is held, or
+			// stale, so it is waited for as if it were held.
+			info = nil
+		default:
+			return err
+		}
+
+		if info != nil && info.Stale {
+			// Its holder is gone, so it will be released anyway once
+			// its lease or session expires.
+			log.Warningf("Breaking stale shared lock on %v %v: %v", lt.Type(), lt.ResourceName(), info.Contents)
+			if err := forceUnlock(ctx, ts.globalCell, sharedLockPath, info.Contents); err != nil && !IsErrType(err, NoNode) {
+				return err
+			}
+			continue
+		}
+		if !isBlocking {
+			return NewError(NodeExists, fmt.Sprintf("shared lock already exists at path %s", lt.Path()))
+		}
+
+		// Taking the lock waits until the shared lock is released, or
+		// expires with its holder.
+		ld, err := lockNameWithTTL(ctx, ts.globalCell, sharedLockPath, j, 0)
+		if err != nil {
+			return err
+		}
+		if err := ld.Unlock(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
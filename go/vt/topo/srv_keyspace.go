@@ -124,6 +124,18 @@ func (ts *Server) GetSrvKeyspaceNames(ctx context.Context, cell string) ([]strin
 	}
 }
 
+// WatchSrvKeyspaceNames watches the list of keyspaces served in a cell. It
+// has the contract of WatchKeyspaces, and returns a NoImplementation error if
+// the topo server of the cell does not support WatchRecursive, in which case
+// the caller should poll GetSrvKeyspaceNames.
+func (ts *Server) WatchSrvKeyspaceNames(ctx context.Context, cell string) (*WatchKeyspaceNamesData, <-chan *WatchKeyspaceNamesData, error) {
+	conn, err := ts.ConnForCell(ctx, cell)
+	if err != nil {
+		return &WatchKeyspaceNamesData{Err: err}, nil, nil
+	}
+	return watchKeyspaceNames(ctx, conn)
+}
+
 // GetShardServingCells returns cells where this shard is serving
 func (ts *Server) GetShardServingCells(ctx context.Context, si *ShardInfo) (servingCells []string, err error) {
 	cells, err := ts.GetCellInfoNames(ctx)
@@ -234,7 +246,7 @@ func (ts *Server) GetShardServingTypes(ctx context.Context, si *ShardInfo) (serv
 
 // AddSrvKeyspacePartitions adds partitions to srvKeyspace
 func (ts *Server) AddSrvKeyspacePartitions(ctx context.Context, keyspace string, shards []*ShardInfo, tabletType topodatapb.TabletType, cells []string) (err error) {
-	if err = CheckKeyspaceLocked(ctx, keyspace); err != nil {
+	if err = CheckKeyspaceRoutingLocked(ctx, keyspace); err != nil {
 		return err
 	}
 
@@ -324,7 +336,7 @@ func (ts *Server) AddSrvKeyspacePartitions(ctx context.Context, keyspace string,
 
 // DeleteSrvKeyspacePartitions deletes shards from srvKeyspace partitions
 func (ts *Server) DeleteSrvKeyspacePartitions(ctx context.Context, keyspace string, shards []*ShardInfo, tabletType topodatapb.TabletType, cells []string) (err error) {
-	if err = CheckKeyspaceLocked(ctx, keyspace); err != nil {
+	if err = CheckKeyspaceRoutingLocked(ctx, keyspace); err != nil {
 		return err
 	}
 
@@ -396,7 +408,7 @@ func (ts *Server) DeleteSrvKeyspacePartitions(ctx context.Context, keyspace stri
 
 // UpdateSrvKeyspaceThrottlerConfig updates existing throttler configuration
 func (ts *Server) UpdateSrvKeyspaceThrottlerConfig(ctx context.Context, keyspace string, cells []string, update func(throttlerConfig *topodatapb.ThrottlerConfig) *topodatapb.ThrottlerConfig) (updatedCells []string, err error) {
-	if err = CheckKeyspaceLocked(ctx, keyspace); err != nil {
+	if err = CheckKeyspaceRoutingLocked(ctx, keyspace); err != nil {
 		return updatedCells, err
 	}
 
@@ -442,7 +454,7 @@ func (ts *Server) UpdateSrvKeyspaceThrottlerConfig(ctx context.Context, keyspace
 // UpdateDisableQueryService will make sure the disableQueryService is
 // set appropriately in tablet controls in srvKeyspace.
 func (ts *Server) UpdateDisableQueryService(ctx context.Context, keyspace string, shards []*ShardInfo, tabletType topodatapb.TabletType, cells []string, disableQueryService bool) (err error) {
-	if err = CheckKeyspaceLocked(ctx, keyspace); err != nil {
+	if err = CheckKeyspaceRoutingLocked(ctx, keyspace); err != nil {
 		return err
 	}
 
@@ -530,7 +542,7 @@ func (ts *Server) UpdateDisableQueryService(ctx context.Context, keyspace string
 
 // MigrateServedType removes/adds shards from srvKeyspace when migrating a served type.
 func (ts *Server) MigrateServedType(ctx context.Context, keyspace string, shardsToAdd, shardsToRemove []*ShardInfo, tabletType topodatapb.TabletType, cells []string) (err error) {
-	if err = CheckKeyspaceLocked(ctx, keyspace); err != nil {
+	if err = CheckKeyspaceRoutingLocked(ctx, keyspace); err != nil {
 		return err
 	}
 
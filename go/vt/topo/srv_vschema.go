@@ -19,8 +19,11 @@ package topo
 import (
 	"context"
 	"fmt"
+	"slices"
 	"sync"
 
+	"google.golang.org/protobuf/proto"
+
 	"vitess.io/vitess/go/vt/log"
 	"vitess.io/vitess/go/vt/vterrors"
 
@@ -111,6 +114,69 @@ func (ts *Server) UpdateSrvVSchema(ctx context.Context, cell string, srvVSchema
 	return err
 }
 
+// UpdateSrvVSchemaKeyspace sets the vschema of a single keyspace in the
+// SrvVSchema of a cell, or removes it if vschema is nil, leaving the other
+// keyspaces and the routing rules alone. It returns whether the SrvVSchema
+// changed: it is not rewritten, and its watchers are not notified, when the
+// keyspace already had this vschema.
+//
+// It returns a NoNode error if the cell has no SrvVSchema yet, as it can
+// only be patched once built with RebuildSrvVSchema.
+func (ts *Server) UpdateSrvVSchemaKeyspace(ctx context.Context, cell, keyspace string, vschema *vschemapb.Keyspace) (bool, error) {
+	changed, _, err := ts.updateSrvVSchemaKeyspace(ctx, cell, keyspace, vschema, nil)
+	return changed, err
+}
+
+// updateSrvVSchemaKeyspace is UpdateSrvVSchemaKeyspace, but leaves the
+// SrvVSchema alone, and returns stale, if current is set and returns false for
+// it.
+func (ts *Server) updateSrvVSchemaKeyspace(ctx context.Context, cell, keyspace string, vschema *vschemapb.Keyspace, current func(*vschemapb.SrvVSchema) bool) (changed, stale bool, err error) {
+	conn, err := ts.ConnForCell(ctx, cell)
+	if err != nil {
+		return false, false, err
+	}
+
+	for {
+		data, version, err := conn.Get(ctx, SrvVSchemaFile)
+		if err != nil {
+			return false, false, err
+		}
+		srvVSchema := &vschemapb.SrvVSchema{}
+		if err := srvVSchema.UnmarshalVT(data); err != nil {
+			return false, false, vterrors.Wrapf(err, "SrvVSchema unmarshal failed: %v", data)
+		}
+		if current != nil && !current(srvVSchema) {
+			return false, true, nil
+		}
+
+		old, ok := srvVSchema.Keyspaces[keyspace]
+		switch {
+		case vschema == nil && !ok:
+			return false, false, nil
+		case vschema == nil:
+			delete(srvVSchema.Keyspaces, keyspace)
+		case ok && proto.Equal(old, vschema):
+			return false, false, nil
+		default:
+			if srvVSchema.Keyspaces == nil {
+				srvVSchema.Keyspaces = map[string]*vschemapb.Keyspace{}
+			}
+			srvVSchema.Keyspaces[keyspace] = vschema
+		}
+
+		data, err = srvVSchema.MarshalVT()
+		if err != nil {
+			return false, false, err
+		}
+		_, err = conn.Update(ctx, SrvVSchemaFile, data, version)
+		if IsErrType(err, BadVersion) {
+			// The SrvVSchema was updated by another process, try again.
+			continue
+		}
+		return err == nil, false, err
+	}
+}
+
 // GetSrvVSchema returns the SrvVSchema for a cell.
 func (ts *Server) GetSrvVSchema(ctx context.Context, cell string) (*vschemapb.SrvVSchema, error) {
 	conn, err := ts.ConnForCell(ctx, cell)
@@ -227,3 +293,126 @@ func (ts *Server) RebuildSrvVSchema(ctx context.Context, cells []string) error {
 
 	return finalErr
 }
+
+// RebuildSrvVSchemaKeyspace updates the vschema of a single keyspace in the
+// SrvVSchema of the provided cells (or all cells if cell list is empty),
+// rather than rebuilding it from all the keyspaces like RebuildSrvVSchema.
+// The keyspace is removed from the SrvVSchemas if it doesn't exist anymore.
+//
+// Only the SrvVSchemas which are otherwise up to date are patched: the cells
+// without a SrvVSchema yet, or whose SrvVSchema has other routing rules or
+// other keyspaces than the global topo, are fully rebuilt with
+// RebuildSrvVSchema. The changes to the vschemas of the other keyspaces which
+// skipped their rebuild are not picked up, as they would not be by their
+// own rebuilds either.
+func (ts *Server) RebuildSrvVSchemaKeyspace(ctx context.Context, keyspace string, cells []string) error {
+	// get the actual list of cells
+	if len(cells) == 0 {
+		var err error
+		cells, err = ts.GetKnownCells(ctx)
+		if err != nil {
+			return fmt.Errorf("GetKnownCells failed: %v", err)
+		}
+	}
+
+	keyspaces, err := ts.GetKeyspaces(ctx)
+	if err != nil {
+		return fmt.Errorf("GetKeyspaces failed: %v", err)
+	}
+
+	// vschema stays nil to remove a deleted keyspace.
+	var vschema *vschemapb.Keyspace
+	if slices.Contains(keyspaces, keyspace) {
+		vschema, err = ts.GetVSchema(ctx, keyspace)
+		if IsErrType(err, NoNode) {
+			vschema = &vschemapb.Keyspace{}
+		} else if err != nil {
+			return fmt.Errorf("GetVSchema(%v) failed: %v", keyspace, err)
+		}
+	}
+
+	rules, err := ts.getSrvVSchemaRoutingRules(ctx)
+	if err != nil {
+		return err
+	}
+	current := func(srvVSchema *vschemapb.SrvVSchema) bool {
+		if !srvVSchemaRoutingRulesEqual(srvVSchema, rules) {
+			return false
+		}
+		for ks := range srvVSchema.Keyspaces {
+			if ks != keyspace && !slices.Contains(keyspaces, ks) {
+				return false
+			}
+		}
+		for _, ks := range keyspaces {
+			if _, ok := srvVSchema.Keyspaces[ks]; ks != keyspace && !ok {
+				return false
+			}
+		}
+		return true
+	}
+
+	// now patch the SrvVSchema in all cells in parallel
+	wg := sync.WaitGroup{}
+	mu := sync.Mutex{}
+	var finalErr error
+	var rebuildCells []string
+	for _, cell := range cells {
+		wg.Add(1)
+		go func(cell string) {
+			defer wg.Done()
+			_, stale, err := ts.updateSrvVSchemaKeyspace(ctx, cell, keyspace, vschema, current)
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err == nil && stale:
+				rebuildCells = append(rebuildCells, cell)
+			case err == nil:
+			case IsErrType(err, NoNode):
+				rebuildCells = append(rebuildCells, cell)
+			default:
+				log.Errorf("%v: UpdateSrvVSchemaKeyspace(%v, %v) failed", err, cell, keyspace)
+				finalErr = err
+			}
+		}(cell)
+	}
+	wg.Wait()
+	if finalErr != nil {
+		return finalErr
+	}
+
+	if len(rebuildCells) > 0 {
+		return ts.RebuildSrvVSchema(ctx, rebuildCells)
+	}
+	return nil
+}
+
+// getSrvVSchemaRoutingRules returns a SrvVSchema holding only the routing
+// rules of the global topo.
+func (ts *Server) getSrvVSchemaRoutingRules(ctx context.Context) (*vschemapb.SrvVSchema, error) {
+	rr, err := ts.GetRoutingRules(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("GetRoutingRules failed: %v", err)
+	}
+	srr, err := ts.GetShardRoutingRules(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("GetShardRoutingRules failed: %v", err)
+	}
+	krr, err := ts.GetKeyspaceRoutingRules(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("GetKeyspaceRoutingRules failed: %v", err)
+	}
+	return &vschemapb.SrvVSchema{
+		RoutingRules:         rr,
+		ShardRoutingRules:    srr,
+		KeyspaceRoutingRules: krr,
+	}, nil
+}
+
+// srvVSchemaRoutingRulesEqual returns whether two SrvVSchemas have the same
+// routing rules. Missing rules are the same as empty ones.
+func srvVSchemaRoutingRulesEqual(a, b *vschemapb.SrvVSchema) bool {
+	return slices.EqualFunc(a.GetRoutingRules().GetRules(), b.GetRoutingRules().GetRules(), func(x, y *vschemapb.RoutingRule) bool { return proto.Equal(x, y) }) &&
+		slices.EqualFunc(a.GetShardRoutingRules().GetRules(), b.GetShardRoutingRules().GetRules(), func(x, y *vschemapb.ShardRoutingRule) bool { return proto.Equal(x, y) }) &&
+		slices.EqualFunc(a.GetKeyspaceRoutingRules().GetRules(), b.GetKeyspaceRoutingRules().GetRules(), func(x, y *vschemapb.KeyspaceRoutingRule) bool { return proto.Equal(x, y) })
+}
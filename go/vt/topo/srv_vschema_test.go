@@ -0,0 +1,93 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topo_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
+)
+
+func TestRebuildSrvVSchemaKeyspace(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "zone1", "zone2")
+	defer ts.Close()
+
+	for _, keyspace := range []string{"ks1", "ks2"} {
+		require.NoError(t, ts.CreateKeyspace(ctx, keyspace, &topodatapb.Keyspace{}))
+		require.NoError(t, ts.SaveVSchema(ctx, keyspace, &vschemapb.Keyspace{}))
+	}
+	require.NoError(t, ts.RebuildSrvVSchema(ctx, []string{"zone1"}))
+
+	current, changes, err := ts.WatchSrvVSchema(ctx, "zone1")
+	require.NoError(t, err)
+	assert.Len(t, current.Value.Keyspaces, 2)
+
+	// Only the keyspace that changed is patched. zone2 has no SrvVSchema
+	// yet, so it is fully rebuilt.
+	require.NoError(t, ts.SaveVSchema(ctx, "ks1", &vschemapb.Keyspace{Sharded: true}))
+	require.NoError(t, ts.RebuildSrvVSchemaKeyspace(ctx, "ks1", nil))
+	wd := <-changes
+	require.NoError(t, wd.Err)
+	assert.True(t, wd.Value.Keyspaces["ks1"].Sharded)
+	assert.Contains(t, wd.Value.Keyspaces, "ks2")
+	srvVSchema, err := ts.GetSrvVSchema(ctx, "zone2")
+	require.NoError(t, err)
+	assert.True(t, srvVSchema.Keyspaces["ks1"].Sharded)
+	assert.Contains(t, srvVSchema.Keyspaces, "ks2")
+
+	// The SrvVSchema is not rewritten when the keyspace didn't change.
+	changed, err := ts.UpdateSrvVSchemaKeyspace(ctx, "zone1", "ks2", &vschemapb.Keyspace{})
+	require.NoError(t, err)
+	assert.False(t, changed)
+
+	// A deleted keyspace is removed.
+	require.NoError(t, ts.DeleteKeyspace(ctx, "ks2"))
+	require.NoError(t, ts.RebuildSrvVSchemaKeyspace(ctx, "ks2", []string{"zone1"}))
+	wd = <-changes
+	require.NoError(t, wd.Err)
+	assert.NotContains(t, wd.Value.Keyspaces, "ks2")
+
+	// The SrvVSchemas whose routing rules or other keyspaces are out of date
+	// are fully rebuilt.
+	require.NoError(t, ts.SaveRoutingRules(ctx, &vschemapb.RoutingRules{
+		Rules: []*vschemapb.RoutingRule{{FromTable: "t", ToTables: []string{"ks1.t"}}},
+	}))
+	require.NoError(t, ts.CreateKeyspace(ctx, "ks3", &topodatapb.Keyspace{}))
+	require.NoError(t, ts.SaveVSchema(ctx, "ks1", &vschemapb.Keyspace{}))
+	require.NoError(t, ts.RebuildSrvVSchemaKeyspace(ctx, "ks1", []string{"zone1"}))
+	wd = <-changes
+	require.NoError(t, wd.Err)
+	assert.False(t, wd.Value.Keyspaces["ks1"].Sharded)
+	assert.Contains(t, wd.Value.Keyspaces, "ks3")
+	assert.Len(t, wd.Value.RoutingRules.Rules, 1)
+
+	_, err = ts.UpdateSrvVSchemaKeyspace(ctx, "zone3", "ks1", &vschemapb.Keyspace{})
+	assert.Error(t, err)
+	require.NoError(t, ts.DeleteSrvVSchema(ctx, "zone2"))
+	_, err = ts.UpdateSrvVSchemaKeyspace(ctx, "zone2", "ks1", &vschemapb.Keyspace{})
+	assert.True(t, topo.IsErrType(err, topo.NoNode), "expected NoNode, got %v", err)
+}
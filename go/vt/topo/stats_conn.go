@@ -18,9 +18,16 @@ package topo
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/logutil"
 	"vitess.io/vitess/go/vt/proto/vtrpc"
 	"vitess.io/vitess/go/vt/vterrors"
 )
@@ -35,17 +42,121 @@ var (
 
 	topoStatsConnErrors = stats.NewCountersWithMultiLabels(
 		"TopologyConnErrors",
-		"TopologyConnErrors errors per operation",
+		"TopologyConnErrors errors per operation and vtrpc error code",
+		[]string{"Operation", "Cell", "Code"})
+
+	topoStatsConnCanceled = stats.NewCountersWithMultiLabels(
+		"TopologyConnCanceled",
+		"TopologyConnCanceled operations given up by their caller, whose context was canceled, which are not counted in TopologyConnErrors",
 		[]string{"Operation", "Cell"})
+
+	topoStatsConnConcurrencyWaits = stats.NewMultiTimings(
+		"TopologyConnConcurrencyWaits",
+		"TopologyConnConcurrencyWaits timings of the waits of the operations held back by the read or write concurrency limit of the cell",
+		[]string{"Cell", "Kind"})
+
+	topoStatsConnSlowOperations = stats.NewCountersWithMultiLabels(
+		"TopologyConnSlowOperations",
+		"TopologyConnSlowOperations operations slower than --topo_slow_operation_threshold",
+		[]string{"Operation", "Cell"})
+
+	topoStatsConnValueSizes = stats.NewCountersWithMultiLabels(
+		"TopologyConnValueSizes",
+		"TopologyConnValueSizes values written by Create and Update per path category, by size bucket: the upper bound of the bucket in bytes, or inf",
+		[]string{"Cell", "Category", "Bucket"})
+
+	topoStatsConnValueBytes = stats.NewCountersWithMultiLabels(
+		"TopologyConnValueBytes",
+		"TopologyConnValueBytes total size of the values written by Create and Update per path category",
+		[]string{"Cell", "Category"})
+
+	// slowOperationsLogger logs the slow operations, at most once per second.
+	slowOperationsLogger = logutil.NewThrottledLogger("TopologySlowOperation", time.Second)
+
+	// topoPathStats is the group of the per-path stats, which are only
+	// recorded while it is enabled, having as many label values as there are
+	// topo paths.
+	topoPathStats = stats.NewGroup("topo_paths", "Timings of the topo operations per path")
+
+	topoStatsConnPathTimings = stats.NewMultiTimings(
+		"TopologyConnPathOperations",
+		"TopologyConnPathOperations timings per path, recorded while the topo_paths stats group is enabled",
+		[]string{"Operation", "Cell", "Path"})
 )
 
 const readOnlyErrorStrFormat = "cannot perform %s on %s as the topology server connection is read-only"
 
+// The kinds of operations limited by the concurrency limits of a StatsConn.
+const (
+	readOperation  = "Read"
+	writeOperation = "Write"
+)
+
+// valueSizeBuckets are the upper bounds of the size buckets of the values
+// counted in TopologyConnValueSizes.
+var valueSizeBuckets = []int{1 << 10, 16 << 10, 64 << 10, 256 << 10, 1 << 20, 4 << 20}
+
+// valueCategoryFiles are the file names of the objects, which are the
+// categories of their values.
+var valueCategoryFiles = map[string]bool{
+	CellInfoFile:           true,
+	CellsAliasFile:         true,
+	KeyspaceFile:           true,
+	ShardFile:              true,
+	VSchemaFile:            true,
+	ShardReplicationFile:   true,
+	TabletFile:             true,
+	SrvVSchemaFile:         true,
+	SrvKeyspaceFile:        true,
+	RoutingRulesFile:       true,
+	ExternalClustersFile:   true,
+	ShardRoutingRulesFile:  true,
+	CommonRoutingRulesFile: true,
+}
+
+// recentErrorsSize is the number of recent errors a StatsConn keeps for the
+// /debug/topo page.
+const recentErrorsSize = 10
+
 // The StatsConn is a wrapper for a Conn that emits stats for every operation
 type StatsConn struct {
 	cell     string
 	conn     Conn
 	readOnly bool
+
+	// maxValueSize is the size in bytes above which the writes are
+	// rejected, if set.
+	maxValueSize int
+
+	// identifier is the Conn presenting a client identity to the topology
+	// server, if any.
+	identifier ClientIdentifier
+	// identityMu protects clientIdentity, the identity identifier presents
+	// as last recorded.
+	identityMu     sync.Mutex
+	clientIdentity string
+
+	// inFlight is the number of operations in progress.
+	inFlight atomic.Int64
+
+	// readLimiter and writeLimiter limit the concurrency of the reads and
+	// the writes, if set.
+	readLimiter  *concurrencyLimiter
+	writeLimiter *concurrencyLimiter
+
+	// mu protects the following fields.
+	mu sync.Mutex
+	// watches is the number of active watches per path.
+	watches map[string]int
+	// recentErrors are the last unexpected errors, oldest first.
+	recentErrors []ConnError
+}
+
+// ConnError is an error returned by an operation of a StatsConn.
+type ConnError struct {
+	Time      time.Time
+	Operation string
+	Error     string
 }
 
 // NewStatsConn returns a StatsConn
@@ -54,17 +165,239 @@ func NewStatsConn(cell string, conn Conn) *StatsConn {
 		cell:     cell,
 		conn:     conn,
 		readOnly: false,
+		watches:  make(map[string]int),
+	}
+}
+
+// begin records the start of an operation on path, and returns the function
+// to call with its error when it ends. The timing of the operation is
+// recorded with ctx, so that it can be linked to the trace of the operation.
+// It is also recorded for path while the topo_paths stats group is enabled,
+// unless path is empty. The operations slower than --topo_slow_operation_threshold
+// are counted and logged.
+func (st *StatsConn) begin(ctx context.Context, statsKey []string, path string) func(err error) {
+	startTime := time.Now()
+	st.inFlight.Add(1)
+	return func(err error) {
+		st.inFlight.Add(-1)
+		topoStatsConnTimings.RecordContext(ctx, statsKey, startTime)
+		if path != "" && topoPathStats.Enabled() {
+			topoStatsConnPathTimings.RecordContext(ctx, []string{statsKey[0], statsKey[1], path}, startTime)
+		}
+		if duration := time.Since(startTime); topoSlowOperationThreshold > 0 && duration >= topoSlowOperationThreshold {
+			topoStatsConnSlowOperations.Add(statsKey, 1)
+			slowOperationsLogger.Warningf("operation=%s cell=%s path=%q duration=%v error=%v", statsKey[0], statsKey[1], path, duration, err)
+		}
+	}
+}
+
+// concurrencyLimiter is a semaphore whose size, returned by limit, may change
+// at runtime. A size of 0 leaves the operations unlimited.
+type concurrencyLimiter struct {
+	limit func() int
+
+	mu    sync.Mutex
+	inUse int
+	// released is closed, and replaced, when a slot is released.
+	released chan struct{}
+}
+
+func newConcurrencyLimiter(limit func() int) *concurrencyLimiter {
+	return &concurrencyLimiter{
+		limit:    limit,
+		released: make(chan struct{}),
+	}
+}
+
+// tryAcquire takes a slot if one is free, or returns the channel closed when
+// one is next released.
+func (cl *concurrencyLimiter) tryAcquire() (bool, <-chan struct{}) {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+	if limit := cl.limit(); limit <= 0 || cl.inUse < limit {
+		cl.inUse++
+		return true, nil
+	}
+	return false, cl.released
+}
+
+// acquire waits for a slot, and returns whether it had to wait.
+func (cl *concurrencyLimiter) acquire(ctx context.Context) (waited bool, err error) {
+	for {
+		ok, released := cl.tryAcquire()
+		if ok {
+			return waited, nil
+		}
+		waited = true
+		select {
+		case <-released:
+		case <-ctx.Done():
+			return waited, ctx.Err()
+		}
+	}
+}
+
+func (cl *concurrencyLimiter) release() {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+	cl.inUse--
+	close(cl.released)
+	cl.released = make(chan struct{})
+}
+
+// acquire waits for a slot of cl, if set, for an operation of the given kind,
+// and returns the function to call to release it when the operation ends.
+func (st *StatsConn) acquire(ctx context.Context, cl *concurrencyLimiter, kind string) (func(), error) {
+	if cl == nil {
+		return func() {}, nil
+	}
+	startTime := time.Now()
+	waited, err := cl.acquire(ctx)
+	if waited {
+		topoStatsConnConcurrencyWaits.Record([]string{st.cell, kind}, startTime)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return cl.release, nil
+}
+
+// recordError counts an error of an operation, and keeps it as a recent
+// error unless it is an expected outcome of the operation, like a missing
+// node or a version mismatch. The operations given up by their caller, whose
+// ctx was canceled, are counted apart, as they are not failures of the
+// topology server. The operations whose ctx exceeded its deadline may be
+// failures of a slow topology server, so they are counted as
+// DEADLINE_EXCEEDED errors.
+func (st *StatsConn) recordError(ctx context.Context, statsKey []string, err error) {
+	code := errorCode(err)
+	switch {
+	case errors.Is(err, context.Canceled) || ctx.Err() == context.Canceled:
+		topoStatsConnCanceled.Add(statsKey, int64(1))
+		return
+	case errors.Is(err, context.DeadlineExceeded) || ctx.Err() == context.DeadlineExceeded:
+		code = vtrpc.Code_DEADLINE_EXCEEDED
+	}
+	topoStatsConnErrors.Add([]string{statsKey[0], statsKey[1], code.String()}, int64(1))
+	if IsErrType(err, NoNode) || IsErrType(err, NodeExists) || IsErrType(err, BadVersion) {
+		return
+	}
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if len(st.recentErrors) == recentErrorsSize {
+		st.recentErrors = st.recentErrors[1:]
+	}
+	st.recentErrors = append(st.recentErrors, ConnError{
+		Time:      time.Now(),
+		Operation: statsKey[0],
+		Error:     err.Error(),
+	})
+}
+
+// readOnlyError returns the error of a write operation on path refused
+// because the connection is read-only, and counts it.
+func (st *StatsConn) readOnlyError(statsKey []string, path string) error {
+	err := vterrors.Errorf(vtrpc.Code_READ_ONLY, readOnlyErrorStrFormat, statsKey[0], path)
+	st.recordError(context.Background(), statsKey, err)
+	return err
+}
+
+// errorCode returns the vtrpc error code of an error of a topo operation.
+// The topo errors are mapped to the closest vtrpc code, the other errors have
+// the code vterrors.Code finds for them.
+func errorCode(err error) vtrpc.Code {
+	var e Error
+	if !errors.As(err, &e) {
+		return vterrors.Code(err)
+	}
+	switch e.code {
+	case NoNode:
+		return vtrpc.Code_NOT_FOUND
+	case NodeExists:
+		return vtrpc.Code_ALREADY_EXISTS
+	case NodeNotEmpty, BadVersion, NoUpdateNeeded:
+		return vtrpc.Code_FAILED_PRECONDITION
+	case Timeout:
+		return vtrpc.Code_DEADLINE_EXCEEDED
+	case Interrupted:
+		return vtrpc.Code_CANCELED
+	case PartialResult:
+		return vtrpc.Code_UNAVAILABLE
+	case NoImplementation, NoReadOnlyImplementation:
+		return vtrpc.Code_UNIMPLEMENTED
+	case ResourceExhausted, QuotaExceeded:
+		return vtrpc.Code_RESOURCE_EXHAUSTED
+	case ValueTooLarge:
+		return vtrpc.Code_INVALID_ARGUMENT
+	default:
+		return vtrpc.Code_UNKNOWN
+	}
+}
+
+// checkValueSize counts the size of the value of a write to filePath, and
+// returns the ValueTooLarge error of the writes of values larger than the
+// maximum value size, which are rejected.
+func (st *StatsConn) checkValueSize(statsKey []string, filePath string, contents []byte) error {
+	category := valueCategory(filePath)
+	bucket := "inf"
+	for _, b := range valueSizeBuckets {
+		if len(contents) <= b {
+			bucket = strconv.Itoa(b)
+			break
+		}
+	}
+	topoStatsConnValueSizes.Add([]string{st.cell, category, bucket}, 1)
+	topoStatsConnValueBytes.Add([]string{st.cell, category}, int64(len(contents)))
+
+	if st.maxValueSize <= 0 || len(contents) <= st.maxValueSize {
+		return nil
+	}
+	err := NewError(ValueTooLarge, fmt.Sprintf("%v is %v bytes, above the limit of %v bytes", filePath, len(contents), st.maxValueSize))
+	st.recordError(context.Background(), statsKey, err)
+	return err
+}
+
+// valueCategory returns the category of the values written to filePath: the
+// file name of the objects, like Keyspace or SrvVSchema, or the top-level
+// directory of the other files, like tablet_leases.
+func valueCategory(filePath string) string {
+	parts := strings.Split(strings.Trim(filePath, "/"), "/")
+	if file := parts[len(parts)-1]; valueCategoryFiles[file] {
+		return file
+	}
+	return parts[0]
+}
+
+// trackWatch counts a watch of a path as active, and returns the function
+// to call when it ends.
+func (st *StatsConn) trackWatch(path string) func() {
+	st.mu.Lock()
+	st.watches[path]++
+	st.mu.Unlock()
+	return func() {
+		st.mu.Lock()
+		defer st.mu.Unlock()
+		if st.watches[path]--; st.watches[path] == 0 {
+			delete(st.watches, path)
+		}
 	}
 }
 
 // ListDir is part of the Conn interface
 func (st *StatsConn) ListDir(ctx context.Context, dirPath string, full bool) ([]DirEntry, error) {
-	startTime := time.Now()
 	statsKey := []string{"ListDir", st.cell}
-	defer topoStatsConnTimings.Record(statsKey, startTime)
+	release, err := st.acquire(ctx, st.readLimiter, readOperation)
+	if err != nil {
+		st.recordError(ctx, statsKey, err)
+		return nil, err
+	}
+	defer release()
+	end := st.begin(ctx, statsKey, dirPath)
 	res, err := st.conn.ListDir(ctx, dirPath, full)
+	end(err)
 	if err != nil {
-		topoStatsConnErrors.Add(statsKey, int64(1))
+		st.recordError(ctx, statsKey, err)
 		return res, err
 	}
 	return res, err
@@ -74,13 +407,22 @@ func (st *StatsConn) ListDir(ctx context.Context, dirPath string, full bool) ([]
 func (st *StatsConn) Create(ctx context.Context, filePath string, contents []byte) (Version, error) {
 	statsKey := []string{"Create", st.cell}
 	if st.readOnly {
-		return nil, vterrors.Errorf(vtrpc.Code_READ_ONLY, readOnlyErrorStrFormat, statsKey[0], filePath)
+		return nil, st.readOnlyError(statsKey, filePath)
 	}
-	startTime := time.Now()
-	defer topoStatsConnTimings.Record(statsKey, startTime)
+	if err := st.checkValueSize(statsKey, filePath, contents); err != nil {
+		return nil, err
+	}
+	release, err := st.acquire(ctx, st.writeLimiter, writeOperation)
+	if err != nil {
+		st.recordError(ctx, statsKey, err)
+		return nil, err
+	}
+	defer release()
+	end := st.begin(ctx, statsKey, filePath)
 	res, err := st.conn.Create(ctx, filePath, contents)
+	end(err)
 	if err != nil {
-		topoStatsConnErrors.Add(statsKey, int64(1))
+		st.recordError(ctx, statsKey, err)
 		return res, err
 	}
 	return res, err
@@ -90,13 +432,22 @@ func (st *StatsConn) Create(ctx context.Context, filePath string, contents []byt
 func (st *StatsConn) Update(ctx context.Context, filePath string, contents []byte, version Version) (Version, error) {
 	statsKey := []string{"Update", st.cell}
 	if st.readOnly {
-		return nil, vterrors.Errorf(vtrpc.Code_READ_ONLY, readOnlyErrorStrFormat, statsKey[0], filePath)
+		return nil, st.readOnlyError(statsKey, filePath)
 	}
-	startTime := time.Now()
-	defer topoStatsConnTimings.Record(statsKey, startTime)
+	if err := st.checkValueSize(statsKey, filePath, contents); err != nil {
+		return nil, err
+	}
+	release, err := st.acquire(ctx, st.writeLimiter, writeOperation)
+	if err != nil {
+		st.recordError(ctx, statsKey, err)
+		return nil, err
+	}
+	defer release()
+	end := st.begin(ctx, statsKey, filePath)
 	res, err := st.conn.Update(ctx, filePath, contents, version)
+	end(err)
 	if err != nil {
-		topoStatsConnErrors.Add(statsKey, int64(1))
+		st.recordError(ctx, statsKey, err)
 		return res, err
 	}
 	return res, err
@@ -104,12 +455,18 @@ func (st *StatsConn) Update(ctx context.Context, filePath string, contents []byt
 
 // Get is part of the Conn interface
 func (st *StatsConn) Get(ctx context.Context, filePath string) ([]byte, Version, error) {
-	startTime := time.Now()
 	statsKey := []string{"Get", st.cell}
-	defer topoStatsConnTimings.Record(statsKey, startTime)
+	release, err := st.acquire(ctx, st.readLimiter, readOperation)
+	if err != nil {
+		st.recordError(ctx, statsKey, err)
+		return nil, nil, err
+	}
+	defer release()
+	end := st.begin(ctx, statsKey, filePath)
 	bytes, version, err := st.conn.Get(ctx, filePath)
+	end(err)
 	if err != nil {
-		topoStatsConnErrors.Add(statsKey, int64(1))
+		st.recordError(ctx, statsKey, err)
 		return bytes, version, err
 	}
 	return bytes, version, err
@@ -117,12 +474,18 @@ func (st *StatsConn) Get(ctx context.Context, filePath string) ([]byte, Version,
 
 // GetVersion is part of the Conn interface.
 func (st *StatsConn) GetVersion(ctx context.Context, filePath string, version int64) ([]byte, error) {
-	startTime := time.Now()
 	statsKey := []string{"GetVersion", st.cell}
-	defer topoStatsConnTimings.Record(statsKey, startTime)
+	release, err := st.acquire(ctx, st.readLimiter, readOperation)
+	if err != nil {
+		st.recordError(ctx, statsKey, err)
+		return nil, err
+	}
+	defer release()
+	end := st.begin(ctx, statsKey, filePath)
 	bytes, err := st.conn.GetVersion(ctx, filePath, version)
+	end(err)
 	if err != nil {
-		topoStatsConnErrors.Add(statsKey, int64(1))
+		st.recordError(ctx, statsKey, err)
 		return bytes, err
 	}
 	return bytes, err
@@ -130,12 +493,18 @@ func (st *StatsConn) GetVersion(ctx context.Context, filePath string, version in
 
 // List is part of the Conn interface
 func (st *StatsConn) List(ctx context.Context, filePathPrefix string) ([]KVInfo, error) {
-	startTime := time.Now()
 	statsKey := []string{"List", st.cell}
-	defer topoStatsConnTimings.Record(statsKey, startTime)
+	release, err := st.acquire(ctx, st.readLimiter, readOperation)
+	if err != nil {
+		st.recordError(ctx, statsKey, err)
+		return nil, err
+	}
+	defer release()
+	end := st.begin(ctx, statsKey, filePathPrefix)
 	bytes, err := st.conn.List(ctx, filePathPrefix)
+	end(err)
 	if err != nil {
-		topoStatsConnErrors.Add(statsKey, int64(1))
+		st.recordError(ctx, statsKey, err)
 		return bytes, err
 	}
 	return bytes, err
@@ -145,13 +514,19 @@ func (st *StatsConn) List(ctx context.Context, filePathPrefix string) ([]KVInfo,
 func (st *StatsConn) Delete(ctx context.Context, filePath string, version Version) error {
 	statsKey := []string{"Delete", st.cell}
 	if st.readOnly {
-		return vterrors.Errorf(vtrpc.Code_READ_ONLY, readOnlyErrorStrFormat, statsKey[0], filePath)
+		return st.readOnlyError(statsKey, filePath)
 	}
-	startTime := time.Now()
-	defer topoStatsConnTimings.Record(statsKey, startTime)
-	err := st.conn.Delete(ctx, filePath, version)
+	release, err := st.acquire(ctx, st.writeLimiter, writeOperation)
+	if err != nil {
+		st.recordError(ctx, statsKey, err)
+		return err
+	}
+	defer release()
+	end := st.begin(ctx, statsKey, filePath)
+	err = st.conn.Delete(ctx, filePath, version)
+	end(err)
 	if err != nil {
-		topoStatsConnErrors.Add(statsKey, int64(1))
+		st.recordError(ctx, statsKey, err)
 		return err
 	}
 	return err
@@ -171,10 +546,9 @@ func (st *StatsConn) TryLock(ctx context.Context, dirPath, contents string) (Loc
 func (st *StatsConn) internalLock(ctx context.Context, dirPath, contents string, isBlocking bool) (LockDescriptor, error) {
 	statsKey := []string{"Lock", st.cell}
 	if st.readOnly {
-		return nil, vterrors.Errorf(vtrpc.Code_READ_ONLY, readOnlyErrorStrFormat, statsKey[0], dirPath)
+		return nil, st.readOnlyError(statsKey, dirPath)
 	}
-	startTime := time.Now()
-	defer topoStatsConnTimings.Record(statsKey, startTime)
+	end := st.begin(ctx, statsKey, dirPath)
 	var res LockDescriptor
 	var err error
 	if isBlocking {
@@ -182,36 +556,124 @@ func (st *StatsConn) internalLock(ctx context.Context, dirPath, contents string,
 	} else {
 		res, err = st.conn.TryLock(ctx, dirPath, contents)
 	}
+	end(err)
+	if err != nil {
+		st.recordError(ctx, statsKey, err)
+		return res, err
+	}
+	return res, err
+}
+
+// LockNameWithTTL is part of the NamedLockConn interface.
+func (st *StatsConn) LockNameWithTTL(ctx context.Context, dirPath, contents string, ttl time.Duration) (LockDescriptor, error) {
+	statsKey := []string{"LockName", st.cell}
+	if st.readOnly {
+		return nil, st.readOnlyError(statsKey, dirPath)
+	}
+	end := st.begin(ctx, statsKey, dirPath)
+	res, err := lockNameWithTTL(ctx, st.conn, dirPath, contents, ttl)
+	end(err)
+	if err != nil {
+		st.recordError(ctx, statsKey, err)
+		return res, err
+	}
+	return res, err
+}
+
+// GetLock is part of the LockHolderConn interface.
+func (st *StatsConn) GetLock(ctx context.Context, dirPath string) (*LockInfo, error) {
+	statsKey := []string{"GetLock", st.cell}
+	release, err := st.acquire(ctx, st.readLimiter, readOperation)
+	if err != nil {
+		st.recordError(ctx, statsKey, err)
+		return nil, err
+	}
+	defer release()
+	end := st.begin(ctx, statsKey, dirPath)
+	res, err := getLock(ctx, st.conn, dirPath)
+	end(err)
 	if err != nil {
-		topoStatsConnErrors.Add(statsKey, int64(1))
+		st.recordError(ctx, statsKey, err)
 		return res, err
 	}
 	return res, err
 }
 
+// ForceUnlock is part of the LockHolderConn interface.
+func (st *StatsConn) ForceUnlock(ctx context.Context, dirPath, contents string) error {
+	statsKey := []string{"ForceUnlock", st.cell}
+	if st.readOnly {
+		return st.readOnlyError(statsKey, dirPath)
+	}
+	release, err := st.acquire(ctx, st.writeLimiter, writeOperation)
+	if err != nil {
+		st.recordError(ctx, statsKey, err)
+		return err
+	}
+	defer release()
+	end := st.begin(ctx, statsKey, dirPath)
+	err = forceUnlock(ctx, st.conn, dirPath, contents)
+	end(err)
+	if err != nil {
+		st.recordError(ctx, statsKey, err)
+		return err
+	}
+	return err
+}
+
 // Watch is part of the Conn interface
 func (st *StatsConn) Watch(ctx context.Context, filePath string) (current *WatchData, changes <-chan *WatchData, err error) {
-	startTime := time.Now()
 	statsKey := []string{"Watch", st.cell}
-	defer topoStatsConnTimings.Record(statsKey, startTime)
-	return st.conn.Watch(ctx, filePath)
+	end := st.begin(ctx, statsKey, filePath)
+	current, changes, err = st.conn.Watch(ctx, filePath)
+	end(err)
+	if err != nil {
+		st.recordError(ctx, statsKey, err)
+		return current, changes, err
+	}
+	return current, forwardWatch(changes, st.trackWatch(filePath)), nil
 }
 
 func (st *StatsConn) WatchRecursive(ctx context.Context, path string) ([]*WatchDataRecursive, <-chan *WatchDataRecursive, error) {
-	startTime := time.Now()
 	statsKey := []string{"WatchRecursive", st.cell}
-	defer topoStatsConnTimings.Record(statsKey, startTime)
-	return st.conn.WatchRecursive(ctx, path)
+	end := st.begin(ctx, statsKey, path)
+	current, changes, err := st.conn.WatchRecursive(ctx, path)
+	end(err)
+	if err != nil {
+		st.recordError(ctx, statsKey, err)
+		return current, changes, err
+	}
+	return current, forwardWatch(changes, st.trackWatch(path)), nil
+}
+
+// forwardWatch forwards the changes of a watch to the returned channel, and
+// calls done once the watch ends.
+func forwardWatch[T any](changes <-chan T, done func()) <-chan T {
+	out := make(chan T, cap(changes))
+	go func() {
+		defer done()
+		defer close(out)
+		for change := range changes {
+			out <- change
+		}
+	}()
+	return out
 }
 
 // NewLeaderParticipation is part of the Conn interface
 func (st *StatsConn) NewLeaderParticipation(name, id string) (LeaderParticipation, error) {
-	startTime := time.Now()
+	return st.NewLeaderParticipationWithTTL(name, id, 0)
+}
+
+// NewLeaderParticipationWithTTL is part of the LeaderParticipationTTLConn
+// interface
+func (st *StatsConn) NewLeaderParticipationWithTTL(name, id string, ttl time.Duration) (LeaderParticipation, error) {
 	statsKey := []string{"NewLeaderParticipation", st.cell}
-	defer topoStatsConnTimings.Record(statsKey, startTime)
-	res, err := st.conn.NewLeaderParticipation(name, id)
+	end := st.begin(context.Background(), statsKey, name)
+	res, err := newLeaderParticipationWithTTL(st.conn, name, id, ttl)
+	end(err)
 	if err != nil {
-		topoStatsConnErrors.Add(statsKey, int64(1))
+		st.recordError(context.Background(), statsKey, err)
 		return res, err
 	}
 	return res, err
@@ -219,12 +681,72 @@ func (st *StatsConn) NewLeaderParticipation(name, id string) (LeaderParticipatio
 
 // Close is part of the Conn interface
 func (st *StatsConn) Close() {
-	startTime := time.Now()
 	statsKey := []string{"Close", st.cell}
-	defer topoStatsConnTimings.Record(statsKey, startTime)
+	defer st.begin(context.Background(), statsKey, "")(nil)
 	st.conn.Close()
 }
 
+// SetConcurrencyLimits limits the number of concurrent reads (ListDir, Get,
+// GetVersion, List and GetLock) and writes (Create, Update, Delete and
+// ForceUnlock) to the values reads and writes return when they start, so that
+// the limits can change at runtime. A limit of 0 leaves them unlimited. The
+// locks, watches and leader participations, which last, are not limited. It
+// must be called before the StatsConn is used.
+func (st *StatsConn) SetConcurrencyLimits(reads, writes func() int) {
+	st.readLimiter = newConcurrencyLimiter(reads)
+	st.writeLimiter = newConcurrencyLimiter(writes)
+}
+
+// SetMaxValueSize rejects the writes of values larger than maxValueSize
+// bytes with a ValueTooLarge error. A size of 0 leaves them unlimited. It
+// must be called before the StatsConn is used.
+func (st *StatsConn) SetMaxValueSize(maxValueSize int) {
+	st.maxValueSize = maxValueSize
+}
+
+// SetClientIdentifier records the identity of the client certificate conn
+// presents to the topology server, exporting it in the
+// TopologyClientIdentity stat, and records it again whenever it may have
+// changed, e.g. when the certificate is reloaded. conn is usually the Conn
+// the StatsConn wraps, or one it wraps in turn. It must be called before the
+// StatsConn is used.
+func (st *StatsConn) SetClientIdentifier(conn Conn) {
+	identifier, ok := conn.(ClientIdentifier)
+	if !ok {
+		return
+	}
+	st.identifier = identifier
+	st.recordClientIdentity()
+	onClientIdentityChange(conn, st.recordClientIdentity)
+}
+
+// recordClientIdentity records the identity the identifier presents, moving
+// the TopologyClientIdentity stat over to it if it changed.
+func (st *StatsConn) recordClientIdentity() {
+	identity := st.identifier.ClientIdentity()
+
+	st.identityMu.Lock()
+	defer st.identityMu.Unlock()
+	if identity == st.clientIdentity {
+		return
+	}
+	if st.clientIdentity != "" {
+		topoClientIdentity.Reset([]string{st.cell, st.clientIdentity})
+	}
+	st.clientIdentity = identity
+	if identity != "" {
+		topoClientIdentity.Set([]string{st.cell, identity}, 1)
+	}
+}
+
+// ClientIdentity returns the identity the Conn presents to the topology
+// server, or "" if it is not known.
+func (st *StatsConn) ClientIdentity() string {
+	st.identityMu.Lock()
+	defer st.identityMu.Unlock()
+	return st.clientIdentity
+}
+
 // SetReadOnly with true prevents any write operations from being made on the topo connection
 func (st *StatsConn) SetReadOnly(readOnly bool) {
 	st.readOnly = readOnly
@@ -234,3 +756,27 @@ func (st *StatsConn) SetReadOnly(readOnly bool) {
 func (st *StatsConn) IsReadOnly() bool {
 	return st.readOnly
 }
+
+// InFlight returns the number of operations in progress.
+func (st *StatsConn) InFlight() int64 {
+	return st.inFlight.Load()
+}
+
+// ActiveWatches returns the number of active watches per path.
+func (st *StatsConn) ActiveWatches() map[string]int {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	watches := make(map[string]int, len(st.watches))
+	for path, count := range st.watches {
+		watches[path] = count
+	}
+	return watches
+}
+
+// RecentErrors returns the last unexpected errors of the operations, oldest
+// first.
+func (st *StatsConn) RecentErrors() []ConnError {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	return append([]ConnError(nil), st.recentErrors...)
+}
@@ -19,8 +19,14 @@ package topo
 import (
 	"context"
 	"fmt"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/stats"
 	"vitess.io/vitess/go/vt/proto/vtrpc"
 	"vitess.io/vitess/go/vt/vterrors"
 )
@@ -29,6 +35,9 @@ import (
 type fakeConn struct {
 	v        Version
 	readOnly bool
+	// blocked, if set, blocks the reads of the "blocked" path until it is
+	// closed.
+	blocked chan struct{}
 }
 
 // ListDir is part of the Conn interface
@@ -70,6 +79,12 @@ func (st *fakeConn) Get(ctx context.Context, filePath string) (bytes []byte, ver
 		return bytes, ver, fmt.Errorf("Dummy error")
 
 	}
+	if filePath == "nonode" {
+		return bytes, ver, NewError(NoNode, filePath)
+	}
+	if filePath == "blocked" {
+		<-st.blocked
+	}
 	return bytes, ver, err
 }
 
@@ -126,6 +141,36 @@ func (st *fakeConn) TryLock(ctx context.Context, dirPath, contents string) (lock
 	return lock, err
 }
 
+// LockNameWithTTL is part of the Conn interface
+func (st *fakeConn) LockNameWithTTL(ctx context.Context, dirPath, contents string, ttl time.Duration) (lock LockDescriptor, err error) {
+	if st.readOnly {
+		return nil, vterrors.Errorf(vtrpc.Code_READ_ONLY, "topo server connection is read-only")
+	}
+	if dirPath == "error" {
+		return lock, fmt.Errorf("dummy error")
+	}
+	return lock, err
+}
+
+// GetLock is part of the LockHolderConn interface
+func (st *fakeConn) GetLock(ctx context.Context, dirPath string) (*LockInfo, error) {
+	if dirPath == "error" {
+		return nil, fmt.Errorf("dummy error")
+	}
+	return nil, NewError(NoNode, dirPath)
+}
+
+// ForceUnlock is part of the LockHolderConn interface
+func (st *fakeConn) ForceUnlock(ctx context.Context, dirPath, contents string) error {
+	if st.readOnly {
+		return vterrors.Errorf(vtrpc.Code_READ_ONLY, "topo server connection is read-only")
+	}
+	if dirPath == "error" {
+		return fmt.Errorf("dummy error")
+	}
+	return NewError(NoNode, dirPath)
+}
+
 // Watch is part of the Conn interface
 func (st *fakeConn) Watch(ctx context.Context, filePath string) (current *WatchData, changes <-chan *WatchData, err error) {
 	return current, changes, err
@@ -165,25 +210,21 @@ func TestStatsConnTopoListDir(t *testing.T) {
 	statsConn := NewStatsConn("global", conn)
 	ctx := context.Background()
 
+	snapshot := stats.TakeSnapshot()
 	statsConn.ListDir(ctx, "", true)
-	timingCounts := topoStatsConnTimings.Counts()["ListDir.global"]
-	if got, want := timingCounts, int64(1); got != want {
-		t.Errorf("stats were not properly recorded: got = %d, want = %d", got, want)
-	}
-
-	// error is zero before getting an error
-	errorCount := topoStatsConnErrors.Counts()["ListDir.global"]
-	if got, want := errorCount, int64(0); got != want {
-		t.Errorf("stats were not properly recorded: got = %d, want = %d", got, want)
-	}
+	// exactly one timing and no error are recorded
+	assert.Equal(t, stats.Snapshot{
+		"TopologyConnOperations": {"ListDir.global": 1, "All": 1},
+	}, snapshot.Diff())
 
+	snapshot = stats.TakeSnapshot()
 	statsConn.ListDir(ctx, "error", true)
 
 	// error stats gets emitted
-	errorCount = topoStatsConnErrors.Counts()["ListDir.global"]
-	if got, want := errorCount, int64(1); got != want {
-		t.Errorf("stats were not properly recorded: got = %d, want = %d", got, want)
-	}
+	assert.Equal(t, stats.Snapshot{
+		"TopologyConnOperations": {"ListDir.global": 1, "All": 1},
+		"TopologyConnErrors":     {"ListDir.global.UNKNOWN": 1},
+	}, snapshot.Diff())
 }
 
 // TestStatsConnTopoCreate emits stats on Create
@@ -192,25 +233,23 @@ func TestStatsConnTopoCreate(t *testing.T) {
 	statsConn := NewStatsConn("global", conn)
 	ctx := context.Background()
 
+	snapshot := stats.TakeSnapshot()
 	statsConn.Create(ctx, "", []byte{})
-	timingCounts := topoStatsConnTimings.Counts()["Create.global"]
-	if got, want := timingCounts, int64(1); got != want {
-		t.Errorf("stats were not properly recorded: got = %d, want = %d", got, want)
-	}
-
-	// error is zero before getting an error
-	errorCount := topoStatsConnErrors.Counts()["Create.global"]
-	if got, want := errorCount, int64(0); got != want {
-		t.Errorf("stats were not properly recorded: got = %d, want = %d", got, want)
-	}
+	// exactly one timing and no error are recorded
+	assert.Equal(t, stats.Snapshot{
+		"TopologyConnOperations": {"Create.global": 1, "All": 1},
+		"TopologyConnValueSizes": {"global..1024": 1},
+	}, snapshot.Diff())
 
+	snapshot = stats.TakeSnapshot()
 	statsConn.Create(ctx, "error", []byte{})
 
 	// error stats gets emitted
-	errorCount = topoStatsConnErrors.Counts()["Create.global"]
-	if got, want := errorCount, int64(1); got != want {
-		t.Errorf("stats were not properly recorded: got = %d, want = %d", got, want)
-	}
+	assert.Equal(t, stats.Snapshot{
+		"TopologyConnOperations": {"Create.global": 1, "All": 1},
+		"TopologyConnErrors":     {"Create.global.UNKNOWN": 1},
+		"TopologyConnValueSizes": {"global.error.1024": 1},
+	}, snapshot.Diff())
 }
 
 // TestStatsConnTopoUpdate emits stats on Update
@@ -219,25 +258,23 @@ func TestStatsConnTopoUpdate(t *testing.T) {
 	statsConn := NewStatsConn("global", conn)
 	ctx := context.Background()
 
+	snapshot := stats.TakeSnapshot()
 	statsConn.Update(ctx, "", []byte{}, conn.v)
-	timingCounts := topoStatsConnTimings.Counts()["Update.global"]
-	if got, want := timingCounts, int64(1); got != want {
-		t.Errorf("stats were not properly recorded: got = %d, want = %d", got, want)
-	}
-
-	// error is zero before getting an error
-	errorCount := topoStatsConnErrors.Counts()["Update.global"]
-	if got, want := errorCount, int64(0); got != want {
-		t.Errorf("stats were not properly recorded: got = %d, want = %d", got, want)
-	}
+	// exactly one timing and no error are recorded
+	assert.Equal(t, stats.Snapshot{
+		"TopologyConnOperations": {"Update.global": 1, "All": 1},
+		"TopologyConnValueSizes": {"global..1024": 1},
+	}, snapshot.Diff())
 
+	snapshot = stats.TakeSnapshot()
 	statsConn.Update(ctx, "error", []byte{}, conn.v)
 
 	// error stats gets emitted
-	errorCount = topoStatsConnErrors.Counts()["Update.global"]
-	if got, want := errorCount, int64(1); got != want {
-		t.Errorf("stats were not properly recorded: got = %d, want = %d", got, want)
-	}
+	assert.Equal(t, stats.Snapshot{
+		"TopologyConnOperations": {"Update.global": 1, "All": 1},
+		"TopologyConnErrors":     {"Update.global.UNKNOWN": 1},
+		"TopologyConnValueSizes": {"global.error.1024": 1},
+	}, snapshot.Diff())
 }
 
 // TestStatsConnTopoGet emits stats on Get
@@ -246,52 +283,175 @@ func TestStatsConnTopoGet(t *testing.T) {
 	statsConn := NewStatsConn("global", conn)
 	ctx := context.Background()
 
+	snapshot := stats.TakeSnapshot()
 	statsConn.Get(ctx, "")
-	timingCounts := topoStatsConnTimings.Counts()["Get.global"]
-	if got, want := timingCounts, int64(1); got != want {
-		t.Errorf("stats were not properly recorded: got = %d, want = %d", got, want)
-	}
-
-	// error is zero before getting an error
-	errorCount := topoStatsConnErrors.Counts()["Get.global"]
-	if got, want := errorCount, int64(0); got != want {
-		t.Errorf("stats were not properly recorded: got = %d, want = %d", got, want)
-	}
+	// exactly one timing and no error are recorded
+	assert.Equal(t, stats.Snapshot{
+		"TopologyConnOperations": {"Get.global": 1, "All": 1},
+	}, snapshot.Diff())
 
+	snapshot = stats.TakeSnapshot()
 	statsConn.Get(ctx, "error")
 
 	// error stats gets emitted
-	errorCount = topoStatsConnErrors.Counts()["Get.global"]
-	if got, want := errorCount, int64(1); got != want {
-		t.Errorf("stats were not properly recorded: got = %d, want = %d", got, want)
+	assert.Equal(t, stats.Snapshot{
+		"TopologyConnOperations": {"Get.global": 1, "All": 1},
+		"TopologyConnErrors":     {"Get.global.UNKNOWN": 1},
+	}, snapshot.Diff())
+}
+
+// TestStatsConnTopoErrorCodes labels the errors with their vtrpc code
+func TestStatsConnTopoErrorCodes(t *testing.T) {
+	conn := &fakeConn{}
+	statsConn := NewStatsConn("global", conn)
+	ctx := context.Background()
+
+	snapshot := stats.TakeSnapshot()
+	statsConn.Get(ctx, "nonode")
+	statsConn.SetReadOnly(true)
+	statsConn.Create(ctx, "", []byte{})
+	assert.Equal(t, map[string]int64{
+		"Get.global.NOT_FOUND":    1,
+		"Create.global.READ_ONLY": 1,
+	}, snapshot.Diff()["TopologyConnErrors"])
+
+	// Only the unexpected errors are kept as recent errors.
+	recentErrors := statsConn.RecentErrors()
+	if assert.Len(t, recentErrors, 1) {
+		assert.Equal(t, "Create", recentErrors[0].Operation)
 	}
 }
 
+// TestStatsConnTopoValueSizes counts the sizes of the values written, and
+// rejects the values above the limit
+func TestStatsConnTopoValueSizes(t *testing.T) {
+	conn := &fakeConn{}
+	statsConn := NewStatsConn("global", conn)
+	statsConn.SetMaxValueSize(100 << 10)
+	ctx := context.Background()
+
+	snapshot := stats.TakeSnapshot()
+	_, err := statsConn.Create(ctx, "/keyspaces/ks/VSchema", make([]byte, 10<<10))
+	require.NoError(t, err)
+	_, err = statsConn.Update(ctx, "tablet_leases/zone1-0000000100", make([]byte, 10), nil)
+	require.NoError(t, err)
+	_, err = statsConn.Update(ctx, "keyspaces/ks/VSchema", make([]byte, 50<<20), nil)
+	assert.True(t, IsErrType(err, ValueTooLarge), "%v", err)
+	diff := snapshot.Diff()
+	assert.Equal(t, map[string]int64{
+		"global.VSchema.16384":      1,
+		"global.VSchema.inf":        1,
+		"global.tablet_leases.1024": 1,
+	}, diff["TopologyConnValueSizes"])
+	assert.Equal(t, map[string]int64{
+		"global.VSchema":       10<<10 + 50<<20,
+		"global.tablet_leases": 10,
+	}, diff["TopologyConnValueBytes"])
+	assert.Equal(t, map[string]int64{"Update.global.INVALID_ARGUMENT": 1}, diff["TopologyConnErrors"])
+}
+
+// TestStatsConnTopoCanceled counts the errors of the operations given up by
+// their caller apart
+func TestStatsConnTopoCanceled(t *testing.T) {
+	conn := &fakeConn{}
+	statsConn := NewStatsConn("global", conn)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	snapshot := stats.TakeSnapshot()
+	statsConn.Get(ctx, "error")
+	diff := snapshot.Diff()
+	assert.Zero(t, diff["TopologyConnErrors"])
+	assert.Equal(t, map[string]int64{"Get.global": 1}, diff["TopologyConnCanceled"])
+	assert.Empty(t, statsConn.RecentErrors())
+}
+
+// TestStatsConnTopoConcurrencyLimits holds back the operations beyond the
+// concurrency limits
+func TestStatsConnTopoConcurrencyLimits(t *testing.T) {
+	conn := &fakeConn{blocked: make(chan struct{})}
+	statsConn := NewStatsConn("global", conn)
+	var readLimit atomic.Int64
+	readLimit.Store(1)
+	statsConn.SetConcurrencyLimits(func() int { return int(readLimit.Load()) }, func() int { return 1 })
+	ctx := context.Background()
+
+	done := make(chan error)
+	go func() {
+		_, _, err := statsConn.Get(ctx, "blocked")
+		done <- err
+	}()
+	assert.Eventually(t, func() bool {
+		return statsConn.InFlight() == 1
+	}, 10*time.Second, time.Millisecond)
+
+	// The reads wait for the blocked one, not the writes.
+	snapshot := stats.TakeSnapshot()
+	shortCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	_, _, err := statsConn.Get(shortCtx, "")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	_, err = statsConn.Create(ctx, "", []byte{})
+	assert.NoError(t, err)
+	diff := snapshot.Diff()
+	assert.Equal(t, map[string]int64{"Get.global.DEADLINE_EXCEEDED": 1}, diff["TopologyConnErrors"])
+	assert.Zero(t, diff["TopologyConnCanceled"])
+	assert.Equal(t, map[string]int64{"global.Read": 1, "All": 1}, diff["TopologyConnConcurrencyWaits"])
+
+	// The limits can change at runtime.
+	readLimit.Store(2)
+	_, _, err = statsConn.Get(ctx, "")
+	assert.NoError(t, err)
+
+	close(conn.blocked)
+	assert.NoError(t, <-done)
+	_, _, err = statsConn.Get(ctx, "")
+	assert.NoError(t, err)
+}
+
+// TestStatsConnTopoSlowOperations counts the operations slower than the
+// threshold
+func TestStatsConnTopoSlowOperations(t *testing.T) {
+	conn := &fakeConn{}
+	statsConn := NewStatsConn("global", conn)
+	ctx := context.Background()
+
+	snapshot := stats.TakeSnapshot()
+	statsConn.Get(ctx, "error")
+	assert.Zero(t, snapshot.Diff()["TopologyConnSlowOperations"])
+
+	defer func(threshold time.Duration) {
+		topoSlowOperationThreshold = threshold
+	}(topoSlowOperationThreshold)
+	topoSlowOperationThreshold = time.Nanosecond
+
+	snapshot = stats.TakeSnapshot()
+	statsConn.Get(ctx, "error")
+	statsConn.Close()
+	assert.Equal(t, map[string]int64{"Get.global": 1, "Close.global": 1}, snapshot.Diff()["TopologyConnSlowOperations"])
+}
+
 // TestStatsConnTopoDelete emits stats on Delete
 func TestStatsConnTopoDelete(t *testing.T) {
 	conn := &fakeConn{}
 	statsConn := NewStatsConn("global", conn)
 	ctx := context.Background()
 
+	snapshot := stats.TakeSnapshot()
 	statsConn.Delete(ctx, "", conn.v)
-	timingCounts := topoStatsConnTimings.Counts()["Delete.global"]
-	if got, want := timingCounts, int64(1); got != want {
-		t.Errorf("stats were not properly recorded: got = %d, want = %d", got, want)
-	}
-
-	// error is zero before getting an error
-	errorCount := topoStatsConnErrors.Counts()["Delete.global"]
-	if got, want := errorCount, int64(0); got != want {
-		t.Errorf("stats were not properly recorded: got = %d, want = %d", got, want)
-	}
+	// exactly one timing and no error are recorded
+	assert.Equal(t, stats.Snapshot{
+		"TopologyConnOperations": {"Delete.global": 1, "All": 1},
+	}, snapshot.Diff())
 
+	snapshot = stats.TakeSnapshot()
 	statsConn.Delete(ctx, "error", conn.v)
 
 	// error stats gets emitted
-	errorCount = topoStatsConnErrors.Counts()["Delete.global"]
-	if got, want := errorCount, int64(1); got != want {
-		t.Errorf("stats were not properly recorded: got = %d, want = %d", got, want)
-	}
+	assert.Equal(t, stats.Snapshot{
+		"TopologyConnOperations": {"Delete.global": 1, "All": 1},
+		"TopologyConnErrors":     {"Delete.global.UNKNOWN": 1},
+	}, snapshot.Diff())
 }
 
 // TestStatsConnTopoLock emits stats on Lock
@@ -300,25 +460,28 @@ func TestStatsConnTopoLock(t *testing.T) {
 	statsConn := NewStatsConn("global", conn)
 	ctx := context.Background()
 
+	snapshot := stats.TakeSnapshot()
 	statsConn.Lock(ctx, "", "")
-	timingCounts := topoStatsConnTimings.Counts()["Lock.global"]
-	if got, want := timingCounts, int64(1); got != want {
-		t.Errorf("stats were not properly recorded: got = %d, want = %d", got, want)
-	}
-
-	// error is zero before getting an error
-	errorCount := topoStatsConnErrors.Counts()["Lock.global"]
-	if got, want := errorCount, int64(0); got != want {
-		t.Errorf("stats were not properly recorded: got = %d, want = %d", got, want)
-	}
+	// exactly one timing and no error are recorded
+	assert.Equal(t, stats.Snapshot{
+		"TopologyConnOperations": {"Lock.global": 1, "All": 1},
+	}, snapshot.Diff())
 
+	snapshot = stats.TakeSnapshot()
 	statsConn.Lock(ctx, "error", "")
 
 	// error stats gets emitted
-	errorCount = topoStatsConnErrors.Counts()["Lock.global"]
-	if got, want := errorCount, int64(1); got != want {
-		t.Errorf("stats were not properly recorded: got = %d, want = %d", got, want)
-	}
+	assert.Equal(t, stats.Snapshot{
+		"TopologyConnOperations": {"Lock.global": 1, "All": 1},
+		"TopologyConnErrors":     {"Lock.global.UNKNOWN": 1},
+	}, snapshot.Diff())
+
+	snapshot = stats.TakeSnapshot()
+	statsConn.LockNameWithTTL(ctx, "error", "", time.Minute)
+	assert.Equal(t, stats.Snapshot{
+		"TopologyConnOperations": {"LockName.global": 1, "All": 1},
+		"TopologyConnErrors":     {"LockName.global.UNKNOWN": 1},
+	}, snapshot.Diff())
 }
 
 // TestStatsConnTopoWatch emits stats on Watch
@@ -327,12 +490,11 @@ func TestStatsConnTopoWatch(t *testing.T) {
 	statsConn := NewStatsConn("global", conn)
 	ctx := context.Background()
 
+	snapshot := stats.TakeSnapshot()
 	statsConn.Watch(ctx, "")
-	timingCounts := topoStatsConnTimings.Counts()["Watch.global"]
-	if got, want := timingCounts, int64(1); got != want {
-		t.Errorf("stats were not properly recorded: got = %d, want = %d", got, want)
-	}
-
+	assert.Equal(t, stats.Snapshot{
+		"TopologyConnOperations": {"Watch.global": 1, "All": 1},
+	}, snapshot.Diff())
 }
 
 // TestStatsConnTopoNewLeaderParticipation emits stats on NewLeaderParticipation
@@ -340,25 +502,21 @@ func TestStatsConnTopoNewLeaderParticipation(t *testing.T) {
 	conn := &fakeConn{}
 	statsConn := NewStatsConn("global", conn)
 
+	snapshot := stats.TakeSnapshot()
 	_, _ = statsConn.NewLeaderParticipation("", "")
-	timingCounts := topoStatsConnTimings.Counts()["NewLeaderParticipation.global"]
-	if got, want := timingCounts, int64(1); got != want {
-		t.Errorf("stats were not properly recorded: got = %d, want = %d", got, want)
-	}
-
-	// error is zero before getting an error
-	errorCount := topoStatsConnErrors.Counts()["NewLeaderParticipation.global"]
-	if got, want := errorCount, int64(0); got != want {
-		t.Errorf("stats were not properly recorded: got = %d, want = %d", got, want)
-	}
+	// exactly one timing and no error are recorded
+	assert.Equal(t, stats.Snapshot{
+		"TopologyConnOperations": {"NewLeaderParticipation.global": 1, "All": 1},
+	}, snapshot.Diff())
 
+	snapshot = stats.TakeSnapshot()
 	_, _ = statsConn.NewLeaderParticipation("error", "")
 
 	// error stats gets emitted
-	errorCount = topoStatsConnErrors.Counts()["NewLeaderParticipation.global"]
-	if got, want := errorCount, int64(1); got != want {
-		t.Errorf("stats were not properly recorded: got = %d, want = %d", got, want)
-	}
+	assert.Equal(t, stats.Snapshot{
+		"TopologyConnOperations": {"NewLeaderParticipation.global": 1, "All": 1},
+		"TopologyConnErrors":     {"NewLeaderParticipation.global.UNKNOWN": 1},
+	}, snapshot.Diff())
 }
 
 // TestStatsConnTopoClose emits stats on Close
@@ -366,9 +524,28 @@ func TestStatsConnTopoClose(t *testing.T) {
 	conn := &fakeConn{}
 	statsConn := NewStatsConn("global", conn)
 
+	snapshot := stats.TakeSnapshot()
 	statsConn.Close()
-	timingCounts := topoStatsConnTimings.Counts()["Close.global"]
-	if got, want := timingCounts, int64(1); got != want {
-		t.Errorf("stats were not properly recorded: got = %d, want = %d", got, want)
-	}
+	assert.Equal(t, stats.Snapshot{
+		"TopologyConnOperations": {"Close.global": 1, "All": 1},
+	}, snapshot.Diff())
+}
+
+// TestStatsConnTopoPathStats emits the per-path stats while they are enabled
+func TestStatsConnTopoPathStats(t *testing.T) {
+	conn := &fakeConn{}
+	statsConn := NewStatsConn("global", conn)
+	ctx := context.Background()
+
+	snapshot := stats.TakeSnapshot()
+	statsConn.Get(ctx, "/keyspaces/ks/Keyspace")
+	assert.Zero(t, snapshot.Diff()["TopologyConnPathOperations"])
+
+	stats.SetEnabledGroups([]string{"topo_paths"})
+	defer stats.SetEnabledGroups(nil)
+
+	snapshot = stats.TakeSnapshot()
+	statsConn.Get(ctx, "/keyspaces/ks/Keyspace")
+	statsConn.Close()
+	assert.Equal(t, map[string]int64{"Get.global./keyspaces/ks/Keyspace": 1, "All": 1}, snapshot.Diff()["TopologyConnPathOperations"])
 }
@@ -0,0 +1,185 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topo
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"github.com/google/safehtml/template"
+
+	"vitess.io/vitess/go/acl"
+	"vitess.io/vitess/go/vt/servenv"
+)
+
+// ConnStatus is the state of the connection of a Server to the topo service
+// of a cell, as shown on the /debug/topo page.
+type ConnStatus struct {
+	Cell           string
+	Implementation string
+	ServerAddress  string
+	Root           string
+	ReadOnly       bool
+	// ClientIdentity is the identity of the client certificate presented
+	// to the topology server, if known.
+	ClientIdentity string
+
+	// InFlight is the number of operations in progress.
+	InFlight int64
+	// Watches are the active watches, sorted by path.
+	Watches []WatchStatus
+	// RecentErrors are the last unexpected errors, oldest first.
+	RecentErrors []ConnError
+}
+
+// WatchStatus is the number of active watches of a path.
+type WatchStatus struct {
+	Path  string
+	Count int
+}
+
+// ConnStatuses returns the state of the connections of the Server: the
+// global cell, its read-only connection if it has one, then the cells it
+// connected to, by name.
+func (ts *Server) ConnStatuses() []*ConnStatus {
+	implementation := ts.implementation
+	if implementation == "" {
+		implementation = fmt.Sprintf("%T", ts.factory)
+	}
+
+	statuses := []*ConnStatus{newConnStatus(GlobalCell, implementation, ts.globalServerAddress, ts.globalRoot, ts.globalCell)}
+	if ts.globalReadOnlyCell != ts.globalCell {
+		statuses = append(statuses, newConnStatus(GlobalReadOnlyCell, implementation, ts.globalServerAddress, ts.globalRoot, ts.globalReadOnlyCell))
+	}
+
+	ts.mu.Lock()
+	cellStatuses := make([]*ConnStatus, 0, len(ts.cellConns))
+	for cell, cc := range ts.cellConns {
+		cellStatuses = append(cellStatuses, newConnStatus(cell, implementation, cc.cellInfo.ServerAddress, cc.cellInfo.Root, cc.conn))
+	}
+	ts.mu.Unlock()
+	sort.Slice(cellStatuses, func(i, j int) bool {
+		return cellStatuses[i].Cell < cellStatuses[j].Cell
+	})
+	return append(statuses, cellStatuses...)
+}
+
+func newConnStatus(cell, implementation, serverAddress, root string, conn Conn) *ConnStatus {
+	status := &ConnStatus{
+		Cell:           cell,
+		Implementation: implementation,
+		ServerAddress:  serverAddress,
+		Root:           root,
+	}
+	st, ok := conn.(*StatsConn)
+	if !ok {
+		return status
+	}
+	status.ReadOnly = st.IsReadOnly()
+	status.ClientIdentity = st.ClientIdentity()
+	status.InFlight = st.InFlight()
+	for path, count := range st.ActiveWatches() {
+		status.Watches = append(status.Watches, WatchStatus{Path: path, Count: count})
+	}
+	sort.Slice(status.Watches, func(i, j int) bool {
+		return status.Watches[i].Path < status.Watches[j].Path
+	})
+	status.RecentErrors = st.RecentErrors()
+	return status
+}
+
+// debugTopoTemplate renders the ConnStatuses of a Server.
+var debugTopoTemplate = template.Must(template.New("debugtopo").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<title>Topo Connections</title>
+<style>
+  table {
+    border-collapse: collapse;
+  }
+  td, th {
+    border: 1px solid #999;
+    padding: 0.2rem;
+    vertical-align: top;
+  }
+</style>
+</head>
+<body>
+<h1>Topo Connections</h1>
+<table>
+  <tr>
+    <th>Cell</th>
+    <th>Implementation</th>
+    <th>Server Address</th>
+    <th>Root</th>
+    <th>Client Identity</th>
+    <th>Read-Only</th>
+    <th>Operations In Flight</th>
+    <th>Active Watches</th>
+    <th>Recent Errors</th>
+  </tr>
+  {{range .}}
+  <tr>
+    <td>{{.Cell}}</td>
+    <td>{{.Implementation}}</td>
+    <td>{{.ServerAddress}}</td>
+    <td>{{.Root}}</td>
+    <td>{{.ClientIdentity}}</td>
+    <td>{{.ReadOnly}}</td>
+    <td>{{.InFlight}}</td>
+    <td>{{range .Watches}}{{.Path}}{{if gt .Count 1}} (x{{.Count}}){{end}}<br>{{end}}</td>
+    <td>{{range .RecentErrors}}{{.Time.Format "2006-01-02 15:04:05"}} {{.Operation}}: {{.Error}}<br>{{end}}</td>
+  </tr>
+  {{end}}
+</table>
+</body>
+</html>
+`))
+
+// ServeDebugTopo serves the /debug/topo page, which shows the state of the
+// connections of the Server.
+func (ts *Server) ServeDebugTopo(w http.ResponseWriter, r *http.Request) {
+	if err := acl.CheckAccessHTTP(r, acl.DEBUGGING); err != nil {
+		acl.SendError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := debugTopoTemplate.Execute(w, ts.ConnStatuses()); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+var (
+	registerDebugTopoOnce sync.Once
+	// debugTopoServer is the Server shown on the /debug/topo page: the last
+	// one returned by Open.
+	debugTopoServer atomic.Pointer[Server]
+)
+
+// registerDebugTopoHandler shows ts on the /debug/topo page, registering it
+// on first use.
+func registerDebugTopoHandler(ts *Server) {
+	debugTopoServer.Store(ts)
+	registerDebugTopoOnce.Do(func() {
+		servenv.HTTPHandleFunc("/debug/topo", func(w http.ResponseWriter, r *http.Request) {
+			debugTopoServer.Load().ServeDebugTopo(w, r)
+		})
+	})
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topo_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+func TestConnStatuses(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts, factory := memorytopo.NewServerAndFactory(ctx, "zone1", "zone2")
+	defer ts.Close()
+
+	require.NoError(t, ts.CreateKeyspace(ctx, "ks", &topodatapb.Keyspace{}))
+	conn, err := ts.ConnForCell(ctx, topo.GlobalCell)
+	require.NoError(t, err)
+	watchCtx, watchCancel := context.WithCancel(ctx)
+	_, changes, err := conn.Watch(watchCtx, "keyspaces/ks/Keyspace")
+	require.NoError(t, err)
+	_, err = ts.ConnForCell(ctx, "zone2")
+	require.NoError(t, err)
+
+	// Missing nodes are not recent errors.
+	_, err = ts.GetKeyspace(ctx, "missing")
+	require.True(t, topo.IsErrType(err, topo.NoNode))
+	factory.SetError(errors.New("connection refused"))
+	_, err = ts.GetKeyspace(ctx, "ks")
+	require.Error(t, err)
+	factory.SetError(nil)
+
+	statuses := ts.ConnStatuses()
+	require.Len(t, statuses, 2)
+	assert.Equal(t, topo.GlobalCell, statuses[0].Cell)
+	assert.Equal(t, "*memorytopo.Factory", statuses[0].Implementation)
+	assert.False(t, statuses[0].ReadOnly)
+	assert.Equal(t, []topo.WatchStatus{{Path: "keyspaces/ks/Keyspace", Count: 1}}, statuses[0].Watches)
+	require.Len(t, statuses[0].RecentErrors, 1)
+	assert.Equal(t, "Get", statuses[0].RecentErrors[0].Operation)
+	assert.Contains(t, statuses[0].RecentErrors[0].Error, "connection refused")
+	assert.Equal(t, "zone2", statuses[1].Cell)
+	assert.Empty(t, statuses[1].Watches)
+
+	w := httptest.NewRecorder()
+	ts.ServeDebugTopo(w, httptest.NewRequest(http.MethodGet, "/debug/topo", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "keyspaces/ks/Keyspace")
+	assert.Contains(t, w.Body.String(), "connection refused")
+
+	// The watch is no longer active once it ends.
+	watchCancel()
+	for range changes {
+	}
+	statuses = ts.ConnStatuses()
+	assert.Empty(t, statuses[0].Watches)
+}
@@ -19,6 +19,7 @@ package topo
 import (
 	"context"
 	"fmt"
+	"maps"
 	"path"
 	"sort"
 	"sync"
@@ -115,6 +116,10 @@ func NewTablet(uid uint32, cell, host string) *topodatapb.Tablet {
 type TabletInfo struct {
 	version Version // node version - used to prevent stomping concurrent writes
 	*topodatapb.Tablet
+
+	// storedTags are the tags of the record at version, for UpdateTablet to
+	// update the tablet tag index.
+	storedTags map[string]string
 }
 
 // String returns a string describing the tablet.
@@ -168,7 +173,7 @@ func (ti *TabletInfo) GetPrimaryTermStartTime() time.Time {
 // version set. This function should be only used by Server
 // implementations.
 func NewTabletInfo(tablet *topodatapb.Tablet, version Version) *TabletInfo {
-	return &TabletInfo{version: version, Tablet: tablet}
+	return &TabletInfo{version: version, Tablet: tablet, storedTags: maps.Clone(tablet.Tags)}
 }
 
 // GetTablet is a high level function to read tablet data.
@@ -195,10 +200,7 @@ func (ts *Server) GetTablet(ctx context.Context, alias *topodatapb.TabletAlias)
 		return nil, err
 	}
 
-	return &TabletInfo{
-		version: version,
-		Tablet:  tablet,
-	}, nil
+	return NewTabletInfo(tablet, version), nil
 }
 
 // GetTabletAliasesByCell returns all the tablet aliases in a cell.
@@ -269,7 +271,7 @@ func (ts *Server) GetTabletsByCell(ctx context.Context, cellAlias string, opt *G
 		if err := tablet.UnmarshalVT(listResults[n].Value); err != nil {
 			return nil, err
 		}
-		tablets[n] = &TabletInfo{Tablet: tablet, version: listResults[n].Version}
+		tablets[n] = NewTabletInfo(tablet, listResults[n].Version)
 	}
 
 	return tablets, nil
@@ -312,6 +314,146 @@ func (ts *Server) GetTabletsIndividuallyByCell(ctx context.Context, cell string,
 	return tablets, partialResultErr
 }
 
+// DefaultTabletsPageSize is the number of tablets in a page of
+// GetTabletsByCellPage and StreamTabletsByCell when the options do not set
+// one.
+const DefaultTabletsPageSize = 1000
+
+// GetTabletsByCellPageOptions controls the behavior of
+// Server.GetTabletsByCellPage and Server.StreamTabletsByCell.
+type GetTabletsByCellPageOptions struct {
+	// Concurrency controls the maximum number of concurrent calls to GetTablet.
+	Concurrency int
+	// PageSize is the maximum number of tablets in a page. It defaults to
+	// DefaultTabletsPageSize.
+	PageSize int
+	// PageToken is the NextPageToken of the previous page, to resume listing
+	// the tablets after it. It is empty for the first page.
+	PageToken string
+}
+
+// TabletsPage is a page of the tablets of a cell, sorted by alias.
+type TabletsPage struct {
+	Tablets []*TabletInfo
+	// NextPageToken is the PageToken to get the next page with. It is empty
+	// on the last page.
+	NextPageToken string
+}
+
+// GetTabletsByCellPage returns a page of the tablets in the cell, sorted by
+// alias, so the tablets of a large cell can be read a bounded number at a
+// time.
+// It returns ErrNoNode if the cell doesn't exist.
+// It returns ErrPartialResult if some tablets of the page couldn't be read.
+// The results in the page are incomplete.
+func (ts *Server) GetTabletsByCellPage(ctx context.Context, cell string, opt *GetTabletsByCellPageOptions) (*TabletsPage, error) {
+	aliases, start, err := ts.getTabletAliasesFromPageToken(ctx, cell, opt)
+	if err != nil {
+		return nil, err
+	}
+	return ts.getTabletsPage(ctx, aliases, start, opt)
+}
+
+// StreamTabletsByCell reads the tablets in the cell page by page, sorted by
+// alias, and calls send with each page, so callers can process the tablets
+// of a large cell incrementally instead of holding all of them at once. It
+// stops at the first error returned by send.
+// It returns ErrNoNode if the cell doesn't exist.
+// It returns ErrPartialResult if some tablets couldn't be read, after having
+// sent all the pages. The results in the pages are incomplete.
+func (ts *Server) StreamTabletsByCell(ctx context.Context, cell string, opt *GetTabletsByCellPageOptions, send func(tablets []*TabletInfo) error) error {
+	// The aliases are listed once for all the pages, rather than once per
+	// page like a caller of GetTabletsByCellPage would have to.
+	aliases, start, err := ts.getTabletAliasesFromPageToken(ctx, cell, opt)
+	if err != nil {
+		return err
+	}
+
+	var partialResultErr error
+	for {
+		page, err := ts.getTabletsPage(ctx, aliases, start, opt)
+		if err != nil {
+			if !IsErrType(err, PartialResult) {
+				return err
+			}
+			partialResultErr = err
+		}
+		if len(page.Tablets) > 0 {
+			if err := send(page.Tablets); err != nil {
+				return err
+			}
+		}
+		if page.NextPageToken == "" {
+			return partialResultErr
+		}
+		start += pageSize(opt)
+	}
+}
+
+func pageSize(opt *GetTabletsByCellPageOptions) int {
+	if opt != nil && opt.PageSize > 0 {
+		return opt.PageSize
+	}
+	return DefaultTabletsPageSize
+}
+
+// getTabletAliasesFromPageToken returns the sorted aliases of the tablets in
+// the cell, and the index of the first one after the page token of opt.
+func (ts *Server) getTabletAliasesFromPageToken(ctx context.Context, cell string, opt *GetTabletsByCellPageOptions) ([]*topodatapb.TabletAlias, int, error) {
+	aliases, err := ts.GetTabletAliasesByCell(ctx, cell)
+	if err != nil {
+		return nil, 0, err
+	}
+	sort.Sort(topoproto.TabletAliasList(aliases))
+
+	if opt == nil || opt.PageToken == "" {
+		return aliases, 0, nil
+	}
+	after, err := topoproto.ParseTabletAlias(opt.PageToken)
+	if err != nil {
+		return nil, 0, vterrors.Wrapf(err, "invalid page token %q", opt.PageToken)
+	}
+	start := sort.Search(len(aliases), func(i int) bool {
+		return topoproto.TabletAliasList{after, aliases[i]}.Less(0, 1)
+	})
+	return aliases, start, nil
+}
+
+// getTabletsPage reads the page of the sorted aliases starting at start.
+func (ts *Server) getTabletsPage(ctx context.Context, aliases []*topodatapb.TabletAlias, start int, opt *GetTabletsByCellPageOptions) (*TabletsPage, error) {
+	page := &TabletsPage{}
+	if start >= len(aliases) {
+		return page, nil
+	}
+
+	end := start + pageSize(opt)
+	if end < len(aliases) {
+		page.NextPageToken = topoproto.TabletAliasString(aliases[end-1])
+	} else {
+		end = len(aliases)
+	}
+	aliases = aliases[start:end]
+
+	var mapOpt *GetTabletsByCellOptions
+	if opt != nil {
+		mapOpt = &GetTabletsByCellOptions{Concurrency: opt.Concurrency}
+	}
+	tabletMap, err := ts.GetTabletMap(ctx, aliases, mapOpt)
+	if err != nil && !IsErrType(err, PartialResult) {
+		return nil, err
+	}
+
+	page.Tablets = make([]*TabletInfo, 0, len(aliases))
+	for _, alias := range aliases {
+		// Tablets deleted since the aliases were listed are skipped, as
+		// GetTabletMap ignores topo.ErrNoNode.
+		if ti, ok := tabletMap[topoproto.TabletAliasString(alias)]; ok {
+			page.Tablets = append(page.Tablets, ti)
+		}
+	}
+	return page, err
+}
+
 // UpdateTablet updates the tablet data only - not associated replication paths.
 // It also uses a span, and sends the event.
 func (ts *Server) UpdateTablet(ctx context.Context, ti *TabletInfo) error {
@@ -329,11 +471,20 @@ func (ts *Server) UpdateTablet(ctx context.Context, ti *TabletInfo) error {
 		return err
 	}
 	tabletPath := path.Join(TabletsPath, topoproto.TabletAliasString(ti.Tablet.Alias), TabletFile)
+	// The tags of the record replaced are the ones ti was read with, as
+	// the update fails if the record changed since. Without a version, the
+	// record replaced is unknown, and so are its tags.
+	var oldTags map[string]string
+	if ti.version != nil {
+		oldTags = ti.storedTags
+	}
 	newVersion, err := conn.Update(ctx, tabletPath, data, ti.version)
 	if err != nil {
 		return err
 	}
 	ti.version = newVersion
+	ti.storedTags = maps.Clone(ti.Tablet.Tags)
+	updateTabletTagIndex(ctx, conn, ti.Tablet.Alias, oldTags, ti.Tablet.Tags)
 
 	event.Dispatch(&events.TabletChange{
 		Tablet: ti.Tablet,
@@ -410,6 +561,7 @@ func (ts *Server) CreateTablet(ctx context.Context, tablet *topodatapb.Tablet) e
 	if _, err := conn.Create(ctx, tabletPath, data); err != nil {
 		return err
 	}
+	updateTabletTagIndex(ctx, conn, tablet.Alias, nil, tablet.Tags)
 
 	if err := UpdateTabletReplicationData(ctx, ts, tablet); err != nil {
 		return err
@@ -438,9 +590,12 @@ func (ts *Server) DeleteTablet(ctx context.Context, tabletAlias *topodatapb.Tabl
 	if err := conn.Delete(ctx, tabletPath, nil); err != nil {
 		return err
 	}
+	deleteTabletLease(ctx, conn, tabletAlias)
 
-	// Only try to log if we have the required info.
+	// Only try to unindex the tags and log if we have the required info.
 	if tErr == nil {
+		updateTabletTagIndex(ctx, conn, tabletAlias, ti.Tablet.Tags, nil)
+
 		// Only copy the identity info for the tablet. The rest has been deleted.
 		event.Dispatch(&events.TabletChange{
 			Tablet: &topodatapb.Tablet{
@@ -482,7 +637,7 @@ func (ts *Server) GetTabletMap(ctx context.Context, tabletAliases []*topodatapb.
 		returnErr error
 	)
 
-	concurrency := DefaultConcurrency
+	concurrency := cappedReadConcurrency(topoCellReadConcurrency)
 	if opt != nil && opt.Concurrency > 0 {
 		concurrency = opt.Concurrency
 	}
@@ -0,0 +1,190 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topo
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/log"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+// This file contains the tablet record cache shared by all the users of a
+// Server in a process. The tablet records of a cell are cached in a
+// TabletsView, whose watch keeps them up to date.
+
+var (
+	topoTabletCacheReads = stats.NewCountersWithMultiLabels(
+		"TopologyTabletCacheReads",
+		"TopologyTabletCacheReads reads of the tablet record cache, by result: Hit when served from the cache, Miss when read from the topology server, Stale when read from the topology server because the watch of the cell failed",
+		[]string{"Cell", "Result"})
+
+	topoTabletCacheStaleness = stats.NewGaugesWithSingleLabel(
+		"TopologyTabletCacheStalenessSeconds",
+		"TopologyTabletCacheStalenessSeconds is how long the cached tablet records of the cell have not been kept up to date, because their watch failed, updated each time setting it again fails",
+		"Cell")
+)
+
+// Results of the reads of the tablet cache.
+const (
+	tabletCacheHit   = "Hit"
+	tabletCacheMiss  = "Miss"
+	tabletCacheStale = "Stale"
+)
+
+// tabletCache is the tablet record cache of a Server.
+type tabletCache struct {
+	// ctx is canceled when the Server is closed, stopping the watches.
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu sync.Mutex
+	// cells are the cached tablets by cell.
+	cells map[string]*tabletCacheCell
+}
+
+// tabletCacheCell is the cache of the tablets of a cell.
+type tabletCacheCell struct {
+	// view is nil until the watch of the cell is set.
+	view *TabletsView
+	// starting is set while the watch of the cell is being set, outside of
+	// the lock of the cache.
+	starting bool
+	// unsupported is set if the topo server of the cell cannot watch the
+	// tablets, the tablets of the cell are then not cached.
+	unsupported bool
+	// lastAttempt is when the watch of the cell was last set, so a watch
+	// which cannot be set is tried again only every watchViewRetryDelay.
+	lastAttempt time.Time
+}
+
+// EnableTabletCache enables the tablet record cache of GetCachedTablet, which
+// is shared by all the users of the Server in the process. It is enabled by
+// --topo_tablet_cache for the servers created after the flags are parsed.
+func (ts *Server) EnableTabletCache() {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	if ts.tabletCache == nil {
+		ctx, cancel := context.WithCancel(context.Background())
+		ts.tabletCache = &tabletCache{
+			ctx:    ctx,
+			cancel: cancel,
+			cells:  make(map[string]*tabletCacheCell),
+		}
+	}
+}
+
+// GetCachedTablet returns the tablet record of alias from the tablet record
+// cache of the Server. The first read of a tablet of a cell sets a watch of
+// all the tablets of the cell, which keeps the cache up to date. If the cache
+// is not enabled, or while the watch is not running, it reads the tablet from
+// the topology server like GetTablet.
+//
+// The records it returns are copies, which the caller may modify. They may
+// lag behind the topology server by the time a watch takes to see a change,
+// so the callers which update a tablet should read it with GetTablet.
+func (ts *Server) GetCachedTablet(ctx context.Context, alias *topodatapb.TabletAlias) (*TabletInfo, error) {
+	ts.mu.Lock()
+	tc := ts.tabletCache
+	ts.mu.Unlock()
+	if tc == nil {
+		return ts.GetTablet(ctx, alias)
+	}
+
+	view := ts.tabletCacheView(ctx, tc, alias.Cell)
+	if view == nil {
+		topoTabletCacheReads.Add([]string{alias.Cell, tabletCacheMiss}, 1)
+		return ts.GetTablet(ctx, alias)
+	}
+
+	if !view.StaleSince().IsZero() {
+		topoTabletCacheReads.Add([]string{alias.Cell, tabletCacheStale}, 1)
+		return ts.GetTablet(ctx, alias)
+	}
+
+	tablet, ok := view.GetTablet(alias)
+	if !ok {
+		// The tablet may have been created since the watch last saw
+		// the cell.
+		topoTabletCacheReads.Add([]string{alias.Cell, tabletCacheMiss}, 1)
+		return ts.GetTablet(ctx, alias)
+	}
+	topoTabletCacheReads.Add([]string{alias.Cell, tabletCacheHit}, 1)
+	return NewTabletInfo(tablet.Tablet.CloneVT(), tablet.version), nil
+}
+
+// tabletCacheView returns the view of the cached tablets of the cell, setting
+// its watch if needed, or nil if the tablets of the cell cannot be cached. The
+// watch is set outside of the lock of the cache, the reads of the cell getting
+// nil until it is set.
+func (ts *Server) tabletCacheView(ctx context.Context, tc *tabletCache, cell string) *TabletsView {
+	tc.mu.Lock()
+	c, ok := tc.cells[cell]
+	if !ok {
+		c = &tabletCacheCell{}
+		tc.cells[cell] = c
+	}
+	if c.view != nil || c.unsupported || c.starting || time.Since(c.lastAttempt) < watchViewRetryDelay {
+		view := c.view
+		tc.mu.Unlock()
+		return view
+	}
+	c.starting = true
+	c.lastAttempt = time.Now()
+	tc.mu.Unlock()
+
+	// The watch outlives the request, so it uses the context of the cache,
+	// bounded by the request for its first read. Its callback keeps the
+	// staleness of the cell up to date.
+	watchCtx, cancel := context.WithCancel(tc.ctx)
+	stop := context.AfterFunc(ctx, cancel)
+	view, err := ts.WatchTabletsByCell(watchCtx, cell, &TabletsWatchCallbacks{
+		OnStale: func(staleSince time.Time) {
+			var staleness time.Duration
+			if !staleSince.IsZero() {
+				staleness = time.Since(staleSince)
+			}
+			topoTabletCacheStaleness.Set(cell, int64(staleness.Seconds()))
+		},
+	})
+	if !stop() && err == nil {
+		// The request was canceled, and the watch with it.
+		err = ctx.Err()
+	}
+
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	c.starting = false
+	if err != nil {
+		cancel()
+		if IsErrType(err, NoImplementation) {
+			log.Infof("Cannot watch the tablets of cell %v, not caching them: %v", cell, err)
+			c.unsupported = true
+		} else {
+			log.Warningf("Cannot watch the tablets of cell %v to cache them, retrying in %v: %v", cell, watchViewRetryDelay, err)
+		}
+		return nil
+	}
+	topoTabletCacheStaleness.Set(cell, 0)
+	c.view = view
+	return view
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topo_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+func TestGetCachedTablet(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts, factory := memorytopo.NewServerAndFactory(ctx, "zone1", "zone2")
+	defer ts.Close()
+
+	alias1 := &topodatapb.TabletAlias{Cell: "zone1", Uid: 1}
+	require.NoError(t, ts.CreateTablet(ctx, &topodatapb.Tablet{Alias: alias1, Hostname: "host1"}))
+
+	// The cache is disabled by default.
+	snapshot := stats.TakeSnapshot()
+	tablet, err := ts.GetCachedTablet(ctx, alias1)
+	require.NoError(t, err)
+	assert.Equal(t, "host1", tablet.Hostname)
+	assert.Empty(t, snapshot.Diff()["TopologyTabletCacheReads"])
+
+	ts.EnableTabletCache()
+	snapshot = stats.TakeSnapshot()
+	tablet, err = ts.GetCachedTablet(ctx, alias1)
+	require.NoError(t, err)
+	assert.Equal(t, "host1", tablet.Hostname)
+
+	// The records are copies of the cached ones.
+	tablet.Hostname = "modified"
+	tablet, err = ts.GetCachedTablet(ctx, alias1)
+	require.NoError(t, err)
+	assert.Equal(t, "host1", tablet.Hostname)
+
+	getCalls := factory.GetCallStats().Counts()["Get"]
+	for range 10 {
+		_, err = ts.GetCachedTablet(ctx, alias1)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, getCalls, factory.GetCallStats().Counts()["Get"], "cached tablets should not be read from the topo server")
+	assert.Equal(t, map[string]int64{"zone1.Hit": 12}, snapshot.Diff()["TopologyTabletCacheReads"])
+
+	// The watch updates the cache.
+	_, err = ts.UpdateTabletFields(ctx, alias1, func(tablet *topodatapb.Tablet) error {
+		tablet.Hostname = "host1b"
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		tablet, err := ts.GetCachedTablet(ctx, alias1)
+		return err == nil && tablet.Hostname == "host1b"
+	}, 10*time.Second, 10*time.Millisecond)
+
+	// Unknown tablets are read from the topo server.
+	snapshot = stats.TakeSnapshot()
+	_, err = ts.GetCachedTablet(ctx, &topodatapb.TabletAlias{Cell: "zone1", Uid: 2})
+	assert.True(t, topo.IsErrType(err, topo.NoNode), "unexpected error: %v", err)
+	assert.Equal(t, map[string]int64{"zone1.Miss": 1}, snapshot.Diff()["TopologyTabletCacheReads"])
+
+	// The tablets of a cell without recursive watches are not cached.
+	factory.AddOperationError(memorytopo.WatchRecursive, "tablets", topo.NewError(topo.NoImplementation, "tablets"))
+	alias3 := &topodatapb.TabletAlias{Cell: "zone2", Uid: 3}
+	require.NoError(t, ts.CreateTablet(ctx, &topodatapb.Tablet{Alias: alias3, Hostname: "host3"}))
+	snapshot = stats.TakeSnapshot()
+	for range 2 {
+		tablet, err = ts.GetCachedTablet(ctx, alias3)
+		require.NoError(t, err)
+		assert.Equal(t, "host3", tablet.Hostname)
+	}
+	assert.Equal(t, map[string]int64{"zone2.Miss": 2}, snapshot.Diff()["TopologyTabletCacheReads"])
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topo
+
+import (
+	"context"
+	"path"
+	"sort"
+	"time"
+
+	"vitess.io/vitess/go/protoutil"
+	"vitess.io/vitess/go/trace"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+// The lease of a tablet is stored in its cell at tablet_leases/<tablet alias>,
+// apart from the tablet record: vttablets renew it every few seconds, and
+// rewriting the tablet record that often would wake up all of its watchers.
+// Tablets whose vttablet does not renew a lease have none, and are never
+// reported as expired.
+
+func tabletLeasePath(alias *topodatapb.TabletAlias) string {
+	return path.Join(TabletLeasesPath, topoproto.TabletAliasString(alias))
+}
+
+// RenewTabletLease renews the lease of a tablet until ttl from now, creating
+// it if needed.
+func (ts *Server) RenewTabletLease(ctx context.Context, alias *topodatapb.TabletAlias, hostname string, ttl time.Duration) error {
+	conn, err := ts.ConnForCell(ctx, alias.Cell)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	lease := &topodatapb.TabletLease{
+		RenewTime:  protoutil.TimeToProto(now),
+		ExpireTime: protoutil.TimeToProto(now.Add(ttl)),
+		Hostname:   hostname,
+	}
+	data, err := lease.MarshalVT()
+	if err != nil {
+		return err
+	}
+	_, err = conn.Update(ctx, tabletLeasePath(alias), data, nil)
+	return err
+}
+
+// GetTabletLease returns the lease of a tablet.
+// It returns ErrNoNode if the tablet has no lease.
+func (ts *Server) GetTabletLease(ctx context.Context, alias *topodatapb.TabletAlias) (*topodatapb.TabletLease, error) {
+	conn, err := ts.ConnForCell(ctx, alias.Cell)
+	if err != nil {
+		return nil, err
+	}
+
+	data, _, err := conn.Get(ctx, tabletLeasePath(alias))
+	if err != nil {
+		return nil, err
+	}
+	lease := &topodatapb.TabletLease{}
+	if err := lease.UnmarshalVT(data); err != nil {
+		return nil, err
+	}
+	return lease, nil
+}
+
+// deleteTabletLease deletes the lease of a tablet, if any. Failures are
+// logged, as a leftover lease only makes the tablet look expired.
+func deleteTabletLease(ctx context.Context, conn Conn, alias *topodatapb.TabletAlias) {
+	if err := conn.Delete(ctx, tabletLeasePath(alias), nil); err != nil && !IsErrType(err, NoNode) {
+		log.Warningf("cannot delete the lease of tablet %v: %v", topoproto.TabletAliasString(alias), err)
+	}
+}
+
+// GetTabletAliasesWithExpiredLease returns the aliases of the tablets of a
+// cell whose lease expired at the given time, sorted. Their vttablet stopped
+// renewing it, so they are likely gone.
+// It returns ErrNoNode if the cell doesn't exist.
+func (ts *Server) GetTabletAliasesWithExpiredLease(ctx context.Context, cell string, now time.Time) ([]*topodatapb.TabletAlias, error) {
+	span, ctx := trace.NewSpan(ctx, "TopoServer.GetTabletAliasesWithExpiredLease")
+	span.Annotate("cell", cell)
+	defer span.Finish()
+
+	conn, err := ts.ConnForCell(ctx, cell)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := conn.ListDir(ctx, TabletLeasesPath, false /*full*/)
+	if err != nil {
+		if IsErrType(err, NoNode) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var expired []*topodatapb.TabletAlias
+	for _, entry := range entries {
+		alias, err := topoproto.ParseTabletAlias(entry.Name)
+		if err != nil {
+			log.Warningf("invalid tablet lease %v in cell %v: %v", entry.Name, cell, err)
+			continue
+		}
+		lease, err := ts.GetTabletLease(ctx, alias)
+		if err != nil {
+			if IsErrType(err, NoNode) {
+				// The tablet was deleted since the leases were listed.
+				continue
+			}
+			return nil, err
+		}
+		if protoutil.TimeFromProto(lease.ExpireTime).Before(now) {
+			expired = append(expired, alias)
+		}
+	}
+	sort.Sort(topoproto.TabletAliasList(expired))
+	return expired, nil
+}
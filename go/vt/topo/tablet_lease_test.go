@@ -0,0 +1,77 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topo_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/protoutil"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+func TestTabletLeases(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "zone1")
+	defer ts.Close()
+
+	expired := func(now time.Time) []string {
+		t.Helper()
+		aliases, err := ts.GetTabletAliasesWithExpiredLease(ctx, "zone1", now)
+		require.NoError(t, err)
+		var out []string
+		for _, alias := range aliases {
+			out = append(out, topoproto.TabletAliasString(alias))
+		}
+		return out
+	}
+
+	// No lease, no expiry.
+	assert.Empty(t, expired(time.Now()))
+
+	alias1 := &topodatapb.TabletAlias{Cell: "zone1", Uid: 1}
+	alias2 := &topodatapb.TabletAlias{Cell: "zone1", Uid: 2}
+	for _, alias := range []*topodatapb.TabletAlias{alias1, alias2} {
+		require.NoError(t, ts.CreateTablet(ctx, &topodatapb.Tablet{Alias: alias, Keyspace: "ks", Shard: "-"}))
+	}
+	require.NoError(t, ts.RenewTabletLease(ctx, alias1, "host1", time.Minute))
+	require.NoError(t, ts.RenewTabletLease(ctx, alias2, "host2", time.Hour))
+
+	lease, err := ts.GetTabletLease(ctx, alias1)
+	require.NoError(t, err)
+	assert.Equal(t, "host1", lease.Hostname)
+	assert.Equal(t, time.Minute, protoutil.TimeFromProto(lease.ExpireTime).Sub(protoutil.TimeFromProto(lease.RenewTime)))
+
+	assert.Empty(t, expired(time.Now()))
+	assert.Equal(t, []string{"zone1-0000000001"}, expired(time.Now().Add(2*time.Minute)))
+	assert.Equal(t, []string{"zone1-0000000001", "zone1-0000000002"}, expired(time.Now().Add(2*time.Hour)))
+
+	// Deleting a tablet deletes its lease.
+	require.NoError(t, ts.DeleteTablet(ctx, alias1))
+	_, err = ts.GetTabletLease(ctx, alias1)
+	assert.True(t, topo.IsErrType(err, topo.NoNode), "expected NoNode, got %v", err)
+	assert.Equal(t, []string{"zone1-0000000002"}, expired(time.Now().Add(2*time.Hour)))
+}
@@ -0,0 +1,208 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topo
+
+import (
+	"context"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+
+	"vitess.io/vitess/go/trace"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+// The tablet tag index of a cell has an empty file for each tag of each of
+// its tablets, at tablet_tags/<key>/<value>/<tablet alias>, so the tablets
+// with a given tag are found without reading all the tablets of the cell.
+//
+// The index is maintained by CreateTablet, UpdateTablet and DeleteTablet on
+// a best-effort basis: GetTabletsByTag checks the tags of the tablets it
+// reads, so stale entries are ignored, and RebuildTabletTagIndex adds the
+// entries that are missing, for instance those of the tablets written
+// before the index existed.
+
+// tabletTagPathComponent escapes a tag key or value into a path component.
+func tabletTagPathComponent(s string) string {
+	switch s {
+	case "":
+		// Escaping never produces a lone %.
+		return "%"
+	case ".", "..":
+		return strings.ReplaceAll(s, ".", "%2E")
+	}
+	return url.PathEscape(s)
+}
+
+func tabletTagPath(key, value string) string {
+	return path.Join(TabletTagsPath, tabletTagPathComponent(key), tabletTagPathComponent(value))
+}
+
+// updateTabletTagIndex updates the entries of the tablet tag index of a
+// tablet whose tags changed from oldTags to newTags. Failures are logged, as
+// the index is best-effort.
+func updateTabletTagIndex(ctx context.Context, conn Conn, alias *topodatapb.TabletAlias, oldTags, newTags map[string]string) {
+	aliasStr := topoproto.TabletAliasString(alias)
+	for key, value := range oldTags {
+		if newValue, ok := newTags[key]; ok && newValue == value {
+			continue
+		}
+		if err := conn.Delete(ctx, path.Join(tabletTagPath(key, value), aliasStr), nil); err != nil && !IsErrType(err, NoNode) {
+			log.Warningf("cannot remove tag %v=%v of tablet %v from the tablet tag index: %v", key, value, aliasStr, err)
+		}
+	}
+	for key, value := range newTags {
+		if oldValue, ok := oldTags[key]; ok && oldValue == value {
+			continue
+		}
+		if _, err := conn.Create(ctx, path.Join(tabletTagPath(key, value), aliasStr), nil); err != nil && !IsErrType(err, NodeExists) {
+			log.Warningf("cannot add tag %v=%v of tablet %v to the tablet tag index: %v", key, value, aliasStr, err)
+		}
+	}
+}
+
+// GetTabletsByTag returns the tablets of all the cells that have the given
+// tag, sorted by alias, using the tablet tag index of each cell.
+// It returns ErrPartialResult if some tablets couldn't be read. The results
+// in the slice are incomplete.
+func (ts *Server) GetTabletsByTag(ctx context.Context, key, value string) ([]*TabletInfo, error) {
+	span, ctx := trace.NewSpan(ctx, "TopoServer.GetTabletsByTag")
+	span.Annotate("key", key)
+	span.Annotate("value", value)
+	defer span.Finish()
+
+	cells, err := ts.GetCellInfoNames(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var aliases []*topodatapb.TabletAlias
+	for _, cell := range cells {
+		conn, err := ts.ConnForCell(ctx, cell)
+		if err != nil {
+			return nil, err
+		}
+		entries, err := conn.ListDir(ctx, tabletTagPath(key, value), false /*full*/)
+		if err != nil {
+			if IsErrType(err, NoNode) {
+				continue
+			}
+			return nil, err
+		}
+		for _, entry := range entries {
+			alias, err := topoproto.ParseTabletAlias(entry.Name)
+			if err != nil {
+				log.Warningf("invalid entry %v in the tablet tag index of cell %v: %v", entry.Name, cell, err)
+				continue
+			}
+			aliases = append(aliases, alias)
+		}
+	}
+
+	var partialResultErr error
+	tabletMap, err := ts.GetTabletMap(ctx, aliases, nil)
+	if err != nil {
+		if !IsErrType(err, PartialResult) {
+			return nil, err
+		}
+		partialResultErr = err
+	}
+
+	tablets := make([]*TabletInfo, 0, len(tabletMap))
+	for _, ti := range tabletMap {
+		// Skip the stale entries of the index.
+		if tagValue, ok := ti.Tags[key]; ok && tagValue == value {
+			tablets = append(tablets, ti)
+		}
+	}
+	sort.Slice(tablets, func(i, j int) bool {
+		return topoproto.TabletAliasString(tablets[i].Alias) < topoproto.TabletAliasString(tablets[j].Alias)
+	})
+	return tablets, partialResultErr
+}
+
+// RebuildTabletTagIndex rebuilds the tablet tag index of a cell from its
+// tablet records, adding the missing entries and removing the stale ones.
+func (ts *Server) RebuildTabletTagIndex(ctx context.Context, cell string) error {
+	span, ctx := trace.NewSpan(ctx, "TopoServer.RebuildTabletTagIndex")
+	span.Annotate("cell", cell)
+	defer span.Finish()
+
+	conn, err := ts.ConnForCell(ctx, cell)
+	if err != nil {
+		return err
+	}
+	tablets, err := ts.GetTabletsByCell(ctx, cell, nil)
+	if err != nil {
+		return err
+	}
+
+	wanted := make(map[string]bool)
+	for _, ti := range tablets {
+		aliasStr := topoproto.TabletAliasString(ti.Alias)
+		for key, value := range ti.Tags {
+			entryPath := path.Join(tabletTagPath(key, value), aliasStr)
+			wanted[entryPath] = true
+			if _, err := conn.Create(ctx, entryPath, nil); err != nil && !IsErrType(err, NodeExists) {
+				return err
+			}
+		}
+	}
+
+	// Remove the entries of the index that no tablet has.
+	keys, err := conn.ListDir(ctx, TabletTagsPath, false /*full*/)
+	if err != nil {
+		if IsErrType(err, NoNode) {
+			return nil
+		}
+		return err
+	}
+	for _, key := range keys {
+		keyPath := path.Join(TabletTagsPath, key.Name)
+		values, err := conn.ListDir(ctx, keyPath, false /*full*/)
+		if err != nil {
+			if IsErrType(err, NoNode) {
+				continue
+			}
+			return err
+		}
+		for _, value := range values {
+			valuePath := path.Join(keyPath, value.Name)
+			entries, err := conn.ListDir(ctx, valuePath, false /*full*/)
+			if err != nil {
+				if IsErrType(err, NoNode) {
+					continue
+				}
+				return err
+			}
+			for _, entry := range entries {
+				entryPath := path.Join(valuePath, entry.Name)
+				if wanted[entryPath] {
+					continue
+				}
+				if err := conn.Delete(ctx, entryPath, nil); err != nil && !IsErrType(err, NoNode) {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topo_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+func tabletAliases(tablets []*topo.TabletInfo) []string {
+	aliases := make([]string, 0, len(tablets))
+	for _, ti := range tablets {
+		aliases = append(aliases, topoproto.TabletAliasString(ti.Alias))
+	}
+	return aliases
+}
+
+func TestGetTabletsByTag(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts, factory := memorytopo.NewServerAndFactory(ctx, "zone1", "zone2")
+	defer ts.Close()
+
+	newTablet := func(cell string, uid uint32, tags map[string]string) *topodatapb.Tablet {
+		return &topodatapb.Tablet{
+			Alias:    &topodatapb.TabletAlias{Cell: cell, Uid: uid},
+			Keyspace: "ks",
+			Shard:    "-",
+			Tags:     tags,
+		}
+	}
+	require.NoError(t, ts.CreateTablet(ctx, newTablet("zone1", 100, map[string]string{"rack": "r1", "path": "a/b"})))
+	require.NoError(t, ts.CreateTablet(ctx, newTablet("zone1", 101, map[string]string{"rack": "r2"})))
+	require.NoError(t, ts.CreateTablet(ctx, newTablet("zone2", 200, map[string]string{"rack": "r1", "empty": ""})))
+
+	byTag := func(key, value string) []string {
+		t.Helper()
+		tablets, err := ts.GetTabletsByTag(ctx, key, value)
+		require.NoError(t, err)
+		return tabletAliases(tablets)
+	}
+	assert.Equal(t, []string{"zone1-0000000100", "zone2-0000000200"}, byTag("rack", "r1"))
+	assert.Equal(t, []string{"zone1-0000000101"}, byTag("rack", "r2"))
+	assert.Equal(t, []string{"zone1-0000000100"}, byTag("path", "a/b"))
+	assert.Equal(t, []string{"zone2-0000000200"}, byTag("empty", ""))
+	assert.Empty(t, byTag("rack", "r3"))
+
+	// Updates move the tablet to the entries of its new tags.
+	_, err := ts.UpdateTabletFields(ctx, &topodatapb.TabletAlias{Cell: "zone1", Uid: 100}, func(tablet *topodatapb.Tablet) error {
+		tablet.Tags = map[string]string{"rack": "r2"}
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"zone2-0000000200"}, byTag("rack", "r1"))
+	assert.Equal(t, []string{"zone1-0000000100", "zone1-0000000101"}, byTag("rack", "r2"))
+	assert.Empty(t, byTag("path", "a/b"))
+
+	// So do the updates of the tags in place, without reading the record
+	// they replace.
+	ti, err := ts.GetTablet(ctx, &topodatapb.TabletAlias{Cell: "zone1", Uid: 100})
+	require.NoError(t, err)
+	ti.Tags["rack"] = "r3"
+	// Only the cell is read, to get its connection.
+	getCalls := factory.GetCallStats().Counts()["Get"]
+	_, err = ts.ConnForCell(ctx, "zone1")
+	require.NoError(t, err)
+	connGetCalls := factory.GetCallStats().Counts()["Get"] - getCalls
+	getCalls = factory.GetCallStats().Counts()["Get"]
+	require.NoError(t, ts.UpdateTablet(ctx, ti))
+	assert.Equal(t, getCalls+connGetCalls, factory.GetCallStats().Counts()["Get"])
+	assert.Equal(t, []string{"zone1-0000000101"}, byTag("rack", "r2"))
+	assert.Equal(t, []string{"zone1-0000000100"}, byTag("rack", "r3"))
+	ti.Tags["rack"] = "r2"
+	require.NoError(t, ts.UpdateTablet(ctx, ti))
+	assert.Empty(t, byTag("rack", "r3"))
+
+	require.NoError(t, ts.DeleteTablet(ctx, &topodatapb.TabletAlias{Cell: "zone1", Uid: 101}))
+	assert.Equal(t, []string{"zone1-0000000100"}, byTag("rack", "r2"))
+
+	// A tablet written without its index entries is only found once the
+	// index is rebuilt, and stale entries are removed.
+	conn, err := ts.ConnForCell(ctx, "zone2")
+	require.NoError(t, err)
+	require.NoError(t, conn.Delete(ctx, "tablet_tags/rack/r1/zone2-0000000200", nil))
+	_, err = conn.Create(ctx, "tablet_tags/rack/r9/zone2-0000000200", nil)
+	require.NoError(t, err)
+	assert.Empty(t, byTag("rack", "r1"))
+	assert.Empty(t, byTag("rack", "r9"))
+
+	require.NoError(t, ts.RebuildTabletTagIndex(ctx, "zone2"))
+	assert.Equal(t, []string{"zone2-0000000200"}, byTag("rack", "r1"))
+	_, _, err = conn.Get(ctx, "tablet_tags/rack/r9/zone2-0000000200")
+	assert.True(t, topo.IsErrType(err, topo.NoNode), "stale entry not removed: %v", err)
+}
@@ -171,3 +171,72 @@ func TestServerGetTabletsByCellPartialResults(t *testing.T) {
 	assert.True(t, proto.Equal(tablets[0].Tablet, out[0].Tablet), "Got: %v, want %v", tablets[0].Tablet, out[0].Tablet)
 	assert.True(t, proto.Equal(tablets[2].Tablet, out[1].Tablet), "Got: %v, want %v", tablets[2].Tablet, out[1].Tablet)
 }
+
+func TestServerGetTabletsByCellPage(t *testing.T) {
+	const cell = "zone1"
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ts, factory := memorytopo.NewServerAndFactory(ctx, cell)
+	defer ts.Close()
+
+	for _, uid := range []uint32{5, 1, 4, 2, 3} {
+		require.NoError(t, ts.CreateTablet(ctx, &topodatapb.Tablet{
+			Alias:    &topodatapb.TabletAlias{Cell: cell, Uid: uid},
+			Keyspace: "ks",
+			Shard:    "-",
+		}))
+	}
+
+	// Pages follow the alias order, and the last one has no next page token.
+	opt := &topo.GetTabletsByCellPageOptions{PageSize: 2}
+	var pages [][]string
+	for {
+		page, err := ts.GetTabletsByCellPage(ctx, cell, opt)
+		require.NoError(t, err)
+		pages = append(pages, tabletAliases(page.Tablets))
+		if page.NextPageToken == "" {
+			break
+		}
+		opt.PageToken = page.NextPageToken
+	}
+	assert.Equal(t, [][]string{
+		{"zone1-0000000001", "zone1-0000000002"},
+		{"zone1-0000000003", "zone1-0000000004"},
+		{"zone1-0000000005"},
+	}, pages)
+
+	// A page token survives the deletion of its tablet.
+	require.NoError(t, ts.DeleteTablet(ctx, &topodatapb.TabletAlias{Cell: cell, Uid: 2}))
+	page, err := ts.GetTabletsByCellPage(ctx, cell, &topo.GetTabletsByCellPageOptions{PageSize: 2, PageToken: "zone1-0000000002"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"zone1-0000000003", "zone1-0000000004"}, tabletAliases(page.Tablets))
+
+	_, err = ts.GetTabletsByCellPage(ctx, cell, &topo.GetTabletsByCellPageOptions{PageToken: "bad"})
+	assert.Error(t, err)
+
+	// Streaming sends every page, and reports the tablets that couldn't be
+	// read once done.
+	factory.AddOperationError(memorytopo.Get, "tablets/zone1-0000000003/Tablet", errors.New("fake error"))
+	pages = nil
+	err = ts.StreamTabletsByCell(ctx, cell, &topo.GetTabletsByCellPageOptions{PageSize: 2}, func(tablets []*topo.TabletInfo) error {
+		pages = append(pages, tabletAliases(tablets))
+		return nil
+	})
+	assert.True(t, topo.IsErrType(err, topo.PartialResult), "Not a partial result: %v", err)
+	assert.Equal(t, [][]string{
+		{"zone1-0000000001"},
+		{"zone1-0000000004", "zone1-0000000005"},
+	}, pages)
+
+	// Streaming stops at the first error of send.
+	sendErr := errors.New("send failed")
+	calls := 0
+	err = ts.StreamTabletsByCell(ctx, cell, &topo.GetTabletsByCellPageOptions{PageSize: 1}, func(tablets []*topo.TabletInfo) error {
+		calls++
+		return sendErr
+	})
+	assert.ErrorIs(t, err, sendErr)
+	assert.Equal(t, 1, calls)
+}
@@ -0,0 +1,254 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topo
+
+import (
+	"context"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+// This file contains a view of the tablets of a cell kept up to date by a
+// watch, for the processes which would otherwise list all the tablets of the
+// cell periodically.
+
+// TabletsWatchCallbacks are called by a TabletsView with the changes of the
+// tablets of its cell. Each of them may be nil. They are called one at a time,
+// from the goroutine of the watch, so they should not block.
+type TabletsWatchCallbacks struct {
+	// OnAdd is called with each tablet of the cell when the watch starts,
+	// and with each tablet created after.
+	OnAdd func(tablet *TabletInfo)
+	// OnUpdate is called with the previous and the new record of an updated
+	// tablet.
+	OnUpdate func(old, new *TabletInfo)
+	// OnRemove is called with the last record of a deleted tablet.
+	OnRemove func(tablet *TabletInfo)
+	// OnStale is called with when the watch failed, when it fails and then
+	// each time setting it again fails, and with the zero time once it is
+	// set again.
+	OnStale func(staleSince time.Time)
+}
+
+// TabletsView is the view of all the tablet records of a cell, kept up to
+// date by a watch. It is created by Server.WatchTabletsByCell.
+type TabletsView struct {
+	*watchedView
+
+	callbacks TabletsWatchCallbacks
+
+	// mu protects tablets.
+	mu sync.Mutex
+	// tablets are the tablets by alias.
+	tablets map[string]*TabletInfo
+}
+
+// WatchTabletsByCell watches all the tablet records of the cell, calling the
+// callbacks with each change. It returns once the view has the current
+// tablets of the cell, and the callbacks were called with them. The watch
+// runs until ctx is canceled, and is set again if it fails, the view then
+// catching up with the changes it missed.
+//
+// It returns a NoImplementation error if the topo server of the cell does not
+// support WatchRecursive, in which case the caller should poll
+// GetTabletsByCell.
+func (ts *Server) WatchTabletsByCell(ctx context.Context, cell string, callbacks *TabletsWatchCallbacks) (*TabletsView, error) {
+	conn, err := ts.ConnForCell(ctx, cell)
+	if err != nil {
+		return nil, err
+	}
+
+	wv, ctx := newWatchedView(ctx)
+	tv := &TabletsView{
+		watchedView: wv,
+		tablets:     make(map[string]*TabletInfo),
+	}
+	if callbacks != nil {
+		tv.callbacks = *callbacks
+	}
+
+	err = startWatch(ctx, wv, &viewWatch[*WatchDataRecursive]{
+		name: "the tablets of cell " + cell,
+		watch: func(ctx context.Context) (<-chan *WatchDataRecursive, error) {
+			initial, changes, err := conn.WatchRecursive(ctx, TabletsPath)
+			if err != nil {
+				return nil, err
+			}
+			tv.sync(initial)
+			return changes, nil
+		},
+		apply:   tv.apply,
+		onStale: tv.callbacks.OnStale,
+	})
+	if err != nil {
+		wv.abort()
+		return nil, err
+	}
+	wv.started()
+	return tv, nil
+}
+
+// apply applies the changes of the watch until it fails, and returns its
+// error.
+func (tv *TabletsView) apply(changes <-chan *WatchDataRecursive) error {
+	for wd := range changes {
+		if wd.Err != nil && !IsErrType(wd.Err, NoNode) {
+			return wd.Err
+		}
+
+		alias, ok := tabletAliasOfPath(wd.Path)
+		if !ok {
+			continue
+		}
+		if wd.Err != nil {
+			tv.remove(alias)
+			continue
+		}
+		if tablet := tabletOfWatchData(alias, &wd.WatchData); tablet != nil {
+			tv.put(alias, tablet)
+		}
+	}
+	return NewError(Interrupted, TabletsPath)
+}
+
+// sync replaces the tablets of the view with the ones of the current values
+// of a new watch, calling the callbacks with the differences.
+func (tv *TabletsView) sync(initial []*WatchDataRecursive) {
+	current := make(map[string]*TabletInfo, len(initial))
+	for _, wd := range initial {
+		alias, ok := tabletAliasOfPath(wd.Path)
+		if !ok {
+			continue
+		}
+		if tablet := tabletOfWatchData(alias, &wd.WatchData); tablet != nil {
+			current[alias] = tablet
+		}
+	}
+
+	aliases := make([]string, 0, len(current))
+	for alias := range current {
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+	for _, alias := range aliases {
+		tv.put(alias, current[alias])
+	}
+
+	tv.mu.Lock()
+	var removed []string
+	for alias := range tv.tablets {
+		if _, ok := current[alias]; !ok {
+			removed = append(removed, alias)
+		}
+	}
+	tv.mu.Unlock()
+	sort.Strings(removed)
+	for _, alias := range removed {
+		tv.remove(alias)
+	}
+}
+
+// put adds or updates a tablet of the view, and calls the callbacks if it
+// changed.
+func (tv *TabletsView) put(alias string, tablet *TabletInfo) {
+	tv.mu.Lock()
+	old, ok := tv.tablets[alias]
+	if ok && old.version.String() == tablet.version.String() {
+		tv.mu.Unlock()
+		return
+	}
+	tv.tablets[alias] = tablet
+	tv.mu.Unlock()
+
+	switch {
+	case !ok && tv.callbacks.OnAdd != nil:
+		tv.callbacks.OnAdd(tablet)
+	case ok && tv.callbacks.OnUpdate != nil:
+		tv.callbacks.OnUpdate(old, tablet)
+	}
+}
+
+// remove removes a tablet of the view, and calls the callbacks if it was in
+// the view.
+func (tv *TabletsView) remove(alias string) {
+	tv.mu.Lock()
+	old, ok := tv.tablets[alias]
+	delete(tv.tablets, alias)
+	tv.mu.Unlock()
+
+	if ok && tv.callbacks.OnRemove != nil {
+		tv.callbacks.OnRemove(old)
+	}
+}
+
+// Tablets returns the tablets of the view, sorted by alias.
+func (tv *TabletsView) Tablets() []*TabletInfo {
+	tv.mu.Lock()
+	defer tv.mu.Unlock()
+
+	aliases := make([]*topodatapb.TabletAlias, 0, len(tv.tablets))
+	for _, tablet := range tv.tablets {
+		aliases = append(aliases, tablet.Alias)
+	}
+	sort.Sort(topoproto.TabletAliasList(aliases))
+
+	tablets := make([]*TabletInfo, 0, len(aliases))
+	for _, alias := range aliases {
+		tablets = append(tablets, tv.tablets[topoproto.TabletAliasString(alias)])
+	}
+	return tablets
+}
+
+// GetTablet returns the tablet of the view with the alias, if any.
+func (tv *TabletsView) GetTablet(alias *topodatapb.TabletAlias) (*TabletInfo, bool) {
+	tv.mu.Lock()
+	defer tv.mu.Unlock()
+
+	tablet, ok := tv.tablets[topoproto.TabletAliasString(alias)]
+	return tablet, ok
+}
+
+// tabletAliasOfPath returns the alias of the tablet of a tablet record path
+// relative to the root of a cell, like tablets/<alias>/Tablet.
+func tabletAliasOfPath(filePath string) (string, bool) {
+	rest, ok := strings.CutPrefix(strings.TrimPrefix(filePath, "/"), TabletsPath+"/")
+	if !ok {
+		return "", false
+	}
+	alias, file := path.Split(rest)
+	alias = strings.TrimSuffix(alias, "/")
+	return alias, file == TabletFile && alias != "" && !strings.Contains(alias, "/")
+}
+
+// tabletOfWatchData unpacks the tablet record of a watch, or returns nil if it
+// cannot be unpacked.
+func tabletOfWatchData(alias string, wd *WatchData) *TabletInfo {
+	tablet := &topodatapb.Tablet{}
+	if err := tablet.UnmarshalVT(wd.Contents); err != nil {
+		log.Warningf("Cannot unpack the tablet record of %v: %v", alias, err)
+		return nil
+	}
+	return NewTabletInfo(tablet, wd.Version)
+}
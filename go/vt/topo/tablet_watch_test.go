@@ -0,0 +1,116 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topo_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+// tabletEvent is a call of the TabletsWatchCallbacks, recorded by a test.
+type tabletEvent struct {
+	op    string
+	alias string
+	host  string
+}
+
+func TestWatchTabletsByCell(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts, factory := memorytopo.NewServerAndFactory(ctx, "zone1", "zone2")
+	defer ts.Close()
+
+	newTablet := func(uid uint32, host string) *topodatapb.Tablet {
+		return &topodatapb.Tablet{
+			Alias:    &topodatapb.TabletAlias{Cell: "zone1", Uid: uid},
+			Hostname: host,
+		}
+	}
+	require.NoError(t, ts.CreateTablet(ctx, newTablet(2, "host2")))
+	require.NoError(t, ts.CreateTablet(ctx, newTablet(1, "host1")))
+
+	events := make(chan tabletEvent, 10)
+	record := func(op string) func(*topo.TabletInfo) {
+		return func(ti *topo.TabletInfo) {
+			events <- tabletEvent{op: op, alias: topoproto.TabletAliasString(ti.Alias), host: ti.Hostname}
+		}
+	}
+	callbacks := &topo.TabletsWatchCallbacks{
+		OnAdd: record("add"),
+		OnUpdate: func(old, new *topo.TabletInfo) {
+			assert.Equal(t, "host1", old.Hostname)
+			record("update")(new)
+		},
+		OnRemove: record("remove"),
+	}
+
+	watchCtx, watchCancel := context.WithCancel(ctx)
+	defer watchCancel()
+	tv, err := ts.WatchTabletsByCell(watchCtx, "zone1", callbacks)
+	require.NoError(t, err)
+
+	// The current tablets are added before WatchTabletsByCell returns.
+	require.Len(t, events, 2)
+	assert.Equal(t, tabletEvent{op: "add", alias: "zone1-0000000001", host: "host1"}, <-events)
+	assert.Equal(t, tabletEvent{op: "add", alias: "zone1-0000000002", host: "host2"}, <-events)
+	tablets := tv.Tablets()
+	require.Len(t, tablets, 2)
+	assert.Equal(t, "host1", tablets[0].Hostname)
+	assert.Equal(t, "host2", tablets[1].Hostname)
+
+	_, err = ts.UpdateTabletFields(ctx, &topodatapb.TabletAlias{Cell: "zone1", Uid: 1}, func(tablet *topodatapb.Tablet) error {
+		tablet.Hostname = "host1b"
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, tabletEvent{op: "update", alias: "zone1-0000000001", host: "host1b"}, <-events)
+
+	require.NoError(t, ts.CreateTablet(ctx, newTablet(3, "host3")))
+	assert.Equal(t, tabletEvent{op: "add", alias: "zone1-0000000003", host: "host3"}, <-events)
+
+	require.NoError(t, ts.DeleteTablet(ctx, &topodatapb.TabletAlias{Cell: "zone1", Uid: 2}))
+	assert.Equal(t, tabletEvent{op: "remove", alias: "zone1-0000000002", host: "host2"}, <-events)
+
+	tablet, ok := tv.GetTablet(&topodatapb.TabletAlias{Cell: "zone1", Uid: 1})
+	require.True(t, ok)
+	assert.Equal(t, "host1b", tablet.Hostname)
+	_, ok = tv.GetTablet(&topodatapb.TabletAlias{Cell: "zone1", Uid: 2})
+	assert.False(t, ok)
+
+	watchCancel()
+	select {
+	case <-tv.Done():
+	case <-time.After(10 * time.Second):
+		t.Fatal("the watch did not stop")
+	}
+	assert.Empty(t, events)
+
+	// The callers of a cell without recursive watches fall back to polling.
+	factory.AddOperationError(memorytopo.WatchRecursive, "tablets", topo.NewError(topo.NoImplementation, "tablets"))
+	_, err = ts.WatchTabletsByCell(ctx, "zone2", callbacks)
+	assert.True(t, topo.IsErrType(err, topo.NoImplementation), "unexpected error: %v", err)
+}
@@ -217,3 +217,155 @@ func checkWaitForNewLeader(t *testing.T, ctx context.Context, ts *topo.Server) {
 		t.Fatalf("wrong node elected: %v", leader)
 	}
 }
+
+// checkElectionTTL runs an election with a TTL on the LeaderParticipation
+func checkElectionTTL(t *testing.T, ctx context.Context, ts *topo.Server) {
+	conn, err := ts.ConnForCell(ctx, topo.GlobalCell)
+	if err != nil {
+		t.Fatalf("ConnForCell(global) failed: %v", err)
+	}
+	name := "testmp"
+	ttlConn, ok := conn.(topo.LeaderParticipationTTLConn)
+	if !ok {
+		t.Fatalf("ConnForCell(global) doesn't support a TTL")
+	}
+
+	// consul sessions can't live less than 10s.
+	id1 := "id1"
+	mp1, err := ttlConn.NewLeaderParticipationWithTTL(name, id1, 10*time.Second)
+	if err != nil {
+		t.Fatalf("cannot create mp1: %v", err)
+	}
+	ctx1, err := mp1.WaitForLeadership()
+	if err != nil {
+		t.Fatalf("mp1 cannot become Leader: %v", err)
+	}
+
+	// the participations without TTL see the same primary
+	id2 := "id2"
+	mp2, err := conn.NewLeaderParticipation(name, id2)
+	if err != nil {
+		t.Fatalf("cannot create mp2: %v", err)
+	}
+	waitForLeaderID(t, mp2, id1)
+
+	mp1.Stop()
+	select {
+	case <-ctx1.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatalf("shutting down mp1 didn't close ctx1 in time")
+	}
+	waitForLeaderID(t, mp2, "")
+}
+
+// checkForceResign runs the ForceResign test on the LeaderParticipation
+func checkForceResign(t *testing.T, ctx context.Context, ts *topo.Server) {
+	conn, err := ts.ConnForCell(ctx, topo.GlobalCell)
+	if err != nil {
+		t.Fatalf("ConnForCell(global) failed: %v", err)
+	}
+	name := "testmp"
+
+	// no primary yet, nothing to resign
+	if _, err := ts.ResignLeader(ctx, name, ""); !topo.IsErrType(err, topo.NoNode) {
+		t.Fatalf("ResignLeader with no primary returned %v, expected NoNode", err)
+	}
+
+	// id1 is the primary
+	id1 := "id1"
+	mp1, err := conn.NewLeaderParticipation(name, id1)
+	if err != nil {
+		t.Fatalf("cannot create mp1: %v", err)
+	}
+	if _, ok := mp1.(topo.ForceResignLeaderParticipation); !ok {
+		t.Fatalf("mp1 doesn't implement topo.ForceResignLeaderParticipation")
+	}
+	ctx1, err := mp1.WaitForLeadership()
+	if err != nil {
+		t.Fatalf("mp1 cannot become Leader: %v", err)
+	}
+	waitForLeaderID(t, mp1, id1)
+
+	// id2 waits in the background
+	id2 := "id2"
+	mp2, err := conn.NewLeaderParticipation(name, id2)
+	if err != nil {
+		t.Fatalf("cannot create mp2: %v", err)
+	}
+	mp2IsLeader := make(chan error)
+	var mp2Context context.Context
+	go func() {
+		var err error
+		mp2Context, err = mp2.WaitForLeadership()
+		mp2IsLeader <- err
+	}()
+
+	// the primary is only forced to resign if it is the expected one
+	if _, err := ts.ResignLeader(ctx, name, id2); !topo.IsErrType(err, topo.BadVersion) {
+		t.Fatalf("ResignLeader(%v) returned %v, expected BadVersion", id2, err)
+	}
+	waitForLeaderID(t, mp2, id1)
+
+	resigned, err := ts.ResignLeader(ctx, name, "")
+	if err != nil {
+		t.Fatalf("ResignLeader failed: %v", err)
+	}
+	if resigned != id1 {
+		t.Fatalf("ResignLeader resigned %v, expected %v", resigned, id1)
+	}
+
+	// this should close ctx1, some implementations only notice
+	// the loss of the lock on their next check of it.
+	select {
+	case <-ctx1.Done():
+	case <-time.After(15 * time.Second):
+		t.Fatalf("forcing mp1 to resign didn't close ctx1 in time")
+	}
+
+	// now mp2 should be primary
+	if err := <-mp2IsLeader; err != nil {
+		t.Fatalf("mp2 awoke with error: %v", err)
+	}
+	waitForLeaderID(t, mp2, id2)
+
+	// mp1 can run for election again
+	mp1IsLeader := make(chan error)
+	var ctx1Again context.Context
+	go func() {
+		var err error
+		ctx1Again, err = mp1.WaitForLeadership()
+		mp1IsLeader <- err
+	}()
+
+	mp2.Stop()
+	select {
+	case <-mp2Context.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatalf("shutting down mp2 didn't close mp2Context in time")
+	}
+
+	if err := <-mp1IsLeader; err != nil {
+		t.Fatalf("mp1 awoke with error: %v", err)
+	}
+	waitForLeaderID(t, mp1, id1)
+
+	// a participation which lost its primaryship can still be stopped
+	if _, err := ts.ResignLeader(ctx, name, id1); err != nil {
+		t.Fatalf("ResignLeader failed: %v", err)
+	}
+	select {
+	case <-ctx1Again.Done():
+	case <-time.After(15 * time.Second):
+		t.Fatalf("forcing mp1 to resign again didn't close its context in time")
+	}
+	stopped := make(chan struct{})
+	go func() {
+		mp1.Stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(15 * time.Second):
+		t.Fatalf("stopping mp1 after it was forced to resign didn't return in time")
+	}
+}
@@ -52,6 +52,15 @@ func checkLock(t *testing.T, ctx context.Context, ts *topo.Server) {
 
 	t.Log("===      checkLockUnblocks")
 	checkLockUnblocks(ctx, t, conn)
+
+	t.Log("===      checkGetLock")
+	checkGetLock(ctx, t, conn)
+
+	t.Log("===      checkForceUnlock")
+	checkForceUnlock(ctx, t, conn)
+
+	t.Log("===      checkLockName")
+	checkLockName(ctx, t, conn)
 }
 
 func checkLockTimeout(ctx context.Context, t *testing.T, conn topo.Conn) {
@@ -121,6 +130,129 @@ func checkLockTimeout(ctx context.Context, t *testing.T, conn topo.Conn) {
 	}
 }
 
+// checkGetLock makes sure GetLock returns the holder of a lock, and
+// ErrNoNode once it is released.
+func checkGetLock(ctx context.Context, t *testing.T, conn topo.Conn) {
+	keyspacePath := path.Join(topo.KeyspacesPath, "test_keyspace")
+	lc, ok := conn.(topo.LockHolderConn)
+	if !ok {
+		t.Fatalf("conn doesn't report the holders of the locks")
+	}
+	if _, err := lc.GetLock(ctx, keyspacePath); !topo.IsErrType(err, topo.NoNode) {
+		t.Fatalf("GetLock(unlocked): %v", err)
+	}
+
+	lockDescriptor, err := conn.Lock(ctx, keyspacePath, "holder")
+	if err != nil {
+		t.Fatalf("Lock: %v", err)
+	}
+	info, err := lc.GetLock(ctx, keyspacePath)
+	if err != nil {
+		t.Fatalf("GetLock: %v", err)
+	}
+	if info.Contents != "holder" {
+		t.Errorf("GetLock returned contents %q, expected %q", info.Contents, "holder")
+	}
+
+	if err := lockDescriptor.Unlock(ctx); err != nil {
+		t.Fatalf("Unlock(): %v", err)
+	}
+	if _, err := lc.GetLock(ctx, keyspacePath); !topo.IsErrType(err, topo.NoNode) {
+		t.Fatalf("GetLock(released): %v", err)
+	}
+}
+
+// checkForceUnlock makes sure ForceUnlock breaks a lock only when it is
+// held with the given contents.
+func checkForceUnlock(ctx context.Context, t *testing.T, conn topo.Conn) {
+	keyspacePath := path.Join(topo.KeyspacesPath, "test_keyspace")
+	lc, ok := conn.(topo.LockHolderConn)
+	if !ok {
+		t.Fatalf("conn doesn't break the locks")
+	}
+	if err := lc.ForceUnlock(ctx, keyspacePath, "stuck"); !topo.IsErrType(err, topo.NoNode) {
+		t.Fatalf("ForceUnlock(unlocked): %v", err)
+	}
+
+	lockDescriptor, err := conn.Lock(ctx, keyspacePath, "stuck")
+	if err != nil {
+		t.Fatalf("Lock: %v", err)
+	}
+	if err := lc.ForceUnlock(ctx, keyspacePath, "other"); !topo.IsErrType(err, topo.BadVersion) {
+		t.Fatalf("ForceUnlock(other contents): %v", err)
+	}
+	if err := lc.ForceUnlock(ctx, keyspacePath, "stuck"); err != nil {
+		t.Fatalf("ForceUnlock: %v", err)
+	}
+
+	// The lock can be taken again, and the stuck holder can't release it.
+	lockDescriptor2, err := conn.Lock(ctx, keyspacePath, "next")
+	if err != nil {
+		t.Fatalf("Lock(broken): %v", err)
+	}
+	_ = lockDescriptor.Unlock(ctx)
+	info, err := lc.GetLock(ctx, keyspacePath)
+	if err != nil {
+		t.Fatalf("GetLock: %v", err)
+	}
+	if info.Contents != "next" {
+		t.Errorf("GetLock returned contents %q, expected %q", info.Contents, "next")
+	}
+	if err := lockDescriptor2.Unlock(ctx); err != nil {
+		t.Fatalf("Unlock(): %v", err)
+	}
+}
+
+// checkLockName makes sure LockNameWithTTL locks a directory that
+// doesn't exist.
+func checkLockName(ctx context.Context, t *testing.T, conn topo.Conn) {
+	namePath := path.Join(topo.NamedLocksPath, "test_name")
+	nc, ok := conn.(topo.NamedLockConn)
+	if !ok {
+		t.Fatalf("conn doesn't support the named locks")
+	}
+
+	// consul sessions can't live less than 10s, and the locks of the
+	// servers without TTLs only support the default one.
+	lockDescriptor, err := nc.LockNameWithTTL(ctx, namePath, "named", 10*time.Second)
+	if topo.IsErrType(err, topo.NoImplementation) {
+		lockDescriptor, err = nc.LockNameWithTTL(ctx, namePath, "named", 0)
+	}
+	if err != nil {
+		t.Fatalf("LockNameWithTTL: %v", err)
+	}
+
+	fastCtx, cancel := context.WithTimeout(ctx, timeUntilLockIsTaken)
+	if _, err := nc.LockNameWithTTL(fastCtx, namePath, "again", 0); !topo.IsErrType(err, topo.Timeout) {
+		t.Fatalf("LockNameWithTTL(again): %v", err)
+	}
+	cancel()
+
+	info, err := conn.(topo.LockHolderConn).GetLock(ctx, namePath)
+	if err != nil {
+		t.Fatalf("GetLock: %v", err)
+	}
+	if info.Contents != "named" {
+		t.Errorf("GetLock returned contents %q, expected %q", info.Contents, "named")
+	}
+
+	if err := lockDescriptor.Check(ctx); err != nil {
+		t.Errorf("Check(): %v", err)
+	}
+	if err := lockDescriptor.Unlock(ctx); err != nil {
+		t.Fatalf("Unlock(): %v", err)
+	}
+
+	// The lock can be taken again once released.
+	lockDescriptor, err = nc.LockNameWithTTL(ctx, namePath, "next", 0)
+	if err != nil {
+		t.Fatalf("LockNameWithTTL(released): %v", err)
+	}
+	if err := lockDescriptor.Unlock(ctx); err != nil {
+		t.Fatalf("Unlock(): %v", err)
+	}
+}
+
 // checkLockMissing makes sure we can't lock a non-existing directory.
 func checkLockMissing(ctx context.Context, t *testing.T, conn topo.Conn) {
 	keyspacePath := path.Join(topo.KeyspacesPath, "test_keyspace_666")
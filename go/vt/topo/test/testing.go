@@ -126,6 +126,16 @@ func TopoServerTestSuite(t *testing.T, ctx context.Context, factory func() *topo
 	executeTestSuite(checkWaitForNewLeader, t, ctx, ts, ignoreList, "checkWaitForNewLeader")
 	ts.Close()
 
+	t.Log("=== checkElectionTTL")
+	ts = factory()
+	executeTestSuite(checkElectionTTL, t, ctx, ts, ignoreList, "checkElectionTTL")
+	ts.Close()
+
+	t.Log("=== checkForceResign")
+	ts = factory()
+	executeTestSuite(checkForceResign, t, ctx, ts, ignoreList, "checkForceResign")
+	ts.Close()
+
 	t.Log("=== checkDirectory")
 	ts = factory()
 	executeTestSuite(checkDirectory, t, ctx, ts, ignoreList, "checkDirectory")
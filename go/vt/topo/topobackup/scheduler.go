@@ -0,0 +1,132 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topobackup
+
+import (
+	"context"
+	"time"
+
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/mysqlctl/backupstorage"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/internal/periodic"
+)
+
+var (
+	backupsCounter = stats.NewCountersWithSingleLabel(
+		"TopoBackups",
+		"Scheduled topo backups, by result",
+		"Result")
+	lastSuccessGauge = stats.NewGauge(
+		"TopoBackupLastSuccessTimestamp",
+		"Unix time of the last successful scheduled topo backup")
+	lastSizeGauge = stats.NewGauge(
+		"TopoBackupLastSuccessBytes",
+		"Size of the archive of the last successful scheduled topo backup")
+	lastDurationGauge = stats.NewGauge(
+		"TopoBackupLastSuccessDurationMs",
+		"Time taken by the last successful scheduled topo backup, in milliseconds")
+	prunedCounter = stats.NewCounter(
+		"TopoBackupsPruned",
+		"Topo backups removed by the retention policy")
+)
+
+// Config configures a Scheduler.
+type Config struct {
+	// Interval is the time between backups.
+	Interval time.Duration
+	// Dir is the backup storage directory of the backups.
+	Dir string
+	// Retention is which backups to keep.
+	Retention Retention
+	// Timeout bounds the time taken by a backup and its pruning.
+	Timeout time.Duration
+}
+
+// Scheduler periodically backs up the topo to the configured backup
+// storage, and prunes the backups that are no longer retained.
+type Scheduler struct {
+	ts     *topo.Server
+	config Config
+
+	// getBackupStorage is backupstorage.GetBackupStorage, except in tests.
+	getBackupStorage func() (backupstorage.BackupStorage, error)
+
+	runner *periodic.Runner
+}
+
+// NewScheduler returns a scheduler for the given config.
+func NewScheduler(ts *topo.Server, config Config) *Scheduler {
+	if config.Dir == "" {
+		config.Dir = DefaultDir
+	}
+	s := &Scheduler{
+		ts:               ts,
+		config:           config,
+		getBackupStorage: backupstorage.GetBackupStorage,
+	}
+	s.runner = periodic.NewRunner(config.Interval, config.Timeout, s.runOnce)
+	return s
+}
+
+// Start starts taking backups, the first one right away.
+func (s *Scheduler) Start() {
+	s.runner.Start()
+	log.Infof("Scheduled topo backups to %v every %v", s.config.Dir, s.config.Interval)
+}
+
+// Stop stops taking backups, and waits for the one in progress, if any, to
+// be interrupted.
+func (s *Scheduler) Stop() {
+	s.runner.Stop()
+}
+
+// runOnce takes a backup and prunes the old ones. Errors are logged and
+// counted, and the next run tries again.
+func (s *Scheduler) runOnce(ctx context.Context) {
+	bs, err := s.getBackupStorage()
+	if err != nil {
+		backupsCounter.Add("Failure", 1)
+		log.Errorf("Cannot take topo backup: %v", err)
+		return
+	}
+	defer bs.Close()
+
+	start := time.Now()
+	name, size, err := Backup(ctx, s.ts, bs, s.config.Dir, start)
+	if err != nil {
+		backupsCounter.Add("Failure", 1)
+		log.Errorf("Topo backup failed: %v", err)
+		return
+	}
+	backupsCounter.Add("Success", 1)
+	lastSuccessGauge.Set(start.Unix())
+	lastSizeGauge.Set(size)
+	lastDurationGauge.Set(time.Since(start).Milliseconds())
+	log.Infof("Topo backup %v/%v done (%d bytes)", s.config.Dir, name, size)
+
+	removed, err := Prune(ctx, bs, s.config.Dir, s.config.Retention, start)
+	prunedCounter.Add(int64(len(removed)))
+	if err != nil {
+		log.Errorf("Cannot prune topo backups: %v", err)
+		return
+	}
+	if len(removed) > 0 {
+		log.Infof("Removed topo backups %v", removed)
+	}
+}
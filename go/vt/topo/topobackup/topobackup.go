@@ -0,0 +1,151 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package topobackup takes portable backups of the topo server.
+//
+// A topo backup is a topo archive (see helpers.TopoArchive) of the global
+// cell and of every cell it knows about, stored in a
+// backupstorage.BackupStorage next to the tablet backups. Unlike a snapshot
+// of the topo server itself, it can be reviewed, and restored to a topo
+// server of any flavor with ImportTopology.
+package topobackup
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/mysqlctl/backupstorage"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/helpers"
+)
+
+const (
+	// DefaultDir is the backup storage directory of topo backups.
+	DefaultDir = "topo"
+
+	// ArchiveFile is the file of a backup that holds the topo archive.
+	ArchiveFile = "ARCHIVE"
+
+	// TimestampFormat is the format of the time in backup names, which
+	// is the same as for tablet backups.
+	TimestampFormat = "2006-01-02.150405"
+)
+
+// Backup archives the topo to a new backup in dir, and returns its name.
+func Backup(ctx context.Context, ts *topo.Server, bs backupstorage.BackupStorage, dir string, now time.Time) (name string, size int64, err error) {
+	cells, err := ts.GetCellInfoNames(ctx)
+	if err != nil {
+		return "", 0, err
+	}
+	files, err := helpers.ExportTopo(ctx, ts, append([]string{topo.GlobalCell}, cells...), "", helpers.ExportOptions{})
+	if err != nil {
+		return "", 0, err
+	}
+	archive, err := helpers.NewTopoArchive(files)
+	if err != nil {
+		return "", 0, err
+	}
+	data, err := helpers.MarshalTopoArchive(archive, helpers.ArchiveFormatJSON)
+	if err != nil {
+		return "", 0, err
+	}
+
+	name = now.UTC().Format(TimestampFormat)
+	bh, err := bs.StartBackup(ctx, dir, name)
+	if err != nil {
+		return "", 0, fmt.Errorf("StartBackup(%v, %v): %w", dir, name, err)
+	}
+	defer func() {
+		if err != nil {
+			if abortErr := bh.AbortBackup(ctx); abortErr != nil {
+				log.Errorf("failed to abort topo backup %v/%v: %v", dir, name, abortErr)
+			}
+		}
+	}()
+
+	w, err := bh.AddFile(ctx, ArchiveFile, int64(len(data)))
+	if err != nil {
+		return "", 0, fmt.Errorf("AddFile(%v): %w", ArchiveFile, err)
+	}
+	if _, err := w.Write(data); err != nil {
+		w.Close()
+		return "", 0, fmt.Errorf("cannot write %v: %w", ArchiveFile, err)
+	}
+	if err := w.Close(); err != nil {
+		return "", 0, fmt.Errorf("cannot close %v: %w", ArchiveFile, err)
+	}
+	if err := bh.EndBackup(ctx); err != nil {
+		return "", 0, fmt.Errorf("EndBackup(%v, %v): %w", dir, name, err)
+	}
+	return name, int64(len(data)), nil
+}
+
+// ReadBackup returns the archive of a backup returned by ListBackups.
+func ReadBackup(ctx context.Context, bh backupstorage.BackupHandle) (*helpers.TopoArchive, error) {
+	r, err := bh.ReadFile(ctx, ArchiveFile)
+	if err != nil {
+		return nil, fmt.Errorf("ReadFile(%v/%v/%v): %w", bh.Directory(), bh.Name(), ArchiveFile, err)
+	}
+	defer r.Close()
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return helpers.UnmarshalTopoArchive(data)
+}
+
+// Retention is which backups Prune keeps. The most recent backup is always
+// kept.
+type Retention struct {
+	// Count is how many backups to keep, 0 for no limit.
+	Count int
+	// MaxAge is how long to keep backups for, 0 for no limit.
+	MaxAge time.Duration
+}
+
+// Prune removes the backups in dir that the retention policy doesn't keep,
+// and returns their names.
+func Prune(ctx context.Context, bs backupstorage.BackupStorage, dir string, retention Retention, now time.Time) ([]string, error) {
+	bhs, err := bs.ListBackups(ctx, dir)
+	if err != nil {
+		return nil, fmt.Errorf("ListBackups(%v): %w", dir, err)
+	}
+
+	var removed []string
+	// Backups are listed oldest first.
+	for i, bh := range bhs[:max(len(bhs)-1, 0)] {
+		remove := retention.Count > 0 && len(bhs)-i > retention.Count
+		if !remove && retention.MaxAge > 0 {
+			backupTime, err := time.Parse(TimestampFormat, bh.Name())
+			if err != nil {
+				log.Warningf("skipping topo backup %v/%v with an unexpected name", dir, bh.Name())
+				continue
+			}
+			remove = now.Sub(backupTime) > retention.MaxAge
+		}
+		if !remove {
+			continue
+		}
+		if err := bs.RemoveBackup(ctx, dir, bh.Name()); err != nil {
+			return removed, fmt.Errorf("RemoveBackup(%v, %v): %w", dir, bh.Name(), err)
+		}
+		removed = append(removed, bh.Name())
+	}
+	return removed, nil
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topobackup
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/mysqlctl/backupstorage"
+	"vitess.io/vitess/go/vt/mysqlctl/filebackupstorage"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+func setupBackupStorage(t *testing.T) backupstorage.BackupStorage {
+	filebackupstorage.FileBackupStorageRoot = t.TempDir()
+	bs, ok := backupstorage.BackupStorageMap["file"]
+	require.True(t, ok)
+	return bs
+}
+
+func backupNames(t *testing.T, bs backupstorage.BackupStorage) []string {
+	bhs, err := bs.ListBackups(context.Background(), DefaultDir)
+	require.NoError(t, err)
+	var names []string
+	for _, bh := range bhs {
+		names = append(names, bh.Name())
+	}
+	return names
+}
+
+func TestBackup(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "zone1")
+	require.NoError(t, ts.CreateKeyspace(ctx, "ks", &topodatapb.Keyspace{}))
+	bs := setupBackupStorage(t)
+
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	name, size, err := Backup(ctx, ts, bs, DefaultDir, now)
+	require.NoError(t, err)
+	assert.Equal(t, "2024-05-01.120000", name)
+	assert.NotZero(t, size)
+
+	bhs, err := bs.ListBackups(ctx, DefaultDir)
+	require.NoError(t, err)
+	require.Len(t, bhs, 1)
+	archive, err := ReadBackup(ctx, bhs[0])
+	require.NoError(t, err)
+	var paths []string
+	for _, file := range archive.Files {
+		paths = append(paths, file.Cell+":"+file.Path)
+	}
+	assert.Equal(t, []string{"global:cells/zone1/CellInfo", "global:keyspaces/ks/Keyspace"}, paths)
+}
+
+func TestPrune(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "zone1")
+	bs := setupBackupStorage(t)
+
+	start := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	for i := range 5 {
+		_, _, err := Backup(ctx, ts, bs, DefaultDir, start.Add(time.Duration(i)*time.Hour))
+		require.NoError(t, err)
+	}
+	now := start.Add(4 * time.Hour)
+
+	removed, err := Prune(ctx, bs, DefaultDir, Retention{Count: 4}, now)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2024-05-01.000000"}, removed)
+
+	removed, err = Prune(ctx, bs, DefaultDir, Retention{MaxAge: 150 * time.Minute}, now)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2024-05-01.010000"}, removed)
+	assert.Equal(t, []string{"2024-05-01.020000", "2024-05-01.030000", "2024-05-01.040000"}, backupNames(t, bs))
+
+	// The most recent backup is always kept.
+	removed, err = Prune(ctx, bs, DefaultDir, Retention{MaxAge: time.Minute}, now.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Len(t, removed, 2)
+	assert.Equal(t, []string{"2024-05-01.040000"}, backupNames(t, bs))
+}
+
+func TestSchedulerRunOnce(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "zone1")
+	bs := setupBackupStorage(t)
+
+	s := NewScheduler(ts, Config{Interval: time.Hour, Retention: Retention{Count: 1}})
+	s.getBackupStorage = func() (backupstorage.BackupStorage, error) { return bs, nil }
+	successes := backupsCounter.Counts()["Success"]
+
+	s.runOnce(ctx)
+	assert.Equal(t, successes+1, backupsCounter.Counts()["Success"])
+	assert.NotZero(t, lastSuccessGauge.Get())
+	assert.NotZero(t, lastSizeGauge.Get())
+	assert.Len(t, backupNames(t, bs), 1)
+}
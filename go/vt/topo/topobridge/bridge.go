@@ -0,0 +1,274 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package topobridge forwards the changes made to topo files to external
+// systems, so they can react to topology changes without polling.
+//
+// The bridge watches the configured topo directories, and delivers a typed
+// Event for every file that is created, updated or deleted to every
+// configured sink: webhooks, or Kafka topics through a Kafka REST Proxy.
+// Delivery is at-least-once and in order for each sink: a failed delivery
+// is retried until it succeeds, and when a watch is interrupted the
+// directory is re-read and the changes missed in the meantime are
+// delivered. Events are kept in memory only, so the changes that happen
+// while the bridge is not running are not delivered.
+package topobridge
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/topo"
+)
+
+// Change types.
+const (
+	// ChangePut is a file that was created or updated.
+	ChangePut = "put"
+	// ChangeDelete is a file that was deleted.
+	ChangeDelete = "delete"
+)
+
+// WatchRetryDelay is how long the bridge waits before restarting an
+// interrupted watch.
+var WatchRetryDelay = 5 * time.Second
+
+var (
+	eventsCounter = stats.NewCountersWithMultiLabels(
+		"TopoBridgeEvents",
+		"Topo change events delivered by the topo bridge",
+		[]string{"Sink", "Change"})
+	deliveryErrorsCounter = stats.NewCountersWithSingleLabel(
+		"TopoBridgeDeliveryErrors",
+		"Failed topo bridge delivery attempts",
+		"Sink")
+	queueLengthGauge = stats.NewGaugesWithSingleLabel(
+		"TopoBridgeQueueLength",
+		"Topo change events waiting to be delivered by the topo bridge",
+		"Sink")
+)
+
+// Event describes a change to a topo file.
+type Event struct {
+	// Cell is the cell of the file, "global" for the global topo.
+	Cell string `json:"cell"`
+	// Path is the path of the file, relative to the root of the cell.
+	Path string `json:"path"`
+	// Change is either ChangePut or ChangeDelete.
+	Change string `json:"change"`
+	// Type is the full name of the protobuf message stored in the file, if
+	// it is known, e.g. "topodata.Keyspace".
+	Type string `json:"type,omitempty"`
+	// Version is the version of the file after a put.
+	Version string `json:"version,omitempty"`
+	// Value is the JSON encoding of the message stored in the file after a
+	// put, if its type is known.
+	Value json.RawMessage `json:"value,omitempty"`
+	// RawValue is the contents of the file after a put, if its type is
+	// not known.
+	RawValue []byte `json:"raw_value,omitempty"`
+	// Time is when the bridge saw the change.
+	Time time.Time `json:"time"`
+}
+
+// Bridge forwards topo change events to sinks.
+type Bridge struct {
+	ts     *topo.Server
+	config *Config
+	sinks  []*queuedSink
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// New returns a bridge for the given config, which must have been loaded by
+// LoadConfig.
+func New(ts *topo.Server, config *Config) *Bridge {
+	b := &Bridge{
+		ts:     ts,
+		config: config,
+	}
+	for _, sc := range config.Sinks {
+		b.sinks = append(b.sinks, newQueuedSink(newSink(sc, time.Duration(config.Timeout)), config))
+	}
+	return b
+}
+
+// Start starts the watches and the delivery of their events.
+func (b *Bridge) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	b.cancel = cancel
+
+	for _, qs := range b.sinks {
+		b.wg.Add(1)
+		go func() {
+			defer b.wg.Done()
+			qs.run(ctx)
+		}()
+	}
+	for _, wc := range b.config.Watches {
+		b.wg.Add(1)
+		go func() {
+			defer b.wg.Done()
+			b.watch(ctx, wc)
+		}()
+	}
+	log.Infof("Topo bridge started with %d watches and %d sinks", len(b.config.Watches), len(b.sinks))
+}
+
+// Stop stops the bridge. The events that were not delivered yet are
+// dropped.
+func (b *Bridge) Stop() {
+	if b.cancel == nil {
+		return
+	}
+	b.cancel()
+	b.wg.Wait()
+	b.cancel = nil
+}
+
+// watch forwards the changes under a directory until ctx is done.
+func (b *Bridge) watch(ctx context.Context, wc WatchConfig) {
+	// versions are the versions of the files we know about. They are used
+	// to find out what changed while the watch was interrupted.
+	var versions map[string]string
+	for {
+		err := b.watchOnce(ctx, wc, &versions)
+		if ctx.Err() != nil {
+			return
+		}
+		log.Warningf("Topo bridge watch of %v:%v interrupted, restarting it in %v: %v", wc.Cell, wc.Path, WatchRetryDelay, err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(WatchRetryDelay):
+		}
+	}
+}
+
+// watchOnce runs a single watch. The first time it is called, with a nil
+// map of versions, the current files are only recorded. After that, the
+// files that changed since the previous watch are forwarded before the
+// changes streamed by the new one.
+func (b *Bridge) watchOnce(ctx context.Context, wc WatchConfig, versions *map[string]string) error {
+	conn, err := b.ts.ConnForCell(ctx, wc.Cell)
+	if err != nil {
+		return err
+	}
+
+	watchCtx, cancel := context.WithCancel(ctx)
+	initial, changes, err := conn.WatchRecursive(watchCtx, wc.Path)
+	if err != nil {
+		cancel()
+		return err
+	}
+	defer func() {
+		cancel()
+		// The changes channel must be drained until it is closed.
+		for range changes {
+		}
+	}()
+
+	current := make(map[string]string, len(initial))
+	for _, wd := range initial {
+		version := wd.Version.String()
+		current[wd.Path] = version
+		if *versions != nil && (*versions)[wd.Path] != version {
+			if !b.publish(ctx, newPutEvent(wc.Cell, wd.Path, version, wd.Contents)) {
+				return ctx.Err()
+			}
+		}
+	}
+	for filePath := range *versions {
+		if _, ok := current[filePath]; !ok {
+			if !b.publish(ctx, newDeleteEvent(wc.Cell, filePath)) {
+				return ctx.Err()
+			}
+		}
+	}
+	*versions = current
+
+	for wd := range changes {
+		var event *Event
+		switch {
+		case wd.Err == nil:
+			version := wd.Version.String()
+			current[wd.Path] = version
+			event = newPutEvent(wc.Cell, wd.Path, version, wd.Contents)
+		case topo.IsErrType(wd.Err, topo.NoNode) && wd.Path != "":
+			delete(current, wd.Path)
+			event = newDeleteEvent(wc.Cell, wd.Path)
+		default:
+			return wd.Err
+		}
+		if !b.publish(ctx, event) {
+			return ctx.Err()
+		}
+	}
+	return topo.NewError(topo.Interrupted, wc.Path)
+}
+
+// publish queues an event for every sink. It blocks while a queue is full,
+// and returns false if ctx is done first.
+func (b *Bridge) publish(ctx context.Context, event *Event) bool {
+	for _, qs := range b.sinks {
+		if !qs.enqueue(ctx, event) {
+			return false
+		}
+	}
+	return true
+}
+
+func newPutEvent(cell, filePath, version string, contents []byte) *Event {
+	event := &Event{
+		Cell:    cell,
+		Path:    filePath,
+		Change:  ChangePut,
+		Version: version,
+		Time:    time.Now(),
+	}
+	if m := topo.NewContentProto(filePath); m != nil {
+		event.Type = string(m.ProtoReflect().Descriptor().FullName())
+		if err := proto.Unmarshal(contents, m); err == nil {
+			if value, err := (protojson.MarshalOptions{UseProtoNames: true}).Marshal(m); err == nil {
+				event.Value = value
+				return event
+			}
+		}
+	}
+	event.RawValue = contents
+	return event
+}
+
+func newDeleteEvent(cell, filePath string) *Event {
+	event := &Event{
+		Cell:   cell,
+		Path:   filePath,
+		Change: ChangeDelete,
+		Time:   time.Now(),
+	}
+	if m := topo.NewContentProto(filePath); m != nil {
+		event.Type = string(m.ProtoReflect().Descriptor().FullName())
+	}
+	return event
+}
@@ -0,0 +1,253 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topobridge
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+// eventRecorder is a webhook, or a Kafka REST Proxy, that records the events
+// it receives. It fails the first `failures` requests.
+type eventRecorder struct {
+	mu       sync.Mutex
+	events   []*Event
+	failures int
+	keys     []string
+}
+
+func (er *eventRecorder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	er.mu.Lock()
+	defer er.mu.Unlock()
+
+	if er.failures > 0 {
+		er.failures--
+		http.Error(w, "try again", http.StatusServiceUnavailable)
+		return
+	}
+
+	if r.Header.Get("Content-Type") == "application/vnd.kafka.json.v2+json" {
+		var request kafkaProduceRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		for _, record := range request.Records {
+			er.keys = append(er.keys, record.Key)
+			er.events = append(er.events, record.Value)
+		}
+		w.Write([]byte(`{"offsets":[{"partition":0,"offset":1}]}`))
+		return
+	}
+
+	event := &Event{}
+	if err := json.NewDecoder(r.Body).Decode(event); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	er.events = append(er.events, event)
+}
+
+// changes returns the "<change> <cell>:<path>" of the recorded events.
+func (er *eventRecorder) changes() []string {
+	er.mu.Lock()
+	defer er.mu.Unlock()
+
+	var changes []string
+	for _, event := range er.events {
+		changes = append(changes, event.Change+" "+event.Cell+":"+event.Path)
+	}
+	return changes
+}
+
+func testConfig(watches []WatchConfig, sinks ...SinkConfig) *Config {
+	config := &Config{
+		Watches:    watches,
+		Sinks:      sinks,
+		RetryDelay: Duration(10 * time.Millisecond),
+	}
+	if err := config.validate(); err != nil {
+		panic(err)
+	}
+	return config
+}
+
+func TestBridge(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "zone1")
+
+	webhook := &eventRecorder{failures: 2}
+	webhookServer := httptest.NewServer(webhook)
+	defer webhookServer.Close()
+	kafka := &eventRecorder{}
+	kafkaServer := httptest.NewServer(kafka)
+	defer kafkaServer.Close()
+
+	b := New(ts, testConfig(
+		[]WatchConfig{{Cell: topo.GlobalCell, Path: topo.KeyspacesPath}},
+		SinkConfig{Type: SinkWebhook, URL: webhookServer.URL, Headers: map[string]string{"X-Test": "1"}},
+		SinkConfig{Type: SinkKafka, URL: kafkaServer.URL, Topic: "vitess"},
+	))
+	// Files that exist when the bridge starts are not forwarded.
+	require.NoError(t, ts.CreateKeyspace(ctx, "ks1", &topodatapb.Keyspace{}))
+	b.Start()
+	defer b.Stop()
+
+	// Wait for the watch to be established.
+	time.Sleep(100 * time.Millisecond)
+	require.NoError(t, ts.CreateKeyspace(ctx, "ks2", &topodatapb.Keyspace{DurabilityPolicy: "semi_sync"}))
+	require.NoError(t, ts.DeleteKeyspace(ctx, "ks1"))
+
+	want := []string{
+		"put global:keyspaces/ks2/Keyspace",
+		"delete global:keyspaces/ks1/Keyspace",
+	}
+	for _, er := range []*eventRecorder{webhook, kafka} {
+		assert.Eventually(t, func() bool {
+			return len(er.changes()) >= len(want)
+		}, 10*time.Second, 10*time.Millisecond)
+		assert.Equal(t, want, er.changes())
+	}
+
+	// Delivery was retried until the webhook accepted the events.
+	assert.Zero(t, webhook.failures)
+
+	event := webhook.events[0]
+	assert.Equal(t, "topodata.Keyspace", event.Type)
+	assert.NotEmpty(t, event.Version)
+	assert.JSONEq(t, `{"durability_policy":"semi_sync"}`, string(event.Value))
+	assert.Equal(t, []string{"global/keyspaces/ks2/Keyspace", "global/keyspaces/ks1/Keyspace"}, kafka.keys)
+}
+
+func TestBridgeResync(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "zone1")
+	require.NoError(t, ts.CreateKeyspace(ctx, "ks1", &topodatapb.Keyspace{}))
+	require.NoError(t, ts.CreateKeyspace(ctx, "ks2", &topodatapb.Keyspace{}))
+
+	webhook := &eventRecorder{}
+	webhookServer := httptest.NewServer(webhook)
+	defer webhookServer.Close()
+
+	wc := WatchConfig{Cell: topo.GlobalCell, Path: topo.KeyspacesPath}
+	b := New(ts, testConfig([]WatchConfig{wc}, SinkConfig{Type: SinkWebhook, URL: webhookServer.URL}))
+
+	// Record the state when the bridge starts.
+	var versions map[string]string
+	watchCtx, watchCancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		b.watchOnce(watchCtx, wc, &versions)
+	}()
+	time.Sleep(100 * time.Millisecond)
+	watchCancel()
+	<-done
+	require.Len(t, versions, 2)
+
+	// What changes while the watch is down is forwarded by the next one.
+	ki, err := ts.GetKeyspace(ctx, "ks1")
+	require.NoError(t, err)
+	ki.DurabilityPolicy = "semi_sync"
+	lctx, unlock, err := ts.LockKeyspace(ctx, "ks1", "test")
+	require.NoError(t, err)
+	require.NoError(t, ts.UpdateKeyspace(lctx, ki))
+	unlock(&err)
+	require.NoError(t, ts.DeleteKeyspace(ctx, "ks2"))
+	require.NoError(t, ts.CreateKeyspace(ctx, "ks3", &topodatapb.Keyspace{}))
+
+	b.Start()
+	defer b.Stop()
+	watchCtx, watchCancel = context.WithCancel(ctx)
+	defer watchCancel()
+	go b.watchOnce(watchCtx, wc, &versions)
+
+	assert.Eventually(t, func() bool {
+		return len(webhook.changes()) >= 3
+	}, 10*time.Second, 10*time.Millisecond)
+	assert.ElementsMatch(t, []string{
+		"put global:keyspaces/ks1/Keyspace",
+		"delete global:keyspaces/ks2/Keyspace",
+		"put global:keyspaces/ks3/Keyspace",
+	}, webhook.changes())
+}
+
+func TestLoadConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		wantErr string
+	}{{
+		name:    "no watches",
+		config:  `{"sinks": [{"type": "webhook", "url": "http://localhost"}]}`,
+		wantErr: "no watches",
+	}, {
+		name:    "no sinks",
+		config:  `{"watches": [{"cell": "global"}]}`,
+		wantErr: "no sinks",
+	}, {
+		name:    "bad sink type",
+		config:  `{"watches": [{"cell": "global"}], "sinks": [{"type": "carrier_pigeon", "url": "http://localhost"}]}`,
+		wantErr: `invalid type "carrier_pigeon"`,
+	}, {
+		name:    "kafka without topic",
+		config:  `{"watches": [{"cell": "global"}], "sinks": [{"type": "kafka", "url": "http://localhost"}]}`,
+		wantErr: "no topic",
+	}, {
+		name:    "bad duration",
+		config:  `{"watches": [{"cell": "global"}], "sinks": [{"type": "webhook", "url": "http://localhost"}], "retry_delay": "soon"}`,
+		wantErr: "cannot parse",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.json")
+			require.NoError(t, os.WriteFile(path, []byte(tt.config), 0o644))
+			_, err := LoadConfig(path)
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{
+		"watches": [{"cell": "global", "path": "keyspaces"}],
+		"sinks": [{"type": "webhook", "url": "http://localhost"}],
+		"retry_delay": "2s"
+	}`), 0o644))
+	config, err := LoadConfig(path)
+	require.NoError(t, err)
+	assert.Equal(t, "webhook-0", config.Sinks[0].Name)
+	assert.Equal(t, Duration(2*time.Second), config.RetryDelay)
+	assert.Equal(t, Duration(DefaultMaxRetryDelay), config.MaxRetryDelay)
+	assert.Equal(t, DefaultQueueSize, config.QueueSize)
+}
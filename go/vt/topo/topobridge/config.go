@@ -0,0 +1,186 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topobridge
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Sink types.
+const (
+	// SinkWebhook POSTs every event as a JSON document to an URL.
+	SinkWebhook = "webhook"
+	// SinkKafka produces every event to a Kafka topic, through a Kafka REST
+	// Proxy (v2 API).
+	SinkKafka = "kafka"
+)
+
+// Default delivery settings.
+const (
+	DefaultQueueSize     = 10000
+	DefaultRetryDelay    = time.Second
+	DefaultMaxRetryDelay = time.Minute
+	DefaultTimeout       = 10 * time.Second
+)
+
+// Config is the format of the bridge configuration file:
+//
+//	{
+//	  "watches": [
+//	    {"cell": "global", "path": "keyspaces"},
+//	    {"cell": "zone1", "path": "tablets"}
+//	  ],
+//	  "sinks": [
+//	    {"type": "webhook", "url": "https://hooks.example.com/vitess", "headers": {"Authorization": "Bearer xyz"}},
+//	    {"type": "kafka", "url": "http://kafka-rest:8082", "topic": "vitess-topology"}
+//	  ],
+//	  "retry_delay": "1s",
+//	  "max_retry_delay": "1m"
+//	}
+type Config struct {
+	Watches []WatchConfig `json:"watches"`
+	Sinks   []SinkConfig  `json:"sinks"`
+
+	// QueueSize is the number of events each sink can buffer before the
+	// watches are paused.
+	QueueSize int `json:"queue_size,omitempty"`
+	// RetryDelay is how long to wait before retrying a failed delivery. It
+	// doubles on every consecutive failure, up to MaxRetryDelay.
+	RetryDelay    Duration `json:"retry_delay,omitempty"`
+	MaxRetryDelay Duration `json:"max_retry_delay,omitempty"`
+	// Timeout is the timeout of every delivery attempt.
+	Timeout Duration `json:"timeout,omitempty"`
+}
+
+// WatchConfig selects a topo directory whose files are forwarded.
+type WatchConfig struct {
+	// Cell is the cell to watch, "global" for the global topo.
+	Cell string `json:"cell"`
+	// Path is the directory to watch, relative to the root of the cell.
+	// An empty path watches the whole cell.
+	Path string `json:"path"`
+}
+
+// SinkConfig describes an external system events are delivered to.
+type SinkConfig struct {
+	// Name identifies the sink in logs and stats. It defaults to
+	// "<type>-<index>".
+	Name string `json:"name,omitempty"`
+	// Type is either SinkWebhook or SinkKafka.
+	Type string `json:"type"`
+	// URL is the webhook URL, or the base URL of the Kafka REST Proxy.
+	URL string `json:"url"`
+	// Topic is the Kafka topic events are produced to.
+	Topic string `json:"topic,omitempty"`
+	// Headers are added to every request.
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// Duration is a time.Duration that is encoded as a string in JSON, e.g. "5s".
+type Duration time.Duration
+
+// UnmarshalJSON is part of the json.Unmarshaler interface.
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	value, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(value)
+	return nil
+}
+
+// MarshalJSON is part of the json.Marshaler interface.
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// LoadConfig reads and validates the bridge configuration from a file.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &Config{}
+	if err := json.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("cannot parse topo bridge config %v: %w", path, err)
+	}
+	if err := config.validate(); err != nil {
+		return nil, fmt.Errorf("invalid topo bridge config %v: %w", path, err)
+	}
+	return config, nil
+}
+
+// validate checks the config and fills in the defaults.
+func (config *Config) validate() error {
+	if len(config.Watches) == 0 {
+		return fmt.Errorf("no watches")
+	}
+	for i, watch := range config.Watches {
+		if watch.Cell == "" {
+			return fmt.Errorf("watch %d has no cell", i)
+		}
+	}
+
+	if len(config.Sinks) == 0 {
+		return fmt.Errorf("no sinks")
+	}
+	names := make(map[string]bool, len(config.Sinks))
+	for i := range config.Sinks {
+		sink := &config.Sinks[i]
+		switch sink.Type {
+		case SinkWebhook:
+		case SinkKafka:
+			if sink.Topic == "" {
+				return fmt.Errorf("kafka sink %d has no topic", i)
+			}
+		default:
+			return fmt.Errorf("sink %d has invalid type %q, expected %q or %q", i, sink.Type, SinkWebhook, SinkKafka)
+		}
+		if sink.URL == "" {
+			return fmt.Errorf("sink %d has no url", i)
+		}
+		if sink.Name == "" {
+			sink.Name = fmt.Sprintf("%s-%d", sink.Type, i)
+		}
+		if names[sink.Name] {
+			return fmt.Errorf("duplicate sink name %q", sink.Name)
+		}
+		names[sink.Name] = true
+	}
+
+	if config.QueueSize <= 0 {
+		config.QueueSize = DefaultQueueSize
+	}
+	if config.RetryDelay <= 0 {
+		config.RetryDelay = Duration(DefaultRetryDelay)
+	}
+	if config.MaxRetryDelay < config.RetryDelay {
+		config.MaxRetryDelay = Duration(max(DefaultMaxRetryDelay, time.Duration(config.RetryDelay)))
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = Duration(DefaultTimeout)
+	}
+	return nil
+}
@@ -0,0 +1,239 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topobridge
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"vitess.io/vitess/go/vt/logutil"
+)
+
+var deliveryErrorsLogger = logutil.NewThrottledLogger("TopoBridgeDeliveryErrors", time.Minute)
+
+// sink delivers events to an external system.
+type sink interface {
+	// name identifies the sink in logs and stats.
+	name() string
+	// deliver sends an event, and returns once the external system
+	// acknowledged it.
+	deliver(ctx context.Context, event *Event) error
+}
+
+func newSink(sc SinkConfig, timeout time.Duration) sink {
+	client := &http.Client{Timeout: timeout}
+	switch sc.Type {
+	case SinkKafka:
+		return &kafkaSink{
+			sinkName: sc.Name,
+			client:   client,
+			url:      strings.TrimSuffix(sc.URL, "/") + "/topics/" + url.PathEscape(sc.Topic),
+			headers:  sc.Headers,
+		}
+	default:
+		return &webhookSink{
+			sinkName: sc.Name,
+			client:   client,
+			url:      sc.URL,
+			headers:  sc.Headers,
+		}
+	}
+}
+
+// webhookSink POSTs every event to an URL. Any 2xx response acknowledges
+// the event.
+type webhookSink struct {
+	sinkName string
+	client   *http.Client
+	url      string
+	headers  map[string]string
+}
+
+func (s *webhookSink) name() string {
+	return s.sinkName
+}
+
+func (s *webhookSink) deliver(ctx context.Context, event *Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return post(ctx, s.client, s.url, "application/json", s.headers, body, nil)
+}
+
+// kafkaSink produces every event to a topic through the v2 API of a Kafka
+// REST Proxy. Events are keyed by "<cell>/<path>", so the changes to a
+// file land in the same partition and keep their order.
+type kafkaSink struct {
+	sinkName string
+	client   *http.Client
+	url      string
+	headers  map[string]string
+}
+
+type kafkaRecord struct {
+	Key   string `json:"key"`
+	Value *Event `json:"value"`
+}
+
+type kafkaProduceRequest struct {
+	Records []kafkaRecord `json:"records"`
+}
+
+type kafkaProduceResponse struct {
+	Offsets []struct {
+		Partition *int32 `json:"partition"`
+		Offset    *int64 `json:"offset"`
+		ErrorCode *int   `json:"error_code"`
+		Error     string `json:"error"`
+	} `json:"offsets"`
+}
+
+func (s *kafkaSink) name() string {
+	return s.sinkName
+}
+
+func (s *kafkaSink) deliver(ctx context.Context, event *Event) error {
+	body, err := json.Marshal(&kafkaProduceRequest{
+		Records: []kafkaRecord{{Key: event.Cell + "/" + event.Path, Value: event}},
+	})
+	if err != nil {
+		return err
+	}
+
+	var response kafkaProduceResponse
+	if err := post(ctx, s.client, s.url, "application/vnd.kafka.json.v2+json", s.headers, body, &response); err != nil {
+		return err
+	}
+	// The proxy returns 200 even if the record could not be produced.
+	for _, offset := range response.Offsets {
+		if offset.ErrorCode != nil || offset.Error != "" {
+			return fmt.Errorf("kafka rest proxy could not produce the record: %v", offset.Error)
+		}
+	}
+	return nil
+}
+
+// post POSTs body to url, and decodes the JSON response into response if it
+// is not nil.
+func post(ctx context.Context, client *http.Client, url, contentType string, headers map[string]string, body []byte, response any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("POST %v: %v: %s", url, resp.Status, bytes.TrimSpace(data))
+	}
+	if response != nil {
+		if err := json.Unmarshal(data, response); err != nil {
+			return fmt.Errorf("POST %v: cannot parse response: %w", url, err)
+		}
+	}
+	return nil
+}
+
+// queuedSink buffers the events of a sink, and delivers them one at a time,
+// retrying until they are acknowledged.
+type queuedSink struct {
+	sink          sink
+	queue         chan *Event
+	retryDelay    time.Duration
+	maxRetryDelay time.Duration
+}
+
+func newQueuedSink(s sink, config *Config) *queuedSink {
+	return &queuedSink{
+		sink:          s,
+		queue:         make(chan *Event, config.QueueSize),
+		retryDelay:    time.Duration(config.RetryDelay),
+		maxRetryDelay: time.Duration(config.MaxRetryDelay),
+	}
+}
+
+// enqueue adds an event to the queue. It blocks while the queue is full, and
+// returns false if ctx is done first.
+func (qs *queuedSink) enqueue(ctx context.Context, event *Event) bool {
+	select {
+	case qs.queue <- event:
+		queueLengthGauge.Add(qs.sink.name(), 1)
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// run delivers the queued events until ctx is done.
+func (qs *queuedSink) run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event := <-qs.queue:
+			queueLengthGauge.Add(qs.sink.name(), -1)
+			if !qs.deliver(ctx, event) {
+				return
+			}
+			eventsCounter.Add([]string{qs.sink.name(), event.Change}, 1)
+		}
+	}
+}
+
+// deliver delivers an event, retrying with an exponential backoff. It
+// returns false if ctx is done before the event is delivered.
+func (qs *queuedSink) deliver(ctx context.Context, event *Event) bool {
+	delay := qs.retryDelay
+	for {
+		err := qs.sink.deliver(ctx, event)
+		if err == nil {
+			return true
+		}
+		if ctx.Err() != nil {
+			return false
+		}
+
+		deliveryErrorsCounter.Add(qs.sink.name(), 1)
+		deliveryErrorsLogger.Warningf("Topo bridge cannot deliver the change of %v:%v to sink %v, retrying in %v: %v", event.Cell, event.Path, qs.sink.name(), delay, err)
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(delay):
+		}
+		delay = min(2*delay, qs.maxRetryDelay)
+	}
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topocount
+
+import (
+	"context"
+	"time"
+
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/internal/periodic"
+)
+
+var (
+	countsCounter = stats.NewCountersWithSingleLabel(
+		"TopoObjectCountRuns",
+		"Counts of the topo objects, by result",
+		"Result")
+	objectsGauge = stats.NewGaugesWithMultiLabels(
+		"TopoObjects",
+		"Topo objects found by the last count, by record type and cell",
+		[]string{"Type", "Cell"})
+)
+
+// Config configures a Collector.
+type Config struct {
+	// Interval is the time between counts.
+	Interval time.Duration
+	// Timeout bounds the time taken by a count.
+	Timeout time.Duration
+}
+
+// Collector periodically counts the topo objects, and exports the counts as
+// the TopoObjects gauges.
+type Collector struct {
+	ts     *topo.Server
+	config Config
+	runner *periodic.Runner
+
+	// reported are the gauges set by the last count, to reset the ones
+	// the next count doesn't find anymore, e.g. of a deleted cell.
+	reported map[Type]map[string]bool
+}
+
+// NewCollector returns a collector for the given config.
+func NewCollector(ts *topo.Server, config Config) *Collector {
+	c := &Collector{
+		ts:     ts,
+		config: config,
+	}
+	c.runner = periodic.NewRunner(config.Interval, config.Timeout, c.runOnce)
+	return c
+}
+
+// Start starts counting, the first time right away.
+func (c *Collector) Start() {
+	c.runner.Start()
+	log.Infof("Counting the topo objects every %v", c.config.Interval)
+}
+
+// Stop stops counting, and waits for the count in progress, if any, to be
+// interrupted.
+func (c *Collector) Stop() {
+	c.runner.Stop()
+}
+
+// runOnce counts the topo objects and updates the gauges. Errors are logged
+// and counted, and the gauges keep the previous counts until the next run.
+func (c *Collector) runOnce(ctx context.Context) {
+	counts, err := Count(ctx, c.ts)
+	if err != nil {
+		countsCounter.Add("Failure", 1)
+		log.Errorf("Topo object count failed: %v", err)
+		return
+	}
+	countsCounter.Add("Success", 1)
+
+	for typ, cells := range c.reported {
+		for cell := range cells {
+			if _, ok := counts[typ][cell]; !ok {
+				objectsGauge.Set([]string{string(typ), cell}, 0)
+			}
+		}
+	}
+	c.reported = make(map[Type]map[string]bool, len(counts))
+	for typ, cells := range counts {
+		c.reported[typ] = make(map[string]bool, len(cells))
+		for cell, n := range cells {
+			objectsGauge.Set([]string{string(typ), cell}, n)
+			c.reported[typ][cell] = true
+		}
+	}
+}
@@ -0,0 +1,122 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package topocount counts the objects of the topo server by record type,
+// for capacity planning and to detect runaway object creation.
+package topocount
+
+import (
+	"context"
+	"fmt"
+	"path"
+
+	"vitess.io/vitess/go/vt/topo"
+)
+
+// Type is a type of topo record.
+type Type string
+
+const (
+	// Keyspace is a keyspace of the global cell.
+	Keyspace Type = "Keyspace"
+	// Shard is a shard of the global cell.
+	Shard Type = "Shard"
+	// VSchema is the vschema of a keyspace served in the SrvVSchema of a cell.
+	VSchema Type = "VSchema"
+	// Lock is a lock held on a keyspace, a shard or the routing rules,
+	// exclusive or shared.
+	Lock Type = "Lock"
+	// Tablet is a tablet of a cell.
+	Tablet Type = "Tablet"
+)
+
+// Types are all the types of topo records counted.
+var Types = []Type{Keyspace, Shard, VSchema, Lock, Tablet}
+
+// Counts are the numbers of topo records, by type and cell. The records of
+// the global cell are counted under topo.GlobalCell.
+type Counts map[Type]map[string]int64
+
+func (c Counts) add(typ Type, cell string, n int64) {
+	if c[typ] == nil {
+		c[typ] = make(map[string]int64)
+	}
+	c[typ][cell] += n
+}
+
+// Count returns the numbers of records of the global cell and of every cell
+// it knows about. The vschemas are counted from the SrvVSchema of each cell,
+// which holds them all, rather than read one by one from the global cell.
+func Count(ctx context.Context, ts *topo.Server) (Counts, error) {
+	counts := make(Counts, len(Types))
+	// The global records are counted even when there are none.
+	for _, typ := range []Type{Keyspace, Shard, Lock} {
+		counts.add(typ, topo.GlobalCell, 0)
+	}
+
+	lockPaths := []string{topo.RoutingRulesPath}
+	keyspaces, err := ts.GetKeyspaces(ctx)
+	if err != nil {
+		return nil, err
+	}
+	counts.add(Keyspace, topo.GlobalCell, int64(len(keyspaces)))
+	for _, keyspace := range keyspaces {
+		lockPaths = append(lockPaths, path.Join(topo.KeyspacesPath, keyspace), topo.KeyspaceRoutingLockPath(keyspace))
+
+		shards, err := ts.GetShardNames(ctx, keyspace)
+		if err != nil {
+			if topo.IsErrType(err, topo.NoNode) {
+				continue
+			}
+			return nil, err
+		}
+		counts.add(Shard, topo.GlobalCell, int64(len(shards)))
+		for _, shard := range shards {
+			lockPaths = append(lockPaths, path.Join(topo.KeyspacesPath, keyspace, topo.ShardsPath, shard))
+		}
+	}
+
+	for _, lockPath := range lockPaths {
+		locks, err := ts.GetHeldLocks(ctx, lockPath)
+		if err != nil {
+			return nil, err
+		}
+		counts.add(Lock, topo.GlobalCell, int64(len(locks)))
+	}
+
+	cells, err := ts.GetCellInfoNames(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, cell := range cells {
+		aliases, err := ts.GetTabletAliasesByCell(ctx, cell)
+		if err != nil {
+			return nil, fmt.Errorf("cannot count the tablets of cell %v: %w", cell, err)
+		}
+		counts.add(Tablet, cell, int64(len(aliases)))
+
+		srvVSchema, err := ts.GetSrvVSchema(ctx, cell)
+		switch {
+		case err == nil:
+			counts.add(VSchema, cell, int64(len(srvVSchema.Keyspaces)))
+		case topo.IsErrType(err, topo.NoNode):
+			counts.add(VSchema, cell, 0)
+		default:
+			return nil, fmt.Errorf("cannot count the vschemas of cell %v: %w", cell, err)
+		}
+	}
+	return counts, nil
+}
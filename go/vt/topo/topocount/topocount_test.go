@@ -0,0 +1,87 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topocount
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
+)
+
+func TestCount(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "zone1", "zone2")
+	defer ts.Close()
+
+	counts, err := Count(ctx, ts)
+	require.NoError(t, err)
+	assert.Equal(t, Counts{
+		Keyspace: {topo.GlobalCell: 0},
+		Shard:    {topo.GlobalCell: 0},
+		VSchema:  {"zone1": 0, "zone2": 0},
+		Lock:     {topo.GlobalCell: 0},
+		Tablet:   {"zone1": 0, "zone2": 0},
+	}, counts)
+
+	require.NoError(t, ts.CreateKeyspace(ctx, "ks1", &topodatapb.Keyspace{}))
+	require.NoError(t, ts.SaveVSchema(ctx, "ks1", &vschemapb.Keyspace{}))
+	require.NoError(t, ts.CreateShard(ctx, "ks1", "-80"))
+	require.NoError(t, ts.CreateShard(ctx, "ks1", "80-"))
+	require.NoError(t, ts.CreateKeyspace(ctx, "ks2", &topodatapb.Keyspace{}))
+	require.NoError(t, ts.CreateShard(ctx, "ks2", "0"))
+	for _, alias := range []*topodatapb.TabletAlias{{Cell: "zone1", Uid: 100}, {Cell: "zone1", Uid: 101}, {Cell: "zone2", Uid: 200}} {
+		require.NoError(t, ts.CreateTablet(ctx, &topodatapb.Tablet{Alias: alias, Keyspace: "ks1", Shard: "-80"}))
+	}
+	require.NoError(t, ts.RebuildSrvVSchema(ctx, []string{"zone1"}))
+	_, unlock, err := ts.LockShard(ctx, "ks1", "-80", "reparent")
+	require.NoError(t, err)
+	defer unlock(&err)
+
+	counts, err = Count(ctx, ts)
+	require.NoError(t, err)
+	assert.Equal(t, Counts{
+		Keyspace: {topo.GlobalCell: 2},
+		Shard:    {topo.GlobalCell: 3},
+		VSchema:  {"zone1": 2, "zone2": 0},
+		Lock:     {topo.GlobalCell: 1},
+		Tablet:   {"zone1": 2, "zone2": 1},
+	}, counts)
+}
+
+func TestCollector(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "zone1")
+	defer ts.Close()
+
+	require.NoError(t, ts.CreateKeyspace(ctx, "ks1", &topodatapb.Keyspace{}))
+
+	c := NewCollector(ts, Config{Interval: time.Hour})
+	c.runOnce(ctx)
+	assert.Equal(t, int64(1), objectsGauge.Counts()["Keyspace.global"])
+	assert.Equal(t, int64(0), objectsGauge.Counts()["Tablet.zone1"])
+}
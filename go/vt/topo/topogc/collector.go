@@ -0,0 +1,119 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topogc
+
+import (
+	"context"
+	"time"
+
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/internal/periodic"
+)
+
+var (
+	scansCounter = stats.NewCountersWithSingleLabel(
+		"TopoGCScans",
+		"Scans of the topo for orphaned objects, by result",
+		"Result")
+	orphansGauge = stats.NewGaugesWithSingleLabel(
+		"TopoGCOrphans",
+		"Orphaned topo objects found by the last scan, by kind",
+		"Kind")
+	prunedCounter = stats.NewCountersWithSingleLabel(
+		"TopoGCPruned",
+		"Orphaned topo objects pruned, by kind",
+		"Kind")
+)
+
+// Config configures a Collector.
+type Config struct {
+	// Interval is the time between scans.
+	Interval time.Duration
+	// Prune is whether to delete the orphaned objects found. Otherwise, the
+	// collector only reports them, in the logs and the metrics.
+	Prune bool
+	// Timeout bounds the time taken by a scan and its pruning.
+	Timeout time.Duration
+}
+
+// Collector periodically scans the topo for orphaned objects, and prunes
+// them if configured to.
+type Collector struct {
+	ts     *topo.Server
+	config Config
+	runner *periodic.Runner
+}
+
+// NewCollector returns a collector for the given config.
+func NewCollector(ts *topo.Server, config Config) *Collector {
+	c := &Collector{
+		ts:     ts,
+		config: config,
+	}
+	c.runner = periodic.NewRunner(config.Interval, config.Timeout, c.runOnce)
+	return c
+}
+
+// Start starts scanning, the first time right away.
+func (c *Collector) Start() {
+	c.runner.Start()
+	log.Infof("Scanning the topo for orphaned objects every %v (prune: %v)", c.config.Interval, c.config.Prune)
+}
+
+// Stop stops scanning, and waits for the scan in progress, if any, to be
+// interrupted.
+func (c *Collector) Stop() {
+	c.runner.Stop()
+}
+
+// runOnce scans the topo and prunes the orphans found if configured to.
+// Errors are logged and counted, and the next run tries again.
+func (c *Collector) runOnce(ctx context.Context) {
+	orphans, err := Scan(ctx, c.ts)
+	if err != nil {
+		scansCounter.Add("Failure", 1)
+		log.Errorf("Topo orphan scan failed: %v", err)
+		return
+	}
+	scansCounter.Add("Success", 1)
+
+	counts := make(map[Kind]int64, len(Kinds))
+	for _, orphan := range orphans {
+		counts[orphan.Kind]++
+	}
+	for _, kind := range Kinds {
+		orphansGauge.Set(string(kind), counts[kind])
+	}
+
+	for _, orphan := range orphans {
+		if !c.config.Prune {
+			log.Infof("Found orphaned topo object %v", orphan)
+			continue
+		}
+		pruned, err := Prune(ctx, c.ts, orphan)
+		if err != nil {
+			log.Errorf("Cannot prune orphaned topo object %v: %v", orphan, err)
+			continue
+		}
+		if pruned {
+			prunedCounter.Add(string(orphan.Kind), 1)
+			log.Infof("Pruned orphaned topo object %v", orphan)
+		}
+	}
+}
@@ -0,0 +1,255 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package topogc finds the orphaned objects of the topo server, which the
+// objects they belong to outlived, and prunes them.
+//
+// Orphaned objects are left behind by operations interrupted half-way, or by
+// manual edits of the topo, and pile up: replication graph entries of deleted
+// tablets, replication graphs of deleted shards, shards of deleted keyspaces,
+// and the SrvKeyspace records of deleted keyspaces.
+package topogc
+
+import (
+	"context"
+	"fmt"
+	"path"
+
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+// Kind is a kind of orphaned topo object.
+type Kind string
+
+const (
+	// ShardReplicationNode is an entry of the replication graph of a shard
+	// in a cell, whose tablet doesn't exist.
+	ShardReplicationNode Kind = "ShardReplicationNode"
+	// ShardReplication is the replication graph of a shard in a cell, whose
+	// shard doesn't exist.
+	ShardReplication Kind = "ShardReplication"
+	// Shard is a shard whose keyspace doesn't exist.
+	Shard Kind = "Shard"
+	// SrvKeyspace is the serving graph of a keyspace in a cell, whose
+	// keyspace doesn't exist.
+	SrvKeyspace Kind = "SrvKeyspace"
+)
+
+// Kinds are all the kinds of orphaned topo objects.
+var Kinds = []Kind{ShardReplicationNode, ShardReplication, Shard, SrvKeyspace}
+
+// Orphan is an orphaned topo object.
+type Orphan struct {
+	Kind Kind
+	// Cell is the cell of the object, unless it is a Shard.
+	Cell     string
+	Keyspace string
+	// Shard is the shard of the object, unless it is a SrvKeyspace.
+	Shard string
+	// TabletAlias is the tablet of a ShardReplicationNode.
+	TabletAlias *topodatapb.TabletAlias
+}
+
+// String is part of the fmt.Stringer interface.
+func (o *Orphan) String() string {
+	switch o.Kind {
+	case ShardReplicationNode:
+		return fmt.Sprintf("%v %v/%v in cell %v for tablet %v", o.Kind, o.Keyspace, o.Shard, o.Cell, topoproto.TabletAliasString(o.TabletAlias))
+	case ShardReplication:
+		return fmt.Sprintf("%v %v/%v in cell %v", o.Kind, o.Keyspace, o.Shard, o.Cell)
+	case Shard:
+		return fmt.Sprintf("%v %v/%v", o.Kind, o.Keyspace, o.Shard)
+	default:
+		return fmt.Sprintf("%v %v in cell %v", o.Kind, o.Keyspace, o.Cell)
+	}
+}
+
+// Scan returns the orphaned objects of the global cell and of every cell it
+// knows about.
+func Scan(ctx context.Context, ts *topo.Server) ([]*Orphan, error) {
+	var orphans []*Orphan
+
+	keyspaces, err := ts.GetKeyspaces(ctx)
+	if err != nil {
+		return nil, err
+	}
+	keyspaceExists := make(map[string]bool, len(keyspaces))
+	for _, keyspace := range keyspaces {
+		exists, err := exists(ts.GetKeyspace(ctx, keyspace))
+		if err != nil {
+			return nil, err
+		}
+		keyspaceExists[keyspace] = exists
+		if exists {
+			continue
+		}
+		shards, err := ts.GetShardNames(ctx, keyspace)
+		if err != nil && !topo.IsErrType(err, topo.NoNode) {
+			return nil, err
+		}
+		for _, shard := range shards {
+			orphans = append(orphans, &Orphan{Kind: Shard, Keyspace: keyspace, Shard: shard})
+		}
+	}
+
+	cells, err := ts.GetCellInfoNames(ctx)
+	if err != nil {
+		return nil, err
+	}
+	shardExists := make(map[string]bool)
+	for _, cell := range cells {
+		cellOrphans, err := scanCell(ctx, ts, cell, keyspaceExists, shardExists)
+		if err != nil {
+			return nil, fmt.Errorf("cannot scan cell %v: %w", cell, err)
+		}
+		orphans = append(orphans, cellOrphans...)
+	}
+	return orphans, nil
+}
+
+// scanCell returns the orphaned objects of a cell. keyspaceExists has all
+// the keyspaces of the global cell, and shardExists caches which shards
+// exist across cells.
+func scanCell(ctx context.Context, ts *topo.Server, cell string, keyspaceExists, shardExists map[string]bool) ([]*Orphan, error) {
+	conn, err := ts.ConnForCell(ctx, cell)
+	if err != nil {
+		return nil, err
+	}
+	aliases, err := ts.GetTabletAliasesByCell(ctx, cell)
+	if err != nil {
+		return nil, err
+	}
+	tabletExists := make(map[string]bool, len(aliases))
+	for _, alias := range aliases {
+		tabletExists[topoproto.TabletAliasString(alias)] = true
+	}
+
+	// The serving graph and the replication graphs of a keyspace are both
+	// under keyspaces/<keyspace> in the cell.
+	keyspaces, err := ts.GetSrvKeyspaceNames(ctx, cell)
+	if err != nil {
+		return nil, err
+	}
+	var orphans []*Orphan
+	for _, keyspace := range keyspaces {
+		if !keyspaceExists[keyspace] {
+			exists, err := exists(ts.GetSrvKeyspace(ctx, cell, keyspace))
+			if err != nil {
+				return nil, err
+			}
+			if exists {
+				orphans = append(orphans, &Orphan{Kind: SrvKeyspace, Cell: cell, Keyspace: keyspace})
+			}
+		}
+
+		shards, err := conn.ListDir(ctx, path.Join(topo.KeyspacesPath, keyspace, topo.ShardsPath), false /*full*/)
+		if err != nil {
+			if topo.IsErrType(err, topo.NoNode) {
+				continue
+			}
+			return nil, err
+		}
+		for _, entry := range shards {
+			shard := entry.Name
+			sri, err := ts.GetShardReplication(ctx, cell, keyspace, shard)
+			if err != nil {
+				if topo.IsErrType(err, topo.NoNode) {
+					continue
+				}
+				return nil, err
+			}
+
+			// The shards of a deleted keyspace are orphans themselves, so
+			// their replication graphs are orphaned too.
+			key := topoproto.KeyspaceShardString(keyspace, shard)
+			if _, ok := shardExists[key]; !ok && keyspaceExists[keyspace] {
+				shardExists[key], err = exists(ts.GetShard(ctx, keyspace, shard))
+				if err != nil {
+					return nil, err
+				}
+			}
+			if !shardExists[key] {
+				orphans = append(orphans, &Orphan{Kind: ShardReplication, Cell: cell, Keyspace: keyspace, Shard: shard})
+				continue
+			}
+
+			for _, node := range sri.Nodes {
+				if !tabletExists[topoproto.TabletAliasString(node.TabletAlias)] {
+					orphans = append(orphans, &Orphan{Kind: ShardReplicationNode, Cell: cell, Keyspace: keyspace, Shard: shard, TabletAlias: node.TabletAlias})
+				}
+			}
+		}
+	}
+	return orphans, nil
+}
+
+// Prune deletes an orphaned object, after checking again that it is still
+// orphaned, as the object it belongs to may have been created since the
+// scan. It returns whether it deleted the object.
+func Prune(ctx context.Context, ts *topo.Server, orphan *Orphan) (bool, error) {
+	switch orphan.Kind {
+	case ShardReplicationNode:
+		tabletExists, err := exists(ts.GetTablet(ctx, orphan.TabletAlias))
+		if err != nil || tabletExists {
+			return false, err
+		}
+		return true, topo.RemoveShardReplicationRecord(ctx, ts, orphan.Cell, orphan.Keyspace, orphan.Shard, orphan.TabletAlias)
+	case ShardReplication:
+		// Shards are pruned before their replication graphs, as they are
+		// found first.
+		shardExists, err := exists(ts.GetShard(ctx, orphan.Keyspace, orphan.Shard))
+		if err != nil || shardExists {
+			return false, err
+		}
+		return true, ignoreNoNode(ts.DeleteShardReplication(ctx, orphan.Cell, orphan.Keyspace, orphan.Shard))
+	case Shard:
+		keyspaceExists, err := exists(ts.GetKeyspace(ctx, orphan.Keyspace))
+		if err != nil || keyspaceExists {
+			return false, err
+		}
+		return true, ignoreNoNode(ts.DeleteShard(ctx, orphan.Keyspace, orphan.Shard))
+	case SrvKeyspace:
+		keyspaceExists, err := exists(ts.GetKeyspace(ctx, orphan.Keyspace))
+		if err != nil || keyspaceExists {
+			return false, err
+		}
+		return true, ignoreNoNode(ts.DeleteSrvKeyspace(ctx, orphan.Cell, orphan.Keyspace))
+	default:
+		return false, fmt.Errorf("unknown kind of orphaned object %v", orphan.Kind)
+	}
+}
+
+// exists returns whether the object read with the given error exists.
+func exists[T any](_ T, err error) (bool, error) {
+	switch {
+	case err == nil:
+		return true, nil
+	case topo.IsErrType(err, topo.NoNode):
+		return false, nil
+	default:
+		return false, err
+	}
+}
+
+func ignoreNoNode(err error) error {
+	if topo.IsErrType(err, topo.NoNode) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topogc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+func orphanStrings(orphans []*Orphan) []string {
+	var out []string
+	for _, orphan := range orphans {
+		out = append(out, orphan.String())
+	}
+	return out
+}
+
+func TestScanAndPrune(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "zone1")
+	defer ts.Close()
+
+	require.NoError(t, ts.CreateKeyspace(ctx, "ks", &topodatapb.Keyspace{}))
+	require.NoError(t, ts.CreateShard(ctx, "ks", "-"))
+	require.NoError(t, ts.UpdateSrvKeyspace(ctx, "zone1", "ks", &topodatapb.SrvKeyspace{}))
+	tablet := &topodatapb.Tablet{Alias: &topodatapb.TabletAlias{Cell: "zone1", Uid: 1}, Keyspace: "ks", Shard: "-"}
+	require.NoError(t, ts.CreateTablet(ctx, tablet))
+
+	// A healthy topo has no orphans.
+	orphans, err := Scan(ctx, ts)
+	require.NoError(t, err)
+	assert.Empty(t, orphans)
+
+	// Orphan a replication graph entry.
+	gone := &topodatapb.TabletAlias{Cell: "zone1", Uid: 2}
+	require.NoError(t, topo.UpdateShardReplicationRecord(ctx, ts, "ks", "-", gone))
+
+	// Orphan the replication graph and the shard of a deleted keyspace, and
+	// its SrvKeyspace.
+	require.NoError(t, ts.CreateKeyspace(ctx, "deleted", &topodatapb.Keyspace{}))
+	require.NoError(t, ts.CreateShard(ctx, "deleted", "-80"))
+	require.NoError(t, ts.UpdateSrvKeyspace(ctx, "zone1", "deleted", &topodatapb.SrvKeyspace{}))
+	require.NoError(t, topo.UpdateShardReplicationRecord(ctx, ts, "deleted", "-80", gone))
+	conn, err := ts.ConnForCell(ctx, topo.GlobalCell)
+	require.NoError(t, err)
+	require.NoError(t, conn.Delete(ctx, "keyspaces/deleted/Keyspace", nil))
+
+	orphans, err = Scan(ctx, ts)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{
+		"Shard deleted/-80",
+		"SrvKeyspace deleted in cell zone1",
+		"ShardReplication deleted/-80 in cell zone1",
+		"ShardReplicationNode ks/- in cell zone1 for tablet zone1-0000000002",
+	}, orphanStrings(orphans))
+
+	// An object that is no longer orphaned is not pruned.
+	require.NoError(t, ts.CreateTablet(ctx, &topodatapb.Tablet{Alias: gone, Keyspace: "ks", Shard: "-"}))
+	for _, orphan := range orphans {
+		pruned, err := Prune(ctx, ts, orphan)
+		require.NoError(t, err)
+		assert.Equal(t, orphan.Kind != ShardReplicationNode, pruned, orphan.String())
+	}
+
+	// The replication graph of the shard was orphaned, but not its entry,
+	// which got pruned with it.
+	orphans, err = Scan(ctx, ts)
+	require.NoError(t, err)
+	assert.Empty(t, orphanStrings(orphans))
+
+	sri, err := ts.GetShardReplication(ctx, "zone1", "ks", "-")
+	require.NoError(t, err)
+	assert.Len(t, sri.Nodes, 2)
+	_, err = ts.GetShard(ctx, "deleted", "-80")
+	assert.True(t, topo.IsErrType(err, topo.NoNode), "expected NoNode, got %v", err)
+}
@@ -48,3 +48,9 @@ func KeyspaceTypeString(kt topodatapb.KeyspaceType) string {
 func KeyspaceTypeLString(kt topodatapb.KeyspaceType) string {
 	return strings.ToLower(KeyspaceTypeString(kt))
 }
+
+// KeyspaceHasLabels returns true if the keyspace has all the given labels,
+// with the same values.
+func KeyspaceHasLabels(ks *topodatapb.Keyspace, labels map[string]string) bool {
+	return HasLabels(ks.GetLabels(), labels)
+}
@@ -0,0 +1,35 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topoproto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+func TestKeyspaceHasLabels(t *testing.T) {
+	ks := &topodatapb.Keyspace{Labels: map[string]string{"owner": "payments", "tier": "1"}}
+	assert.True(t, KeyspaceHasLabels(ks, nil))
+	assert.True(t, KeyspaceHasLabels(ks, map[string]string{"owner": "payments"}))
+	assert.True(t, KeyspaceHasLabels(ks, map[string]string{"owner": "payments", "tier": "1"}))
+	assert.False(t, KeyspaceHasLabels(ks, map[string]string{"owner": "search"}))
+	assert.False(t, KeyspaceHasLabels(ks, map[string]string{"region": ""}))
+	assert.False(t, KeyspaceHasLabels(&topodatapb.Keyspace{}, map[string]string{"owner": "payments"}))
+}
@@ -0,0 +1,52 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topoproto
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// MaxLabelValueLength is the maximum length of the value of a keyspace or
+// shard label.
+const MaxLabelValueLength = 256
+
+var labelKeyRegexp = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9._/-]{0,61}[A-Za-z0-9])?$`)
+
+// ValidateLabel returns an error if key is not a valid label key, or value
+// is not a valid label value. Keys are up to 63 alphanumeric characters,
+// '.', '_', '/' and '-', starting and ending with an alphanumeric character.
+func ValidateLabel(key string, value string) error {
+	if !labelKeyRegexp.MatchString(key) {
+		return fmt.Errorf("invalid label key %q", key)
+	}
+	if len(value) > MaxLabelValueLength {
+		return fmt.Errorf("the value of label %v is longer than %d characters", key, MaxLabelValueLength)
+	}
+	return nil
+}
+
+// HasLabels returns true if labels has all the labels of selector, with the
+// same values.
+func HasLabels(labels map[string]string, selector map[string]string) bool {
+	for key, value := range selector {
+		if v, ok := labels[key]; !ok || v != value {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,35 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topoproto
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateLabel(t *testing.T) {
+	for _, key := range []string{"owner", "team.io/cost-center", "a", "tier_1"} {
+		assert.NoError(t, ValidateLabel(key, "value"), key)
+	}
+	for _, key := range []string{"", "-owner", "owner/", "own er", strings.Repeat("a", 64)} {
+		assert.Error(t, ValidateLabel(key, "value"), key)
+	}
+	assert.NoError(t, ValidateLabel("owner", ""))
+	assert.Error(t, ValidateLabel("owner", strings.Repeat("a", MaxLabelValueLength+1)))
+}
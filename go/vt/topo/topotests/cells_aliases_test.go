@@ -22,6 +22,7 @@ import (
 	"sort"
 	"testing"
 
+	"vitess.io/vitess/go/vt/topo"
 	"vitess.io/vitess/go/vt/topo/memorytopo"
 
 	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
@@ -150,3 +151,97 @@ func TestCellsAliases(t *testing.T) {
 		t.Fatalf("UpdateCellsAlias should fail, got nil")
 	}
 }
+
+func TestAnalyzeCellsAliasChange(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "cell1", "cell2", "cell3")
+	defer ts.Close()
+
+	replica := &topodatapb.SrvKeyspace{
+		Partitions: []*topodatapb.SrvKeyspace_KeyspacePartition{{
+			ServedType:      topodatapb.TabletType_REPLICA,
+			ShardReferences: []*topodatapb.ShardReference{{Name: "-"}},
+		}},
+	}
+	primary := &topodatapb.SrvKeyspace{
+		Partitions: []*topodatapb.SrvKeyspace_KeyspacePartition{{
+			ServedType:      topodatapb.TabletType_PRIMARY,
+			ShardReferences: []*topodatapb.ShardReference{{Name: "-"}},
+		}},
+	}
+	if err := ts.UpdateSrvKeyspace(ctx, "cell1", "ks1", replica); err != nil {
+		t.Fatalf("UpdateSrvKeyspace failed: %v", err)
+	}
+	if err := ts.UpdateSrvKeyspace(ctx, "cell3", "ks2", replica); err != nil {
+		t.Fatalf("UpdateSrvKeyspace failed: %v", err)
+	}
+	if err := ts.UpdateSrvKeyspace(ctx, "cell3", "ks3", primary); err != nil {
+		t.Fatalf("UpdateSrvKeyspace failed: %v", err)
+	}
+	if err := ts.CreateCellsAlias(ctx, "alias", &topodatapb.CellsAlias{Cells: []string{"cell1", "cell2"}}); err != nil {
+		t.Fatalf("CreateCellsAlias failed: %v", err)
+	}
+
+	// Swapping cell2 for cell3 affects the keyspaces serving replica traffic
+	// in all three, but not the ones only served by primaries.
+	impact, err := ts.AnalyzeCellsAliasChange(ctx, "alias", []string{"cell3", "cell1"})
+	if err != nil {
+		t.Fatalf("AnalyzeCellsAliasChange failed: %v", err)
+	}
+	want := &topo.CellsAliasImpact{
+		AddedCells:        []string{"cell3"},
+		RemovedCells:      []string{"cell2"},
+		AffectedKeyspaces: []string{"ks1", "ks2"},
+	}
+	if !reflect.DeepEqual(impact, want) {
+		t.Fatalf("Expected impact to be: %+v, got %+v", want, impact)
+	}
+
+	// An unchanged alias affects nothing.
+	impact, err = ts.AnalyzeCellsAliasChange(ctx, "alias", []string{"cell2", "cell1"})
+	if err != nil {
+		t.Fatalf("AnalyzeCellsAliasChange failed: %v", err)
+	}
+	if !reflect.DeepEqual(impact, &topo.CellsAliasImpact{}) {
+		t.Fatalf("Expected no impact, got %+v", impact)
+	}
+
+	// A new alias of a single cell routes nothing across cells.
+	impact, err = ts.AnalyzeCellsAliasChange(ctx, "alias2", []string{"cell3"})
+	if err != nil {
+		t.Fatalf("AnalyzeCellsAliasChange failed: %v", err)
+	}
+	want = &topo.CellsAliasImpact{
+		AddedCells: []string{"cell3"},
+	}
+	if !reflect.DeepEqual(impact, want) {
+		t.Fatalf("Expected impact to be: %+v, got %+v", want, impact)
+	}
+
+	// The analysis does not change the alias.
+	ca, err := ts.GetCellsAlias(ctx, "alias", true /*strongRead*/)
+	if err != nil {
+		t.Fatalf("GetCellsAlias failed: %v", err)
+	}
+	if !reflect.DeepEqual(ca.Cells, []string{"cell1", "cell2"}) {
+		t.Fatalf("Expected alias to be unchanged, got %v", ca)
+	}
+
+	// Validation rejects unknown, duplicate and overlapping cells, as well as
+	// aliases named after a cell.
+	for name, cells := range map[string][]string{
+		"alias":  {"cell1", "cell4"},
+		"alias2": {"cell3", "cell3"},
+		"alias3": {"cell2"},
+		"cell3":  {"cell3"},
+		"alias4": nil,
+	} {
+		if err := ts.ValidateCellsAlias(ctx, name, &topodatapb.CellsAlias{Cells: cells}); err == nil {
+			t.Fatalf("ValidateCellsAlias(%v, %v) should fail, got nil", name, cells)
+		}
+	}
+	if err := ts.ValidateCellsAlias(ctx, "alias", &topodatapb.CellsAlias{Cells: []string{"cell1", "cell3"}}); err != nil {
+		t.Fatalf("ValidateCellsAlias failed: %v", err)
+	}
+}
@@ -303,9 +303,9 @@ func TestUpdateSrvKeyspacePartitions(t *testing.T) {
 		topo.NewShardInfo(keyspace, "80-", &topodatapb.Shard{KeyRange: rightKeyRange[0]}, nil),
 	}
 
-	ctx, unlock, err := ts.LockKeyspace(ctx, keyspace, "Locking for tests")
+	ctx, unlock, err := ts.LockKeyspaceRouting(ctx, keyspace, "Locking for tests")
 	if err != nil {
-		t.Fatalf("LockKeyspace() failed: %v", err)
+		t.Fatalf("LockKeyspaceRouting() failed: %v", err)
 	}
 	defer unlock(&err)
 
@@ -553,9 +553,9 @@ func TestUpdateUpdateDisableQueryService(t *testing.T) {
 		topo.NewShardInfo(keyspace, "80-", &topodatapb.Shard{KeyRange: rightKeyRange[0]}, nil),
 	}
 
-	ctx, unlock, err := ts.LockKeyspace(ctx, keyspace, "Locking for tests")
+	ctx, unlock, err := ts.LockKeyspaceRouting(ctx, keyspace, "Locking for tests")
 	if err != nil {
-		t.Fatalf("LockKeyspace() failed: %v", err)
+		t.Fatalf("LockKeyspaceRouting() failed: %v", err)
 	}
 	defer unlock(&err)
 
@@ -970,9 +970,9 @@ func TestMasterMigrateServedType(t *testing.T) {
 		t.Fatalf("CreateKeyspace() failed: %v", err)
 	}
 
-	ctx, unlock, err := ts.LockKeyspace(ctx, keyspace, "Locking for tests")
+	ctx, unlock, err := ts.LockKeyspaceRouting(ctx, keyspace, "Locking for tests")
 	if err != nil {
-		t.Fatalf("LockKeyspace() failed: %v", err)
+		t.Fatalf("LockKeyspaceRouting() failed: %v", err)
 	}
 	defer unlock(&err)
 
@@ -0,0 +1,188 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topo
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/key"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
+	"vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+var _ Conn = (*ValidatingConn)(nil)
+
+var topoValidationRejections = stats.NewCountersWithMultiLabels(
+	"TopologyValidationRejections",
+	"TopologyValidationRejections writes rejected because their contents are not valid for their path",
+	[]string{"Operation", "Cell"})
+
+// ValidatingConn is a Conn that checks the contents of the files written to
+// well-known paths, and rejects the writes that don't unmarshal into the
+// proto stored at the path, or that break basic invariants of the record,
+// like a Tablet whose alias isn't the one of its path. This catches records
+// edited by hand, e.g. with TopoCp, before they break the components that
+// read them.
+//
+// Writes to other paths go through unchecked.
+type ValidatingConn struct {
+	Conn
+	cell string
+}
+
+// NewValidatingConn returns a ValidatingConn.
+func NewValidatingConn(cell string, conn Conn) *ValidatingConn {
+	return &ValidatingConn{
+		Conn: conn,
+		cell: cell,
+	}
+}
+
+// Create is part of the Conn interface.
+func (vc *ValidatingConn) Create(ctx context.Context, filePath string, contents []byte) (Version, error) {
+	if err := ValidateContents(filePath, contents); err != nil {
+		topoValidationRejections.Add([]string{"Create", vc.cell}, 1)
+		return nil, err
+	}
+	return vc.Conn.Create(ctx, filePath, contents)
+}
+
+// Update is part of the Conn interface.
+func (vc *ValidatingConn) Update(ctx context.Context, filePath string, contents []byte, version Version) (Version, error) {
+	if err := ValidateContents(filePath, contents); err != nil {
+		topoValidationRejections.Add([]string{"Update", vc.cell}, 1)
+		return nil, err
+	}
+	return vc.Conn.Update(ctx, filePath, contents, version)
+}
+
+// LockNameWithTTL is part of the NamedLockConn interface.
+func (vc *ValidatingConn) LockNameWithTTL(ctx context.Context, dirPath, contents string, ttl time.Duration) (LockDescriptor, error) {
+	return lockNameWithTTL(ctx, vc.Conn, dirPath, contents, ttl)
+}
+
+// GetLock is part of the LockHolderConn interface.
+func (vc *ValidatingConn) GetLock(ctx context.Context, dirPath string) (*LockInfo, error) {
+	return getLock(ctx, vc.Conn, dirPath)
+}
+
+// ForceUnlock is part of the LockHolderConn interface.
+func (vc *ValidatingConn) ForceUnlock(ctx context.Context, dirPath, contents string) error {
+	return forceUnlock(ctx, vc.Conn, dirPath, contents)
+}
+
+// NewLeaderParticipationWithTTL is part of the LeaderParticipationTTLConn
+// interface.
+func (vc *ValidatingConn) NewLeaderParticipationWithTTL(name, id string, ttl time.Duration) (LeaderParticipation, error) {
+	return newLeaderParticipationWithTTL(vc.Conn, name, id, ttl)
+}
+
+// ValidateContents returns an error if the contents are not a valid record
+// for the well-known path they're written to. The path is relative to the
+// root of the cell, as for the Conn methods.
+func ValidateContents(filePath string, contents []byte) error {
+	msg, check := validatorForPath(filePath)
+	if msg == nil {
+		return nil
+	}
+	if err := proto.Unmarshal(contents, msg); err != nil {
+		return vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "invalid %v at %v: %v", msg.ProtoReflect().Descriptor().Name(), filePath, err)
+	}
+	if check == nil {
+		return nil
+	}
+	if err := check(); err != nil {
+		return vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "invalid %v at %v: %v", msg.ProtoReflect().Descriptor().Name(), filePath, err)
+	}
+	return nil
+}
+
+// validatorForPath returns the proto stored at a path, and a function that
+// checks its invariants once unmarshaled, if any. It returns a nil proto for
+// the paths it doesn't know about.
+func validatorForPath(filePath string) (proto.Message, func() error) {
+	dir, file := path.Split(path.Clean(filePath))
+	parts := strings.Split(strings.Trim(dir, "/"), "/")
+	if dir == "" {
+		parts = nil
+	}
+
+	switch {
+	case file == CellInfoFile && len(parts) == 2 && parts[0] == CellsPath:
+		return &topodatapb.CellInfo{}, nil
+	case file == CellsAliasFile && len(parts) == 2 && parts[0] == CellsAliasesPath:
+		return &topodatapb.CellsAlias{}, nil
+	case file == TabletFile && len(parts) == 2 && parts[0] == TabletsPath:
+		tablet := &topodatapb.Tablet{}
+		return tablet, func() error {
+			if alias := topoproto.TabletAliasString(tablet.Alias); alias != parts[1] {
+				return fmt.Errorf("alias %v does not match the path", alias)
+			}
+			return nil
+		}
+	case file == KeyspaceFile && len(parts) == 2 && parts[0] == KeyspacesPath:
+		return &topodatapb.Keyspace{}, nil
+	case file == VSchemaFile && len(parts) == 2 && parts[0] == KeyspacesPath:
+		vschema := &vschemapb.Keyspace{}
+		return vschema, func() error {
+			for name, table := range vschema.Tables {
+				for _, cv := range table.ColumnVindexes {
+					if _, ok := vschema.Vindexes[cv.Name]; !ok {
+						return fmt.Errorf("table %v refers to vindex %v, which is not defined", name, cv.Name)
+					}
+				}
+			}
+			return nil
+		}
+	case file == SrvKeyspaceFile && len(parts) == 2 && parts[0] == KeyspacesPath:
+		return &topodatapb.SrvKeyspace{}, nil
+	case file == ShardFile && len(parts) == 4 && parts[0] == KeyspacesPath && parts[2] == ShardsPath:
+		shard := &topodatapb.Shard{}
+		return shard, func() error {
+			_, keyRange, err := ValidateShardName(parts[3])
+			if err != nil {
+				return err
+			}
+			if !key.KeyRangeEqual(shard.KeyRange, keyRange) {
+				return fmt.Errorf("key range %v does not match the shard name", key.KeyRangeString(shard.KeyRange))
+			}
+			return nil
+		}
+	case file == ShardReplicationFile && len(parts) == 4 && parts[0] == KeyspacesPath && parts[2] == ShardsPath:
+		return &topodatapb.ShardReplication{}, nil
+	case file == SrvVSchemaFile && len(parts) == 0:
+		return &vschemapb.SrvVSchema{}, nil
+	case file == RoutingRulesFile && len(parts) == 0:
+		return &vschemapb.RoutingRules{}, nil
+	case file == ShardRoutingRulesFile && len(parts) == 0:
+		return &vschemapb.ShardRoutingRules{}, nil
+	case file == CommonRoutingRulesFile && len(parts) == 2 && parts[0] == RoutingRulesPath && parts[1] == KeyspaceRoutingRulesPath:
+		return &vschemapb.KeyspaceRoutingRules{}, nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topo_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	"vitess.io/vitess/go/vt/key"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
+)
+
+func TestValidatingConn(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "zone1")
+	defer ts.Close()
+	conn, err := ts.ConnForCell(ctx, topo.GlobalCell)
+	require.NoError(t, err)
+	vc := topo.NewValidatingConn(topo.GlobalCell, conn)
+
+	marshal := func(m proto.Message) []byte {
+		data, err := proto.Marshal(m)
+		require.NoError(t, err)
+		return data
+	}
+	keyRange, err := key.ParseShardingSpec("-80")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		path     string
+		contents []byte
+		err      string
+	}{{
+		name:     "valid tablet",
+		path:     "tablets/zone1-0000000100/Tablet",
+		contents: marshal(&topodatapb.Tablet{Alias: &topodatapb.TabletAlias{Cell: "zone1", Uid: 100}}),
+	}, {
+		name:     "tablet with another alias",
+		path:     "tablets/zone1-0000000102/Tablet",
+		contents: marshal(&topodatapb.Tablet{Alias: &topodatapb.TabletAlias{Cell: "zone1", Uid: 101}}),
+		err:      "invalid Tablet at tablets/zone1-0000000102/Tablet: alias zone1-0000000101 does not match the path",
+	}, {
+		name:     "hand-edited JSON",
+		path:     "keyspaces/ks/Keyspace",
+		contents: []byte(`{"keyspace_type": 0}`),
+		err:      "invalid Keyspace at keyspaces/ks/Keyspace",
+	}, {
+		name:     "valid shard",
+		path:     "keyspaces/ks/shards/-80/Shard",
+		contents: marshal(&topodatapb.Shard{KeyRange: keyRange[0]}),
+	}, {
+		name:     "shard with another key range",
+		path:     "keyspaces/ks/shards/80-/Shard",
+		contents: marshal(&topodatapb.Shard{KeyRange: keyRange[0]}),
+		err:      "key range -80 does not match the shard name",
+	}, {
+		name: "vschema with an undefined vindex",
+		path: "keyspaces/ks/VSchema",
+		contents: marshal(&vschemapb.Keyspace{
+			Tables: map[string]*vschemapb.Table{
+				"t1": {ColumnVindexes: []*vschemapb.ColumnVindex{{Name: "hash", Column: "id"}}},
+			},
+		}),
+		err: "table t1 refers to vindex hash, which is not defined",
+	}, {
+		name:     "unknown path",
+		path:     "some/other/file",
+		contents: []byte("anything"),
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := vc.Update(ctx, tt.path, tt.contents, nil)
+			if tt.err == "" {
+				require.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.err)
+			_, _, err = conn.Get(ctx, tt.path)
+			assert.True(t, topo.IsErrType(err, topo.NoNode), "expected the write to be rejected, got %v", err)
+		})
+	}
+}
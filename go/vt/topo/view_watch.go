@@ -0,0 +1,187 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topo
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/timer"
+	"vitess.io/vitess/go/vt/log"
+)
+
+// This file contains the machinery shared by the views of topo records kept
+// up to date by watches, like TabletsView and ShardsView: it runs their
+// watches, sets them again when they fail, and keeps track of the failed
+// ones.
+
+// watchViewRetryDelay is how long a view waits before setting one of its
+// watches again after it failed.
+var watchViewRetryDelay = 5 * time.Second
+
+// watchedView runs the watches of a view. It is embedded in the views.
+type watchedView struct {
+	// cancel stops the watches.
+	cancel context.CancelFunc
+	// wg tracks the goroutines of the watches.
+	wg sync.WaitGroup
+	// done is closed when all the watches stopped.
+	done chan struct{}
+
+	// staleMu protects staleSince.
+	staleMu sync.Mutex
+	// staleSince is when the watches being set again failed, by name.
+	staleSince map[string]time.Time
+}
+
+// viewWatch is one of the watches of a view.
+type viewWatch[C any] struct {
+	// name describes the records of the watch in the logs.
+	name string
+	// watch sets the watch, updates the view with the current records it
+	// returns, and returns the channel of their changes.
+	watch func(ctx context.Context) (<-chan C, error)
+	// apply applies the changes of the watch to the view until it fails,
+	// and returns its error.
+	apply func(changes <-chan C) error
+	// onStale, if set, is called with when the watch failed, when it fails
+	// and then each time setting it again fails, and with the zero time once
+	// it is set again.
+	onStale func(staleSince time.Time)
+}
+
+// newWatchedView returns the watchedView of a view whose watches run until
+// the context returned is canceled, which happens when ctx is.
+func newWatchedView(ctx context.Context) (*watchedView, context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	return &watchedView{
+		cancel:     cancel,
+		done:       make(chan struct{}),
+		staleSince: make(map[string]time.Time),
+	}, ctx
+}
+
+// startWatch sets a watch of the view, and runs it in the background until
+// ctx is canceled, setting it again each time it fails. It returns the error
+// of setting it, in which case it does not run.
+func startWatch[C any](ctx context.Context, wv *watchedView, w *viewWatch[C]) error {
+	watchCtx, cancel := context.WithCancel(ctx)
+	changes, err := w.watch(watchCtx)
+	if err != nil {
+		cancel()
+		return err
+	}
+
+	wv.wg.Add(1)
+	go func() {
+		defer wv.wg.Done()
+		runWatch(ctx, wv, w, cancel, changes)
+	}()
+	return nil
+}
+
+// runWatch applies the changes of a watch, and sets it again each time it
+// fails, until ctx is canceled.
+func runWatch[C any](ctx context.Context, wv *watchedView, w *viewWatch[C], cancel context.CancelFunc, changes <-chan C) {
+	for {
+		err := w.apply(changes)
+		// Some topo implementations keep the channel open after an error
+		// until the watch is canceled.
+		cancel()
+		for range changes {
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		log.Warningf("Watch of %v failed, setting it again in %v: %v", w.name, watchViewRetryDelay, err)
+		staleSince := time.Now()
+		setStale(wv, w, staleSince)
+
+		for {
+			if err := timer.SleepContext(ctx, watchViewRetryDelay); err != nil {
+				return
+			}
+
+			var watchCtx context.Context
+			watchCtx, cancel = context.WithCancel(ctx)
+			changes, err = w.watch(watchCtx)
+			if err == nil {
+				setStale(wv, w, time.Time{})
+				break
+			}
+			cancel()
+			log.Warningf("Cannot watch %v, retrying in %v: %v", w.name, watchViewRetryDelay, err)
+			setStale(wv, w, staleSince)
+		}
+	}
+}
+
+// setStale records when a watch of the view failed, or that it runs again
+// for the zero time, and calls its onStale.
+func setStale[C any](wv *watchedView, w *viewWatch[C], staleSince time.Time) {
+	wv.staleMu.Lock()
+	if staleSince.IsZero() {
+		delete(wv.staleSince, w.name)
+	} else {
+		wv.staleSince[w.name] = staleSince
+	}
+	wv.staleMu.Unlock()
+
+	if w.onStale != nil {
+		w.onStale(staleSince)
+	}
+}
+
+// started closes done once the watches of the view stopped. It is called
+// once all of them are started.
+func (wv *watchedView) started() {
+	go func() {
+		wv.wg.Wait()
+		wv.cancel()
+		close(wv.done)
+	}()
+}
+
+// abort stops the watches of a view which could not start all of them.
+func (wv *watchedView) abort() {
+	wv.cancel()
+	wv.wg.Wait()
+}
+
+// StaleSince returns when the oldest of the watches of the view being set
+// again failed, or the zero time if they all run. The records of the view
+// may not be up to date meanwhile.
+func (wv *watchedView) StaleSince() time.Time {
+	wv.staleMu.Lock()
+	defer wv.staleMu.Unlock()
+
+	var oldest time.Time
+	for _, since := range wv.staleSince {
+		if oldest.IsZero() || since.Before(oldest) {
+			oldest = since
+		}
+	}
+	return oldest
+}
+
+// Done returns a channel closed when the watches of the view stopped, after
+// the context the view was created with was canceled. The callbacks of the
+// view are not called anymore once it is closed.
+func (wv *watchedView) Done() <-chan struct{} {
+	return wv.done
+}
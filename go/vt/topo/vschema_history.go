@@ -0,0 +1,178 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topo
+
+import (
+	"context"
+	"path"
+	"slices"
+	"strconv"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	"vitess.io/vitess/go/protoutil"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
+)
+
+// This file contains the utility methods to manage the vschema history of a
+// keyspace: the prior versions of its vschema, each stored in a file of
+// keyspaces/<keyspace>/vschema_history named after its version.
+
+// VSchemaHistorySize is the number of prior versions of the vschema of a
+// keyspace kept in its vschema history.
+const VSchemaHistorySize = 10
+
+// SaveVSchemaWithHistory saves a vschema like SaveVSchema, after adding the
+// vschema it replaces, if any and if different, to the vschema history of
+// the keyspace.
+func (ts *Server) SaveVSchemaWithHistory(ctx context.Context, keyspace string, vschema *vschemapb.Keyspace) error {
+	old, err := ts.GetVSchema(ctx, keyspace)
+	switch {
+	case err == nil:
+		if !proto.Equal(old, vschema) {
+			if err := ts.AddVSchemaHistoryEntry(ctx, keyspace, old); err != nil {
+				return vterrors.Wrapf(err, "AddVSchemaHistoryEntry(%v)", keyspace)
+			}
+		}
+	case IsErrType(err, NoNode):
+		// No prior vschema to keep.
+	default:
+		return err
+	}
+	return ts.SaveVSchema(ctx, keyspace, vschema)
+}
+
+// AddVSchemaHistoryEntry adds a vschema to the vschema history of a keyspace,
+// with the next version, and prunes the oldest entries beyond
+// VSchemaHistorySize.
+func (ts *Server) AddVSchemaHistoryEntry(ctx context.Context, keyspace string, vschema *vschemapb.Keyspace) error {
+	for {
+		versions, err := ts.getVSchemaHistoryVersions(ctx, keyspace)
+		if err != nil {
+			return err
+		}
+		entry := &vschemapb.VSchemaHistoryEntry{
+			Version:      1,
+			TimeReplaced: protoutil.TimeToProto(time.Now()),
+			Vschema:      vschema,
+		}
+		if len(versions) > 0 {
+			entry.Version = versions[len(versions)-1] + 1
+		}
+		data, err := entry.MarshalVT()
+		if err != nil {
+			return err
+		}
+		if _, err := ts.globalCell.Create(ctx, vschemaHistoryEntryPath(keyspace, entry.Version), data); err != nil {
+			if IsErrType(err, NodeExists) {
+				// Another process added the same version, try again.
+				continue
+			}
+			return err
+		}
+
+		versions = append(versions, entry.Version)
+		if len(versions) <= VSchemaHistorySize {
+			return nil
+		}
+		for _, version := range versions[:len(versions)-VSchemaHistorySize] {
+			if err := ts.globalCell.Delete(ctx, vschemaHistoryEntryPath(keyspace, version), nil); err != nil && !IsErrType(err, NoNode) {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// GetVSchemaHistory returns the vschema history of a keyspace, newest first.
+func (ts *Server) GetVSchemaHistory(ctx context.Context, keyspace string) ([]*vschemapb.VSchemaHistoryEntry, error) {
+	versions, err := ts.getVSchemaHistoryVersions(ctx, keyspace)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]*vschemapb.VSchemaHistoryEntry, 0, len(versions))
+	for i := len(versions) - 1; i >= 0; i-- {
+		entry, err := ts.GetVSchemaHistoryEntry(ctx, keyspace, versions[i])
+		if err != nil {
+			if IsErrType(err, NoNode) {
+				// Pruned since we listed the versions.
+				continue
+			}
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// GetVSchemaHistoryEntry returns a version of the vschema history of a
+// keyspace.
+func (ts *Server) GetVSchemaHistoryEntry(ctx context.Context, keyspace string, version int64) (*vschemapb.VSchemaHistoryEntry, error) {
+	data, _, err := ts.globalCell.Get(ctx, vschemaHistoryEntryPath(keyspace, version))
+	if err != nil {
+		return nil, err
+	}
+	entry := &vschemapb.VSchemaHistoryEntry{}
+	if err := entry.UnmarshalVT(data); err != nil {
+		return nil, vterrors.Wrapf(err, "bad vschema history data: %q", data)
+	}
+	return entry, nil
+}
+
+// deleteVSchemaHistory deletes the vschema history of a keyspace, if any.
+func (ts *Server) deleteVSchemaHistory(ctx context.Context, keyspace string) error {
+	versions, err := ts.getVSchemaHistoryVersions(ctx, keyspace)
+	if err != nil {
+		return err
+	}
+	for _, version := range versions {
+		if err := ts.globalCell.Delete(ctx, vschemaHistoryEntryPath(keyspace, version), nil); err != nil && !IsErrType(err, NoNode) {
+			return err
+		}
+	}
+	return nil
+}
+
+// getVSchemaHistoryVersions returns the versions of the vschema history of a
+// keyspace, in increasing order.
+func (ts *Server) getVSchemaHistoryVersions(ctx context.Context, keyspace string) ([]int64, error) {
+	children, err := ts.globalCell.ListDir(ctx, path.Join(KeyspacesPath, keyspace, VSchemaHistoryPath), false /*full*/)
+	switch {
+	case err == nil:
+	case IsErrType(err, NoNode):
+		return nil, nil
+	default:
+		return nil, err
+	}
+	versions := make([]int64, 0, len(children))
+	for _, child := range children {
+		version, err := strconv.ParseInt(child.Name, 10, 64)
+		if err != nil {
+			return nil, vterrors.Wrapf(err, "bad vschema history version %q", child.Name)
+		}
+		versions = append(versions, version)
+	}
+	slices.Sort(versions)
+	return versions, nil
+}
+
+func vschemaHistoryEntryPath(keyspace string, version int64) string {
+	return path.Join(KeyspacesPath, keyspace, VSchemaHistoryPath, strconv.FormatInt(version, 10))
+}
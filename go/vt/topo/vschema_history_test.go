@@ -0,0 +1,74 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topo_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
+)
+
+func TestVSchemaHistory(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "zone1")
+	defer ts.Close()
+
+	require.NoError(t, ts.CreateKeyspace(ctx, "ks", &topodatapb.Keyspace{}))
+
+	// The first vschema has nothing to replace, and saving the same vschema
+	// again doesn't add to the history.
+	vschema := func(i int) *vschemapb.Keyspace {
+		return &vschemapb.Keyspace{Vindexes: map[string]*vschemapb.Vindex{fmt.Sprintf("v%d", i): {Type: "hash"}}}
+	}
+	require.NoError(t, ts.SaveVSchemaWithHistory(ctx, "ks", vschema(0)))
+	require.NoError(t, ts.SaveVSchemaWithHistory(ctx, "ks", vschema(0)))
+	entries, err := ts.GetVSchemaHistory(ctx, "ks")
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	// The history is bounded, and the oldest entries are pruned.
+	for i := 1; i <= topo.VSchemaHistorySize+2; i++ {
+		require.NoError(t, ts.SaveVSchemaWithHistory(ctx, "ks", vschema(i)))
+	}
+	entries, err = ts.GetVSchemaHistory(ctx, "ks")
+	require.NoError(t, err)
+	require.Len(t, entries, topo.VSchemaHistorySize)
+	assert.EqualValues(t, topo.VSchemaHistorySize+2, entries[0].Version)
+	assert.Equal(t, vschema(topo.VSchemaHistorySize+1).String(), entries[0].Vschema.String())
+	assert.EqualValues(t, 3, entries[len(entries)-1].Version)
+	_, err = ts.GetVSchemaHistoryEntry(ctx, "ks", 2)
+	assert.True(t, topo.IsErrType(err, topo.NoNode), "expected NoNode, got %v", err)
+
+	// Deleting the keyspace deletes its history, so it is not listed anymore.
+	require.NoError(t, ts.DeleteKeyspace(ctx, "ks"))
+	entries, err = ts.GetVSchemaHistory(ctx, "ks")
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+	keyspaces, err := ts.GetKeyspaces(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, keyspaces)
+}
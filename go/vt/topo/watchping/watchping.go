@@ -0,0 +1,335 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package watchping checks that topo watches deliver changes. Ping writes a
+// canary value to a file of the topo server of a cell, and measures how long
+// the watches of that file take to deliver it, in-process and in the
+// components that serve the watch-echo endpoint of RegisterEchoHandler.
+package watchping
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/acl"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/servenv"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+const (
+	// EchoPath is the URL path of the watch-echo endpoint.
+	EchoPath = "/debug/topo_watch_echo"
+	// DefaultPath is the canary file used when none is given.
+	DefaultPath = "topo_watch_ping"
+	// DefaultThreshold is the threshold used when none is given.
+	DefaultThreshold = 5 * time.Second
+
+	// maxEchoTimeout bounds the time the watch-echo endpoint keeps a watch
+	// open for.
+	maxEchoTimeout = time.Minute
+	// canaryPrefix starts the contents of every canary file, so Ping never
+	// overwrites a file it did not write.
+	canaryPrefix = "ping_topo_watch "
+
+	stateWatching = "watching"
+	stateReceived = "received"
+)
+
+// Component is a component whose watch of the canary file Ping checks.
+type Component struct {
+	// Name identifies the component in the results.
+	Name string
+	// Addr is the host:port of the HTTP server of the component, or empty
+	// to watch the file from this process.
+	Addr string
+}
+
+// Result is the outcome of the watch of one component.
+type Result struct {
+	Component string
+	// Latency is the time between the write of the canary and its delivery
+	// by the watch of the component.
+	Latency time.Duration
+	// Err is set when the watch did not deliver the canary within the
+	// threshold.
+	Err error
+}
+
+// event is a line of the response of the watch-echo endpoint.
+type event struct {
+	State string `json:"state,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// RegisterEchoHandler registers the watch-echo endpoint, which Ping uses to
+// check the topo watches of this process.
+func RegisterEchoHandler(ts *topo.Server) {
+	servenv.HTTPHandleFunc(EchoPath, EchoHandler(ts))
+}
+
+// EchoHandler returns the handler of the watch-echo endpoint. Given the cell,
+// path, value and timeout query parameters, it watches the file, and streams
+// an event when the watch started and another one when it delivered the
+// value, or failed.
+func EchoHandler(ts *topo.Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := acl.CheckAccessHTTP(r, acl.DEBUGGING); err != nil {
+			acl.SendError(w, err)
+			return
+		}
+
+		query := r.URL.Query()
+		timeout := maxEchoTimeout
+		if s := query.Get("timeout"); s != "" {
+			d, err := time.ParseDuration(s)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("invalid timeout %q: %v", s, err), http.StatusBadRequest)
+				return
+			}
+			timeout = min(d, maxEchoTimeout)
+		}
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		flusher, _ := w.(http.Flusher)
+		echo(ctx, ts, query.Get("cell"), query.Get("path"), query.Get("value"), func(e event) {
+			if err := enc.Encode(e); err != nil {
+				log.Warningf("cannot write topo watch echo event: %v", err)
+				return
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+		})
+	}
+}
+
+// echo watches the file, and sends a watching event once the watch started,
+// then a received event once it delivered the value. It sends an error event
+// instead if either fails.
+func echo(ctx context.Context, ts *topo.Server, cell, path, value string, send func(event)) {
+	conn, err := ts.ConnForCell(ctx, cell)
+	if err != nil {
+		send(event{Error: err.Error()})
+		return
+	}
+	current, changes, err := conn.Watch(ctx, path)
+	if err != nil {
+		send(event{Error: err.Error()})
+		return
+	}
+	send(event{State: stateWatching})
+	if string(current.Contents) == value {
+		send(event{State: stateReceived})
+		return
+	}
+	for wd := range changes {
+		if wd.Err != nil {
+			send(event{Error: wd.Err.Error()})
+			return
+		}
+		if string(wd.Contents) == value {
+			send(event{State: stateReceived})
+			return
+		}
+	}
+	send(event{Error: "watch ended before the canary was delivered"})
+}
+
+// remoteEcho runs echo in the component serving the watch-echo endpoint at
+// addr, passing on the events it sends.
+func remoteEcho(ctx context.Context, addr, cell, path, value string, timeout time.Duration, send func(event)) {
+	u := url.URL{
+		Scheme: "http",
+		Host:   addr,
+		Path:   EchoPath,
+		RawQuery: url.Values{
+			"cell":    {cell},
+			"path":    {path},
+			"value":   {value},
+			"timeout": {timeout.String()},
+		}.Encode(),
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		send(event{Error: err.Error()})
+		return
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		send(event{Error: err.Error()})
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		send(event{Error: fmt.Sprintf("%v: %s", resp.Status, strings.TrimSpace(string(body)))})
+		return
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		var e event
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			send(event{Error: fmt.Sprintf("invalid topo watch echo event %q: %v", scanner.Text(), err)})
+			return
+		}
+		send(e)
+	}
+	if err := scanner.Err(); err != nil {
+		send(event{Error: err.Error()})
+		return
+	}
+	send(event{Error: "topo watch echo ended before the canary was delivered"})
+}
+
+// watcher follows the watch of one component.
+type watcher struct {
+	watchingOnce sync.Once
+	doneOnce     sync.Once
+	// watching is closed once the watch started.
+	watching chan struct{}
+	// done is closed once the watch delivered the canary, or failed.
+	done chan struct{}
+
+	// received and err are set before done is closed.
+	received time.Time
+	err      error
+}
+
+func (w *watcher) handle(e event) {
+	switch {
+	case e.Error != "":
+		w.doneOnce.Do(func() {
+			w.err = vterrors.New(vtrpcpb.Code_UNAVAILABLE, e.Error)
+			close(w.done)
+		})
+	case e.State == stateWatching:
+		w.watchingOnce.Do(func() { close(w.watching) })
+	case e.State == stateReceived:
+		w.doneOnce.Do(func() {
+			w.received = time.Now()
+			close(w.done)
+		})
+	}
+}
+
+// Ping writes a new canary value to path in the topo server of cell, and
+// returns how long the watch of each of the components took to deliver it.
+// Each component has threshold to start its watch, and then threshold to
+// see the canary.
+func Ping(ctx context.Context, ts *topo.Server, cell, path string, components []Component, threshold time.Duration) ([]Result, error) {
+	conn, err := ts.ConnForCell(ctx, cell)
+	if err != nil {
+		return nil, err
+	}
+
+	// The file must exist for the components to watch it.
+	contents, version, err := conn.Get(ctx, path)
+	switch {
+	case topo.IsErrType(err, topo.NoNode):
+		if version, err = conn.Create(ctx, path, []byte(newCanary())); err != nil {
+			return nil, err
+		}
+	case err != nil:
+		return nil, err
+	case !strings.HasPrefix(string(contents), canaryPrefix):
+		return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "%v in cell %v is not a topo watch canary, refusing to overwrite it", path, cell)
+	}
+
+	// The watches end when Ping returns.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	value := newCanary()
+	watchers := make([]*watcher, len(components))
+	for i, c := range components {
+		w := &watcher{watching: make(chan struct{}), done: make(chan struct{})}
+		watchers[i] = w
+		go func(c Component) {
+			if c.Addr == "" {
+				echo(ctx, ts, cell, path, value, w.handle)
+				return
+			}
+			remoteEcho(ctx, c.Addr, cell, path, value, 2*threshold, w.handle)
+		}(c)
+	}
+
+	startCtx, startCancel := context.WithTimeout(ctx, threshold)
+	defer startCancel()
+	for _, w := range watchers {
+		select {
+		case <-w.watching:
+		case <-w.done:
+		case <-startCtx.Done():
+		}
+	}
+
+	if _, err := conn.Update(ctx, path, []byte(value), version); err != nil {
+		return nil, vterrors.Wrapf(err, "cannot write the canary to %v in cell %v", path, cell)
+	}
+	written := time.Now()
+
+	deliverCtx, deliverCancel := context.WithTimeout(ctx, threshold)
+	defer deliverCancel()
+	results := make([]Result, len(components))
+	for i, w := range watchers {
+		results[i].Component = components[i].Name
+		select {
+		case <-w.done:
+		case <-deliverCtx.Done():
+		}
+		select {
+		case <-w.done:
+			if w.err != nil {
+				results[i].Err = w.err
+				continue
+			}
+			results[i].Latency = w.received.Sub(written)
+		default:
+			select {
+			case <-w.watching:
+				results[i].Err = vterrors.Errorf(vtrpcpb.Code_DEADLINE_EXCEEDED, "the watch did not deliver the canary within %v", threshold)
+			default:
+				results[i].Err = vterrors.Errorf(vtrpcpb.Code_DEADLINE_EXCEEDED, "the watch did not start within %v", threshold)
+			}
+		}
+	}
+	return results, nil
+}
+
+// newCanary returns a canary value that no other Ping writes.
+func newCanary() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return canaryPrefix + time.Now().UTC().Format(time.RFC3339Nano) + " " + hex.EncodeToString(b)
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package watchping
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+)
+
+func TestPing(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "zone1")
+	defer ts.Close()
+
+	srv := httptest.NewServer(EchoHandler(ts))
+	defer srv.Close()
+	remote := strings.TrimPrefix(srv.URL, "http://")
+
+	components := []Component{
+		{Name: "local"},
+		{Name: "remote", Addr: remote},
+	}
+	// The first ping creates the canary file, the second one updates it.
+	for i := 0; i < 2; i++ {
+		results, err := Ping(ctx, ts, "zone1", DefaultPath, components, 5*time.Second)
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+		for j, r := range results {
+			assert.Equal(t, components[j].Name, r.Component)
+			assert.NoError(t, r.Err)
+			assert.Less(t, r.Latency, 5*time.Second)
+		}
+	}
+
+	conn, err := ts.ConnForCell(ctx, "zone1")
+	require.NoError(t, err)
+	contents, _, err := conn.Get(ctx, DefaultPath)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(contents), canaryPrefix), "contents: %s", contents)
+
+	t.Run("component without the cell", func(t *testing.T) {
+		srv := httptest.NewServer(EchoHandler(memorytopo.NewServer(ctx, "zone2")))
+		defer srv.Close()
+		results, err := Ping(ctx, ts, "zone1", DefaultPath, []Component{{Name: "other", Addr: strings.TrimPrefix(srv.URL, "http://")}}, time.Second)
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		assert.ErrorContains(t, results[0].Err, "zone1")
+	})
+
+	t.Run("unreachable component", func(t *testing.T) {
+		srv := httptest.NewServer(nil)
+		addr := strings.TrimPrefix(srv.URL, "http://")
+		srv.Close()
+
+		results, err := Ping(ctx, ts, "zone1", DefaultPath, []Component{{Name: "local"}, {Name: "gone", Addr: addr}}, time.Second)
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+		assert.NoError(t, results[0].Err)
+		assert.Error(t, results[1].Err)
+	})
+
+	t.Run("not a canary", func(t *testing.T) {
+		_, err := conn.Create(ctx, "keyspaces/ks/Keyspace", []byte("not a canary"))
+		require.NoError(t, err)
+		_, err = Ping(ctx, ts, "zone1", "keyspaces/ks/Keyspace", components, time.Second)
+		assert.ErrorContains(t, err, "is not a topo watch canary")
+	})
+}
@@ -20,6 +20,8 @@ import (
 	"context"
 	"path"
 	"sort"
+	"sync"
+	"time"
 
 	"github.com/z-division/go-zookeeper/zk"
 
@@ -34,6 +36,15 @@ import (
 // NewLeaderParticipation is part of the topo.Server interface.
 // We use the full path: <root path>/election/<name>
 func (zs *Server) NewLeaderParticipation(name, id string) (topo.LeaderParticipation, error) {
+	return zs.NewLeaderParticipationWithTTL(name, id, 0)
+}
+
+// NewLeaderParticipationWithTTL is part of the
+// topo.LeaderParticipationTTLConn interface.
+// The proposals live as long as the session they were created in, so with a
+// ttl, the participation uses its own connection, with sessions of that
+// timeout.
+func (zs *Server) NewLeaderParticipationWithTTL(name, id string, ttl time.Duration) (topo.LeaderParticipation, error) {
 	ctx := context.TODO()
 
 	zkPath := path.Join(zs.root, electionsPath, name)
@@ -46,10 +57,15 @@ func (zs *Server) NewLeaderParticipation(name, id string) (topo.LeaderParticipat
 
 	result := &zkLeaderParticipation{
 		zs:   zs,
+		conn: zs.conn,
 		name: name,
 		id:   []byte(id),
 		done: make(chan struct{}),
 	}
+	if ttl > 0 {
+		// The connection only dials when first used.
+		result.conn = connectWithSessionTimeout(zs.conn.addr, ttl)
+	}
 	result.stopCtx, result.stopCtxCancel = context.WithCancel(context.Background())
 	return result, nil
 }
@@ -65,6 +81,10 @@ type zkLeaderParticipation struct {
 	// zs is our parent zk topo Server
 	zs *Server
 
+	// conn is the connection the proposals are created with: the one of
+	// zs, or our own one for a ttl.
+	conn *ZkConn
+
 	// name is the name of this LeaderParticipation
 	name string
 
@@ -79,13 +99,17 @@ type zkLeaderParticipation struct {
 
 	// done is a channel closed when the stop operation is done.
 	done chan struct{}
+
+	// wg tracks the goroutines watching the primaryship, which delete the
+	// proposal when Stop is called.
+	wg sync.WaitGroup
 }
 
 // WaitForLeadership is part of the topo.LeaderParticipation interface.
 func (mp *zkLeaderParticipation) WaitForLeadership() (context.Context, error) {
-	// If Stop was already called, mp.done is closed, so we are interrupted.
+	// If Stop was already called, we are interrupted.
 	select {
-	case <-mp.done:
+	case <-mp.stopCtx.Done():
 		return nil, topo.NewError(topo.Interrupted, "Leadership")
 	default:
 	}
@@ -93,16 +117,8 @@ func (mp *zkLeaderParticipation) WaitForLeadership() (context.Context, error) {
 	ctx := context.TODO()
 	zkPath := path.Join(mp.zs.root, electionsPath, mp.name)
 
-	// Fast path if Stop was already called.
-	select {
-	case <-mp.stopCtx.Done():
-		close(mp.done)
-		return nil, topo.NewError(topo.Interrupted, "Leadership")
-	default:
-	}
-
 	// Create the current proposal.
-	proposal, err := mp.zs.conn.Create(ctx, zkPath+"/", mp.id, zk.FlagSequence|zk.FlagEphemeral, zk.WorldACL(PermFile))
+	proposal, err := mp.conn.Create(ctx, zkPath+"/", mp.id, zk.FlagSequence|zk.FlagEphemeral, zk.WorldACL(PermFile))
 	if err != nil {
 		return nil, vterrors.Wrapf(err, "cannot create proposal file in %v", zkPath)
 	}
@@ -110,12 +126,11 @@ func (mp *zkLeaderParticipation) WaitForLeadership() (context.Context, error) {
 	// Wait until we are it, or we are interrupted. Using a
 	// small-ish time out so it gets exercised faster (as opposed
 	// to crashing after a day of use).
-	err = obtainQueueLock(mp.stopCtx, mp.zs.conn, proposal)
+	err = obtainQueueLock(mp.stopCtx, mp.conn, proposal)
 	switch err {
 	case nil:
 		break
 	case context.Canceled:
-		close(mp.done)
 		return nil, topo.NewError(topo.Interrupted, "Leadership")
 	default:
 		// something else went wrong
@@ -124,7 +139,8 @@ func (mp *zkLeaderParticipation) WaitForLeadership() (context.Context, error) {
 
 	// we got the lock, create our background context
 	ctx, cancel := context.WithCancel(context.Background())
-	go mp.watchLeadership(ctx, mp.zs.conn, proposal, cancel)
+	mp.wg.Add(1)
+	go mp.watchLeadership(ctx, mp.conn, proposal, cancel)
 	return ctx, nil
 }
 
@@ -135,6 +151,7 @@ func (mp *zkLeaderParticipation) WaitForLeadership() (context.Context, error) {
 //     being the primary.
 //   - wait for mp.stop.
 func (mp *zkLeaderParticipation) watchLeadership(ctx context.Context, conn *ZkConn, proposal string, cancel context.CancelFunc) {
+	defer mp.wg.Done()
 	// any interruption of this routine means we're not primary any more.
 	defer cancel()
 
@@ -153,7 +170,6 @@ func (mp *zkLeaderParticipation) watchLeadership(ctx context.Context, conn *ZkCo
 		if err := conn.Delete(ctx, proposal, stats.Version); err != nil {
 			log.Warningf("Error deleting our proposal %v: %v", proposal, err)
 		}
-		close(mp.done)
 
 	case e := <-events:
 		// something happened to our proposal, that can only be bad.
@@ -164,7 +180,13 @@ func (mp *zkLeaderParticipation) watchLeadership(ctx context.Context, conn *ZkCo
 // Stop is part of the topo.LeaderParticipation interface
 func (mp *zkLeaderParticipation) Stop() {
 	mp.stopCtxCancel()
-	<-mp.done
+	// The lock may have been lost already, in which case there is nothing
+	// left to release.
+	mp.wg.Wait()
+	close(mp.done)
+	if mp.conn != mp.zs.conn {
+		mp.conn.Close()
+	}
 }
 
 // GetCurrentLeaderID is part of the topo.LeaderParticipation interface.
@@ -204,3 +226,32 @@ func (mp *zkLeaderParticipation) WaitForNewLeader(context.Context) (<-chan strin
 	// as how WatchRecursive could be implemented as well.
 	return nil, topo.NewError(topo.NoImplementation, "wait for leader not supported in ZK2 topo")
 }
+
+// ForceResign is part of the topo.ForceResignLeaderParticipation interface.
+// It deletes the proposal of the primary, the smallest (first) node, which
+// the primary watches.
+func (mp *zkLeaderParticipation) ForceResign(ctx context.Context, id string) error {
+	zkPath := path.Join(mp.zs.root, electionsPath, mp.name)
+
+	children, _, err := mp.zs.conn.Children(ctx, zkPath)
+	if err != nil {
+		return convertError(err, zkPath)
+	}
+	if len(children) == 0 {
+		return topo.NewError(topo.NoNode, zkPath)
+	}
+	sort.Strings(children)
+
+	childPath := path.Join(zkPath, children[0])
+	data, stat, err := mp.zs.conn.Get(ctx, childPath)
+	if err != nil {
+		return convertError(err, childPath)
+	}
+	if string(data) != id {
+		return topo.NewError(topo.BadVersion, zkPath)
+	}
+	if err := mp.zs.conn.Delete(ctx, childPath, stat.Version); err != nil {
+		return convertError(err, childPath)
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package zk2topo
+
+import (
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/z-division/go-zookeeper/zk"
+
+	"vitess.io/vitess/go/vt/log"
+)
+
+// ensembleClientAddrs returns the client addresses of all the servers
+// (participants and observers) listed in a ZooKeeper 3.5+ dynamic
+// configuration, as found in zk.DynConfPath. Servers whose client address is
+// a wildcard are reached through their quorum host instead.
+func ensembleClientAddrs(data []byte) ([]string, error) {
+	cfg, err := zk.ParseDynConfig(data)
+	if err != nil {
+		return nil, err
+	}
+
+	addrs := make([]string, 0, len(cfg.Servers))
+	for _, server := range cfg.Servers {
+		host := server.ClientHost
+		if ip := net.ParseIP(host); host == "" || (ip != nil && ip.IsUnspecified()) {
+			host = server.Host
+		}
+		addrs = append(addrs, net.JoinHostPort(host, fmt.Sprint(server.ClientPort)))
+	}
+	if len(addrs) == 0 {
+		return nil, fmt.Errorf("no servers in zk dynamic config")
+	}
+	slices.Sort(addrs)
+	return addrs, nil
+}
+
+// Delays between the attempts to watch the ensemble configuration after an
+// error, doubling from the first one up to the last one.
+const (
+	ensembleRetryMinDelay = time.Second
+	ensembleRetryMaxDelay = time.Minute
+)
+
+// watchEnsemble follows the dynamic ensemble configuration for as long as
+// conn is the connection of c. Every change updates both the host provider of
+// the live connection and the server list used for future reconnects, so
+// membership changes are picked up without restarting the process. The errors
+// of the watch, e.g. while the ensemble is being reconfigured, are retried
+// with a backoff.
+//
+// On ensembles without dynamic reconfiguration (ZooKeeper 3.4), the config
+// node does not exist and this returns right away.
+func (c *ZkConn) watchEnsemble(conn *zk.Conn, hostProvider zk.HostProvider) {
+	retryDelay := ensembleRetryMinDelay
+	for {
+		if !c.isCurrentConn(conn) {
+			return
+		}
+
+		data, _, watch, err := conn.GetW(zk.DynConfPath)
+		switch err {
+		case nil:
+			retryDelay = ensembleRetryMinDelay
+		case zk.ErrNoNode:
+			log.Warningf("zk conn: %v has no dynamic config for addr %v, not following ensemble changes", zk.DynConfPath, c.addr)
+			return
+		case zk.ErrClosing, zk.ErrConnectionClosed:
+			return
+		default:
+			log.Warningf("zk conn: cannot watch ensemble config for addr %v, retrying in %v: %v", c.addr, retryDelay, err)
+			time.Sleep(retryDelay)
+			retryDelay = min(2*retryDelay, ensembleRetryMaxDelay)
+			continue
+		}
+
+		if addrs, err := ensembleClientAddrs(data); err != nil {
+			log.Warningf("zk conn: ignoring invalid ensemble config for addr %v: %v", c.addr, err)
+		} else {
+			c.mu.Lock()
+			changed := !slices.Equal(c.servers, addrs)
+			if changed {
+				c.servers = addrs
+			}
+			c.mu.Unlock()
+
+			if changed {
+				// If this fails, the existing host list is left untouched.
+				if err := hostProvider.Init(addrs); err != nil {
+					log.Warningf("zk conn: failed to apply ensemble config %v for addr %v: %v", addrs, c.addr, err)
+				} else {
+					log.Infof("zk conn: ensemble for addr %v is now %v", c.addr, strings.Join(addrs, ","))
+				}
+			}
+		}
+
+		// The watch is also triggered when it is lost, e.g. with the
+		// session, in which case it is set again if conn is still the
+		// connection of c.
+		<-watch
+	}
+}
+
+// isCurrentConn returns whether conn is still the connection of c, which
+// replaces it once it is closed or its session expired.
+func (c *ZkConn) isCurrentConn(conn *zk.Conn) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.conn == conn
+}
@@ -0,0 +1,41 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package zk2topo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnsembleClientAddrs(t *testing.T) {
+	data := []byte(`server.3=zk3:2888:3888:observer;zk3.example.com:2181
+server.1=zk1:2888:3888:participant;0.0.0.0:2181
+server.2=zk2:2888:3888:participant;zk2.example.com:2182
+version=100000003`)
+
+	addrs, err := ensembleClientAddrs(data)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"zk1:2181", "zk2.example.com:2182", "zk3.example.com:2181"}, addrs)
+
+	_, err = ensembleClientAddrs([]byte("version=1"))
+	assert.Error(t, err)
+
+	_, err = ensembleClientAddrs([]byte("server.1=zk1:2888"))
+	assert.Error(t, err)
+}
@@ -36,7 +36,7 @@ func convertError(err error, node string) error {
 		return topo.NewError(topo.NodeExists, node)
 	case errors.Is(err, zk.ErrNotEmpty):
 		return topo.NewError(topo.NodeNotEmpty, node)
-	case errors.Is(err, zk.ErrSessionExpired):
+	case errors.Is(err, zk.ErrSessionExpired), errors.Is(err, zk.ErrConnectionClosed), errors.Is(err, zk.ErrNoServer):
 		return topo.NewError(topo.Timeout, node)
 	case errors.Is(err, context.Canceled):
 		return topo.NewError(topo.Interrupted, node)
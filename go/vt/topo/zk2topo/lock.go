@@ -20,6 +20,8 @@ import (
 	"context"
 	"fmt"
 	"path"
+	"sort"
+	"time"
 
 	"github.com/z-division/go-zookeeper/zk"
 
@@ -42,6 +44,16 @@ func (zs *Server) Lock(ctx context.Context, dirPath, contents string) (topo.Lock
 	return zs.lock(ctx, dirPath, contents)
 }
 
+// LockNameWithTTL is part of the topo.NamedLockConn interface.
+// The lock lives as long as the session of the server, so only a ttl of 0 is
+// supported.
+func (zs *Server) LockNameWithTTL(ctx context.Context, dirPath, contents string, ttl time.Duration) (topo.LockDescriptor, error) {
+	if ttl != 0 {
+		return nil, topo.NewError(topo.NoImplementation, fmt.Sprintf("zookeeper locks with a TTL of %v", ttl))
+	}
+	return zs.lock(ctx, dirPath, contents)
+}
+
 // TryLock is part of the topo.Conn interface.
 func (zs *Server) TryLock(ctx context.Context, dirPath, contents string) (topo.LockDescriptor, error) {
 	// We list all the entries under dirPath
@@ -70,6 +82,58 @@ func (zs *Server) TryLock(ctx context.Context, dirPath, contents string) (topo.L
 	return zs.lock(ctx, dirPath, contents)
 }
 
+// GetLock is part of the topo.LockHolderConn interface.
+// The holder of the lock is the first of the sequential nodes of the locks
+// directory, the others are waiting for it. The zookeeper sessions have no
+// TTL per lock, so it is left unset.
+func (zs *Server) GetLock(ctx context.Context, dirPath string) (*topo.LockInfo, error) {
+	locksDir := path.Join(zs.root, dirPath, locksPath)
+	children, _, err := zs.conn.Children(ctx, locksDir)
+	if err != nil {
+		return nil, convertError(err, locksDir)
+	}
+	if len(children) == 0 {
+		return nil, topo.NewError(topo.NoNode, dirPath)
+	}
+	sort.Strings(children)
+
+	// The node is ephemeral, so it is deleted when the session of its holder
+	// expires: the lock is never stale.
+	data, _, err := zs.conn.Get(ctx, path.Join(locksDir, children[0]))
+	if err != nil {
+		return nil, convertError(err, locksDir)
+	}
+	return &topo.LockInfo{Contents: string(data)}, nil
+}
+
+// ForceUnlock is part of the topo.LockHolderConn interface.
+// It deletes the first of the sequential nodes of the locks directory, which
+// lets the next waiter take the lock.
+func (zs *Server) ForceUnlock(ctx context.Context, dirPath, contents string) error {
+	locksDir := path.Join(zs.root, dirPath, locksPath)
+	children, _, err := zs.conn.Children(ctx, locksDir)
+	if err != nil {
+		return convertError(err, locksDir)
+	}
+	if len(children) == 0 {
+		return topo.NewError(topo.NoNode, dirPath)
+	}
+	sort.Strings(children)
+
+	nodePath := path.Join(locksDir, children[0])
+	data, stat, err := zs.conn.Get(ctx, nodePath)
+	if err != nil {
+		return convertError(err, nodePath)
+	}
+	if string(data) != contents {
+		return topo.NewError(topo.BadVersion, dirPath)
+	}
+	if err := zs.conn.Delete(ctx, nodePath, stat.Version); err != nil {
+		return convertError(err, nodePath)
+	}
+	return nil
+}
+
 // Lock is part of the topo.Conn interface.
 func (zs *Server) lock(ctx context.Context, dirPath, contents string) (topo.LockDescriptor, error) {
 	// Lock paths end in a trailing slash so that when we create
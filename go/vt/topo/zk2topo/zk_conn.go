@@ -55,6 +55,8 @@ var (
 	baseTimeout    = 30 * time.Second
 
 	certPath, keyPath, caPath, authFile string
+
+	dynamicReconfig bool
 )
 
 func init() {
@@ -68,6 +70,7 @@ func registerFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&keyPath, "topo_zk_tls_key", keyPath, "the key to use to connect to the zk topo server, enables TLS")
 	fs.StringVar(&caPath, "topo_zk_tls_ca", caPath, "the server ca to use to validate servers when connecting to the zk topo server")
 	fs.StringVar(&authFile, "topo_zk_auth_file", authFile, "auth to use when connecting to the zk topo server, file contents should be <scheme>:<auth>, e.g., digest:user:pass")
+	fs.BoolVar(&dynamicReconfig, "topo_zk_dynamic_reconfig", dynamicReconfig, "watch the ZooKeeper 3.5+ dynamic ensemble configuration and follow membership changes without a restart")
 
 }
 
@@ -88,21 +91,38 @@ type ZkConn struct {
 	// addr is set at construction time, and immutable.
 	addr string
 
+	// sessionTimeout is the timeout of the sessions, or 0 for
+	// --topo_zk_base_timeout. It is set at construction time, and
+	// immutable.
+	sessionTimeout time.Duration
+
 	// sem protects concurrent calls to Zookeeper.
 	sem *semaphore.Weighted
 
 	// mu protects the following fields.
 	mu   sync.Mutex
 	conn *zk.Conn
+	// servers is the list of servers learned from the dynamic ensemble
+	// configuration, if any. When set, it is used instead of addr when
+	// (re)connecting.
+	servers []string
 }
 
 // Connect to the Zookeeper servers specified in addr
 // addr can be a comma separated list of servers and each server can be a DNS entry with multiple values.
 // Connects to the endpoints in a randomized order to avoid hot spots.
 func Connect(addr string) *ZkConn {
+	return connectWithSessionTimeout(addr, 0)
+}
+
+// connectWithSessionTimeout is like Connect, with sessions of the given
+// timeout instead of --topo_zk_base_timeout, if set. The servers bound the
+// timeout between 2 and 20 ticks of theirs.
+func connectWithSessionTimeout(addr string, sessionTimeout time.Duration) *ZkConn {
 	return &ZkConn{
-		addr: addr,
-		sem:  semaphore.NewWeighted(int64(maxConcurrency)),
+		addr:           addr,
+		sessionTimeout: sessionTimeout,
+		sem:            semaphore.NewWeighted(int64(maxConcurrency)),
 	}
 }
 
@@ -283,13 +303,23 @@ func (c *ZkConn) getConn(ctx context.Context) (*zk.Conn, error) {
 	defer c.mu.Unlock()
 
 	if c.conn == nil {
-		conn, events, err := dialZk(ctx, c.addr)
+		servers := c.servers
+		if len(servers) == 0 {
+			servers = strings.Split(c.addr, ",")
+		}
+		// Make sure we re-resolve the DNS name every time we reconnect to a server
+		// In environments where DNS changes such as Kubernetes we can't cache the IP address
+		hostProvider := &zk.SimpleDNSHostProvider{}
+		conn, events, err := dialZk(ctx, c.addr, servers, hostProvider, c.sessionTimeout)
 		if err != nil {
 			return nil, err
 		}
 		c.conn = conn
 		go c.handleSessionEvents(conn, events)
 		c.maybeAddAuth(ctx)
+		if dynamicReconfig {
+			go c.watchEnsemble(conn, hostProvider)
+		}
 	}
 	return c.conn, nil
 }
@@ -346,9 +376,13 @@ func (c *ZkConn) handleSessionEvents(conn *zk.Conn, session <-chan zk.Event) {
 	}
 }
 
-// dialZk dials the server, and waits until connection.
-func dialZk(ctx context.Context, addr string) (*zk.Conn, <-chan zk.Event, error) {
-	servers := strings.Split(addr, ",")
+// dialZk dials the servers, and waits until connection. addr is the
+// configured server address, used to derive the TLS server name. The session
+// has the given timeout, or --topo_zk_base_timeout if 0.
+func dialZk(ctx context.Context, addr string, servers []string, hostProvider zk.HostProvider, sessionTimeout time.Duration) (*zk.Conn, <-chan zk.Event, error) {
+	if sessionTimeout <= 0 {
+		sessionTimeout = baseTimeout
+	}
 	dialer := zk.WithDialer(net.DialTimeout)
 	ctx, cancel := context.WithTimeout(ctx, baseTimeout)
 	defer cancel()
@@ -386,12 +420,8 @@ func dialZk(ctx context.Context, addr string) (*zk.Conn, <-chan zk.Event, error)
 			return tls.DialWithDialer(&d, network, address, tlsConfig)
 		})
 	}
-	// Make sure we re-resolve the DNS name every time we reconnect to a server
-	// In environments where DNS changes such as Kubernetes we can't cache the IP address
-	hostProvider := zk.WithHostProvider(&zk.SimpleDNSHostProvider{})
-
 	// zk.Connect automatically shuffles the servers
-	zconn, session, err := zk.Connect(servers, baseTimeout, dialer, hostProvider)
+	zconn, session, err := zk.Connect(servers, sessionTimeout, dialer, zk.WithHostProvider(hostProvider))
 	if err != nil {
 		return nil, nil, err
 	}
@@ -0,0 +1,361 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topotools
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+)
+
+// The names of the checks of ValidateCrossReferences, as reported in the
+// issues.
+const (
+	CheckRead             = "read"
+	CheckCellsAlias       = "cells_alias"
+	CheckVSchema          = "vschema"
+	CheckShardPrimary     = "shard_primary"
+	CheckReplicationGraph = "replication_graph"
+	CheckTabletShard      = "tablet_shard"
+	CheckSrvKeyspace      = "srv_keyspace"
+	CheckSrvVSchema       = "srv_vschema"
+	CheckRoutingRules     = "routing_rules"
+)
+
+// crossReferenceValidator accumulates the issues of ValidateCrossReferences.
+type crossReferenceValidator struct {
+	ts     *topo.Server
+	issues []*vtctldatapb.TopologyIssue
+
+	cells     []string
+	keyspaces []string
+	// shards maps the keyspaces to their shards.
+	shards map[string]map[string]*topo.ShardInfo
+}
+
+// ValidateCrossReferences checks the references between the records of the
+// topology: the cells of the cells aliases, the primaries of the shards, the
+// tablets of the replication graphs, the shards of the tablets, the shards and
+// keyspaces served in each cell, and the keyspaces of the routing rules. It
+// returns the inconsistencies found, sorted by path. Records that can't be
+// read are reported as issues too; an error is only returned when the cells
+// or keyspaces can't be listed.
+func ValidateCrossReferences(ctx context.Context, ts *topo.Server) ([]*vtctldatapb.TopologyIssue, error) {
+	cells, err := ts.GetKnownCells(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("GetKnownCells failed: %w", err)
+	}
+	keyspaces, err := ts.GetKeyspaces(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("GetKeyspaces failed: %w", err)
+	}
+	slices.Sort(cells)
+	slices.Sort(keyspaces)
+
+	v := &crossReferenceValidator{
+		ts:        ts,
+		cells:     cells,
+		keyspaces: keyspaces,
+		shards:    make(map[string]map[string]*topo.ShardInfo, len(keyspaces)),
+	}
+	v.validateCells(ctx)
+	v.validateKeyspaces(ctx)
+	for _, cell := range cells {
+		v.validateCell(ctx, cell)
+	}
+	v.validateRoutingRules(ctx)
+
+	slices.SortStableFunc(v.issues, func(a, b *vtctldatapb.TopologyIssue) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+	return v.issues, nil
+}
+
+func (v *crossReferenceValidator) add(severity vtctldatapb.TopologyIssue_Severity, check string, path string, format string, args ...any) {
+	v.issues = append(v.issues, &vtctldatapb.TopologyIssue{
+		Severity: severity,
+		Check:    check,
+		Path:     path,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+func (v *crossReferenceValidator) addReadError(path string, err error) {
+	v.add(vtctldatapb.TopologyIssue_ERROR, CheckRead, path, "cannot read: %v", err)
+}
+
+// validateCells checks that the cells of the cells aliases exist.
+func (v *crossReferenceValidator) validateCells(ctx context.Context) {
+	for _, cell := range v.cells {
+		if _, err := v.ts.GetCellInfo(ctx, cell, false /* strongRead */); err != nil {
+			v.addReadError(globalPath(topo.CellsPath, cell, topo.CellInfoFile), err)
+		}
+	}
+
+	aliases, err := v.ts.GetCellsAliases(ctx, false /* strongRead */)
+	if err != nil {
+		v.addReadError(globalPath(topo.CellsAliasesPath), err)
+		return
+	}
+	for name, alias := range aliases {
+		for _, cell := range alias.Cells {
+			if !slices.Contains(v.cells, cell) {
+				v.add(vtctldatapb.TopologyIssue_ERROR, CheckCellsAlias, globalPath(topo.CellsAliasesPath, name, topo.CellsAliasFile),
+					"cell %v does not exist", cell)
+			}
+		}
+	}
+}
+
+// validateKeyspaces checks the global records of the keyspaces and their
+// shards, and loads the shards for the checks of the cells.
+func (v *crossReferenceValidator) validateKeyspaces(ctx context.Context) {
+	for _, keyspace := range v.keyspaces {
+		if _, err := v.ts.GetKeyspace(ctx, keyspace); err != nil {
+			v.addReadError(globalPath(topo.KeyspacesPath, keyspace, topo.KeyspaceFile), err)
+		}
+
+		switch _, err := v.ts.GetVSchema(ctx, keyspace); {
+		case err == nil:
+		case topo.IsErrType(err, topo.NoNode):
+			v.add(vtctldatapb.TopologyIssue_WARNING, CheckVSchema, globalPath(topo.KeyspacesPath, keyspace, topo.VSchemaFile),
+				"keyspace has no vschema")
+		default:
+			v.addReadError(globalPath(topo.KeyspacesPath, keyspace, topo.VSchemaFile), err)
+		}
+
+		shards, err := v.ts.FindAllShardsInKeyspace(ctx, keyspace, nil)
+		if err != nil {
+			v.addReadError(globalPath(topo.KeyspacesPath, keyspace, topo.ShardsPath), err)
+			continue
+		}
+		v.shards[keyspace] = shards
+
+		for _, name := range sortedKeys(shards) {
+			shard := shards[name]
+			if shard.PrimaryAlias == nil {
+				continue
+			}
+			shardPath := globalPath(topo.KeyspacesPath, keyspace, topo.ShardsPath, name, topo.ShardFile)
+			tablet, err := v.ts.GetTablet(ctx, shard.PrimaryAlias)
+			switch {
+			case err == nil:
+				if tablet.Keyspace != keyspace || tablet.Shard != name {
+					v.add(vtctldatapb.TopologyIssue_ERROR, CheckShardPrimary, shardPath,
+						"primary tablet %v belongs to shard %v/%v", topoproto.TabletAliasString(shard.PrimaryAlias), tablet.Keyspace, tablet.Shard)
+				}
+			case topo.IsErrType(err, topo.NoNode):
+				v.add(vtctldatapb.TopologyIssue_ERROR, CheckShardPrimary, shardPath,
+					"primary tablet %v does not exist", topoproto.TabletAliasString(shard.PrimaryAlias))
+			default:
+				v.addReadError(tabletPath(shard.PrimaryAlias), err)
+			}
+		}
+	}
+}
+
+// validateCell checks the records of a cell against the global ones.
+func (v *crossReferenceValidator) validateCell(ctx context.Context, cell string) {
+	for _, keyspace := range v.keyspaces {
+		shards := v.shards[keyspace]
+		for _, name := range sortedKeys(shards) {
+			v.validateReplicationGraph(ctx, cell, keyspace, name)
+		}
+		v.validateSrvKeyspace(ctx, cell, keyspace, shards)
+	}
+
+	tablets, err := v.ts.GetTabletsByCell(ctx, cell, nil)
+	if err != nil {
+		v.addReadError(cellPath(cell, topo.TabletsPath), err)
+	}
+	for _, tablet := range tablets {
+		if tablet.Keyspace == "" {
+			continue
+		}
+		if _, ok := v.shards[tablet.Keyspace][tablet.Shard]; !ok {
+			v.add(vtctldatapb.TopologyIssue_ERROR, CheckTabletShard, tabletPath(tablet.Alias),
+				"shard %v/%v of the tablet does not exist", tablet.Keyspace, tablet.Shard)
+		}
+	}
+
+	names, err := v.ts.GetSrvKeyspaceNames(ctx, cell)
+	if err != nil && !topo.IsErrType(err, topo.NoNode) {
+		v.addReadError(cellPath(cell, topo.KeyspacesPath), err)
+	}
+	for _, name := range names {
+		if !slices.Contains(v.keyspaces, name) {
+			v.add(vtctldatapb.TopologyIssue_WARNING, CheckSrvKeyspace, cellPath(cell, topo.KeyspacesPath, name, topo.SrvKeyspaceFile),
+				"keyspace %v does not exist", name)
+		}
+	}
+
+	srvVSchemaPath := cellPath(cell, topo.SrvVSchemaFile)
+	srvVSchema, err := v.ts.GetSrvVSchema(ctx, cell)
+	switch {
+	case err == nil:
+		for _, name := range sortedKeys(srvVSchema.Keyspaces) {
+			if !slices.Contains(v.keyspaces, name) {
+				v.add(vtctldatapb.TopologyIssue_ERROR, CheckSrvVSchema, srvVSchemaPath,
+					"keyspace %v does not exist", name)
+			}
+		}
+		for _, keyspace := range v.keyspaces {
+			if _, ok := srvVSchema.Keyspaces[keyspace]; !ok {
+				v.add(vtctldatapb.TopologyIssue_WARNING, CheckSrvVSchema, srvVSchemaPath,
+					"keyspace %v is missing", keyspace)
+			}
+		}
+	case topo.IsErrType(err, topo.NoNode):
+		if len(v.keyspaces) > 0 {
+			v.add(vtctldatapb.TopologyIssue_WARNING, CheckSrvVSchema, srvVSchemaPath,
+				"cell has no SrvVSchema")
+		}
+	default:
+		v.addReadError(srvVSchemaPath, err)
+	}
+}
+
+// validateReplicationGraph checks that the tablets of the replication graph
+// of a shard in a cell exist, and belong to the shard.
+func (v *crossReferenceValidator) validateReplicationGraph(ctx context.Context, cell string, keyspace string, shard string) {
+	graphPath := cellPath(cell, topo.KeyspacesPath, keyspace, topo.ShardsPath, shard, topo.ShardReplicationFile)
+	sri, err := v.ts.GetShardReplication(ctx, cell, keyspace, shard)
+	switch {
+	case err == nil:
+	case topo.IsErrType(err, topo.NoNode):
+		return
+	default:
+		v.addReadError(graphPath, err)
+		return
+	}
+
+	for _, node := range sri.Nodes {
+		alias := topoproto.TabletAliasString(node.TabletAlias)
+		tablet, err := v.ts.GetTablet(ctx, node.TabletAlias)
+		switch {
+		case err == nil:
+			if tablet.Keyspace != keyspace || tablet.Shard != shard {
+				v.add(vtctldatapb.TopologyIssue_ERROR, CheckReplicationGraph, graphPath,
+					"tablet %v belongs to shard %v/%v", alias, tablet.Keyspace, tablet.Shard)
+			}
+		case topo.IsErrType(err, topo.NoNode):
+			v.add(vtctldatapb.TopologyIssue_ERROR, CheckReplicationGraph, graphPath,
+				"tablet %v does not exist", alias)
+		default:
+			v.addReadError(tabletPath(node.TabletAlias), err)
+		}
+	}
+}
+
+// validateSrvKeyspace checks that the SrvKeyspace of a keyspace in a cell
+// only serves shards that exist, and serves all the primary serving ones.
+func (v *crossReferenceValidator) validateSrvKeyspace(ctx context.Context, cell string, keyspace string, shards map[string]*topo.ShardInfo) {
+	var serving []string
+	for _, name := range sortedKeys(shards) {
+		if shards[name].IsPrimaryServing {
+			serving = append(serving, name)
+		}
+	}
+
+	srvKeyspacePath := cellPath(cell, topo.KeyspacesPath, keyspace, topo.SrvKeyspaceFile)
+	srvKeyspace, err := v.ts.GetSrvKeyspace(ctx, cell, keyspace)
+	switch {
+	case err == nil:
+	case topo.IsErrType(err, topo.NoNode):
+		if len(serving) > 0 {
+			v.add(vtctldatapb.TopologyIssue_WARNING, CheckSrvKeyspace, srvKeyspacePath,
+				"keyspace has primary serving shards but is not served in the cell")
+		}
+		return
+	default:
+		v.addReadError(srvKeyspacePath, err)
+		return
+	}
+
+	var primaryShards []string
+	for _, partition := range srvKeyspace.Partitions {
+		for _, ref := range partition.ShardReferences {
+			if _, ok := shards[ref.Name]; !ok {
+				v.add(vtctldatapb.TopologyIssue_ERROR, CheckSrvKeyspace, srvKeyspacePath,
+					"shard %v served to %v does not exist", ref.Name, topoproto.TabletTypeLString(partition.ServedType))
+			}
+			if partition.ServedType == topodatapb.TabletType_PRIMARY {
+				primaryShards = append(primaryShards, ref.Name)
+			}
+		}
+	}
+	for _, name := range serving {
+		if !slices.Contains(primaryShards, name) {
+			v.add(vtctldatapb.TopologyIssue_ERROR, CheckSrvKeyspace, srvKeyspacePath,
+				"primary serving shard %v is not served to primary", name)
+		}
+	}
+}
+
+// validateRoutingRules checks that the routing rules route to keyspaces that
+// exist.
+func (v *crossReferenceValidator) validateRoutingRules(ctx context.Context) {
+	rulesPath := globalPath(topo.RoutingRulesFile)
+	rules, err := v.ts.GetRoutingRules(ctx)
+	if err != nil {
+		v.addReadError(rulesPath, err)
+		return
+	}
+	for _, rule := range rules.Rules {
+		for _, to := range rule.ToTables {
+			keyspace, _, ok := strings.Cut(to, ".")
+			if !ok {
+				// Unqualified tables are resolved through the vschemas.
+				continue
+			}
+			keyspace, _, _ = strings.Cut(keyspace, "@")
+			if !slices.Contains(v.keyspaces, keyspace) {
+				v.add(vtctldatapb.TopologyIssue_WARNING, CheckRoutingRules, rulesPath,
+					"rule for %v routes to keyspace %v, which does not exist", rule.FromTable, keyspace)
+			}
+		}
+	}
+}
+
+func globalPath(parts ...string) string {
+	return cellPath(topo.GlobalCell, parts...)
+}
+
+func cellPath(cell string, parts ...string) string {
+	return "/" + path.Join(append([]string{cell}, parts...)...)
+}
+
+func tabletPath(alias *topodatapb.TabletAlias) string {
+	return cellPath(alias.Cell, topo.TabletsPath, topoproto.TabletAliasString(alias), topo.TabletFile)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
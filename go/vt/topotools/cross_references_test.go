@@ -0,0 +1,177 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topotools
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/test/utils"
+	"vitess.io/vitess/go/vt/logutil"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+)
+
+func TestValidateCrossReferences(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "zone1", "zone2")
+	defer ts.Close()
+
+	const keyspace = "ks"
+	require.NoError(t, ts.CreateKeyspace(ctx, keyspace, &topodatapb.Keyspace{}))
+	require.NoError(t, ts.SaveVSchema(ctx, keyspace, &vschemapb.Keyspace{}))
+	require.NoError(t, ts.CreateShard(ctx, keyspace, "-"))
+	primary := &topodatapb.Tablet{
+		Alias:    &topodatapb.TabletAlias{Cell: "zone1", Uid: 100},
+		Keyspace: keyspace,
+		Shard:    "-",
+		Type:     topodatapb.TabletType_PRIMARY,
+	}
+	require.NoError(t, ts.CreateTablet(ctx, primary))
+	_, err := ts.UpdateShardFields(ctx, keyspace, "-", func(si *topo.ShardInfo) error {
+		si.PrimaryAlias = primary.Alias
+		return nil
+	})
+	require.NoError(t, err)
+	require.NoError(t, RebuildKeyspace(ctx, logutil.NewMemoryLogger(), ts, keyspace, nil, false))
+	require.NoError(t, ts.RebuildSrvVSchema(ctx, nil))
+
+	issues, err := ValidateCrossReferences(ctx, ts)
+	require.NoError(t, err)
+	assert.Empty(t, issues)
+
+	// Break the references between the records.
+	require.NoError(t, ts.CreateKeyspace(ctx, "novschema", &topodatapb.Keyspace{}))
+	require.NoError(t, ts.CreateCellsAlias(ctx, "region", &topodatapb.CellsAlias{Cells: []string{"zone1", "zone3"}}))
+	require.NoError(t, ts.CreateTablet(ctx, &topodatapb.Tablet{
+		Alias:    &topodatapb.TabletAlias{Cell: "zone2", Uid: 200},
+		Keyspace: keyspace,
+		Shard:    "80-",
+		Type:     topodatapb.TabletType_REPLICA,
+	}))
+	conn, err := ts.ConnForCell(ctx, "zone1")
+	require.NoError(t, err)
+	require.NoError(t, conn.Delete(ctx, "tablets/zone1-0000000100/Tablet", nil))
+	require.NoError(t, ts.UpdateSrvKeyspace(ctx, "zone1", keyspace, &topodatapb.SrvKeyspace{
+		Partitions: []*topodatapb.SrvKeyspace_KeyspacePartition{{
+			ServedType:      topodatapb.TabletType_PRIMARY,
+			ShardReferences: []*topodatapb.ShardReference{{Name: "nope"}},
+		}},
+	}))
+	require.NoError(t, ts.DeleteSrvKeyspace(ctx, "zone2", keyspace))
+	require.NoError(t, ts.UpdateSrvKeyspace(ctx, "zone2", "gone", &topodatapb.SrvKeyspace{}))
+	require.NoError(t, ts.UpdateSrvVSchema(ctx, "zone1", &vschemapb.SrvVSchema{
+		Keyspaces: map[string]*vschemapb.Keyspace{
+			keyspace: {},
+			"gone":   {},
+		},
+	}))
+	require.NoError(t, ts.SaveRoutingRules(ctx, &vschemapb.RoutingRules{
+		Rules: []*vschemapb.RoutingRule{{FromTable: "t1", ToTables: []string{"other@replica.t1"}}},
+	}))
+
+	issues, err = ValidateCrossReferences(ctx, ts)
+	require.NoError(t, err)
+	utils.MustMatch(t, []*vtctldatapb.TopologyIssue{
+		{
+			Severity: vtctldatapb.TopologyIssue_WARNING,
+			Check:    CheckRoutingRules,
+			Path:     "/global/RoutingRules",
+			Message:  "rule for t1 routes to keyspace other, which does not exist",
+		},
+		{
+			Severity: vtctldatapb.TopologyIssue_ERROR,
+			Check:    CheckCellsAlias,
+			Path:     "/global/cells_aliases/region/CellsAlias",
+			Message:  "cell zone3 does not exist",
+		},
+		{
+			Severity: vtctldatapb.TopologyIssue_ERROR,
+			Check:    CheckShardPrimary,
+			Path:     "/global/keyspaces/ks/shards/-/Shard",
+			Message:  "primary tablet zone1-0000000100 does not exist",
+		},
+		{
+			Severity: vtctldatapb.TopologyIssue_WARNING,
+			Check:    CheckVSchema,
+			Path:     "/global/keyspaces/novschema/VSchema",
+			Message:  "keyspace has no vschema",
+		},
+		{
+			Severity: vtctldatapb.TopologyIssue_ERROR,
+			Check:    CheckSrvVSchema,
+			Path:     "/zone1/SrvVSchema",
+			Message:  "keyspace gone does not exist",
+		},
+		{
+			Severity: vtctldatapb.TopologyIssue_WARNING,
+			Check:    CheckSrvVSchema,
+			Path:     "/zone1/SrvVSchema",
+			Message:  "keyspace novschema is missing",
+		},
+		{
+			Severity: vtctldatapb.TopologyIssue_ERROR,
+			Check:    CheckSrvKeyspace,
+			Path:     "/zone1/keyspaces/ks/SrvKeyspace",
+			Message:  "shard nope served to primary does not exist",
+		},
+		{
+			Severity: vtctldatapb.TopologyIssue_ERROR,
+			Check:    CheckSrvKeyspace,
+			Path:     "/zone1/keyspaces/ks/SrvKeyspace",
+			Message:  "primary serving shard - is not served to primary",
+		},
+		{
+			Severity: vtctldatapb.TopologyIssue_ERROR,
+			Check:    CheckReplicationGraph,
+			Path:     "/zone1/keyspaces/ks/shards/-/ShardReplication",
+			Message:  "tablet zone1-0000000100 does not exist",
+		},
+		{
+			Severity: vtctldatapb.TopologyIssue_WARNING,
+			Check:    CheckSrvVSchema,
+			Path:     "/zone2/SrvVSchema",
+			Message:  "keyspace novschema is missing",
+		},
+		{
+			Severity: vtctldatapb.TopologyIssue_WARNING,
+			Check:    CheckSrvKeyspace,
+			Path:     "/zone2/keyspaces/gone/SrvKeyspace",
+			Message:  "keyspace gone does not exist",
+		},
+		{
+			Severity: vtctldatapb.TopologyIssue_WARNING,
+			Check:    CheckSrvKeyspace,
+			Path:     "/zone2/keyspaces/ks/SrvKeyspace",
+			Message:  "keyspace has primary serving shards but is not served in the cell",
+		},
+		{
+			Severity: vtctldatapb.TopologyIssue_ERROR,
+			Check:    CheckTabletShard,
+			Path:     "/zone2/tablets/zone2-0000000200/Tablet",
+			Message:  "shard ks/80- of the tablet does not exist",
+		},
+	}, issues)
+}
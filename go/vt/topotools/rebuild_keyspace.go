@@ -19,8 +19,11 @@ package topotools
 import (
 	"context"
 	"fmt"
+	"slices"
 	"sync"
 
+	"google.golang.org/protobuf/proto"
+
 	"vitess.io/vitess/go/vt/concurrency"
 	"vitess.io/vitess/go/vt/logutil"
 	"vitess.io/vitess/go/vt/topo"
@@ -31,7 +34,7 @@ import (
 
 // RebuildKeyspace rebuilds the serving graph data while locking out other changes.
 func RebuildKeyspace(ctx context.Context, log logutil.Logger, ts *topo.Server, keyspace string, cells []string, allowPartial bool) (err error) {
-	ctx, unlock, lockErr := ts.LockKeyspace(ctx, keyspace, "RebuildKeyspace")
+	ctx, unlock, lockErr := ts.LockKeyspaceRouting(ctx, keyspace, "RebuildKeyspace")
 	if lockErr != nil {
 		return lockErr
 	}
@@ -40,14 +43,14 @@ func RebuildKeyspace(ctx context.Context, log logutil.Logger, ts *topo.Server, k
 	return RebuildKeyspaceLocked(ctx, log, ts, keyspace, cells, allowPartial)
 }
 
-// RebuildKeyspaceLocked should only be used with an action lock on the keyspace
-// - otherwise the consistency of the serving graph data can't be
+// RebuildKeyspaceLocked should only be used with the routing lock of the
+// keyspace - otherwise the consistency of the serving graph data can't be
 // guaranteed.
 //
 // Take data from the global keyspace and rebuild the local serving
 // copies in each cell.
 func RebuildKeyspaceLocked(ctx context.Context, log logutil.Logger, ts *topo.Server, keyspace string, cells []string, allowPartial bool) error {
-	if err := topo.CheckKeyspaceLocked(ctx, keyspace); err != nil {
+	if err := topo.CheckKeyspaceRoutingLocked(ctx, keyspace); err != nil {
 		return err
 	}
 
@@ -156,3 +159,145 @@ func RebuildKeyspaceLocked(ctx context.Context, log logutil.Logger, ts *topo.Ser
 	wg.Wait()
 	return rec.Error()
 }
+
+// RebuildKeyspaceShards rebuilds the serving graph data of the given shards
+// of a keyspace while locking out other changes. See
+// RebuildKeyspaceShardsLocked.
+func RebuildKeyspaceShards(ctx context.Context, log logutil.Logger, ts *topo.Server, keyspace string, shards []string, cells []string, allowPartial bool) (err error) {
+	ctx, unlock, lockErr := ts.LockKeyspaceRouting(ctx, keyspace, "RebuildKeyspaceShards")
+	if lockErr != nil {
+		return lockErr
+	}
+	defer unlock(&err)
+
+	return RebuildKeyspaceShardsLocked(ctx, log, ts, keyspace, shards, cells, allowPartial)
+}
+
+// RebuildKeyspaceShardsLocked is the incremental version of
+// RebuildKeyspaceLocked, for when only the given shards changed: it patches
+// the references to these shards in the existing SrvKeyspace of each cell,
+// rather than recomputing it from all the shards of the keyspace, and leaves
+// the SrvKeyspaces it doesn't change alone, so their watchers are not woken
+// up. The cells without a SrvKeyspace yet are fully rebuilt.
+//
+// It should only be used with the routing lock of the keyspace.
+func RebuildKeyspaceShardsLocked(ctx context.Context, log logutil.Logger, ts *topo.Server, keyspace string, shards []string, cells []string, allowPartial bool) error {
+	if err := topo.CheckKeyspaceRoutingLocked(ctx, keyspace); err != nil {
+		return err
+	}
+
+	ki, err := ts.GetKeyspace(ctx, keyspace)
+	if err != nil {
+		return err
+	}
+
+	if len(cells) == 0 {
+		cells, err = ts.GetCellInfoNames(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	// A shard that doesn't exist anymore is removed from the partitions.
+	shardInfos := make(map[string]*topo.ShardInfo, len(shards))
+	for _, shard := range shards {
+		si, err := ts.GetShard(ctx, keyspace, shard)
+		switch {
+		case err == nil:
+			shardInfos[shard] = si
+		case topo.IsErrType(err, topo.NoNode):
+			shardInfos[shard] = nil
+		default:
+			return err
+		}
+	}
+
+	servedTypes := []topodatapb.TabletType{topodatapb.TabletType_PRIMARY, topodatapb.TabletType_REPLICA, topodatapb.TabletType_RDONLY}
+
+	var fullRebuildCells []string
+	srvKeyspaceMap := make(map[string]*topodatapb.SrvKeyspace)
+	for _, cell := range cells {
+		oldSrvKeyspace, err := ts.GetSrvKeyspace(ctx, cell, keyspace)
+		switch {
+		case err == nil:
+		case topo.IsErrType(err, topo.NoNode):
+			fullRebuildCells = append(fullRebuildCells, cell)
+			continue
+		default:
+			return err
+		}
+		for _, partition := range oldSrvKeyspace.GetPartitions() {
+			for _, shardTabletControl := range partition.GetShardTabletControls() {
+				if shardTabletControl.QueryServiceDisabled {
+					return fmt.Errorf("can't rebuild serving keyspace while a migration is on going. TabletControls is set for partition %v", partition)
+				}
+			}
+		}
+
+		srvKeyspace := oldSrvKeyspace.CloneVT()
+		srvKeyspace.ThrottlerConfig = ki.ThrottlerConfig
+		for _, shard := range shards {
+			si := shardInfos[shard]
+			// Only shards whose primary is in a serving state are referenced.
+			serving := si != nil && si.GetIsPrimaryServing()
+			for _, tabletType := range servedTypes {
+				partition := topoproto.SrvKeyspaceGetPartition(srvKeyspace, tabletType)
+				if partition == nil {
+					if !serving {
+						continue
+					}
+					partition = &topodatapb.SrvKeyspace_KeyspacePartition{
+						ServedType: tabletType,
+					}
+					srvKeyspace.Partitions = append(srvKeyspace.Partitions, partition)
+				}
+				partition.ShardReferences = slices.DeleteFunc(partition.ShardReferences, func(ref *topodatapb.ShardReference) bool {
+					return ref.Name == shard
+				})
+				if serving {
+					partition.ShardReferences = append(partition.ShardReferences, &topodatapb.ShardReference{
+						Name:     si.ShardName(),
+						KeyRange: si.KeyRange,
+					})
+				}
+			}
+		}
+		srvKeyspace.Partitions = slices.DeleteFunc(srvKeyspace.Partitions, func(partition *topodatapb.SrvKeyspace_KeyspacePartition) bool {
+			return len(partition.ShardReferences) == 0
+		})
+
+		if !(ki.KeyspaceType == topodatapb.KeyspaceType_SNAPSHOT && allowPartial) {
+			// skip this check for SNAPSHOT keyspaces so that incomplete keyspaces can still serve
+			if err := topo.OrderAndCheckPartitions(cell, srvKeyspace); err != nil {
+				return err
+			}
+		}
+
+		if proto.Equal(oldSrvKeyspace, srvKeyspace) {
+			log.Infof("SrvKeyspace of %v in cell %v is up to date", keyspace, cell)
+			continue
+		}
+		srvKeyspaceMap[cell] = srvKeyspace
+	}
+
+	rec := concurrency.AllErrorRecorder{}
+	wg := sync.WaitGroup{}
+	for cell, srvKeyspace := range srvKeyspaceMap {
+		wg.Add(1)
+		go func(cell string, srvKeyspace *topodatapb.SrvKeyspace) {
+			defer wg.Done()
+			if err := ts.UpdateSrvKeyspace(ctx, cell, keyspace, srvKeyspace); err != nil {
+				rec.RecordError(fmt.Errorf("writing serving data failed: %v", err))
+			}
+		}(cell, srvKeyspace)
+	}
+	wg.Wait()
+	if rec.HasErrors() {
+		return rec.Error()
+	}
+
+	if len(fullRebuildCells) > 0 {
+		return RebuildKeyspaceLocked(ctx, log, ts, keyspace, fullRebuildCells, allowPartial)
+	}
+	return nil
+}
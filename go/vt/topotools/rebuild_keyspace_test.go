@@ -0,0 +1,103 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topotools
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/test/utils"
+	"vitess.io/vitess/go/vt/logutil"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+func TestRebuildKeyspaceShards(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "cell1", "cell2")
+	defer ts.Close()
+	logger := logutil.NewMemoryLogger()
+
+	const keyspace = "ks"
+	require.NoError(t, ts.CreateKeyspace(ctx, keyspace, &topodatapb.Keyspace{}))
+	for _, shard := range []string{"-80", "80-"} {
+		require.NoError(t, ts.CreateShard(ctx, keyspace, shard))
+	}
+	require.NoError(t, RebuildKeyspace(ctx, logger, ts, keyspace, []string{"cell1"}, false))
+
+	// cell2 has no SrvKeyspace yet, so it is fully rebuilt.
+	require.NoError(t, RebuildKeyspaceShards(ctx, logger, ts, keyspace, []string{"-80"}, nil, false))
+	srvKeyspace1, err := ts.GetSrvKeyspace(ctx, "cell1", keyspace)
+	require.NoError(t, err)
+	srvKeyspace2, err := ts.GetSrvKeyspace(ctx, "cell2", keyspace)
+	require.NoError(t, err)
+	utils.MustMatch(t, srvKeyspace1, srvKeyspace2)
+
+	// The SrvKeyspaces are left alone when the shards didn't change.
+	conn, err := ts.ConnForCell(ctx, "cell1")
+	require.NoError(t, err)
+	_, version, err := conn.Get(ctx, "keyspaces/ks/SrvKeyspace")
+	require.NoError(t, err)
+	require.NoError(t, RebuildKeyspaceShards(ctx, logger, ts, keyspace, []string{"-80"}, nil, false))
+	_, newVersion, err := conn.Get(ctx, "keyspaces/ks/SrvKeyspace")
+	require.NoError(t, err)
+	assert.Equal(t, version.String(), newVersion.String())
+
+	// Split 80- in two: the new shards, created non-serving as they overlap
+	// it, replace it once they switch over.
+	for _, shard := range []string{"80-c0", "c0-"} {
+		require.NoError(t, ts.CreateShard(ctx, keyspace, shard))
+	}
+	lockCtx, unlock, err := ts.LockKeyspaceRouting(ctx, keyspace, "test")
+	require.NoError(t, err)
+	for shard, serving := range map[string]bool{"80-": false, "80-c0": true, "c0-": true} {
+		_, err = ts.UpdateShardFields(lockCtx, keyspace, shard, func(si *topo.ShardInfo) error {
+			si.IsPrimaryServing = serving
+			return nil
+		})
+		require.NoError(t, err)
+	}
+	err = RebuildKeyspaceShardsLocked(lockCtx, logger, ts, keyspace, []string{"80-", "80-c0", "c0-"}, nil, false)
+	unlock(&err)
+	require.NoError(t, err)
+
+	full := &topodatapb.SrvKeyspace{}
+	for _, cell := range []string{"cell1", "cell2"} {
+		srvKeyspace, err := ts.GetSrvKeyspace(ctx, cell, keyspace)
+		require.NoError(t, err)
+		for _, partition := range srvKeyspace.Partitions {
+			var names []string
+			for _, ref := range partition.ShardReferences {
+				names = append(names, ref.Name)
+			}
+			assert.Equal(t, []string{"-80", "80-c0", "c0-"}, names, "%v %v", cell, partition.ServedType)
+		}
+		full = srvKeyspace
+	}
+
+	// The incremental rebuild matches the full one.
+	require.NoError(t, RebuildKeyspace(ctx, logger, ts, keyspace, []string{"cell2"}, false))
+	srvKeyspace2, err = ts.GetSrvKeyspace(ctx, "cell2", keyspace)
+	require.NoError(t, err)
+	utils.MustMatch(t, full, srvKeyspace2)
+}
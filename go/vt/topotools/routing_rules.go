@@ -19,12 +19,15 @@ package topotools
 import (
 	"context"
 	"fmt"
+	"slices"
+	"sort"
 	"strings"
 
 	"vitess.io/vitess/go/vt/log"
 	"vitess.io/vitess/go/vt/topo"
 
 	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
 )
 
 // region routing rules
@@ -69,6 +72,23 @@ func SaveRoutingRules(ctx context.Context, ts *topo.Server, rules map[string][]s
 	return ts.SaveRoutingRules(ctx, rrs)
 }
 
+// UpdateRoutingRules reads the routing rules, passes them to update, and saves
+// them, under a RoutingRulesLock, so that the concurrent read-modify-writes of
+// the routing rules, e.g. by the traffic switches of different workflows,
+// can't lose each other's changes.
+func UpdateRoutingRules(ctx context.Context, ts *topo.Server, reason string, update func(ctx context.Context, rules map[string][]string) error) error {
+	return withRoutingRulesLock(ctx, ts, reason, func(ctx context.Context) error {
+		rules, err := GetRoutingRules(ctx, ts)
+		if err != nil {
+			return err
+		}
+		if err := update(ctx, rules); err != nil {
+			return err
+		}
+		return SaveRoutingRules(ctx, ts, rules)
+	})
+}
+
 // endregion
 
 // region shard routing rules
@@ -123,6 +143,24 @@ func SaveShardRoutingRules(ctx context.Context, ts *topo.Server, srr map[string]
 	return ts.SaveShardRoutingRules(ctx, srs)
 }
 
+// UpdateShardRoutingRules reads the shard routing rules, passes them to
+// update, and saves them, under a RoutingRulesLock, like UpdateRoutingRules.
+func UpdateShardRoutingRules(ctx context.Context, ts *topo.Server, reason string, update func(ctx context.Context, srr map[string]string) error) error {
+	return withRoutingRulesLock(ctx, ts, reason, func(ctx context.Context) error {
+		srr, err := GetShardRoutingRules(ctx, ts)
+		if err != nil {
+			return err
+		}
+		if srr == nil {
+			srr = make(map[string]string)
+		}
+		if err := update(ctx, srr); err != nil {
+			return err
+		}
+		return SaveShardRoutingRules(ctx, ts, srr)
+	})
+}
+
 // endregion
 
 // region keyspace routing rules
@@ -213,3 +251,129 @@ func UpdateKeyspaceRoutingRules(ctx context.Context, ts *topo.Server, reason str
 }
 
 // endregion
+
+// region routing rules swap
+
+// DiffRoutingRules returns the changes from the old to the new routing rules,
+// sorted by from table.
+func DiffRoutingRules(oldRules, newRules *vschemapb.RoutingRules) []*vtctldatapb.RoutingRuleChange {
+	oldMap, newMap := GetRoutingRulesMap(oldRules), GetRoutingRulesMap(newRules)
+	var changes []*vtctldatapb.RoutingRuleChange
+	for from, to := range newMap {
+		if oldTo, ok := oldMap[from]; !ok || !slices.Equal(oldTo, to) {
+			changes = append(changes, &vtctldatapb.RoutingRuleChange{FromTable: from, OldToTables: oldTo, NewToTables: to})
+		}
+	}
+	for from, oldTo := range oldMap {
+		if _, ok := newMap[from]; !ok {
+			changes = append(changes, &vtctldatapb.RoutingRuleChange{FromTable: from, OldToTables: oldTo})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].FromTable < changes[j].FromTable
+	})
+	return changes
+}
+
+// DiffShardRoutingRules returns the changes from the old to the new shard
+// routing rules, sorted by from keyspace and shard.
+func DiffShardRoutingRules(oldRules, newRules *vschemapb.ShardRoutingRules) []*vtctldatapb.ShardRoutingRuleChange {
+	oldMap, newMap := GetShardRoutingRulesMap(oldRules), GetShardRoutingRulesMap(newRules)
+	var changes []*vtctldatapb.ShardRoutingRuleChange
+	for from, to := range newMap {
+		if oldTo := oldMap[from]; oldTo != to {
+			fromKeyspace, shard := ParseShardRoutingRuleKey(from)
+			changes = append(changes, &vtctldatapb.ShardRoutingRuleChange{FromKeyspace: fromKeyspace, Shard: shard, OldToKeyspace: oldTo, NewToKeyspace: to})
+		}
+	}
+	for from, oldTo := range oldMap {
+		if _, ok := newMap[from]; !ok {
+			fromKeyspace, shard := ParseShardRoutingRuleKey(from)
+			changes = append(changes, &vtctldatapb.ShardRoutingRuleChange{FromKeyspace: fromKeyspace, Shard: shard, OldToKeyspace: oldTo})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].FromKeyspace != changes[j].FromKeyspace {
+			return changes[i].FromKeyspace < changes[j].FromKeyspace
+		}
+		return changes[i].Shard < changes[j].Shard
+	})
+	return changes
+}
+
+// SwapRoutingRules replaces the routing rules and the shard routing rules
+// with the given ones, leaving those that are nil as they are, and returns
+// the changes made to them. The rules are read and saved under a
+// RoutingRulesLock, so that concurrent swaps can't interleave. Nothing is
+// saved in a dry run.
+func SwapRoutingRules(ctx context.Context, ts *topo.Server, rr *vschemapb.RoutingRules, srr *vschemapb.ShardRoutingRules, dryRun bool) (rrChanges []*vtctldatapb.RoutingRuleChange, srrChanges []*vtctldatapb.ShardRoutingRuleChange, err error) {
+	if !dryRun {
+		lockCtx, unlock, lockErr := lockRoutingRules(ctx, ts, "SwapRoutingRules")
+		if lockErr != nil {
+			return nil, nil, lockErr
+		}
+		defer unlock(&err)
+		ctx = lockCtx
+	}
+
+	if rr != nil {
+		current, err := ts.GetRoutingRules(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+		rrChanges = DiffRoutingRules(current, rr)
+	}
+	if srr != nil {
+		current, err := ts.GetShardRoutingRules(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+		srrChanges = DiffShardRoutingRules(current, srr)
+	}
+	if dryRun {
+		return rrChanges, srrChanges, nil
+	}
+
+	if len(rrChanges) > 0 {
+		log.Infof("Swapping routing rules, changes: %v", rrChanges)
+		if err := ts.SaveRoutingRules(ctx, rr); err != nil {
+			return nil, nil, err
+		}
+	}
+	if len(srrChanges) > 0 {
+		log.Infof("Swapping shard routing rules, changes: %v", srrChanges)
+		if err := ts.SaveShardRoutingRules(ctx, srr); err != nil {
+			return nil, nil, err
+		}
+	}
+	return rrChanges, srrChanges, nil
+}
+
+// lockRoutingRules acquires a RoutingRulesLock, after creating empty keyspace
+// routing rules if there are none, as the lock is taken on their directory.
+func lockRoutingRules(ctx context.Context, ts *topo.Server, action string) (context.Context, func(*error), error) {
+	lockCtx, unlock, err := ts.LockRoutingRules(ctx, action)
+	if !topo.IsErrType(err, topo.NoNode) {
+		return lockCtx, unlock, err
+	}
+	if err := ts.CreateKeyspaceRoutingRules(ctx, &vschemapb.KeyspaceRoutingRules{}); err != nil && !topo.IsErrType(err, topo.NodeExists) {
+		return nil, nil, err
+	}
+	return ts.LockRoutingRules(ctx, action)
+}
+
+// withRoutingRulesLock runs f under a RoutingRulesLock, unless ctx already
+// holds it.
+func withRoutingRulesLock(ctx context.Context, ts *topo.Server, action string, f func(ctx context.Context) error) (err error) {
+	if topo.CheckRoutingRulesLocked(ctx) == nil {
+		return f(ctx)
+	}
+	lockCtx, unlock, lockErr := lockRoutingRules(ctx, ts, action)
+	if lockErr != nil {
+		return lockErr
+	}
+	defer unlock(&err)
+	return f(lockCtx)
+}
+
+// endregion
@@ -24,8 +24,12 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"vitess.io/vitess/go/test/utils"
 	"vitess.io/vitess/go/vt/topo"
 	"vitess.io/vitess/go/vt/topo/memorytopo"
+
+	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
 )
 
 func TestRoutingRulesRoundTrip(t *testing.T) {
@@ -163,3 +167,114 @@ func TestSaveKeyspaceRoutingRulesLocked(t *testing.T) {
 		require.Errorf(t, err, "routing_rules is not locked (no locksInfo)")
 	})
 }
+
+func TestSwapRoutingRules(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "zone1")
+	defer ts.Close()
+
+	err := SaveRoutingRules(ctx, ts, map[string][]string{
+		"t1": {"ks1.t1"},
+		"t2": {"ks1.t2"},
+	})
+	require.NoError(t, err)
+	err = SaveShardRoutingRules(ctx, ts, map[string]string{
+		GetShardRoutingRuleKey("ks1", "-80"): "ks2",
+	})
+	require.NoError(t, err)
+
+	rr := &vschemapb.RoutingRules{
+		Rules: []*vschemapb.RoutingRule{
+			{FromTable: "t1", ToTables: []string{"ks2.t1"}},
+			{FromTable: "t2", ToTables: []string{"ks1.t2"}},
+			{FromTable: "t3", ToTables: []string{"ks2.t3"}},
+		},
+	}
+	srr := &vschemapb.ShardRoutingRules{
+		Rules: []*vschemapb.ShardRoutingRule{
+			{FromKeyspace: "ks1", Shard: "80-", ToKeyspace: "ks2"},
+		},
+	}
+	wantRRChanges := []*vtctldatapb.RoutingRuleChange{
+		{FromTable: "t1", OldToTables: []string{"ks1.t1"}, NewToTables: []string{"ks2.t1"}},
+		{FromTable: "t3", NewToTables: []string{"ks2.t3"}},
+	}
+	wantSRRChanges := []*vtctldatapb.ShardRoutingRuleChange{
+		{FromKeyspace: "ks1", Shard: "-80", OldToKeyspace: "ks2"},
+		{FromKeyspace: "ks1", Shard: "80-", NewToKeyspace: "ks2"},
+	}
+
+	// A dry run reports the changes without making them.
+	rrChanges, srrChanges, err := SwapRoutingRules(ctx, ts, rr, srr, true)
+	require.NoError(t, err)
+	utils.MustMatch(t, wantRRChanges, rrChanges)
+	utils.MustMatch(t, wantSRRChanges, srrChanges)
+	rules, err := GetRoutingRules(ctx, ts)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ks1.t1"}, rules["t1"])
+
+	rrChanges, srrChanges, err = SwapRoutingRules(ctx, ts, rr, srr, false)
+	require.NoError(t, err)
+	utils.MustMatch(t, wantRRChanges, rrChanges)
+	utils.MustMatch(t, wantSRRChanges, srrChanges)
+	rules, err = GetRoutingRules(ctx, ts)
+	require.NoError(t, err)
+	assert.Equal(t, GetRoutingRulesMap(rr), rules)
+	shardRules, err := GetShardRoutingRules(ctx, ts)
+	require.NoError(t, err)
+	assert.Equal(t, GetShardRoutingRulesMap(srr), shardRules)
+
+	// Swapping in the same rules changes nothing, and nil rules are left
+	// alone.
+	rrChanges, srrChanges, err = SwapRoutingRules(ctx, ts, rr, nil, false)
+	require.NoError(t, err)
+	assert.Empty(t, rrChanges)
+	assert.Empty(t, srrChanges)
+	shardRules, err = GetShardRoutingRules(ctx, ts)
+	require.NoError(t, err)
+	assert.Equal(t, GetShardRoutingRulesMap(srr), shardRules)
+}
+
+func TestUpdateRoutingRules(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "zone1")
+	defer ts.Close()
+
+	err := UpdateRoutingRules(ctx, ts, "test", func(ctx context.Context, rules map[string][]string) error {
+		require.NoError(t, topo.CheckRoutingRulesLocked(ctx))
+		rules["t1"] = []string{"ks1.t1"}
+		return nil
+	})
+	require.NoError(t, err)
+	err = UpdateShardRoutingRules(ctx, ts, "test", func(ctx context.Context, srr map[string]string) error {
+		require.NoError(t, topo.CheckRoutingRulesLocked(ctx))
+		srr[GetShardRoutingRuleKey("ks1", "-80")] = "ks2"
+		return nil
+	})
+	require.NoError(t, err)
+
+	// The updates can be nested under a RoutingRulesLock, and the rules are
+	// not saved when the update fails.
+	lockCtx, unlock, err := ts.LockRoutingRules(ctx, "test")
+	require.NoError(t, err)
+	err = UpdateRoutingRules(lockCtx, ts, "test", func(ctx context.Context, rules map[string][]string) error {
+		rules["t2"] = []string{"ks1.t2"}
+		return nil
+	})
+	require.NoError(t, err)
+	err = UpdateRoutingRules(lockCtx, ts, "test", func(ctx context.Context, rules map[string][]string) error {
+		rules["t3"] = []string{"ks1.t3"}
+		return errors.New("update failed")
+	})
+	require.ErrorContains(t, err, "update failed")
+	unlock(&err)
+
+	rules, err := GetRoutingRules(ctx, ts)
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{"t1": {"ks1.t1"}, "t2": {"ks1.t2"}}, rules)
+	shardRules, err := GetShardRoutingRules(ctx, ts)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"ks1.-80": "ks2"}, shardRules)
+}
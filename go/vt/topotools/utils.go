@@ -21,6 +21,7 @@ import (
 	"sync"
 
 	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/topoproto"
 
 	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
 )
@@ -36,12 +37,16 @@ func FindTabletByHostAndPort(tabletMap map[string]*topo.TabletInfo, addr, portNa
 }
 
 // GetTabletMapForCell returns a map of TabletInfo keyed by alias as string
+// It reads the tablets a page at a time, so large cells do not spawn a read
+// for each of their tablets at once.
 func GetTabletMapForCell(ctx context.Context, ts *topo.Server, cell string) (map[string]*topo.TabletInfo, error) {
-	aliases, err := ts.GetTabletAliasesByCell(ctx, cell)
-	if err != nil {
-		return nil, err
-	}
-	tabletMap, err := ts.GetTabletMap(ctx, aliases, nil)
+	tabletMap := make(map[string]*topo.TabletInfo)
+	err := ts.StreamTabletsByCell(ctx, cell, nil, func(tablets []*topo.TabletInfo) error {
+		for _, ti := range tablets {
+			tabletMap[topoproto.TabletAliasString(ti.Alias)] = ti
+		}
+		return nil
+	})
 	if err != nil {
 		// we got another error than topo.ErrNoNode
 		return nil, err
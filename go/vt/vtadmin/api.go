@@ -26,6 +26,7 @@ import (
 	stdsort "sort"
 	"strings"
 	"sync"
+	"text/template"
 	"time"
 
 	"github.com/gorilla/handlers"
@@ -36,9 +37,11 @@ import (
 	"vitess.io/vitess/go/vt/vtenv"
 
 	"vitess.io/vitess/go/sets"
+	"vitess.io/vitess/go/textutil"
 	"vitess.io/vitess/go/trace"
 	"vitess.io/vitess/go/vt/concurrency"
 	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/servenv"
 	"vitess.io/vitess/go/vt/topo"
 	"vitess.io/vitess/go/vt/topo/memorytopo"
 	"vitess.io/vitess/go/vt/topo/topoproto"
@@ -76,6 +79,10 @@ type API struct {
 
 	authz *rbac.Authorizer
 
+	// components makes the requests to the http servers of the tablets,
+	// vtgates and vtctlds.
+	components *componentClient
+
 	options Options
 
 	// vtexplain is now global again due to stat exporters in the tablet layer
@@ -144,6 +151,7 @@ func NewAPI(env *vtenv.Environment, clusters []*cluster.Cluster, opts Options) *
 		clusters:   clusters,
 		clusterMap: clusterMap,
 		authz:      authz,
+		components: newComponentClient(opts.HTTPOpts),
 		env:        env,
 	}
 
@@ -362,8 +370,14 @@ func (api *API) Handler() http.Handler {
 	router.HandleFunc("/cells", httpAPI.Adapt(vtadminhttp.GetCellInfos)).Name("API.GetCellInfos")
 	router.HandleFunc("/cells_aliases", httpAPI.Adapt(vtadminhttp.GetCellsAliases)).Name("API.GetCellsAliases")
 	router.HandleFunc("/clusters", httpAPI.Adapt(vtadminhttp.GetClusters)).Name("API.GetClusters")
+	router.HandleFunc("/cluster/{cluster_id}/elections", httpAPI.Adapt(vtadminhttp.GetElections)).Name("API.GetElections")
+	router.HandleFunc("/cluster/{cluster_id}/locks", httpAPI.Adapt(vtadminhttp.GetLocks)).Name("API.GetLocks")
+	router.HandleFunc("/cluster/{cluster_id}/locks/force_unlock", httpAPI.Adapt(vtadminhttp.ForceUnlock)).Name("API.ForceUnlock").Methods("PUT", "OPTIONS")
 	router.HandleFunc("/cluster/{cluster_id}/topology", httpAPI.Adapt(vtadminhttp.GetTopologyPath)).Name("API.GetTopologyPath")
+	router.HandleFunc("/cluster/{cluster_id}/topology/tree", httpAPI.Adapt(vtadminhttp.ListTopologyPath)).Name("API.ListTopologyPath")
 	router.HandleFunc("/cluster/{cluster_id}/validate", httpAPI.Adapt(vtadminhttp.Validate)).Name("API.Validate").Methods("PUT", "OPTIONS")
+	router.HandleFunc("/fleet", httpAPI.Adapt(vtadminhttp.GetFleetInfo)).Name("API.GetFleetInfo")
+	router.HandleFunc("/fleet/config_drift", httpAPI.Adapt(vtadminhttp.GetConfigDrift)).Name("API.GetConfigDrift")
 	router.HandleFunc("/gates", httpAPI.Adapt(vtadminhttp.GetGates)).Name("API.GetGates")
 	router.HandleFunc("/keyspace/{cluster_id}", httpAPI.Adapt(vtadminhttp.CreateKeyspace)).Name("API.CreateKeyspace").Methods("POST")
 	router.HandleFunc("/keyspace/{cluster_id}/{name}", httpAPI.Adapt(vtadminhttp.DeleteKeyspace)).Name("API.DeleteKeyspace").Methods("DELETE")
@@ -715,6 +729,40 @@ func (api *API) FindSchema(ctx context.Context, req *vtadminpb.FindSchemaRequest
 	}
 }
 
+// ForceUnlock is part of the vtadminpb.VTAdminServer interface.
+func (api *API) ForceUnlock(ctx context.Context, req *vtadminpb.ForceUnlockRequest) (*vtctldatapb.ForceUnlockResponse, error) {
+	span, ctx := trace.NewSpan(ctx, "API.ForceUnlock")
+	defer span.Finish()
+
+	c, err := api.getClusterForRequest(req.ClusterId)
+	if err != nil {
+		return nil, err
+	}
+
+	cluster.AnnotateSpan(c, span)
+
+	if !api.authz.IsAuthorized(ctx, c.ID, rbac.LockResource, rbac.DeleteAction) {
+		return nil, fmt.Errorf("%w: cannot force unlock in %s", errors.ErrUnauthorized, c.ID)
+	}
+
+	if req.Options == nil {
+		return nil, fmt.Errorf("%w: options are required", errors.ErrInvalidRequest)
+	}
+
+	// vtctld records its own caller, i.e. vtadmin, in the audit log, so the
+	// actor who broke the lock is recorded as the actor of the entry.
+	options := &vtctldatapb.ForceUnlockRequest{
+		Path:   req.Options.Path,
+		MinAge: req.Options.MinAge,
+		Reason: req.Options.Reason,
+	}
+	if actor, ok := rbac.FromContext(ctx); ok {
+		options.Actor = actor.Name
+	}
+
+	return c.ForceUnlock(ctx, options)
+}
+
 // GetBackups is part of the vtadminpb.VTAdminServer interface.
 func (api *API) GetBackups(ctx context.Context, req *vtadminpb.GetBackupsRequest) (*vtadminpb.GetBackupsResponse, error) {
 	span, ctx := trace.NewSpan(ctx, "API.GetBackups")
@@ -882,6 +930,262 @@ func (api *API) GetClusters(ctx context.Context, req *vtadminpb.GetClustersReque
 	}, nil
 }
 
+// GetConfigDrift is part of the vtadminpb.VTAdminServer interface.
+func (api *API) GetConfigDrift(ctx context.Context, req *vtadminpb.GetConfigDriftRequest) (*vtadminpb.GetConfigDriftResponse, error) {
+	span, ctx := trace.NewSpan(ctx, "API.GetConfigDrift")
+	defer span.Finish()
+
+	span.Annotate("include_settings", req.IncludeSettings)
+
+	clusters, _ := api.getClustersForRequest(req.ClusterIds)
+
+	type fetchedConfig struct {
+		cluster  *vtadminpb.Cluster
+		kind     string
+		config   *vtadminpb.ComponentConfig
+		settings map[string]string
+	}
+
+	var (
+		m       sync.Mutex
+		configs []*fetchedConfig
+	)
+
+	err := api.fetchComponents(ctx, clusters, func(c *vtadminpb.Cluster, kind, name, baseURL string) {
+		config, settings := api.components.fetchConfig(ctx, baseURL)
+		config.Name = name
+
+		m.Lock()
+		defer m.Unlock()
+		configs = append(configs, &fetchedConfig{cluster: c, kind: kind, config: config, settings: settings})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	groups := map[string]*vtadminpb.ConfigDriftGroup{}
+	settingsByGroup := map[string][]*fetchedConfig{}
+	for _, fc := range configs {
+		key := fc.cluster.GetId() + "/" + fc.kind
+		group, ok := groups[key]
+		if !ok {
+			group = &vtadminpb.ConfigDriftGroup{
+				Cluster: fc.cluster,
+				Kind:    fc.kind,
+			}
+			groups[key] = group
+		}
+		group.Components = append(group.Components, fc.config)
+		settingsByGroup[key] = append(settingsByGroup[key], fc)
+	}
+
+	resp := &vtadminpb.GetConfigDriftResponse{
+		Groups: make([]*vtadminpb.ConfigDriftGroup, 0, len(groups)),
+	}
+	for key, group := range groups {
+		if baseline, ok := req.Baselines[group.Kind]; ok {
+			group.BaselineHash = baseline
+			group.BaselineDeclared = true
+		} else {
+			group.BaselineHash = mostCommonConfigHash(group.Components)
+		}
+
+		var baselineSettings map[string]string
+		for _, fc := range settingsByGroup[key] {
+			if fc.config.Error == "" && fc.config.ConfigHash == group.BaselineHash {
+				baselineSettings = fc.settings
+				break
+			}
+		}
+
+		for _, fc := range settingsByGroup[key] {
+			if fc.config.Error != "" || fc.config.ConfigHash == group.BaselineHash {
+				continue
+			}
+
+			fc.config.Drifted = true
+			if baselineSettings != nil {
+				fc.config.DriftedKeys = driftedKeys(baselineSettings, fc.settings)
+			}
+		}
+
+		if req.IncludeSettings {
+			for _, fc := range settingsByGroup[key] {
+				fc.config.Settings = fc.settings
+			}
+		}
+
+		stdsort.Slice(group.Components, func(i, j int) bool {
+			return group.Components[i].Name < group.Components[j].Name
+		})
+		resp.Groups = append(resp.Groups, group)
+	}
+
+	stdsort.Slice(resp.Groups, func(i, j int) bool {
+		a, b := resp.Groups[i], resp.Groups[j]
+		if a.Cluster.GetId() != b.Cluster.GetId() {
+			return a.Cluster.GetId() < b.Cluster.GetId()
+		}
+		return a.Kind < b.Kind
+	})
+
+	return resp, nil
+}
+
+// GetElections is part of the vtadminpb.VTAdminServer interface.
+func (api *API) GetElections(ctx context.Context, req *vtadminpb.GetElectionsRequest) (*vtctldatapb.GetElectionsResponse, error) {
+	span, ctx := trace.NewSpan(ctx, "API.GetElections")
+	defer span.Finish()
+
+	c, err := api.getClusterForRequest(req.ClusterId)
+	if err != nil {
+		return nil, err
+	}
+
+	cluster.AnnotateSpan(c, span)
+
+	if !api.authz.IsAuthorized(ctx, c.ID, rbac.TopologyResource, rbac.GetAction) {
+		return nil, fmt.Errorf("%w: cannot get elections in %s", errors.ErrUnauthorized, c.ID)
+	}
+
+	return c.Vtctld.GetElections(ctx, &vtctldatapb.GetElectionsRequest{})
+}
+
+// GetFleetInfo is part of the vtadminpb.VTAdminServer interface.
+func (api *API) GetFleetInfo(ctx context.Context, req *vtadminpb.GetFleetInfoRequest) (*vtadminpb.GetFleetInfoResponse, error) {
+	span, ctx := trace.NewSpan(ctx, "API.GetFleetInfo")
+	defer span.Finish()
+
+	clusters, _ := api.getClustersForRequest(req.ClusterIds)
+
+	var (
+		m          sync.Mutex
+		components []*vtadminpb.ComponentInfo
+	)
+
+	err := api.fetchComponents(ctx, clusters, func(c *vtadminpb.Cluster, kind, name, baseURL string) {
+		info := api.components.fetchInfo(ctx, baseURL)
+		info.Cluster = c
+		info.Kind = kind
+		info.Name = name
+
+		m.Lock()
+		defer m.Unlock()
+		components = append(components, info)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	stdsort.Slice(components, func(i, j int) bool {
+		a, b := components[i], components[j]
+		if a.Cluster.GetId() != b.Cluster.GetId() {
+			return a.Cluster.GetId() < b.Cluster.GetId()
+		}
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		return a.Name < b.Name
+	})
+
+	versions := map[string]int32{}
+	for _, info := range components {
+		if info.Error == "" {
+			versions[info.Version]++
+		}
+	}
+
+	return &vtadminpb.GetFleetInfoResponse{
+		Components: components,
+		Versions:   versions,
+	}, nil
+}
+
+// fetchComponents calls fetch concurrently for each tablet, vtgate and vtctld
+// of the given clusters the caller is authorized to get, with its kind
+// (vttablet, vtgate or vtctld), its name, and the base URL of its http
+// server, and waits for the calls to return.
+func (api *API) fetchComponents(ctx context.Context, clusters []*cluster.Cluster, fetch func(c *vtadminpb.Cluster, kind, name, baseURL string)) error {
+	tabletURLTmpl, err := template.New("tablet-url").Parse(api.options.HTTPOpts.ExperimentalOptions.TabletURLTmpl)
+	if err != nil {
+		return err
+	}
+
+	var (
+		wg  sync.WaitGroup
+		rec concurrency.AllErrorRecorder
+	)
+
+	fetchOne := func(c *vtadminpb.Cluster, kind, name, baseURL string) {
+		defer wg.Done()
+		fetch(c, kind, name, baseURL)
+	}
+
+	for _, c := range clusters {
+		if api.authz.IsAuthorized(ctx, c.ID, rbac.TabletResource, rbac.GetAction) {
+			wg.Add(1)
+			go func(c *cluster.Cluster) {
+				defer wg.Done()
+
+				tablets, err := c.GetTablets(ctx)
+				if err != nil {
+					rec.RecordError(err)
+					return
+				}
+				for _, tablet := range tablets {
+					baseURL, err := textutil.ExecuteTemplate(tabletURLTmpl, tablet)
+					if err != nil {
+						rec.RecordError(err)
+						return
+					}
+					wg.Add(1)
+					go fetchOne(tablet.Cluster, "vttablet", topoproto.TabletAliasString(tablet.Tablet.Alias), baseURL)
+				}
+			}(c)
+		}
+
+		if api.authz.IsAuthorized(ctx, c.ID, rbac.VTGateResource, rbac.GetAction) {
+			wg.Add(1)
+			go func(c *cluster.Cluster) {
+				defer wg.Done()
+
+				gates, err := c.GetGates(ctx)
+				if err != nil {
+					rec.RecordError(err)
+					return
+				}
+				for _, gate := range gates {
+					wg.Add(1)
+					go fetchOne(gate.Cluster, "vtgate", gate.Hostname, gate.FQDN)
+				}
+			}(c)
+		}
+
+		if api.authz.IsAuthorized(ctx, c.ID, rbac.VtctldResource, rbac.GetAction) {
+			wg.Add(1)
+			go func(c *cluster.Cluster) {
+				defer wg.Done()
+
+				vtctlds, err := c.GetVtctlds(ctx)
+				if err != nil {
+					rec.RecordError(err)
+					return
+				}
+				for _, vtctld := range vtctlds {
+					wg.Add(1)
+					go fetchOne(vtctld.Cluster, "vtctld", vtctld.Hostname, vtctld.FQDN)
+				}
+			}(c)
+		}
+	}
+
+	wg.Wait()
+	if rec.HasErrors() {
+		return rec.Error()
+	}
+	return nil
+}
+
 // GetFullStatus is part of the vtadminpb.VTAdminServer interface.
 func (api *API) GetFullStatus(ctx context.Context, req *vtadminpb.GetFullStatusRequest) (*vtctldatapb.GetFullStatusResponse, error) {
 	span, ctx := trace.NewSpan(ctx, "API.GetFullStatus")
@@ -997,7 +1301,11 @@ func (api *API) GetKeyspaces(ctx context.Context, req *vtadminpb.GetKeyspacesReq
 			}
 
 			m.Lock()
-			keyspaces = append(keyspaces, kss...)
+			for _, ks := range kss {
+				if topoproto.KeyspaceHasLabels(ks.Keyspace.GetKeyspace(), req.Labels) {
+					keyspaces = append(keyspaces, ks)
+				}
+			}
 			m.Unlock()
 		}(c)
 	}
@@ -1013,6 +1321,25 @@ func (api *API) GetKeyspaces(ctx context.Context, req *vtadminpb.GetKeyspacesReq
 	}, nil
 }
 
+// GetLocks is part of the vtadminpb.VTAdminServer interface.
+func (api *API) GetLocks(ctx context.Context, req *vtadminpb.GetLocksRequest) (*vtctldatapb.GetLocksResponse, error) {
+	span, ctx := trace.NewSpan(ctx, "API.GetLocks")
+	defer span.Finish()
+
+	c, err := api.getClusterForRequest(req.ClusterId)
+	if err != nil {
+		return nil, err
+	}
+
+	cluster.AnnotateSpan(c, span)
+
+	if !api.authz.IsAuthorized(ctx, c.ID, rbac.LockResource, rbac.GetAction) {
+		return nil, fmt.Errorf("%w: cannot get locks in %s", errors.ErrUnauthorized, c.ID)
+	}
+
+	return c.GetLocks(ctx, req.Options)
+}
+
 // GetSchema is part of the vtadminpb.VTAdminServer interface.
 func (api *API) GetSchema(ctx context.Context, req *vtadminpb.GetSchemaRequest) (*vtadminpb.Schema, error) {
 	span, ctx := trace.NewSpan(ctx, "API.GetSchema")
@@ -1732,6 +2059,27 @@ func (api *API) LaunchSchemaMigration(ctx context.Context, req *vtadminpb.Launch
 	return c.LaunchSchemaMigration(ctx, req.Request)
 }
 
+// ListTopologyPath is part of the vtadminpb.VTAdminServer interface.
+func (api *API) ListTopologyPath(ctx context.Context, req *vtadminpb.ListTopologyPathRequest) (*vtctldatapb.ListTopologyPathResponse, error) {
+	span, ctx := trace.NewSpan(ctx, "API.ListTopologyPath")
+	defer span.Finish()
+
+	c, err := api.getClusterForRequest(req.ClusterId)
+	if err != nil {
+		return nil, err
+	}
+
+	cluster.AnnotateSpan(c, span)
+	span.Annotate("path", req.Request.GetPath())
+	span.Annotate("page_token", req.Request.GetPageToken())
+
+	if !api.authz.IsAuthorized(ctx, c.ID, rbac.TopologyResource, rbac.GetAction) {
+		return nil, fmt.Errorf("%w: cannot list topology in %s", errors.ErrUnauthorized, c.ID)
+	}
+
+	return c.Vtctld.ListTopologyPath(ctx, req.Request)
+}
+
 // PingTablet is part of the vtadminpb.VTAdminServer interface.
 func (api *API) PingTablet(ctx context.Context, req *vtadminpb.PingTabletRequest) (*vtadminpb.PingTabletResponse, error) {
 	span, ctx := trace.NewSpan(ctx, "API.PingTablet")
@@ -2520,3 +2868,166 @@ func (api *API) getTabletForResourceAndAction(
 
 	return nil, nil, vterrors.Errorf(vtrpcpb.Code_NOT_FOUND, "%s: %s, searched clusters = %v", errors.ErrAmbiguousTablet, alias, ids)
 }
+
+// componentClient makes the requests to the http servers of the tablets,
+// vtgates and vtctlds.
+type componentClient struct {
+	client *http.Client
+	// scheme is the scheme of the addresses without one.
+	scheme string
+}
+
+// newComponentClient returns a componentClient with the timeout and the TLS
+// config of opts.
+func newComponentClient(opts vtadminhttp.Options) *componentClient {
+	cc := &componentClient{
+		client: &http.Client{Timeout: opts.ComponentTimeout},
+		scheme: "http",
+	}
+	if opts.ComponentTLSConfig != nil {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = opts.ComponentTLSConfig
+		cc.client.Transport = transport
+		cc.scheme = "https"
+	}
+	return cc
+}
+
+// get fetches the given path from the http server of the component at
+// baseURL, and returns its response if its status is OK. The caller must
+// close its body.
+func (cc *componentClient) get(ctx context.Context, baseURL, path string) (*http.Response, error) {
+	if baseURL == "" {
+		return nil, fmt.Errorf("no http address")
+	}
+	if !strings.Contains(baseURL, "://") {
+		baseURL = cc.scheme + "://" + baseURL
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", strings.TrimSuffix(baseURL, "/")+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := cc.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return resp, nil
+}
+
+// fetchConfig fetches the config hash the component whose http server is at
+// baseURL reports in its component info, and its effective settings from its
+// /debug/config endpoint, which redacts the values of its secrets. The
+// settings are returned as JSON values by dot-separated key. Errors are
+// reported in the returned config.
+func (cc *componentClient) fetchConfig(ctx context.Context, baseURL string) (*vtadminpb.ComponentConfig, map[string]string) {
+	info := cc.fetchInfo(ctx, baseURL)
+	if info.Error != "" {
+		return &vtadminpb.ComponentConfig{Error: info.Error}, nil
+	}
+
+	resp, err := cc.get(ctx, baseURL, "/debug/config?format=json")
+	if err != nil {
+		return &vtadminpb.ComponentConfig{Error: err.Error()}, nil
+	}
+	defer resp.Body.Close()
+
+	var nested map[string]any
+	if err := json.NewDecoder(resp.Body).Decode(&nested); err != nil {
+		return &vtadminpb.ComponentConfig{Error: err.Error()}, nil
+	}
+
+	settings := map[string]string{}
+	if err := flattenSettings(settings, "", nested); err != nil {
+		return &vtadminpb.ComponentConfig{Error: err.Error()}, nil
+	}
+	return &vtadminpb.ComponentConfig{ConfigHash: info.ConfigHash}, settings
+}
+
+// flattenSettings adds the nested settings to flat, as JSON values by
+// dot-separated key.
+func flattenSettings(flat map[string]string, prefix string, nested map[string]any) error {
+	for key, value := range nested {
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		if m, ok := value.(map[string]any); ok {
+			if err := flattenSettings(flat, key, m); err != nil {
+				return err
+			}
+			continue
+		}
+
+		data, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		flat[key] = string(data)
+	}
+	return nil
+}
+
+// mostCommonConfigHash returns the config hash of the most components among
+// the ones whose config was fetched. Ties go to the lowest hash.
+func mostCommonConfigHash(components []*vtadminpb.ComponentConfig) string {
+	counts := map[string]int{}
+	for _, config := range components {
+		if config.Error == "" {
+			counts[config.ConfigHash]++
+		}
+	}
+
+	var hash string
+	for h, count := range counts {
+		if count > counts[hash] || (count == counts[hash] && h < hash) {
+			hash = h
+		}
+	}
+	return hash
+}
+
+// driftedKeys returns the keys of the settings whose values differ from the
+// baseline ones, sorted.
+func driftedKeys(baseline, settings map[string]string) []string {
+	var keys []string
+	for key, value := range settings {
+		if baselineValue, ok := baseline[key]; !ok || value != baselineValue {
+			keys = append(keys, key)
+		}
+	}
+	for key := range baseline {
+		if _, ok := settings[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	stdsort.Strings(keys)
+	return keys
+}
+
+// fetchInfo fetches the version and the configuration a component serves at
+// /debug/component_info. It never fails; the error of the fetch, if any, is
+// recorded in the returned ComponentInfo instead, so one unreachable component
+// does not hide the rest of the fleet.
+func (cc *componentClient) fetchInfo(ctx context.Context, baseURL string) *vtadminpb.ComponentInfo {
+	resp, err := cc.get(ctx, baseURL, servenv.ComponentInfoPath)
+	if err != nil {
+		return &vtadminpb.ComponentInfo{Error: err.Error()}
+	}
+	defer resp.Body.Close()
+
+	var info servenv.ComponentInfo
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return &vtadminpb.ComponentInfo{Error: err.Error()}
+	}
+
+	return &vtadminpb.ComponentInfo{
+		Version:      info.Version,
+		GitRevision:  info.GitRevision,
+		FeatureFlags: info.FeatureFlags,
+		ConfigHash:   info.ConfigHash,
+	}
+}
@@ -803,6 +803,73 @@ func TestFindSchema(t *testing.T) {
 	})
 }
 
+func TestForceUnlock(t *testing.T) {
+	t.Parallel()
+
+	opts := vtadmin.Options{
+		RBAC: &rbac.Config{
+			Rules: []*struct {
+				Resource string
+				Actions  []string
+				Subjects []string
+				Clusters []string
+			}{
+				{
+					Resource: "Lock",
+					Actions:  []string{"delete"},
+					Subjects: []string{"user:allowed"},
+					Clusters: []string{"*"},
+				},
+			},
+		},
+	}
+	err := opts.RBAC.Reify()
+	require.NoError(t, err, "failed to reify authorization rules: %+v", opts.RBAC.Rules)
+
+	api := vtadmin.NewAPI(vtenv.NewTestEnv(), testClusters(t), opts)
+	t.Cleanup(func() {
+		if err := api.Close(); err != nil {
+			t.Logf("api did not close cleanly: %s", err.Error())
+		}
+	})
+
+	t.Run("unauthorized actor", func(t *testing.T) {
+		t.Parallel()
+
+		actor := &rbac.Actor{Name: "other"}
+		ctx := context.Background()
+		ctx = rbac.NewContext(ctx, actor)
+
+		resp, err := api.ForceUnlock(ctx, &vtadminpb.ForceUnlockRequest{
+			ClusterId: "test",
+			Options: &vtctldatapb.ForceUnlockRequest{
+				Path:   "keyspaces/test",
+				Reason: "stale",
+			},
+		})
+		assert.Error(t, err, "actor %+v should not be permitted to ForceUnlock", actor)
+		assert.Nil(t, resp, "actor %+v should not be permitted to ForceUnlock", actor)
+	})
+
+	t.Run("authorized actor", func(t *testing.T) {
+		t.Parallel()
+
+		actor := &rbac.Actor{Name: "allowed"}
+		ctx := context.Background()
+		ctx = rbac.NewContext(ctx, actor)
+
+		resp, err := api.ForceUnlock(ctx, &vtadminpb.ForceUnlockRequest{
+			ClusterId: "test",
+			Options: &vtctldatapb.ForceUnlockRequest{
+				Path:   "keyspaces/test",
+				Reason: "stale",
+			},
+		})
+		require.NoError(t, err)
+		assert.NotNil(t, resp, "actor %+v should be permitted to ForceUnlock", actor)
+	})
+}
+
 func TestGetBackups(t *testing.T) {
 	t.Parallel()
 
@@ -1330,6 +1397,65 @@ func TestGetKeyspaces(t *testing.T) {
 	})
 }
 
+func TestGetLocks(t *testing.T) {
+	t.Parallel()
+
+	opts := vtadmin.Options{
+		RBAC: &rbac.Config{
+			Rules: []*struct {
+				Resource string
+				Actions  []string
+				Subjects []string
+				Clusters []string
+			}{
+				{
+					Resource: "Lock",
+					Actions:  []string{"get"},
+					Subjects: []string{"user:allowed"},
+					Clusters: []string{"*"},
+				},
+			},
+		},
+	}
+	err := opts.RBAC.Reify()
+	require.NoError(t, err, "failed to reify authorization rules: %+v", opts.RBAC.Rules)
+
+	api := vtadmin.NewAPI(vtenv.NewTestEnv(), testClusters(t), opts)
+	t.Cleanup(func() {
+		if err := api.Close(); err != nil {
+			t.Logf("api did not close cleanly: %s", err.Error())
+		}
+	})
+
+	t.Run("unauthorized actor", func(t *testing.T) {
+		t.Parallel()
+
+		actor := &rbac.Actor{Name: "other"}
+		ctx := context.Background()
+		ctx = rbac.NewContext(ctx, actor)
+
+		resp, err := api.GetLocks(ctx, &vtadminpb.GetLocksRequest{
+			ClusterId: "test",
+		})
+		assert.Error(t, err, "actor %+v should not be permitted to GetLocks", actor)
+		assert.Nil(t, resp, "actor %+v should not be permitted to GetLocks", actor)
+	})
+
+	t.Run("authorized actor", func(t *testing.T) {
+		t.Parallel()
+
+		actor := &rbac.Actor{Name: "allowed"}
+		ctx := context.Background()
+		ctx = rbac.NewContext(ctx, actor)
+
+		resp, err := api.GetLocks(ctx, &vtadminpb.GetLocksRequest{
+			ClusterId: "test",
+		})
+		require.NoError(t, err)
+		assert.NotNil(t, resp, "actor %+v should be permitted to GetLocks", actor)
+	})
+}
+
 func TestGetSchemaMigrations(t *testing.T) {
 	t.Parallel()
 
@@ -3526,6 +3652,14 @@ func testClusters(t testing.TB) []*cluster.Cluster {
 						},
 					},
 				},
+				ForceUnlockResults: map[string]struct {
+					Response *vtctldatapb.ForceUnlockResponse
+					Error    error
+				}{
+					"keyspaces/test": {
+						Response: &vtctldatapb.ForceUnlockResponse{},
+					},
+				},
 				GetBackupsResults: map[string]struct {
 					Response *vtctldatapb.GetBackupsResponse
 					Error    error
@@ -3581,6 +3715,18 @@ func testClusters(t testing.TB) []*cluster.Cluster {
 						},
 					},
 				},
+				GetLocksResults: &struct {
+					Response *vtctldatapb.GetLocksResponse
+					Error    error
+				}{
+					Response: &vtctldatapb.GetLocksResponse{
+						Locks: []*vtctldatapb.TopologyLock{
+							{
+								Path: "keyspaces/test",
+							},
+						},
+					},
+				},
 				GetSchemaMigrationsResults: map[string]struct {
 					Response *vtctldatapb.GetSchemaMigrationsResponse
 					Error    error
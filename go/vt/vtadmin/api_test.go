@@ -27,6 +27,7 @@ import (
 	"net/url"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -42,6 +43,8 @@ import (
 	"vitess.io/vitess/go/vt/vtadmin/cluster"
 	"vitess.io/vitess/go/vt/vtadmin/cluster/discovery/fakediscovery"
 	vtadminerrors "vitess.io/vitess/go/vt/vtadmin/errors"
+	vtadminhttp "vitess.io/vitess/go/vt/vtadmin/http"
+	"vitess.io/vitess/go/vt/vtadmin/rbac"
 	vtadmintestutil "vitess.io/vitess/go/vt/vtadmin/testutil"
 	"vitess.io/vitess/go/vt/vtadmin/vtctldclient/fakevtctldclient"
 	"vitess.io/vitess/go/vt/vtctl/grpcvtctldserver"
@@ -817,6 +820,82 @@ func TestFindSchema(t *testing.T) {
 	})
 }
 
+func TestForceUnlock(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts, factory := memorytopo.NewServerAndFactory(ctx, "zone1")
+	testutil.AddShards(ctx, t, ts, &vtctldatapb.Shard{Keyspace: "ks", Name: "-", Shard: &topodatapb.Shard{}})
+	vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, nil, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+		return grpcvtctldserver.NewVtctldServer(vtenv.NewTestEnv(), ts)
+	})
+
+	// The holder of the lock goes away while it holds it.
+	holder, err := topo.NewWithFactory(factory, "" /*serverAddress*/, "" /*root*/)
+	require.NoError(t, err)
+	_, unlock, err := holder.LockShard(ctx, "ks", "-", "reparent")
+	require.NoError(t, err)
+	holder.Close()
+
+	testutil.WithTestServer(ctx, t, vtctld, func(t *testing.T, client vtctldclient.VtctldClient) {
+		c := vtadmintestutil.BuildCluster(t, vtadmintestutil.TestClusterConfig{
+			Cluster: &vtadminpb.Cluster{
+				Id:   "c1",
+				Name: "cluster1",
+			},
+			VtctldClient: client,
+		})
+		api := NewAPI(vtenv.NewTestEnv(), []*cluster.Cluster{c}, Options{})
+
+		resp, err := api.GetLocks(ctx, &vtadminpb.GetLocksRequest{
+			ClusterId: "c1",
+			Options:   &vtctldatapb.GetLocksRequest{Keyspace: "ks"},
+		})
+		require.NoError(t, err)
+		require.Len(t, resp.Locks, 1)
+		assert.Equal(t, "keyspaces/ks/shards/-", resp.Locks[0].Path)
+		assert.Equal(t, "reparent", resp.Locks[0].Action)
+
+		// A reason is required.
+		_, err = api.ForceUnlock(ctx, &vtadminpb.ForceUnlockRequest{
+			ClusterId: "c1",
+			Options: &vtctldatapb.ForceUnlockRequest{
+				Path:   "keyspaces/ks/shards/-",
+				MinAge: &vttime.Duration{},
+			},
+		})
+		assert.ErrorIs(t, err, vtadminerrors.ErrInvalidRequest)
+
+		actorCtx := rbac.NewContext(ctx, &rbac.Actor{Name: "alice"})
+		unlockResp, err := api.ForceUnlock(actorCtx, &vtadminpb.ForceUnlockRequest{
+			ClusterId: "c1",
+			Options: &vtctldatapb.ForceUnlockRequest{
+				Path:   "keyspaces/ks/shards/-",
+				MinAge: &vttime.Duration{},
+				Reason: "stuck",
+			},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "reparent", unlockResp.Lock.Action)
+
+		resp, err = api.GetLocks(ctx, &vtadminpb.GetLocksRequest{ClusterId: "c1"})
+		require.NoError(t, err)
+		assert.Empty(t, resp.Locks)
+	})
+
+	entries, err := ts.GetAuditLog(ctx)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "alice", entries[0].Actor)
+	assert.Contains(t, entries[0].Details, "reason: stuck")
+	assert.NotContains(t, entries[0].Details, "alice")
+
+	var unlockErr error
+	unlock(&unlockErr)
+	assert.Error(t, unlockErr)
+}
+
 func TestGetClusters(t *testing.T) {
 	t.Parallel()
 
@@ -5371,6 +5450,132 @@ func TestServeHTTP(t *testing.T) {
 	}
 }
 
+func TestFetchComponentInfo(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/debug/component_info" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(`{"component":"vtgate","version":"20.0.0","git_revision":"abc123","feature_flags":["enable-buffer=true"],"config_hash":"0123"}`))
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	cc := newComponentClient(vtadminhttp.Options{})
+	info := cc.fetchInfo(ctx, srv.URL)
+	utils.MustMatch(t, &vtadminpb.ComponentInfo{
+		Version:      "20.0.0",
+		GitRevision:  "abc123",
+		FeatureFlags: []string{"enable-buffer=true"},
+		ConfigHash:   "0123",
+	}, info)
+
+	// Addresses without a scheme are reached over http.
+	info = cc.fetchInfo(ctx, srv.Listener.Addr().String())
+	assert.Equal(t, "20.0.0", info.Version)
+	assert.Empty(t, info.Error)
+
+	assert.NotEmpty(t, cc.fetchInfo(ctx, "").Error)
+	assert.NotEmpty(t, cc.fetchInfo(ctx, srv.URL+"/missing").Error)
+
+	// The requests time out.
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer slow.Close()
+	cc = newComponentClient(vtadminhttp.Options{ComponentTimeout: 10 * time.Millisecond})
+	assert.NotEmpty(t, cc.fetchInfo(ctx, slow.URL).Error)
+}
+
+func TestFetchComponentInfoTLS(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"component":"vtgate","version":"20.0.0","config_hash":"0123"}`))
+	}))
+	defer srv.Close()
+
+	// With a TLS config, the addresses without a scheme are reached over
+	// https.
+	ctx := context.Background()
+	cc := newComponentClient(vtadminhttp.Options{ComponentTLSConfig: srv.Client().Transport.(*http.Transport).TLSClientConfig})
+	info := cc.fetchInfo(ctx, srv.Listener.Addr().String())
+	assert.Empty(t, info.Error)
+	assert.Equal(t, "20.0.0", info.Version)
+
+	// Without it, the certificate of the server is not trusted.
+	cc = newComponentClient(vtadminhttp.Options{})
+	assert.NotEmpty(t, cc.fetchInfo(ctx, srv.URL).Error)
+}
+
+func TestFetchComponentConfig(t *testing.T) {
+	t.Parallel()
+
+	config := `{"replication":{"heartbeat":"1s","enable":true},"port":15000}`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/debug/component_info":
+			_, _ = w.Write([]byte(`{"component":"vtgate","config_hash":"0123"}`))
+		case r.URL.Path == "/debug/config" && r.URL.Query().Get("format") == "json":
+			_, _ = w.Write([]byte(config))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	cc := newComponentClient(vtadminhttp.Options{})
+	info, settings := cc.fetchConfig(ctx, srv.URL)
+	assert.Empty(t, info.Error)
+	// The config hash is the one of the component info.
+	assert.Equal(t, "0123", info.ConfigHash)
+	assert.Equal(t, map[string]string{
+		"replication.heartbeat": `"1s"`,
+		"replication.enable":    "true",
+		"port":                  "15000",
+	}, settings)
+
+	info, settings = cc.fetchConfig(ctx, "")
+	assert.NotEmpty(t, info.Error)
+	assert.Nil(t, settings)
+	info, _ = cc.fetchConfig(ctx, srv.URL+"/missing")
+	assert.NotEmpty(t, info.Error)
+}
+
+func TestConfigDrift(t *testing.T) {
+	t.Parallel()
+
+	t.Run("most common config hash", func(t *testing.T) {
+		t.Parallel()
+
+		components := []*vtadminpb.ComponentConfig{
+			{Name: "a", ConfigHash: "bbb"},
+			{Name: "b", ConfigHash: "aaa"},
+			{Name: "c", ConfigHash: "bbb"},
+			{Name: "d", Error: "unreachable"},
+			{Name: "e", Error: "unreachable"},
+			{Name: "f", Error: "unreachable"},
+		}
+		assert.Equal(t, "bbb", mostCommonConfigHash(components))
+
+		// Ties go to the lowest hash.
+		assert.Equal(t, "aaa", mostCommonConfigHash(components[:2]))
+		assert.Empty(t, mostCommonConfigHash(components[3:]))
+	})
+
+	t.Run("drifted keys", func(t *testing.T) {
+		t.Parallel()
+
+		baseline := map[string]string{"a": "1", "b": "2", "c": "3"}
+		settings := map[string]string{"a": "1", "b": "20", "d": "4"}
+		assert.Equal(t, []string{"b", "c", "d"}, driftedKeys(baseline, settings))
+		assert.Empty(t, driftedKeys(baseline, baseline))
+	})
+}
+
 func init() {
 	// For tests that don't actually care about mocking the tmclient (i.e. they
 	// call grpcvtctldserver.NewVtctldServer to initialize the unit under test),
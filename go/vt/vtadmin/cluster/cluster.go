@@ -30,6 +30,9 @@ import (
 	"text/template"
 	"time"
 
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
 	"vitess.io/vitess/go/pools"
 	"vitess.io/vitess/go/protoutil"
 	"vitess.io/vitess/go/sets"
@@ -844,6 +847,42 @@ func (c *Cluster) findWorkflows(ctx context.Context, keyspaces []string, opts Fi
 	}, nil
 }
 
+// ForceUnlock breaks a stale lock held on a resource of the topology of the
+// cluster, proxying a ForceUnlockRequest to a vtctld in that cluster.
+func (c *Cluster) ForceUnlock(ctx context.Context, req *vtctldatapb.ForceUnlockRequest) (*vtctldatapb.ForceUnlockResponse, error) {
+	span, ctx := trace.NewSpan(ctx, "Cluster.ForceUnlock")
+	defer span.Finish()
+
+	AnnotateSpan(c, span)
+
+	if req == nil {
+		return nil, fmt.Errorf("%w: request cannot be nil", errors.ErrInvalidRequest)
+	}
+
+	if req.Path == "" {
+		return nil, fmt.Errorf("%w: path is required", errors.ErrInvalidRequest)
+	}
+
+	if req.Reason == "" {
+		return nil, fmt.Errorf("%w: reason is required", errors.ErrInvalidRequest)
+	}
+
+	span.Annotate("path", req.Path)
+	span.Annotate("reason", req.Reason)
+
+	if err := c.topoRWPool.Acquire(ctx); err != nil {
+		return nil, fmt.Errorf("ForceUnlock(%+v) failed to acquire topoRWPool: %w", req, err)
+	}
+	defer c.topoRWPool.Release()
+
+	resp, err := c.Vtctld.ForceUnlock(ctx, req)
+	if status.Code(err) == codes.Unimplemented {
+		// The vtctlds of the cluster predate ForceUnlock.
+		return nil, fmt.Errorf("%w: ForceUnlock in cluster %s: %v", errors.ErrUnimplemented, c.ID, err)
+	}
+	return resp, err
+}
+
 // GetBackups returns a ClusterBackups object for all backups in the cluster.
 func (c *Cluster) GetBackups(ctx context.Context, req *vtadminpb.GetBackupsRequest) ([]*vtadminpb.ClusterBackup, error) {
 	span, ctx := trace.NewSpan(ctx, "Cluster.GetBackups")
@@ -1259,6 +1298,30 @@ func (c *Cluster) GetKeyspaces(ctx context.Context) ([]*vtadminpb.Keyspace, erro
 	return keyspaces, nil
 }
 
+// GetLocks returns the locks currently held on the resources of the topology
+// of the cluster, proxying a GetLocksRequest to a vtctld in that cluster.
+func (c *Cluster) GetLocks(ctx context.Context, req *vtctldatapb.GetLocksRequest) (*vtctldatapb.GetLocksResponse, error) {
+	span, ctx := trace.NewSpan(ctx, "Cluster.GetLocks")
+	defer span.Finish()
+
+	AnnotateSpan(c, span)
+
+	if req == nil {
+		req = &vtctldatapb.GetLocksRequest{}
+	}
+
+	span.Annotate("keyspace", req.Keyspace)
+	span.Annotate("shard", req.Shard)
+	span.Annotate("path", req.Path)
+
+	if err := c.topoReadPool.Acquire(ctx); err != nil {
+		return nil, fmt.Errorf("GetLocks(%+v) failed to acquire topoReadPool: %w", req, err)
+	}
+	defer c.topoReadPool.Release()
+
+	return c.Vtctld.GetLocks(ctx, req)
+}
+
 // GetSrvKeyspaces returns all SrvKeyspaces for all keyspaces in a cluster.
 func (c *Cluster) GetSrvKeyspaces(ctx context.Context, cells []string) (map[string]*vtctldatapb.GetSrvKeyspacesResponse, error) {
 	span, ctx := trace.NewSpan(ctx, "Cluster.GetKeyspaces")
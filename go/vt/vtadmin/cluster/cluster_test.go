@@ -27,6 +27,8 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	"vitess.io/vitess/go/mysql/replication"
 	"vitess.io/vitess/go/protoutil"
@@ -1208,6 +1210,36 @@ func TestFindWorkflows(t *testing.T) {
 	}
 }
 
+func TestForceUnlock(t *testing.T) {
+	t.Parallel()
+
+	// A vtctld which predates ForceUnlock, or whose topology server can't
+	// break locks.
+	c := testutil.BuildCluster(t, testutil.TestClusterConfig{
+		Cluster: &vtadminpb.Cluster{
+			Id:   "c1",
+			Name: "cluster1",
+		},
+		VtctldClient: &fakevtctldclient.VtctldClient{
+			ForceUnlockResults: map[string]struct {
+				Response *vtctldatapb.ForceUnlockResponse
+				Error    error
+			}{
+				"keyspaces/ks": {
+					Error: status.Error(codes.Unimplemented, "unknown method ForceUnlock"),
+				},
+			},
+		},
+	})
+	defer c.Close()
+
+	_, err := c.ForceUnlock(context.Background(), &vtctldatapb.ForceUnlockRequest{
+		Path:   "keyspaces/ks",
+		Reason: "stuck",
+	})
+	assert.ErrorIs(t, err, vtadminerrors.ErrUnimplemented)
+}
+
 func TestGetCellInfos(t *testing.T) {
 	t.Parallel()
 
@@ -53,6 +53,9 @@ var (
 	ErrUnauthorized = errors.New("unauthorized")
 	// ErrUnsupportedCluster occurs when a cluster parameter is invalid.
 	ErrUnsupportedCluster = errors.New("unsupported cluster(s)")
+	// ErrUnimplemented occurs when the components of a cluster don't
+	// implement an operation, e.g. because its vtctlds predate it.
+	ErrUnimplemented = errors.New("not implemented")
 )
 
 // Errors returned by cluster setup and flag parsing.
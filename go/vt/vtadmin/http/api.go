@@ -18,7 +18,9 @@ package http
 
 import (
 	"context"
+	"crypto/tls"
 	"net/http"
+	"time"
 
 	"vitess.io/vitess/go/sets"
 	"vitess.io/vitess/go/trace"
@@ -47,6 +49,14 @@ type Options struct {
 	ExperimentalOptions struct {
 		TabletURLTmpl string
 	}
+	// ComponentTimeout bounds the requests to the http servers of the
+	// tablets, vtgates and vtctlds, e.g. for their component info. Zero
+	// means no timeout.
+	ComponentTimeout time.Duration
+	// ComponentTLSConfig is the TLS config of the requests to the http
+	// servers of the tablets, vtgates and vtctlds. If set, their addresses
+	// without a scheme are reached over https instead of http.
+	ComponentTLSConfig *tls.Config
 }
 
 // API is used to power HTTP endpoint wrappers to the VTAdminServer interface.
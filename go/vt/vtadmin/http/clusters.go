@@ -19,10 +19,13 @@ package http
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"strings"
 
 	"github.com/gorilla/mux"
 
 	vtadminpb "vitess.io/vitess/go/vt/proto/vtadmin"
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
 	"vitess.io/vitess/go/vt/vtadmin/errors"
 )
 
@@ -32,6 +35,62 @@ func GetClusters(ctx context.Context, r Request, api *API) *JSONResponse {
 	return NewJSONResponse(clusters, err)
 }
 
+// GetElections implements the http wrapper for /cluster/{cluster_id}/elections
+func GetElections(ctx context.Context, r Request, api *API) *JSONResponse {
+	vars := r.Vars()
+
+	result, err := api.server.GetElections(ctx, &vtadminpb.GetElectionsRequest{
+		ClusterId: vars["cluster_id"],
+	})
+	return NewJSONResponse(result, err)
+}
+
+// ForceUnlock implements the http wrapper for
+// PUT /cluster/{cluster_id}/locks/force_unlock.
+//
+// Query params: none
+//
+// PUT body is unmarshalled as vtctldatapb.ForceUnlockRequest, whose reason is
+// required.
+func ForceUnlock(ctx context.Context, r Request, api *API) *JSONResponse {
+	decoder := json.NewDecoder(r.Body)
+	defer r.Body.Close()
+
+	var options vtctldatapb.ForceUnlockRequest
+	if err := decoder.Decode(&options); err != nil {
+		return NewJSONResponse(nil, &errors.BadRequest{
+			Err: err,
+		})
+	}
+
+	result, err := api.server.ForceUnlock(ctx, &vtadminpb.ForceUnlockRequest{
+		ClusterId: r.Vars()["cluster_id"],
+		Options:   &options,
+	})
+	return NewJSONResponse(result, err)
+}
+
+// GetLocks implements the http wrapper for /cluster/{cluster_id}/locks
+//
+// Query params:
+// - keyspace: string
+// - shard: string
+// - path: string
+func GetLocks(ctx context.Context, r Request, api *API) *JSONResponse {
+	vars := r.Vars()
+	query := r.URL.Query()
+
+	result, err := api.server.GetLocks(ctx, &vtadminpb.GetLocksRequest{
+		ClusterId: vars["cluster_id"],
+		Options: &vtctldatapb.GetLocksRequest{
+			Keyspace: query.Get("keyspace"),
+			Shard:    query.Get("shard"),
+			Path:     query.Get("path"),
+		},
+	})
+	return NewJSONResponse(result, err)
+}
+
 // GetTopologyPath implements the http wrapper for /cluster/{cluster_id}/topology
 //
 // Query params:
@@ -47,6 +106,44 @@ func GetTopologyPath(ctx context.Context, r Request, api *API) *JSONResponse {
 	return NewJSONResponse(result, err)
 }
 
+// ListTopologyPath implements the http wrapper for /cluster/{cluster_id}/topology/tree
+//
+// Query params:
+// - path: string, defaults to "/"
+// - page_size: int32, defaults to 0, for all the entries
+// - page_token: string
+// - include_data: bool, defaults to false
+func ListTopologyPath(ctx context.Context, r Request, api *API) *JSONResponse {
+	vars := r.Vars()
+	query := r.URL.Query()
+
+	pageSize, err := r.ParseQueryParamAsInt32("page_size", 0)
+	if err != nil {
+		return NewJSONResponse(nil, err)
+	}
+
+	includeData, err := r.ParseQueryParamAsBool("include_data", false)
+	if err != nil {
+		return NewJSONResponse(nil, err)
+	}
+
+	path := query.Get("path")
+	if path == "" {
+		path = "/"
+	}
+
+	result, err := api.server.ListTopologyPath(ctx, &vtadminpb.ListTopologyPathRequest{
+		ClusterId: vars["cluster_id"],
+		Request: &vtctldatapb.ListTopologyPathRequest{
+			Path:        path,
+			PageSize:    pageSize,
+			PageToken:   query.Get("page_token"),
+			IncludeData: includeData,
+		},
+	})
+	return NewJSONResponse(result, err)
+}
+
 // Validate implements the http wrapper for /cluster/{cluster_id}/validate
 func Validate(ctx context.Context, r Request, api *API) *JSONResponse {
 	vars := mux.Vars(r.Request)
@@ -69,3 +166,40 @@ func Validate(ctx context.Context, r Request, api *API) *JSONResponse {
 	})
 	return NewJSONResponse(resp, err)
 }
+
+// GetFleetInfo implements the http wrapper for /fleet[?cluster_id=[&cluster_id=]].
+func GetFleetInfo(ctx context.Context, r Request, api *API) *JSONResponse {
+	fleet, err := api.server.GetFleetInfo(ctx, &vtadminpb.GetFleetInfoRequest{
+		ClusterIds: r.URL.Query()["cluster_id"],
+	})
+	return NewJSONResponse(fleet, err)
+}
+
+// GetConfigDrift implements the http wrapper for
+// /fleet/config_drift[?cluster_id=[&cluster_id=]][&baseline=kind:hash[&baseline=kind:hash]][&include_settings=].
+func GetConfigDrift(ctx context.Context, r Request, api *API) *JSONResponse {
+	query := r.URL.Query()
+
+	includeSettings, err := r.ParseQueryParamAsBool("include_settings", false)
+	if err != nil {
+		return NewJSONResponse(nil, err)
+	}
+
+	baselines := map[string]string{}
+	for _, baseline := range query["baseline"] {
+		kind, hash, ok := strings.Cut(baseline, ":")
+		if !ok || kind == "" || hash == "" {
+			return NewJSONResponse(nil, &errors.BadRequest{
+				Err: fmt.Errorf("invalid baseline %q: must be of the form kind:hash", baseline),
+			})
+		}
+		baselines[kind] = hash
+	}
+
+	drift, err := api.server.GetConfigDrift(ctx, &vtadminpb.GetConfigDriftRequest{
+		ClusterIds:      query["cluster_id"],
+		Baselines:       baselines,
+		IncludeSettings: includeSettings,
+	})
+	return NewJSONResponse(drift, err)
+}
@@ -19,6 +19,7 @@ package http
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"strings"
 
 	"github.com/gorilla/mux"
@@ -78,10 +79,25 @@ func GetKeyspace(ctx context.Context, r Request, api *API) *JSONResponse {
 	return NewJSONResponse(keyspace, err)
 }
 
-// GetKeyspaces implements the http wrapper for /keyspaces[?cluster_id=[&cluster_id=]].
+// GetKeyspaces implements the http wrapper for /keyspaces[?cluster_id=[&cluster_id=]][&label=<key>:<value>[&label=]].
 func GetKeyspaces(ctx context.Context, r Request, api *API) *JSONResponse {
+	var labels map[string]string
+	for _, label := range r.URL.Query()["label"] {
+		key, value, ok := strings.Cut(label, ":")
+		if !ok {
+			return NewJSONResponse(nil, &errors.BadRequest{
+				Err: fmt.Errorf("invalid label %q, expected <key>:<value>", label),
+			})
+		}
+		if labels == nil {
+			labels = map[string]string{}
+		}
+		labels[key] = value
+	}
+
 	keyspaces, err := api.server.GetKeyspaces(ctx, &vtadminpb.GetKeyspacesRequest{
 		ClusterIds: r.URL.Query()["cluster_id"],
+		Labels:     labels,
 	})
 
 	return NewJSONResponse(keyspaces, err)
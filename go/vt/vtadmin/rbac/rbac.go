@@ -108,6 +108,7 @@ const (
 	CellInfoResource   Resource = "CellInfo"
 	CellsAliasResource Resource = "CellsAlias"
 	KeyspaceResource   Resource = "Keyspace"
+	LockResource       Resource = "Lock"
 	ShardResource      Resource = "Shard"
 	TabletResource     Resource = "Tablet"
 	VTGateResource     Resource = "VTGate"
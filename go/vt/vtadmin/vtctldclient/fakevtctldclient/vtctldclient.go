@@ -69,6 +69,11 @@ type VtctldClient struct {
 		Response *vtctldatapb.FindAllShardsInKeyspaceResponse
 		Error    error
 	}
+	// Keyed by path.
+	ForceUnlockResults map[string]struct {
+		Response *vtctldatapb.ForceUnlockResponse
+		Error    error
+	}
 	GetBackupsResults map[string]struct {
 		Response *vtctldatapb.GetBackupsResponse
 		Error    error
@@ -93,6 +98,10 @@ type VtctldClient struct {
 		Keyspaces []*vtctldatapb.Keyspace
 		Error     error
 	}
+	GetLocksResults *struct {
+		Response *vtctldatapb.GetLocksResponse
+		Error    error
+	}
 	GetSchemaMigrationsResults map[string]struct {
 		Response *vtctldatapb.GetSchemaMigrationsResponse
 		Error    error
@@ -360,6 +369,19 @@ func (fake *VtctldClient) FindAllShardsInKeyspace(ctx context.Context, req *vtct
 	return nil, fmt.Errorf("%w: no result set for keyspace %s", assert.AnError, req.Keyspace)
 }
 
+// ForceUnlock is part of the vtctldclient.VtctldClient interface.
+func (fake *VtctldClient) ForceUnlock(ctx context.Context, req *vtctldatapb.ForceUnlockRequest, opts ...grpc.CallOption) (*vtctldatapb.ForceUnlockResponse, error) {
+	if fake.ForceUnlockResults == nil {
+		return nil, fmt.Errorf("%w: ForceUnlockResults not set on fake vtctldclient", assert.AnError)
+	}
+
+	if result, ok := fake.ForceUnlockResults[req.Path]; ok {
+		return result.Response, result.Error
+	}
+
+	return nil, fmt.Errorf("%w: no result set for path %s", assert.AnError, req.Path)
+}
+
 // GetBackups is part of the vtctldclient.VtctldClient interface.
 func (fake *VtctldClient) GetBackups(ctx context.Context, req *vtctldatapb.GetBackupsRequest, opts ...grpc.CallOption) (*vtctldatapb.GetBackupsResponse, error) {
 	if fake.GetBackupsResults == nil {
@@ -433,6 +455,15 @@ func (fake *VtctldClient) GetKeyspaces(ctx context.Context, req *vtctldatapb.Get
 	}, nil
 }
 
+// GetLocks is part of the vtctldclient.VtctldClient interface.
+func (fake *VtctldClient) GetLocks(ctx context.Context, req *vtctldatapb.GetLocksRequest, opts ...grpc.CallOption) (*vtctldatapb.GetLocksResponse, error) {
+	if fake.GetLocksResults == nil {
+		return nil, fmt.Errorf("%w: GetLocksResults not set on fake vtctldclient", assert.AnError)
+	}
+
+	return fake.GetLocksResults.Response, fake.GetLocksResults.Error
+}
+
 // GetSchemaMigrations is part of the vtctldclient.VtctldClient interface.
 func (fake *VtctldClient) GetSchemaMigrations(ctx context.Context, req *vtctldatapb.GetSchemaMigrationsRequest, opts ...grpc.CallOption) (*vtctldatapb.GetSchemaMigrationsResponse, error) {
 	if fake.GetSchemaMigrationsResults == nil {
@@ -771,6 +771,24 @@ func (itmc *internalTabletManagerClient) GetGlobalStatusVars(ctx context.Context
 	return t.tm.GetGlobalStatusVars(ctx, variables)
 }
 
+// GetInFlightQueries is part of the tmclient.TabletManagerClient interface.
+func (itmc *internalTabletManagerClient) GetInFlightQueries(ctx context.Context, tablet *topodatapb.Tablet) ([]*tabletmanagerdatapb.InFlightQuery, error) {
+	t, ok := tabletMap[tablet.Alias.Uid]
+	if !ok {
+		return nil, fmt.Errorf("tmclient: cannot find tablet %v", topoproto.TabletAliasString(tablet.Alias))
+	}
+	return t.tm.GetInFlightQueries(ctx)
+}
+
+// KillInFlightQuery is part of the tmclient.TabletManagerClient interface.
+func (itmc *internalTabletManagerClient) KillInFlightQuery(ctx context.Context, tablet *topodatapb.Tablet, id string) error {
+	t, ok := tabletMap[tablet.Alias.Uid]
+	if !ok {
+		return fmt.Errorf("tmclient: cannot find tablet %v", topoproto.TabletAliasString(tablet.Alias))
+	}
+	return t.tm.KillInFlightQuery(ctx, id)
+}
+
 func (itmc *internalTabletManagerClient) SetReadOnly(ctx context.Context, tablet *topodatapb.Tablet) error {
 	return fmt.Errorf("not implemented in vtcombo")
 }
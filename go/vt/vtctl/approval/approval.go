@@ -0,0 +1,423 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package approval implements the two-person rule for the destructive
+// commands of vtctld.
+//
+// When a command class requires approval, running the command records a
+// pending approval in the global topo and fails with its id. A second
+// authenticated caller approves it with ApproveCommand, after which running
+// the same command with the same request, before the approval expires,
+// executes it. Approvals are kept as an audit history until the retention
+// passes.
+//
+// The commands are gated where they are implemented, so that the gRPC API of
+// vtctld and the legacy vtctl commands it serves enforce the same approvals.
+// Callers are identified by their credentials only: the username of the
+// static gRPC auth plugin, or the common name of a verified TLS client
+// certificate. The caller id of a request is set by the client, so it is
+// never trusted.
+package approval
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/pflag"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"vitess.io/vitess/go/protoutil"
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/servenv"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// The command classes that can require approval.
+const (
+	// DeleteKeyspace is the DeleteKeyspace command.
+	DeleteKeyspace = "DeleteKeyspace"
+	// DropSources is the completion of a MoveTables workflow that drops
+	// the source tables, i.e. without --keep-data.
+	DropSources = "DropSources"
+	// EmergencyReparentShard is the EmergencyReparentShard command. It
+	// promotes a new primary without the current one being reachable, so
+	// every run of it is gated.
+	EmergencyReparentShard = "EmergencyReparentShard"
+)
+
+// Commands are the command classes that can require approval.
+var Commands = []string{DeleteKeyspace, DropSources, EmergencyReparentShard}
+
+// Dir is the directory of the global topo the approvals are stored in.
+const Dir = "command_approvals"
+
+var (
+	requiredCommands []string
+	defaultWindow    = time.Hour
+	retention        = 7 * 24 * time.Hour
+
+	// requiredWindows are the approval windows of the command classes of
+	// --command_approval_required, parsed when the process starts.
+	requiredWindows map[string]time.Duration
+
+	approvalEvents = stats.NewCountersWithMultiLabels(
+		"CommandApprovals",
+		"The number of approval events of the destructive commands, by command class and event",
+		[]string{"Command", "Event"})
+)
+
+func init() {
+	servenv.OnParseFor("vtctld", registerFlags)
+	servenv.OnInit(func() {
+		windows, err := parseRequiredCommands()
+		if err != nil {
+			log.Exitf("invalid --command_approval_required: %v", err)
+		}
+		requiredWindows = windows
+	})
+}
+
+func registerFlags(fs *pflag.FlagSet) {
+	fs.StringSliceVar(&requiredCommands, "command_approval_required", requiredCommands, fmt.Sprintf("The command classes that require the approval of a second authenticated caller before they run, as <class> or <class>=<window>. The classes are %v.", strings.Join(Commands, ", ")))
+	fs.DurationVar(&defaultWindow, "command_approval_window", defaultWindow, "How long a command can be approved and run for after it is first requested, for the classes of --command_approval_required without a window.")
+	fs.DurationVar(&retention, "command_approval_retention", retention, "How long the approvals of commands are kept in the global topo as an audit history.")
+}
+
+// Gate gates the command classes that require approval. A nil Gate gates
+// nothing.
+type Gate struct {
+	ts *topo.Server
+	// windows are the approval windows of the gated command classes.
+	windows   map[string]time.Duration
+	retention time.Duration
+
+	now func() time.Time
+}
+
+// NewGate returns a Gate for the command classes in windows, each with its
+// approval window. Approvals are kept until retention passes.
+func NewGate(ts *topo.Server, windows map[string]time.Duration, retention time.Duration) *Gate {
+	return &Gate{
+		ts:        ts,
+		windows:   windows,
+		retention: retention,
+		now:       time.Now,
+	}
+}
+
+// NewGateFromFlags returns the Gate configured by --command_approval_required,
+// or nil if no command class requires approval. The flag is validated when the
+// process starts.
+func NewGateFromFlags(ts *topo.Server) *Gate {
+	if len(requiredWindows) == 0 {
+		return nil
+	}
+	return NewGate(ts, requiredWindows, retention)
+}
+
+// parseRequiredCommands returns the approval windows of the command classes
+// of --command_approval_required.
+func parseRequiredCommands() (map[string]time.Duration, error) {
+	if len(requiredCommands) == 0 {
+		return nil, nil
+	}
+	windows := make(map[string]time.Duration, len(requiredCommands))
+	for _, spec := range requiredCommands {
+		class, windowSpec, hasWindow := strings.Cut(strings.TrimSpace(spec), "=")
+		if !isCommand(class) {
+			return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "unknown command class %q, expected one of %v", class, strings.Join(Commands, ", "))
+		}
+		window := defaultWindow
+		if hasWindow {
+			var err error
+			if window, err = time.ParseDuration(windowSpec); err != nil {
+				return nil, vterrors.Wrapf(err, "invalid approval window for %v", class)
+			}
+		}
+		if window <= 0 {
+			return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "the approval window for %v must be positive", class)
+		}
+		windows[class] = window
+	}
+	return windows, nil
+}
+
+func isCommand(class string) bool {
+	for _, c := range Commands {
+		if c == class {
+			return true
+		}
+	}
+	return false
+}
+
+// Caller returns the authenticated caller of ctx, or "" if there is none: the
+// username of the static gRPC auth plugin, or else the common name of the
+// verified TLS client certificate of the peer.
+func Caller(ctx context.Context) string {
+	if username := servenv.StaticAuthUsernameFromContext(ctx); username != "" {
+		return username
+	}
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(tlsInfo.State.VerifiedChains) == 0 || len(tlsInfo.State.VerifiedChains[0]) == 0 {
+		return ""
+	}
+	return tlsInfo.State.VerifiedChains[0][0].Subject.CommonName
+}
+
+// Check returns nil if the command class may run with req. If the class
+// requires approval and the request was approved by a second caller, the
+// approval is marked as executed and the command may run once. Otherwise
+// the request is recorded as pending and Check returns a FAILED_PRECONDITION
+// error with the id to approve.
+func (g *Gate) Check(ctx context.Context, command string, req proto.Message) error {
+	if g == nil {
+		return nil
+	}
+	window, ok := g.windows[command]
+	if !ok {
+		return nil
+	}
+	caller := Caller(ctx)
+	if caller == "" {
+		approvalEvents.Add([]string{command, "Denied"}, 1)
+		return vterrors.Errorf(vtrpcpb.Code_PERMISSION_DENIED, "%v requires approval, and can only be run by an authenticated caller", command)
+	}
+
+	conn, err := g.ts.ConnForCell(ctx, topo.GlobalCell)
+	if err != nil {
+		return err
+	}
+	records, err := g.list(ctx, conn)
+	if err != nil {
+		return err
+	}
+	now := g.now()
+	for _, r := range records {
+		a := r.approval
+		if a.Command != command || a.ExecutedAt != nil || !now.Before(protoutil.TimeFromProto(a.ExpiresAt)) {
+			continue
+		}
+		if !sameRequest(a, req) {
+			continue
+		}
+		if a.ApprovedAt == nil {
+			return vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "%v requires the approval of a second caller: approval %v, requested by %v, is still pending and expires at %v", command, a.Id, a.Requester, protoutil.TimeFromProto(a.ExpiresAt).UTC().Format(time.RFC3339))
+		}
+
+		a.Executor = caller
+		a.ExecutedAt = protoutil.TimeToProto(now)
+		if err := g.write(ctx, conn, a, r.version); err != nil {
+			return vterrors.Wrapf(err, "cannot mark approval %v as executed", a.Id)
+		}
+		audit(a, "Executed", caller)
+		return nil
+	}
+
+	data, err := protojson.Marshal(req)
+	if err != nil {
+		return err
+	}
+	id, err := newID(now)
+	if err != nil {
+		return err
+	}
+	a := &vtctldatapb.CommandApproval{
+		Id:          id,
+		Command:     command,
+		Request:     string(data),
+		Requester:   caller,
+		RequestedAt: protoutil.TimeToProto(now),
+		ExpiresAt:   protoutil.TimeToProto(now.Add(window)),
+	}
+	if err := g.write(ctx, conn, a, nil); err != nil {
+		return vterrors.Wrapf(err, "cannot record the approval of %v", command)
+	}
+	audit(a, "Requested", caller)
+	return vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "%v requires the approval of a second caller: have them run ApproveCommand %v, then run the command again before %v", command, a.Id, protoutil.TimeFromProto(a.ExpiresAt).UTC().Format(time.RFC3339))
+}
+
+// Approve approves the pending approval with the given id. The caller must
+// be authenticated, and be another caller than the one who requested it.
+func (g *Gate) Approve(ctx context.Context, id string) (*vtctldatapb.CommandApproval, error) {
+	if g == nil {
+		return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "no command requires approval")
+	}
+	caller := Caller(ctx)
+	if caller == "" {
+		return nil, vterrors.Errorf(vtrpcpb.Code_PERMISSION_DENIED, "commands can only be approved by an authenticated caller")
+	}
+	if id == "" || strings.Contains(id, "/") {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid approval id %q", id)
+	}
+
+	conn, err := g.ts.ConnForCell(ctx, topo.GlobalCell)
+	if err != nil {
+		return nil, err
+	}
+	data, version, err := conn.Get(ctx, path.Join(Dir, id))
+	switch {
+	case topo.IsErrType(err, topo.NoNode):
+		return nil, vterrors.Errorf(vtrpcpb.Code_NOT_FOUND, "approval %v does not exist", id)
+	case err != nil:
+		return nil, err
+	}
+	a := &vtctldatapb.CommandApproval{}
+	if err := a.UnmarshalVT(data); err != nil {
+		return nil, vterrors.Wrapf(err, "cannot unmarshal approval %v", id)
+	}
+
+	switch {
+	case a.ExecutedAt != nil:
+		return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "approval %v was already executed by %v", id, a.Executor)
+	case a.ApprovedAt != nil:
+		return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "approval %v was already approved by %v", id, a.Approver)
+	case !g.now().Before(protoutil.TimeFromProto(a.ExpiresAt)):
+		return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "approval %v expired at %v", id, protoutil.TimeFromProto(a.ExpiresAt).UTC().Format(time.RFC3339))
+	case a.Requester == caller:
+		approvalEvents.Add([]string{a.Command, "Denied"}, 1)
+		return nil, vterrors.Errorf(vtrpcpb.Code_PERMISSION_DENIED, "approval %v must be approved by another caller than %v, who requested it", id, caller)
+	}
+
+	a.Approver = caller
+	a.ApprovedAt = protoutil.TimeToProto(g.now())
+	if err := g.write(ctx, conn, a, version); err != nil {
+		return nil, vterrors.Wrapf(err, "cannot approve %v", id)
+	}
+	audit(a, "Approved", caller)
+	return a, nil
+}
+
+// List returns the approvals that are still kept, oldest first.
+func (g *Gate) List(ctx context.Context) ([]*vtctldatapb.CommandApproval, error) {
+	if g == nil {
+		return nil, nil
+	}
+	conn, err := g.ts.ConnForCell(ctx, topo.GlobalCell)
+	if err != nil {
+		return nil, err
+	}
+	records, err := g.list(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+	approvals := make([]*vtctldatapb.CommandApproval, 0, len(records))
+	for _, r := range records {
+		approvals = append(approvals, r.approval)
+	}
+	return approvals, nil
+}
+
+type record struct {
+	approval *vtctldatapb.CommandApproval
+	version  topo.Version
+}
+
+// list reads the approvals, oldest first, and deletes those past the
+// retention.
+func (g *Gate) list(ctx context.Context, conn topo.Conn) ([]record, error) {
+	entries, err := conn.ListDir(ctx, Dir, false /* full */)
+	switch {
+	case topo.IsErrType(err, topo.NoNode):
+		return nil, nil
+	case err != nil:
+		return nil, err
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+
+	cutoff := g.now().Add(-g.retention)
+	records := make([]record, 0, len(entries))
+	for _, entry := range entries {
+		filePath := path.Join(Dir, entry.Name)
+		data, version, err := conn.Get(ctx, filePath)
+		switch {
+		case topo.IsErrType(err, topo.NoNode):
+			continue
+		case err != nil:
+			return nil, err
+		}
+		a := &vtctldatapb.CommandApproval{}
+		if err := a.UnmarshalVT(data); err != nil {
+			return nil, vterrors.Wrapf(err, "cannot unmarshal approval %v", entry.Name)
+		}
+		if protoutil.TimeFromProto(a.RequestedAt).Before(cutoff) {
+			if err := conn.Delete(ctx, filePath, version); err != nil && !topo.IsErrType(err, topo.NoNode) {
+				log.Warningf("Cannot delete approval %v past its retention: %v", a.Id, err)
+			}
+			continue
+		}
+		records = append(records, record{approval: a, version: version})
+	}
+	return records, nil
+}
+
+// write creates the approval if version is nil, and updates it otherwise.
+func (g *Gate) write(ctx context.Context, conn topo.Conn, a *vtctldatapb.CommandApproval, version topo.Version) error {
+	data, err := a.MarshalVT()
+	if err != nil {
+		return err
+	}
+	filePath := path.Join(Dir, a.Id)
+	if version == nil {
+		_, err = conn.Create(ctx, filePath, data)
+	} else {
+		_, err = conn.Update(ctx, filePath, data, version)
+	}
+	return err
+}
+
+// sameRequest returns true if req is the request the approval was
+// requested for.
+func sameRequest(a *vtctldatapb.CommandApproval, req proto.Message) bool {
+	approved := req.ProtoReflect().New().Interface()
+	if err := protojson.Unmarshal([]byte(a.Request), approved); err != nil {
+		return false
+	}
+	return proto.Equal(approved, req)
+}
+
+// newID returns an id that sorts the approvals by the time they were
+// requested.
+func newID(now time.Time) (string, error) {
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", err
+	}
+	return now.UTC().Format("20060102T150405.000000") + "-" + hex.EncodeToString(suffix), nil
+}
+
+func audit(a *vtctldatapb.CommandApproval, event string, caller string) {
+	approvalEvents.Add([]string{a.Command, event}, 1)
+	log.Infof("Command approval %v: %v %v by %v, requested by %v: %v", a.Id, a.Command, strings.ToLower(event), caller, a.Requester, a.Request)
+}
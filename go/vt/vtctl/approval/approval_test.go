@@ -0,0 +1,140 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package approval
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+
+	"vitess.io/vitess/go/vt/callerid"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// asCaller returns a context authenticated by a verified TLS client
+// certificate with the given common name.
+func asCaller(ctx context.Context, commonName string) context.Context {
+	cert := &x509.Certificate{Subject: pkix.Name{CommonName: commonName}}
+	return peer.NewContext(ctx, &peer.Peer{
+		AuthInfo: credentials.TLSInfo{
+			State: tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}},
+		},
+	})
+}
+
+func TestGate(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "zone1")
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	g := NewGate(ts, map[string]time.Duration{DeleteKeyspace: time.Hour}, 24*time.Hour)
+	g.now = func() time.Time { return now }
+
+	alice := asCaller(ctx, "alice")
+	bob := asCaller(ctx, "bob")
+	req := &vtctldatapb.DeleteKeyspaceRequest{Keyspace: "ks", Recursive: true}
+
+	// Classes that are not gated run right away.
+	require.NoError(t, g.Check(ctx, EmergencyReparentShard, &vtctldatapb.EmergencyReparentShardRequest{}))
+
+	err := g.Check(ctx, DeleteKeyspace, req)
+	assert.Equal(t, vtrpcpb.Code_PERMISSION_DENIED, vterrors.Code(err))
+	// The caller id is set by the client, so it does not authenticate anyone.
+	spoofed := callerid.NewContext(ctx, callerid.NewEffectiveCallerID("alice", "", ""), nil)
+	err = g.Check(spoofed, DeleteKeyspace, req)
+	assert.Equal(t, vtrpcpb.Code_PERMISSION_DENIED, vterrors.Code(err))
+
+	err = g.Check(alice, DeleteKeyspace, req)
+	assert.Equal(t, vtrpcpb.Code_FAILED_PRECONDITION, vterrors.Code(err))
+	approvals, err := g.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, approvals, 1)
+	a := approvals[0]
+	assert.Equal(t, "alice", a.Requester)
+	assert.ErrorContains(t, g.Check(alice, DeleteKeyspace, req), "approval "+a.Id+", requested by alice, is still pending")
+
+	// The requester cannot approve their own command.
+	_, err = g.Approve(alice, a.Id)
+	assert.Equal(t, vtrpcpb.Code_PERMISSION_DENIED, vterrors.Code(err))
+	_, err = g.Approve(bob, "unknown")
+	assert.Equal(t, vtrpcpb.Code_NOT_FOUND, vterrors.Code(err))
+	a, err = g.Approve(bob, a.Id)
+	require.NoError(t, err)
+	assert.Equal(t, "bob", a.Approver)
+
+	// The approval only covers the same request, and runs it once.
+	now = now.Add(time.Minute)
+	err = g.Check(alice, DeleteKeyspace, &vtctldatapb.DeleteKeyspaceRequest{Keyspace: "ks"})
+	assert.Equal(t, vtrpcpb.Code_FAILED_PRECONDITION, vterrors.Code(err))
+	require.NoError(t, g.Check(alice, DeleteKeyspace, req))
+	now = now.Add(time.Minute)
+	err = g.Check(alice, DeleteKeyspace, req)
+	assert.Equal(t, vtrpcpb.Code_FAILED_PRECONDITION, vterrors.Code(err))
+
+	approvals, err = g.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, approvals, 3)
+	assert.Equal(t, "alice", approvals[0].Executor)
+	assert.Equal(t, a.Id, approvals[0].Id)
+
+	// Approvals cannot be given past their window, and are deleted past the
+	// retention.
+	now = now.Add(2 * time.Hour)
+	_, err = g.Approve(bob, approvals[1].Id)
+	assert.ErrorContains(t, err, "expired")
+	now = now.Add(24 * time.Hour)
+	approvals, err = g.List(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, approvals)
+}
+
+func TestNewGateFromFlags(t *testing.T) {
+	defer func(old []string) { requiredCommands = old }(requiredCommands)
+	defer func(old map[string]time.Duration) { requiredWindows = old }(requiredWindows)
+
+	requiredCommands = nil
+	windows, err := parseRequiredCommands()
+	require.NoError(t, err)
+	requiredWindows = windows
+	g := NewGateFromFlags(nil)
+	assert.Nil(t, g)
+	// A nil gate lets everything run.
+	assert.NoError(t, g.Check(context.Background(), DeleteKeyspace, &vtctldatapb.DeleteKeyspaceRequest{}))
+
+	requiredCommands = []string{"DeleteKeyspace", "DropSources=30m"}
+	windows, err = parseRequiredCommands()
+	require.NoError(t, err)
+	requiredWindows = windows
+	g = NewGateFromFlags(nil)
+	assert.Equal(t, map[string]time.Duration{DeleteKeyspace: defaultWindow, DropSources: 30 * time.Minute}, g.windows)
+
+	requiredCommands = []string{"DeleteShard"}
+	_, err = parseRequiredCommands()
+	assert.ErrorContains(t, err, `unknown command class "DeleteShard"`)
+}
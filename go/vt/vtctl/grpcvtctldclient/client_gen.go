@@ -92,6 +92,15 @@ func (client *gRPCVtctldClient) ApplyVSchema(ctx context.Context, in *vtctldatap
 	return client.c.ApplyVSchema(ctx, in, opts...)
 }
 
+// ApproveCommand is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) ApproveCommand(ctx context.Context, in *vtctldatapb.ApproveCommandRequest, opts ...grpc.CallOption) (*vtctldatapb.ApproveCommandResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.ApproveCommand(ctx, in, opts...)
+}
+
 // Backup is part of the vtctlservicepb.VtctldClient interface.
 func (client *gRPCVtctldClient) Backup(ctx context.Context, in *vtctldatapb.BackupRequest, opts ...grpc.CallOption) (vtctlservicepb.Vtctld_BackupClient, error) {
 	if client.c == nil {
@@ -173,6 +182,15 @@ func (client *gRPCVtctldClient) CreateShard(ctx context.Context, in *vtctldatapb
 	return client.c.CreateShard(ctx, in, opts...)
 }
 
+// DecommissionCell is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) DecommissionCell(ctx context.Context, in *vtctldatapb.DecommissionCellRequest, opts ...grpc.CallOption) (*vtctldatapb.DecommissionCellResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.DecommissionCell(ctx, in, opts...)
+}
+
 // DeleteCellInfo is part of the vtctlservicepb.VtctldClient interface.
 func (client *gRPCVtctldClient) DeleteCellInfo(ctx context.Context, in *vtctldatapb.DeleteCellInfoRequest, opts ...grpc.CallOption) (*vtctldatapb.DeleteCellInfoResponse, error) {
 	if client.c == nil {
@@ -272,6 +290,15 @@ func (client *gRPCVtctldClient) ExecuteMultiFetchAsDBA(ctx context.Context, in *
 	return client.c.ExecuteMultiFetchAsDBA(ctx, in, opts...)
 }
 
+// ExportTopology is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) ExportTopology(ctx context.Context, in *vtctldatapb.ExportTopologyRequest, opts ...grpc.CallOption) (*vtctldatapb.ExportTopologyResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.ExportTopology(ctx, in, opts...)
+}
+
 // FindAllShardsInKeyspace is part of the vtctlservicepb.VtctldClient interface.
 func (client *gRPCVtctldClient) FindAllShardsInKeyspace(ctx context.Context, in *vtctldatapb.FindAllShardsInKeyspaceRequest, opts ...grpc.CallOption) (*vtctldatapb.FindAllShardsInKeyspaceResponse, error) {
 	if client.c == nil {
@@ -290,6 +317,24 @@ func (client *gRPCVtctldClient) ForceCutOverSchemaMigration(ctx context.Context,
 	return client.c.ForceCutOverSchemaMigration(ctx, in, opts...)
 }
 
+// ForceUnlock is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) ForceUnlock(ctx context.Context, in *vtctldatapb.ForceUnlockRequest, opts ...grpc.CallOption) (*vtctldatapb.ForceUnlockResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.ForceUnlock(ctx, in, opts...)
+}
+
+// GetElections is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) GetElections(ctx context.Context, in *vtctldatapb.GetElectionsRequest, opts ...grpc.CallOption) (*vtctldatapb.GetElectionsResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.GetElections(ctx, in, opts...)
+}
+
 // GetBackups is part of the vtctlservicepb.VtctldClient interface.
 func (client *gRPCVtctldClient) GetBackups(ctx context.Context, in *vtctldatapb.GetBackupsRequest, opts ...grpc.CallOption) (*vtctldatapb.GetBackupsResponse, error) {
 	if client.c == nil {
@@ -326,6 +371,15 @@ func (client *gRPCVtctldClient) GetCellsAliases(ctx context.Context, in *vtctlda
 	return client.c.GetCellsAliases(ctx, in, opts...)
 }
 
+// GetCommandApprovals is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) GetCommandApprovals(ctx context.Context, in *vtctldatapb.GetCommandApprovalsRequest, opts ...grpc.CallOption) (*vtctldatapb.GetCommandApprovalsResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.GetCommandApprovals(ctx, in, opts...)
+}
+
 // GetFullStatus is part of the vtctlservicepb.VtctldClient interface.
 func (client *gRPCVtctldClient) GetFullStatus(ctx context.Context, in *vtctldatapb.GetFullStatusRequest, opts ...grpc.CallOption) (*vtctldatapb.GetFullStatusResponse, error) {
 	if client.c == nil {
@@ -344,6 +398,15 @@ func (client *gRPCVtctldClient) GetKeyspace(ctx context.Context, in *vtctldatapb
 	return client.c.GetKeyspace(ctx, in, opts...)
 }
 
+// GetKeyspaceLabels is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) GetKeyspaceLabels(ctx context.Context, in *vtctldatapb.GetKeyspaceLabelsRequest, opts ...grpc.CallOption) (*vtctldatapb.GetKeyspaceLabelsResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.GetKeyspaceLabels(ctx, in, opts...)
+}
+
 // GetKeyspaceRoutingRules is part of the vtctlservicepb.VtctldClient interface.
 func (client *gRPCVtctldClient) GetKeyspaceRoutingRules(ctx context.Context, in *vtctldatapb.GetKeyspaceRoutingRulesRequest, opts ...grpc.CallOption) (*vtctldatapb.GetKeyspaceRoutingRulesResponse, error) {
 	if client.c == nil {
@@ -362,6 +425,15 @@ func (client *gRPCVtctldClient) GetKeyspaces(ctx context.Context, in *vtctldatap
 	return client.c.GetKeyspaces(ctx, in, opts...)
 }
 
+// GetLocks is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) GetLocks(ctx context.Context, in *vtctldatapb.GetLocksRequest, opts ...grpc.CallOption) (*vtctldatapb.GetLocksResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.GetLocks(ctx, in, opts...)
+}
+
 // GetPermissions is part of the vtctlservicepb.VtctldClient interface.
 func (client *gRPCVtctldClient) GetPermissions(ctx context.Context, in *vtctldatapb.GetPermissionsRequest, opts ...grpc.CallOption) (*vtctldatapb.GetPermissionsResponse, error) {
 	if client.c == nil {
@@ -407,6 +479,15 @@ func (client *gRPCVtctldClient) GetShard(ctx context.Context, in *vtctldatapb.Ge
 	return client.c.GetShard(ctx, in, opts...)
 }
 
+// GetShardLabels is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) GetShardLabels(ctx context.Context, in *vtctldatapb.GetShardLabelsRequest, opts ...grpc.CallOption) (*vtctldatapb.GetShardLabelsResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.GetShardLabels(ctx, in, opts...)
+}
+
 // GetShardReplication is part of the vtctlservicepb.VtctldClient interface.
 func (client *gRPCVtctldClient) GetShardReplication(ctx context.Context, in *vtctldatapb.GetShardReplicationRequest, opts ...grpc.CallOption) (*vtctldatapb.GetShardReplicationResponse, error) {
 	if client.c == nil {
@@ -506,6 +587,15 @@ func (client *gRPCVtctldClient) GetVSchema(ctx context.Context, in *vtctldatapb.
 	return client.c.GetVSchema(ctx, in, opts...)
 }
 
+// GetVSchemaHistory is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) GetVSchemaHistory(ctx context.Context, in *vtctldatapb.GetVSchemaHistoryRequest, opts ...grpc.CallOption) (*vtctldatapb.GetVSchemaHistoryResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.GetVSchemaHistory(ctx, in, opts...)
+}
+
 // GetVersion is part of the vtctlservicepb.VtctldClient interface.
 func (client *gRPCVtctldClient) GetVersion(ctx context.Context, in *vtctldatapb.GetVersionRequest, opts ...grpc.CallOption) (*vtctldatapb.GetVersionResponse, error) {
 	if client.c == nil {
@@ -524,6 +614,15 @@ func (client *gRPCVtctldClient) GetWorkflows(ctx context.Context, in *vtctldatap
 	return client.c.GetWorkflows(ctx, in, opts...)
 }
 
+// ImportTopology is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) ImportTopology(ctx context.Context, in *vtctldatapb.ImportTopologyRequest, opts ...grpc.CallOption) (*vtctldatapb.ImportTopologyResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.ImportTopology(ctx, in, opts...)
+}
+
 // InitShardPrimary is part of the vtctlservicepb.VtctldClient interface.
 func (client *gRPCVtctldClient) InitShardPrimary(ctx context.Context, in *vtctldatapb.InitShardPrimaryRequest, opts ...grpc.CallOption) (*vtctldatapb.InitShardPrimaryResponse, error) {
 	if client.c == nil {
@@ -542,6 +641,15 @@ func (client *gRPCVtctldClient) LaunchSchemaMigration(ctx context.Context, in *v
 	return client.c.LaunchSchemaMigration(ctx, in, opts...)
 }
 
+// ListTopologyPath is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) ListTopologyPath(ctx context.Context, in *vtctldatapb.ListTopologyPathRequest, opts ...grpc.CallOption) (*vtctldatapb.ListTopologyPathResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.ListTopologyPath(ctx, in, opts...)
+}
+
 // LookupVindexCreate is part of the vtctlservicepb.VtctldClient interface.
 func (client *gRPCVtctldClient) LookupVindexCreate(ctx context.Context, in *vtctldatapb.LookupVindexCreateRequest, opts ...grpc.CallOption) (*vtctldatapb.LookupVindexCreateResponse, error) {
 	if client.c == nil {
@@ -641,6 +749,15 @@ func (client *gRPCVtctldClient) PingTablet(ctx context.Context, in *vtctldatapb.
 	return client.c.PingTablet(ctx, in, opts...)
 }
 
+// PingTopoWatch is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) PingTopoWatch(ctx context.Context, in *vtctldatapb.PingTopoWatchRequest, opts ...grpc.CallOption) (*vtctldatapb.PingTopoWatchResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.PingTopoWatch(ctx, in, opts...)
+}
+
 // PlannedReparentShard is part of the vtctlservicepb.VtctldClient interface.
 func (client *gRPCVtctldClient) PlannedReparentShard(ctx context.Context, in *vtctldatapb.PlannedReparentShardRequest, opts ...grpc.CallOption) (*vtctldatapb.PlannedReparentShardResponse, error) {
 	if client.c == nil {
@@ -758,6 +875,15 @@ func (client *gRPCVtctldClient) ReshardCreate(ctx context.Context, in *vtctldata
 	return client.c.ReshardCreate(ctx, in, opts...)
 }
 
+// ResignLeader is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) ResignLeader(ctx context.Context, in *vtctldatapb.ResignLeaderRequest, opts ...grpc.CallOption) (*vtctldatapb.ResignLeaderResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.ResignLeader(ctx, in, opts...)
+}
+
 // RestoreFromBackup is part of the vtctlservicepb.VtctldClient interface.
 func (client *gRPCVtctldClient) RestoreFromBackup(ctx context.Context, in *vtctldatapb.RestoreFromBackupRequest, opts ...grpc.CallOption) (vtctlservicepb.Vtctld_RestoreFromBackupClient, error) {
 	if client.c == nil {
@@ -767,6 +893,15 @@ func (client *gRPCVtctldClient) RestoreFromBackup(ctx context.Context, in *vtctl
 	return client.c.RestoreFromBackup(ctx, in, opts...)
 }
 
+// RestoreTopoFromBackups is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) RestoreTopoFromBackups(ctx context.Context, in *vtctldatapb.RestoreTopoFromBackupsRequest, opts ...grpc.CallOption) (*vtctldatapb.RestoreTopoFromBackupsResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.RestoreTopoFromBackups(ctx, in, opts...)
+}
+
 // RetrySchemaMigration is part of the vtctlservicepb.VtctldClient interface.
 func (client *gRPCVtctldClient) RetrySchemaMigration(ctx context.Context, in *vtctldatapb.RetrySchemaMigrationRequest, opts ...grpc.CallOption) (*vtctldatapb.RetrySchemaMigrationResponse, error) {
 	if client.c == nil {
@@ -776,6 +911,15 @@ func (client *gRPCVtctldClient) RetrySchemaMigration(ctx context.Context, in *vt
 	return client.c.RetrySchemaMigration(ctx, in, opts...)
 }
 
+// RollbackVSchema is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) RollbackVSchema(ctx context.Context, in *vtctldatapb.RollbackVSchemaRequest, opts ...grpc.CallOption) (*vtctldatapb.RollbackVSchemaResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.RollbackVSchema(ctx, in, opts...)
+}
+
 // RunHealthCheck is part of the vtctlservicepb.VtctldClient interface.
 func (client *gRPCVtctldClient) RunHealthCheck(ctx context.Context, in *vtctldatapb.RunHealthCheckRequest, opts ...grpc.CallOption) (*vtctldatapb.RunHealthCheckResponse, error) {
 	if client.c == nil {
@@ -794,6 +938,15 @@ func (client *gRPCVtctldClient) SetKeyspaceDurabilityPolicy(ctx context.Context,
 	return client.c.SetKeyspaceDurabilityPolicy(ctx, in, opts...)
 }
 
+// SetKeyspaceLabels is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) SetKeyspaceLabels(ctx context.Context, in *vtctldatapb.SetKeyspaceLabelsRequest, opts ...grpc.CallOption) (*vtctldatapb.SetKeyspaceLabelsResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.SetKeyspaceLabels(ctx, in, opts...)
+}
+
 // SetShardIsPrimaryServing is part of the vtctlservicepb.VtctldClient interface.
 func (client *gRPCVtctldClient) SetShardIsPrimaryServing(ctx context.Context, in *vtctldatapb.SetShardIsPrimaryServingRequest, opts ...grpc.CallOption) (*vtctldatapb.SetShardIsPrimaryServingResponse, error) {
 	if client.c == nil {
@@ -803,6 +956,15 @@ func (client *gRPCVtctldClient) SetShardIsPrimaryServing(ctx context.Context, in
 	return client.c.SetShardIsPrimaryServing(ctx, in, opts...)
 }
 
+// SetShardLabels is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) SetShardLabels(ctx context.Context, in *vtctldatapb.SetShardLabelsRequest, opts ...grpc.CallOption) (*vtctldatapb.SetShardLabelsResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.SetShardLabels(ctx, in, opts...)
+}
+
 // SetShardTabletControl is part of the vtctlservicepb.VtctldClient interface.
 func (client *gRPCVtctldClient) SetShardTabletControl(ctx context.Context, in *vtctldatapb.SetShardTabletControlRequest, opts ...grpc.CallOption) (*vtctldatapb.SetShardTabletControlResponse, error) {
 	if client.c == nil {
@@ -902,6 +1064,15 @@ func (client *gRPCVtctldClient) StopReplication(ctx context.Context, in *vtctlda
 	return client.c.StopReplication(ctx, in, opts...)
 }
 
+// SwapRoutingRules is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) SwapRoutingRules(ctx context.Context, in *vtctldatapb.SwapRoutingRulesRequest, opts ...grpc.CallOption) (*vtctldatapb.SwapRoutingRulesResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.SwapRoutingRules(ctx, in, opts...)
+}
+
 // TabletExternallyReparented is part of the vtctlservicepb.VtctldClient interface.
 func (client *gRPCVtctldClient) TabletExternallyReparented(ctx context.Context, in *vtctldatapb.TabletExternallyReparentedRequest, opts ...grpc.CallOption) (*vtctldatapb.TabletExternallyReparentedResponse, error) {
 	if client.c == nil {
@@ -1046,6 +1217,15 @@ func (client *gRPCVtctldClient) ValidateVersionShard(ctx context.Context, in *vt
 	return client.c.ValidateVersionShard(ctx, in, opts...)
 }
 
+// WatchTopologyPath is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) WatchTopologyPath(ctx context.Context, in *vtctldatapb.WatchTopologyPathRequest, opts ...grpc.CallOption) (vtctlservicepb.Vtctld_WatchTopologyPathClient, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.WatchTopologyPath(ctx, in, opts...)
+}
+
 // WorkflowDelete is part of the vtctlservicepb.VtctldClient interface.
 func (client *gRPCVtctldClient) WorkflowDelete(ctx context.Context, in *vtctldatapb.WorkflowDeleteRequest, opts ...grpc.CallOption) (*vtctldatapb.WorkflowDeleteResponse, error) {
 	if client.c == nil {
@@ -23,8 +23,11 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"path"
 	"path/filepath"
+	"regexp"
 	"runtime/debug"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -55,9 +58,12 @@ import (
 	"vitess.io/vitess/go/vt/schemamanager"
 	"vitess.io/vitess/go/vt/sqlparser"
 	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/helpers"
 	"vitess.io/vitess/go/vt/topo/topoproto"
+	"vitess.io/vitess/go/vt/topo/watchping"
 	"vitess.io/vitess/go/vt/topotools"
 	"vitess.io/vitess/go/vt/topotools/events"
+	"vitess.io/vitess/go/vt/vtctl/approval"
 	"vitess.io/vitess/go/vt/vtctl/reparentutil"
 	"vitess.io/vitess/go/vt/vtctl/schematools"
 	"vitess.io/vitess/go/vt/vtctl/workflow"
@@ -83,6 +89,10 @@ const (
 
 	// DefaultWaitReplicasTimeout is the default value for waitReplicasTimeout, which is used when calling method ApplySchema.
 	DefaultWaitReplicasTimeout = 10 * time.Second
+
+	// DefaultForceUnlockMinAge is the default age a stale lock must have for
+	// ForceUnlock to break it.
+	DefaultForceUnlockMinAge = time.Hour
 )
 
 // VtctldServer implements the Vtctld RPC service protocol.
@@ -91,6 +101,10 @@ type VtctldServer struct {
 	ts  *topo.Server
 	tmc tmclient.TabletManagerClient
 	ws  *workflow.Server
+
+	// approvals gates the destructive commands. It is nil unless some
+	// command requires approval.
+	approvals *approval.Gate
 }
 
 // NewVtctldServer returns a new VtctldServer for the given topo server.
@@ -98,9 +112,10 @@ func NewVtctldServer(env *vtenv.Environment, ts *topo.Server) *VtctldServer {
 	tmc := tmclient.NewTabletManagerClient()
 
 	return &VtctldServer{
-		ts:  ts,
-		tmc: tmc,
-		ws:  workflow.NewServer(env, ts, tmc),
+		ts:        ts,
+		tmc:       tmc,
+		ws:        workflow.NewServer(env, ts, tmc),
+		approvals: approval.NewGateFromFlags(ts),
 	}
 }
 
@@ -155,15 +170,36 @@ func (s *VtctldServer) AddCellsAlias(ctx context.Context, req *vtctldatapb.AddCe
 
 	span.Annotate("cells_alias", req.Name)
 	span.Annotate("cells", strings.Join(req.Cells, ","))
+	span.Annotate("dry_run", req.DryRun)
 
 	ctx, cancel := context.WithTimeout(ctx, topo.RemoteOperationTimeout)
 	defer cancel()
 
-	if err = s.ts.CreateCellsAlias(ctx, req.Name, &topodatapb.CellsAlias{Cells: req.Cells}); err != nil {
+	ca := &topodatapb.CellsAlias{Cells: req.Cells}
+	if err = s.ts.ValidateCellsAlias(ctx, req.Name, ca); err != nil {
+		return nil, err
+	}
+
+	impact, err := s.ts.AnalyzeCellsAliasChange(ctx, req.Name, req.Cells)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.DryRun {
+		// Creating an alias that already exists fails, so fail the dry run
+		// the same way.
+		if _, err = s.ts.GetCellsAlias(ctx, req.Name, true); err == nil {
+			return nil, topo.NewError(topo.NodeExists, req.Name)
+		} else if !topo.IsErrType(err, topo.NoNode) {
+			return nil, err
+		}
+	} else if err = s.ts.CreateCellsAlias(ctx, req.Name, ca); err != nil {
 		return nil, err
 	}
 
-	return &vtctldatapb.AddCellsAliasResponse{}, nil
+	return &vtctldatapb.AddCellsAliasResponse{
+		Impact: cellsAliasImpactToProto(impact),
+	}, nil
 }
 
 // ApplyRoutingRules is part of the vtctlservicepb.VtctldServer interface.
@@ -319,6 +355,7 @@ func (s *VtctldServer) ApplyVSchema(ctx context.Context, req *vtctldatapb.ApplyV
 	span.Annotate("cells", strings.Join(req.Cells, ","))
 	span.Annotate("skip_rebuild", req.SkipRebuild)
 	span.Annotate("dry_run", req.DryRun)
+	span.Annotate("validate_against_schema", req.ValidateAgainstSchema)
 
 	if _, err = s.ts.GetKeyspace(ctx, req.Keyspace); err != nil {
 		if topo.IsErrType(err, topo.NoNode) {
@@ -402,18 +439,24 @@ func (s *VtctldServer) ApplyVSchema(ctx context.Context, req *vtctldatapb.ApplyV
 		return response, err
 	}
 
+	if req.ValidateAgainstSchema {
+		if err = s.validateVSchemaAgainstSchema(ctx, req.Keyspace, vs); err != nil {
+			return nil, err
+		}
+	}
+
 	if req.DryRun { // return early if dry run
 		return response, err
 	}
 
-	if err = s.ts.SaveVSchema(ctx, req.Keyspace, vs); err != nil {
-		err = vterrors.Wrapf(err, "SaveVSchema(%s, %v)", req.Keyspace, req.VSchema)
+	if err = s.ts.SaveVSchemaWithHistory(ctx, req.Keyspace, vs); err != nil {
+		err = vterrors.Wrapf(err, "SaveVSchemaWithHistory(%s, %v)", req.Keyspace, req.VSchema)
 		return nil, err
 	}
 
 	if !req.SkipRebuild {
-		if err = s.ts.RebuildSrvVSchema(ctx, req.Cells); err != nil {
-			err = vterrors.Wrapf(err, "RebuildSrvVSchema")
+		if err = s.ts.RebuildSrvVSchemaKeyspace(ctx, req.Keyspace, req.Cells); err != nil {
+			err = vterrors.Wrapf(err, "RebuildSrvVSchemaKeyspace")
 			return nil, err
 		}
 	}
@@ -426,6 +469,78 @@ func (s *VtctldServer) ApplyVSchema(ctx context.Context, req *vtctldatapb.ApplyV
 	return response, nil
 }
 
+// validateVSchemaAgainstSchema checks the vschema of a keyspace against the
+// schema of the primary of each of its shards, see
+// schematools.ValidateVSchemaAgainstSchema.
+func (s *VtctldServer) validateVSchemaAgainstSchema(ctx context.Context, keyspace string, vs *vschemapb.Keyspace) error {
+	shards, err := s.ts.GetShardNames(ctx, keyspace)
+	if err != nil {
+		return vterrors.Wrapf(err, "GetShardNames(%s)", keyspace)
+	}
+
+	var (
+		wg       sync.WaitGroup
+		m        sync.Mutex
+		rec      concurrency.AllErrorRecorder
+		problems []string
+	)
+	for _, shard := range shards {
+		wg.Add(1)
+		go func(shard string) {
+			defer wg.Done()
+
+			si, err := s.ts.GetShard(ctx, keyspace, shard)
+			if err != nil {
+				rec.RecordError(vterrors.Wrapf(err, "GetShard(%s, %s)", keyspace, shard))
+				return
+			}
+			if si.PrimaryAlias == nil {
+				rec.RecordError(vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "cannot validate the vschema against the schema of %s/%s, which has no primary", keyspace, shard))
+				return
+			}
+			r := &tabletmanagerdatapb.GetSchemaRequest{IncludeViews: true}
+			sd, err := schematools.GetSchema(ctx, s.ts, s.tmc, si.PrimaryAlias, r)
+			if err != nil {
+				rec.RecordError(err)
+				return
+			}
+
+			m.Lock()
+			defer m.Unlock()
+			for _, problem := range schematools.ValidateVSchemaAgainstSchema(vs, sd) {
+				problems = append(problems, fmt.Sprintf("%s/%s: %s", keyspace, shard, problem))
+			}
+		}(shard)
+	}
+	wg.Wait()
+	if rec.HasErrors() {
+		return rec.Error()
+	}
+
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "vschema does not match the schema: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// ApproveCommand is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) ApproveCommand(ctx context.Context, req *vtctldatapb.ApproveCommandRequest) (resp *vtctldatapb.ApproveCommandResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.ApproveCommand")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("id", req.Id)
+
+	a, err := s.approvals.Approve(ctx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &vtctldatapb.ApproveCommandResponse{Approval: a}, nil
+}
+
 // Backup is part of the vtctlservicepb.VtctldServer interface.
 func (s *VtctldServer) Backup(req *vtctldatapb.BackupRequest, stream vtctlservicepb.Vtctld_BackupServer) (err error) {
 	span, ctx := trace.NewSpan(stream.Context(), "VtctldServer.Backup")
@@ -743,6 +858,58 @@ func (s *VtctldServer) ForceCutOverSchemaMigration(ctx context.Context, req *vtc
 	return resp, nil
 }
 
+// ForceUnlock is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) ForceUnlock(ctx context.Context, req *vtctldatapb.ForceUnlockRequest) (resp *vtctldatapb.ForceUnlockResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.ForceUnlock")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("path", req.Path)
+	span.Annotate("reason", req.Reason)
+	span.Annotate("actor", req.Actor)
+
+	dirPath := strings.Trim(req.Path, "/")
+	if dirPath == "" {
+		err = vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "path is required")
+		return nil, err
+	}
+	minAge, ok, err := protoutil.DurationFromProto(req.MinAge)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		minAge = DefaultForceUnlockMinAge
+	}
+	span.Annotate("min_age", minAge.String())
+
+	lock, err := s.ts.ForceUnlock(ctx, dirPath, minAge)
+	if err != nil {
+		if topo.IsErrType(err, topo.NoImplementation) {
+			err = vterrors.Errorf(vtrpcpb.Code_UNIMPLEMENTED, "the topology server can't break its locks: %v", err)
+		}
+		return nil, err
+	}
+
+	caller := approval.Caller(ctx)
+	log.Warningf("ForceUnlock: %v (actor: %v) broke the lock on %v: %v (holder: %v)", caller, req.Actor, dirPath, req.Reason, lock.Contents)
+	if err = s.ts.AddAuditLogEntry(ctx, &topodatapb.AuditLogEntry{
+		Action:  "ForceUnlock",
+		Path:    dirPath,
+		Caller:  caller,
+		Actor:   req.Actor,
+		Details: fmt.Sprintf("reason: %v, holder: %v", req.Reason, lock.Contents),
+	}); err != nil {
+		// The lock is broken already, so fail loudly for the missing record.
+		err = vterrors.Wrapf(err, "broke the lock on %v, but failed to record it in the audit log", dirPath)
+		return nil, err
+	}
+
+	return &vtctldatapb.ForceUnlockResponse{
+		Lock: heldLockToProto(lock),
+	}, nil
+}
+
 // CompleteSchemaMigration is part of the vtctlservicepb.VtctldServer interface.
 func (s *VtctldServer) CompleteSchemaMigration(ctx context.Context, req *vtctldatapb.CompleteSchemaMigrationRequest) (resp *vtctldatapb.CompleteSchemaMigrationResponse, err error) {
 	span, ctx := trace.NewSpan(ctx, "VtctldServer.CompleteSchemaMigration")
@@ -940,6 +1107,63 @@ func (s *VtctldServer) CreateShard(ctx context.Context, req *vtctldatapb.CreateS
 	}, nil
 }
 
+// DecommissionCell is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) DecommissionCell(ctx context.Context, req *vtctldatapb.DecommissionCellRequest) (resp *vtctldatapb.DecommissionCellResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.DecommissionCell")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("cell", req.Cell)
+	span.Annotate("dry_run", req.DryRun)
+
+	if req.Cell == "" {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "cell is required")
+	}
+
+	keyspaces, err := s.ts.GetKeyspaces(ctx)
+	if err != nil {
+		return nil, err
+	}
+	workflows := make(map[string][]*vtctldatapb.Workflow, len(keyspaces))
+	for _, keyspace := range keyspaces {
+		shards, err := s.ts.GetShardNames(ctx, keyspace)
+		if err != nil && !topo.IsErrType(err, topo.NoNode) {
+			return nil, err
+		}
+		if len(shards) == 0 {
+			// Keyspaces without shards have no workflows.
+			continue
+		}
+		wresp, err := s.ws.GetWorkflows(ctx, &vtctldatapb.GetWorkflowsRequest{Keyspace: keyspace})
+		if err != nil {
+			return nil, vterrors.Wrapf(err, "cannot read the workflows of keyspace %v", keyspace)
+		}
+		workflows[keyspace] = wresp.Workflows
+	}
+
+	d, err := helpers.PlanCellDecommission(ctx, s.ts, req.Cell, workflows)
+	if err != nil {
+		return nil, err
+	}
+	resp = &vtctldatapb.DecommissionCellResponse{
+		Tablets:      d.Tablets,
+		Streams:      d.Streams,
+		CellsAliases: d.CellsAliases,
+		Files:        d.Files,
+		Steps:        d.Steps(),
+	}
+	if req.DryRun || d.Blocked() {
+		return resp, nil
+	}
+
+	if err := helpers.DecommissionCell(ctx, s.ts, d); err != nil {
+		return nil, err
+	}
+	resp.Decommissioned = true
+	return resp, nil
+}
+
 // DeleteCellInfo is part of the vtctlservicepb.VtctldServer interface.
 func (s *VtctldServer) DeleteCellInfo(ctx context.Context, req *vtctldatapb.DeleteCellInfoRequest) (resp *vtctldatapb.DeleteCellInfoResponse, err error) {
 	span, ctx := trace.NewSpan(ctx, "VtctldServer.DeleteCellInfo")
@@ -968,15 +1192,29 @@ func (s *VtctldServer) DeleteCellsAlias(ctx context.Context, req *vtctldatapb.De
 	defer panicHandler(&err)
 
 	span.Annotate("cells_alias", req.Name)
+	span.Annotate("dry_run", req.DryRun)
 
 	ctx, cancel := context.WithTimeout(ctx, topo.RemoteOperationTimeout)
 	defer cancel()
 
-	if err = s.ts.DeleteCellsAlias(ctx, req.Name); err != nil {
+	impact, err := s.ts.AnalyzeCellsAliasChange(ctx, req.Name, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.DryRun {
+		// Deleting an alias that does not exist fails, so fail the dry run
+		// the same way.
+		if _, err = s.ts.GetCellsAlias(ctx, req.Name, true); err != nil {
+			return nil, err
+		}
+	} else if err = s.ts.DeleteCellsAlias(ctx, req.Name); err != nil {
 		return nil, err
 	}
 
-	return &vtctldatapb.DeleteCellsAliasResponse{}, nil
+	return &vtctldatapb.DeleteCellsAliasResponse{
+		Impact: cellsAliasImpactToProto(impact),
+	}, nil
 }
 
 // DeleteKeyspace is part of the vtctlservicepb.VtctldServer interface.
@@ -990,6 +1228,10 @@ func (s *VtctldServer) DeleteKeyspace(ctx context.Context, req *vtctldatapb.Dele
 	span.Annotate("recursive", req.Recursive)
 	span.Annotate("force", req.Force)
 
+	if err = s.approvals.Check(ctx, approval.DeleteKeyspace, req); err != nil {
+		return nil, err
+	}
+
 	lctx, unlock, lerr := s.ts.LockKeyspace(ctx, req.Keyspace, "DeleteKeyspace")
 	switch {
 	case lerr == nil:
@@ -1157,6 +1399,10 @@ func (s *VtctldServer) EmergencyReparentShard(ctx context.Context, req *vtctldat
 	span.Annotate("prevent_cross_cell_promotion", req.PreventCrossCellPromotion)
 	span.Annotate("wait_for_all_tablets", req.WaitForAllTablets)
 
+	if err = s.approvals.Check(ctx, approval.EmergencyReparentShard, req); err != nil {
+		return nil, err
+	}
+
 	m := sync.RWMutex{}
 	logstream := []*logutilpb.Event{}
 	logger := logutil.NewCallbackLogger(func(e *logutilpb.Event) {
@@ -1327,6 +1573,39 @@ func (s *VtctldServer) ExecuteHook(ctx context.Context, req *vtctldatapb.Execute
 	}}, nil
 }
 
+// ExportTopology is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) ExportTopology(ctx context.Context, req *vtctldatapb.ExportTopologyRequest) (resp *vtctldatapb.ExportTopologyResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.ExportTopology")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("cells", strings.Join(req.Cells, ","))
+	span.Annotate("path", req.Path)
+	span.Annotate("filter", req.Filter)
+	span.Annotate("shallow", req.Shallow)
+
+	if len(req.Cells) == 0 {
+		err = vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "at least one cell is required")
+		return nil, err
+	}
+
+	opts := helpers.ExportOptions{Shallow: req.Shallow}
+	if req.Filter != "" {
+		if opts.Filter, err = regexp.Compile(req.Filter); err != nil {
+			err = vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid filter %q: %v", req.Filter, err)
+			return nil, err
+		}
+	}
+
+	files, err := helpers.ExportTopo(ctx, s.ts, req.Cells, strings.Trim(req.Path, "/"), opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &vtctldatapb.ExportTopologyResponse{Files: files}, nil
+}
+
 // FindAllShardsInKeyspace is part of the vtctlservicepb.VtctldServer interface.
 func (s *VtctldServer) FindAllShardsInKeyspace(ctx context.Context, req *vtctldatapb.FindAllShardsInKeyspaceRequest) (resp *vtctldatapb.FindAllShardsInKeyspaceResponse, err error) {
 	span, ctx := trace.NewSpan(ctx, "VtctldServer.FindAllShardsInKeyspace")
@@ -1355,6 +1634,23 @@ func (s *VtctldServer) FindAllShardsInKeyspace(ctx context.Context, req *vtctlda
 	}, nil
 }
 
+// GetElections is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) GetElections(ctx context.Context, req *vtctldatapb.GetElectionsRequest) (resp *vtctldatapb.GetElectionsResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.GetElections")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	elections, err := s.ts.GetElections(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &vtctldatapb.GetElectionsResponse{
+		Elections: elections,
+	}, nil
+}
+
 // GetBackups is part of the vtctldservicepb.VtctldServer interface.
 func (s *VtctldServer) GetBackups(ctx context.Context, req *vtctldatapb.GetBackupsRequest) (resp *vtctldatapb.GetBackupsResponse, err error) {
 	span, ctx := trace.NewSpan(ctx, "VtctldServer.GetBackups")
@@ -1476,6 +1772,21 @@ func (s *VtctldServer) GetCellsAliases(ctx context.Context, req *vtctldatapb.Get
 	return &vtctldatapb.GetCellsAliasesResponse{Aliases: aliases}, nil
 }
 
+// GetCommandApprovals is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) GetCommandApprovals(ctx context.Context, req *vtctldatapb.GetCommandApprovalsRequest) (resp *vtctldatapb.GetCommandApprovalsResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.GetCommandApprovals")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	approvals, err := s.approvals.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &vtctldatapb.GetCommandApprovalsResponse{Approvals: approvals}, nil
+}
+
 // GetFullStatus is part of the vtctlservicepb.VtctldServer interface.
 func (s *VtctldServer) GetFullStatus(ctx context.Context, req *vtctldatapb.GetFullStatusRequest) (resp *vtctldatapb.GetFullStatusResponse, err error) {
 	span, ctx := trace.NewSpan(ctx, "VtctldServer.GetFullStatus")
@@ -1522,6 +1833,25 @@ func (s *VtctldServer) GetKeyspace(ctx context.Context, req *vtctldatapb.GetKeys
 	}, nil
 }
 
+// GetKeyspaceLabels is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) GetKeyspaceLabels(ctx context.Context, req *vtctldatapb.GetKeyspaceLabelsRequest) (resp *vtctldatapb.GetKeyspaceLabelsResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.GetKeyspaceLabels")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("keyspace", req.Keyspace)
+
+	keyspace, err := s.ts.GetKeyspace(ctx, req.Keyspace)
+	if err != nil {
+		return nil, err
+	}
+
+	return &vtctldatapb.GetKeyspaceLabelsResponse{
+		Labels: keyspace.Labels,
+	}, nil
+}
+
 // GetKeyspaces is part of the vtctlservicepb.VtctldServer interface.
 func (s *VtctldServer) GetKeyspaces(ctx context.Context, req *vtctldatapb.GetKeyspacesRequest) (resp *vtctldatapb.GetKeyspacesResponse, err error) {
 	span, ctx := trace.NewSpan(ctx, "VtctldServer.GetKeyspaces")
@@ -1534,21 +1864,94 @@ func (s *VtctldServer) GetKeyspaces(ctx context.Context, req *vtctldatapb.GetKey
 		return nil, err
 	}
 
-	keyspaces := make([]*vtctldatapb.Keyspace, len(names))
+	keyspaces := make([]*vtctldatapb.Keyspace, 0, len(names))
 
-	for i, name := range names {
+	for _, name := range names {
 		ks, err2 := s.GetKeyspace(ctx, &vtctldatapb.GetKeyspaceRequest{Keyspace: name})
 		if err2 != nil {
 			err = err2
 			return nil, err
 		}
 
-		keyspaces[i] = ks.Keyspace
+		if !topoproto.KeyspaceHasLabels(ks.Keyspace.Keyspace, req.Labels) {
+			continue
+		}
+
+		keyspaces = append(keyspaces, ks.Keyspace)
 	}
 
 	return &vtctldatapb.GetKeyspacesResponse{Keyspaces: keyspaces}, nil
 }
 
+// GetLocks is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) GetLocks(ctx context.Context, req *vtctldatapb.GetLocksRequest) (resp *vtctldatapb.GetLocksResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.GetLocks")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("keyspace", req.Keyspace)
+	span.Annotate("shard", req.Shard)
+	span.Annotate("path", req.Path)
+
+	var dirPaths []string
+	switch {
+	case req.Path != "":
+		if req.Keyspace != "" || req.Shard != "" {
+			err = vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "path cannot be combined with keyspace or shard")
+			return nil, err
+		}
+		dirPaths = []string{strings.Trim(req.Path, "/")}
+	case req.Shard != "":
+		if req.Keyspace == "" {
+			err = vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "shard requires a keyspace")
+			return nil, err
+		}
+		dirPaths = []string{path.Join(topo.KeyspacesPath, req.Keyspace, topo.ShardsPath, req.Shard)}
+	default:
+		keyspaces := []string{req.Keyspace}
+		if req.Keyspace == "" {
+			if keyspaces, err = s.ts.GetKeyspaces(ctx); err != nil {
+				return nil, err
+			}
+			dirPaths = append(dirPaths, topo.RoutingRulesPath)
+
+			names, err := s.ts.GetLockNames(ctx)
+			if err != nil {
+				return nil, err
+			}
+			for _, name := range names {
+				dirPaths = append(dirPaths, topo.NamedLockPath(name))
+			}
+		}
+		for _, keyspace := range keyspaces {
+			shards, err := s.ts.GetShardNames(ctx, keyspace)
+			if err != nil {
+				return nil, err
+			}
+			dirPaths = append(dirPaths, path.Join(topo.KeyspacesPath, keyspace), topo.KeyspaceRoutingLockPath(keyspace))
+			for _, shard := range shards {
+				dirPaths = append(dirPaths, path.Join(topo.KeyspacesPath, keyspace, topo.ShardsPath, shard))
+			}
+		}
+	}
+
+	resp = &vtctldatapb.GetLocksResponse{}
+	for _, dirPath := range dirPaths {
+		locks, err := s.ts.GetHeldLocks(ctx, dirPath)
+		if err != nil {
+			if topo.IsErrType(err, topo.NoImplementation) {
+				err = vterrors.Errorf(vtrpcpb.Code_UNIMPLEMENTED, "the topology server doesn't report the holders of its locks: %v", err)
+			}
+			return nil, err
+		}
+		for _, lock := range locks {
+			resp.Locks = append(resp.Locks, heldLockToProto(lock))
+		}
+	}
+	return resp, nil
+}
+
 // GetPermissions is part of the vtctlservicepb.VtctldServer interface.
 func (s *VtctldServer) GetPermissions(ctx context.Context, req *vtctldatapb.GetPermissionsRequest) (resp *vtctldatapb.GetPermissionsResponse, err error) {
 	span, ctx := trace.NewSpan(ctx, "VtctldServer.GetPermissions")
@@ -1798,6 +2201,26 @@ func (s *VtctldServer) GetShard(ctx context.Context, req *vtctldatapb.GetShardRe
 	}, nil
 }
 
+// GetShardLabels is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) GetShardLabels(ctx context.Context, req *vtctldatapb.GetShardLabelsRequest) (resp *vtctldatapb.GetShardLabelsResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.GetShardLabels")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("keyspace", req.Keyspace)
+	span.Annotate("shard", req.Shard)
+
+	shard, err := s.ts.GetShard(ctx, req.Keyspace, req.Shard)
+	if err != nil {
+		return nil, err
+	}
+
+	return &vtctldatapb.GetShardLabelsResponse{
+		Labels: shard.Labels,
+	}, nil
+}
+
 // GetShardReplication is part of the vtctlservicepb.VtctldServer interface.
 func (s *VtctldServer) GetShardReplication(ctx context.Context, req *vtctldatapb.GetShardReplicationRequest) (resp *vtctldatapb.GetShardReplicationResponse, err error) {
 	span, ctx := trace.NewSpan(ctx, "VtctldServer.GetShardReplication")
@@ -1971,6 +2394,11 @@ func (s *VtctldServer) UpdateThrottlerConfig(ctx context.Context, req *vtctldata
 		return nil, lockErr
 	}
 	defer unlock(&err)
+	ctx, unlockRouting, lockErr := s.ts.LockKeyspaceRouting(ctx, req.Keyspace, "UpdateThrottlerConfig")
+	if lockErr != nil {
+		return nil, lockErr
+	}
+	defer unlockRouting(&err)
 
 	ki, err := s.ts.GetKeyspace(ctx, req.Keyspace)
 	if err != nil {
@@ -2329,8 +2757,27 @@ func (s *VtctldServer) GetVSchema(ctx context.Context, req *vtctldatapb.GetVSche
 	}, nil
 }
 
-// GetWorkflows is part of the vtctlservicepb.VtctldServer interface.
-func (s *VtctldServer) GetWorkflows(ctx context.Context, req *vtctldatapb.GetWorkflowsRequest) (resp *vtctldatapb.GetWorkflowsResponse, err error) {
+// GetVSchemaHistory is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) GetVSchemaHistory(ctx context.Context, req *vtctldatapb.GetVSchemaHistoryRequest) (resp *vtctldatapb.GetVSchemaHistoryResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.GetVSchemaHistory")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("keyspace", req.Keyspace)
+
+	entries, err := s.ts.GetVSchemaHistory(ctx, req.Keyspace)
+	if err != nil {
+		return nil, err
+	}
+
+	return &vtctldatapb.GetVSchemaHistoryResponse{
+		Entries: entries,
+	}, nil
+}
+
+// GetWorkflows is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) GetWorkflows(ctx context.Context, req *vtctldatapb.GetWorkflowsRequest) (resp *vtctldatapb.GetWorkflowsResponse, err error) {
 	span, ctx := trace.NewSpan(ctx, "VtctldServer.GetWorkflows")
 	defer span.Finish()
 
@@ -2344,6 +2791,37 @@ func (s *VtctldServer) GetWorkflows(ctx context.Context, req *vtctldatapb.GetWor
 	return resp, err
 }
 
+// ImportTopology is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) ImportTopology(ctx context.Context, req *vtctldatapb.ImportTopologyRequest) (resp *vtctldatapb.ImportTopologyResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.ImportTopology")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("files", len(req.Files))
+	span.Annotate("overwrite", req.Overwrite)
+	span.Annotate("dry_run", req.DryRun)
+	span.Annotate("check_versions", req.CheckVersions)
+
+	result, err := helpers.ImportTopo(ctx, s.ts, req.Files, helpers.ImportOptions{
+		CellMappings:  req.CellMappings,
+		PathMappings:  req.PathMappings,
+		Overwrite:     req.Overwrite,
+		CheckVersions: req.CheckVersions,
+		DryRun:        req.DryRun,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &vtctldatapb.ImportTopologyResponse{
+		Created:   result.Created,
+		Updated:   result.Updated,
+		Skipped:   result.Skipped,
+		Conflicts: result.Conflicts,
+	}, nil
+}
+
 // InitShardPrimary is part of the vtctlservicepb.VtctldServer interface.
 func (s *VtctldServer) InitShardPrimary(ctx context.Context, req *vtctldatapb.InitShardPrimaryRequest) (resp *vtctldatapb.InitShardPrimaryResponse, err error) {
 	span, ctx := trace.NewSpan(ctx, "VtctldServer.InitShardPrimary")
@@ -2653,6 +3131,115 @@ func (s *VtctldServer) LaunchSchemaMigration(ctx context.Context, req *vtctldata
 	return resp, nil
 }
 
+// ListTopologyPath is part of the vtctlservicepb.VtctldServer interface.
+// Paths are the same as for GetTopologyPath. The page token is the name of
+// the last entry of the previous page, so that the pages stay consistent as
+// entries are added or removed.
+func (s *VtctldServer) ListTopologyPath(ctx context.Context, req *vtctldatapb.ListTopologyPathRequest) (resp *vtctldatapb.ListTopologyPathResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.ListTopologyPath")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("path", req.Path)
+	span.Annotate("page_size", req.PageSize)
+	span.Annotate("page_token", req.PageToken)
+	span.Annotate("include_data", req.IncludeData)
+
+	if req.PageSize < 0 {
+		err = vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "page size must be positive, got %d", req.PageSize)
+		return nil, err
+	}
+
+	dirPath := strings.TrimSuffix(req.Path, "/")
+	var (
+		conn         topo.Conn
+		relativePath string
+		entries      []*vtctldatapb.TopologyEntry
+	)
+	if dirPath == "" {
+		// The toplevel lists the cells.
+		cells, err := s.ts.GetKnownCells(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, cell := range append([]string{topo.GlobalCell}, cells...) {
+			entries = append(entries, &vtctldatapb.TopologyEntry{
+				Name:        cell,
+				Path:        "/" + cell,
+				IsDirectory: true,
+			})
+		}
+	} else {
+		parts := strings.Split(dirPath, "/")
+		if parts[0] != "" || parts[1] == "" {
+			err = vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid path: %s", req.Path)
+			return nil, err
+		}
+		cell := parts[1]
+		relativePath = dirPath[len(cell)+1:]
+		if relativePath == "" {
+			relativePath = "/"
+		}
+
+		conn, err = s.ts.ConnForCell(ctx, cell)
+		if err != nil {
+			err = vterrors.Errorf(vtrpcpb.Code_UNAVAILABLE, "error fetching connection to cell %s: %v", cell, err)
+			return nil, err
+		}
+		children, err := conn.ListDir(ctx, relativePath, true /*full*/)
+		if err != nil {
+			return nil, err
+		}
+		for _, child := range children {
+			entries = append(entries, &vtctldatapb.TopologyEntry{
+				Name:        child.Name,
+				Path:        dirPath + "/" + child.Name,
+				IsDirectory: child.Type == topo.TypeDirectory,
+			})
+		}
+	}
+
+	slices.SortFunc(entries, func(a, b *vtctldatapb.TopologyEntry) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	if req.PageToken != "" {
+		start := sort.Search(len(entries), func(i int) bool { return entries[i].Name > req.PageToken })
+		entries = entries[start:]
+	}
+	resp = &vtctldatapb.ListTopologyPathResponse{}
+	if req.PageSize > 0 && len(entries) > int(req.PageSize) {
+		entries = entries[:req.PageSize]
+		resp.NextPageToken = entries[len(entries)-1].Name
+	}
+
+	for _, entry := range entries {
+		if entry.IsDirectory {
+			continue
+		}
+		filePath := strings.TrimSuffix(relativePath, "/") + "/" + entry.Name
+		data, version, err := conn.Get(ctx, filePath)
+		if err != nil {
+			if topo.IsErrType(err, topo.NoNode) {
+				// Deleted since we listed the directory.
+				continue
+			}
+			return nil, err
+		}
+		if entry.Version, err = strconv.ParseInt(version.String(), 10, 64); err != nil {
+			err = vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "error decoding file version for %s (version: %s): %v", entry.Path, version, err)
+			return nil, err
+		}
+		entry.Size = int64(len(data))
+		if req.IncludeData {
+			// Records that can't be decoded are returned as is.
+			entry.Data, _ = topo.DecodeContent(filePath, data, topo.NewContentProto(filePath) != nil)
+		}
+	}
+	resp.Entries = entries
+	return resp, nil
+}
+
 // LookupVindexCreate is part of the vtctlservicepb.VtctldServer interface.
 func (s *VtctldServer) LookupVindexCreate(ctx context.Context, req *vtctldatapb.LookupVindexCreateRequest) (resp *vtctldatapb.LookupVindexCreateResponse, err error) {
 	span, ctx := trace.NewSpan(ctx, "VtctldServer.LookupVindexCreate")
@@ -2803,6 +3390,12 @@ func (s *VtctldServer) MoveTablesComplete(ctx context.Context, req *vtctldatapb.
 	span.Annotate("keep_routing_rules", req.KeepRoutingRules)
 	span.Annotate("dry_run", req.DryRun)
 
+	if !req.KeepData && !req.DryRun {
+		if err = s.approvals.Check(ctx, approval.DropSources, req); err != nil {
+			return nil, err
+		}
+	}
+
 	resp, err = s.ws.MoveTablesComplete(ctx, req)
 	return resp, err
 }
@@ -2829,6 +3422,78 @@ func (s *VtctldServer) PingTablet(ctx context.Context, req *vtctldatapb.PingTabl
 	return &vtctldatapb.PingTabletResponse{}, nil
 }
 
+// PingTopoWatch is part of the vtctldservicepb.VtctldServer interface.
+func (s *VtctldServer) PingTopoWatch(ctx context.Context, req *vtctldatapb.PingTopoWatchRequest) (resp *vtctldatapb.PingTopoWatchResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.PingTopoWatch")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("cell", req.Cell)
+	span.Annotate("path", req.Path)
+	span.Annotate("components", strings.Join(req.Components, ","))
+
+	if req.Cell == "" {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "cell must be specified")
+	}
+	path := req.Path
+	if path == "" {
+		path = watchping.DefaultPath
+	}
+	threshold, ok, err := protoutil.DurationFromProto(req.Threshold)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		threshold = watchping.DefaultThreshold
+	}
+	if threshold <= 0 {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "threshold must be positive, got %v", threshold)
+	}
+
+	// The vtctld checks its own watch, then the one of each component. A
+	// component is given either as the host:port of its HTTP server, or as
+	// the alias of a tablet.
+	components := []watchping.Component{{Name: "vtctld"}}
+	for _, c := range req.Components {
+		if strings.Contains(c, ":") {
+			components = append(components, watchping.Component{Name: c, Addr: c})
+			continue
+		}
+		alias, err := topoproto.ParseTabletAlias(c)
+		if err != nil {
+			return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "component %q is neither a host:port nor a tablet alias: %v", c, err)
+		}
+		tablet, err := s.ts.GetTablet(ctx, alias)
+		if err != nil {
+			return nil, err
+		}
+		components = append(components, watchping.Component{
+			Name: c,
+			Addr: netutil.JoinHostPort(tablet.Hostname, tablet.PortMap["vt"]),
+		})
+	}
+
+	results, err := watchping.Ping(ctx, s.ts, req.Cell, path, components, threshold)
+	if err != nil {
+		return nil, err
+	}
+
+	resp = &vtctldatapb.PingTopoWatchResponse{
+		Results: make([]*vtctldatapb.TopoWatchPingResult, 0, len(results)),
+	}
+	for _, r := range results {
+		result := &vtctldatapb.TopoWatchPingResult{Component: r.Component}
+		if r.Err != nil {
+			result.Error = r.Err.Error()
+		} else {
+			result.Latency = protoutil.DurationToProto(r.Latency)
+		}
+		resp.Results = append(resp.Results, result)
+	}
+	return resp, nil
+}
+
 // PlannedReparentShard is part of the vtctldservicepb.VtctldServer interface.
 func (s *VtctldServer) PlannedReparentShard(ctx context.Context, req *vtctldatapb.PlannedReparentShardRequest) (resp *vtctldatapb.PlannedReparentShardResponse, err error) {
 	span, ctx := trace.NewSpan(ctx, "VtctldServer.PlannedReparentShard")
@@ -2912,8 +3577,15 @@ func (s *VtctldServer) RebuildKeyspaceGraph(ctx context.Context, req *vtctldatap
 	span.Annotate("keyspace", req.Keyspace)
 	span.Annotate("cells", strings.Join(req.Cells, ","))
 	span.Annotate("allow_partial", req.AllowPartial)
+	span.Annotate("shards", strings.Join(req.Shards, ","))
 
-	if err = topotools.RebuildKeyspace(ctx, logutil.NewCallbackLogger(func(e *logutilpb.Event) {}), s.ts, req.Keyspace, req.Cells, req.AllowPartial); err != nil {
+	logger := logutil.NewCallbackLogger(func(e *logutilpb.Event) {})
+	if len(req.Shards) > 0 {
+		err = topotools.RebuildKeyspaceShards(ctx, logger, s.ts, req.Keyspace, req.Shards, req.Cells, req.AllowPartial)
+	} else {
+		err = topotools.RebuildKeyspace(ctx, logger, s.ts, req.Keyspace, req.Cells, req.AllowPartial)
+	}
+	if err != nil {
 		return nil, err
 	}
 
@@ -3289,6 +3961,48 @@ func (s *VtctldServer) ReshardCreate(ctx context.Context, req *vtctldatapb.Resha
 	resp, err = s.ws.ReshardCreate(ctx, req)
 	return resp, err
 }
+
+// ResignLeader is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) ResignLeader(ctx context.Context, req *vtctldatapb.ResignLeaderRequest) (resp *vtctldatapb.ResignLeaderResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.ResignLeader")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("name", req.Name)
+	span.Annotate("id", req.Id)
+	span.Annotate("reason", req.Reason)
+
+	if req.Name == "" {
+		err = vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "name is required")
+		return nil, err
+	}
+
+	id, err := s.ts.ResignLeader(ctx, req.Name, req.Id)
+	if err != nil {
+		if topo.IsErrType(err, topo.NoImplementation) {
+			err = vterrors.Errorf(vtrpcpb.Code_UNIMPLEMENTED, "the topology server can't force the primary of an election to resign: %v", err)
+		}
+		return nil, err
+	}
+
+	caller := approval.Caller(ctx)
+	log.Warningf("ResignLeader: %v forced %v to resign from election %v: %v", caller, id, req.Name, req.Reason)
+	if err = s.ts.AddAuditLogEntry(ctx, &topodatapb.AuditLogEntry{
+		Action:  "ResignLeader",
+		Path:    path.Join(topo.ElectionsPath, req.Name),
+		Caller:  caller,
+		Details: fmt.Sprintf("reason: %v, leader: %v", req.Reason, id),
+	}); err != nil {
+		// The primary resigned already, so fail loudly for the missing record.
+		err = vterrors.Wrapf(err, "forced %v to resign from election %v, but failed to record it in the audit log", id, req.Name)
+		return nil, err
+	}
+
+	return &vtctldatapb.ResignLeaderResponse{
+		LeaderId: id,
+	}, nil
+}
 func (s *VtctldServer) RestoreFromBackup(req *vtctldatapb.RestoreFromBackupRequest, stream vtctlservicepb.Vtctld_RestoreFromBackupServer) (err error) {
 	span, ctx := trace.NewSpan(stream.Context(), "VtctldServer.RestoreFromBackup")
 	defer span.Finish()
@@ -3366,6 +4080,95 @@ func (s *VtctldServer) RestoreFromBackup(req *vtctldatapb.RestoreFromBackupReque
 	}
 }
 
+// RestoreTopoFromBackups is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) RestoreTopoFromBackups(ctx context.Context, req *vtctldatapb.RestoreTopoFromBackupsRequest) (resp *vtctldatapb.RestoreTopoFromBackupsResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.RestoreTopoFromBackups")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("keyspace", req.Keyspace)
+	span.Annotate("shards", strings.Join(req.Shards, ","))
+	span.Annotate("dry_run", req.DryRun)
+
+	if req.Keyspace == "" || len(req.Shards) == 0 {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "a keyspace and at least one shard are required")
+	}
+
+	bs, err := backupstorage.GetBackupStorage()
+	if err != nil {
+		return nil, err
+	}
+	defer bs.Close()
+
+	// The records of the most recent backup of each shard that has some, and
+	// for the records shared by the shards, the most recent of them.
+	type backupRecord struct {
+		backup   string
+		captured string
+		*mysqlctl.BackupTopoRecord
+	}
+	latest := make(map[string]*backupRecord)
+	for _, shard := range req.Shards {
+		bucket := filepath.Join(req.Keyspace, shard)
+		bhs, err := bs.ListBackups(ctx, bucket)
+		if err != nil {
+			return nil, err
+		}
+		for i := len(bhs) - 1; i >= 0; i-- {
+			manifest, err := mysqlctl.GetBackupManifest(ctx, bhs[i])
+			if err != nil {
+				// Backups in progress or that failed have no MANIFEST.
+				log.Warningf("Skipping backup %v/%v: %v", bucket, bhs[i].Name(), err)
+				continue
+			}
+			if manifest.TopoRecords == nil {
+				continue
+			}
+			for _, record := range manifest.TopoRecords.Records {
+				if cur, ok := latest[record.Path]; ok && cur.captured >= manifest.TopoRecords.CapturedTime {
+					continue
+				}
+				latest[record.Path] = &backupRecord{
+					backup:           filepath.Join(bucket, bhs[i].Name()),
+					captured:         manifest.TopoRecords.CapturedTime,
+					BackupTopoRecord: record,
+				}
+			}
+			break
+		}
+	}
+	if len(latest) == 0 {
+		return nil, vterrors.Errorf(vtrpcpb.Code_NOT_FOUND, "no backup of %v/%v has topo records", req.Keyspace, strings.Join(req.Shards, ","))
+	}
+
+	paths := make([]string, 0, len(latest))
+	for p := range latest {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	records := &mysqlctl.BackupTopoRecords{}
+	for _, p := range paths {
+		records.Records = append(records.Records, latest[p].BackupTopoRecord)
+	}
+	statuses, err := mysqlctl.CheckBackupTopoRecords(ctx, s.ts, records, !req.DryRun)
+	if err != nil {
+		return nil, err
+	}
+
+	resp = &vtctldatapb.RestoreTopoFromBackupsResponse{}
+	for _, p := range paths {
+		record := latest[p]
+		resp.Records = append(resp.Records, &vtctldatapb.RestoreTopoFromBackupsResponse_Record{
+			Path:    record.Path,
+			Backup:  record.backup,
+			Version: record.Version,
+			Status:  string(statuses[p]),
+		})
+	}
+	return resp, nil
+}
+
 // RetrySchemaMigration is part of the vtctlservicepb.VtctldServer interface.
 func (s *VtctldServer) RetrySchemaMigration(ctx context.Context, req *vtctldatapb.RetrySchemaMigrationRequest) (resp *vtctldatapb.RetrySchemaMigrationResponse, err error) {
 	span, ctx := trace.NewSpan(ctx, "VtctldServer.RetrySchemaMigration")
@@ -3397,6 +4200,50 @@ func (s *VtctldServer) RetrySchemaMigration(ctx context.Context, req *vtctldatap
 	return resp, nil
 }
 
+// RollbackVSchema is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) RollbackVSchema(ctx context.Context, req *vtctldatapb.RollbackVSchemaRequest) (resp *vtctldatapb.RollbackVSchemaResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.RollbackVSchema")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("keyspace", req.Keyspace)
+	span.Annotate("version", req.Version)
+	span.Annotate("skip_rebuild", req.SkipRebuild)
+	span.Annotate("cells", strings.Join(req.Cells, ","))
+
+	entry, err := s.ts.GetVSchemaHistoryEntry(ctx, req.Keyspace, req.Version)
+	if err != nil {
+		if topo.IsErrType(err, topo.NoNode) {
+			err = vterrors.Errorf(vtrpcpb.Code_NOT_FOUND, "version %d is not in the vschema history of keyspace %s", req.Version, req.Keyspace)
+		}
+		return nil, err
+	}
+
+	// The vschema may not be valid anymore, if it refers to things that
+	// changed since.
+	if _, err = vindexes.BuildKeyspace(entry.Vschema, s.ws.SQLParser()); err != nil {
+		err = vterrors.Wrapf(err, "BuildKeyspace(%s)", req.Keyspace)
+		return nil, err
+	}
+
+	if err = s.ts.SaveVSchemaWithHistory(ctx, req.Keyspace, entry.Vschema); err != nil {
+		err = vterrors.Wrapf(err, "SaveVSchemaWithHistory(%s)", req.Keyspace)
+		return nil, err
+	}
+
+	if !req.SkipRebuild {
+		if err = s.ts.RebuildSrvVSchemaKeyspace(ctx, req.Keyspace, req.Cells); err != nil {
+			err = vterrors.Wrapf(err, "RebuildSrvVSchemaKeyspace")
+			return nil, err
+		}
+	}
+
+	return &vtctldatapb.RollbackVSchemaResponse{
+		VSchema: entry.Vschema,
+	}, nil
+}
+
 // RunHealthCheck is part of the vtctlservicepb.VtctldServer interface.
 func (s *VtctldServer) RunHealthCheck(ctx context.Context, req *vtctldatapb.RunHealthCheckRequest) (resp *vtctldatapb.RunHealthCheckResponse, err error) {
 	span, ctx := trace.NewSpan(ctx, "VtctldServer.RunHealthCheck")
@@ -3460,6 +4307,63 @@ func (s *VtctldServer) SetKeyspaceDurabilityPolicy(ctx context.Context, req *vtc
 	}, nil
 }
 
+// SetKeyspaceLabels is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) SetKeyspaceLabels(ctx context.Context, req *vtctldatapb.SetKeyspaceLabelsRequest) (resp *vtctldatapb.SetKeyspaceLabelsResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.SetKeyspaceLabels")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("keyspace", req.Keyspace)
+	span.Annotate("remove_labels", strings.Join(req.RemoveLabels, ","))
+
+	for key, value := range req.Labels {
+		if err = topoproto.ValidateLabel(key, value); err != nil {
+			err = vterrors.New(vtrpcpb.Code_INVALID_ARGUMENT, err.Error())
+			return nil, err
+		}
+		if slices.Contains(req.RemoveLabels, key) {
+			err = vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "label %v cannot be both set and removed", key)
+			return nil, err
+		}
+	}
+
+	ctx, unlock, lockErr := s.ts.LockKeyspace(ctx, req.Keyspace, "SetKeyspaceLabels")
+	if lockErr != nil {
+		err = lockErr
+		return nil, err
+	}
+
+	defer unlock(&err)
+
+	ki, err := s.ts.GetKeyspace(ctx, req.Keyspace)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, key := range req.RemoveLabels {
+		delete(ki.Labels, key)
+	}
+	for key, value := range req.Labels {
+		if ki.Labels == nil {
+			ki.Labels = make(map[string]string, len(req.Labels))
+		}
+		ki.Labels[key] = value
+	}
+	if len(ki.Labels) == 0 {
+		ki.Labels = nil
+	}
+
+	err = s.ts.UpdateKeyspace(ctx, ki)
+	if err != nil {
+		return nil, err
+	}
+
+	return &vtctldatapb.SetKeyspaceLabelsResponse{
+		Keyspace: ki.Keyspace,
+	}, nil
+}
+
 // SetShardIsPrimaryServing is part of the vtctlservicepb.VtctldServer interface.
 func (s *VtctldServer) SetShardIsPrimaryServing(ctx context.Context, req *vtctldatapb.SetShardIsPrimaryServingRequest) (resp *vtctldatapb.SetShardIsPrimaryServingResponse, err error) {
 	span, ctx := trace.NewSpan(ctx, "VtctldServer.SetShardIsPrimaryServing")
@@ -3492,6 +4396,27 @@ func (s *VtctldServer) SetShardIsPrimaryServing(ctx context.Context, req *vtctld
 	}, nil
 }
 
+// SetShardLabels is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) SetShardLabels(ctx context.Context, req *vtctldatapb.SetShardLabelsRequest) (resp *vtctldatapb.SetShardLabelsResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.SetShardLabels")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("keyspace", req.Keyspace)
+	span.Annotate("shard", req.Shard)
+	span.Annotate("remove_labels", strings.Join(req.RemoveLabels, ","))
+
+	si, err := s.ts.UpdateShardLabels(ctx, req.Keyspace, req.Shard, req.Labels, req.RemoveLabels, req.ExpectedLabels)
+	if err != nil {
+		return nil, err
+	}
+
+	return &vtctldatapb.SetShardLabelsResponse{
+		Shard: si.Shard,
+	}, nil
+}
+
 // SetShardTabletControl is part of the vtctlservicepb.VtctldServer interface.
 func (s *VtctldServer) SetShardTabletControl(ctx context.Context, req *vtctldatapb.SetShardTabletControlRequest) (resp *vtctldatapb.SetShardTabletControlResponse, err error) {
 	span, ctx := trace.NewSpan(ctx, "VtctldServer.SetShardTabletControl")
@@ -3513,6 +4438,14 @@ func (s *VtctldServer) SetShardTabletControl(ctx context.Context, req *vtctldata
 
 	defer unlock(&err)
 
+	ctx, unlockRouting, lockErr := s.ts.LockKeyspaceRouting(ctx, req.Keyspace, "SetShardTabletControl")
+	if lockErr != nil {
+		err = lockErr
+		return nil, err
+	}
+
+	defer unlockRouting(&err)
+
 	si, err := s.ts.UpdateShardFields(ctx, req.Keyspace, req.Shard, func(si *topo.ShardInfo) error {
 		return si.UpdateDeniedTables(ctx, req.TabletType, req.Cells, req.Remove, req.DeniedTables)
 	})
@@ -4005,6 +4938,49 @@ func (s *VtctldServer) StopReplication(ctx context.Context, req *vtctldatapb.Sto
 	return &vtctldatapb.StopReplicationResponse{}, nil
 }
 
+// SwapRoutingRules is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) SwapRoutingRules(ctx context.Context, req *vtctldatapb.SwapRoutingRulesRequest) (resp *vtctldatapb.SwapRoutingRulesResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.SwapRoutingRules")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("dry_run", req.DryRun)
+	span.Annotate("skip_rebuild", req.SkipRebuild)
+	span.Annotate("rebuild_cells", strings.Join(req.RebuildCells, ","))
+
+	if req.RoutingRules == nil && req.ShardRoutingRules == nil {
+		err = vterrors.New(vtrpcpb.Code_INVALID_ARGUMENT, "must pass at least one of req.RoutingRules and req.ShardRoutingRules")
+		return nil, err
+	}
+
+	rrChanges, srrChanges, err := topotools.SwapRoutingRules(ctx, s.ts, req.RoutingRules, req.ShardRoutingRules, req.DryRun)
+	if err != nil {
+		return nil, err
+	}
+
+	resp = &vtctldatapb.SwapRoutingRulesResponse{
+		RoutingRuleChanges:      rrChanges,
+		ShardRoutingRuleChanges: srrChanges,
+	}
+
+	if req.DryRun || (len(rrChanges) == 0 && len(srrChanges) == 0) {
+		return resp, nil
+	}
+
+	if req.SkipRebuild {
+		log.Warningf("Skipping rebuild of SrvVSchema, will need to run RebuildVSchemaGraph for changes to take effect")
+		return resp, nil
+	}
+
+	if err = s.ts.RebuildSrvVSchema(ctx, req.RebuildCells); err != nil {
+		err = vterrors.Wrapf(err, "RebuildSrvVSchema(%v) failed: %v", req.RebuildCells, err)
+		return nil, err
+	}
+
+	return resp, nil
+}
+
 // TabletExternallyReparented is part of the vtctldservicepb.VtctldServer interface.
 func (s *VtctldServer) TabletExternallyReparented(ctx context.Context, req *vtctldatapb.TabletExternallyReparentedRequest) (resp *vtctldatapb.TabletExternallyReparentedResponse, err error) {
 	span, ctx := trace.NewSpan(ctx, "VtctldServer.TabletExternallyReparented")
@@ -4139,10 +5115,28 @@ func (s *VtctldServer) UpdateCellsAlias(ctx context.Context, req *vtctldatapb.Up
 
 	span.Annotate("cells_alias", req.Name)
 	span.Annotate("cells_alias_cells", strings.Join(req.CellsAlias.Cells, ","))
+	span.Annotate("dry_run", req.DryRun)
 
 	ctx, cancel := context.WithTimeout(ctx, topo.RemoteOperationTimeout)
 	defer cancel()
 
+	if err = s.ts.ValidateCellsAlias(ctx, req.Name, req.CellsAlias); err != nil {
+		return nil, err
+	}
+
+	impact, err := s.ts.AnalyzeCellsAliasChange(ctx, req.Name, req.CellsAlias.Cells)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.DryRun {
+		return &vtctldatapb.UpdateCellsAliasResponse{
+			Name:       req.Name,
+			CellsAlias: req.CellsAlias.CloneVT(),
+			Impact:     cellsAliasImpactToProto(impact),
+		}, nil
+	}
+
 	var updatedCa *topodatapb.CellsAlias
 	err = s.ts.UpdateCellsAlias(ctx, req.Name, func(ca *topodatapb.CellsAlias) error {
 		defer func() { updatedCa = ca.CloneVT() }()
@@ -4158,6 +5152,7 @@ func (s *VtctldServer) UpdateCellsAlias(ctx context.Context, req *vtctldatapb.Up
 	return &vtctldatapb.UpdateCellsAliasResponse{
 		Name:       req.Name,
 		CellsAlias: updatedCa,
+		Impact:     cellsAliasImpactToProto(impact),
 	}, nil
 }
 
@@ -4168,7 +5163,12 @@ func (s *VtctldServer) Validate(ctx context.Context, req *vtctldatapb.ValidateRe
 
 	defer panicHandler(&err)
 
+	// The validation reports the state of the topo, so it tolerates stale
+	// reads.
+	ctx = topo.WithConsistency(ctx, topo.Relaxed)
+
 	span.Annotate("ping_tablets", req.PingTablets)
+	span.Annotate("cross_references", req.CrossReferences)
 
 	resp = &vtctldatapb.ValidateResponse{}
 	getKeyspacesCtx, getKeyspacesCancel := context.WithTimeout(ctx, topo.RemoteOperationTimeout)
@@ -4291,6 +5291,23 @@ func (s *VtctldServer) Validate(ctx context.Context, req *vtctldatapb.ValidateRe
 		}(keyspace)
 	}
 
+	if req.CrossReferences {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			issues, err := topotools.ValidateCrossReferences(ctx, s.ts)
+
+			m.Lock()
+			defer m.Unlock()
+
+			if err != nil {
+				resp.Results = append(resp.Results, fmt.Sprintf("topotools.ValidateCrossReferences failed: %v", err))
+				return
+			}
+			resp.Issues = issues
+		}()
+	}
+
 	wg.Wait()
 	return resp, err
 }
@@ -4302,6 +5319,10 @@ func (s *VtctldServer) ValidateKeyspace(ctx context.Context, req *vtctldatapb.Va
 
 	defer panicHandler(&err)
 
+	// The validation reports the state of the topo, so it tolerates stale
+	// reads.
+	ctx = topo.WithConsistency(ctx, topo.Relaxed)
+
 	span.Annotate("keyspace", req.Keyspace)
 	span.Annotate("ping_tablets", req.PingTablets)
 
@@ -4495,6 +5516,10 @@ func (s *VtctldServer) ValidateShard(ctx context.Context, req *vtctldatapb.Valid
 
 	defer panicHandler(&err)
 
+	// The validation reports the state of the topo, so it tolerates stale
+	// reads.
+	ctx = topo.WithConsistency(ctx, topo.Relaxed)
+
 	span.Annotate("keyspace", req.Keyspace)
 	span.Annotate("shard", req.Shard)
 	span.Annotate("ping_tablets", req.PingTablets)
@@ -4997,6 +6022,80 @@ func (s *VtctldServer) VDiffStop(ctx context.Context, req *vtctldatapb.VDiffStop
 	return resp, err
 }
 
+// WatchTopologyPath is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) WatchTopologyPath(req *vtctldatapb.WatchTopologyPathRequest, stream vtctlservicepb.Vtctld_WatchTopologyPathServer) (err error) {
+	span, ctx := trace.NewSpan(stream.Context(), "VtctldServer.WatchTopologyPath")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("cell", req.Cell)
+	span.Annotate("path", req.Path)
+	span.Annotate("include_initial", req.IncludeInitial)
+
+	if req.Cell == "" {
+		err = vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "cell is required")
+		return err
+	}
+
+	conn, err := s.ts.ConnForCell(ctx, req.Cell)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	current, changes, err := conn.WatchRecursive(ctx, strings.Trim(req.Path, "/"))
+	if err != nil {
+		return err
+	}
+	defer func() {
+		// The changes have to be drained once the watch is canceled.
+		cancel()
+		for range changes {
+		}
+	}()
+
+	send := func(wd *topo.WatchDataRecursive, initial bool) error {
+		resp := &vtctldatapb.WatchTopologyPathResponse{
+			Path:    wd.Path,
+			Time:    protoutil.TimeToProto(time.Now()),
+			Deleted: wd.Err != nil,
+			Initial: initial,
+		}
+		if wd.Err == nil {
+			resp.Version = wd.Version.String()
+			// Records that can't be decoded are sent as is.
+			resp.Data, _ = topo.DecodeContent(wd.Path, wd.Contents, topo.NewContentProto(wd.Path) != nil)
+		}
+		return stream.Send(resp)
+	}
+
+	if req.IncludeInitial {
+		for _, wd := range current {
+			if err = send(wd, true); err != nil {
+				return err
+			}
+		}
+	}
+
+	for wd := range changes {
+		switch {
+		case wd.Err == nil, topo.IsErrType(wd.Err, topo.NoNode):
+			if err = send(wd, false); err != nil {
+				return err
+			}
+		case ctx.Err() != nil:
+			// The client went away.
+			return nil
+		default:
+			err = wd.Err
+			return err
+		}
+	}
+	return nil
+}
+
 // WorkflowDelete is part of the vtctlservicepb.VtctldServer interface.
 func (s *VtctldServer) WorkflowDelete(ctx context.Context, req *vtctldatapb.WorkflowDeleteRequest) (resp *vtctldatapb.WorkflowDeleteResponse, err error) {
 	span, ctx := trace.NewSpan(ctx, "VtctldServer.WorkflowDelete")
@@ -5065,6 +6164,29 @@ func StartServer(s *grpc.Server, env *vtenv.Environment, ts *topo.Server) {
 	vtctlservicepb.RegisterVtctldServer(s, NewVtctldServer(env, ts))
 }
 
+// heldLockToProto converts a lock held on the topology to its proto.
+func heldLockToProto(lock *topo.HeldLock) *vtctldatapb.TopologyLock {
+	tl := &vtctldatapb.TopologyLock{
+		Path:     lock.Path,
+		Shared:   lock.Shared,
+		Contents: lock.Contents,
+		Stale:    lock.Stale,
+	}
+	if lock.TTL > 0 {
+		tl.Ttl = protoutil.DurationToProto(lock.TTL)
+	}
+	if lock.Lock != nil {
+		tl.Action = lock.Lock.Action
+		tl.HostName = lock.Lock.HostName
+		tl.UserName = lock.Lock.UserName
+		tl.Status = lock.Lock.Status
+		if t, err := time.Parse(time.RFC3339, lock.Lock.Time); err == nil {
+			tl.Time = protoutil.TimeToProto(t)
+		}
+	}
+	return tl
+}
+
 // getTopologyCell is a helper method that returns a topology cell given its path.
 func (s *VtctldServer) getTopologyCell(ctx context.Context, cellPath string, version int64, asJSON bool) (*vtctldatapb.TopologyCell, error) {
 	// extract cell and relative path
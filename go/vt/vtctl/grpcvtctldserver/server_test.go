@@ -18,6 +18,10 @@ package grpcvtctldserver
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -32,6 +36,8 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
 
 	"vitess.io/vitess/go/mysql"
 	"vitess.io/vitess/go/mysql/replication"
@@ -39,15 +45,18 @@ import (
 	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/test/utils"
 	hk "vitess.io/vitess/go/vt/hook"
+	"vitess.io/vitess/go/vt/mysqlctl"
 	"vitess.io/vitess/go/vt/mysqlctl/backupstorage"
 	"vitess.io/vitess/go/vt/proto/vttime"
 	"vitess.io/vitess/go/vt/topo"
 	"vitess.io/vitess/go/vt/topo/memorytopo"
 	"vitess.io/vitess/go/vt/topo/topoproto"
+	"vitess.io/vitess/go/vt/vtctl/approval"
 	"vitess.io/vitess/go/vt/vtctl/grpcvtctldserver/testutil"
 	"vitess.io/vitess/go/vt/vtctl/localvtctldclient"
 	"vitess.io/vitess/go/vt/vtctl/schematools"
 	"vitess.io/vitess/go/vt/vtenv"
+	"vitess.io/vitess/go/vt/vterrors"
 	"vitess.io/vitess/go/vt/vttablet/tmclient"
 	"vitess.io/vitess/go/vt/vttablet/tmclienttest"
 
@@ -60,6 +69,7 @@ import (
 	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
 	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
 	vtctlservicepb "vitess.io/vitess/go/vt/proto/vtctlservice"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
 )
 
 func init() {
@@ -206,6 +216,39 @@ func TestAddCellsAlias(t *testing.T) {
 			},
 			shouldErr: true,
 		},
+		{
+			name: "unknown cell",
+			ts:   memorytopo.NewServer(ctx, "zone1", "zone2"),
+			req: &vtctldatapb.AddCellsAliasRequest{
+				Name:  "zone",
+				Cells: []string{"zone1", "zone2", "zone3"},
+			},
+			shouldErr: true,
+		},
+		{
+			name: "dry run",
+			ts:   memorytopo.NewServer(ctx, "zone1", "zone2", "zone3"),
+			req: &vtctldatapb.AddCellsAliasRequest{
+				Name:   "zone",
+				Cells:  []string{"zone1", "zone2"},
+				DryRun: true,
+			},
+		},
+		{
+			name: "dry run alias exists",
+			ts:   memorytopo.NewServer(ctx, "zone1", "zone2", "zone3"),
+			setup: func(ts *topo.Server) error {
+				return ts.CreateCellsAlias(ctx, "zone", &topodatapb.CellsAlias{
+					Cells: []string{"zone1", "zone2"},
+				})
+			},
+			req: &vtctldatapb.AddCellsAliasRequest{
+				Name:   "zone",
+				Cells:  []string{"zone1", "zone2", "zone3"},
+				DryRun: true,
+			},
+			shouldErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -218,13 +261,20 @@ func TestAddCellsAlias(t *testing.T) {
 			vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, tt.ts, nil, func(ts *topo.Server) vtctlservicepb.VtctldServer {
 				return NewVtctldServer(vtenv.NewTestEnv(), ts)
 			})
-			_, err := vtctld.AddCellsAlias(ctx, tt.req)
+			resp, err := vtctld.AddCellsAlias(ctx, tt.req)
 			if tt.shouldErr {
 				assert.Error(t, err)
 				return
 			}
 
 			require.NoError(t, err)
+			utils.MustMatch(t, &vtctldatapb.CellsAliasImpact{AddedCells: tt.req.Cells}, resp.Impact)
+			if tt.req.DryRun {
+				_, err := tt.ts.GetCellsAlias(ctx, tt.req.Name, true)
+				assert.True(t, topo.IsErrType(err, topo.NoNode), "expected cell alias %s to not be created by a dry run", tt.req.Name)
+				return
+			}
+
 			ca, err := tt.ts.GetCellsAlias(ctx, tt.req.Name, true)
 			require.NoError(t, err, "failed to read new cells alias %s from topo", tt.req.Name)
 			utils.MustMatch(t, &topodatapb.CellsAlias{Cells: tt.req.Cells}, ca)
@@ -659,6 +709,8 @@ func TestApplyVSchema(t *testing.T) {
 			if tt.req.SkipRebuild || tt.req.DryRun {
 				utils.MustMatch(t, origSrvVSchema, finalSrvVSchema)
 			} else {
+				// Only the vschema of the keyspace is patched, the routing
+				// rules are left as they were.
 				changedSrvVSchema := &vschemapb.SrvVSchema{
 					Keyspaces: map[string]*vschemapb.Keyspace{
 						"testkeyspace": {
@@ -668,9 +720,6 @@ func TestApplyVSchema(t *testing.T) {
 					RoutingRules: &vschemapb.RoutingRules{
 						Rules: []*vschemapb.RoutingRule{},
 					},
-					ShardRoutingRules: &vschemapb.ShardRoutingRules{
-						Rules: []*vschemapb.ShardRoutingRule{},
-					},
 				}
 				utils.MustMatch(t, changedSrvVSchema, finalSrvVSchema)
 			}
@@ -678,6 +727,76 @@ func TestApplyVSchema(t *testing.T) {
 	}
 }
 
+func TestApplyVSchemaValidateAgainstSchema(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "zone1")
+	tmc := &testutil.TabletManagerClient{
+		GetSchemaResults: map[string]struct {
+			Schema *tabletmanagerdatapb.SchemaDefinition
+			Error  error
+		}{
+			"zone1-0000000100": {
+				Schema: &tabletmanagerdatapb.SchemaDefinition{
+					TableDefinitions: []*tabletmanagerdatapb.TableDefinition{
+						{Name: "t1", Columns: []string{"id", "c1"}},
+					},
+				},
+			},
+		},
+	}
+	testutil.AddKeyspace(ctx, t, ts, &vtctldatapb.Keyspace{
+		Name:     "testkeyspace",
+		Keyspace: &topodatapb.Keyspace{},
+	})
+	testutil.AddTablets(ctx, t, ts, &testutil.AddTabletOptions{
+		AlsoSetShardPrimary: true,
+	}, &topodatapb.Tablet{
+		Alias:    &topodatapb.TabletAlias{Cell: "zone1", Uid: 100},
+		Keyspace: "testkeyspace",
+		Shard:    "-",
+		Type:     topodatapb.TabletType_PRIMARY,
+	})
+	vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, tmc, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+		return NewVtctldServer(vtenv.NewTestEnv(), ts)
+	})
+
+	vschema := func(column string) *vschemapb.Keyspace {
+		return &vschemapb.Keyspace{
+			Sharded: true,
+			Vindexes: map[string]*vschemapb.Vindex{
+				"hash": {Type: "hash"},
+			},
+			Tables: map[string]*vschemapb.Table{
+				"t1": {
+					ColumnVindexes: []*vschemapb.ColumnVindex{{Name: "hash", Column: column}},
+				},
+			},
+		}
+	}
+
+	_, err := vtctld.ApplyVSchema(ctx, &vtctldatapb.ApplyVSchemaRequest{
+		Keyspace:              "testkeyspace",
+		VSchema:               vschema("missing"),
+		ValidateAgainstSchema: true,
+	})
+	assert.ErrorContains(t, err, "testkeyspace/-: column missing of vindex hash is not in table t1")
+	_, err = ts.GetVSchema(ctx, "testkeyspace")
+	assert.True(t, topo.IsErrType(err, topo.NoNode), "expected the vschema not to be saved, got %v", err)
+
+	_, err = vtctld.ApplyVSchema(ctx, &vtctldatapb.ApplyVSchemaRequest{
+		Keyspace:              "testkeyspace",
+		VSchema:               vschema("id"),
+		ValidateAgainstSchema: true,
+	})
+	require.NoError(t, err)
+	vs, err := ts.GetVSchema(ctx, "testkeyspace")
+	require.NoError(t, err)
+	utils.MustMatch(t, vschema("id"), vs)
+}
+
 func TestBackup(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -2905,6 +3024,43 @@ func TestCreateShard(t *testing.T) {
 	}
 }
 
+func TestDecommissionCell(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "zone1", "zone2")
+	require.NoError(t, ts.CreateKeyspace(ctx, "testkeyspace", &topodatapb.Keyspace{}))
+	require.NoError(t, ts.CreateCellsAlias(ctx, "all", &topodatapb.CellsAlias{Cells: []string{"zone1", "zone2"}}))
+	require.NoError(t, ts.UpdateSrvVSchema(ctx, "zone2", &vschemapb.SrvVSchema{}))
+	vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, nil, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+		return NewVtctldServer(vtenv.NewTestEnv(), ts)
+	})
+
+	expected := &vtctldatapb.DecommissionCellResponse{
+		CellsAliases: []string{"all"},
+		Files:        []string{"SrvVSchema"},
+		Steps: []string{
+			"remove cell zone2 from cells alias all",
+			"delete the 1 files of the cell-local topology: SrvVSchema",
+			"delete the CellInfo of cell zone2",
+		},
+	}
+	resp, err := vtctld.DecommissionCell(ctx, &vtctldatapb.DecommissionCellRequest{Cell: "zone2", DryRun: true})
+	require.NoError(t, err)
+	utils.MustMatch(t, expected, resp)
+	_, err = ts.GetCellInfo(ctx, "zone2", true)
+	require.NoError(t, err)
+
+	resp, err = vtctld.DecommissionCell(ctx, &vtctldatapb.DecommissionCellRequest{Cell: "zone2"})
+	require.NoError(t, err)
+	expected.Decommissioned = true
+	utils.MustMatch(t, expected, resp)
+	_, err = ts.GetCellInfo(ctx, "zone2", true)
+	assert.True(t, topo.IsErrType(err, topo.NoNode))
+
+	_, err = vtctld.DecommissionCell(ctx, &vtctldatapb.DecommissionCellRequest{Cell: "zone2"})
+	assert.ErrorContains(t, err, "cannot read the CellInfo of cell zone2")
+}
+
 func TestDeleteCellInfo(t *testing.T) {
 	t.Parallel()
 
@@ -2956,11 +3112,13 @@ func TestDeleteCellsAlias(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 	tests := []struct {
-		name      string
-		ts        *topo.Server
-		setup     func(ts *topo.Server) error
-		req       *vtctldatapb.DeleteCellsAliasRequest
-		shouldErr bool
+		name  string
+		ts    *topo.Server
+		setup func(ts *topo.Server) error
+		req   *vtctldatapb.DeleteCellsAliasRequest
+		// expectedImpact, if set, is checked against the response.
+		expectedImpact *vtctldatapb.CellsAliasImpact
+		shouldErr      bool
 	}{
 		{
 			ts: memorytopo.NewServer(ctx, "zone1", "zone2"),
@@ -2986,6 +3144,40 @@ func TestDeleteCellsAlias(t *testing.T) {
 			},
 			shouldErr: true,
 		},
+		{
+			name: "dry run",
+			ts:   memorytopo.NewServer(ctx, "zone1", "zone2"),
+			setup: func(ts *topo.Server) error {
+				if err := ts.UpdateSrvKeyspace(ctx, "zone2", "ks", &topodatapb.SrvKeyspace{
+					Partitions: []*topodatapb.SrvKeyspace_KeyspacePartition{{
+						ServedType:      topodatapb.TabletType_REPLICA,
+						ShardReferences: []*topodatapb.ShardReference{{Name: "-"}},
+					}},
+				}); err != nil {
+					return err
+				}
+				return ts.CreateCellsAlias(ctx, "zone", &topodatapb.CellsAlias{
+					Cells: []string{"zone1", "zone2"},
+				})
+			},
+			req: &vtctldatapb.DeleteCellsAliasRequest{
+				Name:   "zone",
+				DryRun: true,
+			},
+			expectedImpact: &vtctldatapb.CellsAliasImpact{
+				RemovedCells:      []string{"zone1", "zone2"},
+				AffectedKeyspaces: []string{"ks"},
+			},
+		},
+		{
+			name: "dry run alias does not exist",
+			ts:   memorytopo.NewServer(ctx, "zone1", "zone2"),
+			req: &vtctldatapb.DeleteCellsAliasRequest{
+				Name:   "zone",
+				DryRun: true,
+			},
+			shouldErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -2998,13 +3190,22 @@ func TestDeleteCellsAlias(t *testing.T) {
 			vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, tt.ts, nil, func(ts *topo.Server) vtctlservicepb.VtctldServer {
 				return NewVtctldServer(vtenv.NewTestEnv(), ts)
 			})
-			_, err := vtctld.DeleteCellsAlias(ctx, tt.req)
+			resp, err := vtctld.DeleteCellsAlias(ctx, tt.req)
 			if tt.shouldErr {
 				assert.Error(t, err)
 				return
 			}
 
 			require.NoError(t, err)
+			if tt.expectedImpact != nil {
+				utils.MustMatch(t, tt.expectedImpact, resp.Impact)
+			}
+			if tt.req.DryRun {
+				_, err := tt.ts.GetCellsAlias(ctx, tt.req.Name, true)
+				assert.NoError(t, err, "expected cell alias %s to still exist after a dry run", tt.req.Name)
+				return
+			}
+
 			ca, err := tt.ts.GetCellsAlias(ctx, tt.req.Name, true)
 			assert.True(t, topo.IsErrType(err, topo.NoNode), "expected cell alias %s to no longer exist; found %+v", tt.req.Name, ca)
 		})
@@ -3288,6 +3489,47 @@ func TestDeleteKeyspace(t *testing.T) {
 	}
 }
 
+// tlsCallerContext returns a context authenticated by a verified TLS client
+// certificate with the given common name.
+func tlsCallerContext(ctx context.Context, commonName string) context.Context {
+	cert := &x509.Certificate{Subject: pkix.Name{CommonName: commonName}}
+	return peer.NewContext(ctx, &peer.Peer{
+		AuthInfo: credentials.TLSInfo{
+			State: tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}},
+		},
+	})
+}
+
+func TestDeleteKeyspaceApproval(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "zone1")
+	require.NoError(t, ts.CreateKeyspace(ctx, "testkeyspace", &topodatapb.Keyspace{}))
+	vtctld := NewVtctldServer(vtenv.NewTestEnv(), ts)
+	vtctld.approvals = approval.NewGate(ts, map[string]time.Duration{approval.DeleteKeyspace: time.Hour}, time.Hour)
+
+	alice := tlsCallerContext(ctx, "alice")
+	bob := tlsCallerContext(ctx, "bob")
+	req := &vtctldatapb.DeleteKeyspaceRequest{Keyspace: "testkeyspace"}
+
+	_, err := vtctld.DeleteKeyspace(alice, req)
+	assert.ErrorContains(t, err, "DeleteKeyspace requires the approval of a second caller")
+	_, err = ts.GetKeyspace(ctx, "testkeyspace")
+	require.NoError(t, err)
+
+	approvals, err := vtctld.GetCommandApprovals(ctx, &vtctldatapb.GetCommandApprovalsRequest{})
+	require.NoError(t, err)
+	require.Len(t, approvals.Approvals, 1)
+	resp, err := vtctld.ApproveCommand(bob, &vtctldatapb.ApproveCommandRequest{Id: approvals.Approvals[0].Id})
+	require.NoError(t, err)
+	assert.Equal(t, "bob", resp.Approval.Approver)
+
+	_, err = vtctld.DeleteKeyspace(alice, req)
+	require.NoError(t, err)
+	_, err = ts.GetKeyspace(ctx, "testkeyspace")
+	assert.True(t, topo.IsErrType(err, topo.NoNode))
+}
+
 func TestDeleteShards(t *testing.T) {
 	t.Parallel()
 
@@ -5164,6 +5406,49 @@ func TestExecuteHook(t *testing.T) {
 	}
 }
 
+func TestExportImportTopology(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	fromTS := memorytopo.NewServer(ctx, "cell1")
+	toTS := memorytopo.NewServer(ctx, "cell1")
+	fromVtctld := testutil.NewVtctldServerWithTabletManagerClient(t, fromTS, nil, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+		return NewVtctldServer(vtenv.NewTestEnv(), ts)
+	})
+	toVtctld := testutil.NewVtctldServerWithTabletManagerClient(t, toTS, nil, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+		return NewVtctldServer(vtenv.NewTestEnv(), ts)
+	})
+
+	require.NoError(t, fromTS.CreateKeyspace(ctx, "prod", &topodatapb.Keyspace{DurabilityPolicy: "semi_sync"}))
+	require.NoError(t, fromTS.CreateShard(ctx, "prod", "0"))
+
+	_, err := fromVtctld.ExportTopology(ctx, &vtctldatapb.ExportTopologyRequest{})
+	assert.Error(t, err, "cells are required")
+
+	exported, err := fromVtctld.ExportTopology(ctx, &vtctldatapb.ExportTopologyRequest{
+		Cells: []string{topo.GlobalCell},
+		Path:  "/keyspaces/prod/",
+	})
+	require.NoError(t, err)
+	require.Len(t, exported.Files, 2)
+
+	resp, err := toVtctld.ImportTopology(ctx, &vtctldatapb.ImportTopologyRequest{
+		Files:        exported.Files,
+		PathMappings: map[string]string{"keyspaces/prod": "keyspaces/staging"},
+	})
+	require.NoError(t, err)
+	utils.MustMatch(t, &vtctldatapb.ImportTopologyResponse{
+		Created: []string{"global:keyspaces/staging/Keyspace", "global:keyspaces/staging/shards/0/Shard"},
+	}, resp)
+
+	ki, err := toTS.GetKeyspace(ctx, "staging")
+	require.NoError(t, err)
+	assert.Equal(t, "semi_sync", ki.DurabilityPolicy)
+	_, err = toTS.GetShard(ctx, "staging", "0")
+	require.NoError(t, err)
+}
+
 func TestFindAllShardsInKeyspace(t *testing.T) {
 	t.Parallel()
 
@@ -5208,6 +5493,140 @@ func TestFindAllShardsInKeyspace(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestForceUnlock(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts, factory := memorytopo.NewServerAndFactory(ctx, "zone1")
+	vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, nil, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+		return NewVtctldServer(vtenv.NewTestEnv(), ts)
+	})
+
+	testutil.AddKeyspace(ctx, t, ts, &vtctldatapb.Keyspace{Name: "ks1", Keyspace: &topodatapb.Keyspace{}})
+	testutil.AddShards(ctx, t, ts, &vtctldatapb.Shard{Keyspace: "ks1", Name: "-"})
+
+	_, err := vtctld.ForceUnlock(ctx, &vtctldatapb.ForceUnlockRequest{Path: "keyspaces/ks1/shards/-"})
+	assert.True(t, topo.IsErrType(err, topo.NoNode), "ForceUnlock(unlocked): %v", err)
+
+	// The holder of the lock goes away while it holds it.
+	holder, err := topo.NewWithFactory(factory, "" /*serverAddress*/, "" /*root*/)
+	require.NoError(t, err)
+	_, unlock, err := holder.LockShard(ctx, "ks1", "-", "reparent")
+	require.NoError(t, err)
+	holder.Close()
+
+	locks, err := vtctld.GetLocks(ctx, &vtctldatapb.GetLocksRequest{Keyspace: "ks1"})
+	require.NoError(t, err)
+	require.Len(t, locks.Locks, 1)
+	assert.True(t, locks.Locks[0].Stale)
+
+	// The lock is too recent to be broken with the default minimum age.
+	_, err = vtctld.ForceUnlock(ctx, &vtctldatapb.ForceUnlockRequest{Path: "keyspaces/ks1/shards/-"})
+	assert.ErrorContains(t, err, "refusing to break it")
+
+	resp, err := vtctld.ForceUnlock(ctx, &vtctldatapb.ForceUnlockRequest{
+		Path:   "/keyspaces/ks1/shards/-",
+		MinAge: protoutil.DurationToProto(0),
+		Reason: "stuck",
+		Actor:  "alice",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "keyspaces/ks1/shards/-", resp.Lock.Path)
+	assert.Equal(t, "reparent", resp.Lock.Action)
+
+	entries, err := ts.GetAuditLog(ctx)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "ForceUnlock", entries[0].Action)
+	assert.Equal(t, "keyspaces/ks1/shards/-", entries[0].Path)
+	assert.Equal(t, "alice", entries[0].Actor)
+	assert.Contains(t, entries[0].Details, "reason: stuck")
+
+	var unlockErr error
+	unlock(&unlockErr)
+	assert.Error(t, unlockErr)
+
+	_, err = vtctld.ForceUnlock(ctx, &vtctldatapb.ForceUnlockRequest{})
+	assert.Error(t, err)
+}
+
+// plainTopoFactory is a topo.Factory whose Conns, and their
+// LeaderParticipations, don't implement the optional interfaces.
+type plainTopoFactory struct {
+	topo.Factory
+}
+
+type plainTopoConn struct {
+	topo.Conn
+}
+
+func (f *plainTopoFactory) Create(cell, serverAddr, root string) (topo.Conn, error) {
+	conn, err := f.Factory.Create(cell, serverAddr, root)
+	if err != nil {
+		return nil, err
+	}
+	return &plainTopoConn{Conn: conn}, nil
+}
+
+func (c *plainTopoConn) NewLeaderParticipation(name, id string) (topo.LeaderParticipation, error) {
+	mp, err := c.Conn.NewLeaderParticipation(name, id)
+	if err != nil {
+		return nil, err
+	}
+	return struct{ topo.LeaderParticipation }{mp}, nil
+}
+
+func TestLocksNoImplementation(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	_, factory := memorytopo.NewServerAndFactory(ctx, "zone1")
+	ts, err := topo.NewWithFactory(&plainTopoFactory{Factory: factory}, "" /*serverAddress*/, "" /*root*/)
+	require.NoError(t, err)
+	defer ts.Close()
+	vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, nil, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+		return NewVtctldServer(vtenv.NewTestEnv(), ts)
+	})
+
+	testutil.AddKeyspace(ctx, t, ts, &vtctldatapb.Keyspace{Name: "ks1", Keyspace: &topodatapb.Keyspace{}})
+
+	_, err = vtctld.GetLocks(ctx, &vtctldatapb.GetLocksRequest{Keyspace: "ks1"})
+	assert.Equal(t, vtrpcpb.Code_UNIMPLEMENTED, vterrors.Code(err), "GetLocks: %v", err)
+
+	_, err = vtctld.ForceUnlock(ctx, &vtctldatapb.ForceUnlockRequest{Path: "keyspaces/ks1", MinAge: protoutil.DurationToProto(0)})
+	assert.Equal(t, vtrpcpb.Code_UNIMPLEMENTED, vterrors.Code(err), "ForceUnlock: %v", err)
+}
+
+func TestGetElections(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "zone1")
+	vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, nil, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+		return NewVtctldServer(vtenv.NewTestEnv(), ts)
+	})
+
+	resp, err := vtctld.GetElections(ctx, &vtctldatapb.GetElectionsRequest{})
+	require.NoError(t, err)
+	assert.Empty(t, resp.Elections)
+
+	mp, err := ts.NewLeaderParticipation("vtorc", "vtorc1:15000")
+	require.NoError(t, err)
+	_, err = mp.WaitForLeadership()
+	require.NoError(t, err)
+	defer mp.Stop()
+
+	resp, err = vtctld.GetElections(ctx, &vtctldatapb.GetElectionsRequest{})
+	require.NoError(t, err)
+	require.Len(t, resp.Elections, 1)
+	assert.Equal(t, "vtorc", resp.Elections[0].Name)
+	assert.Equal(t, "vtorc1:15000", resp.Elections[0].LeaderId)
+	assert.NotNil(t, resp.Elections[0].LeaderSince)
+}
+
 func TestGetBackups(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -5575,6 +5994,64 @@ func TestGetKeyspaces(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestGetLocks(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "zone1")
+	vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, nil, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+		return NewVtctldServer(vtenv.NewTestEnv(), ts)
+	})
+
+	testutil.AddKeyspace(ctx, t, ts, &vtctldatapb.Keyspace{Name: "ks1", Keyspace: &topodatapb.Keyspace{}})
+	testutil.AddKeyspace(ctx, t, ts, &vtctldatapb.Keyspace{Name: "ks2", Keyspace: &topodatapb.Keyspace{}})
+	testutil.AddShards(ctx, t, ts, &vtctldatapb.Shard{Keyspace: "ks1", Name: "-"})
+
+	_, unlockShard, err := ts.LockShard(ctx, "ks1", "-", "reparent")
+	require.NoError(t, err)
+	defer unlockShard(&err)
+	_, unlockKeyspace, err := ts.LockKeyspaceShared(ctx, "ks2", "validate")
+	require.NoError(t, err)
+	defer unlockKeyspace(&err)
+	_, unlockName, err := ts.LockName(ctx, "wf1", "vdiff")
+	require.NoError(t, err)
+	defer unlockName(&err)
+
+	resp, err := vtctld.GetLocks(ctx, &vtctldatapb.GetLocksRequest{})
+	require.NoError(t, err)
+	require.Len(t, resp.Locks, 3)
+	assert.Equal(t, "named_locks/wf1", resp.Locks[0].Path)
+	assert.Equal(t, "vdiff", resp.Locks[0].Action)
+	assert.Equal(t, "keyspaces/ks1/shards/-", resp.Locks[1].Path)
+	assert.False(t, resp.Locks[1].Shared)
+	assert.Equal(t, "reparent", resp.Locks[1].Action)
+	assert.NotNil(t, resp.Locks[1].Time)
+	assert.Equal(t, "keyspaces/ks2", resp.Locks[2].Path)
+	assert.True(t, resp.Locks[2].Shared)
+	assert.Equal(t, "validate", resp.Locks[2].Action)
+	assert.False(t, resp.Locks[2].Stale)
+
+	resp, err = vtctld.GetLocks(ctx, &vtctldatapb.GetLocksRequest{Keyspace: "ks2"})
+	require.NoError(t, err)
+	require.Len(t, resp.Locks, 1)
+	assert.Equal(t, "keyspaces/ks2", resp.Locks[0].Path)
+
+	resp, err = vtctld.GetLocks(ctx, &vtctldatapb.GetLocksRequest{Keyspace: "ks1", Shard: "-"})
+	require.NoError(t, err)
+	require.Len(t, resp.Locks, 1)
+	assert.Equal(t, "reparent", resp.Locks[0].Action)
+
+	resp, err = vtctld.GetLocks(ctx, &vtctldatapb.GetLocksRequest{Path: "/routing_rules"})
+	require.NoError(t, err)
+	assert.Empty(t, resp.Locks)
+
+	_, err = vtctld.GetLocks(ctx, &vtctldatapb.GetLocksRequest{Shard: "-"})
+	assert.Error(t, err)
+	_, err = vtctld.GetLocks(ctx, &vtctldatapb.GetLocksRequest{Keyspace: "ks1", Path: "routing_rules"})
+	assert.Error(t, err)
+}
+
 func TestGetPermissions(t *testing.T) {
 	t.Parallel()
 
@@ -7937,15 +8414,86 @@ func TestGetVSchema(t *testing.T) {
 	})
 }
 
-func TestLaunchSchemaMigration(t *testing.T) {
+func TestVSchemaHistory(t *testing.T) {
 	t.Parallel()
 
-	tests := []struct {
-		name      string
-		tablets   []*topodatapb.Tablet
-		tmc       *testutil.TabletManagerClient
-		req       *vtctldatapb.LaunchSchemaMigrationRequest
-		expected  *vtctldatapb.LaunchSchemaMigrationResponse
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "zone1")
+	vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, nil, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+		return NewVtctldServer(vtenv.NewTestEnv(), ts)
+	})
+
+	testutil.AddKeyspace(ctx, t, ts, &vtctldatapb.Keyspace{
+		Name:     "testkeyspace",
+		Keyspace: &topodatapb.Keyspace{},
+	})
+	require.NoError(t, ts.RebuildSrvVSchema(ctx, nil))
+
+	v1 := &vschemapb.Keyspace{
+		Sharded: true,
+		Vindexes: map[string]*vschemapb.Vindex{
+			"v1": {
+				Type: "hash",
+			},
+		},
+	}
+	v2 := &vschemapb.Keyspace{}
+	for _, vs := range []*vschemapb.Keyspace{v1, v2} {
+		_, err := vtctld.ApplyVSchema(ctx, &vtctldatapb.ApplyVSchemaRequest{
+			Keyspace: "testkeyspace",
+			VSchema:  vs,
+		})
+		require.NoError(t, err)
+	}
+
+	// The keyspace had no vschema before v1, so only v1 was replaced.
+	resp, err := vtctld.GetVSchemaHistory(ctx, &vtctldatapb.GetVSchemaHistoryRequest{
+		Keyspace: "testkeyspace",
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.Entries, 1)
+	assert.EqualValues(t, 1, resp.Entries[0].Version)
+	utils.MustMatch(t, v1, resp.Entries[0].Vschema)
+
+	rollbackResp, err := vtctld.RollbackVSchema(ctx, &vtctldatapb.RollbackVSchemaRequest{
+		Keyspace: "testkeyspace",
+		Version:  1,
+	})
+	require.NoError(t, err)
+	utils.MustMatch(t, v1, rollbackResp.VSchema)
+
+	vs, err := ts.GetVSchema(ctx, "testkeyspace")
+	require.NoError(t, err)
+	utils.MustMatch(t, v1, vs)
+	srvVSchema, err := ts.GetSrvVSchema(ctx, "zone1")
+	require.NoError(t, err)
+	utils.MustMatch(t, v1, srvVSchema.Keyspaces["testkeyspace"])
+
+	// The rollback is itself undoable.
+	resp, err = vtctld.GetVSchemaHistory(ctx, &vtctldatapb.GetVSchemaHistoryRequest{
+		Keyspace: "testkeyspace",
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.Entries, 2)
+	utils.MustMatch(t, v2, resp.Entries[0].Vschema)
+
+	_, err = vtctld.RollbackVSchema(ctx, &vtctldatapb.RollbackVSchemaRequest{
+		Keyspace: "testkeyspace",
+		Version:  42,
+	})
+	assert.ErrorContains(t, err, "version 42 is not in the vschema history")
+}
+
+func TestLaunchSchemaMigration(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		tablets   []*topodatapb.Tablet
+		tmc       *testutil.TabletManagerClient
+		req       *vtctldatapb.LaunchSchemaMigrationRequest
+		expected  *vtctldatapb.LaunchSchemaMigrationResponse
 		shouldErr bool
 	}{
 		{
@@ -8141,6 +8689,75 @@ func TestLaunchSchemaMigration(t *testing.T) {
 	}
 }
 
+func TestListTopologyPath(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "cell1", "cell2", "cell3")
+	vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, nil, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+		return NewVtctldServer(vtenv.NewTestEnv(), ts)
+	})
+
+	require.NoError(t, ts.CreateKeyspace(ctx, "keyspace1", &topodatapb.Keyspace{DurabilityPolicy: "none"}))
+	require.NoError(t, ts.CreateShard(ctx, "keyspace1", "-"))
+
+	// The cells are listed one page at a time, sorted by name.
+	resp, err := vtctld.ListTopologyPath(ctx, &vtctldatapb.ListTopologyPathRequest{
+		Path:     "/",
+		PageSize: 2,
+	})
+	require.NoError(t, err)
+	utils.MustMatch(t, &vtctldatapb.ListTopologyPathResponse{
+		Entries: []*vtctldatapb.TopologyEntry{
+			{Name: "cell1", Path: "/cell1", IsDirectory: true},
+			{Name: "cell2", Path: "/cell2", IsDirectory: true},
+		},
+		NextPageToken: "cell2",
+	}, resp)
+
+	resp, err = vtctld.ListTopologyPath(ctx, &vtctldatapb.ListTopologyPathRequest{
+		Path:      "/",
+		PageSize:  2,
+		PageToken: resp.NextPageToken,
+	})
+	require.NoError(t, err)
+	utils.MustMatch(t, &vtctldatapb.ListTopologyPathResponse{
+		Entries: []*vtctldatapb.TopologyEntry{
+			{Name: "cell3", Path: "/cell3", IsDirectory: true},
+			{Name: "global", Path: "/global", IsDirectory: true},
+		},
+	}, resp)
+
+	// Files come with their version, size and decoded contents.
+	resp, err = vtctld.ListTopologyPath(ctx, &vtctldatapb.ListTopologyPathRequest{
+		Path:        "/global/keyspaces/keyspace1/",
+		IncludeData: true,
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.Entries, 2)
+	assert.Empty(t, resp.NextPageToken)
+	keyspace := resp.Entries[0]
+	assert.Equal(t, "Keyspace", keyspace.Name)
+	assert.Equal(t, "/global/keyspaces/keyspace1/Keyspace", keyspace.Path)
+	assert.False(t, keyspace.IsDirectory)
+	assert.NotZero(t, keyspace.Version)
+	assert.NotZero(t, keyspace.Size)
+	assert.Contains(t, keyspace.Data, `"durability_policy": "none"`)
+	utils.MustMatch(t, &vtctldatapb.TopologyEntry{
+		Name:        "shards",
+		Path:        "/global/keyspaces/keyspace1/shards",
+		IsDirectory: true,
+	}, resp.Entries[1])
+
+	_, err = vtctld.ListTopologyPath(ctx, &vtctldatapb.ListTopologyPathRequest{Path: "/global/nonexistent"})
+	assert.True(t, topo.IsErrType(err, topo.NoNode), "expected NoNode, got %v", err)
+	_, err = vtctld.ListTopologyPath(ctx, &vtctldatapb.ListTopologyPathRequest{Path: "global"})
+	assert.Error(t, err)
+	_, err = vtctld.ListTopologyPath(ctx, &vtctldatapb.ListTopologyPathRequest{Path: "/", PageSize: -1})
+	assert.Error(t, err)
+}
+
 func TestPingTablet(t *testing.T) {
 	t.Parallel()
 
@@ -8229,6 +8846,41 @@ func TestPingTablet(t *testing.T) {
 	}
 }
 
+func TestPingTopoWatch(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "zone1")
+	testutil.AddTablet(ctx, t, ts, &topodatapb.Tablet{
+		Alias:    &topodatapb.TabletAlias{Cell: "zone1", Uid: 100},
+		Hostname: "localhost",
+		PortMap:  map[string]int32{"vt": 1},
+	}, nil)
+	vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, nil, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+		return NewVtctldServer(vtenv.NewTestEnv(), ts)
+	})
+
+	resp, err := vtctld.PingTopoWatch(ctx, &vtctldatapb.PingTopoWatchRequest{
+		Cell:       "zone1",
+		Components: []string{"zone1-0000000100"},
+		Threshold:  protoutil.DurationToProto(time.Second),
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.Results, 2)
+	assert.Equal(t, "vtctld", resp.Results[0].Component)
+	assert.Empty(t, resp.Results[0].Error)
+	assert.NotNil(t, resp.Results[0].Latency)
+	// Nothing serves the watch-echo endpoint of the tablet.
+	assert.Equal(t, "zone1-0000000100", resp.Results[1].Component)
+	assert.NotEmpty(t, resp.Results[1].Error)
+
+	_, err = vtctld.PingTopoWatch(ctx, &vtctldatapb.PingTopoWatchRequest{})
+	assert.ErrorContains(t, err, "cell must be specified")
+	_, err = vtctld.PingTopoWatch(ctx, &vtctldatapb.PingTopoWatchRequest{Cell: "zone1", Components: []string{"zone1-0000000200"}})
+	assert.True(t, topo.IsErrType(err, topo.NoNode), "expected NoNode, got %v", err)
+}
+
 func TestPlannedReparentShard(t *testing.T) {
 	t.Parallel()
 
@@ -8565,8 +9217,8 @@ func TestRebuildKeyspaceGraph(t *testing.T) {
 			return NewVtctldServer(vtenv.NewTestEnv(), ts)
 		})
 
-		lctx, unlock, lerr := ts.LockKeyspace(context.Background(), "testkeyspace", "test lock")
-		require.NoError(t, lerr, "could not lock keyspace for testing")
+		lctx, unlock, lerr := ts.LockKeyspaceRouting(context.Background(), "testkeyspace", "test lock")
+		require.NoError(t, lerr, "could not lock the routing of keyspace for testing")
 
 		defer unlock(&lerr)
 		defer func() { require.NoError(t, lerr, "could not unlock testkeyspace after test") }()
@@ -9491,8 +10143,8 @@ func TestRemoveKeyspaceCell(t *testing.T) {
 			}
 
 			if tt.topoIsLocked {
-				lctx, unlock, err := ts.LockKeyspace(ctx, tt.req.Keyspace, "testing locked keyspace")
-				require.NoError(t, err, "cannot lock keyspace %s", tt.req.Keyspace)
+				lctx, unlock, err := ts.LockKeyspaceRouting(ctx, tt.req.Keyspace, "testing locked keyspace")
+				require.NoError(t, err, "cannot lock the routing of keyspace %s", tt.req.Keyspace)
 				defer unlock(&err)
 
 				ctx = lctx
@@ -9789,8 +10441,8 @@ func TestRemoveShardCell(t *testing.T) {
 			}
 
 			if tt.topoIsLocked {
-				lctx, unlock, err := ts.LockKeyspace(ctx, tt.req.Keyspace, "testing locked keyspace")
-				require.NoError(t, err, "cannot lock keyspace %s", tt.req.Keyspace)
+				lctx, unlock, err := ts.LockKeyspaceRouting(ctx, tt.req.Keyspace, "testing locked keyspace")
+				require.NoError(t, err, "cannot lock the routing of keyspace %s", tt.req.Keyspace)
 				defer unlock(&err)
 
 				// Need to use the lock ctx in the RPC call so we fail when
@@ -10378,6 +11030,75 @@ func TestReparentTablet(t *testing.T) {
 	}
 }
 
+func TestResignLeader(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "zone1")
+	vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, nil, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+		return NewVtctldServer(vtenv.NewTestEnv(), ts)
+	})
+
+	_, err := vtctld.ResignLeader(ctx, &vtctldatapb.ResignLeaderRequest{Name: "vtctld"})
+	assert.True(t, topo.IsErrType(err, topo.NoNode), "ResignLeader(no primary): %v", err)
+
+	conn, err := ts.ConnForCell(ctx, topo.GlobalCell)
+	require.NoError(t, err)
+	mp, err := conn.NewLeaderParticipation("vtctld", "host1:15999")
+	require.NoError(t, err)
+	leaderCtx, err := mp.WaitForLeadership()
+	require.NoError(t, err)
+
+	// Another primary than the expected one is left alone.
+	_, err = vtctld.ResignLeader(ctx, &vtctldatapb.ResignLeaderRequest{Name: "vtctld", Id: "host2:15999"})
+	assert.True(t, topo.IsErrType(err, topo.BadVersion), "ResignLeader(other id): %v", err)
+	require.NoError(t, leaderCtx.Err())
+
+	resp, err := vtctld.ResignLeader(ctx, &vtctldatapb.ResignLeaderRequest{
+		Name:   "vtctld",
+		Reason: "maintenance",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "host1:15999", resp.LeaderId)
+	select {
+	case <-leaderCtx.Done():
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "the primary was not forced to resign")
+	}
+
+	entries, err := ts.GetAuditLog(ctx)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "ResignLeader", entries[0].Action)
+	assert.Equal(t, "elections/vtctld", entries[0].Path)
+	assert.Contains(t, entries[0].Details, "reason: maintenance")
+
+	_, err = vtctld.ResignLeader(ctx, &vtctldatapb.ResignLeaderRequest{})
+	assert.Error(t, err)
+}
+
+func TestResignLeaderNoImplementation(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	_, factory := memorytopo.NewServerAndFactory(ctx, "zone1")
+	ts, err := topo.NewWithFactory(&plainTopoFactory{Factory: factory}, "" /*serverAddress*/, "" /*root*/)
+	require.NoError(t, err)
+	defer ts.Close()
+	vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, nil, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+		return NewVtctldServer(vtenv.NewTestEnv(), ts)
+	})
+
+	_, err = vtctld.ResignLeader(ctx, &vtctldatapb.ResignLeaderRequest{Name: "vtctld", Id: "host1:15999"})
+	assert.Equal(t, vtrpcpb.Code_UNIMPLEMENTED, vterrors.Code(err), "ResignLeader: %v", err)
+
+	entries, err := ts.GetAuditLog(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
 func TestRestoreFromBackup(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -10518,6 +11239,83 @@ func TestRestoreFromBackup(t *testing.T) {
 	}
 }
 
+func TestRestoreTopoFromBackups(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// The backups were taken with a topo that was lost since.
+	oldTS := memorytopo.NewServer(ctx, "zone1")
+	require.NoError(t, oldTS.CreateKeyspace(ctx, "testkeyspace", &topodatapb.Keyspace{DurabilityPolicy: "semi_sync"}))
+	require.NoError(t, oldTS.SaveVSchema(ctx, "testkeyspace", &vschemapb.Keyspace{Sharded: true}))
+	manifests := map[string][]byte{}
+	for _, shard := range []string{"-80", "80-"} {
+		require.NoError(t, oldTS.CreateShard(ctx, "testkeyspace", shard))
+		records, err := mysqlctl.GetBackupTopoRecords(ctx, oldTS, "testkeyspace", shard)
+		require.NoError(t, err)
+		data, err := json.Marshal(&mysqlctl.BackupManifest{TopoRecords: records})
+		require.NoError(t, err)
+		manifests["testkeyspace/"+shard+"/backup2"] = data
+	}
+	// backup1 has no topo records, and backup3 is in progress.
+	manifests["testkeyspace/80-/backup1"] = []byte("{}")
+	testutil.BackupStorage.Backups = map[string][]string{
+		"testkeyspace/-80": {"backup2"},
+		"testkeyspace/80-": {"backup1", "backup2", "backup3"},
+	}
+	testutil.BackupStorage.Manifests = manifests
+	defer func() { testutil.BackupStorage.Manifests = nil }()
+
+	ts := memorytopo.NewServer(ctx, "zone1")
+	require.NoError(t, ts.CreateKeyspace(ctx, "testkeyspace", &topodatapb.Keyspace{}))
+	vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, nil, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+		return NewVtctldServer(vtenv.NewTestEnv(), ts)
+	})
+
+	statuses := func(resp *vtctldatapb.RestoreTopoFromBackupsResponse) map[string]string {
+		m := map[string]string{}
+		for _, record := range resp.Records {
+			m[record.Path] = record.Status
+		}
+		return m
+	}
+
+	resp, err := vtctld.RestoreTopoFromBackups(ctx, &vtctldatapb.RestoreTopoFromBackupsRequest{
+		Keyspace: "testkeyspace",
+		Shards:   []string{"-80", "80-"},
+		DryRun:   true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"keyspaces/testkeyspace/Keyspace":         "differs",
+		"keyspaces/testkeyspace/VSchema":          "missing",
+		"keyspaces/testkeyspace/shards/-80/Shard": "missing",
+		"keyspaces/testkeyspace/shards/80-/Shard": "missing",
+	}, statuses(resp))
+	_, err = ts.GetShard(ctx, "testkeyspace", "-80")
+	assert.True(t, topo.IsErrType(err, topo.NoNode))
+
+	resp, err = vtctld.RestoreTopoFromBackups(ctx, &vtctldatapb.RestoreTopoFromBackupsRequest{
+		Keyspace: "testkeyspace",
+		Shards:   []string{"-80", "80-"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"keyspaces/testkeyspace/Keyspace":         "differs",
+		"keyspaces/testkeyspace/VSchema":          "created",
+		"keyspaces/testkeyspace/shards/-80/Shard": "created",
+		"keyspaces/testkeyspace/shards/80-/Shard": "created",
+	}, statuses(resp))
+	shards, err := ts.GetShardNames(ctx, "testkeyspace")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"-80", "80-"}, shards)
+
+	_, err = vtctld.RestoreTopoFromBackups(ctx, &vtctldatapb.RestoreTopoFromBackupsRequest{
+		Keyspace: "testkeyspace",
+		Shards:   []string{"-"},
+	})
+	assert.ErrorContains(t, err, "no backup of testkeyspace/- has topo records")
+}
+
 func TestRetrySchemaMigration(t *testing.T) {
 	t.Parallel()
 
@@ -10904,6 +11702,92 @@ func TestSetKeyspaceDurabilityPolicy(t *testing.T) {
 	}
 }
 
+func TestSetKeyspaceLabels(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "zone1")
+	testutil.AddKeyspaces(ctx, t, ts,
+		&vtctldatapb.Keyspace{Name: "ks1", Keyspace: &topodatapb.Keyspace{Labels: map[string]string{"owner": "payments", "tier": "2"}}},
+		&vtctldatapb.Keyspace{Name: "ks2", Keyspace: &topodatapb.Keyspace{Labels: map[string]string{"owner": "search"}}},
+	)
+	vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, nil, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+		return NewVtctldServer(vtenv.NewTestEnv(), ts)
+	})
+
+	resp, err := vtctld.SetKeyspaceLabels(ctx, &vtctldatapb.SetKeyspaceLabelsRequest{
+		Keyspace:     "ks1",
+		Labels:       map[string]string{"tier": "1", "cost-center": "cc42"},
+		RemoveLabels: []string{"owner"},
+	})
+	require.NoError(t, err)
+	utils.MustMatch(t, map[string]string{"tier": "1", "cost-center": "cc42"}, resp.Keyspace.Labels)
+
+	labels, err := vtctld.GetKeyspaceLabels(ctx, &vtctldatapb.GetKeyspaceLabelsRequest{Keyspace: "ks1"})
+	require.NoError(t, err)
+	utils.MustMatch(t, map[string]string{"tier": "1", "cost-center": "cc42"}, labels.Labels)
+
+	keyspaces, err := vtctld.GetKeyspaces(ctx, &vtctldatapb.GetKeyspacesRequest{Labels: map[string]string{"owner": "search"}})
+	require.NoError(t, err)
+	require.Len(t, keyspaces.Keyspaces, 1)
+	assert.Equal(t, "ks2", keyspaces.Keyspaces[0].Name)
+
+	_, err = vtctld.SetKeyspaceLabels(ctx, &vtctldatapb.SetKeyspaceLabelsRequest{Keyspace: "ks1", Labels: map[string]string{"bad key": "x"}})
+	assert.ErrorContains(t, err, `invalid label key "bad key"`)
+	_, err = vtctld.SetKeyspaceLabels(ctx, &vtctldatapb.SetKeyspaceLabelsRequest{Keyspace: "ks1", Labels: map[string]string{"tier": "3"}, RemoveLabels: []string{"tier"}})
+	assert.ErrorContains(t, err, "label tier cannot be both set and removed")
+}
+
+func TestSetShardLabels(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "zone1")
+	testutil.AddShards(ctx, t, ts, &vtctldatapb.Shard{
+		Keyspace: "testkeyspace",
+		Name:     "-",
+		Shard:    &topodatapb.Shard{Labels: map[string]string{"owner": "payments"}},
+	})
+	vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, nil, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+		return NewVtctldServer(vtenv.NewTestEnv(), ts)
+	})
+
+	resp, err := vtctld.SetShardLabels(ctx, &vtctldatapb.SetShardLabelsRequest{
+		Keyspace:       "testkeyspace",
+		Shard:          "-",
+		Labels:         map[string]string{"state": "frozen"},
+		RemoveLabels:   []string{"owner"},
+		ExpectedLabels: map[string]string{"owner": "payments", "state": ""},
+	})
+	require.NoError(t, err)
+	utils.MustMatch(t, map[string]string{"state": "frozen"}, resp.Shard.Labels)
+
+	labels, err := vtctld.GetShardLabels(ctx, &vtctldatapb.GetShardLabelsRequest{Keyspace: "testkeyspace", Shard: "-"})
+	require.NoError(t, err)
+	utils.MustMatch(t, map[string]string{"state": "frozen"}, labels.Labels)
+
+	// Another change that expects the shard not to be frozen fails, and
+	// leaves the labels alone.
+	_, err = vtctld.SetShardLabels(ctx, &vtctldatapb.SetShardLabelsRequest{
+		Keyspace:       "testkeyspace",
+		Shard:          "-",
+		Labels:         map[string]string{"state": "pending-cleanup"},
+		ExpectedLabels: map[string]string{"state": ""},
+	})
+	assert.ErrorContains(t, err, `label state of shard testkeyspace/- is "frozen", expected ""`)
+
+	_, err = vtctld.SetShardLabels(ctx, &vtctldatapb.SetShardLabelsRequest{Keyspace: "testkeyspace", Shard: "-", RemoveLabels: []string{"state"}})
+	require.NoError(t, err)
+	labels, err = vtctld.GetShardLabels(ctx, &vtctldatapb.GetShardLabelsRequest{Keyspace: "testkeyspace", Shard: "-"})
+	require.NoError(t, err)
+	assert.Empty(t, labels.Labels)
+
+	_, err = vtctld.SetShardLabels(ctx, &vtctldatapb.SetShardLabelsRequest{Keyspace: "testkeyspace", Shard: "-", Labels: map[string]string{"bad key": "x"}})
+	assert.ErrorContains(t, err, `invalid label key "bad key"`)
+}
+
 func TestSetShardIsPrimaryServing(t *testing.T) {
 	t.Parallel()
 
@@ -12421,6 +13305,53 @@ func TestStopReplication(t *testing.T) {
 	}
 }
 
+func TestSwapRoutingRules(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "zone1")
+	vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, nil, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+		return NewVtctldServer(vtenv.NewTestEnv(), ts)
+	})
+
+	_, err := vtctld.SwapRoutingRules(ctx, &vtctldatapb.SwapRoutingRulesRequest{})
+	assert.Error(t, err)
+
+	require.NoError(t, ts.SaveRoutingRules(ctx, &vschemapb.RoutingRules{
+		Rules: []*vschemapb.RoutingRule{{FromTable: "t1", ToTables: []string{"ks1.t1"}}},
+	}))
+	require.NoError(t, ts.RebuildSrvVSchema(ctx, nil))
+
+	rr := &vschemapb.RoutingRules{
+		Rules: []*vschemapb.RoutingRule{{FromTable: "t1", ToTables: []string{"ks2.t1"}}},
+	}
+	want := &vtctldatapb.SwapRoutingRulesResponse{
+		RoutingRuleChanges: []*vtctldatapb.RoutingRuleChange{
+			{FromTable: "t1", OldToTables: []string{"ks1.t1"}, NewToTables: []string{"ks2.t1"}},
+		},
+	}
+
+	resp, err := vtctld.SwapRoutingRules(ctx, &vtctldatapb.SwapRoutingRulesRequest{
+		RoutingRules: rr,
+		DryRun:       true,
+	})
+	require.NoError(t, err)
+	utils.MustMatch(t, want, resp)
+	srvVSchema, err := ts.GetSrvVSchema(ctx, "zone1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ks1.t1"}, srvVSchema.RoutingRules.Rules[0].ToTables)
+
+	resp, err = vtctld.SwapRoutingRules(ctx, &vtctldatapb.SwapRoutingRulesRequest{
+		RoutingRules: rr,
+	})
+	require.NoError(t, err)
+	utils.MustMatch(t, want, resp)
+	srvVSchema, err = ts.GetSrvVSchema(ctx, "zone1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ks2.t1"}, srvVSchema.RoutingRules.Rules[0].ToTables)
+}
+
 func TestTabletExternallyReparented(t *testing.T) {
 	t.Parallel()
 
@@ -12994,12 +13925,14 @@ func TestUpdateCellsAlias(t *testing.T) {
 	t.Parallel()
 
 	tests := []struct {
-		name      string
-		cells     []string
-		aliases   map[string][]string
-		req       *vtctldatapb.UpdateCellsAliasRequest
-		expected  *vtctldatapb.UpdateCellsAliasResponse
-		shouldErr bool
+		name    string
+		cells   []string
+		aliases map[string][]string
+		// srvKeyspaces maps cells to the keyspaces served in them.
+		srvKeyspaces map[string][]string
+		req          *vtctldatapb.UpdateCellsAliasRequest
+		expected     *vtctldatapb.UpdateCellsAliasResponse
+		shouldErr    bool
 	}{
 		{
 			name: "remove one cell",
@@ -13021,6 +13954,9 @@ func TestUpdateCellsAlias(t *testing.T) {
 				CellsAlias: &topodatapb.CellsAlias{
 					Cells: []string{"zone1", "zone2"},
 				},
+				Impact: &vtctldatapb.CellsAliasImpact{
+					RemovedCells: []string{"zone3"},
+				},
 			},
 		},
 		{
@@ -13054,6 +13990,9 @@ func TestUpdateCellsAlias(t *testing.T) {
 						"zone4",
 					},
 				},
+				Impact: &vtctldatapb.CellsAliasImpact{
+					AddedCells: []string{"zone4"},
+				},
 			},
 		},
 		{
@@ -13070,7 +14009,129 @@ func TestUpdateCellsAlias(t *testing.T) {
 				CellsAlias: &topodatapb.CellsAlias{
 					Cells: []string{"zone1", "zone2"},
 				},
+				Impact: &vtctldatapb.CellsAliasImpact{
+					AddedCells: []string{"zone1", "zone2"},
+				},
+			},
+		},
+		{
+			name:  "affected keyspaces",
+			cells: []string{"zone1", "zone2", "zone3"},
+			aliases: map[string][]string{
+				"zone": {
+					"zone1",
+					"zone2",
+				},
+			},
+			srvKeyspaces: map[string][]string{
+				"zone1": {"ks1"},
+				"zone3": {"ks1", "ks2"},
+			},
+			req: &vtctldatapb.UpdateCellsAliasRequest{
+				Name: "zone",
+				CellsAlias: &topodatapb.CellsAlias{
+					Cells: []string{"zone1", "zone3"},
+				},
+			},
+			expected: &vtctldatapb.UpdateCellsAliasResponse{
+				Name: "zone",
+				CellsAlias: &topodatapb.CellsAlias{
+					Cells: []string{"zone1", "zone3"},
+				},
+				Impact: &vtctldatapb.CellsAliasImpact{
+					AddedCells:        []string{"zone3"},
+					RemovedCells:      []string{"zone2"},
+					AffectedKeyspaces: []string{"ks1", "ks2"},
+				},
+			},
+		},
+		{
+			name:  "no change",
+			cells: []string{"zone1"},
+			aliases: map[string][]string{
+				"zone": {
+					"zone1",
+				},
+			},
+			srvKeyspaces: map[string][]string{
+				"zone1": {"ks1"},
+			},
+			req: &vtctldatapb.UpdateCellsAliasRequest{
+				Name: "zone",
+				CellsAlias: &topodatapb.CellsAlias{
+					Cells: []string{"zone1"},
+				},
+			},
+			expected: &vtctldatapb.UpdateCellsAliasResponse{
+				Name: "zone",
+				CellsAlias: &topodatapb.CellsAlias{
+					Cells: []string{"zone1"},
+				},
+				Impact: &vtctldatapb.CellsAliasImpact{},
+			},
+		},
+		{
+			name:  "dry run",
+			cells: []string{"zone1", "zone2", "zone3"},
+			aliases: map[string][]string{
+				"zone": {
+					"zone1",
+					"zone2",
+				},
+			},
+			srvKeyspaces: map[string][]string{
+				"zone2": {"ks1"},
+			},
+			req: &vtctldatapb.UpdateCellsAliasRequest{
+				Name: "zone",
+				CellsAlias: &topodatapb.CellsAlias{
+					Cells: []string{"zone1", "zone2", "zone3"},
+				},
+				DryRun: true,
+			},
+			expected: &vtctldatapb.UpdateCellsAliasResponse{
+				Name: "zone",
+				CellsAlias: &topodatapb.CellsAlias{
+					Cells: []string{"zone1", "zone2", "zone3"},
+				},
+				Impact: &vtctldatapb.CellsAliasImpact{
+					AddedCells:        []string{"zone3"},
+					AffectedKeyspaces: []string{"ks1"},
+				},
+			},
+		},
+		{
+			name:  "unknown cell",
+			cells: []string{"zone1"},
+			req: &vtctldatapb.UpdateCellsAliasRequest{
+				Name: "zone",
+				CellsAlias: &topodatapb.CellsAlias{
+					Cells: []string{"zone1", "zone2"},
+				},
 			},
+			shouldErr: true,
+		},
+		{
+			name:  "duplicate cell",
+			cells: []string{"zone1"},
+			req: &vtctldatapb.UpdateCellsAliasRequest{
+				Name: "zone",
+				CellsAlias: &topodatapb.CellsAlias{
+					Cells: []string{"zone1", "zone1"},
+				},
+			},
+			shouldErr: true,
+		},
+		{
+			name:  "alias shadows cell",
+			cells: []string{"zone1", "zone2"},
+			req: &vtctldatapb.UpdateCellsAliasRequest{
+				Name: "zone1",
+				CellsAlias: &topodatapb.CellsAlias{
+					Cells: []string{"zone2"},
+				},
+			},
+			shouldErr: true,
 		},
 		{
 			name: "invalid alias list",
@@ -13108,6 +14169,10 @@ func TestUpdateCellsAlias(t *testing.T) {
 			ts := memorytopo.NewServer(ctx, tt.cells...)
 			for name, cells := range tt.aliases {
 				for _, cell := range cells {
+					if slices.Contains(tt.cells, cell) {
+						continue
+					}
+
 					// We use UpdateCellInfoFields rather than CreateCellInfo
 					// for the update-or-create behavior.
 					err := ts.UpdateCellInfoFields(ctx, cell, func(ci *topodatapb.CellInfo) error {
@@ -13124,6 +14189,24 @@ func TestUpdateCellsAlias(t *testing.T) {
 				require.NoError(t, err, "failed to create cell alias %v (cells = %v)", name, cells)
 			}
 
+			for cell, keyspaces := range tt.srvKeyspaces {
+				for _, keyspace := range keyspaces {
+					err := ts.UpdateSrvKeyspace(ctx, cell, keyspace, &topodatapb.SrvKeyspace{
+						Partitions: []*topodatapb.SrvKeyspace_KeyspacePartition{{
+							ServedType:      topodatapb.TabletType_REPLICA,
+							ShardReferences: []*topodatapb.ShardReference{{Name: "-"}},
+						}},
+					})
+					require.NoError(t, err, "failed to create srvkeyspace %v in cell %v", keyspace, cell)
+				}
+			}
+
+			before, err := ts.GetCellsAlias(ctx, tt.req.Name, true)
+			if topo.IsErrType(err, topo.NoNode) {
+				err = nil
+			}
+			require.NoError(t, err)
+
 			vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, nil, func(ts *topo.Server) vtctlservicepb.VtctldServer {
 				return NewVtctldServer(vtenv.NewTestEnv(), ts)
 			})
@@ -13135,6 +14218,19 @@ func TestUpdateCellsAlias(t *testing.T) {
 
 			require.NoError(t, err)
 			utils.MustMatch(t, tt.expected, resp)
+
+			after, err := ts.GetCellsAlias(ctx, tt.req.Name, true)
+			if tt.req.DryRun {
+				if topo.IsErrType(err, topo.NoNode) {
+					err = nil
+				}
+				require.NoError(t, err)
+				utils.MustMatch(t, before, after, "dry run should not change the cells alias")
+				return
+			}
+
+			require.NoError(t, err)
+			utils.MustMatch(t, resp.CellsAlias, after)
 		})
 	}
 }
@@ -14256,6 +15352,51 @@ func TestValidateShard(t *testing.T) {
 		})
 	}
 }
+func TestWatchTopologyPath(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "zone1")
+	vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, nil, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+		return NewVtctldServer(vtenv.NewTestEnv(), ts)
+	})
+	client := localvtctldclient.New(vtctld)
+
+	require.NoError(t, ts.UpdateSrvKeyspace(ctx, "zone1", "ks", &topodatapb.SrvKeyspace{}))
+
+	watchCtx, watchCancel := context.WithCancel(ctx)
+	defer watchCancel()
+	stream, err := client.WatchTopologyPath(watchCtx, &vtctldatapb.WatchTopologyPathRequest{
+		Cell:           "zone1",
+		Path:           "keyspaces/ks",
+		IncludeInitial: true,
+	})
+	require.NoError(t, err)
+
+	resp, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, "keyspaces/ks/SrvKeyspace", resp.Path)
+	assert.True(t, resp.Initial)
+	assert.False(t, resp.Deleted)
+
+	require.NoError(t, ts.UpdateSrvKeyspace(ctx, "zone1", "ks", &topodatapb.SrvKeyspace{
+		Partitions: []*topodatapb.SrvKeyspace_KeyspacePartition{{ServedType: topodatapb.TabletType_PRIMARY}},
+	}))
+	resp, err = stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, "keyspaces/ks/SrvKeyspace", resp.Path)
+	assert.False(t, resp.Initial)
+	assert.Contains(t, resp.Data, `"served_type": "PRIMARY"`)
+
+	require.NoError(t, ts.DeleteSrvKeyspace(ctx, "zone1", "ks"))
+	resp, err = stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, "keyspaces/ks/SrvKeyspace", resp.Path)
+	assert.True(t, resp.Deleted)
+	assert.Empty(t, resp.Data)
+}
+
 func TestMain(m *testing.M) {
 	_flag.ParseFlagsForTest()
 	os.Exit(m.Run())
@@ -29,6 +29,7 @@ import (
 	"vitess.io/vitess/go/vt/vterrors"
 
 	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
 	"vitess.io/vitess/go/vt/proto/vtrpc"
 )
 
@@ -335,7 +336,7 @@ func removeShardCell(ctx context.Context, ts *topo.Server, cell string, keyspace
 
 	log.Infof("Removing cell %v from SrvKeyspace %v/%v", cell, keyspace, shardName)
 
-	ctx, unlock, lockErr := ts.LockKeyspace(ctx, keyspace, "Locking keyspace to remove shard from SrvKeyspace")
+	ctx, unlock, lockErr := ts.LockKeyspaceRouting(ctx, keyspace, "Locking keyspace routing to remove shard from SrvKeyspace")
 	if lockErr != nil {
 		return lockErr
 	}
@@ -356,3 +357,11 @@ func removeShardCell(ctx context.Context, ts *topo.Server, cell string, keyspace
 
 	return err
 }
+
+func cellsAliasImpactToProto(impact *topo.CellsAliasImpact) *vtctldatapb.CellsAliasImpact {
+	return &vtctldatapb.CellsAliasImpact{
+		AddedCells:        impact.AddedCells,
+		RemovedCells:      impact.RemovedCells,
+		AffectedKeyspaces: impact.AffectedKeyspaces,
+	}
+}
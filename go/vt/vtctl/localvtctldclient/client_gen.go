@@ -101,6 +101,11 @@ func (stream *backupStreamAdapter) Send(msg *vtctldatapb.BackupResponse) error {
 	}
 }
 
+// ApproveCommand is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) ApproveCommand(ctx context.Context, in *vtctldatapb.ApproveCommandRequest, opts ...grpc.CallOption) (*vtctldatapb.ApproveCommandResponse, error) {
+	return client.s.ApproveCommand(ctx, in)
+}
+
 // Backup is part of the vtctlservicepb.VtctldClient interface.
 func (client *localVtctldClient) Backup(ctx context.Context, in *vtctldatapb.BackupRequest, opts ...grpc.CallOption) (vtctlservicepb.Vtctld_BackupClient, error) {
 	stream := &backupStreamAdapter{
@@ -201,6 +206,11 @@ func (client *localVtctldClient) CreateShard(ctx context.Context, in *vtctldatap
 	return client.s.CreateShard(ctx, in)
 }
 
+// DecommissionCell is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) DecommissionCell(ctx context.Context, in *vtctldatapb.DecommissionCellRequest, opts ...grpc.CallOption) (*vtctldatapb.DecommissionCellResponse, error) {
+	return client.s.DecommissionCell(ctx, in)
+}
+
 // DeleteCellInfo is part of the vtctlservicepb.VtctldClient interface.
 func (client *localVtctldClient) DeleteCellInfo(ctx context.Context, in *vtctldatapb.DeleteCellInfoRequest, opts ...grpc.CallOption) (*vtctldatapb.DeleteCellInfoResponse, error) {
 	return client.s.DeleteCellInfo(ctx, in)
@@ -256,6 +266,11 @@ func (client *localVtctldClient) ExecuteMultiFetchAsDBA(ctx context.Context, in
 	return client.s.ExecuteMultiFetchAsDBA(ctx, in)
 }
 
+// ExportTopology is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) ExportTopology(ctx context.Context, in *vtctldatapb.ExportTopologyRequest, opts ...grpc.CallOption) (*vtctldatapb.ExportTopologyResponse, error) {
+	return client.s.ExportTopology(ctx, in)
+}
+
 // FindAllShardsInKeyspace is part of the vtctlservicepb.VtctldClient interface.
 func (client *localVtctldClient) FindAllShardsInKeyspace(ctx context.Context, in *vtctldatapb.FindAllShardsInKeyspaceRequest, opts ...grpc.CallOption) (*vtctldatapb.FindAllShardsInKeyspaceResponse, error) {
 	return client.s.FindAllShardsInKeyspace(ctx, in)
@@ -266,6 +281,16 @@ func (client *localVtctldClient) ForceCutOverSchemaMigration(ctx context.Context
 	return client.s.ForceCutOverSchemaMigration(ctx, in)
 }
 
+// ForceUnlock is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) ForceUnlock(ctx context.Context, in *vtctldatapb.ForceUnlockRequest, opts ...grpc.CallOption) (*vtctldatapb.ForceUnlockResponse, error) {
+	return client.s.ForceUnlock(ctx, in)
+}
+
+// GetElections is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) GetElections(ctx context.Context, in *vtctldatapb.GetElectionsRequest, opts ...grpc.CallOption) (*vtctldatapb.GetElectionsResponse, error) {
+	return client.s.GetElections(ctx, in)
+}
+
 // GetBackups is part of the vtctlservicepb.VtctldClient interface.
 func (client *localVtctldClient) GetBackups(ctx context.Context, in *vtctldatapb.GetBackupsRequest, opts ...grpc.CallOption) (*vtctldatapb.GetBackupsResponse, error) {
 	return client.s.GetBackups(ctx, in)
@@ -286,6 +311,11 @@ func (client *localVtctldClient) GetCellsAliases(ctx context.Context, in *vtctld
 	return client.s.GetCellsAliases(ctx, in)
 }
 
+// GetCommandApprovals is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) GetCommandApprovals(ctx context.Context, in *vtctldatapb.GetCommandApprovalsRequest, opts ...grpc.CallOption) (*vtctldatapb.GetCommandApprovalsResponse, error) {
+	return client.s.GetCommandApprovals(ctx, in)
+}
+
 // GetFullStatus is part of the vtctlservicepb.VtctldClient interface.
 func (client *localVtctldClient) GetFullStatus(ctx context.Context, in *vtctldatapb.GetFullStatusRequest, opts ...grpc.CallOption) (*vtctldatapb.GetFullStatusResponse, error) {
 	return client.s.GetFullStatus(ctx, in)
@@ -296,6 +326,11 @@ func (client *localVtctldClient) GetKeyspace(ctx context.Context, in *vtctldatap
 	return client.s.GetKeyspace(ctx, in)
 }
 
+// GetKeyspaceLabels is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) GetKeyspaceLabels(ctx context.Context, in *vtctldatapb.GetKeyspaceLabelsRequest, opts ...grpc.CallOption) (*vtctldatapb.GetKeyspaceLabelsResponse, error) {
+	return client.s.GetKeyspaceLabels(ctx, in)
+}
+
 // GetKeyspaceRoutingRules is part of the vtctlservicepb.VtctldClient interface.
 func (client *localVtctldClient) GetKeyspaceRoutingRules(ctx context.Context, in *vtctldatapb.GetKeyspaceRoutingRulesRequest, opts ...grpc.CallOption) (*vtctldatapb.GetKeyspaceRoutingRulesResponse, error) {
 	return client.s.GetKeyspaceRoutingRules(ctx, in)
@@ -306,6 +341,11 @@ func (client *localVtctldClient) GetKeyspaces(ctx context.Context, in *vtctldata
 	return client.s.GetKeyspaces(ctx, in)
 }
 
+// GetLocks is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) GetLocks(ctx context.Context, in *vtctldatapb.GetLocksRequest, opts ...grpc.CallOption) (*vtctldatapb.GetLocksResponse, error) {
+	return client.s.GetLocks(ctx, in)
+}
+
 // GetPermissions is part of the vtctlservicepb.VtctldClient interface.
 func (client *localVtctldClient) GetPermissions(ctx context.Context, in *vtctldatapb.GetPermissionsRequest, opts ...grpc.CallOption) (*vtctldatapb.GetPermissionsResponse, error) {
 	return client.s.GetPermissions(ctx, in)
@@ -331,6 +371,11 @@ func (client *localVtctldClient) GetShard(ctx context.Context, in *vtctldatapb.G
 	return client.s.GetShard(ctx, in)
 }
 
+// GetShardLabels is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) GetShardLabels(ctx context.Context, in *vtctldatapb.GetShardLabelsRequest, opts ...grpc.CallOption) (*vtctldatapb.GetShardLabelsResponse, error) {
+	return client.s.GetShardLabels(ctx, in)
+}
+
 // GetShardReplication is part of the vtctlservicepb.VtctldClient interface.
 func (client *localVtctldClient) GetShardReplication(ctx context.Context, in *vtctldatapb.GetShardReplicationRequest, opts ...grpc.CallOption) (*vtctldatapb.GetShardReplicationResponse, error) {
 	return client.s.GetShardReplication(ctx, in)
@@ -386,6 +431,11 @@ func (client *localVtctldClient) GetVSchema(ctx context.Context, in *vtctldatapb
 	return client.s.GetVSchema(ctx, in)
 }
 
+// GetVSchemaHistory is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) GetVSchemaHistory(ctx context.Context, in *vtctldatapb.GetVSchemaHistoryRequest, opts ...grpc.CallOption) (*vtctldatapb.GetVSchemaHistoryResponse, error) {
+	return client.s.GetVSchemaHistory(ctx, in)
+}
+
 // GetVersion is part of the vtctlservicepb.VtctldClient interface.
 func (client *localVtctldClient) GetVersion(ctx context.Context, in *vtctldatapb.GetVersionRequest, opts ...grpc.CallOption) (*vtctldatapb.GetVersionResponse, error) {
 	return client.s.GetVersion(ctx, in)
@@ -396,6 +446,11 @@ func (client *localVtctldClient) GetWorkflows(ctx context.Context, in *vtctldata
 	return client.s.GetWorkflows(ctx, in)
 }
 
+// ImportTopology is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) ImportTopology(ctx context.Context, in *vtctldatapb.ImportTopologyRequest, opts ...grpc.CallOption) (*vtctldatapb.ImportTopologyResponse, error) {
+	return client.s.ImportTopology(ctx, in)
+}
+
 // InitShardPrimary is part of the vtctlservicepb.VtctldClient interface.
 func (client *localVtctldClient) InitShardPrimary(ctx context.Context, in *vtctldatapb.InitShardPrimaryRequest, opts ...grpc.CallOption) (*vtctldatapb.InitShardPrimaryResponse, error) {
 	return client.s.InitShardPrimary(ctx, in)
@@ -406,6 +461,11 @@ func (client *localVtctldClient) LaunchSchemaMigration(ctx context.Context, in *
 	return client.s.LaunchSchemaMigration(ctx, in)
 }
 
+// ListTopologyPath is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) ListTopologyPath(ctx context.Context, in *vtctldatapb.ListTopologyPathRequest, opts ...grpc.CallOption) (*vtctldatapb.ListTopologyPathResponse, error) {
+	return client.s.ListTopologyPath(ctx, in)
+}
+
 // LookupVindexCreate is part of the vtctlservicepb.VtctldClient interface.
 func (client *localVtctldClient) LookupVindexCreate(ctx context.Context, in *vtctldatapb.LookupVindexCreateRequest, opts ...grpc.CallOption) (*vtctldatapb.LookupVindexCreateResponse, error) {
 	return client.s.LookupVindexCreate(ctx, in)
@@ -461,6 +521,11 @@ func (client *localVtctldClient) PingTablet(ctx context.Context, in *vtctldatapb
 	return client.s.PingTablet(ctx, in)
 }
 
+// PingTopoWatch is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) PingTopoWatch(ctx context.Context, in *vtctldatapb.PingTopoWatchRequest, opts ...grpc.CallOption) (*vtctldatapb.PingTopoWatchResponse, error) {
+	return client.s.PingTopoWatch(ctx, in)
+}
+
 // PlannedReparentShard is part of the vtctlservicepb.VtctldClient interface.
 func (client *localVtctldClient) PlannedReparentShard(ctx context.Context, in *vtctldatapb.PlannedReparentShardRequest, opts ...grpc.CallOption) (*vtctldatapb.PlannedReparentShardResponse, error) {
 	return client.s.PlannedReparentShard(ctx, in)
@@ -526,6 +591,11 @@ func (client *localVtctldClient) ReshardCreate(ctx context.Context, in *vtctldat
 	return client.s.ReshardCreate(ctx, in)
 }
 
+// ResignLeader is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) ResignLeader(ctx context.Context, in *vtctldatapb.ResignLeaderRequest, opts ...grpc.CallOption) (*vtctldatapb.ResignLeaderResponse, error) {
+	return client.s.ResignLeader(ctx, in)
+}
+
 type restoreFromBackupStreamAdapter struct {
 	*grpcshim.BidiStream
 	ch chan *vtctldatapb.RestoreFromBackupResponse
@@ -577,11 +647,21 @@ func (client *localVtctldClient) RestoreFromBackup(ctx context.Context, in *vtct
 	return stream, nil
 }
 
+// RestoreTopoFromBackups is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) RestoreTopoFromBackups(ctx context.Context, in *vtctldatapb.RestoreTopoFromBackupsRequest, opts ...grpc.CallOption) (*vtctldatapb.RestoreTopoFromBackupsResponse, error) {
+	return client.s.RestoreTopoFromBackups(ctx, in)
+}
+
 // RetrySchemaMigration is part of the vtctlservicepb.VtctldClient interface.
 func (client *localVtctldClient) RetrySchemaMigration(ctx context.Context, in *vtctldatapb.RetrySchemaMigrationRequest, opts ...grpc.CallOption) (*vtctldatapb.RetrySchemaMigrationResponse, error) {
 	return client.s.RetrySchemaMigration(ctx, in)
 }
 
+// RollbackVSchema is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) RollbackVSchema(ctx context.Context, in *vtctldatapb.RollbackVSchemaRequest, opts ...grpc.CallOption) (*vtctldatapb.RollbackVSchemaResponse, error) {
+	return client.s.RollbackVSchema(ctx, in)
+}
+
 // RunHealthCheck is part of the vtctlservicepb.VtctldClient interface.
 func (client *localVtctldClient) RunHealthCheck(ctx context.Context, in *vtctldatapb.RunHealthCheckRequest, opts ...grpc.CallOption) (*vtctldatapb.RunHealthCheckResponse, error) {
 	return client.s.RunHealthCheck(ctx, in)
@@ -592,11 +672,21 @@ func (client *localVtctldClient) SetKeyspaceDurabilityPolicy(ctx context.Context
 	return client.s.SetKeyspaceDurabilityPolicy(ctx, in)
 }
 
+// SetKeyspaceLabels is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) SetKeyspaceLabels(ctx context.Context, in *vtctldatapb.SetKeyspaceLabelsRequest, opts ...grpc.CallOption) (*vtctldatapb.SetKeyspaceLabelsResponse, error) {
+	return client.s.SetKeyspaceLabels(ctx, in)
+}
+
 // SetShardIsPrimaryServing is part of the vtctlservicepb.VtctldClient interface.
 func (client *localVtctldClient) SetShardIsPrimaryServing(ctx context.Context, in *vtctldatapb.SetShardIsPrimaryServingRequest, opts ...grpc.CallOption) (*vtctldatapb.SetShardIsPrimaryServingResponse, error) {
 	return client.s.SetShardIsPrimaryServing(ctx, in)
 }
 
+// SetShardLabels is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) SetShardLabels(ctx context.Context, in *vtctldatapb.SetShardLabelsRequest, opts ...grpc.CallOption) (*vtctldatapb.SetShardLabelsResponse, error) {
+	return client.s.SetShardLabels(ctx, in)
+}
+
 // SetShardTabletControl is part of the vtctlservicepb.VtctldClient interface.
 func (client *localVtctldClient) SetShardTabletControl(ctx context.Context, in *vtctldatapb.SetShardTabletControlRequest, opts ...grpc.CallOption) (*vtctldatapb.SetShardTabletControlResponse, error) {
 	return client.s.SetShardTabletControl(ctx, in)
@@ -652,6 +742,11 @@ func (client *localVtctldClient) StopReplication(ctx context.Context, in *vtctld
 	return client.s.StopReplication(ctx, in)
 }
 
+// SwapRoutingRules is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) SwapRoutingRules(ctx context.Context, in *vtctldatapb.SwapRoutingRulesRequest, opts ...grpc.CallOption) (*vtctldatapb.SwapRoutingRulesResponse, error) {
+	return client.s.SwapRoutingRules(ctx, in)
+}
+
 // TabletExternallyReparented is part of the vtctlservicepb.VtctldClient interface.
 func (client *localVtctldClient) TabletExternallyReparented(ctx context.Context, in *vtctldatapb.TabletExternallyReparentedRequest, opts ...grpc.CallOption) (*vtctldatapb.TabletExternallyReparentedResponse, error) {
 	return client.s.TabletExternallyReparented(ctx, in)
@@ -732,6 +827,57 @@ func (client *localVtctldClient) ValidateVersionShard(ctx context.Context, in *v
 	return client.s.ValidateVersionShard(ctx, in)
 }
 
+type watchTopologyPathStreamAdapter struct {
+	*grpcshim.BidiStream
+	ch chan *vtctldatapb.WatchTopologyPathResponse
+}
+
+func (stream *watchTopologyPathStreamAdapter) Recv() (*vtctldatapb.WatchTopologyPathResponse, error) {
+	select {
+	case <-stream.Context().Done():
+		return nil, stream.Context().Err()
+	case <-stream.Closed():
+		// Stream has been closed for future sends. If there are messages that
+		// have already been sent, receive them until there are no more. After
+		// all sent messages have been received, Recv will return the CloseErr.
+		select {
+		case msg := <-stream.ch:
+			return msg, nil
+		default:
+			return nil, stream.CloseErr()
+		}
+	case err := <-stream.ErrCh:
+		return nil, err
+	case msg := <-stream.ch:
+		return msg, nil
+	}
+}
+
+func (stream *watchTopologyPathStreamAdapter) Send(msg *vtctldatapb.WatchTopologyPathResponse) error {
+	select {
+	case <-stream.Context().Done():
+		return stream.Context().Err()
+	case <-stream.Closed():
+		return grpcshim.ErrStreamClosed
+	case stream.ch <- msg:
+		return nil
+	}
+}
+
+// WatchTopologyPath is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) WatchTopologyPath(ctx context.Context, in *vtctldatapb.WatchTopologyPathRequest, opts ...grpc.CallOption) (vtctlservicepb.Vtctld_WatchTopologyPathClient, error) {
+	stream := &watchTopologyPathStreamAdapter{
+		BidiStream: grpcshim.NewBidiStream(ctx),
+		ch:         make(chan *vtctldatapb.WatchTopologyPathResponse, 1),
+	}
+	go func() {
+		err := client.s.WatchTopologyPath(in, stream)
+		stream.CloseWithError(err)
+	}()
+
+	return stream, nil
+}
+
 // WorkflowDelete is part of the vtctlservicepb.VtctldClient interface.
 func (client *localVtctldClient) WorkflowDelete(ctx context.Context, in *vtctldatapb.WorkflowDeleteRequest, opts ...grpc.CallOption) (*vtctldatapb.WorkflowDeleteResponse, error) {
 	return client.s.WorkflowDelete(ctx, in)
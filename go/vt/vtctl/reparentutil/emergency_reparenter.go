@@ -100,10 +100,12 @@ func (erp *EmergencyReparenter) ReparentShard(ctx context.Context, keyspace stri
 	statsLabels := []string{keyspace, shard}
 
 	opts.lockAction = erp.getLockAction(opts.NewPrimaryAlias)
-	// First step is to lock the shard for the given operation, if not already locked
+	// First step is to lock the shard for the given operation, if not already locked.
+	// The routine actions holding the shard lock with a low priority are
+	// preempted, rather than waited for.
 	if err = topo.CheckShardLocked(ctx, keyspace, shard); err != nil {
 		var unlock func(*error)
-		ctx, unlock, err = erp.ts.LockShard(ctx, keyspace, shard, opts.lockAction)
+		ctx, unlock, err = erp.ts.LockShard(topo.WithLockPriority(ctx, topo.EmergencyLockPriority), keyspace, shard, opts.lockAction)
 		if err != nil {
 			ersCounter.Add(append(statsLabels, failureResult), 1)
 			return nil, err
@@ -98,10 +98,13 @@ func (pr *PlannedReparenter) ReparentShard(ctx context.Context, keyspace string,
 	var err error
 	statsLabels := []string{keyspace, shard}
 
+	// The shard lock is taken with a low priority, so that an
+	// EmergencyReparentShard waiting for it preempts this reparent, which
+	// then aborts and releases it.
 	if err = topo.CheckShardLocked(ctx, keyspace, shard); err != nil {
 		var unlock func(*error)
 		opts.lockAction = pr.getLockAction(opts)
-		ctx, unlock, err = pr.ts.LockShard(ctx, keyspace, shard, opts.lockAction)
+		ctx, unlock, err = pr.ts.LockShard(topo.WithLockPriority(ctx, topo.LowLockPriority), keyspace, shard, opts.lockAction)
 		if err != nil {
 			prsCounter.Add(append(statsLabels, failureResult), 1)
 			return nil, err
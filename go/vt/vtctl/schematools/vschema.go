@@ -0,0 +1,77 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schematools
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
+	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
+)
+
+// ValidateVSchemaAgainstSchema returns the problems of a vschema with regards
+// to the schema of a shard of its keyspace: the tables of the vschema that are
+// not in the schema, and the columns of their vindexes and auto-increments
+// that are not in their table. Column names are compared case-insensitively,
+// as MySQL does.
+func ValidateVSchemaAgainstSchema(vschema *vschemapb.Keyspace, sd *tabletmanagerdatapb.SchemaDefinition) []string {
+	tableDefs := make(map[string]*tabletmanagerdatapb.TableDefinition, len(sd.GetTableDefinitions()))
+	for _, td := range sd.GetTableDefinitions() {
+		tableDefs[td.Name] = td
+	}
+
+	tableNames := make([]string, 0, len(vschema.GetTables()))
+	for name := range vschema.GetTables() {
+		tableNames = append(tableNames, name)
+	}
+	sort.Strings(tableNames)
+
+	var problems []string
+	for _, name := range tableNames {
+		td, ok := tableDefs[name]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("table %s is not in the schema", name))
+			continue
+		}
+		if len(td.Columns) == 0 {
+			continue
+		}
+		columns := make(map[string]bool, len(td.Columns))
+		for _, column := range td.Columns {
+			columns[strings.ToLower(column)] = true
+		}
+
+		table := vschema.Tables[name]
+		for _, cv := range table.ColumnVindexes {
+			cvColumns := cv.Columns
+			if cv.Column != "" {
+				cvColumns = append([]string{cv.Column}, cvColumns...)
+			}
+			for _, column := range cvColumns {
+				if !columns[strings.ToLower(column)] {
+					problems = append(problems, fmt.Sprintf("column %s of vindex %s is not in table %s", column, cv.Name, name))
+				}
+			}
+		}
+		if ai := table.AutoIncrement; ai != nil && ai.Column != "" && !columns[strings.ToLower(ai.Column)] {
+			problems = append(problems, fmt.Sprintf("auto-increment column %s is not in table %s", ai.Column, name))
+		}
+	}
+	return problems
+}
@@ -0,0 +1,61 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schematools
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
+	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
+)
+
+func TestValidateVSchemaAgainstSchema(t *testing.T) {
+	sd := &tabletmanagerdatapb.SchemaDefinition{
+		TableDefinitions: []*tabletmanagerdatapb.TableDefinition{
+			{Name: "t1", Columns: []string{"id", "Name"}},
+			{Name: "t2", Columns: []string{"id"}},
+		},
+	}
+	vschema := &vschemapb.Keyspace{
+		Sharded: true,
+		Tables: map[string]*vschemapb.Table{
+			"t1": {
+				ColumnVindexes: []*vschemapb.ColumnVindex{
+					{Name: "hash", Column: "ID"},
+					{Name: "lookup", Columns: []string{"name", "missing"}},
+				},
+			},
+			"t2": {
+				AutoIncrement: &vschemapb.AutoIncrement{Column: "seq", Sequence: "t2_seq"},
+			},
+			"t3": {},
+		},
+	}
+
+	assert.Equal(t, []string{
+		"column missing of vindex lookup is not in table t1",
+		"auto-increment column seq is not in table t2",
+		"table t3 is not in the schema",
+	}, ValidateVSchemaAgainstSchema(vschema, sd))
+
+	delete(vschema.Tables, "t3")
+	vschema.Tables["t1"].ColumnVindexes = vschema.Tables["t1"].ColumnVindexes[:1]
+	vschema.Tables["t2"].AutoIncrement.Column = "id"
+	assert.Empty(t, ValidateVSchemaAgainstSchema(vschema, sd))
+}
@@ -38,8 +38,8 @@ func init() {
 	addCommand(topoGroupName, command{
 		name:   "TopoCat",
 		method: commandTopoCat,
-		params: "[--cell <cell>] [--decode_proto] [--decode_proto_json] [--long] <path> [<path>...]",
-		help:   "Retrieves the file(s) at <path> from the topo service, and displays it. It can resolve wildcards, and decode the proto-encoded data.",
+		params: "[--cell <cell>] [--decode_proto] [--decode_proto_json] [--long] [--keys] <path> [<path>...]",
+		help:   "Retrieves the file(s) at <path> from the topo service, and displays it. It can resolve wildcards, and decode the proto-encoded data. With --keys, the paths are the keys of the files in the topology server, including the root of the cell, like /vitess/global/keyspaces/ks/Keyspace.",
 	})
 
 	addCommand(topoGroupName, command{
@@ -55,11 +55,19 @@ func commandTopoCat(ctx context.Context, wr *wrangler.Wrangler, subFlags *pflag.
 	long := subFlags.Bool("long", false, "long listing.")
 	decodeProtoJSON := subFlags.Bool("decode_proto_json", false, "decode proto files and display them as json")
 	decodeProto := subFlags.Bool("decode_proto", false, "decode proto files and display them as text")
+	keys := subFlags.Bool("keys", false, "the paths are the keys of the files in the topology server, including the root of the cell, which are translated to the paths relative to the root.")
 	subFlags.Parse(args)
 	if subFlags.NArg() == 0 {
 		return fmt.Errorf("TopoCat: no path specified")
 	}
-	resolved, err := wr.TopoServer().ResolveWildcards(ctx, *cell, subFlags.Args())
+	paths := subFlags.Args()
+	if *keys {
+		var err error
+		if paths, err = keysToPaths(ctx, wr.TopoServer(), *cell, paths); err != nil {
+			return fmt.Errorf("TopoCat: %v", err)
+		}
+	}
+	resolved, err := wr.TopoServer().ResolveWildcards(ctx, *cell, paths)
 	if err != nil {
 		return fmt.Errorf("TopoCat: invalid wildcards: %v", err)
 	}
@@ -86,6 +94,24 @@ func commandTopoCat(ctx context.Context, wr *wrangler.Wrangler, subFlags *pflag.
 	return topologyDecoder.decode(ctx, resolved, conn, wr, *long)
 }
 
+// keysToPaths translates the keys of files in the topology server of cell
+// into their paths relative to the root of the cell.
+func keysToPaths(ctx context.Context, ts *topo.Server, cell string, keys []string) ([]string, error) {
+	root, err := ts.RootOf(ctx, cell)
+	if err != nil {
+		return nil, err
+	}
+	paths := make([]string, 0, len(keys))
+	for _, key := range keys {
+		p, ok := topo.RelativeToRoot(root, key)
+		if !ok {
+			return nil, fmt.Errorf("%v is not under the root %q of cell %v", key, root, cell)
+		}
+		paths = append(paths, p)
+	}
+	return paths, nil
+}
+
 func commandTopoCp(ctx context.Context, wr *wrangler.Wrangler, subFlags *pflag.FlagSet, args []string) error {
 	cell := subFlags.String("cell", topo.GlobalCell, "topology cell to use for the copy. Defaults to global cell.")
 	toTopo := subFlags.Bool("to_topo", false, "copies from local server to topo instead (reverse direction).")
@@ -3452,7 +3452,7 @@ func commandApplyVSchema(ctx context.Context, wr *wrangler.Wrangler, subFlags *p
 		return err
 	}
 
-	if err := wr.TopoServer().SaveVSchema(ctx, keyspace, vs); err != nil {
+	if err := wr.TopoServer().SaveVSchemaWithHistory(ctx, keyspace, vs); err != nil {
 		return err
 	}
 
@@ -3460,7 +3460,7 @@ func commandApplyVSchema(ctx context.Context, wr *wrangler.Wrangler, subFlags *p
 		wr.Logger().Warningf("Skipping rebuild of SrvVSchema, will need to run RebuildVSchemaGraph for changes to take effect")
 		return nil
 	}
-	return wr.TopoServer().RebuildSrvVSchema(ctx, cells)
+	return wr.TopoServer().RebuildSrvVSchemaKeyspace(ctx, keyspace, cells)
 }
 
 func commandApplyRoutingRules(ctx context.Context, wr *wrangler.Wrangler, subFlags *pflag.FlagSet, args []string) error {
@@ -265,6 +265,13 @@ func (mz *materializer) deploySchema() error {
 		removeAutoInc = true
 	}
 
+	// The adjustments made to the tables created on the target are recorded
+	// in the workflow options.
+	adjustments := mz.ms.GetWorkflowOptions().GetTargetSchemaAdjustments()
+	if adjustments != nil {
+		mz.ms.WorkflowOptions.AppliedSchemaAdjustments = make(map[string]string)
+	}
+
 	return forAllShards(mz.targetShards, func(target *topo.ShardInfo) error {
 		allTables := []string{"/.*/"}
 
@@ -353,6 +360,24 @@ func (mz *materializer) deploySchema() error {
 					}
 				}
 
+				if adjustments != nil {
+					ddl, err = adjustTableDDL(ddl, adjustments, mz.env.Parser())
+					if err != nil {
+						return err
+					}
+
+					env := schemadiff.NewEnv(mz.env, mz.env.CollationEnv().DefaultConnectionCharset())
+					diff, err := schemadiff.DiffCreateTablesQueries(env, sourceDDLs[ts.TargetTable], ddl, schemadiff.EmptyDiffHints())
+					if err != nil {
+						return vterrors.Wrapf(err, "failed to compute the schema adjustments of table %v", ts.TargetTable)
+					}
+					if !diff.IsEmpty() {
+						mu.Lock()
+						mz.ms.WorkflowOptions.AppliedSchemaAdjustments[ts.TargetTable] = diff.CanonicalStatementString()
+						mu.Unlock()
+					}
+				}
+
 				createDDL = ddl
 			}
 
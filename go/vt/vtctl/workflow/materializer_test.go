@@ -18,6 +18,7 @@ package workflow
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"slices"
 	"strings"
@@ -283,6 +284,120 @@ func TestStripAutoIncrement(t *testing.T) {
 	}
 }
 
+func TestAdjustTableDDL(t *testing.T) {
+	parser := sqlparser.NewTestParser()
+	ddl := "CREATE TABLE `t1` (\n" +
+		"`id` int NOT NULL,\n" +
+		"`c1` int,\n" +
+		"PRIMARY KEY (`id`),\n" +
+		"CONSTRAINT `chk` CHECK (`c1` > 0)\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=latin1 COLLATE=latin1_swedish_ci\n" +
+		"PARTITION BY RANGE (`id`) (PARTITION p0 VALUES LESS THAN (10), PARTITION p1 VALUES LESS THAN MAXVALUE)"
+
+	tcs := []struct {
+		desc        string
+		adjustments *vtctldatapb.TargetSchemaAdjustments
+		want        string
+		wantErr     string
+	}{
+		{
+			desc:        "drop unsupported features and override the charset",
+			adjustments: &vtctldatapb.TargetSchemaAdjustments{DropCheckConstraints: true, DropPartitioning: true, Charset: "utf8mb4", Collation: "utf8mb4_0900_ai_ci"},
+			want: "create table t1 (\n" +
+				"\tid int not null,\n" +
+				"\tc1 int,\n" +
+				"\tprimary key (id)\n" +
+				") ENGINE InnoDB,\n" +
+				"  CHARSET utf8mb4,\n" +
+				"  COLLATE utf8mb4_0900_ai_ci",
+		},
+		{
+			desc: "override the partitioning",
+			adjustments: &vtctldatapb.TargetSchemaAdjustments{
+				DropPartitioning: true,
+				Partitioning:     map[string]string{"t1": "PARTITION BY HASH (id) PARTITIONS 4"},
+			},
+			want: "create table t1 (\n" +
+				"\tid int not null,\n" +
+				"\tc1 int,\n" +
+				"\tprimary key (id),\n" +
+				"\tconstraint chk check (c1 > 0)\n" +
+				") ENGINE InnoDB,\n" +
+				"  CHARSET latin1,\n" +
+				"  COLLATE latin1_swedish_ci\n" +
+				"partition by hash (id) partitions 4",
+		},
+		{
+			desc: "invalid partitioning",
+			adjustments: &vtctldatapb.TargetSchemaAdjustments{
+				Partitioning: map[string]string{"t1": "ENGINE=InnoDB"},
+			},
+			wantErr: "no PARTITION BY clause",
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.desc, func(t *testing.T) {
+			got, err := adjustTableDDL(ddl, tc.adjustments, parser)
+			if tc.wantErr != "" {
+				require.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.want, got)
+		})
+	}
+}
+
+// TestMoveTablesTargetSchemaAdjustments confirms that the target tables are
+// created with the requested adjustments, and that the resulting changes are
+// recorded in the workflow options.
+func TestMoveTablesTargetSchemaAdjustments(t *testing.T) {
+	ms := &vtctldatapb.MaterializeSettings{
+		Workflow:       "workflow",
+		SourceKeyspace: "sourceks",
+		TargetKeyspace: "targetks",
+		TableSettings: []*vtctldatapb.TableMaterializeSettings{{
+			TargetTable:      "t1",
+			SourceExpression: "select * from t1",
+		}},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	env := newTestMaterializerEnv(t, ctx, ms, []string{"0"}, []string{"0"})
+	defer env.close()
+
+	env.tmc.schema[ms.SourceKeyspace+".t1"].TableDefinitions[0].Schema = "CREATE TABLE `t1` (\n" +
+		"`id` int NOT NULL,\n" +
+		"PRIMARY KEY (`id`)\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4\n" +
+		"PARTITION BY HASH (`id`) PARTITIONS 2"
+	delete(env.tmc.schema, ms.TargetKeyspace+".t1")
+
+	env.tmc.expectVRQuery(200, "create table t1 (\n\tid int not null,\n\tprimary key (id)\n) ENGINE InnoDB,\n  CHARSET utf8mb4", &sqltypes.Result{})
+	env.tmc.expectVRQuery(100, mzCheckJournal, &sqltypes.Result{})
+	env.tmc.expectVRQuery(200, mzGetCopyState, &sqltypes.Result{})
+	env.tmc.expectVRQuery(200, mzGetLatestCopyState, &sqltypes.Result{})
+
+	_, err := env.ws.MoveTablesCreate(ctx, &vtctldatapb.MoveTablesCreateRequest{
+		Workflow:       ms.Workflow,
+		SourceKeyspace: ms.SourceKeyspace,
+		TargetKeyspace: ms.TargetKeyspace,
+		IncludeTables:  []string{"t1"},
+		WorkflowOptions: &vtctldatapb.WorkflowOptions{
+			TargetSchemaAdjustments: &vtctldatapb.TargetSchemaAdjustments{DropPartitioning: true},
+		},
+	})
+	require.NoError(t, err)
+
+	req := env.tmc.receivedCreateVReplicationWorkflowRequests[200]
+	require.NotNil(t, req)
+	options := &vtctldatapb.WorkflowOptions{}
+	require.NoError(t, json.Unmarshal([]byte(req.Options), options))
+	require.Equal(t, map[string]string{"t1": "ALTER TABLE `t1` REMOVE PARTITIONING"}, options.AppliedSchemaAdjustments)
+}
+
 func TestAddTablesToVSchema(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -1623,29 +1623,24 @@ func (s *Server) setupInitialRoutingRules(ctx context.Context, req *vtctldatapb.
 	}
 
 	// Setup table routing rules.
-	rules, err := topotools.GetRoutingRules(ctx, s.ts)
-	if err != nil {
-		return err
-	}
-	routeTableToSource := func(keyspace, table string) {
-		key := table
-		route := fmt.Sprintf("%s.%s", sourceKeyspace, table)
-		if keyspace != "" {
-			key = fmt.Sprintf("%s.%s", keyspace, table)
-		}
-		for _, typ := range tabletTypeSuffixes {
-			rules[key+typ] = []string{route}
+	return topotools.UpdateRoutingRules(ctx, s.ts, "MoveTablesCreate", func(ctx context.Context, rules map[string][]string) error {
+		routeTableToSource := func(keyspace, table string) {
+			key := table
+			route := fmt.Sprintf("%s.%s", sourceKeyspace, table)
+			if keyspace != "" {
+				key = fmt.Sprintf("%s.%s", keyspace, table)
+			}
+			for _, typ := range tabletTypeSuffixes {
+				rules[key+typ] = []string{route}
+			}
 		}
-	}
-	for _, table := range tables {
-		for _, ks := range []string{globalTableQualifier, targetKeyspace, sourceKeyspace} {
-			routeTableToSource(ks, table)
+		for _, table := range tables {
+			for _, ks := range []string{globalTableQualifier, targetKeyspace, sourceKeyspace} {
+				routeTableToSource(ks, table)
+			}
 		}
-	}
-	if err := topotools.SaveRoutingRules(ctx, s.ts, rules); err != nil {
-		return err
-	}
-	return nil
+		return nil
+	})
 }
 
 // MoveTablesComplete is part of the vtctlservicepb.VtctldServer interface.
@@ -2590,6 +2585,13 @@ func (s *Server) DropTargets(ctx context.Context, ts *trafficSwitcher, keepData,
 		defer targetUnlock(&err)
 		ctx = tctx
 	}
+	tctx, routingUnlock, lockErr := lockWorkflowRouting(ctx, sw, ts, "DropTargets")
+	if lockErr != nil {
+		ts.Logger().Errorf("LockKeyspaceRouting failed: %v", lockErr)
+		return nil, lockErr
+	}
+	defer routingUnlock(&err)
+	ctx = tctx
 	if !keepData {
 		switch ts.MigrationType() {
 		case binlogdatapb.MigrationType_TABLES:
@@ -2781,6 +2783,13 @@ func (s *Server) dropSources(ctx context.Context, ts *trafficSwitcher, removalTy
 		defer targetUnlock(&err)
 		ctx = tctx
 	}
+	tctx, routingUnlock, lockErr := lockWorkflowRouting(ctx, sw, ts, "DropSources")
+	if lockErr != nil {
+		ts.Logger().Errorf("LockKeyspaceRouting failed: %v", lockErr)
+		return nil, lockErr
+	}
+	defer routingUnlock(&err)
+	ctx = tctx
 	if !force {
 		if err := sw.validateWorkflowHasCompleted(ctx); err != nil {
 			ts.Logger().Errorf("Workflow has not completed, cannot DropSources: %v", err)
@@ -3239,10 +3248,12 @@ func (s *Server) switchReads(ctx context.Context, req *vtctldatapb.WorkflowSwitc
 		return handleError("workflow validation failed", err)
 	}
 
-	// For reads, locking the source keyspace is sufficient.
-	ctx, unlock, lockErr := sw.lockKeyspace(ctx, ts.SourceKeyspaceName(), "SwitchReads")
+	// For reads, locking the routing of the source keyspace is sufficient:
+	// the other traffic switches of the workflow lock it too, and the
+	// reparents and the backups of the keyspace are not held up.
+	ctx, unlock, lockErr := sw.lockKeyspaceRouting(ctx, ts.SourceKeyspaceName(), "SwitchReads")
 	if lockErr != nil {
-		return handleError(fmt.Sprintf("failed to lock the %s keyspace", ts.SourceKeyspaceName()), lockErr)
+		return handleError(fmt.Sprintf("failed to lock the routing of the %s keyspace", ts.SourceKeyspaceName()), lockErr)
 	}
 	defer unlock(&err)
 
@@ -3278,6 +3289,28 @@ func (s *Server) switchReads(ctx context.Context, req *vtctldatapb.WorkflowSwitc
 	return sw.logs(), nil
 }
 
+// lockWorkflowRouting takes the routing locks of the source and the target
+// keyspaces of the workflow, in that order. They are taken after the keyspace
+// locks, see topo.Server.LockKeyspaceRouting for the order of the locks.
+func lockWorkflowRouting(ctx context.Context, sw iswitcher, ts *trafficSwitcher, action string) (context.Context, func(*error), error) {
+	ctx, sourceUnlock, err := sw.lockKeyspaceRouting(ctx, ts.SourceKeyspaceName(), action)
+	if err != nil {
+		return nil, nil, err
+	}
+	if ts.TargetKeyspaceName() == ts.SourceKeyspaceName() {
+		return ctx, sourceUnlock, nil
+	}
+	ctx, targetUnlock, err := sw.lockKeyspaceRouting(ctx, ts.TargetKeyspaceName(), action)
+	if err != nil {
+		sourceUnlock(&err)
+		return nil, nil, err
+	}
+	return ctx, func(finalErr *error) {
+		targetUnlock(finalErr)
+		sourceUnlock(finalErr)
+	}, nil
+}
+
 // switchWrites is a generic way of migrating write traffic for a workflow.
 func (s *Server) switchWrites(ctx context.Context, req *vtctldatapb.WorkflowSwitchTrafficRequest, ts *trafficSwitcher, timeout time.Duration,
 	cancel bool,
@@ -3326,6 +3359,13 @@ func (s *Server) switchWrites(ctx context.Context, req *vtctldatapb.WorkflowSwit
 		ctx = tctx
 		defer targetUnlock(&err)
 	}
+	// The SrvKeyspaces are changed as well, so their routing is locked too.
+	tctx, routingUnlock, lockErr := lockWorkflowRouting(ctx, sw, ts, "SwitchWrites")
+	if lockErr != nil {
+		return handleError("failed to lock the routing of the keyspaces", lockErr)
+	}
+	ctx = tctx
+	defer routingUnlock(&err)
 
 	// Find out if the target is using any sequence tables for auto_increment
 	// value generation. If so, then we'll need to ensure that they are
@@ -130,6 +130,10 @@ func (r *switcher) lockKeyspace(ctx context.Context, keyspace, action string) (c
 	return r.s.ts.LockKeyspace(ctx, keyspace, action)
 }
 
+func (r *switcher) lockKeyspaceRouting(ctx context.Context, keyspace, action string) (context.Context, func(*error), error) {
+	return r.s.ts.LockKeyspaceRouting(ctx, keyspace, action)
+}
+
 func (r *switcher) freezeTargetVReplication(ctx context.Context) error {
 	return r.ts.freezeTargetVReplication(ctx)
 }
@@ -300,6 +300,13 @@ func (dr *switcherDryRun) lockKeyspace(ctx context.Context, keyspace, _ string)
 	}, nil
 }
 
+func (dr *switcherDryRun) lockKeyspaceRouting(ctx context.Context, keyspace, _ string) (context.Context, func(*error), error) {
+	dr.drLog.Logf("Lock routing of keyspace %s", keyspace)
+	return ctx, func(e *error) {
+		dr.drLog.Logf("Unlock routing of keyspace %s", keyspace)
+	}, nil
+}
+
 func (dr *switcherDryRun) removeSourceTables(ctx context.Context, removalType TableRemovalType) error {
 	logs := make([]string, 0)
 	sort.Strings(dr.ts.Tables()) // For deterministic output
@@ -25,6 +25,7 @@ import (
 
 type iswitcher interface {
 	lockKeyspace(ctx context.Context, keyspace, action string) (context.Context, func(*error), error)
+	lockKeyspaceRouting(ctx context.Context, keyspace, action string) (context.Context, func(*error), error)
 	cancelMigration(ctx context.Context, sm *StreamMigrator)
 	stopStreams(ctx context.Context, sm *StreamMigrator) ([]string, error)
 	stopSourceWrites(ctx context.Context) error
@@ -408,46 +408,37 @@ func (ts *trafficSwitcher) addParticipatingTablesToKeyspace(ctx context.Context,
 }
 
 func (ts *trafficSwitcher) deleteRoutingRules(ctx context.Context) error {
-	rules, err := topotools.GetRoutingRules(ctx, ts.TopoServer())
-	if err != nil {
-		return err
-	}
-	for _, table := range ts.Tables() {
-		delete(rules, table)
-		delete(rules, table+"@replica")
-		delete(rules, table+"@rdonly")
-		delete(rules, ts.TargetKeyspaceName()+"."+table)
-		delete(rules, ts.TargetKeyspaceName()+"."+table+"@replica")
-		delete(rules, ts.TargetKeyspaceName()+"."+table+"@rdonly")
-		delete(rules, ts.SourceKeyspaceName()+"."+table)
-		delete(rules, ts.SourceKeyspaceName()+"."+table+"@replica")
-		delete(rules, ts.SourceKeyspaceName()+"."+table+"@rdonly")
-	}
-	if err := topotools.SaveRoutingRules(ctx, ts.TopoServer(), rules); err != nil {
-		return err
-	}
-	return nil
+	return topotools.UpdateRoutingRules(ctx, ts.TopoServer(), "DeleteRoutingRules", func(ctx context.Context, rules map[string][]string) error {
+		for _, table := range ts.Tables() {
+			delete(rules, table)
+			delete(rules, table+"@replica")
+			delete(rules, table+"@rdonly")
+			delete(rules, ts.TargetKeyspaceName()+"."+table)
+			delete(rules, ts.TargetKeyspaceName()+"."+table+"@replica")
+			delete(rules, ts.TargetKeyspaceName()+"."+table+"@rdonly")
+			delete(rules, ts.SourceKeyspaceName()+"."+table)
+			delete(rules, ts.SourceKeyspaceName()+"."+table+"@replica")
+			delete(rules, ts.SourceKeyspaceName()+"."+table+"@rdonly")
+		}
+		return nil
+	})
 }
 
 func (ts *trafficSwitcher) deleteShardRoutingRules(ctx context.Context) error {
 	if !ts.isPartialMigration {
 		return nil
 	}
-	srr, err := topotools.GetShardRoutingRules(ctx, ts.TopoServer())
-	if err != nil {
-		if topo.IsErrType(err, topo.NoNode) {
-			log.Warningf("No shard routing rules found when attempting to delete the ones for the %s keyspace", ts.targetKeyspace)
-			return nil
+	err := topotools.UpdateShardRoutingRules(ctx, ts.TopoServer(), "DeleteShardRoutingRules", func(ctx context.Context, srr map[string]string) error {
+		for _, si := range ts.TargetShards() {
+			delete(srr, fmt.Sprintf("%s.%s", ts.targetKeyspace, si.ShardName()))
 		}
-		return err
-	}
-	for _, si := range ts.TargetShards() {
-		delete(srr, fmt.Sprintf("%s.%s", ts.targetKeyspace, si.ShardName()))
-	}
-	if err := topotools.SaveShardRoutingRules(ctx, ts.TopoServer(), srr); err != nil {
-		return err
+		return nil
+	})
+	if topo.IsErrType(err, topo.NoNode) {
+		log.Warningf("No shard routing rules found when attempting to delete the ones for the %s keyspace", ts.targetKeyspace)
+		return nil
 	}
-	return nil
+	return err
 }
 
 func (ts *trafficSwitcher) deleteKeyspaceRoutingRules(ctx context.Context) error {
@@ -614,29 +605,28 @@ func (ts *trafficSwitcher) switchShardReads(ctx context.Context, cells []string,
 
 func (ts *trafficSwitcher) switchTableReads(ctx context.Context, cells []string, servedTypes []topodatapb.TabletType, rebuildSrvVSchema bool, direction TrafficSwitchDirection) error {
 	log.Infof("switchTableReads: cells: %s, tablet types: %+v, direction: %s", strings.Join(cells, ","), servedTypes, direction)
-	rules, err := topotools.GetRoutingRules(ctx, ts.TopoServer())
-	if err != nil {
-		return err
-	}
-	// We assume that the following rules were setup when the targets were created:
-	// table -> sourceKeyspace.table
-	// targetKeyspace.table -> sourceKeyspace.table
-	// For forward migration, we add tablet type specific rules to redirect traffic to the target.
-	// For backward, we redirect to source.
-	for _, servedType := range servedTypes {
-		if servedType != topodatapb.TabletType_REPLICA && servedType != topodatapb.TabletType_RDONLY {
-			return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid tablet type specified when switching reads: %v", servedType)
-		}
+	err := topotools.UpdateRoutingRules(ctx, ts.TopoServer(), "SwitchReads", func(ctx context.Context, rules map[string][]string) error {
+		// We assume that the following rules were setup when the targets were created:
+		// table -> sourceKeyspace.table
+		// targetKeyspace.table -> sourceKeyspace.table
+		// For forward migration, we add tablet type specific rules to redirect traffic to the target.
+		// For backward, we redirect to source.
+		for _, servedType := range servedTypes {
+			if servedType != topodatapb.TabletType_REPLICA && servedType != topodatapb.TabletType_RDONLY {
+				return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid tablet type specified when switching reads: %v", servedType)
+			}
 
-		tt := strings.ToLower(servedType.String())
-		for _, table := range ts.Tables() {
-			toTarget := []string{ts.TargetKeyspaceName() + "." + table}
-			rules[table+"@"+tt] = toTarget
-			rules[ts.TargetKeyspaceName()+"."+table+"@"+tt] = toTarget
-			rules[ts.SourceKeyspaceName()+"."+table+"@"+tt] = toTarget
+			tt := strings.ToLower(servedType.String())
+			for _, table := range ts.Tables() {
+				toTarget := []string{ts.TargetKeyspaceName() + "." + table}
+				rules[table+"@"+tt] = toTarget
+				rules[ts.TargetKeyspaceName()+"."+table+"@"+tt] = toTarget
+				rules[ts.SourceKeyspaceName()+"."+table+"@"+tt] = toTarget
+			}
 		}
-	}
-	if err := topotools.SaveRoutingRules(ctx, ts.TopoServer(), rules); err != nil {
+		return nil
+	})
+	if err != nil {
 		return err
 	}
 	if rebuildSrvVSchema {
@@ -735,36 +725,34 @@ func (ts *trafficSwitcher) changeWriteRoute(ctx context.Context) error {
 			return err
 		}
 	} else if ts.isPartialMigration {
-		srr, err := topotools.GetShardRoutingRules(ctx, ts.TopoServer())
+		err := topotools.UpdateShardRoutingRules(ctx, ts.TopoServer(), "SwitchWrites", func(ctx context.Context, srr map[string]string) error {
+			for _, si := range ts.SourceShards() {
+				delete(srr, fmt.Sprintf("%s.%s", ts.TargetKeyspaceName(), si.ShardName()))
+				ts.Logger().Infof("Deleted shard routing: %v:%v", ts.TargetKeyspaceName(), si.ShardName())
+				srr[fmt.Sprintf("%s.%s", ts.SourceKeyspaceName(), si.ShardName())] = ts.TargetKeyspaceName()
+				ts.Logger().Infof("Added shard routing: %v:%v", ts.SourceKeyspaceName(), si.ShardName())
+			}
+			return nil
+		})
 		if err != nil {
 			return err
 		}
-		for _, si := range ts.SourceShards() {
-			delete(srr, fmt.Sprintf("%s.%s", ts.TargetKeyspaceName(), si.ShardName()))
-			ts.Logger().Infof("Deleted shard routing: %v:%v", ts.TargetKeyspaceName(), si.ShardName())
-			srr[fmt.Sprintf("%s.%s", ts.SourceKeyspaceName(), si.ShardName())] = ts.TargetKeyspaceName()
-			ts.Logger().Infof("Added shard routing: %v:%v", ts.SourceKeyspaceName(), si.ShardName())
-		}
-		if err := topotools.SaveShardRoutingRules(ctx, ts.TopoServer(), srr); err != nil {
-			return err
-		}
 	} else {
-		rules, err := topotools.GetRoutingRules(ctx, ts.TopoServer())
+		err := topotools.UpdateRoutingRules(ctx, ts.TopoServer(), "SwitchWrites", func(ctx context.Context, rules map[string][]string) error {
+			for _, table := range ts.Tables() {
+				targetKsTable := fmt.Sprintf("%s.%s", ts.TargetKeyspaceName(), table)
+				sourceKsTable := fmt.Sprintf("%s.%s", ts.SourceKeyspaceName(), table)
+				delete(rules, targetKsTable)
+				ts.Logger().Infof("Deleted routing: %s", targetKsTable)
+				rules[table] = []string{targetKsTable}
+				rules[sourceKsTable] = []string{targetKsTable}
+				ts.Logger().Infof("Added routing: %v %v", table, sourceKsTable)
+			}
+			return nil
+		})
 		if err != nil {
 			return err
 		}
-		for _, table := range ts.Tables() {
-			targetKsTable := fmt.Sprintf("%s.%s", ts.TargetKeyspaceName(), table)
-			sourceKsTable := fmt.Sprintf("%s.%s", ts.SourceKeyspaceName(), table)
-			delete(rules, targetKsTable)
-			ts.Logger().Infof("Deleted routing: %s", targetKsTable)
-			rules[table] = []string{targetKsTable}
-			rules[sourceKsTable] = []string{targetKsTable}
-			ts.Logger().Infof("Added routing: %v %v", table, sourceKsTable)
-		}
-		if err := topotools.SaveRoutingRules(ctx, ts.TopoServer(), rules); err != nil {
-			return err
-		}
 	}
 
 	return ts.TopoServer().RebuildSrvVSchema(ctx, nil)
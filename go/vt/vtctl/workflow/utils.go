@@ -137,28 +137,27 @@ func validateNewWorkflow(ctx context.Context, ts *topo.Server, tmc tmclient.Tabl
 // each shard in a new partial keyspace migration workflow that does
 // not already have an existing routing rule in place.
 func createDefaultShardRoutingRules(ctx context.Context, ms *vtctldatapb.MaterializeSettings, ts *topo.Server) error {
-	srr, err := topotools.GetShardRoutingRules(ctx, ts)
-	if err != nil {
-		return err
-	}
 	allShards, err := ts.GetServingShards(ctx, ms.SourceKeyspace)
 	if err != nil {
 		return err
 	}
 	changed := false
-	for _, si := range allShards {
-		fromSource := fmt.Sprintf("%s.%s", ms.SourceKeyspace, si.ShardName())
-		fromTarget := fmt.Sprintf("%s.%s", ms.TargetKeyspace, si.ShardName())
-		if srr[fromSource] == "" && srr[fromTarget] == "" {
-			srr[fromTarget] = ms.SourceKeyspace
-			changed = true
-			log.Infof("Added default shard routing rule from %q to %q", fromTarget, fromSource)
+	err = topotools.UpdateShardRoutingRules(ctx, ts, "MoveTablesCreate", func(ctx context.Context, srr map[string]string) error {
+		for _, si := range allShards {
+			fromSource := fmt.Sprintf("%s.%s", ms.SourceKeyspace, si.ShardName())
+			fromTarget := fmt.Sprintf("%s.%s", ms.TargetKeyspace, si.ShardName())
+			if srr[fromSource] == "" && srr[fromTarget] == "" {
+				srr[fromTarget] = ms.SourceKeyspace
+				changed = true
+				log.Infof("Added default shard routing rule from %q to %q", fromTarget, fromSource)
+			}
 		}
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 	if changed {
-		if err := topotools.SaveShardRoutingRules(ctx, ts, srr); err != nil {
-			return err
-		}
 		if err := ts.RebuildSrvVSchema(ctx, nil); err != nil {
 			return err
 		}
@@ -236,6 +235,71 @@ func stripAutoIncrement(ddl string, parser *sqlparser.Parser) (string, error) {
 	return sqlparser.String(newDDL), nil
 }
 
+// adjustTableDDL applies the given adjustments to the CREATE TABLE statement
+// of a source table, and returns the resulting statement.
+func adjustTableDDL(ddl string, adjustments *vtctldatapb.TargetSchemaAdjustments, parser *sqlparser.Parser) (string, error) {
+	stmt, err := parser.ParseStrictDDL(ddl)
+	if err != nil {
+		return "", err
+	}
+	createTable, ok := stmt.(*sqlparser.CreateTable)
+	if !ok {
+		return "", fmt.Errorf("expected a CREATE TABLE statement, got: %s", ddl)
+	}
+	spec := createTable.TableSpec
+
+	if adjustments.DropCheckConstraints {
+		var constraints []*sqlparser.ConstraintDefinition
+		for _, constraint := range spec.Constraints {
+			if _, ok := constraint.Details.(*sqlparser.CheckConstraintDefinition); !ok {
+				constraints = append(constraints, constraint)
+			}
+		}
+		spec.Constraints = constraints
+	}
+
+	if partitioning, ok := adjustments.Partitioning[createTable.Table.Name.String()]; ok {
+		// Parse the clause on its own, as part of a dummy table.
+		stmt, err := parser.ParseStrictDDL("create table t (c int) " + partitioning)
+		if err != nil {
+			return "", fmt.Errorf("invalid partitioning %q for table %v: %w", partitioning, createTable.Table.Name.String(), err)
+		}
+		partitionOption := stmt.(*sqlparser.CreateTable).TableSpec.PartitionOption
+		if partitionOption == nil {
+			return "", fmt.Errorf("invalid partitioning %q for table %v: no PARTITION BY clause", partitioning, createTable.Table.Name.String())
+		}
+		spec.PartitionOption = partitionOption
+	} else if adjustments.DropPartitioning {
+		spec.PartitionOption = nil
+	}
+
+	if adjustments.Charset != "" || adjustments.Collation != "" {
+		var options sqlparser.TableOptions
+		for _, option := range spec.Options {
+			switch strings.ToLower(option.Name) {
+			case "charset", "character set":
+				if adjustments.Charset != "" {
+					continue
+				}
+			case "collate":
+				if adjustments.Collation != "" {
+					continue
+				}
+			}
+			options = append(options, option)
+		}
+		if adjustments.Charset != "" {
+			options = append(options, &sqlparser.TableOption{Name: "CHARSET", String: adjustments.Charset, CaseSensitive: true})
+		}
+		if adjustments.Collation != "" {
+			options = append(options, &sqlparser.TableOption{Name: "COLLATE", String: adjustments.Collation, CaseSensitive: true})
+		}
+		spec.Options = options
+	}
+
+	return sqlparser.String(createTable), nil
+}
+
 func getSourceTableDDLs(ctx context.Context, ts *topo.Server, tmc tmclient.TabletManagerClient, shards []*topo.ShardInfo) (map[string]string, error) {
 	sourceDDLs := make(map[string]string)
 	allTables := []string{"/.*/"}
@@ -51,7 +51,7 @@ func newBackendExplorer(ts *topo.Server) *backendExplorer {
 
 // HandlePath is the main function for this class.
 func (ex *backendExplorer) HandlePath(nodePath string, r *http.Request) *Result {
-	ctx := context.Background()
+	ctx := topo.WithConsistency(context.Background(), topo.Relaxed)
 	result := &Result{}
 
 	// Handle toplevel display: global, then one line per cell.
@@ -20,6 +20,7 @@ package vtctld
 
 import (
 	"context"
+	"time"
 
 	"github.com/spf13/pflag"
 
@@ -29,6 +30,10 @@ import (
 
 	"vitess.io/vitess/go/acl"
 	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/topobackup"
+	"vitess.io/vitess/go/vt/topo/topobridge"
+	"vitess.io/vitess/go/vt/topo/topocount"
+	"vitess.io/vitess/go/vt/topo/topogc"
 	"vitess.io/vitess/go/vt/wrangler"
 
 	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
@@ -37,16 +42,48 @@ import (
 
 var (
 	sanitizeLogMessages = false
+	topoBridgeConfig    string
+
+	topoBackupInterval       time.Duration
+	topoBackupDir            = topobackup.DefaultDir
+	topoBackupRetentionCount = 7
+	topoBackupRetentionAge   time.Duration
+
+	topoGCInterval time.Duration
+	topoGCPrune    bool
+
+	topoObjectCountInterval time.Duration
 )
 
 func init() {
 	for _, cmd := range []string{"vtcombo", "vtctld"} {
 		servenv.OnParseFor(cmd, registerVtctldFlags)
+		servenv.OnParseFor(cmd, registerTopoGCFlags)
+		servenv.OnParseFor(cmd, registerTopoObjectCountFlags)
 	}
+	// Topo backups go to the backup storage, which only vtctld configures.
+	servenv.OnParseFor("vtctld", registerTopoBackupFlags)
 }
 
 func registerVtctldFlags(fs *pflag.FlagSet) {
 	fs.BoolVar(&sanitizeLogMessages, "vtctld_sanitize_log_messages", sanitizeLogMessages, "When true, vtctld sanitizes logging.")
+	fs.StringVar(&topoBridgeConfig, "topo_bridge_config", topoBridgeConfig, "Path to a JSON file configuring topo paths to watch, and webhooks or Kafka topics to forward their changes to. When set, vtctld runs the topo bridge.")
+}
+
+func registerTopoBackupFlags(fs *pflag.FlagSet) {
+	fs.DurationVar(&topoBackupInterval, "topo_backup_interval", topoBackupInterval, "How often to back up the topo to the backup storage. 0 disables scheduled topo backups.")
+	fs.StringVar(&topoBackupDir, "topo_backup_dir", topoBackupDir, "The backup storage directory of the scheduled topo backups.")
+	fs.IntVar(&topoBackupRetentionCount, "topo_backup_retention_count", topoBackupRetentionCount, "How many scheduled topo backups to keep. 0 keeps them all, unless --topo_backup_retention_age is set.")
+	fs.DurationVar(&topoBackupRetentionAge, "topo_backup_retention_age", topoBackupRetentionAge, "How long to keep scheduled topo backups for. 0 keeps them regardless of their age. The most recent backup is always kept.")
+}
+
+func registerTopoGCFlags(fs *pflag.FlagSet) {
+	fs.DurationVar(&topoGCInterval, "topo_gc_interval", topoGCInterval, "How often to scan the topo for orphaned objects, such as replication graph entries of deleted tablets, shards of deleted keyspaces, and SrvKeyspaces of deleted keyspaces. 0 disables the scans.")
+	fs.BoolVar(&topoGCPrune, "topo_gc_prune", topoGCPrune, "When true, the orphaned topo objects found by the scans are deleted. Otherwise, they are only reported in the logs and the TopoGCOrphans metric.")
+}
+
+func registerTopoObjectCountFlags(fs *pflag.FlagSet) {
+	fs.DurationVar(&topoObjectCountInterval, "topo_object_count_interval", topoObjectCountInterval, "How often to count the keyspaces, shards, served vschemas, locks and tablets per cell of the topo, exported as the TopoObjects metric. 0 disables the counts.")
 }
 
 // InitVtctld initializes all the vtctld functionality.
@@ -127,6 +164,50 @@ func InitVtctld(env *vtenv.Environment, ts *topo.Server) error {
 			return "", err
 		})
 
+	// Forward topology changes to external systems
+	if topoBridgeConfig != "" {
+		config, err := topobridge.LoadConfig(topoBridgeConfig)
+		if err != nil {
+			return err
+		}
+		bridge := topobridge.New(ts, config)
+		bridge.Start()
+		servenv.OnClose(bridge.Stop)
+	}
+
+	// Periodically back up the topo
+	if topoBackupInterval > 0 {
+		scheduler := topobackup.NewScheduler(ts, topobackup.Config{
+			Interval: topoBackupInterval,
+			Dir:      topoBackupDir,
+			Retention: topobackup.Retention{
+				Count:  topoBackupRetentionCount,
+				MaxAge: topoBackupRetentionAge,
+			},
+		})
+		scheduler.Start()
+		servenv.OnClose(scheduler.Stop)
+	}
+
+	// Periodically look for orphaned topo objects
+	if topoGCInterval > 0 {
+		collector := topogc.NewCollector(ts, topogc.Config{
+			Interval: topoGCInterval,
+			Prune:    topoGCPrune,
+		})
+		collector.Start()
+		servenv.OnClose(collector.Stop)
+	}
+
+	// Periodically count the topo objects
+	if topoObjectCountInterval > 0 {
+		collector := topocount.NewCollector(ts, topocount.Config{
+			Interval: topoObjectCountInterval,
+		})
+		collector.Start()
+		servenv.OnClose(collector.Stop)
+	}
+
 	// Serve the REST API
 	initAPI(context.Background(), ts, actionRepo)
 
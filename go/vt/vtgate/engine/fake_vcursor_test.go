@@ -48,6 +48,7 @@ import (
 
 var testMaxMemoryRows = 100
 var testIgnoreMaxMemoryRows = false
+var testAggregationSpillConfig SpillConfig
 
 var _ VCursor = (*noopVCursor)(nil)
 var _ SessionActions = (*noopVCursor)(nil)
@@ -337,6 +338,10 @@ func (t *noopVCursor) ExceedsMaxMemoryRows(numRows int) bool {
 	return !testIgnoreMaxMemoryRows && numRows > testMaxMemoryRows
 }
 
+func (t *noopVCursor) AggregationSpillConfig() SpillConfig {
+	return testAggregationSpillConfig
+}
+
 func (t *noopVCursor) GetKeyspace() string {
 	return ""
 }
@@ -121,6 +121,68 @@ func (ms *MemorySort) TryStreamExecute(ctx context.Context, vcursor VCursor, bin
 	return cb(&sqltypes.Result{Rows: sorter.Sorted()})
 }
 
+// canSpill returns true if the sort can spill to disk instead of running
+// out of memory. Only complete sorts can: the rows of a sort with a limit
+// are already bounded.
+func (ms *MemorySort) canSpill() bool {
+	return ms.UpperLimit == nil
+}
+
+// streamExecuteSpilling works like TryStreamExecute, but sorts the rows
+// within the memory limit of config by spilling them to disk, and streams
+// them in batches.
+func (ms *MemorySort) streamExecuteSpilling(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, wantfields bool, config SpillConfig, callback func(*sqltypes.Result) error) (err error) {
+	defer evalengine.PanicHandler(&err)
+
+	cb := func(qr *sqltypes.Result) error {
+		return callback(qr.Truncate(ms.TruncateColumnCount))
+	}
+
+	sorter := newSpillSorter(config, ms.OrderBy)
+	defer sorter.close()
+
+	var mu sync.Mutex
+	err = vcursor.StreamExecutePrimitive(ctx, ms.Input, bindVars, wantfields, func(qr *sqltypes.Result) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if len(qr.Fields) != 0 {
+			if err := cb(&sqltypes.Result{Fields: qr.Fields}); err != nil {
+				return err
+			}
+		}
+		for _, row := range qr.Rows {
+			if err := sorter.push(row); err != nil {
+				return err
+			}
+		}
+		if vcursor.ExceedsMaxMemoryRows(sorter.Len()) {
+			return fmt.Errorf("in-memory row count exceeded allowed limit of %d", vcursor.MaxMemoryRows())
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	batch := make([]sqltypes.Row, 0, spillBatchSize)
+	err = sorter.sorted(func(row sqltypes.Row) error {
+		batch = append(batch, row)
+		if len(batch) < spillBatchSize {
+			return nil
+		}
+		err := cb(&sqltypes.Result{Rows: batch})
+		batch = make([]sqltypes.Row, 0, spillBatchSize)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return cb(&sqltypes.Result{Rows: batch})
+}
+
+// spillBatchSize is how many rows a spilling sort streams at a time.
+const spillBatchSize = 1024
+
 // GetFields satisfies the Primitive interface.
 func (ms *MemorySort) GetFields(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable) (*sqltypes.Result, error) {
 	return ms.Input.GetFields(ctx, vcursor, bindVars)
@@ -96,6 +96,23 @@ func (oa *OrderedAggregate) GetTableName() string {
 
 // TryExecute is a Primitive function.
 func (oa *OrderedAggregate) TryExecute(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, _ bool) (*sqltypes.Result, error) {
+	if _, ok := oa.spillingSort(vcursor); ok {
+		// Stream the sorted rows through the aggregation, so only the
+		// aggregated rows are held in memory.
+		qr := &sqltypes.Result{}
+		err := oa.TryStreamExecute(ctx, vcursor, bindVars, true, func(result *sqltypes.Result) error {
+			if result.Fields != nil {
+				qr.Fields = result.Fields
+			}
+			qr.Rows = append(qr.Rows, result.Rows...)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		return qr, nil
+	}
+
 	qr, err := oa.execute(ctx, vcursor, bindVars)
 	if err != nil {
 		return nil, err
@@ -220,7 +237,7 @@ func (oa *OrderedAggregate) executeStreamGroupBy(ctx context.Context, vcursor VC
 	}
 
 	/* we need the input fields types to correctly calculate the output types */
-	err := vcursor.StreamExecutePrimitive(ctx, oa.Input, bindVars, true, visitor)
+	err := oa.streamInput(ctx, vcursor, bindVars, visitor)
 	if err != nil {
 		return err
 	}
@@ -286,7 +303,7 @@ func (oa *OrderedAggregate) TryStreamExecute(ctx context.Context, vcursor VCurso
 	}
 
 	/* we need the input fields types to correctly calculate the output types */
-	err := vcursor.StreamExecutePrimitive(ctx, oa.Input, bindVars, true, visitor)
+	err := oa.streamInput(ctx, vcursor, bindVars, visitor)
 	if err != nil {
 		return err
 	}
@@ -299,6 +316,25 @@ func (oa *OrderedAggregate) TryStreamExecute(ctx context.Context, vcursor VCurso
 	return nil
 }
 
+// spillingSort returns the sort that feeds the aggregation, if it spills
+// to disk.
+func (oa *OrderedAggregate) spillingSort(vcursor VCursor) (*MemorySort, bool) {
+	ms, ok := oa.Input.(*MemorySort)
+	if !ok || !ms.canSpill() || !vcursor.AggregationSpillConfig().Enabled() {
+		return nil, false
+	}
+	return ms, true
+}
+
+// streamInput streams the rows of the input, through a sort that spills to
+// disk if it is enabled.
+func (oa *OrderedAggregate) streamInput(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, visitor func(*sqltypes.Result) error) error {
+	if ms, ok := oa.spillingSort(vcursor); ok {
+		return ms.streamExecuteSpilling(ctx, vcursor, bindVars, true, vcursor.AggregationSpillConfig(), visitor)
+	}
+	return vcursor.StreamExecutePrimitive(ctx, oa.Input, bindVars, true, visitor)
+}
+
 // GetFields is a Primitive function.
 func (oa *OrderedAggregate) GetFields(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable) (*sqltypes.Result, error) {
 	qr, err := oa.Input.GetFields(ctx, vcursor, bindVars)
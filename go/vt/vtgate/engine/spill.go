@@ -0,0 +1,298 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"bufio"
+	"container/heap"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/evalengine"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+var (
+	aggregationSpills = stats.NewCounter(
+		"AggregationSpills",
+		"Number of aggregations that spilled the rows they sort to disk")
+	aggregationSpilledRows = stats.NewCounter(
+		"AggregationSpilledRows",
+		"Number of rows spilled to disk by aggregations")
+	aggregationSpilledBytes = stats.NewCounter(
+		"AggregationSpilledBytes",
+		"Number of bytes spilled to disk by aggregations")
+)
+
+// SpillConfig bounds the memory used to sort the rows of an aggregation.
+// Past the limit, the sorted rows are written to temporary files, which are
+// merged as the aggregation reads them back.
+type SpillConfig struct {
+	// Dir is the directory of the temporary files, the default directory
+	// for temporary files if empty.
+	Dir string
+	// MemoryBytes is how many bytes of rows an aggregation sorts in memory
+	// before spilling them. 0 disables spilling.
+	MemoryBytes int64
+	// MaxDiskBytes is how many bytes an aggregation can spill before it
+	// fails. 0 for no limit.
+	MaxDiskBytes int64
+}
+
+// Enabled returns true if aggregations spill to disk.
+func (c SpillConfig) Enabled() bool {
+	return c.MemoryBytes > 0
+}
+
+// rowOverhead approximates the memory used by a row and its values on top
+// of their bytes.
+const rowOverhead, valueOverhead = 24, 32
+
+// spillSorter sorts rows within a memory budget, by writing sorted runs of
+// rows to temporary files when it is exceeded, and merging the runs.
+type spillSorter struct {
+	config  SpillConfig
+	compare evalengine.Comparison
+
+	rows []sqltypes.Row
+	size int64
+
+	runs      []*os.File
+	diskBytes int64
+}
+
+func newSpillSorter(config SpillConfig, compare evalengine.Comparison) *spillSorter {
+	return &spillSorter{config: config, compare: compare}
+}
+
+// Len returns the number of rows held in memory.
+func (s *spillSorter) Len() int {
+	return len(s.rows)
+}
+
+// push adds a row to sort.
+func (s *spillSorter) push(row sqltypes.Row) error {
+	s.rows = append(s.rows, row)
+	s.size += rowOverhead
+	for _, v := range row {
+		s.size += valueOverhead + int64(v.Len())
+	}
+	if s.size > s.config.MemoryBytes {
+		return s.spill()
+	}
+	return nil
+}
+
+// spill writes the rows in memory to a new run.
+func (s *spillSorter) spill() error {
+	if len(s.rows) == 0 {
+		return nil
+	}
+	if len(s.runs) == 0 {
+		aggregationSpills.Add(1)
+	}
+	s.compare.Sort(s.rows)
+
+	f, err := os.CreateTemp(s.config.Dir, "vtgate-aggregation-")
+	if err != nil {
+		return vterrors.Wrapf(err, "cannot spill aggregation rows")
+	}
+	s.runs = append(s.runs, f)
+
+	w := bufio.NewWriter(f)
+	var written int64
+	for _, row := range s.rows {
+		n, err := writeSpillRow(w, row)
+		if err != nil {
+			return vterrors.Wrapf(err, "cannot spill aggregation rows")
+		}
+		written += int64(n)
+	}
+	if err := w.Flush(); err != nil {
+		return vterrors.Wrapf(err, "cannot spill aggregation rows")
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return vterrors.Wrapf(err, "cannot spill aggregation rows")
+	}
+
+	aggregationSpilledRows.Add(int64(len(s.rows)))
+	aggregationSpilledBytes.Add(written)
+	s.diskBytes += written
+	s.rows, s.size = nil, 0
+	if s.config.MaxDiskBytes > 0 && s.diskBytes > s.config.MaxDiskBytes {
+		return vterrors.Errorf(vtrpcpb.Code_RESOURCE_EXHAUSTED, "aggregation spilled more than the allowed limit of %d bytes to disk", s.config.MaxDiskBytes)
+	}
+	return nil
+}
+
+// sorted calls fn with the rows, in order.
+func (s *spillSorter) sorted(fn func(sqltypes.Row) error) error {
+	if len(s.runs) == 0 {
+		s.compare.Sort(s.rows)
+		for _, row := range s.rows {
+			if err := fn(row); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err := s.spill(); err != nil {
+		return err
+	}
+	h := &spillHeap{compare: s.compare}
+	for _, f := range s.runs {
+		r := bufio.NewReader(f)
+		row, err := readSpillRow(r)
+		switch {
+		case err == io.EOF:
+			continue
+		case err != nil:
+			return err
+		}
+		h.runs = append(h.runs, spillRun{r: r, row: row})
+	}
+	heap.Init(h)
+	for h.Len() > 0 {
+		run := &h.runs[0]
+		if err := fn(run.row); err != nil {
+			return err
+		}
+		row, err := readSpillRow(run.r)
+		switch {
+		case err == io.EOF:
+			heap.Pop(h)
+		case err != nil:
+			return err
+		default:
+			run.row = row
+			heap.Fix(h, 0)
+		}
+	}
+	return nil
+}
+
+// close removes the temporary files.
+func (s *spillSorter) close() {
+	for _, f := range s.runs {
+		f.Close()
+		os.Remove(f.Name())
+	}
+	s.runs = nil
+}
+
+// A spilled row is its number of values, followed by the type and the
+// length of each value, -1 for NULL, and its bytes.
+func writeSpillRow(w *bufio.Writer, row sqltypes.Row) (int, error) {
+	var buf [binary.MaxVarintLen64]byte
+	written := 0
+	put := func(n int) error {
+		l := binary.PutVarint(buf[:], int64(n))
+		_, err := w.Write(buf[:l])
+		written += l
+		return err
+	}
+
+	if err := put(len(row)); err != nil {
+		return written, err
+	}
+	for _, v := range row {
+		if err := put(int(v.Type())); err != nil {
+			return written, err
+		}
+		if v.IsNull() {
+			if err := put(-1); err != nil {
+				return written, err
+			}
+			continue
+		}
+		if err := put(v.Len()); err != nil {
+			return written, err
+		}
+		n, err := w.Write(v.Raw())
+		written += n
+		if err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
+
+func readSpillRow(r *bufio.Reader) (sqltypes.Row, error) {
+	count, err := binary.ReadVarint(r)
+	if err != nil {
+		return nil, err
+	}
+	row := make(sqltypes.Row, count)
+	for i := range row {
+		typ, err := binary.ReadVarint(r)
+		if err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		length, err := binary.ReadVarint(r)
+		if err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		if length < 0 {
+			row[i] = sqltypes.NULL
+			continue
+		}
+		raw := make([]byte, length)
+		if _, err := io.ReadFull(r, raw); err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		// The values were valid when they were spilled.
+		row[i] = sqltypes.MakeTrusted(querypb.Type(typ), raw)
+	}
+	return row, nil
+}
+
+func unexpectedEOF(err error) error {
+	if errors.Is(err, io.EOF) {
+		err = io.ErrUnexpectedEOF
+	}
+	return fmt.Errorf("cannot read spilled aggregation rows: %w", err)
+}
+
+type spillRun struct {
+	r   *bufio.Reader
+	row sqltypes.Row
+}
+
+// spillHeap merges runs, with the run of the smallest row first.
+type spillHeap struct {
+	compare evalengine.Comparison
+	runs    []spillRun
+}
+
+func (h *spillHeap) Len() int           { return len(h.runs) }
+func (h *spillHeap) Less(i, j int) bool { return h.compare.Less(h.runs[i].row, h.runs[j].row) }
+func (h *spillHeap) Swap(i, j int)      { h.runs[i], h.runs[j] = h.runs[j], h.runs[i] }
+func (h *spillHeap) Push(x any)         { h.runs = append(h.runs, x.(spillRun)) }
+func (h *spillHeap) Pop() any {
+	last := h.runs[len(h.runs)-1]
+	h.runs = h.runs[:len(h.runs)-1]
+	return last
+}
@@ -0,0 +1,128 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/mysql/collations"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/test/utils"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/evalengine"
+
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+	. "vitess.io/vitess/go/vt/vtgate/engine/opcode"
+)
+
+func TestSpillSorter(t *testing.T) {
+	dir := t.TempDir()
+	compare := evalengine.Comparison{{Col: 0, WeightStringCol: -1, Type: evalengine.NewType(sqltypes.Int64, collations.CollationBinaryID)}}
+	sorter := newSpillSorter(SpillConfig{Dir: dir, MemoryBytes: 1024}, compare)
+	defer sorter.close()
+
+	spills, spilledRows := aggregationSpills.Get(), aggregationSpilledRows.Get()
+	for i := range 1000 {
+		row := sqltypes.Row{sqltypes.NewInt64(int64((i * 7919) % 1000)), sqltypes.NULL, sqltypes.NewVarChar(fmt.Sprintf("row %d", i))}
+		require.NoError(t, sorter.push(row))
+	}
+	assert.Less(t, sorter.Len(), 1000)
+	assert.Greater(t, len(sorter.runs), 1)
+
+	var got []int64
+	err := sorter.sorted(func(row sqltypes.Row) error {
+		v, err := row[0].ToInt64()
+		require.NoError(t, err)
+		assert.True(t, row[1].IsNull())
+		assert.Equal(t, sqltypes.VarChar, row[2].Type())
+		got = append(got, v)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Len(t, got, 1000)
+	for i, v := range got {
+		assert.EqualValues(t, i, v)
+	}
+	assert.Equal(t, spills+1, aggregationSpills.Get())
+	assert.Equal(t, spilledRows+1000, aggregationSpilledRows.Get())
+
+	// The temporary files are removed once the rows are read.
+	sorter.close()
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestSpillSorterMaxDiskBytes(t *testing.T) {
+	sorter := newSpillSorter(SpillConfig{Dir: t.TempDir(), MemoryBytes: 100, MaxDiskBytes: 1000}, evalengine.Comparison{{Col: 0, WeightStringCol: -1}})
+	defer sorter.close()
+
+	var err error
+	for i := 0; i < 1000 && err == nil; i++ {
+		err = sorter.push(sqltypes.Row{sqltypes.NewVarBinary(fmt.Sprintf("some value %d", i))})
+	}
+	assert.Equal(t, vtrpcpb.Code_RESOURCE_EXHAUSTED, vterrors.Code(err))
+}
+
+func TestOrderedAggregateSpill(t *testing.T) {
+	fields := sqltypes.MakeTestFields(
+		"col|count(*)",
+		"varbinary|decimal",
+	)
+	var rows []string
+	for i := range 500 {
+		rows = append(rows, fmt.Sprintf("key%03d|1", i%100))
+	}
+	newAggregate := func() *OrderedAggregate {
+		return &OrderedAggregate{
+			Aggregates:  []*AggregateParams{NewAggregateParam(AggregateSum, 1, "", collations.MySQL8())},
+			GroupByKeys: []*GroupByParams{{KeyCol: 0}},
+			Input: &MemorySort{
+				OrderBy: evalengine.Comparison{{Col: 0, WeightStringCol: -1}},
+				Input: &fakePrimitive{
+					results:             []*sqltypes.Result{sqltypes.MakeTestResult(fields, rows...)},
+					allResultsInOneCall: true,
+				},
+			},
+		}
+	}
+
+	var want []string
+	for i := range 100 {
+		want = append(want, fmt.Sprintf("key%03d|5", i))
+	}
+	wantResult := sqltypes.MakeTestResult(fields, want...)
+
+	defer func() { testAggregationSpillConfig = SpillConfig{} }()
+	testAggregationSpillConfig = SpillConfig{Dir: t.TempDir(), MemoryBytes: 1024}
+	spills := aggregationSpills.Get()
+
+	result, err := newAggregate().TryExecute(context.Background(), &noopVCursor{}, nil, true)
+	require.NoError(t, err)
+	utils.MustMatch(t, wantResult, result)
+
+	result, err = wrapStreamExecute(newAggregate(), &noopVCursor{}, nil, true)
+	require.NoError(t, err)
+	utils.MustMatch(t, wantResult, result)
+	assert.Equal(t, spills+2, aggregationSpills.Get())
+}
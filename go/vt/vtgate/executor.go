@@ -262,6 +262,11 @@ type streaminResultReceiver struct {
 	rowsReturned int
 	insertID     uint64
 	callback     func(*sqltypes.Result) error
+
+	// limits, if set, are checked against the rows and bytes returned.
+	limits        *resolvedResultLimits
+	bytesReturned uint64
+	violation     *resultLimitViolation
 }
 
 func (s *streaminResultReceiver) storeResultStats(typ sqlparser.StatementType, qr *sqltypes.Result) error {
@@ -269,6 +274,12 @@ func (s *streaminResultReceiver) storeResultStats(typ sqlparser.StatementType, q
 	defer s.mu.Unlock()
 	s.rowsAffected += qr.RowsAffected
 	s.rowsReturned += len(qr.Rows)
+	if s.limits != nil && s.violation == nil {
+		s.bytesReturned += resultBytes(qr)
+		if s.violation = s.limits.check(uint64(s.rowsReturned), s.bytesReturned); s.violation != nil && s.limits.enforced() {
+			return s.limits.reject(s.violation)
+		}
+	}
 	if qr.InsertID != 0 {
 		s.insertID = qr.InsertID
 	}
@@ -336,6 +347,9 @@ func (e *Executor) StreamExecute(
 		}
 
 		// 4: Execute!
+		if canReturnRows(plan.Type) {
+			srr.limits = resultLimits.forQuery(vc.keyspace, callerid.ImmediateCallerIDFromContext(ctx).GetUsername())
+		}
 		err := vc.StreamExecutePrimitive(ctx, plan.Instructions, bindVars, true, func(qr *sqltypes.Result) error {
 			return srr.storeResultStats(plan.Type, qr)
 		})
@@ -387,6 +401,9 @@ func (e *Executor) StreamExecute(
 			Message: warningMsg,
 		})
 	}
+	if srr.violation != nil && !srr.limits.enforced() {
+		srr.limits.warn(safeSession, e.piiSafeSQL(sql, logStats), srr.violation)
+	}
 
 	logStats.SaveEndTime()
 	e.queryLogger.Send(logStats)
@@ -397,6 +414,16 @@ func (e *Executor) StreamExecute(
 	return err
 }
 
+// piiSafeSQL returns the redacted form of sql to use in warnings, or the
+// statement type if it cannot be redacted.
+func (e *Executor) piiSafeSQL(sql string, logStats *logstats.LogStats) string {
+	piiSafeSQL, err := e.env.Parser().RedactSQLQuery(sql)
+	if err != nil {
+		return logStats.StmtType
+	}
+	return piiSafeSQL
+}
+
 func canReturnRows(stmtType sqlparser.StatementType) bool {
 	switch stmtType {
 	case sqlparser.StmtSelect, sqlparser.StmtShow, sqlparser.StmtExplain, sqlparser.StmtCallProc:
@@ -1109,7 +1136,7 @@ func (e *Executor) getPlan(
 	vcursor.SetConsolidator(sqlparser.Consolidator(stmt))
 	vcursor.SetWorkloadName(sqlparser.GetWorkloadNameFromStatement(stmt))
 	vcursor.UpdateForeignKeyChecksState(sqlparser.ForeignKeyChecksState(stmt))
-	priority, err := sqlparser.GetPriorityFromStatement(stmt)
+	priority, err := queryPriority(stmt)
 	if err != nil {
 		return nil, err
 	}
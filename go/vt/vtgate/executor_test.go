@@ -44,6 +44,7 @@ import (
 	"vitess.io/vitess/go/vt/discovery"
 	"vitess.io/vitess/go/vt/sqlparser"
 	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/vterrors"
 	"vitess.io/vitess/go/vt/vtgate/buffer"
 	"vitess.io/vitess/go/vt/vtgate/engine"
 	"vitess.io/vitess/go/vt/vtgate/logstats"
@@ -120,6 +121,80 @@ func TestExecutorMaxMemoryRowsExceeded(t *testing.T) {
 	}
 }
 
+func TestExecutorResultLimits(t *testing.T) {
+	executor, _, _, sbclookup, ctx := createExecutorEnv(t)
+
+	save := resultLimits
+	defer func() { resultLimits = save }()
+	warn, enforce := resultLimitWarn, resultLimitEnforce
+	maxRows, unlimited := uint64(3), uint64(0)
+	resultLimits = &resultLimitsConfig{
+		Default: resultLimitsSpec{MaxRows: &maxRows, Mode: &warn},
+		Users: map[string]resultLimitsSpec{
+			"strict":    {Mode: &enforce},
+			"unlimited": {MaxRows: &unlimited},
+		},
+	}
+
+	result := sqltypes.MakeTestResult(sqltypes.MakeTestFields("col", "int64"), "1", "2", "3", "4")
+	fn := func(r *sqltypes.Result) error {
+		return nil
+	}
+	testCases := []struct {
+		user    string
+		err     string
+		warning bool
+		// label is the User label of the counts of the exceeded limits.
+		label string
+	}{
+		{user: "", warning: true, label: otherUsersLabel},
+		{user: "strict", err: "result row count 4 exceeds the limit of 3", label: "strict"},
+		{user: "unlimited"},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.user, func(t *testing.T) {
+			ctx := callerid.NewContext(ctx, nil, callerid.NewImmediateCallerID(test.user))
+			exceeded := resultLimitsExceeded.Counts()
+
+			session := NewSafeSession(&vtgatepb.Session{TargetString: "@primary"})
+			sbclookup.SetResults([]*sqltypes.Result{result})
+			_, err := executor.Execute(ctx, nil, "TestExecutorResultLimits", session, "select * from main1", nil)
+			if test.err != "" {
+				assert.ErrorContains(t, err, test.err)
+				assert.Equal(t, vtrpcpb.Code_RESOURCE_EXHAUSTED, vterrors.Code(err))
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, test.warning, len(session.Warnings) == 1, "warnings: %v", session.Warnings)
+
+			session = NewSafeSession(&vtgatepb.Session{TargetString: "@primary"})
+			sbclookup.SetResults([]*sqltypes.Result{result})
+			err = executor.StreamExecute(ctx, nil, "TestExecutorResultLimits", session, "select * from main1", nil, fn)
+			if test.err != "" {
+				assert.ErrorContains(t, err, test.err)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, test.warning, len(session.Warnings) == 1, "warnings: %v", session.Warnings)
+
+			var wantCount int64
+			if test.err != "" || test.warning {
+				wantCount = 2
+			}
+			var gotCount int64
+			for key, count := range resultLimitsExceeded.Counts() {
+				if count == exceeded[key] {
+					continue
+				}
+				gotCount += count - exceeded[key]
+				assert.Equal(t, test.label, strings.Split(key, ".")[1], "VtgateResultLimitsExceeded %v", key)
+			}
+			assert.Equal(t, wantCount, gotCount, "VtgateResultLimitsExceeded")
+		})
+	}
+}
+
 func TestExecutorTransactionsNoAutoCommit(t *testing.T) {
 	executor, _, _, sbclookup, ctx := createExecutorEnv(t)
 
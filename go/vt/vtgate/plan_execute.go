@@ -23,6 +23,7 @@ import (
 	"time"
 
 	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/callerid"
 	"vitess.io/vitess/go/vt/log"
 	"vitess.io/vitess/go/vt/sqlparser"
 	"vitess.io/vitess/go/vt/vterrors"
@@ -318,7 +319,22 @@ func (e *Executor) executePlan(
 ) (*sqltypes.Result, error) {
 
 	// 4: Execute!
+	// The result limits are resolved first, so that the enforced ones fail
+	// the query as soon as the results of its shards exceed them.
+	if canReturnRows(plan.Type) {
+		vcursor.resultLimits = resultLimits.forQuery(vcursor.keyspace, callerid.ImmediateCallerIDFromContext(ctx).GetUsername())
+	}
 	qr, err := vcursor.ExecutePrimitive(ctx, plan.Instructions, bindVars, true)
+	if err == nil {
+		// vtgate may add rows to the results of the shards, e.g. in joins.
+		if violation := vcursor.resultLimits.check(uint64(len(qr.Rows)), resultBytes(qr)); violation != nil {
+			if vcursor.resultLimits.enforced() {
+				qr, err = nil, vcursor.resultLimits.reject(violation)
+			} else {
+				vcursor.resultLimits.warn(safeSession, e.piiSafeSQL(logStats.SQL, logStats), violation)
+			}
+		}
+	}
 
 	// 5: Log and add statistics
 	e.setLogStats(logStats, plan, vcursor, execStart, err, qr)
@@ -0,0 +1,239 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"vitess.io/vitess/go/mysql/sqlerror"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/logutil"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// Result limit modes.
+const (
+	// resultLimitWarn annotates results that exceed a limit with a warning.
+	resultLimitWarn = "warn"
+	// resultLimitEnforce rejects results that exceed a limit.
+	resultLimitEnforce = "enforce"
+)
+
+var (
+	// resultLimitsFile is the path of the JSON file with the result limits.
+	resultLimitsFile string
+
+	// resultLimits holds the limits loaded from resultLimitsFile, if any.
+	resultLimits *resultLimitsConfig
+
+	resultLimitsExceeded = stats.NewCountersWithMultiLabels(
+		"VtgateResultLimitsExceeded",
+		"Results exceeding a configured row or byte limit, by the keyspace and user the limit applied to. The users without limits of their own are counted as other",
+		[]string{"Keyspace", "User", "Limit", "Mode"})
+
+	exceedResultLimitsLogger = logutil.NewThrottledLogger("ExceedResultLimits", 1*time.Minute)
+)
+
+// resultLimitsConfig is the format of the --result_limits_file. The limits
+// for a query are resolved field by field: the limits of its user override
+// those of its keyspace, which override the default ones.
+//
+//	{
+//	  "default": {"max_rows": 100000, "max_bytes": 67108864, "mode": "warn"},
+//	  "keyspaces": {"commerce": {"max_rows": 10000}},
+//	  "users": {"reporting": {"max_rows": 1000000, "mode": "enforce"}}
+//	}
+type resultLimitsConfig struct {
+	Default   resultLimitsSpec            `json:"default"`
+	Keyspaces map[string]resultLimitsSpec `json:"keyspaces"`
+	Users     map[string]resultLimitsSpec `json:"users"`
+}
+
+// resultLimitsSpec is one layer of limits. Unset fields inherit from the
+// layer below, and a limit of 0 means unlimited.
+type resultLimitsSpec struct {
+	MaxRows  *uint64 `json:"max_rows,omitempty"`
+	MaxBytes *uint64 `json:"max_bytes,omitempty"`
+	Mode     *string `json:"mode,omitempty"`
+}
+
+func (spec *resultLimitsSpec) validate() error {
+	if spec.Mode == nil {
+		return nil
+	}
+	switch *spec.Mode {
+	case resultLimitWarn, resultLimitEnforce:
+		return nil
+	default:
+		return fmt.Errorf("invalid mode %q, expected %q or %q", *spec.Mode, resultLimitWarn, resultLimitEnforce)
+	}
+}
+
+// loadResultLimits reads the result limits from the given file. An empty
+// path disables the limits.
+func loadResultLimits(path string) (*resultLimitsConfig, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &resultLimitsConfig{}
+	if err := json.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("cannot parse result limits file %v: %w", path, err)
+	}
+
+	if err := config.Default.validate(); err != nil {
+		return nil, fmt.Errorf("default result limits: %w", err)
+	}
+	for keyspace, spec := range config.Keyspaces {
+		if err := spec.validate(); err != nil {
+			return nil, fmt.Errorf("result limits for keyspace %v: %w", keyspace, err)
+		}
+	}
+	for user, spec := range config.Users {
+		if err := spec.validate(); err != nil {
+			return nil, fmt.Errorf("result limits for user %v: %w", user, err)
+		}
+	}
+
+	return config, nil
+}
+
+// otherUsersLabel is the User label of VtgateResultLimitsExceeded for the
+// users without limits of their own, to keep its cardinality bounded by the
+// config.
+const otherUsersLabel = "other"
+
+// resolvedResultLimits are the limits that apply to a single query.
+type resolvedResultLimits struct {
+	keyspace string
+	user     string
+	// userLabel is user if it has limits of its own, otherUsersLabel
+	// otherwise.
+	userLabel string
+	maxRows   uint64
+	maxBytes  uint64
+	mode      string
+}
+
+// forQuery returns the limits that apply to a query by the given user in the
+// given keyspace, or nil if there are none.
+func (config *resultLimitsConfig) forQuery(keyspace, user string) *resolvedResultLimits {
+	if config == nil {
+		return nil
+	}
+
+	limits := &resolvedResultLimits{keyspace: keyspace, user: user, userLabel: otherUsersLabel, mode: resultLimitWarn}
+	apply := func(spec resultLimitsSpec) {
+		if spec.MaxRows != nil {
+			limits.maxRows = *spec.MaxRows
+		}
+		if spec.MaxBytes != nil {
+			limits.maxBytes = *spec.MaxBytes
+		}
+		if spec.Mode != nil {
+			limits.mode = *spec.Mode
+		}
+	}
+
+	apply(config.Default)
+	if spec, ok := config.Keyspaces[keyspace]; ok {
+		apply(spec)
+	}
+	if spec, ok := config.Users[user]; ok {
+		apply(spec)
+		limits.userLabel = user
+	}
+
+	if limits.maxRows == 0 && limits.maxBytes == 0 {
+		return nil
+	}
+	return limits
+}
+
+// resultLimitViolation describes a limit that a result exceeded.
+type resultLimitViolation struct {
+	// limit is either "Rows" or "Bytes".
+	limit   string
+	message string
+}
+
+// check returns the limit that a result with the given number of rows and
+// bytes exceeds, or nil if it exceeds none.
+func (limits *resolvedResultLimits) check(rows, bytes uint64) *resultLimitViolation {
+	switch {
+	case limits == nil:
+		return nil
+	case limits.maxRows != 0 && rows > limits.maxRows:
+		return &resultLimitViolation{
+			limit:   "Rows",
+			message: fmt.Sprintf("result row count %d exceeds the limit of %d", rows, limits.maxRows),
+		}
+	case limits.maxBytes != 0 && bytes > limits.maxBytes:
+		return &resultLimitViolation{
+			limit:   "Bytes",
+			message: fmt.Sprintf("result size of %d bytes exceeds the limit of %d", bytes, limits.maxBytes),
+		}
+	default:
+		return nil
+	}
+}
+
+// enforced returns whether results exceeding the limits are rejected.
+func (limits *resolvedResultLimits) enforced() bool {
+	return limits != nil && limits.mode == resultLimitEnforce
+}
+
+// reject counts a violation and returns the error to fail the query with.
+func (limits *resolvedResultLimits) reject(violation *resultLimitViolation) error {
+	resultLimitsExceeded.Add([]string{limits.keyspace, limits.userLabel, violation.limit, limits.mode}, 1)
+	return vterrors.NewErrorf(vtrpcpb.Code_RESOURCE_EXHAUSTED, vterrors.NetPacketTooLarge, "%s", violation.message)
+}
+
+// warn counts a violation and annotates the result with a warning.
+func (limits *resolvedResultLimits) warn(safeSession *SafeSession, piiSafeSQL string, violation *resultLimitViolation) {
+	resultLimitsExceeded.Add([]string{limits.keyspace, limits.userLabel, violation.limit, limits.mode}, 1)
+
+	warningMsg := fmt.Sprintf("%q: %s", piiSafeSQL, violation.message)
+	exceedResultLimitsLogger.Warningf("%s (keyspace %q, user %q)", warningMsg, limits.keyspace, limits.user)
+	safeSession.RecordWarning(&querypb.QueryWarning{
+		Code:    uint32(sqlerror.EROutOfMemory),
+		Message: warningMsg,
+	})
+}
+
+// resultBytes returns the size of the values in the rows of a result.
+func resultBytes(qr *sqltypes.Result) uint64 {
+	var size uint64
+	for _, row := range qr.Rows {
+		for _, col := range row {
+			size += uint64(col.Len())
+		}
+	}
+	return size
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadResultLimits(t *testing.T) {
+	config, err := loadResultLimits("")
+	require.NoError(t, err)
+	assert.Nil(t, config)
+
+	dir := t.TempDir()
+	write := func(name, contents string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(contents), 0o644))
+		return path
+	}
+
+	_, err = loadResultLimits(filepath.Join(dir, "missing.json"))
+	assert.Error(t, err)
+	_, err = loadResultLimits(write("invalid.json", "{"))
+	assert.ErrorContains(t, err, "cannot parse result limits file")
+	_, err = loadResultLimits(write("mode.json", `{"users": {"u1": {"mode": "reject"}}}`))
+	assert.ErrorContains(t, err, `result limits for user u1: invalid mode "reject"`)
+
+	config, err = loadResultLimits(write("valid.json", `{
+		"default": {"max_rows": 100, "max_bytes": 1000},
+		"keyspaces": {"ks1": {"max_rows": 10, "mode": "enforce"}},
+		"users": {"u1": {"max_rows": 0}, "u2": {"max_bytes": 50, "mode": "warn"}}
+	}`))
+	require.NoError(t, err)
+
+	testCases := []struct {
+		keyspace, user string
+		want           *resolvedResultLimits
+	}{{
+		keyspace: "ks2",
+		want:     &resolvedResultLimits{keyspace: "ks2", userLabel: otherUsersLabel, maxRows: 100, maxBytes: 1000, mode: resultLimitWarn},
+	}, {
+		keyspace: "ks1",
+		want:     &resolvedResultLimits{keyspace: "ks1", userLabel: otherUsersLabel, maxRows: 10, maxBytes: 1000, mode: resultLimitEnforce},
+	}, {
+		keyspace: "ks1",
+		user:     "u1",
+		want:     &resolvedResultLimits{keyspace: "ks1", user: "u1", userLabel: "u1", maxBytes: 1000, mode: resultLimitEnforce},
+	}, {
+		keyspace: "ks2",
+		user:     "u3",
+		want:     &resolvedResultLimits{keyspace: "ks2", user: "u3", userLabel: otherUsersLabel, maxRows: 100, maxBytes: 1000, mode: resultLimitWarn},
+	}, {
+		keyspace: "ks1",
+		user:     "u2",
+		want:     &resolvedResultLimits{keyspace: "ks1", user: "u2", userLabel: "u2", maxRows: 10, maxBytes: 50, mode: resultLimitWarn},
+	}}
+	for _, tc := range testCases {
+		assert.Equal(t, tc.want, config.forQuery(tc.keyspace, tc.user), "keyspace %q, user %q", tc.keyspace, tc.user)
+	}
+
+	config, err = loadResultLimits(write("unlimited.json", `{"users": {"u1": {"mode": "enforce"}}}`))
+	require.NoError(t, err)
+	assert.Nil(t, config.forQuery("ks1", "u1"))
+}
+
+func TestResultLimitsCheck(t *testing.T) {
+	var none *resolvedResultLimits
+	assert.Nil(t, none.check(1000, 1000))
+
+	limits := &resolvedResultLimits{maxRows: 10, maxBytes: 100}
+	assert.Nil(t, limits.check(10, 100))
+
+	violation := limits.check(11, 0)
+	require.NotNil(t, violation)
+	assert.Equal(t, "Rows", violation.limit)
+	assert.Equal(t, "result row count 11 exceeds the limit of 10", violation.message)
+
+	violation = limits.check(1, 101)
+	require.NotNil(t, violation)
+	assert.Equal(t, "Bytes", violation.limit)
+	assert.Equal(t, "result size of 101 bytes exceeds the limit of 100", violation.message)
+}
@@ -114,10 +114,13 @@ type vcursorImpl struct {
 	// A nil value represents that no foreign_key_checks value was provided.
 	fkChecksState       *bool
 	ignoreMaxMemoryRows bool
-	vschema             *vindexes.VSchema
-	vm                  VSchemaOperator
-	semTable            *semantics.SemTable
-	warnShardedOnly     bool // when using sharded only features, a warning will be warnings field
+	// resultLimits are the limits of the result of the query, if any. The
+	// enforced ones are checked against the results of the shards too.
+	resultLimits    *resolvedResultLimits
+	vschema         *vindexes.VSchema
+	vm              VSchemaOperator
+	semTable        *semantics.SemTable
+	warnShardedOnly bool // when using sharded only features, a warning will be warnings field
 
 	warnings []*querypb.QueryWarning // any warnings that are accumulated during the planning phase are stored here
 	pv       plancontext.PlannerVersion
@@ -235,6 +238,15 @@ func (vc *vcursorImpl) ExceedsMaxMemoryRows(numRows int) bool {
 	return !vc.ignoreMaxMemoryRows && numRows > maxMemoryRows
 }
 
+// AggregationSpillConfig returns how aggregations spill the rows they sort to disk.
+func (vc *vcursorImpl) AggregationSpillConfig() engine.SpillConfig {
+	return engine.SpillConfig{
+		Dir:          aggregationSpillDir,
+		MemoryBytes:  aggregationSpillMemoryBytes,
+		MaxDiskBytes: aggregationSpillMaxDiskBytes,
+	}
+}
+
 // SetIgnoreMaxMemoryRows sets the ignoreMaxMemoryRows value.
 func (vc *vcursorImpl) SetIgnoreMaxMemoryRows(ignoreMaxMemoryRows bool) {
 	vc.ignoreMaxMemoryRows = ignoreMaxMemoryRows
@@ -579,6 +591,13 @@ func (vc *vcursorImpl) ExecuteMultiShard(ctx context.Context, primitive engine.P
 
 	qr, errs := vc.executor.ExecuteMultiShard(ctx, primitive, rss, commentedShardQueries(queries, vc.marginComments), vc.safeSession, canAutocommit, vc.ignoreMaxMemoryRows)
 	vc.setRollbackOnPartialExecIfRequired(len(errs) != len(rss), rollbackOnError)
+	if qr != nil && vc.resultLimits.enforced() {
+		// Like --max_memory_rows, the rows of the shards count against the
+		// limits, before vtgate goes on with the plan.
+		if violation := vc.resultLimits.check(uint64(len(qr.Rows)), resultBytes(qr)); violation != nil {
+			return nil, []error{vc.resultLimits.reject(violation)}
+		}
+	}
 
 	return qr, errs
 }
@@ -1234,6 +1253,11 @@ func (vc *vcursorImpl) ThrottleApp(ctx context.Context, throttledAppRule *topoda
 		return lockErr
 	}
 	defer unlock(&err)
+	ctx, unlockRouting, lockErr := vc.topoServer.LockKeyspaceRouting(ctx, req.Keyspace, "UpdateThrottlerConfig")
+	if lockErr != nil {
+		return lockErr
+	}
+	defer unlockRouting(&err)
 
 	ki, err := vc.topoServer.GetKeyspace(ctx, req.Keyspace)
 	if err != nil {
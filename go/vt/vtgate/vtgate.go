@@ -73,6 +73,15 @@ var (
 	maxPayloadSize  int
 	warnPayloadSize int
 
+	// workloadPriorities are the priorities of the queries of workloads
+	// without a PRIORITY directive.
+	workloadPriorities = workloadPrioritiesFlag{}
+
+	// aggregation spill related flags
+	aggregationSpillDir          string
+	aggregationSpillMemoryBytes  int64
+	aggregationSpillMaxDiskBytes int64
+
 	noScatter          bool
 	enableShardRouting bool
 
@@ -128,6 +137,11 @@ func registerFlags(fs *pflag.FlagSet) {
 	fs.Int64Var(&queryPlanCacheMemory, "gate_query_cache_memory", queryPlanCacheMemory, "gate server query cache size in bytes, maximum amount of memory to be cached. vtgate analyzes every incoming query and generate a query plan, these plans are being cached in a lru cache. This config controls the capacity of the lru cache.")
 	fs.IntVar(&maxMemoryRows, "max_memory_rows", maxMemoryRows, "Maximum number of rows that will be held in memory for intermediate results as well as the final result.")
 	fs.IntVar(&warnMemoryRows, "warn_memory_rows", warnMemoryRows, "Warning threshold for in-memory results. A row count higher than this amount will cause the VtGateWarnings.ResultsExceeded counter to be incremented.")
+	fs.Var(&workloadPriorities, "workload-priorities", "A comma-separated list of <workload_name>=<priority>. Queries with a WORKLOAD_NAME directive and no PRIORITY directive are sent to vttablet with the priority of their workload.")
+	fs.Int64Var(&aggregationSpillMemoryBytes, "aggregation_spill_memory_bytes", aggregationSpillMemoryBytes, "Bytes of rows an aggregation can sort in memory before spilling them to temporary files. 0 disables spilling, and the rows are always sorted in memory.")
+	fs.Int64Var(&aggregationSpillMaxDiskBytes, "aggregation_spill_max_disk_bytes", aggregationSpillMaxDiskBytes, "Bytes of rows an aggregation can spill to disk before the query fails. 0 for no limit.")
+	fs.StringVar(&aggregationSpillDir, "aggregation_spill_dir", aggregationSpillDir, "Directory of the temporary files of the aggregations that spill to disk. Defaults to the system temporary directory.")
+	fs.StringVar(&resultLimitsFile, "result_limits_file", resultLimitsFile, "JSON file with the row and byte limits of query results, with per-keyspace and per-user overrides. Results exceeding a limit are either rejected or returned with a warning, and counted in VtgateResultLimitsExceeded.")
 	fs.StringVar(&defaultDDLStrategy, "ddl_strategy", defaultDDLStrategy, "Set default strategy for DDL statements. Override with @@ddl_strategy session variable")
 	fs.StringVar(&dbDDLPlugin, "dbddl_plugin", dbDDLPlugin, "controls how to handle CREATE/DROP DATABASE. use it if you are using your own database provisioning service")
 	fs.BoolVar(&noScatter, "no_scatter", noScatter, "when set to true, the planner will fail instead of producing a plan that includes scatter queries")
@@ -281,6 +295,10 @@ func Init(
 	if _, err := schema.ParseDDLStrategy(defaultDDLStrategy); err != nil {
 		log.Fatalf("Invalid value for -ddl_strategy: %v", err.Error())
 	}
+	var err error
+	if resultLimits, err = loadResultLimits(resultLimitsFile); err != nil {
+		log.Fatalf("Invalid value for -result_limits_file: %v", err)
+	}
 	tc := NewTxConn(gw, getTxMode())
 	// ScatterConn depends on TxConn to perform forced rollbacks.
 	sc := NewScatterConn("VttabletCall", tc, gw)
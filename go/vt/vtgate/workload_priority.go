@@ -0,0 +1,78 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"vitess.io/vitess/go/vt/sqlparser"
+)
+
+// workloadPrioritiesFlag maps the names of workloads, as set by the
+// WORKLOAD_NAME directive, to the priority of their queries. It is set as a
+// comma-separated list of <workload_name>=<priority>.
+type workloadPrioritiesFlag map[string]string
+
+// Set is part of the pflag.Value interface.
+func (f *workloadPrioritiesFlag) Set(s string) error {
+	priorities := workloadPrioritiesFlag{}
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, priority, ok := strings.Cut(part, "=")
+		if !ok || name == "" {
+			return fmt.Errorf("invalid workload priority %q, expected <workload_name>=<priority>", part)
+		}
+		p, err := strconv.Atoi(priority)
+		if err != nil || p < 0 || p > sqlparser.MaxPriorityValue {
+			return fmt.Errorf("invalid priority %q for workload %v, expected an integer between 0 and %d", priority, name, sqlparser.MaxPriorityValue)
+		}
+		priorities[name] = strconv.Itoa(p)
+	}
+	*f = priorities
+	return nil
+}
+
+// String is part of the pflag.Value interface.
+func (f *workloadPrioritiesFlag) String() string {
+	parts := make([]string, 0, len(*f))
+	for name, priority := range *f {
+		parts = append(parts, name+"="+priority)
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ",")
+}
+
+// Type is part of the pflag.Value interface.
+func (f *workloadPrioritiesFlag) Type() string {
+	return "string"
+}
+
+// queryPriority returns the priority of a query: the one of its PRIORITY
+// directive, or else the one of its workload.
+func queryPriority(stmt sqlparser.Statement) (string, error) {
+	priority, err := sqlparser.GetPriorityFromStatement(stmt)
+	if err != nil || priority != "" {
+		return priority, err
+	}
+	return workloadPriorities[sqlparser.GetWorkloadNameFromStatement(stmt)], nil
+}
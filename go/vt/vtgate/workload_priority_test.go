@@ -0,0 +1,48 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/sqlparser"
+)
+
+func TestQueryPriority(t *testing.T) {
+	defer func(old workloadPrioritiesFlag) { workloadPriorities = old }(workloadPriorities)
+	require.NoError(t, workloadPriorities.Set("batch=10,reports=30"))
+	assert.Equal(t, "batch=10,reports=30", workloadPriorities.String())
+	assert.ErrorContains(t, (&workloadPrioritiesFlag{}).Set("batch=101"), "expected an integer between 0 and 100")
+
+	parser := sqlparser.NewTestParser()
+	for query, want := range map[string]string{
+		"select 1":                                          "",
+		"select /*vt+ PRIORITY=20 */ 1":                     "20",
+		"select /*vt+ WORKLOAD_NAME=batch */ 1":             "10",
+		"select /*vt+ WORKLOAD_NAME=oltp */ 1":              "",
+		"select /*vt+ WORKLOAD_NAME=batch PRIORITY=50 */ 1": "50",
+	} {
+		stmt, err := parser.Parse(query)
+		require.NoError(t, err)
+		priority, err := queryPriority(stmt)
+		require.NoError(t, err)
+		assert.Equal(t, want, priority, query)
+	}
+}
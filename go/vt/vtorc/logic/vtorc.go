@@ -17,11 +17,8 @@
 package logic
 
 import (
-	"os"
-	"os/signal"
 	"sync"
 	"sync/atomic"
-	"syscall"
 	"time"
 
 	"github.com/patrickmn/go-cache"
@@ -78,19 +75,12 @@ func instancePollSecondsDuration() time.Duration {
 	return time.Duration(config.Config.InstancePollSeconds) * time.Second
 }
 
-// acceptSighupSignal registers for SIGHUP signal from the OS to reload the configuration files.
-func acceptSighupSignal() {
-	c := make(chan os.Signal, 1)
-
-	signal.Notify(c, syscall.SIGHUP)
-	go func() {
-		for range c {
-			log.Infof("Received SIGHUP. Reloading configuration")
-			_ = inst.AuditOperation("reload-configuration", "", "Triggered via SIGHUP")
-			config.Reload()
-			discoveryMetrics.SetExpirePeriod(time.Duration(config.DiscoveryCollectionRetentionSeconds) * time.Second)
-		}
-	}()
+// reloadConfiguration reloads the configuration files, on SIGHUP.
+func reloadConfiguration() {
+	log.Infof("Received SIGHUP. Reloading configuration")
+	_ = inst.AuditOperation("reload-configuration", "", "Triggered via SIGHUP")
+	config.Reload()
+	discoveryMetrics.SetExpirePeriod(time.Duration(config.DiscoveryCollectionRetentionSeconds) * time.Second)
 }
 
 // closeVTOrc runs all the operations required to cleanly shutdown VTOrc
@@ -288,7 +278,7 @@ func ContinuousDiscovery() {
 	go func() {
 		_ = ometrics.InitMetrics()
 	}()
-	go acceptSighupSignal()
+	servenv.OnSIGHUP(reloadConfiguration)
 	// On termination of the server, we should close VTOrc cleanly
 	servenv.OnTermSync(closeVTOrc)
 
@@ -133,6 +133,16 @@ func (client *FakeTabletManagerClient) GetGlobalStatusVars(ctx context.Context,
 	return make(map[string]string), nil
 }
 
+// GetInFlightQueries is part of the tmclient.TabletManagerClient interface.
+func (client *FakeTabletManagerClient) GetInFlightQueries(ctx context.Context, tablet *topodatapb.Tablet) ([]*tabletmanagerdatapb.InFlightQuery, error) {
+	return nil, nil
+}
+
+// KillInFlightQuery is part of the tmclient.TabletManagerClient interface.
+func (client *FakeTabletManagerClient) KillInFlightQuery(ctx context.Context, tablet *topodatapb.Tablet, id string) error {
+	return nil
+}
+
 // LockTables is part of the tmclient.TabletManagerClient interface.
 func (client *FakeTabletManagerClient) LockTables(ctx context.Context, tablet *topodatapb.Tablet) error {
 	return nil
@@ -358,6 +358,33 @@ func (client *Client) GetGlobalStatusVars(ctx context.Context, tablet *topodatap
 	return response.GetStatusValues(), nil
 }
 
+// GetInFlightQueries is part of the tmclient.TabletManagerClient interface.
+func (client *Client) GetInFlightQueries(ctx context.Context, tablet *topodatapb.Tablet) ([]*tabletmanagerdatapb.InFlightQuery, error) {
+	c, closer, err := client.dialer.dial(ctx, tablet)
+	if err != nil {
+		return nil, err
+	}
+	defer closer.Close()
+	response, err := c.GetInFlightQueries(ctx, &tabletmanagerdatapb.GetInFlightQueriesRequest{})
+	if err != nil {
+		return nil, err
+	}
+	return response.GetQueries(), nil
+}
+
+// KillInFlightQuery is part of the tmclient.TabletManagerClient interface.
+func (client *Client) KillInFlightQuery(ctx context.Context, tablet *topodatapb.Tablet, id string) error {
+	c, closer, err := client.dialer.dial(ctx, tablet)
+	if err != nil {
+		return err
+	}
+	defer closer.Close()
+	_, err = c.KillInFlightQuery(ctx, &tabletmanagerdatapb.KillInFlightQueryRequest{
+		Id: id,
+	})
+	return err
+}
+
 //
 // Various read-write methods
 //
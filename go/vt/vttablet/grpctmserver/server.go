@@ -109,6 +109,28 @@ func (s *server) GetGlobalStatusVars(ctx context.Context, request *tabletmanager
 	return response, err
 }
 
+func (s *server) GetInFlightQueries(ctx context.Context, request *tabletmanagerdatapb.GetInFlightQueriesRequest) (response *tabletmanagerdatapb.GetInFlightQueriesResponse, err error) {
+	defer s.tm.HandleRPCPanic(ctx, "GetInFlightQueries", request, response, false /*verbose*/, &err)
+	ctx = callinfo.GRPCCallInfo(ctx)
+	response = &tabletmanagerdatapb.GetInFlightQueriesResponse{}
+	queries, err := s.tm.GetInFlightQueries(ctx)
+	if err == nil {
+		response.Queries = queries
+	}
+	return response, err
+}
+
+func (s *server) KillInFlightQuery(ctx context.Context, request *tabletmanagerdatapb.KillInFlightQueryRequest) (response *tabletmanagerdatapb.KillInFlightQueryResponse, err error) {
+	defer s.tm.HandleRPCPanic(ctx, "KillInFlightQuery", request, response, true /*verbose*/, &err)
+	ctx = callinfo.GRPCCallInfo(ctx)
+	// The caller is who the static auth plugin authenticated, if any.
+	if username := servenv.StaticAuthUsernameFromContext(ctx); username != "" {
+		ctx = callerid.NewContext(ctx, nil, callerid.NewImmediateCallerID(username))
+	}
+	response = &tabletmanagerdatapb.KillInFlightQueryResponse{}
+	return response, s.tm.KillInFlightQuery(ctx, request.Id)
+}
+
 //
 // Various read-write methods
 //
@@ -0,0 +1,344 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package querydigest implements an optional plugin that samples the queries
+// served by vttablet and writes them out in the MySQL slow query log format,
+// so that existing tooling such as pt-query-digest can be pointed at it.
+//
+// By default only queries targeting REPLICA and RDONLY tablets are sampled,
+// to keep the overhead off the primary.
+package querydigest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/spf13/pflag"
+
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/servenv"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+var (
+	digestFile        string
+	digestTabletTypes       = topoproto.TabletTypeListFlag{topodatapb.TabletType_REPLICA, topodatapb.TabletType_RDONLY}
+	digestSampleRate        = 1.0
+	digestMaxFileSize int64 = 100 * 1024 * 1024
+	digestMaxBackups        = 5
+)
+
+func registerFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&digestFile, "query-digest-file", digestFile, "If set, sample queries to this file in the MySQL slow query log format (readable by pt-query-digest).")
+	fs.Var(&digestTabletTypes, "query-digest-tablet-types", "Comma-separated list of tablet types whose queries are sampled to the query digest log.")
+	fs.Float64Var(&digestSampleRate, "query-digest-sample-rate", digestSampleRate, "Fraction of queries sampled to the query digest log, between 0.0 and 1.0.")
+	fs.Int64Var(&digestMaxFileSize, "query-digest-max-file-size", digestMaxFileSize, "Size in bytes at which the query digest log is rotated. 0 disables rotation.")
+	fs.IntVar(&digestMaxBackups, "query-digest-max-backups", digestMaxBackups, "Number of rotated query digest logs to keep.")
+}
+
+func init() {
+	servenv.OnParseFor("vtcombo", registerFlags)
+	servenv.OnParseFor("vttablet", registerFlags)
+
+	servenv.OnRun(func() {
+		if digestFile == "" {
+			return
+		}
+		s, err := Init(digestFile)
+		if err != nil {
+			log.Errorf("Failed to start query digest sampling to %s: %v", digestFile, err)
+			return
+		}
+		servenv.OnClose(s.Stop)
+
+		f := newFilter(digestTabletTypes, digestSampleRate)
+		tabletenv.StatsLogger.ServeLogs("/debug/querydigest", func(w io.Writer, _ url.Values, val any) error {
+			stats, ok := val.(*tabletenv.LogStats)
+			if !ok || !f.match(stats) {
+				return nil
+			}
+			return WriteEntry(w, stats)
+		})
+	})
+}
+
+// Sampler is an opaque interface used to control query digest sampling.
+type Sampler interface {
+	// Stop sampling and close the underlying file.
+	Stop()
+}
+
+type sampler struct {
+	logChan chan *tabletenv.LogStats
+	out     *rotatingFile
+	filter  *filter
+	done    chan struct{}
+	wg      sync.WaitGroup
+}
+
+func (s *sampler) Stop() {
+	tabletenv.StatsLogger.Unsubscribe(s.logChan)
+	close(s.done)
+	s.wg.Wait()
+	s.out.Close()
+}
+
+// Init starts sampling queries to the given file path, using the process-wide
+// flag settings.
+func Init(path string) (Sampler, error) {
+	out, err := newRotatingFile(path, digestMaxFileSize, digestMaxBackups)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &sampler{
+		logChan: tabletenv.StatsLogger.Subscribe("QueryDigest"),
+		out:     out,
+		filter:  newFilter(digestTabletTypes, digestSampleRate),
+		done:    make(chan struct{}),
+	}
+
+	log.Infof("Sampling query digests for tablet types %v to %s", digestTabletTypes.String(), path)
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		for {
+			select {
+			case <-s.done:
+				return
+			case stats := <-s.logChan:
+				if !s.filter.match(stats) {
+					continue
+				}
+				if err := WriteEntry(s.out, stats); err != nil {
+					log.Warningf("Failed to write query digest entry: %v", err)
+				}
+			}
+		}
+	}()
+
+	return s, nil
+}
+
+// filter decides which queries are sampled.
+type filter struct {
+	tabletTypes map[topodatapb.TabletType]bool
+	sampleRate  float64
+}
+
+func newFilter(tabletTypes []topodatapb.TabletType, sampleRate float64) *filter {
+	f := &filter{
+		tabletTypes: make(map[topodatapb.TabletType]bool, len(tabletTypes)),
+		sampleRate:  sampleRate,
+	}
+	for _, tt := range tabletTypes {
+		f.tabletTypes[tt] = true
+	}
+	return f
+}
+
+func (f *filter) match(stats *tabletenv.LogStats) bool {
+	if stats.Target == nil || !f.tabletTypes[stats.Target.TabletType] {
+		return false
+	}
+	switch {
+	case f.sampleRate <= 0:
+		return false
+	case f.sampleRate >= 1:
+		return true
+	}
+	return rand.Float64() < f.sampleRate
+}
+
+// digestParser parses the queries to compute their digest. The digest only
+// depends on the structure of the queries, so the parser of the default MySQL
+// version does.
+var digestParser = sync.OnceValues(func() (*sqlparser.Parser, error) {
+	return sqlparser.New(sqlparser.Options{})
+})
+
+// Digest returns the digest of a query, the hex SHA-256 of its normalized
+// form: the query is parsed, its literals are replaced by bind variables, its
+// lists of values are folded into one, and its margin comments are dropped,
+// so that the queries which only differ by their values share a digest. The
+// queries which can't be parsed are only normalized by collapsing their
+// whitespace. It is not the DIGEST of performance_schema, which MySQL
+// computes from its own normalization of the statement.
+func Digest(sql string) string {
+	normalized, err := normalizeSQL(sql)
+	if err != nil {
+		normalized = strings.Join(strings.Fields(sql), " ")
+	}
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
+
+// normalizeSQL returns the normalized form of a query, see Digest.
+func normalizeSQL(sql string) (string, error) {
+	parser, err := digestParser()
+	if err != nil {
+		return "", err
+	}
+	sql, _ = sqlparser.SplitMarginComments(sql)
+	stmt, reservedVars, err := parser.Parse2(sql)
+	if err != nil {
+		return "", err
+	}
+	if err := sqlparser.Normalize(stmt, sqlparser.NewReservedVars("v", reservedVars), map[string]*querypb.BindVariable{}); err != nil {
+		return "", err
+	}
+	return sqlparser.String(stmt), nil
+}
+
+// WriteEntry writes a single query in the MySQL slow query log format, with
+// the Percona extensions understood by pt-query-digest. Only the attributes
+// vttablet knows are written: it doesn't know how long the query waited on
+// MySQL's locks, nor how many rows MySQL examined to run it, so there is no
+// Lock_time nor Rows_examined.
+func WriteEntry(w io.Writer, stats *tabletenv.LogStats) error {
+	sql := strings.TrimSuffix(strings.TrimSpace(stats.OriginalSQL), ";")
+	if sql == "" {
+		return nil
+	}
+
+	var (
+		schema     string
+		tabletType string
+	)
+	if stats.Target != nil {
+		schema = stats.Target.Keyspace
+		tabletType = strings.ToLower(stats.Target.TabletType.String())
+	}
+	user := stats.EffectiveCaller()
+	immediate := stats.ImmediateCaller()
+	errno := 0
+	if stats.Error != nil {
+		errno = 1
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Time: %s\n", stats.EndTime.UTC().Format("2006-01-02T15:04:05.000000Z"))
+	fmt.Fprintf(&b, "# User@Host: %s[%s] @ %s []  Id: %d\n", user, immediate, tabletType, stats.TransactionID)
+	fmt.Fprintf(&b, "# Schema: %s  Last_errno: %d  Killed: 0\n", schema, errno)
+	fmt.Fprintf(&b, "# Query_time: %.6f  Rows_sent: %d  Rows_affected: %d\n",
+		stats.TotalTime().Seconds(), len(stats.Rows), stats.RowsAffected)
+	fmt.Fprintf(&b, "# Bytes_sent: %d  Digest: %s\n", stats.SizeOfResponse(), Digest(sql))
+	if schema != "" {
+		fmt.Fprintf(&b, "use %s;\n", schema)
+	}
+	fmt.Fprintf(&b, "SET timestamp=%d;\n", stats.EndTime.Unix())
+	fmt.Fprintf(&b, "%s;\n", sql)
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// rotatingFile is an io.WriteCloser that rotates the underlying file once it
+// exceeds maxSize bytes, keeping at most maxBackups old files around as
+// path.1 (most recent) through path.<maxBackups>.
+type rotatingFile struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+
+	mu   sync.Mutex
+	f    *os.File
+	size int64
+}
+
+func newRotatingFile(path string, maxSize int64, maxBackups int) (*rotatingFile, error) {
+	r := &rotatingFile{
+		path:       path,
+		maxSize:    maxSize,
+		maxBackups: maxBackups,
+	}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *rotatingFile) open() error {
+	f, err := os.OpenFile(r.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	r.f = f
+	r.size = info.Size()
+	return nil
+}
+
+func (r *rotatingFile) rotate() error {
+	if err := r.f.Close(); err != nil {
+		return err
+	}
+	if r.maxBackups <= 0 {
+		if err := os.Remove(r.path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return r.open()
+	}
+	for i := r.maxBackups - 1; i > 0; i-- {
+		if err := os.Rename(fmt.Sprintf("%s.%d", r.path, i), fmt.Sprintf("%s.%d", r.path, i+1)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	if err := os.Rename(r.path, r.path+".1"); err != nil {
+		return err
+	}
+	return r.open()
+}
+
+// Write is part of the io.Writer interface.
+func (r *rotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.maxSize > 0 && r.size > 0 && r.size+int64(len(p)) > r.maxSize {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := r.f.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// Close is part of the io.Closer interface.
+func (r *rotatingFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.f.Close()
+}
+
+var _ io.WriteCloser = (*rotatingFile)(nil)
@@ -0,0 +1,143 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package querydigest
+
+import (
+	"context"
+	"os"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/callerid"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+func TestWriteEntry(t *testing.T) {
+	ctx := callerid.NewContext(context.Background(), callerid.NewEffectiveCallerID("alice", "", ""), callerid.NewImmediateCallerID("vtgate"))
+	start := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	stats := &tabletenv.LogStats{
+		Ctx:          ctx,
+		Target:       &querypb.Target{Keyspace: "commerce", TabletType: topodatapb.TabletType_REPLICA},
+		OriginalSQL:  "select * from  customer where id = :vtg1",
+		StartTime:    start,
+		EndTime:      start.Add(1500 * time.Microsecond),
+		RowsAffected: 0,
+	}
+
+	var b strings.Builder
+	require.NoError(t, WriteEntry(&b, stats))
+	want := `# Time: 2024-01-02T03:04:05.001500Z
+# User@Host: alice[vtgate] @ replica []  Id: 0
+# Schema: commerce  Last_errno: 0  Killed: 0
+# Query_time: 0.001500  Rows_sent: 0  Rows_affected: 0
+# Bytes_sent: 0  Digest: ` + Digest("select * from customer where id = :vtg1") + `
+use commerce;
+SET timestamp=1704164645;
+select * from  customer where id = :vtg1;
+`
+	assert.Equal(t, want, b.String())
+
+}
+
+func TestDigest(t *testing.T) {
+	// The queries which only differ by their values share a digest.
+	assert.Equal(t, Digest("select * from customer where id = 1"), Digest("select * from customer where id = 42"))
+	assert.Equal(t, Digest("select * from customer where name = 'alice'"), Digest("SELECT *\nFROM customer WHERE name='bob'"))
+	assert.Equal(t, Digest("select * from customer where id in (1, 2)"), Digest("select * from customer where id in (3, 4, 5)"))
+	assert.Equal(t, Digest("/* trace 1 */ select 1"), Digest("/* trace 2 */ select 2"))
+	assert.Equal(t, Digest("select * from customer where id = :vtg1"), Digest("select * from  customer where id = :vtg1"))
+
+	assert.NotEqual(t, Digest("select * from customer where id = 1"), Digest("select * from customer where name = 1"))
+	assert.NotEqual(t, Digest("select * from customer"), Digest("select * from corder"))
+
+	// The queries which can't be parsed are only normalized by collapsing
+	// their whitespace.
+	assert.Equal(t, Digest("not a  query 1"), Digest("not a\nquery 1"))
+	assert.NotEqual(t, Digest("not a query 1"), Digest("not a query 2"))
+}
+
+func TestFilter(t *testing.T) {
+	replica := &tabletenv.LogStats{Target: &querypb.Target{TabletType: topodatapb.TabletType_REPLICA}}
+	primary := &tabletenv.LogStats{Target: &querypb.Target{TabletType: topodatapb.TabletType_PRIMARY}}
+	noTarget := &tabletenv.LogStats{}
+
+	f := newFilter([]topodatapb.TabletType{topodatapb.TabletType_REPLICA}, 1)
+	assert.True(t, f.match(replica))
+	assert.False(t, f.match(primary))
+	assert.False(t, f.match(noTarget))
+
+	f = newFilter([]topodatapb.TabletType{topodatapb.TabletType_REPLICA}, 0)
+	assert.False(t, f.match(replica))
+}
+
+func TestRotatingFile(t *testing.T) {
+	p := path.Join(t.TempDir(), "digest.log")
+	r, err := newRotatingFile(p, 10, 2)
+	require.NoError(t, err)
+	defer r.Close()
+
+	for _, s := range []string{"aaaaaaaa\n", "bbbbbbbb\n", "cccccccc\n", "dddddddd\n"} {
+		_, err := r.Write([]byte(s))
+		require.NoError(t, err)
+	}
+
+	read := func(p string) string {
+		data, err := os.ReadFile(p)
+		require.NoError(t, err)
+		return string(data)
+	}
+	assert.Equal(t, "dddddddd\n", read(p))
+	assert.Equal(t, "cccccccc\n", read(p+".1"))
+	assert.Equal(t, "bbbbbbbb\n", read(p+".2"))
+	_, err = os.Stat(p + ".3")
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestSampler(t *testing.T) {
+	p := path.Join(t.TempDir(), "digest.log")
+	s, err := Init(p)
+	require.NoError(t, err)
+
+	tabletenv.StatsLogger.Send(&tabletenv.LogStats{
+		Ctx:         context.Background(),
+		Target:      &querypb.Target{Keyspace: "ks", TabletType: topodatapb.TabletType_PRIMARY},
+		OriginalSQL: "select 'primary'",
+	})
+	tabletenv.StatsLogger.Send(&tabletenv.LogStats{
+		Ctx:         context.Background(),
+		Target:      &querypb.Target{Keyspace: "ks", TabletType: topodatapb.TabletType_RDONLY},
+		OriginalSQL: "select 'rdonly'",
+	})
+
+	require.Eventually(t, func() bool {
+		data, _ := os.ReadFile(p)
+		return strings.Contains(string(data), "select 'rdonly';")
+	}, 5*time.Second, 10*time.Millisecond)
+	s.Stop()
+
+	data, err := os.ReadFile(p)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "select 'primary'")
+}
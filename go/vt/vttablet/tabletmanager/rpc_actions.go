@@ -72,6 +72,17 @@ func (tm *TabletManager) GetGlobalStatusVars(ctx context.Context, variables []st
 	return tm.MysqlDaemon.GetGlobalStatusVars(ctx, variables)
 }
 
+// GetInFlightQueries returns the queries and transactions in progress.
+func (tm *TabletManager) GetInFlightQueries(ctx context.Context) ([]*tabletmanagerdatapb.InFlightQuery, error) {
+	return tm.QueryServiceControl.InFlightQueries(), nil
+}
+
+// KillInFlightQuery kills a query or transaction in progress. The immediate
+// caller of ctx must be allowed to kill it.
+func (tm *TabletManager) KillInFlightQuery(ctx context.Context, id string) error {
+	return tm.QueryServiceControl.KillInFlightQuery(ctx, id)
+}
+
 // SetReadOnly makes the mysql instance read-only or read-write.
 func (tm *TabletManager) SetReadOnly(ctx context.Context, rdonly bool) error {
 	if err := tm.lock(ctx); err != nil {
@@ -47,6 +47,12 @@ type RPCTM interface {
 	// An empty/nil variable name parameter slice means you want all of them.
 	GetGlobalStatusVars(ctx context.Context, variables []string) (map[string]string, error)
 
+	// GetInFlightQueries returns the queries and transactions in progress.
+	GetInFlightQueries(ctx context.Context) ([]*tabletmanagerdatapb.InFlightQuery, error)
+
+	// KillInFlightQuery kills a query or transaction in progress.
+	KillInFlightQuery(ctx context.Context, id string) error
+
 	// Various read-write methods
 
 	SetReadOnly(ctx context.Context, rdonly bool) error
@@ -54,9 +54,9 @@ func init() {
 // and shard record are in sync, this goroutine goes to sleep waiting for
 // something to change in either the tablet state or in the shard record.
 //
-// This goroutine gets woken up for shard record changes by maintaining a
-// topo watch on the shard record. It gets woken up for tablet state changes by
-// a notification signal from setTablet().
+// This goroutine gets woken up for changes of the primary in the shard record
+// by maintaining a topo.ShardsView of the shard. It gets woken up for tablet
+// state changes by a notification signal from setTablet().
 func (tm *TabletManager) shardSyncLoop(ctx context.Context, notifyChan <-chan struct{}, doneChan chan<- struct{}) {
 	defer close(doneChan)
 
@@ -80,16 +80,12 @@ func (tm *TabletManager) shardSyncLoop(ctx context.Context, notifyChan <-chan st
 		case <-retryChan:
 			// It's time to retry a previous failed sync attempt.
 			log.Info("Retry sync")
-		case event := <-shardWatch.watchChan:
-			// Something may have changed in the shard record.
-			// We don't use the watch event except to know that we should
-			// re-read the shard record, and to know if the watch dies.
+		case <-shardWatch.changes:
+			// The primary may have changed in the shard record.
+			// We don't use the change except to know that we should
+			// re-read the shard record. The watch is set again by the
+			// view if it fails.
 			log.Info("Change in shard record")
-			if event.Err != nil {
-				// The watch failed. Stop it so we start a new one if needed.
-				log.Errorf("Shard watch failed: %v", event.Err)
-				shardWatch.stop()
-			}
 		case <-ctx.Done():
 			// Our context was cancelled. Terminate the loop.
 			return
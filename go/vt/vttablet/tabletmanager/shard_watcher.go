@@ -23,26 +23,42 @@ import (
 	"vitess.io/vitess/go/vt/topo"
 )
 
+// shardWatcher watches the shard record of the tablet, to notify the shard
+// sync loop when its primary changes.
 type shardWatcher struct {
-	watchChan   <-chan *topo.WatchShardData
+	// changes receives a value when the primary of the shard record, or
+	// the start of its term, changed. It is nil while the watch is stopped.
+	changes     chan struct{}
+	view        *topo.ShardsView
 	watchCancel context.CancelFunc
 }
 
 func (sw *shardWatcher) active() bool {
-	return sw.watchChan != nil
+	return sw.view != nil
 }
 
 func (sw *shardWatcher) start(ts *topo.Server, keyspace, shard string) error {
 	log.Infof("Starting shard watch of %v/%v", keyspace, shard)
 
 	ctx, cancel := context.WithCancel(context.Background())
-	_, c, err := ts.WatchShard(ctx, keyspace, shard)
+	changes := make(chan struct{}, 1)
+	view, err := ts.WatchShards(ctx, []topo.KeyspaceShard{{Keyspace: keyspace, Shard: shard}}, &topo.ShardsWatchCallbacks{
+		OnPrimaryChange: func(old, new *topo.ShardInfo) {
+			// If a notification is already pending, the loop will see
+			// this change too.
+			select {
+			case changes <- struct{}{}:
+			default:
+			}
+		},
+	})
 	if err != nil {
 		cancel()
 		return err
 	}
 
-	sw.watchChan = c
+	sw.changes = changes
+	sw.view = view
 	sw.watchCancel = cancel
 	return nil
 }
@@ -54,12 +70,10 @@ func (sw *shardWatcher) stop() {
 
 	log.Infof("Stopping shard watch...")
 	sw.watchCancel()
-
-	// Drain all remaining watch events.
-	for range sw.watchChan {
-	}
+	<-sw.view.Done()
 	log.Infof("Shard watch stopped.")
 
-	sw.watchChan = nil
+	sw.changes = nil
+	sw.view = nil
 	sw.watchCancel = nil
 }
@@ -0,0 +1,99 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletmanager
+
+import (
+	"context"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/servenv"
+	"vitess.io/vitess/go/vt/topo"
+)
+
+var (
+	tabletLeaseTTL time.Duration
+
+	statsTabletLeaseRenewals = stats.NewCountersWithSingleLabel("TabletLeaseRenewals", "Number of renewals of the tablet lease in the topo, by result", "result")
+)
+
+func registerTabletLeaseFlags(fs *pflag.FlagSet) {
+	fs.DurationVar(&tabletLeaseTTL, "tablet-lease-ttl", tabletLeaseTTL, "if set, the tablet keeps a lease in the topo that expires after this duration unless renewed, which it renews three times per duration, so tablets whose vttablet is gone can be found. 0 disables the lease.")
+}
+
+func init() {
+	servenv.OnParseFor("vtcombo", registerTabletLeaseFlags)
+	servenv.OnParseFor("vttablet", registerTabletLeaseFlags)
+}
+
+// tabletLeaseLoop renews the lease of the tablet every third of its ttl,
+// until ctx is canceled.
+func (tm *TabletManager) tabletLeaseLoop(ctx context.Context, ttl time.Duration, doneChan chan<- struct{}) {
+	defer close(doneChan)
+
+	ticker := time.NewTicker(ttl / 3)
+	defer ticker.Stop()
+	for {
+		renewCtx, cancel := context.WithTimeout(ctx, topo.RemoteOperationTimeout)
+		err := tm.TopoServer.RenewTabletLease(renewCtx, tm.tabletAlias, tm.Tablet().Hostname, ttl)
+		cancel()
+		if err != nil {
+			log.Warningf("Failed to renew the tablet lease: %v", err)
+			statsTabletLeaseRenewals.Add("Error", 1)
+		} else {
+			statsTabletLeaseRenewals.Add("Success", 1)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (tm *TabletManager) startTabletLease() {
+	if tabletLeaseTTL <= 0 {
+		return
+	}
+
+	tm.mutex.Lock()
+	defer tm.mutex.Unlock()
+	tm._tabletLeaseDone = make(chan struct{})
+	ctx, cancel := context.WithCancel(context.Background())
+	tm._tabletLeaseCancel = cancel
+
+	go tm.tabletLeaseLoop(ctx, tabletLeaseTTL, tm._tabletLeaseDone)
+}
+
+// stopTabletLease stops renewing the tablet lease, which then expires.
+func (tm *TabletManager) stopTabletLease() {
+	tm.mutex.Lock()
+	if tm._tabletLeaseCancel != nil {
+		tm._tabletLeaseCancel()
+	}
+	doneChan := tm._tabletLeaseDone
+	tm.mutex.Unlock()
+
+	// If the lease loop was running, wait for it to fully stop.
+	if doneChan != nil {
+		<-doneChan
+	}
+}
@@ -203,6 +203,14 @@ type TabletManager struct {
 	// in progress
 	_rebuildKeyspaceCancel context.CancelFunc
 
+	// _tabletLeaseDone is a channel for waiting until the tablet lease
+	// goroutine has really finished after _tabletLeaseCancel was called.
+	_tabletLeaseDone chan struct{}
+
+	// _tabletLeaseCancel is the function to stop the background tablet lease
+	// goroutine.
+	_tabletLeaseCancel context.CancelFunc
+
 	// _lockTablesConnection is used to get and release the table read locks to pause replication
 	_lockTablesConnection *dbconnpool.DBConnection
 	_lockTablesTimer      *time.Timer
@@ -402,6 +410,7 @@ func (tm *TabletManager) Start(tablet *topodatapb.Tablet, config *tabletenv.Tabl
 	// The following initializations don't need to be done
 	// in any specific order.
 	tm.startShardSync()
+	tm.startTabletLease()
 	tm.exportStats()
 	servenv.OnRun(tm.registerTabletManager)
 
@@ -440,6 +449,7 @@ func (tm *TabletManager) Close() {
 	// running during lame duck.
 	tm.stopShardSync()
 	tm.stopRebuildKeyspace()
+	tm.stopTabletLease()
 
 	// cleanup initialized fields in the tablet entry
 	f := func(tablet *topodatapb.Tablet) error {
@@ -471,6 +481,7 @@ func (tm *TabletManager) Stop() {
 	// here in addition to in Close() because tests do not call Close().
 	tm.stopShardSync()
 	tm.stopRebuildKeyspace()
+	tm.stopTabletLease()
 
 	if tm.QueryServiceControl != nil {
 		tm.QueryServiceControl.Stats().Stop()
@@ -30,6 +30,7 @@ import (
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/throttle"
 
 	querypb "vitess.io/vitess/go/vt/proto/query"
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
 	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
 )
 
@@ -92,6 +93,13 @@ type Controller interface {
 
 	// CheckThrottler
 	CheckThrottler(ctx context.Context, appName string, flags *throttle.CheckFlags) *throttle.CheckResult
+
+	// InFlightQueries returns the queries and transactions in progress.
+	InFlightQueries() []*tabletmanagerdatapb.InFlightQuery
+
+	// KillInFlightQuery kills a query or transaction in progress, if the
+	// caller of ctx is allowed to.
+	KillInFlightQuery(ctx context.Context, id string) error
 }
 
 // Ensure TabletServer satisfies Controller interface.
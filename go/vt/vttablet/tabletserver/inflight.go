@@ -0,0 +1,342 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/safehtml/template"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"vitess.io/vitess/go/acl"
+	"vitess.io/vitess/go/protoutil"
+	"vitess.io/vitess/go/streamlog"
+	"vitess.io/vitess/go/vt/callerid"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/logz"
+	"vitess.io/vitess/go/vt/tableacl"
+	tacl "vitess.io/vitess/go/vt/tableacl/acl"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
+
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// Kinds of in-flight items.
+const (
+	InFlightQuery       = "query"
+	InFlightStream      = "stream"
+	InFlightTransaction = "transaction"
+	InFlightReserved    = "reserved"
+)
+
+// inFlightTxPrefix prefixes the ids of transactions and reserved
+// connections. The ids of queries are prefixed with their query list name.
+const inFlightTxPrefix = "tx"
+
+// newInFlightKillACL returns the ACL of the callers allowed to kill any
+// in-flight item, or nil if there is none.
+func newInFlightKillACL(config *tabletenv.TabletConfig) tacl.ACL {
+	if config.InFlightKillACL == "" {
+		return nil
+	}
+	f, err := tableacl.GetCurrentACLFactory()
+	if err != nil {
+		log.Infof("Cannot get current ACL Factory: %v", err)
+		return nil
+	}
+	killACL, err := f.New(strings.Split(config.InFlightKillACL, ","))
+	if err != nil {
+		log.Infof("Cannot build in-flight kill ACL: %v", err)
+		return nil
+	}
+	log.Infof("Setting in-flight kill ACL to %v", config.InFlightKillACL)
+	return killACL
+}
+
+// InFlightQueries returns the queries, streams, transactions and reserved
+// connections in progress, sorted by start time.
+func (tsv *TabletServer) InFlightQueries() []*tabletmanagerdatapb.InFlightQuery {
+	now := time.Now()
+	var items []*tabletmanagerdatapb.InFlightQuery
+	for _, ql := range []*QueryList{tsv.statelessql, tsv.statefulql, tsv.olapql} {
+		items = ql.appendInFlight(items, now)
+	}
+	items = tsv.te.txPool.appendInFlight(items, now)
+
+	if streamlog.GetRedactDebugUIQueries() {
+		for _, item := range items {
+			item.Sql, _ = tsv.env.Parser().RedactSQLQuery(item.Sql)
+		}
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return protoutil.TimeFromProto(items[i].StartTime).Before(protoutil.TimeFromProto(items[j].StartTime))
+	})
+	return items
+}
+
+// KillInFlightQuery kills an item returned by InFlightQueries. Unless the
+// immediate caller of ctx is a member of the in-flight kill ACL, it can only
+// kill the items it started.
+func (tsv *TabletServer) KillInFlightQuery(ctx context.Context, id string) error {
+	caller := callerid.ImmediateCallerIDFromContext(ctx)
+	if caller.GetUsername() == "" {
+		return vterrors.Errorf(vtrpcpb.Code_PERMISSION_DENIED, "an authenticated caller is required to kill %v", id)
+	}
+	if tsv.inFlightKillACL == nil || !tsv.inFlightKillACL.IsMember(caller) {
+		var owner *tabletmanagerdatapb.InFlightQuery
+		for _, item := range tsv.InFlightQueries() {
+			if item.Id == id {
+				owner = item
+				break
+			}
+		}
+		if owner == nil {
+			return vterrors.Errorf(vtrpcpb.Code_NOT_FOUND, "no in-flight query %v", id)
+		}
+		if owner.ImmediateCallerId.GetUsername() != caller.GetUsername() {
+			return vterrors.Errorf(vtrpcpb.Code_PERMISSION_DENIED, "%v is not allowed to kill %v of %v", caller.GetUsername(), id, owner.ImmediateCallerId.GetUsername())
+		}
+	}
+	return tsv.killInFlightQuery(id, fmt.Sprintf("killed by %v", caller.GetUsername()))
+}
+
+func (tsv *TabletServer) killInFlightQuery(id, reason string) error {
+	name, num, ok := strings.Cut(id, "/")
+	if !ok {
+		return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid in-flight query id %q", id)
+	}
+	connID, err := strconv.ParseInt(num, 10, 64)
+	if err != nil {
+		return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid in-flight query id %q", id)
+	}
+
+	if name == inFlightTxPrefix {
+		return tsv.te.txPool.Kill(connID, reason)
+	}
+	for _, ql := range []*QueryList{tsv.statelessql, tsv.statefulql, tsv.olapql} {
+		if ql.name == name {
+			if !ql.Terminate(connID) {
+				return vterrors.Errorf(vtrpcpb.Code_NOT_FOUND, "no in-flight query %v", id)
+			}
+			return nil
+		}
+	}
+	return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid in-flight query id %q", id)
+}
+
+// appendInFlight appends the queries of the list to items.
+func (ql *QueryList) appendInFlight(items []*tabletmanagerdatapb.InFlightQuery, now time.Time) []*tabletmanagerdatapb.InFlightQuery {
+	kind := InFlightQuery
+	if ql.name == "olap" {
+		kind = InFlightStream
+	}
+
+	ql.mu.Lock()
+	defer ql.mu.Unlock()
+	for _, qds := range ql.queryDetails {
+		for _, qd := range qds {
+			items = append(items, &tabletmanagerdatapb.InFlightQuery{
+				Id:                fmt.Sprintf("%s/%d", ql.name, qd.connID),
+				Kind:              kind,
+				Sql:               qd.conn.Current(),
+				EffectiveCallerId: callerid.EffectiveCallerIDFromContext(qd.ctx),
+				ImmediateCallerId: callerid.ImmediateCallerIDFromContext(qd.ctx),
+				StartTime:         protoutil.TimeToProto(qd.start),
+				Duration:          protoutil.DurationToProto(now.Sub(qd.start)),
+				Rows:              qd.rows.Load(),
+				ConnectionId:      qd.connID,
+			})
+		}
+	}
+	return items
+}
+
+// appendInFlight appends the transactions and reserved connections of the
+// pool to items.
+func (tp *TxPool) appendInFlight(items []*tabletmanagerdatapb.InFlightQuery, now time.Time) []*tabletmanagerdatapb.InFlightQuery {
+	for _, conn := range mapToTxConn(tp.scp.active.GetAll()) {
+		item := &tabletmanagerdatapb.InFlightQuery{
+			Id:            fmt.Sprintf("%s/%d", inFlightTxPrefix, conn.ConnID),
+			TransactionId: conn.ConnID,
+		}
+		var start time.Time
+		if props := conn.txProps; props != nil {
+			item.Kind = InFlightTransaction
+			item.EffectiveCallerId = props.EffectiveCaller
+			item.ImmediateCallerId = props.ImmediateCaller
+			start = props.StartTime
+		} else if props := conn.reservedProps; props != nil {
+			item.Kind = InFlightReserved
+			item.EffectiveCallerId = props.EffectiveCaller
+			item.ImmediateCallerId = props.ImmediateCaller
+			start = props.StartTime
+		} else {
+			continue
+		}
+		if dbConn := conn.dbConn; dbConn != nil {
+			item.Sql = dbConn.Conn.Current()
+			item.ConnectionId = dbConn.Conn.ID()
+		}
+		item.StartTime = protoutil.TimeToProto(start)
+		item.Duration = protoutil.DurationToProto(now.Sub(start))
+		items = append(items, item)
+	}
+	return items
+}
+
+var (
+	inflightzHeader = []byte(`<thead>
+		<tr>
+			<th>ID</th>
+			<th>Kind</th>
+			<th>Query</th>
+			<th>Caller</th>
+			<th>Duration</th>
+			<th>Start</th>
+			<th>Rows</th>
+			<th>Kill</th>
+		</tr>
+        </thead>
+	`)
+	inflightzTmpl = template.Must(template.New("inflightz").Parse(`
+		<tr>
+			<td>{{.Id}}</td>
+			<td>{{.Kind}}</td>
+			<td>{{.Sql}}</td>
+			<td>{{.Caller}}</td>
+			<td>{{.Duration}}</td>
+			<td>{{.Start}}</td>
+			<td>{{.Rows}}</td>
+			<td><form method='POST' action='inflightz/kill'><input type='hidden' name='id' value='{{.Id}}'><input type='submit' value='Kill'></form></td>
+		</tr>
+	`))
+)
+
+type inflightzRow struct {
+	*tabletmanagerdatapb.InFlightQuery
+	Caller   string
+	Start    string
+	Duration time.Duration
+}
+
+func (tsv *TabletServer) registerInFlightHandlers() {
+	tsv.exporter.HandleFunc("/debug/inflightz", func(w http.ResponseWriter, r *http.Request) {
+		inflightzHandler(tsv, w, r)
+	})
+	tsv.exporter.HandleFunc("/debug/inflightz/kill", func(w http.ResponseWriter, r *http.Request) {
+		inflightzKillHandler(tsv, w, r)
+	})
+}
+
+func inflightzHandler(tsv *TabletServer, w http.ResponseWriter, r *http.Request) {
+	if err := acl.CheckAccessHTTP(r, acl.DEBUGGING); err != nil {
+		acl.SendError(w, err)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, fmt.Sprintf("cannot parse form: %s", err), http.StatusInternalServerError)
+		return
+	}
+
+	items := tsv.InFlightQueries()
+	if r.FormValue("format") == "json" {
+		js, err := protojson.MarshalOptions{UseProtoNames: true, Multiline: true}.Marshal(&tabletmanagerdatapb.GetInFlightQueriesResponse{Queries: items})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(js)
+		return
+	}
+
+	logz.StartHTMLTable(w)
+	defer logz.EndHTMLTable(w)
+	w.Write(inflightzHeader)
+	for _, item := range items {
+		row := inflightzRow{
+			InFlightQuery: item,
+			Caller:        callerid.GetPrincipal(item.EffectiveCallerId),
+			Start:         protoutil.TimeFromProto(item.StartTime).Format(time.RFC3339),
+		}
+		if row.Caller == "" {
+			row.Caller = item.ImmediateCallerId.GetUsername()
+		}
+		row.Duration, _, _ = protoutil.DurationFromProto(item.Duration)
+		if err := inflightzTmpl.Execute(w, row); err != nil {
+			log.Errorf("inflightz: couldn't execute template: %v", err)
+		}
+	}
+}
+
+// inflightzKillHandler kills an in-flight item on a POST from an ADMIN. When
+// the in-flight kill ACL is set, the caller must also be allowed to kill the
+// item by KillInFlightQuery, which needs it to be authenticated by a verified
+// TLS client certificate.
+func inflightzKillHandler(tsv *TabletServer, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "in-flight queries are killed with a POST", http.StatusMethodNotAllowed)
+		return
+	}
+	if err := acl.CheckAccessHTTP(r, acl.ADMIN); err != nil {
+		acl.SendError(w, err)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, fmt.Sprintf("cannot parse form: %s", err), http.StatusInternalServerError)
+		return
+	}
+
+	id := r.FormValue("id")
+	var err error
+	if tsv.inFlightKillACL == nil {
+		err = tsv.killInFlightQuery(id, "killed from /debug/inflightz")
+	} else {
+		ctx := r.Context()
+		if username := httpClientCertName(r); username != "" {
+			ctx = callerid.NewContext(ctx, nil, callerid.NewImmediateCallerID(username))
+		}
+		err = tsv.KillInFlightQuery(ctx, id)
+	}
+	switch {
+	case vterrors.Code(err) == vtrpcpb.Code_PERMISSION_DENIED:
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	http.Redirect(w, r, "/debug/inflightz", http.StatusFound)
+}
+
+// httpClientCertName returns the common name of the verified TLS client
+// certificate of r, if any.
+func httpClientCertName(r *http.Request) string {
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+		return ""
+	}
+	return r.TLS.VerifiedChains[0][0].Subject.CommonName
+}
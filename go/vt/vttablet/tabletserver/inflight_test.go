@@ -0,0 +1,190 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/callerid"
+	"vitess.io/vitess/go/vt/tableacl"
+	"vitess.io/vitess/go/vt/tableacl/simpleacl"
+	"vitess.io/vitess/go/vt/vtenv"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+func callerContext(ctx context.Context, username string) context.Context {
+	return callerid.NewContext(ctx, callerid.NewEffectiveCallerID(username, "", ""), callerid.NewImmediateCallerID(username))
+}
+
+func TestInFlightQueries(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	aclName := fmt.Sprintf("simpleacl-test-%d", rand.Int64())
+	tableacl.Register(aclName, &simpleacl.Factory{})
+	tableacl.SetDefaultACL(aclName)
+	cfg := tabletenv.NewDefaultConfig()
+	cfg.InFlightKillACL = "admin"
+	db, tsv := setupTabletServerTestCustom(t, ctx, cfg, "", vtenv.NewTestEnv())
+	defer tsv.StopService()
+	defer db.Close()
+	// The kill counters are shared by the tests of the package.
+	defer tsv.stats.KillCounters.ResetAll()
+
+	target := querypb.Target{TabletType: topodatapb.TabletType_PRIMARY}
+	state, err := tsv.Begin(callerContext(ctx, "alice"), &target, nil)
+	require.NoError(t, err)
+
+	conn := &testConn{id: 42, query: "select sleep(10)"}
+	qd := NewQueryDetail(callerContext(ctx, "bob"), conn)
+	qd.rows.Add(3)
+	require.NoError(t, tsv.olapql.Add(qd))
+	defer tsv.olapql.Remove(qd)
+
+	items := tsv.InFlightQueries()
+	require.Len(t, items, 2)
+	byID := make(map[string]int)
+	for i, item := range items {
+		byID[item.Id] = i
+	}
+	txID := fmt.Sprintf("tx/%d", state.TransactionID)
+	require.Contains(t, byID, txID)
+	require.Contains(t, byID, "olap/42")
+
+	tx := items[byID[txID]]
+	assert.Equal(t, InFlightTransaction, tx.Kind)
+	assert.Equal(t, "alice", tx.ImmediateCallerId.Username)
+	assert.Equal(t, state.TransactionID, tx.TransactionId)
+
+	stream := items[byID["olap/42"]]
+	assert.Equal(t, InFlightStream, stream.Kind)
+	assert.Equal(t, "select sleep(10)", stream.Sql)
+	assert.Equal(t, "bob", stream.EffectiveCallerId.Principal)
+	assert.EqualValues(t, 3, stream.Rows)
+
+	// Callers can only kill what they started, unless they are in the ACL.
+	err = tsv.KillInFlightQuery(callerContext(ctx, "bob"), txID)
+	assert.Equal(t, vtrpcpb.Code_PERMISSION_DENIED, vterrors.Code(err))
+	err = tsv.KillInFlightQuery(ctx, "olap/42")
+	assert.Equal(t, vtrpcpb.Code_PERMISSION_DENIED, vterrors.Code(err))
+	err = tsv.KillInFlightQuery(callerContext(ctx, "bob"), "olap/43")
+	assert.Equal(t, vtrpcpb.Code_NOT_FOUND, vterrors.Code(err))
+	assert.False(t, conn.killed)
+
+	require.NoError(t, tsv.KillInFlightQuery(callerContext(ctx, "admin"), "olap/42"))
+	assert.True(t, conn.killed)
+
+	require.NoError(t, tsv.KillInFlightQuery(callerContext(ctx, "alice"), txID))
+	_, err = tsv.Commit(ctx, &target, state.TransactionID)
+	require.Error(t, err)
+	assert.Len(t, tsv.InFlightQueries(), 1)
+}
+
+func TestInFlightzHandler(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	db, tsv := setupTabletServerTest(t, ctx, "")
+	defer tsv.StopService()
+	defer db.Close()
+
+	conn := &testConn{id: 7, query: "select * from test_table"}
+	qd := NewQueryDetail(callerContext(ctx, "bob"), conn)
+	require.NoError(t, tsv.statelessql.Add(qd))
+	defer tsv.statelessql.Remove(qd)
+
+	req := httptest.NewRequest(http.MethodGet, "/debug/inflightz", nil)
+	resp := httptest.NewRecorder()
+	inflightzHandler(tsv, resp, req)
+	body := resp.Body.String()
+	assert.Contains(t, body, "oltp-stateless/7")
+	assert.Contains(t, body, "select * from test_table")
+	assert.Contains(t, body, "bob")
+
+	req = httptest.NewRequest(http.MethodGet, "/debug/inflightz?format=json", nil)
+	resp = httptest.NewRecorder()
+	inflightzHandler(tsv, resp, req)
+	assert.Equal(t, "application/json", resp.Header().Get("Content-Type"))
+	assert.Contains(t, resp.Body.String(), `"id": "oltp-stateless/7"`)
+
+	// Kills are only made with a POST.
+	req = httptest.NewRequest(http.MethodGet, "/debug/inflightz/kill?id=oltp-stateless/7", nil)
+	resp = httptest.NewRecorder()
+	inflightzKillHandler(tsv, resp, req)
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.Code)
+	assert.False(t, conn.killed)
+
+	req = httptest.NewRequest(http.MethodPost, "/debug/inflightz/kill?id=oltp-stateless/7", nil)
+	resp = httptest.NewRecorder()
+	inflightzKillHandler(tsv, resp, req)
+	assert.Equal(t, http.StatusFound, resp.Code)
+	assert.True(t, conn.killed)
+
+	req = httptest.NewRequest(http.MethodPost, "/debug/inflightz/kill?id=nonsense", nil)
+	resp = httptest.NewRecorder()
+	inflightzKillHandler(tsv, resp, req)
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
+
+func TestInFlightzKillHandlerACL(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	aclName := fmt.Sprintf("simpleacl-test-%d", rand.Int64())
+	tableacl.Register(aclName, &simpleacl.Factory{})
+	tableacl.SetDefaultACL(aclName)
+	cfg := tabletenv.NewDefaultConfig()
+	cfg.InFlightKillACL = "admin"
+	db, tsv := setupTabletServerTestCustom(t, ctx, cfg, "", vtenv.NewTestEnv())
+	defer tsv.StopService()
+	defer db.Close()
+
+	conn := &testConn{id: 7, query: "select * from test_table"}
+	qd := NewQueryDetail(callerContext(ctx, "bob"), conn)
+	require.NoError(t, tsv.statelessql.Add(qd))
+	defer tsv.statelessql.Remove(qd)
+
+	// With the kill ACL set, the caller must be authenticated, and allowed
+	// to kill the query.
+	kill := func(commonName string) int {
+		req := httptest.NewRequest(http.MethodPost, "/debug/inflightz/kill?id=oltp-stateless/7", nil)
+		if commonName != "" {
+			req.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{{Subject: pkix.Name{CommonName: commonName}}}}}
+		}
+		resp := httptest.NewRecorder()
+		inflightzKillHandler(tsv, resp, req)
+		return resp.Code
+	}
+	assert.Equal(t, http.StatusForbidden, kill(""))
+	assert.Equal(t, http.StatusForbidden, kill("alice"))
+	assert.False(t, conn.killed)
+	assert.Equal(t, http.StatusFound, kill("admin"))
+	assert.True(t, conn.killed)
+}
@@ -832,14 +832,15 @@ func (qre *QueryExecutor) generateFinalSQL(parsedQuery *sqlparser.ParsedQuery, b
 		qre.marginComments.Leading = buf.String()
 	}
 
+	sql := qre.addPriorityHints(query)
 	if qre.marginComments.Leading == "" && qre.marginComments.Trailing == "" {
-		return query, query, nil
+		return sql, query, nil
 	}
 
 	var buf strings.Builder
-	buf.Grow(len(qre.marginComments.Leading) + len(query) + len(qre.marginComments.Trailing))
+	buf.Grow(len(qre.marginComments.Leading) + len(sql) + len(qre.marginComments.Trailing))
 	buf.WriteString(qre.marginComments.Leading)
-	buf.WriteString(query)
+	buf.WriteString(sql)
 	buf.WriteString(qre.marginComments.Trailing)
 	return buf.String(), query, nil
 }
@@ -1097,8 +1098,10 @@ func (qre *QueryExecutor) execStatefulConn(conn *StatefulConnection, sql string,
 func (qre *QueryExecutor) execStreamSQL(conn *connpool.PooledConn, isTransaction bool, sql string, callback func(*sqltypes.Result) error) error {
 	span, ctx := trace.NewSpan(qre.ctx, "QueryExecutor.execStreamSQL")
 	trace.AnnotateSQL(span, sqlparser.Preview(sql))
+	qd := NewQueryDetail(qre.logStats.Ctx, conn.Conn)
 	callBackClosingSpan := func(result *sqltypes.Result) error {
 		defer span.Finish()
+		qd.rows.Add(uint64(len(result.Rows)))
 		return callback(result)
 	}
 
@@ -1110,7 +1113,6 @@ func (qre *QueryExecutor) execStreamSQL(conn *connpool.PooledConn, isTransaction
 	// weren't getting cleaned up during unserveCommon>terminateAllQueries in state_manager.go.
 	// This change will ensure that long-running streaming stateful queries get gracefully shutdown during ServingTypeChange
 	// once their grace period is over.
-	qd := NewQueryDetail(qre.logStats.Ctx, conn.Conn)
 	if isTransaction {
 		err := qre.tsv.statefulql.Add(qd)
 		if err != nil {
@@ -20,6 +20,7 @@ import (
 	"context"
 	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/safehtml"
@@ -38,6 +39,8 @@ type QueryDetail struct {
 	conn   killable
 	connID int64
 	start  time.Time
+	// rows is the number of rows streamed so far.
+	rows atomic.Uint64
 }
 
 type killable interface {
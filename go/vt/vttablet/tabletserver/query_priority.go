@@ -0,0 +1,115 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"fmt"
+	"strings"
+
+	p "vitess.io/vitess/go/vt/vttablet/tabletserver/planbuilder"
+)
+
+// addPriorityHints adds the optimizer hints that make mysqld run the query
+// with the resources of its priority: the RESOURCE_GROUP of the priority
+// and, for SELECTs which don't set their own, its MAX_EXECUTION_TIME.
+func (qre *QueryExecutor) addPriorityHints(query string) string {
+	groups := qre.tsv.config.PriorityResourceGroups
+	times := qre.tsv.config.PriorityMaxExecutionTimes
+	if (len(groups) == 0 && len(times) == 0) || qre.plan == nil {
+		return query
+	}
+
+	// Optimizer hints must directly follow the keyword that starts the
+	// statement, so the other statements, and the statements which start
+	// with another keyword, like the SELECTs with a WITH clause, are left
+	// alone.
+	var keywords []string
+	isSelect := false
+	switch qre.plan.PlanID {
+	case p.PlanSelect, p.PlanSelectImpossible, p.PlanSelectLockFunc:
+		keywords = []string{"select"}
+		isSelect = true
+	case p.PlanInsert:
+		keywords = []string{"insert", "replace"}
+	case p.PlanUpdate, p.PlanUpdateLimit:
+		keywords = []string{"update"}
+	case p.PlanDelete, p.PlanDeleteLimit:
+		keywords = []string{"delete"}
+	default:
+		return query
+	}
+	n := statementKeywordLen(query, keywords)
+	if n == 0 {
+		return query
+	}
+
+	priority := qre.tsv.getPriorityFromOptions(qre.options)
+	var hints []string
+	if group := groups.For(priority); group != "" {
+		hints = append(hints, fmt.Sprintf("RESOURCE_GROUP(%s)", group))
+		qre.tsv.stats.PriorityResourceGroupQueries.Add(group, 1)
+	}
+	if isSelect && !hasOptimizerHint(query, n, "MAX_EXECUTION_TIME") {
+		if d := times.For(priority); d > 0 {
+			hints = append(hints, fmt.Sprintf("MAX_EXECUTION_TIME(%d)", d.Milliseconds()))
+		}
+	}
+	if len(hints) == 0 {
+		return query
+	}
+	return addOptimizerHints(query, n, strings.Join(hints, " "))
+}
+
+// statementKeywordLen returns the length of the keyword the query starts
+// with, if it is one of keywords, or 0.
+func statementKeywordLen(query string, keywords []string) int {
+	for _, keyword := range keywords {
+		if len(query) > len(keyword) && strings.EqualFold(query[:len(keyword)], keyword) && query[len(keyword)] == ' ' {
+			return len(keyword)
+		}
+	}
+	return 0
+}
+
+// optimizerHintComment returns the optimizer hint comment that follows the
+// first n bytes of the query, without its delimiters, or "" if there is none.
+func optimizerHintComment(query string, n int) string {
+	rest, ok := strings.CutPrefix(strings.TrimLeft(query[n:], " "), "/*+")
+	if !ok {
+		return ""
+	}
+	comment, _, _ := strings.Cut(rest, "*/")
+	return comment
+}
+
+// hasOptimizerHint returns whether the optimizer hint comment that follows
+// the first n bytes of the query has the hint named name.
+func hasOptimizerHint(query string, n int, name string) bool {
+	return strings.Contains(strings.ToUpper(optimizerHintComment(query, n)), strings.ToUpper(name)+"(")
+}
+
+// addOptimizerHints adds hints to the optimizer hint comment that follows
+// the first n bytes of the query, creating it if there is none. mysqld only
+// reads the first hint comment of a statement, and the first of conflicting
+// hints, so the hints are added ahead of those of the query.
+func addOptimizerHints(query string, n int, hints string) string {
+	rest := query[n:]
+	if trimmed := strings.TrimLeft(rest, " "); strings.HasPrefix(trimmed, "/*+") {
+		return query[:n] + " /*+ " + hints + " " + strings.TrimLeft(trimmed[len("/*+"):], " ")
+	}
+	return query[:n] + " /*+ " + hints + " */" + rest
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+)
+
+func TestAddOptimizerHints(t *testing.T) {
+	assert.Equal(t, "select /*+ RESOURCE_GROUP(low) */ * from t", addOptimizerHints("select * from t", 6, "RESOURCE_GROUP(low)"))
+	assert.Equal(t, "select /*+ RESOURCE_GROUP(low) SET_VAR(sort_buffer_size = 16M) */ * from t", addOptimizerHints("select /*+ SET_VAR(sort_buffer_size = 16M) */ * from t", 6, "RESOURCE_GROUP(low)"))
+
+	assert.True(t, hasOptimizerHint("select /*+ max_execution_time(10) */ * from t", 6, "MAX_EXECUTION_TIME"))
+	assert.False(t, hasOptimizerHint("select * from t /*+ MAX_EXECUTION_TIME(10) */", 6, "MAX_EXECUTION_TIME"))
+
+	assert.Equal(t, 6, statementKeywordLen("SELECT * from t", []string{"select"}))
+	assert.Equal(t, 7, statementKeywordLen("replace into t values (1)", []string{"insert", "replace"}))
+	assert.Zero(t, statementKeywordLen("with c as (select 1) select * from c", []string{"select"}))
+	assert.Zero(t, statementKeywordLen("selection", []string{"select"}))
+}
+
+func TestQueryExecutorPriorityHints(t *testing.T) {
+	db := setUpQueryExecutorTest(t)
+	defer db.Close()
+	ctx := context.Background()
+	tsv := newTestTabletServer(ctx, noFlags, db)
+	defer tsv.StopService()
+	require.NoError(t, tsv.config.PriorityResourceGroups.Set("10=batch,50=reporting"))
+	require.NoError(t, tsv.config.PriorityMaxExecutionTimes.Set("10=2s"))
+
+	testcases := []struct {
+		input    string
+		priority string
+		want     string
+	}{{
+		input:    "select * from test_table",
+		priority: "5",
+		want:     "select /*+ RESOURCE_GROUP(batch) MAX_EXECUTION_TIME(2000) */ * from test_table limit 10001",
+	}, {
+		input:    "select * from test_table",
+		priority: "30",
+		want:     "select /*+ RESOURCE_GROUP(reporting) */ * from test_table limit 10001",
+	}, {
+		// Queries without a priority have the default one, which is higher.
+		input: "select * from test_table",
+		want:  "select * from test_table limit 10001",
+	}, {
+		// The query keeps its own MAX_EXECUTION_TIME.
+		input:    "select /*+ MAX_EXECUTION_TIME(500) */ * from test_table",
+		priority: "5",
+		want:     "select /*+ RESOURCE_GROUP(batch) MAX_EXECUTION_TIME(500) */ * from test_table limit 10001",
+	}, {
+		input:    "delete from test_table where pk = 1",
+		priority: "5",
+		want:     "delete /*+ RESOURCE_GROUP(batch) */ from test_table where pk = 1 limit 10001",
+	}}
+	for _, tcase := range testcases {
+		t.Run(tcase.input+"/"+tcase.priority, func(t *testing.T) {
+			db.AddQuery(tcase.want, &sqltypes.Result{})
+			qre := newTestQueryExecutor(ctx, tsv, tcase.input, 0)
+			qre.options = &querypb.ExecuteOptions{Priority: tcase.priority}
+			_, err := qre.Execute()
+			require.NoError(t, err)
+			assert.Contains(t, qre.logStats.RewrittenSQL(), tcase.want)
+		})
+	}
+	assert.EqualValues(t, 3, tsv.stats.PriorityResourceGroupQueries.Counts()["batch"])
+}
@@ -150,6 +150,7 @@ func registerTabletEnvFlags(fs *pflag.FlagSet) {
 	fs.BoolVar(&currentConfig.StrictTableACL, "queryserver-config-strict-table-acl", defaultConfig.StrictTableACL, "only allow queries that pass table acl checks")
 	fs.BoolVar(&currentConfig.EnableTableACLDryRun, "queryserver-config-enable-table-acl-dry-run", defaultConfig.EnableTableACLDryRun, "If this flag is enabled, tabletserver will emit monitoring metrics and let the request pass regardless of table acl check results")
 	fs.StringVar(&currentConfig.TableACLExemptACL, "queryserver-config-acl-exempt-acl", defaultConfig.TableACLExemptACL, "an acl that exempt from table acl checking (this acl is free to access any vitess tables).")
+	fs.StringVar(&currentConfig.InFlightKillACL, "queryserver-config-inflight-kill-acl", defaultConfig.InFlightKillACL, "a comma-separated acl of the callers allowed to kill any in-flight query or transaction. Other callers can only kill their own.")
 	fs.BoolVar(&currentConfig.TerseErrors, "queryserver-config-terse-errors", defaultConfig.TerseErrors, "prevent bind vars from escaping in client error messages")
 	fs.IntVar(&currentConfig.TruncateErrorLen, "queryserver-config-truncate-error-len", defaultConfig.TruncateErrorLen, "truncate errors sent to client if they are longer than this value (0 means do not truncate)")
 	fs.BoolVar(&currentConfig.AnnotateQueries, "queryserver-config-annotate-queries", defaultConfig.AnnotateQueries, "prefix queries to MySQL backend with comment indicating vtgate principal (user) and target tablet type")
@@ -168,6 +169,9 @@ func registerTabletEnvFlags(fs *pflag.FlagSet) {
 	fs.BoolVar(&currentConfig.TxThrottlerDryRun, "tx-throttler-dry-run", defaultConfig.TxThrottlerDryRun, "If present, the transaction throttler only records metrics about requests received and throttled, but does not actually throttle any requests.")
 	fs.DurationVar(&currentConfig.TxThrottlerTopoRefreshInterval, "tx-throttler-topo-refresh-interval", time.Minute*5, "The rate that the transaction throttler will refresh the topology to find cells.")
 
+	fs.Var(&currentConfig.PriorityResourceGroups, "queryserver-priority-resource-groups", "A comma-separated list of <max_priority>=<resource_group>. Queries whose priority, from the PRIORITY directive or --tx-throttler-default-priority, is at most max_priority run in that MySQL resource group, with a RESOURCE_GROUP optimizer hint. The resource groups must exist in MySQL.")
+	fs.Var(&currentConfig.PriorityMaxExecutionTimes, "queryserver-priority-max-execution-times", "A comma-separated list of <max_priority>=<duration>. SELECTs whose priority is at most max_priority are stopped by MySQL after that duration, with a MAX_EXECUTION_TIME optimizer hint.")
+
 	fs.BoolVar(&enableHotRowProtection, "enable_hot_row_protection", false, "If true, incoming transactions for the same row (range) will be queued and cannot consume all txpool slots.")
 	fs.BoolVar(&enableHotRowProtectionDryRun, "enable_hot_row_protection_dry_run", false, "If true, hot row protection is not enforced but logs if transactions would have been queued.")
 	fs.IntVar(&currentConfig.HotRowProtection.MaxQueueSize, "hot_row_protection_max_queue_size", defaultConfig.HotRowProtection.MaxQueueSize, "Maximum number of BeginExecute RPCs which will be queued for the same row (range).")
@@ -337,6 +341,7 @@ type TabletConfig struct {
 	StrictTableACL          bool    `json:"-"`
 	EnableTableACLDryRun    bool    `json:"-"`
 	TableACLExemptACL       string  `json:"-"`
+	InFlightKillACL         string  `json:"-"`
 	TwoPCEnable             bool    `json:"-"`
 	TwoPCCoordinatorAddress string  `json:"-"`
 	TwoPCAbandonAge         Seconds `json:"-"`
@@ -349,6 +354,9 @@ type TabletConfig struct {
 	TxThrottlerTopoRefreshInterval time.Duration                 `json:"-"`
 	TxThrottlerDryRun              bool                          `json:"-"`
 
+	PriorityResourceGroups    PriorityResourceGroups    `json:"-"`
+	PriorityMaxExecutionTimes PriorityMaxExecutionTimes `json:"-"`
+
 	EnableTableGC bool `json:"-"` // can be turned off programmatically by tests
 
 	TransactionLimitConfig `json:"-"`
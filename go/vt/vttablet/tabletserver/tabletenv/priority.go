@@ -0,0 +1,171 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletenv
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"vitess.io/vitess/go/vt/sqlparser"
+)
+
+// PriorityResourceGroup is the MySQL resource group the queries run in when
+// their priority is at most MaxPriority.
+type PriorityResourceGroup struct {
+	MaxPriority int
+	Name        string
+}
+
+// PriorityResourceGroups maps query priorities to MySQL resource groups.
+// It is set as a comma-separated list of <max_priority>=<resource_group>.
+type PriorityResourceGroups []PriorityResourceGroup
+
+var resourceGroupNameRegexp = regexp.MustCompile(`^[A-Za-z0-9_]{1,64}$`)
+
+// Set is part of the pflag.Value interface.
+func (groups *PriorityResourceGroups) Set(s string) error {
+	var parsed PriorityResourceGroups
+	err := parsePriorityThresholds(s, func(maxPriority int, value string) error {
+		if !resourceGroupNameRegexp.MatchString(value) {
+			return fmt.Errorf("invalid resource group name %q", value)
+		}
+		parsed = append(parsed, PriorityResourceGroup{MaxPriority: maxPriority, Name: value})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	sort.Slice(parsed, func(i, j int) bool { return parsed[i].MaxPriority < parsed[j].MaxPriority })
+	*groups = parsed
+	return nil
+}
+
+// String is part of the pflag.Value interface.
+func (groups *PriorityResourceGroups) String() string {
+	parts := make([]string, 0, len(*groups))
+	for _, group := range *groups {
+		parts = append(parts, fmt.Sprintf("%d=%s", group.MaxPriority, group.Name))
+	}
+	return strings.Join(parts, ",")
+}
+
+// Type is part of the pflag.Value interface.
+func (groups *PriorityResourceGroups) Type() string {
+	return "string"
+}
+
+// For returns the resource group of the queries of the given priority, or
+// "" if they run in the default one.
+func (groups PriorityResourceGroups) For(priority int) string {
+	for _, group := range groups {
+		if priority <= group.MaxPriority {
+			return group.Name
+		}
+	}
+	return ""
+}
+
+// PriorityMaxExecutionTime is the maximum execution time of the SELECT
+// queries whose priority is at most MaxPriority.
+type PriorityMaxExecutionTime struct {
+	MaxPriority      int
+	MaxExecutionTime time.Duration
+}
+
+// PriorityMaxExecutionTimes maps query priorities to the maximum execution
+// time mysqld lets their SELECTs run for. It is set as a comma-separated
+// list of <max_priority>=<duration>.
+type PriorityMaxExecutionTimes []PriorityMaxExecutionTime
+
+// Set is part of the pflag.Value interface.
+func (times *PriorityMaxExecutionTimes) Set(s string) error {
+	var parsed PriorityMaxExecutionTimes
+	err := parsePriorityThresholds(s, func(maxPriority int, value string) error {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		if d < time.Millisecond {
+			return fmt.Errorf("max execution time %v is less than 1ms", d)
+		}
+		parsed = append(parsed, PriorityMaxExecutionTime{MaxPriority: maxPriority, MaxExecutionTime: d})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	sort.Slice(parsed, func(i, j int) bool { return parsed[i].MaxPriority < parsed[j].MaxPriority })
+	*times = parsed
+	return nil
+}
+
+// String is part of the pflag.Value interface.
+func (times *PriorityMaxExecutionTimes) String() string {
+	parts := make([]string, 0, len(*times))
+	for _, t := range *times {
+		parts = append(parts, fmt.Sprintf("%d=%v", t.MaxPriority, t.MaxExecutionTime))
+	}
+	return strings.Join(parts, ",")
+}
+
+// Type is part of the pflag.Value interface.
+func (times *PriorityMaxExecutionTimes) Type() string {
+	return "string"
+}
+
+// For returns the maximum execution time of the SELECTs of the given
+// priority, or 0 if they have none.
+func (times PriorityMaxExecutionTimes) For(priority int) time.Duration {
+	for _, t := range times {
+		if priority <= t.MaxPriority {
+			return t.MaxExecutionTime
+		}
+	}
+	return 0
+}
+
+// parsePriorityThresholds parses a comma-separated list of
+// <max_priority>=<value>, calling add for each of them.
+func parsePriorityThresholds(s string, add func(maxPriority int, value string) error) error {
+	seen := map[int]bool{}
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		priorityStr, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return fmt.Errorf("invalid priority threshold %q, expected <max_priority>=<value>", part)
+		}
+		maxPriority, err := strconv.Atoi(strings.TrimSpace(priorityStr))
+		if err != nil || maxPriority < 0 || maxPriority > sqlparser.MaxPriorityValue {
+			return fmt.Errorf("invalid priority %q in %q, expected an integer between 0 and %d", priorityStr, part, sqlparser.MaxPriorityValue)
+		}
+		if seen[maxPriority] {
+			return fmt.Errorf("priority %d is set more than once", maxPriority)
+		}
+		seen[maxPriority] = true
+		if err := add(maxPriority, strings.TrimSpace(value)); err != nil {
+			return fmt.Errorf("invalid priority threshold %q: %w", part, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletenv
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPriorityResourceGroups(t *testing.T) {
+	var groups PriorityResourceGroups
+	require.NoError(t, groups.Set("50=reporting, 10=batch"))
+	assert.Equal(t, "10=batch,50=reporting", groups.String())
+	assert.Equal(t, "batch", groups.For(0))
+	assert.Equal(t, "batch", groups.For(10))
+	assert.Equal(t, "reporting", groups.For(11))
+	assert.Equal(t, "", groups.For(100))
+
+	assert.ErrorContains(t, groups.Set("101=batch"), "expected an integer between 0 and 100")
+	assert.ErrorContains(t, groups.Set("10=batch,10=reporting"), "priority 10 is set more than once")
+	assert.ErrorContains(t, groups.Set("10=bad-name"), `invalid resource group name "bad-name"`)
+	assert.ErrorContains(t, groups.Set("batch"), "expected <max_priority>=<value>")
+}
+
+func TestPriorityMaxExecutionTimes(t *testing.T) {
+	var times PriorityMaxExecutionTimes
+	require.NoError(t, times.Set("10=1s,30=1m"))
+	assert.Equal(t, "10=1s,30=1m0s", times.String())
+	assert.Equal(t, time.Second, times.For(5))
+	assert.Equal(t, time.Minute, times.For(30))
+	assert.Zero(t, times.For(31))
+
+	assert.ErrorContains(t, times.Set("10=1us"), "less than 1ms")
+}
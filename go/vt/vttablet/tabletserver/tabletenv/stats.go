@@ -48,6 +48,8 @@ type Stats struct {
 	UserReservedCount       *stats.CountersWithSingleLabel // Per CallerID reserved connection counts
 	UserReservedTimesNs     *stats.CountersWithSingleLabel // Per CallerID reserved connection duration
 
+	PriorityResourceGroupQueries *stats.CountersWithSingleLabel // Queries run in a resource group because of their priority
+
 	QueryTimingsByTabletType *servenv.TimingsWrapper // Query timings split by current tablet type
 }
 
@@ -97,6 +99,8 @@ func NewStats(exporter *servenv.Exporter) *Stats {
 		UserReservedCount:       exporter.NewCountersWithSingleLabel("UserReservedCount", "reserved connection received for each CallerID", "CallerID"),
 		UserReservedTimesNs:     exporter.NewCountersWithSingleLabel("UserReservedTimesNs", "Total reserved connection latency for each CallerID", "CallerID"),
 
+		PriorityResourceGroupQueries: exporter.NewCountersWithSingleLabel("PriorityResourceGroupQueries", "Queries run in a MySQL resource group because of their priority", "ResourceGroup"),
+
 		QueryTimingsByTabletType: exporter.NewTimings("QueryTimingsByTabletType", "Query timings broken down by active tablet type", "TabletType"),
 	}
 	stats.QPSRates = exporter.NewRates("QPS", stats.QueryTimings, 15*60/5, 5*time.Second)
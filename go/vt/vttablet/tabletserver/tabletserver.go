@@ -23,13 +23,10 @@ import (
 	"fmt"
 	"math"
 	"net/http"
-	"os"
-	"os/signal"
 	"sort"
 	"strconv"
 	"strings"
 	"sync/atomic"
-	"syscall"
 	"time"
 
 	"vitess.io/vitess/go/acl"
@@ -48,6 +45,7 @@ import (
 	"vitess.io/vitess/go/vt/sqlparser"
 	"vitess.io/vitess/go/vt/srvtopo"
 	"vitess.io/vitess/go/vt/tableacl"
+	tacl "vitess.io/vitess/go/vt/tableacl/acl"
 	"vitess.io/vitess/go/vt/topo"
 	"vitess.io/vitess/go/vt/topo/topoproto"
 	"vitess.io/vitess/go/vt/vtenv"
@@ -125,6 +123,9 @@ type TabletServer struct {
 	// alias is used for identifying this tabletserver in healthcheck responses.
 	alias *topodatapb.TabletAlias
 
+	// inFlightKillACL lists the callers that can kill any in-flight query.
+	inFlightKillACL tacl.ACL
+
 	// This field is only stored for testing
 	checkMysqlGaugeFunc *stats.GaugeFunc
 
@@ -159,6 +160,7 @@ func NewTabletServer(ctx context.Context, env *vtenv.Environment, name string, c
 		env:                    env,
 	}
 	tsv.QueryTimeout.Store(config.Oltp.QueryTimeout.Nanoseconds())
+	tsv.inFlightKillACL = newInFlightKillACL(config)
 
 	srvTopoServer := srvtopo.NewResilientServer(ctx, topoServer, srvTopoCounts)
 
@@ -225,6 +227,7 @@ func NewTabletServer(ctx context.Context, env *vtenv.Environment, name string, c
 	tsv.registerTxlogzHandler()
 	tsv.registerQueryListHandlers([]*QueryList{tsv.statelessql, tsv.statefulql, tsv.olapql})
 	tsv.registerTwopczHandler()
+	tsv.registerInFlightHandlers()
 	tsv.registerMigrationStatusHandler()
 	tsv.registerThrottlerHandlers()
 	tsv.registerDebugEnvHandler()
@@ -354,19 +357,15 @@ func (tsv *TabletServer) initACL(tableACLConfigFile string, enforceTableACLConfi
 func (tsv *TabletServer) InitACL(tableACLConfigFile string, enforceTableACLConfig bool, reloadACLConfigFileInterval time.Duration) {
 	tsv.initACL(tableACLConfigFile, enforceTableACLConfig)
 
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGHUP)
-	go func() {
-		for range sigChan {
-			tsv.initACL(tableACLConfigFile, enforceTableACLConfig)
-		}
-	}()
+	servenv.OnSIGHUP(func() {
+		tsv.initACL(tableACLConfigFile, enforceTableACLConfig)
+	})
 
 	if reloadACLConfigFileInterval != 0 {
 		ticker := time.NewTicker(reloadACLConfigFileInterval)
 		go func() {
 			for range ticker.C {
-				sigChan <- syscall.SIGHUP
+				tsv.initACL(tableACLConfigFile, enforceTableACLConfig)
 			}
 		}()
 	}
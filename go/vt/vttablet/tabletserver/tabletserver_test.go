@@ -26,7 +26,6 @@ import (
 	"os"
 	"strings"
 	"sync"
-	"syscall"
 	"testing"
 	"time"
 
@@ -47,6 +46,7 @@ import (
 	"vitess.io/vitess/go/mysql"
 	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/servenv"
 	"vitess.io/vitess/go/vt/sqlparser"
 	"vitess.io/vitess/go/vt/tableacl"
 	"vitess.io/vitess/go/vt/tableacl/simpleacl"
@@ -2102,8 +2102,7 @@ func TestACLHUP(t *testing.T) {
 	_, err = io.WriteString(f, aclJSON2)
 	require.NoError(t, err)
 
-	syscall.Kill(syscall.Getpid(), syscall.SIGHUP)
-	time.Sleep(100 * time.Millisecond) // wait for signal handler
+	servenv.FireSIGHUPHooks()
 
 	groups2 := tableacl.GetCurrentConfig().TableGroups
 	if len(groups2) != 1 {
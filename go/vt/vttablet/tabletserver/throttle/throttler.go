@@ -141,7 +141,7 @@ const (
 // throttlerTopoService represents the functionality we expect from a TopoServer, abstracted so that
 // it can be mocked in unit tests
 type throttlerTopoService interface {
-	GetTablet(ctx context.Context, alias *topodatapb.TabletAlias) (*topo.TabletInfo, error)
+	GetCachedTablet(ctx context.Context, alias *topodatapb.TabletAlias) (*topo.TabletInfo, error)
 	FindAllTabletAliasesInShard(ctx context.Context, keyspace, shard string) ([]*topodatapb.TabletAlias, error)
 	GetSrvKeyspace(ctx context.Context, cell, keyspace string) (*topodatapb.SrvKeyspace, error)
 }
@@ -600,7 +600,7 @@ func (throttler *Throttler) stimulatePrimaryThrottler(ctx context.Context, tmCli
 		return err
 	}
 	for _, tabletAlias := range tabletAliases {
-		tablet, err := throttler.ts.GetTablet(ctx, tabletAlias)
+		tablet, err := throttler.ts.GetCachedTablet(ctx, tabletAlias)
 		if err != nil {
 			return err
 		}
@@ -997,7 +997,7 @@ func (throttler *Throttler) refreshMySQLInventory(ctx context.Context) error {
 				return err
 			}
 			for _, tabletAlias := range tabletAliases {
-				tablet, err := throttler.ts.GetTablet(ctx, tabletAlias)
+				tablet, err := throttler.ts.GetCachedTablet(ctx, tabletAlias)
 				if err != nil {
 					return err
 				}
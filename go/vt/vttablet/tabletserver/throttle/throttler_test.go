@@ -78,7 +78,7 @@ func (c *fakeTMClient) AppNames() []string {
 type FakeTopoServer struct {
 }
 
-func (ts *FakeTopoServer) GetTablet(ctx context.Context, alias *topodatapb.TabletAlias) (*topo.TabletInfo, error) {
+func (ts *FakeTopoServer) GetCachedTablet(ctx context.Context, alias *topodatapb.TabletAlias) (*topo.TabletInfo, error) {
 	tabletType := topodatapb.TabletType_PRIMARY
 	if alias.Uid != 100 {
 		tabletType = topodatapb.TabletType_REPLICA
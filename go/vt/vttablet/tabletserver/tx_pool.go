@@ -153,6 +153,34 @@ func (tp *TxPool) transactionKiller() {
 	}
 }
 
+// Kill ends the transaction or reserved connection connID. If it is running
+// a statement, its MySQL connection is killed instead, which fails the
+// statement and leaves the connection to be released by its session.
+func (tp *TxPool) Kill(connID tx.ConnID, reason string) error {
+	conn, err := tp.scp.GetAndLock(connID, reason)
+	if err != nil {
+		for _, active := range mapToTxConn(tp.scp.active.GetAll()) {
+			if active.ConnID == connID && active.dbConn != nil {
+				return active.dbConn.Conn.Kill(reason, 0)
+			}
+		}
+		return vterrors.Errorf(vtrpcpb.Code_NOT_FOUND, "transaction %d: %v", connID, err)
+	}
+
+	log.Warningf("killing connection %d (%s): %s", connID, reason, conn.String(tp.env.Config().SanitizeLogMessages, tp.env.Environment().Parser()))
+	if conn.IsInTransaction() {
+		tp.env.Stats().KillCounters.Add("Transactions", 1)
+	} else {
+		tp.env.Stats().KillCounters.Add("ReservedConnection", 1)
+	}
+	conn.Close()
+	if conn.IsInTransaction() {
+		tp.txComplete(conn, tx.TxKill)
+	}
+	conn.Releasef("%s", reason)
+	return nil
+}
+
 // WaitForEmpty waits until all active transactions are completed.
 func (tp *TxPool) WaitForEmpty() {
 	tp.scp.WaitForEmpty()
@@ -33,6 +33,7 @@ import (
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/throttle"
 
 	querypb "vitess.io/vitess/go/vt/proto/query"
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
 	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
 )
 
@@ -221,6 +222,16 @@ func (tqsc *Controller) CheckThrottler(ctx context.Context, appName string, flag
 	return nil
 }
 
+// InFlightQueries is part of the tabletserver.Controller interface
+func (tqsc *Controller) InFlightQueries() []*tabletmanagerdatapb.InFlightQuery {
+	return nil
+}
+
+// KillInFlightQuery is part of the tabletserver.Controller interface
+func (tqsc *Controller) KillInFlightQuery(ctx context.Context, id string) error {
+	return nil
+}
+
 // EnterLameduck implements tabletserver.Controller.
 func (tqsc *Controller) EnterLameduck() {
 	tqsc.mu.Lock()
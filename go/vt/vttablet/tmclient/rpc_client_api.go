@@ -78,6 +78,14 @@ type TabletManagerClient interface {
 	// An empty/nil variable name parameter slice means you want all of them.
 	GetGlobalStatusVars(ctx context.Context, tablet *topodatapb.Tablet, variables []string) (map[string]string, error)
 
+	// GetInFlightQueries returns the queries and transactions in progress
+	// on the tablet.
+	GetInFlightQueries(ctx context.Context, tablet *topodatapb.Tablet) ([]*tabletmanagerdatapb.InFlightQuery, error)
+
+	// KillInFlightQuery kills a query or transaction in progress on the
+	// tablet, by the id returned by GetInFlightQueries.
+	KillInFlightQuery(ctx context.Context, tablet *topodatapb.Tablet, id string) error
+
 	//
 	// Various read-write methods
 	//
@@ -404,6 +404,53 @@ func tmRPCTestGetGlobalStatusVarsPanic(ctx context.Context, t *testing.T, client
 	expectHandleRPCPanic(t, "GetGlobalStatusVars", false /*verbose*/, err)
 }
 
+var testGetInFlightQueriesReply = []*tabletmanagerdatapb.InFlightQuery{{
+	Id:           "oltp-stateless/42",
+	Kind:         "query",
+	Sql:          "select sleep(10)",
+	Rows:         3,
+	ConnectionId: 42,
+}}
+
+func (fra *fakeRPCTM) GetInFlightQueries(ctx context.Context) ([]*tabletmanagerdatapb.InFlightQuery, error) {
+	if fra.panics {
+		panic(fmt.Errorf("test-triggered panic"))
+	}
+	return testGetInFlightQueriesReply, nil
+}
+
+func tmRPCTestGetInFlightQueries(ctx context.Context, t *testing.T, client tmclient.TabletManagerClient, tablet *topodatapb.Tablet) {
+	result, err := client.GetInFlightQueries(ctx, tablet)
+	compareError(t, "GetInFlightQueries", err, result, testGetInFlightQueriesReply)
+}
+
+func tmRPCTestGetInFlightQueriesPanic(ctx context.Context, t *testing.T, client tmclient.TabletManagerClient, tablet *topodatapb.Tablet) {
+	_, err := client.GetInFlightQueries(ctx, tablet)
+	expectHandleRPCPanic(t, "GetInFlightQueries", false /*verbose*/, err)
+}
+
+var testKillInFlightQueryID = "tx/1234"
+
+func (fra *fakeRPCTM) KillInFlightQuery(ctx context.Context, id string) error {
+	if fra.panics {
+		panic(fmt.Errorf("test-triggered panic"))
+	}
+	compare(fra.t, "KillInFlightQuery id", id, testKillInFlightQueryID)
+	return nil
+}
+
+func tmRPCTestKillInFlightQuery(ctx context.Context, t *testing.T, client tmclient.TabletManagerClient, tablet *topodatapb.Tablet) {
+	err := client.KillInFlightQuery(ctx, tablet, testKillInFlightQueryID)
+	if err != nil {
+		t.Errorf("KillInFlightQuery failed: %v", err)
+	}
+}
+
+func tmRPCTestKillInFlightQueryPanic(ctx context.Context, t *testing.T, client tmclient.TabletManagerClient, tablet *topodatapb.Tablet) {
+	err := client.KillInFlightQuery(ctx, tablet, testKillInFlightQueryID)
+	expectHandleRPCPanic(t, "KillInFlightQuery", true /*verbose*/, err)
+}
+
 //
 // Various read-write methods
 //
@@ -1415,6 +1462,8 @@ func Run(t *testing.T, client tmclient.TabletManagerClient, tablet *topodatapb.T
 	tmRPCTestGetSchema(ctx, t, client, tablet)
 	tmRPCTestGetPermissions(ctx, t, client, tablet)
 	tmRPCTestGetGlobalStatusVars(ctx, t, client, tablet)
+	tmRPCTestGetInFlightQueries(ctx, t, client, tablet)
+	tmRPCTestKillInFlightQuery(ctx, t, client, tablet)
 
 	// Various read-write methods
 	tmRPCTestSetReadOnly(ctx, t, client, tablet)
@@ -1476,6 +1525,8 @@ func Run(t *testing.T, client tmclient.TabletManagerClient, tablet *topodatapb.T
 	tmRPCTestGetSchemaPanic(ctx, t, client, tablet)
 	tmRPCTestGetPermissionsPanic(ctx, t, client, tablet)
 	tmRPCTestGetGlobalStatusVarsPanic(ctx, t, client, tablet)
+	tmRPCTestGetInFlightQueriesPanic(ctx, t, client, tablet)
+	tmRPCTestKillInFlightQueryPanic(ctx, t, client, tablet)
 
 	// Various read-write methods
 	tmRPCTestSetReadOnlyPanic(ctx, t, client, tablet)
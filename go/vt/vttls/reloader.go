@@ -0,0 +1,219 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vttls
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/proto/vtrpc"
+	"vitess.io/vitess/go/vt/vterrors"
+)
+
+// CertReloader keeps a client certificate and an optional CA pool loaded
+// from disk, and reloads them whenever the underlying files change. It is
+// meant for long-running clients (such as the topo clients) that must pick
+// up rotated certificates without a process restart.
+//
+// The certificates are handed out through the tls.Config callbacks returned
+// by ClientConfig, so connections established after a reload use the new
+// material while existing connections are left untouched.
+type CertReloader struct {
+	certPath string
+	keyPath  string
+	caPath   string
+
+	mu   sync.RWMutex
+	cert *tls.Certificate
+	pool *x509.CertPool
+	// onReload are called after each reload.
+	onReload []func()
+
+	watcher *fsnotify.Watcher
+	done    chan struct{}
+}
+
+// NewCertReloader loads the given certificate, key and (optional) CA files.
+// If watch is true, the directories holding those files are watched and the
+// files are reloaded on every change. Watching the directories rather than
+// the files themselves lets us follow atomic symlink swaps as performed by
+// cert-manager or Vault agent.
+func NewCertReloader(certPath, keyPath, caPath string, watch bool) (*CertReloader, error) {
+	r := &CertReloader{
+		certPath: certPath,
+		keyPath:  keyPath,
+		caPath:   caPath,
+		done:     make(chan struct{}),
+	}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	if !watch {
+		return r, nil
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	dirs := map[string]bool{}
+	for _, p := range []string{certPath, keyPath, caPath} {
+		if p == "" {
+			continue
+		}
+		dir := filepath.Dir(p)
+		if dirs[dir] {
+			continue
+		}
+		dirs[dir] = true
+		if err := watcher.Add(dir); err != nil {
+			watcher.Close()
+			return nil, err
+		}
+	}
+	r.watcher = watcher
+	go r.watch()
+	return r, nil
+}
+
+func (r *CertReloader) watch() {
+	for {
+		select {
+		case <-r.done:
+			return
+		case _, ok := <-r.watcher.Events:
+			if !ok {
+				return
+			}
+			if err := r.Reload(); err != nil {
+				log.Warningf("Failed to reload TLS certificates from %v, keeping previous ones: %v", r.certPath, err)
+				continue
+			}
+			log.Infof("Reloaded TLS certificates from %v", r.certPath)
+		case err, ok := <-r.watcher.Errors:
+			if !ok {
+				return
+			}
+			log.Errorf("Error watching TLS certificates %v: %v", r.certPath, err)
+		}
+	}
+}
+
+// Reload reads the certificate, key and CA files from disk. On error, the
+// previously loaded material is kept.
+func (r *CertReloader) Reload() error {
+	cert, err := tls.LoadX509KeyPair(r.certPath, r.keyPath)
+	if err != nil {
+		return err
+	}
+
+	var pool *x509.CertPool
+	if r.caPath != "" {
+		b, err := os.ReadFile(r.caPath)
+		if err != nil {
+			return vterrors.Errorf(vtrpc.Code_NOT_FOUND, "failed to read ca file: %s", r.caPath)
+		}
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(b) {
+			return vterrors.Errorf(vtrpc.Code_UNKNOWN, "failed to append certificates")
+		}
+	}
+
+	r.mu.Lock()
+	r.cert = &cert
+	r.pool = pool
+	onReload := r.onReload
+	r.mu.Unlock()
+
+	for _, f := range onReload {
+		f()
+	}
+	return nil
+}
+
+// OnReload registers f to be called after each successful reload of the
+// files, e.g. to export the identity of the new certificate.
+func (r *CertReloader) OnReload(f func()) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.onReload = append(r.onReload, f)
+}
+
+// Certificate returns the currently loaded client certificate.
+func (r *CertReloader) Certificate() *tls.Certificate {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert
+}
+
+// CertPool returns the currently loaded CA pool, or nil if no CA file was
+// provided.
+func (r *CertReloader) CertPool() *x509.CertPool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.pool
+}
+
+// ClientConfig returns a tls.Config that always presents the most recently
+// loaded client certificate and verifies the server against the most
+// recently loaded CA pool (or the system pool if none was provided).
+func (r *CertReloader) ClientConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return r.Certificate(), nil
+		},
+		// The CA pool can change after the config has been built, so we
+		// can't use RootCAs and do the verification ourselves instead.
+		InsecureSkipVerify: true,
+		VerifyConnection: func(cs tls.ConnectionState) error {
+			roots := r.CertPool()
+			if roots == nil {
+				var err error
+				roots, err = x509.SystemCertPool()
+				if err != nil {
+					return err
+				}
+			}
+			opts := x509.VerifyOptions{
+				Roots:         roots,
+				DNSName:       cs.ServerName,
+				Intermediates: x509.NewCertPool(),
+			}
+			for _, cert := range cs.PeerCertificates[1:] {
+				opts.Intermediates.AddCert(cert)
+			}
+			_, err := cs.PeerCertificates[0].Verify(opts)
+			return err
+		},
+	}
+}
+
+// Close stops watching the certificate files.
+func (r *CertReloader) Close() {
+	if r.watcher == nil {
+		return
+	}
+	close(r.done)
+	r.watcher.Close()
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vttls
+
+import (
+	"crypto/x509"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/tlstest"
+)
+
+func leafSerial(t *testing.T, r *CertReloader) int64 {
+	t.Helper()
+	cert := r.Certificate()
+	require.NotNil(t, cert)
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	require.NoError(t, err)
+	return leaf.SerialNumber.Int64()
+}
+
+func TestCertReloader(t *testing.T) {
+	root := t.TempDir()
+	tlstest.CreateCA(root)
+	tlstest.CreateSignedCert(root, tlstest.CA, "01", "client", "Client Cert")
+
+	certPath := path.Join(root, "client-cert.pem")
+	keyPath := path.Join(root, "client-key.pem")
+	caPath := path.Join(root, "ca-cert.pem")
+
+	r, err := NewCertReloader(certPath, keyPath, caPath, true)
+	require.NoError(t, err)
+	defer r.Close()
+
+	assert.EqualValues(t, 1, leafSerial(t, r))
+	assert.NotNil(t, r.CertPool())
+
+	// Rotating the cert on disk is picked up by the watcher.
+	tlstest.CreateSignedCert(root, tlstest.CA, "02", "client", "Client Cert")
+	require.Eventually(t, func() bool {
+		return leafSerial(t, r) == 2
+	}, 10*time.Second, 10*time.Millisecond)
+
+	// A broken file keeps the previous certificate around.
+	require.NoError(t, os.WriteFile(certPath, []byte("garbage"), 0o644))
+	assert.Error(t, r.Reload())
+	assert.EqualValues(t, 2, leafSerial(t, r))
+}
+
+func TestCertReloaderNoWatch(t *testing.T) {
+	root := t.TempDir()
+	tlstest.CreateCA(root)
+	tlstest.CreateSignedCert(root, tlstest.CA, "01", "client", "Client Cert")
+
+	r, err := NewCertReloader(path.Join(root, "client-cert.pem"), path.Join(root, "client-key.pem"), "", false)
+	require.NoError(t, err)
+	defer r.Close()
+
+	assert.Nil(t, r.CertPool())
+	reloads := 0
+	r.OnReload(func() { reloads++ })
+
+	tlstest.CreateSignedCert(root, tlstest.CA, "02", "client", "Client Cert")
+	assert.EqualValues(t, 1, leafSerial(t, r))
+	require.NoError(t, r.Reload())
+	assert.EqualValues(t, 2, leafSerial(t, r))
+	assert.Equal(t, 1, reloads)
+
+	_, err = NewCertReloader(path.Join(root, "missing-cert.pem"), path.Join(root, "client-key.pem"), "", false)
+	assert.Error(t, err)
+}
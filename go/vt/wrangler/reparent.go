@@ -26,9 +26,12 @@ import (
 	"time"
 
 	"vitess.io/vitess/go/event"
+	"vitess.io/vitess/go/protoutil"
+	"vitess.io/vitess/go/sets"
 	"vitess.io/vitess/go/vt/log"
 	"vitess.io/vitess/go/vt/topo/topoproto"
 	"vitess.io/vitess/go/vt/topotools/events"
+	"vitess.io/vitess/go/vt/vtctl/approval"
 	"vitess.io/vitess/go/vt/vtctl/grpcvtctldserver"
 	"vitess.io/vitess/go/vt/vtctl/reparentutil"
 
@@ -93,6 +96,25 @@ func (wr *Wrangler) PlannedReparentShard(
 // EmergencyReparentShard will make the provided tablet the primary for
 // the shard, when the old primary is completely unreachable.
 func (wr *Wrangler) EmergencyReparentShard(ctx context.Context, keyspace, shard string, opts reparentutil.EmergencyReparentOptions) (err error) {
+	req := &vtctldatapb.EmergencyReparentShardRequest{
+		Keyspace:                  keyspace,
+		Shard:                     shard,
+		NewPrimary:                opts.NewPrimaryAlias,
+		WaitReplicasTimeout:       protoutil.DurationToProto(opts.WaitReplicasTimeout),
+		PreventCrossCellPromotion: opts.PreventCrossCellPromotion,
+		WaitForAllTablets:         opts.WaitAllTablets,
+	}
+	for _, alias := range sets.List(opts.IgnoreReplicas) {
+		tabletAlias, err := topoproto.ParseTabletAlias(alias)
+		if err != nil {
+			return err
+		}
+		req.IgnoreReplicas = append(req.IgnoreReplicas, tabletAlias)
+	}
+	if err := wr.approvals.Check(ctx, approval.EmergencyReparentShard, req); err != nil {
+		return err
+	}
+
 	_, err = reparentutil.NewEmergencyReparenter(wr.ts, wr.tmc, wr.logger).ReparentShard(
 		ctx,
 		keyspace,
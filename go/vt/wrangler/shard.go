@@ -31,10 +31,11 @@ import (
 // UpdateSrvKeyspacePartitions changes the SrvKeyspaceGraph
 // for a shard.  It updates serving graph
 //
-// This takes the keyspace lock as to not interfere with resharding operations.
+// This takes the routing lock of the keyspace as to not interfere with
+// resharding operations.
 func (wr *Wrangler) UpdateSrvKeyspacePartitions(ctx context.Context, keyspace, shard string, tabletType topodatapb.TabletType, cells []string, remove bool) (err error) {
-	// lock the keyspace
-	ctx, unlock, lockErr := wr.ts.LockKeyspace(ctx, keyspace, "UpdateSrvKeyspacePartitions")
+	// lock the routing of the keyspace
+	ctx, unlock, lockErr := wr.ts.LockKeyspaceRouting(ctx, keyspace, "UpdateSrvKeyspacePartitions")
 	if lockErr != nil {
 		return lockErr
 	}
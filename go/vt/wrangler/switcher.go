@@ -121,6 +121,10 @@ func (r *switcher) lockKeyspace(ctx context.Context, keyspace, action string) (c
 	return r.wr.ts.LockKeyspace(ctx, keyspace, action)
 }
 
+func (r *switcher) lockKeyspaceRouting(ctx context.Context, keyspace, action string) (context.Context, func(*error), error) {
+	return r.wr.ts.LockKeyspaceRouting(ctx, keyspace, action)
+}
+
 func (r *switcher) freezeTargetVReplication(ctx context.Context) error {
 	return r.ts.freezeTargetVReplication(ctx)
 }
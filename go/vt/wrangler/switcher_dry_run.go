@@ -235,6 +235,13 @@ func (dr *switcherDryRun) lockKeyspace(ctx context.Context, keyspace, _ string)
 	}, nil
 }
 
+func (dr *switcherDryRun) lockKeyspaceRouting(ctx context.Context, keyspace, _ string) (context.Context, func(*error), error) {
+	dr.drLog.Log(fmt.Sprintf("Lock routing of keyspace %s", keyspace))
+	return ctx, func(e *error) {
+		dr.drLog.Log(fmt.Sprintf("Unlock routing of keyspace %s", keyspace))
+	}, nil
+}
+
 func (dr *switcherDryRun) removeSourceTables(ctx context.Context, removalType workflow.TableRemovalType) error {
 	logs := make([]string, 0)
 	for _, source := range dr.ts.Sources() {
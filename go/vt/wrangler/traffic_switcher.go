@@ -42,6 +42,7 @@ import (
 	"vitess.io/vitess/go/vt/topo"
 	"vitess.io/vitess/go/vt/topo/topoproto"
 	"vitess.io/vitess/go/vt/topotools"
+	"vitess.io/vitess/go/vt/vtctl/approval"
 	"vitess.io/vitess/go/vt/vtctl/workflow"
 	"vitess.io/vitess/go/vt/vterrors"
 	"vitess.io/vitess/go/vt/vtgate/vindexes"
@@ -53,6 +54,7 @@ import (
 	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
 	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
 	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
 	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
 )
 
@@ -404,10 +406,10 @@ func (wr *Wrangler) SwitchReads(ctx context.Context, targetKeyspace, workflowNam
 		return handleError("workflow validation failed", err)
 	}
 
-	// For reads, locking the source keyspace is sufficient.
-	ctx, unlock, lockErr := sw.lockKeyspace(ctx, ts.SourceKeyspaceName(), "SwitchReads")
+	// For reads, locking the routing of the source keyspace is sufficient.
+	ctx, unlock, lockErr := sw.lockKeyspaceRouting(ctx, ts.SourceKeyspaceName(), "SwitchReads")
 	if lockErr != nil {
-		return handleError(fmt.Sprintf("failed to lock the %s keyspace", ts.SourceKeyspaceName()), lockErr)
+		return handleError(fmt.Sprintf("failed to lock the routing of the %s keyspace", ts.SourceKeyspaceName()), lockErr)
 	}
 	defer unlock(&err)
 
@@ -473,6 +475,28 @@ func (wr *Wrangler) areTabletsAvailableToStreamFrom(ctx context.Context, ts *tra
 	return nil
 }
 
+// lockWorkflowRouting takes the routing locks of the source and the target
+// keyspaces of the workflow, in that order, after their keyspace locks (see
+// topo.Server.LockKeyspaceRouting).
+func lockWorkflowRouting(ctx context.Context, sw iswitcher, ts *trafficSwitcher, action string) (context.Context, func(*error), error) {
+	ctx, sourceUnlock, err := sw.lockKeyspaceRouting(ctx, ts.SourceKeyspaceName(), action)
+	if err != nil {
+		return nil, nil, err
+	}
+	if ts.TargetKeyspaceName() == ts.SourceKeyspaceName() {
+		return ctx, sourceUnlock, nil
+	}
+	ctx, targetUnlock, err := sw.lockKeyspaceRouting(ctx, ts.TargetKeyspaceName(), action)
+	if err != nil {
+		sourceUnlock(&err)
+		return nil, nil, err
+	}
+	return ctx, func(finalErr *error) {
+		targetUnlock(finalErr)
+		sourceUnlock(finalErr)
+	}, nil
+}
+
 // SwitchWrites is a generic way of migrating write traffic for a resharding workflow.
 func (wr *Wrangler) SwitchWrites(ctx context.Context, targetKeyspace, workflowName string, timeout time.Duration,
 	cancel, reverse, reverseReplication bool, dryRun, initializeTargetSequences bool) (journalID int64, dryRunResults *[]string, err error) {
@@ -532,6 +556,12 @@ func (wr *Wrangler) SwitchWrites(ctx context.Context, targetKeyspace, workflowNa
 		ctx = tctx
 		defer targetUnlock(&err)
 	}
+	tctx, routingUnlock, lockErr := lockWorkflowRouting(ctx, sw, ts, "SwitchWrites")
+	if lockErr != nil {
+		return handleError("failed to lock the routing of the keyspaces", lockErr)
+	}
+	ctx = tctx
+	defer routingUnlock(&err)
 
 	// Find out if the target is using any sequence tables for auto_increment
 	// value generation. If so, then we'll need to ensure that they are
@@ -704,6 +734,13 @@ func (wr *Wrangler) DropTargets(ctx context.Context, targetKeyspace, workflow st
 		defer targetUnlock(&err)
 		ctx = tctx
 	}
+	tctx, routingUnlock, lockErr := lockWorkflowRouting(ctx, sw, ts, "DropTargets")
+	if lockErr != nil {
+		ts.Logger().Errorf("LockKeyspaceRouting failed: %v", lockErr)
+		return nil, lockErr
+	}
+	defer routingUnlock(&err)
+	ctx = tctx
 	if !keepData {
 		switch ts.MigrationType() {
 		case binlogdatapb.MigrationType_TABLES:
@@ -796,6 +833,17 @@ func (wr *Wrangler) finalizeMigrateWorkflow(ctx context.Context, targetKeyspace,
 // DropSources cleans up source tables, shards and denied tables after a
 // MoveTables/Reshard is completed.
 func (wr *Wrangler) DropSources(ctx context.Context, targetKeyspace, workflowName string, removalType workflow.TableRemovalType, keepData, keepRoutingRules, force, dryRun bool) (*[]string, error) {
+	if !keepData && !dryRun {
+		req := &vtctldatapb.MoveTablesCompleteRequest{
+			Workflow:         workflowName,
+			TargetKeyspace:   targetKeyspace,
+			KeepRoutingRules: keepRoutingRules,
+			RenameTables:     removalType == workflow.RenameTable,
+		}
+		if err := wr.approvals.Check(ctx, approval.DropSources, req); err != nil {
+			return nil, err
+		}
+	}
 	ts, err := wr.buildTrafficSwitcher(ctx, targetKeyspace, workflowName)
 	if err != nil {
 		wr.Logger().Errorf("buildTrafficSwitcher failed: %v", err)
@@ -824,6 +872,13 @@ func (wr *Wrangler) DropSources(ctx context.Context, targetKeyspace, workflowNam
 		defer targetUnlock(&err)
 		ctx = tctx
 	}
+	tctx, routingUnlock, lockErr := lockWorkflowRouting(ctx, sw, ts, "DropSources")
+	if lockErr != nil {
+		ts.Logger().Errorf("LockKeyspaceRouting failed: %v", lockErr)
+		return nil, lockErr
+	}
+	defer routingUnlock(&err)
+	ctx = tctx
 	if !force {
 		if err := sw.validateWorkflowHasCompleted(ctx); err != nil {
 			wr.Logger().Errorf("Workflow has not completed, cannot DropSources: %v", err)
@@ -1118,34 +1173,33 @@ func (ts *trafficSwitcher) validate(ctx context.Context) error {
 
 func (ts *trafficSwitcher) switchTableReads(ctx context.Context, cells []string, servedTypes []topodatapb.TabletType, direction workflow.TrafficSwitchDirection) error {
 	log.Infof("switchTableReads: servedTypes: %+v, direction %t", servedTypes, direction)
-	rules, err := topotools.GetRoutingRules(ctx, ts.TopoServer())
-	if err != nil {
-		return err
-	}
-	// We assume that the following rules were setup when the targets were created:
-	// table -> sourceKeyspace.table
-	// targetKeyspace.table -> sourceKeyspace.table
-	// For forward migration, we add tablet type specific rules to redirect traffic to the target.
-	// For backward, we redirect to source.
-	for _, servedType := range servedTypes {
-		tt := strings.ToLower(servedType.String())
-		for _, table := range ts.Tables() {
-			if direction == workflow.DirectionForward {
-				log.Infof("Route direction forward")
-				toTarget := []string{ts.TargetKeyspaceName() + "." + table}
-				rules[table+"@"+tt] = toTarget
-				rules[ts.TargetKeyspaceName()+"."+table+"@"+tt] = toTarget
-				rules[ts.SourceKeyspaceName()+"."+table+"@"+tt] = toTarget
-			} else {
-				log.Infof("Route direction backwards")
-				toSource := []string{ts.SourceKeyspaceName() + "." + table}
-				rules[table+"@"+tt] = toSource
-				rules[ts.TargetKeyspaceName()+"."+table+"@"+tt] = toSource
-				rules[ts.SourceKeyspaceName()+"."+table+"@"+tt] = toSource
+	err := topotools.UpdateRoutingRules(ctx, ts.TopoServer(), "SwitchReads", func(ctx context.Context, rules map[string][]string) error {
+		// We assume that the following rules were setup when the targets were created:
+		// table -> sourceKeyspace.table
+		// targetKeyspace.table -> sourceKeyspace.table
+		// For forward migration, we add tablet type specific rules to redirect traffic to the target.
+		// For backward, we redirect to source.
+		for _, servedType := range servedTypes {
+			tt := strings.ToLower(servedType.String())
+			for _, table := range ts.Tables() {
+				if direction == workflow.DirectionForward {
+					log.Infof("Route direction forward")
+					toTarget := []string{ts.TargetKeyspaceName() + "." + table}
+					rules[table+"@"+tt] = toTarget
+					rules[ts.TargetKeyspaceName()+"."+table+"@"+tt] = toTarget
+					rules[ts.SourceKeyspaceName()+"."+table+"@"+tt] = toTarget
+				} else {
+					log.Infof("Route direction backwards")
+					toSource := []string{ts.SourceKeyspaceName() + "." + table}
+					rules[table+"@"+tt] = toSource
+					rules[ts.TargetKeyspaceName()+"."+table+"@"+tt] = toSource
+					rules[ts.SourceKeyspaceName()+"."+table+"@"+tt] = toSource
+				}
 			}
 		}
-	}
-	if err := topotools.SaveRoutingRules(ctx, ts.TopoServer(), rules); err != nil {
+		return nil
+	})
+	if err != nil {
 		return err
 	}
 	return ts.TopoServer().RebuildSrvVSchema(ctx, cells)
@@ -1563,36 +1617,34 @@ func (ts *trafficSwitcher) changeRouting(ctx context.Context) error {
 
 func (ts *trafficSwitcher) changeWriteRoute(ctx context.Context) error {
 	if ts.isPartialMigration {
-		srr, err := topotools.GetShardRoutingRules(ctx, ts.TopoServer())
+		err := topotools.UpdateShardRoutingRules(ctx, ts.TopoServer(), "SwitchWrites", func(ctx context.Context, srr map[string]string) error {
+			for _, si := range ts.SourceShards() {
+				delete(srr, fmt.Sprintf("%s.%s", ts.TargetKeyspaceName(), si.ShardName()))
+				ts.Logger().Infof("Deleted shard routing: %v:%v", ts.TargetKeyspaceName(), si.ShardName())
+				srr[fmt.Sprintf("%s.%s", ts.SourceKeyspaceName(), si.ShardName())] = ts.TargetKeyspaceName()
+				ts.Logger().Infof("Added shard routing: %v:%v", ts.SourceKeyspaceName(), si.ShardName())
+			}
+			return nil
+		})
 		if err != nil {
 			return err
 		}
-		for _, si := range ts.SourceShards() {
-			delete(srr, fmt.Sprintf("%s.%s", ts.TargetKeyspaceName(), si.ShardName()))
-			ts.Logger().Infof("Deleted shard routing: %v:%v", ts.TargetKeyspaceName(), si.ShardName())
-			srr[fmt.Sprintf("%s.%s", ts.SourceKeyspaceName(), si.ShardName())] = ts.TargetKeyspaceName()
-			ts.Logger().Infof("Added shard routing: %v:%v", ts.SourceKeyspaceName(), si.ShardName())
-		}
-		if err := topotools.SaveShardRoutingRules(ctx, ts.TopoServer(), srr); err != nil {
-			return err
-		}
 	} else {
-		rules, err := topotools.GetRoutingRules(ctx, ts.TopoServer())
+		err := topotools.UpdateRoutingRules(ctx, ts.TopoServer(), "SwitchWrites", func(ctx context.Context, rules map[string][]string) error {
+			for _, table := range ts.Tables() {
+				targetKsTable := fmt.Sprintf("%s.%s", ts.TargetKeyspaceName(), table)
+				sourceKsTable := fmt.Sprintf("%s.%s", ts.SourceKeyspaceName(), table)
+				delete(rules, targetKsTable)
+				ts.Logger().Infof("Deleted routing: %s", targetKsTable)
+				rules[table] = []string{targetKsTable}
+				rules[sourceKsTable] = []string{targetKsTable}
+				ts.Logger().Infof("Added routing: %v %v", table, sourceKsTable)
+			}
+			return nil
+		})
 		if err != nil {
 			return err
 		}
-		for _, table := range ts.Tables() {
-			targetKsTable := fmt.Sprintf("%s.%s", ts.TargetKeyspaceName(), table)
-			sourceKsTable := fmt.Sprintf("%s.%s", ts.SourceKeyspaceName(), table)
-			delete(rules, targetKsTable)
-			ts.Logger().Infof("Deleted routing: %s", targetKsTable)
-			rules[table] = []string{targetKsTable}
-			rules[sourceKsTable] = []string{targetKsTable}
-			ts.Logger().Infof("Added routing: %v %v", table, sourceKsTable)
-		}
-		if err := topotools.SaveRoutingRules(ctx, ts.TopoServer(), rules); err != nil {
-			return err
-		}
 	}
 	return ts.TopoServer().RebuildSrvVSchema(ctx, nil)
 }
@@ -1639,17 +1691,12 @@ func (ts *trafficSwitcher) deleteShardRoutingRules(ctx context.Context) error {
 	if !ts.isPartialMigration {
 		return nil
 	}
-	srr, err := topotools.GetShardRoutingRules(ctx, ts.TopoServer())
-	if err != nil {
-		return err
-	}
-	for _, si := range ts.TargetShards() {
-		delete(srr, fmt.Sprintf("%s.%s", ts.targetKeyspace, si.ShardName()))
-	}
-	if err := topotools.SaveShardRoutingRules(ctx, ts.TopoServer(), srr); err != nil {
-		return err
-	}
-	return nil
+	return topotools.UpdateShardRoutingRules(ctx, ts.TopoServer(), "DeleteShardRoutingRules", func(ctx context.Context, srr map[string]string) error {
+		for _, si := range ts.TargetShards() {
+			delete(srr, fmt.Sprintf("%s.%s", ts.targetKeyspace, si.ShardName()))
+		}
+		return nil
+	})
 }
 
 func (ts *trafficSwitcher) startReverseVReplication(ctx context.Context) error {
@@ -1944,25 +1991,20 @@ func (ts *trafficSwitcher) dropTargetShards(ctx context.Context) error {
 }
 
 func (ts *trafficSwitcher) deleteRoutingRules(ctx context.Context) error {
-	rules, err := topotools.GetRoutingRules(ctx, ts.TopoServer())
-	if err != nil {
-		return err
-	}
-	for _, table := range ts.Tables() {
-		delete(rules, table)
-		delete(rules, table+"@replica")
-		delete(rules, table+"@rdonly")
-		delete(rules, ts.TargetKeyspaceName()+"."+table)
-		delete(rules, ts.TargetKeyspaceName()+"."+table+"@replica")
-		delete(rules, ts.TargetKeyspaceName()+"."+table+"@rdonly")
-		delete(rules, ts.SourceKeyspaceName()+"."+table)
-		delete(rules, ts.SourceKeyspaceName()+"."+table+"@replica")
-		delete(rules, ts.SourceKeyspaceName()+"."+table+"@rdonly")
-	}
-	if err := topotools.SaveRoutingRules(ctx, ts.TopoServer(), rules); err != nil {
-		return err
-	}
-	return nil
+	return topotools.UpdateRoutingRules(ctx, ts.TopoServer(), "DeleteRoutingRules", func(ctx context.Context, rules map[string][]string) error {
+		for _, table := range ts.Tables() {
+			delete(rules, table)
+			delete(rules, table+"@replica")
+			delete(rules, table+"@rdonly")
+			delete(rules, ts.TargetKeyspaceName()+"."+table)
+			delete(rules, ts.TargetKeyspaceName()+"."+table+"@replica")
+			delete(rules, ts.TargetKeyspaceName()+"."+table+"@rdonly")
+			delete(rules, ts.SourceKeyspaceName()+"."+table)
+			delete(rules, ts.SourceKeyspaceName()+"."+table+"@replica")
+			delete(rules, ts.SourceKeyspaceName()+"."+table+"@rdonly")
+		}
+		return nil
+	})
 }
 
 // addParticipatingTablesToKeyspace updates the vschema with the new tables that were created as part of the
@@ -885,6 +885,8 @@ func testTableMigrateOneToMany(t *testing.T, keepData, keepRoutingRules bool) {
 	wantdryRunDropSources := []string{
 		"Lock keyspace ks1",
 		"Lock keyspace ks2",
+		"Lock routing of keyspace ks1",
+		"Lock routing of keyspace ks2",
 	}
 	if !keepData {
 		wantdryRunDropSources = append(wantdryRunDropSources, "Dropping these tables from the database and removing them from the vschema for keyspace ks1:",
@@ -905,7 +907,7 @@ func testTableMigrateOneToMany(t *testing.T, keepData, keepRoutingRules bool) {
 	if !keepRoutingRules {
 		wantdryRunDropSources = append(wantdryRunDropSources, "Routing rules for participating tables will be deleted")
 	}
-	wantdryRunDropSources = append(wantdryRunDropSources, "Unlock keyspace ks2", "Unlock keyspace ks1")
+	wantdryRunDropSources = append(wantdryRunDropSources, "Unlock routing of keyspace ks2", "Unlock routing of keyspace ks1", "Unlock keyspace ks2", "Unlock keyspace ks1")
 	results, err := tme.wr.DropSources(ctx, tme.targetKeyspace, "test", workflow.DropTable, keepData, keepRoutingRules, false, true)
 	require.NoError(t, err)
 	require.Empty(t, cmp.Diff(wantdryRunDropSources, *results))
@@ -919,6 +921,8 @@ func testTableMigrateOneToMany(t *testing.T, keepData, keepRoutingRules bool) {
 	wantdryRunRenameSources := []string{
 		"Lock keyspace ks1",
 		"Lock keyspace ks2",
+		"Lock routing of keyspace ks1",
+		"Lock routing of keyspace ks2",
 	}
 	if !keepData {
 		wantdryRunRenameSources = append(wantdryRunRenameSources, "Renaming these tables from the database and removing them from the vschema for keyspace ks1:", "	"+
@@ -939,7 +943,7 @@ func testTableMigrateOneToMany(t *testing.T, keepData, keepRoutingRules bool) {
 	if !keepRoutingRules {
 		wantdryRunRenameSources = append(wantdryRunRenameSources, "Routing rules for participating tables will be deleted")
 	}
-	wantdryRunRenameSources = append(wantdryRunRenameSources, "Unlock keyspace ks2", "Unlock keyspace ks1")
+	wantdryRunRenameSources = append(wantdryRunRenameSources, "Unlock routing of keyspace ks2", "Unlock routing of keyspace ks1", "Unlock keyspace ks2", "Unlock keyspace ks1")
 	results, err = tme.wr.DropSources(ctx, tme.targetKeyspace, "test", workflow.RenameTable, keepData, keepRoutingRules, false, true)
 	require.NoError(t, err)
 	require.Empty(t, cmp.Diff(wantdryRunRenameSources, *results))
@@ -999,14 +1003,16 @@ func TestTableMigrateOneToManyDryRun(t *testing.T) {
 	defer tme.close(t)
 
 	wantdryRunReads := []string{
-		"Lock keyspace ks1",
+		"Lock routing of keyspace ks1",
 		"Switch reads for tables [t1,t2] to keyspace ks2 for tablet types [RDONLY]",
 		"Routing rules for tables [t1,t2] will be updated",
-		"Unlock keyspace ks1",
+		"Unlock routing of keyspace ks1",
 	}
 	wantdryRunWrites := []string{
 		"Lock keyspace ks1",
 		"Lock keyspace ks2",
+		"Lock routing of keyspace ks1",
+		"Lock routing of keyspace ks2",
 		"Stop writes on keyspace ks1, tables [t1,t2]:",
 		"\tKeyspace ks1, Shard 0 at Position MariaDB/5-456-892",
 		"Wait for VReplication on stopped streams to catchup for up to 1s",
@@ -1022,6 +1028,8 @@ func TestTableMigrateOneToManyDryRun(t *testing.T) {
 		"Mark vreplication streams frozen on:",
 		"	Keyspace ks2, Shard -80, Tablet 20, Workflow test, DbName vt_ks2",
 		"	Keyspace ks2, Shard 80-, Tablet 30, Workflow test, DbName vt_ks2",
+		"Unlock routing of keyspace ks2",
+		"Unlock routing of keyspace ks1",
 		"Unlock keyspace ks2",
 		"Unlock keyspace ks1",
 	}
@@ -1413,7 +1421,11 @@ func TestTableMigrateCancelDryRun(t *testing.T) {
 	want := []string{
 		"Lock keyspace ks1",
 		"Lock keyspace ks2",
+		"Lock routing of keyspace ks1",
+		"Lock routing of keyspace ks2",
 		"Cancel migration as requested",
+		"Unlock routing of keyspace ks2",
+		"Unlock routing of keyspace ks1",
 		"Unlock keyspace ks2",
 		"Unlock keyspace ks1",
 	}
@@ -27,6 +27,7 @@ import (
 	"vitess.io/vitess/go/vt/logutil"
 	"vitess.io/vitess/go/vt/sqlparser"
 	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/vtctl/approval"
 	"vitess.io/vitess/go/vt/vtctl/grpcvtctldserver"
 	"vitess.io/vitess/go/vt/vtenv"
 	"vitess.io/vitess/go/vt/vttablet/tmclient"
@@ -61,17 +62,21 @@ type Wrangler struct {
 	// Limt the number of concurrent background goroutines if needed.
 	sem            *semaphore.Weighted
 	WorkflowParams *VReplicationWorkflowParams
+	// approvals gates the destructive commands implemented by the wrangler,
+	// like the ones of the VtctldServer.
+	approvals *approval.Gate
 }
 
 // New creates a new Wrangler object.
 func New(env *vtenv.Environment, logger logutil.Logger, ts *topo.Server, tmc tmclient.TabletManagerClient) *Wrangler {
 	return &Wrangler{
-		env:      env,
-		logger:   logger,
-		ts:       ts,
-		tmc:      tmc,
-		vtctld:   grpcvtctldserver.NewVtctldServer(env, ts),
-		sourceTs: ts,
+		env:       env,
+		logger:    logger,
+		ts:        ts,
+		tmc:       tmc,
+		vtctld:    grpcvtctldserver.NewVtctldServer(env, ts),
+		sourceTs:  ts,
+		approvals: approval.NewGateFromFlags(ts),
 	}
 }
 